@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// auditSince limits the audit output to recent entries
+	auditSince string
+	// auditNoDiff hides the diff of each entry
+	auditNoDiff bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the log of changes sshc made to your configs",
+	Long: `Show every add, update, delete, move and tag change sshc made, with the diff of the affected block.
+
+Examples:
+  sshc audit             # Show all recorded changes
+  sshc audit --since 7d  # Show changes from the last 7 days
+  sshc audit --since 12h --no-diff`,
+	Args: cobra.NoArgs,
+	Run:  runAudit,
+}
+
+func runAudit(cmd *cobra.Command, args []string) {
+	var since time.Time
+	if auditSince != "" {
+		d, err := parseAuditSince(auditSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		since = time.Now().Add(-d)
+	}
+
+	entries, err := config.ReadAuditLog(since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading audit log: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No changes recorded.")
+		return
+	}
+
+	for _, entry := range entries {
+		fmt.Printf("%s  %-7s %-20s %s", entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Operation, entry.Host, entry.File)
+		if entry.User != "" {
+			fmt.Printf("  (%s)", entry.User)
+		}
+		fmt.Println()
+		if !auditNoDiff && entry.Diff != "" {
+			for _, line := range strings.Split(entry.Diff, "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// parseAuditSince parses a duration like "30m", "12h" or "7d"
+func parseAuditSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid --since value %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid --since value %q (use e.g. 30m, 12h or 7d)", value)
+	}
+	return d, nil
+}
+
+func init() {
+	RootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "Only show changes newer than this (e.g. 30m, 12h, 7d)")
+	auditCmd.Flags().BoolVar(&auditNoDiff, "no-diff", false, "Hide the diff of each change")
+}
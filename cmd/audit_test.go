@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditCommandRegistration(t *testing.T) {
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "audit" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Audit command not found in root command")
+	}
+
+	if auditCmd.Flags().Lookup("since") == nil {
+		t.Error("Expected --since flag to be defined")
+	}
+}
+
+func TestParseAuditSince(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"30m", 30 * time.Minute, false},
+		{"0d", 0, false},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"week", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAuditSince(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAuditSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAuditSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/share"
+	"github.com/xvertile/sshc/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var browseToken string
+
+var browseCmd = &cobra.Command{
+	Use:   "browse <url>",
+	Short: "Browse the hosts a colleague shares with \"sshc serve\"",
+	Long: `Fetch the hosts shared by "sshc serve" and show them in the usual list, read-only.
+Connecting uses your own ssh, keys and agent, reaching the shared hostname, user and port.
+Hosts whose details could be read as ssh options are left out.
+
+Example:
+  sshc browse http://192.168.1.10:7843 --token pairing`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBrowse,
+}
+
+func runBrowse(cmd *cobra.Command, args []string) {
+	if browseToken == "" {
+		fmt.Fprintln(os.Stderr, "Error: --token is required")
+		os.Exit(1)
+	}
+
+	hosts, skipped, err := share.Fetch(context.Background(), nil, args[0], browseToken)
+	if errors.Is(err, share.ErrUnauthorized) {
+		fmt.Fprintln(os.Stderr, "Error: the token was rejected")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching hosts: %v\n", err)
+		os.Exit(1)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: left out hosts with unsafe details: %s\n", strings.Join(skipped, ", "))
+	}
+	if len(hosts) == 0 {
+		fmt.Println("The peer shares no hosts.")
+		return
+	}
+
+	if err := ui.RunBrowseMode(hosts, args[0], AppVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running browse mode: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(browseCmd)
+
+	browseCmd.Flags().StringVar(&browseToken, "token", "", "Token printed by \"sshc serve\"")
+}
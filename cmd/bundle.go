@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// bundlePassphraseEnv supplies the bundle passphrase when there is no terminal to prompt on
+const bundlePassphraseEnv = "SSHC_BUNDLE_PASSPHRASE"
+
+var (
+	bundleTag      string
+	bundleOut      string
+	bundleWithKeys bool
+
+	bundleImportTo      string
+	bundleImportYes     bool
+	bundleImportKeysDir string
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle [host...]",
+	Short: "Write a passphrase-protected bundle of hosts to share",
+	Long: `Write the named hosts, or all hosts with --tag, to an encrypted bundle that a teammate
+can read with "sshc bundle import". The bundle is encrypted with AES-GCM under a key derived
+from a passphrase (scrypt). With --with-keys the public keys of the hosts' IdentityFiles are
+included; private keys never are. The passphrase is prompted for, or read from ` + bundlePassphraseEnv + `.
+
+Examples:
+  sshc bundle --tag projectX --out projectX.sshcb
+  sshc bundle web db --with-keys --out team.sshcb`,
+	Run: runBundle,
+}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Preview and import the hosts of a bundle",
+	Long: `Decrypt a bundle written by "sshc bundle", list its hosts and import them after confirmation.
+Hosts whose name already exists are marked and skipped. Hosts are added to the file given with
+--to, or the config selected with -c. With --keys-dir the bundle's public keys are saved there.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBundleImport,
+}
+
+func runBundle(cmd *cobra.Command, args []string) {
+	if bundleOut == "" {
+		fmt.Fprintln(os.Stderr, "Error: --out is required")
+		os.Exit(1)
+	}
+	if bundleTag == "" && len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: name hosts or select them with --tag")
+		os.Exit(1)
+	}
+
+	var hosts []config.SSHHost
+	var err error
+	if configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(configFile)
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	selected, missing := selectBundleHosts(hosts, args, bundleTag)
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: host not found: %s\n", strings.Join(missing, ", "))
+		os.Exit(1)
+	}
+	if len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no hosts tagged %q\n", bundleTag)
+		os.Exit(1)
+	}
+
+	bundle, err := config.NewBundle(selected, bundleWithKeys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	passphrase, err := readBundlePassphrase(true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := config.SealBundle(bundle, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encrypting bundle: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(bundleOut, data, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing bundle: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Bundled %d hosts and %d public keys into %s\n", len(bundle.Hosts), len(bundle.PublicKeys), bundleOut)
+}
+
+// selectBundleHosts picks the named hosts and those tagged tag, in config order.
+// It also returns the names that match no host.
+func selectBundleHosts(hosts []config.SSHHost, names []string, tag string) ([]config.SSHHost, []string) {
+	found := make(map[string]bool)
+	var selected []config.SSHHost
+	for _, host := range hosts {
+		named := slices.Contains(names, host.Name)
+		tagged := tag != "" && host.HasTag(tag)
+		if named {
+			found[host.Name] = true
+		}
+		if named || tagged {
+			selected = append(selected, host)
+		}
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return selected, missing
+}
+
+func runBundleImport(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading bundle: %v\n", err)
+		os.Exit(1)
+	}
+	passphrase, err := readBundlePassphrase(false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	bundle, err := config.OpenBundle(data, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	target := bundleImportTo
+	if target == "" {
+		target = configFile
+	}
+	conflicts, err := config.BundleConflicts(bundle, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(formatBundlePreview(bundle, conflicts))
+	if len(conflicts) == len(bundle.Hosts) && len(bundle.PublicKeys) == 0 {
+		fmt.Println("Nothing to import.")
+		return
+	}
+
+	if !bundleImportYes {
+		if !stdinIsTerminal() {
+			fmt.Fprintln(os.Stderr, "Error: use --yes to import without a terminal")
+			os.Exit(1)
+		}
+		fmt.Print("Import these hosts? [y/N]: ")
+		response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !strings.EqualFold(strings.TrimSpace(response), "y") {
+			fmt.Println("Nothing imported.")
+			return
+		}
+	}
+
+	var accepted []config.SSHHost
+	for _, host := range bundle.Hosts {
+		if _, exists := conflicts[host.Name]; !exists {
+			accepted = append(accepted, host)
+		}
+	}
+	result, err := config.ImportHosts(accepted, nil, target)
+	if result != nil {
+		fmt.Printf(changeMessage("Imported %d hosts\n", "Would import %d hosts\n"), len(result.Added))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if bundleImportKeysDir != "" && len(bundle.PublicKeys) > 0 {
+		written, skipped, err := config.WriteBundleKeys(bundle, bundleImportKeysDir)
+		for _, path := range written {
+			fmt.Printf("Saved public key %s\n", path)
+		}
+		for _, path := range skipped {
+			fmt.Printf("Skipped public key %s: already exists\n", path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving public keys: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// formatBundlePreview lists the hosts of a bundle, marking those that already exist
+// with "!" and how they differ, followed by the public keys it carries
+func formatBundlePreview(bundle *config.Bundle, conflicts map[string]config.SSHHost) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bundle with %d hosts:\n", len(bundle.Hosts))
+	for _, host := range bundle.Hosts {
+		existing, conflict := conflicts[host.Name]
+		if !conflict {
+			fmt.Fprintf(&b, "  + %s  %s\n", host.Name, bundleDestination(host))
+			continue
+		}
+		note := "already exists, skipped"
+		if bundleDestination(existing) != bundleDestination(host) {
+			note = fmt.Sprintf("already exists as %s, skipped", bundleDestination(existing))
+		}
+		fmt.Fprintf(&b, "  ! %s  %s (%s)\n", host.Name, bundleDestination(host), note)
+	}
+
+	if len(bundle.PublicKeys) > 0 {
+		names := make([]string, len(bundle.PublicKeys))
+		for i, key := range bundle.PublicKeys {
+			names[i] = key.Name
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&b, "Public keys: %s\n", strings.Join(names, ", "))
+	}
+	return b.String()
+}
+
+// bundleDestination renders user@hostname:port of a host, leaving out what is unset
+func bundleDestination(host config.SSHHost) string {
+	destination := host.Hostname
+	if host.User != "" {
+		destination = host.User + "@" + destination
+	}
+	if host.Port != "" && host.Port != "22" {
+		destination += ":" + host.Port
+	}
+	return destination
+}
+
+// readBundlePassphrase reads the bundle passphrase from the environment or prompts
+// for it, twice when confirm is set
+func readBundlePassphrase(confirm bool) ([]byte, error) {
+	if passphrase := os.Getenv(bundlePassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return nil, fmt.Errorf("no terminal to ask for the passphrase; set %s", bundlePassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Bundle passphrase: ")
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("a passphrase is required")
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		again, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		if string(again) != string(passphrase) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+func init() {
+	RootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+
+	bundleCmd.Flags().StringVar(&bundleTag, "tag", "", "Bundle all hosts with this tag")
+	bundleCmd.Flags().StringVarP(&bundleOut, "out", "o", "", "File to write the bundle to")
+	bundleCmd.Flags().BoolVar(&bundleWithKeys, "with-keys", false, "Include the public keys of the hosts' IdentityFiles")
+
+	bundleImportCmd.Flags().StringVar(&bundleImportTo, "to", "", "Config file to add the hosts to (default: the config selected with -c)")
+	bundleImportCmd.Flags().BoolVarP(&bundleImportYes, "yes", "y", false, "Import without asking")
+	bundleImportCmd.Flags().StringVar(&bundleImportKeysDir, "keys-dir", "", "Directory to save the bundle's public keys in")
+}
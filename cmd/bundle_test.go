@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func TestSelectBundleHosts(t *testing.T) {
+	hosts := []config.SSHHost{
+		{Name: "web", Tags: []string{"projectX"}},
+		{Name: "db", Tags: []string{"ProjectX", "prod"}},
+		{Name: "cache"},
+		{Name: "ci"},
+	}
+
+	selected, missing := selectBundleHosts(hosts, []string{"ci", "gone"}, "projectx")
+	var names []string
+	for _, host := range selected {
+		names = append(names, host.Name)
+	}
+	if !slices.Equal(names, []string{"web", "db", "ci"}) {
+		t.Errorf("selected = %v, want web, db and ci in config order", names)
+	}
+	if !slices.Equal(missing, []string{"gone"}) {
+		t.Errorf("missing = %v, want gone", missing)
+	}
+}
+
+func TestFormatBundlePreviewMarksConflicts(t *testing.T) {
+	bundle := &config.Bundle{
+		Hosts: []config.SSHHost{
+			{Name: "web", Hostname: "10.0.0.1", User: "deploy"},
+			{Name: "db", Hostname: "10.0.0.2", Port: "2222"},
+		},
+		PublicKeys: []config.BundleKey{{Name: "alice.pub"}},
+	}
+	conflicts := map[string]config.SSHHost{"web": {Name: "web", Hostname: "192.168.1.1"}}
+
+	preview := formatBundlePreview(bundle, conflicts)
+	for _, want := range []string{
+		"  ! web  deploy@10.0.0.1 (already exists as 192.168.1.1, skipped)",
+		"  + db  10.0.0.2:2222",
+		"Public keys: alice.pub",
+	} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("Preview is missing %q:\n%s", want, preview)
+		}
+	}
+}
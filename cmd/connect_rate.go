@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/history"
+)
+
+// confirmConnectRate asks before connecting to a host when this connection would
+// go over its connect rate. Without a terminal it only warns, so scripts keep working.
+func confirmConnectRate(hostName string, historyManager *history.HistoryManager) bool {
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		return true
+	}
+	limit := appConfig.UsageLimitFor(hostName)
+	if limit.MaxConnects <= 0 {
+		return true
+	}
+	window := limit.WindowDuration()
+	count := history.CountSince(historyManager.GetRecentConnects(hostName), time.Now().Add(-window))
+	if count < limit.MaxConnects {
+		return true
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: %d connections to %s in the last %d minutes, the limit is %d.\n", count, hostName, int(window.Minutes()), limit.MaxConnects)
+	if limit.MaxUsers > 0 {
+		fmt.Fprintf(os.Stderr, "%s is shared by at most %d concurrent users.\n", hostName, limit.MaxUsers)
+	}
+	if !stdinIsTerminal() {
+		return true
+	}
+	fmt.Print("Connect anyway? [y/N] ")
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
@@ -91,11 +91,7 @@ Examples:
 			return fmt.Errorf("transfer failed: %w", result.Error)
 		}
 
-		// Record the transfer in history
-		historyManager, err := history.NewHistoryManager()
-		if err == nil {
-			_ = historyManager.RecordTransfer(req.Host, direction, req.LocalPath, req.RemotePath)
-		}
+		recordTransfer(req)
 
 		fmt.Println("Transfer complete!")
 		return nil
@@ -236,11 +232,7 @@ Examples:
 			return fmt.Errorf("upload failed: %w", result.Error)
 		}
 
-		// Record in history
-		historyManager, err := history.NewHistoryManager()
-		if err == nil {
-			_ = historyManager.RecordTransfer(hostName, "upload", expandedPath, remotePath)
-		}
+		recordTransfer(req)
 
 		fmt.Println("Upload complete!")
 		return nil
@@ -349,11 +341,7 @@ Examples:
 			return fmt.Errorf("download failed: %w", result.Error)
 		}
 
-		// Record in history
-		historyManager, err := history.NewHistoryManager()
-		if err == nil {
-			_ = historyManager.RecordTransfer(hostName, "download", expandedPath, remotePath)
-		}
+		recordTransfer(req)
 
 		fmt.Println("Download complete!")
 		return nil
@@ -364,3 +352,22 @@ func init() {
 	RootCmd.AddCommand(sendCmd)
 	RootCmd.AddCommand(getCmd)
 }
+
+// recordTransfer saves a transfer that completed in the history
+func recordTransfer(req *transfer.TransferRequest) {
+	historyManager, err := history.NewHistoryManager()
+	if err != nil {
+		return
+	}
+	direction := "upload"
+	if req.Direction == transfer.Download {
+		direction = "download"
+	}
+	_ = historyManager.RecordTransferEntry(req.Host, history.TransferHistoryEntry{
+		Direction:  direction,
+		LocalPath:  req.LocalPath,
+		RemotePath: req.RemotePath,
+		Recursive:  req.Recursive,
+		Outcome:    history.TransferSucceeded,
+	})
+}
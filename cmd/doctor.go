@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Find records sshc keeps for hosts that no longer exist, and files ssh refuses",
+	Long: `Check the permissions of the .ssh directory, the SSH config files and the identity files
+of hosts, which ssh refuses when group or others can write them, or read a key.
+
+Look for connection history and color labels kept for hosts that are in no SSH config
+anymore, such as a name removed from a multi-host block. A name still declared as an alias of
+another host is not orphaned.
+
+With --fix the permissions are set to 700 and 600 and the orphaned records are removed. Files
+outside the .ssh directory are only changed after confirming.
+Exits with status 1 when problems are found and not fixed.`,
+	Args: cobra.NoArgs,
+	Run:  runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	permissionsOK := checkPermissions()
+
+	known, err := config.KnownHostNames(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	historyManager, err := history.NewHistoryManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not load connection history: %v\n", err)
+		historyManager = nil
+	}
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not load application config: %v\n", err)
+		appConfig = nil
+	}
+
+	report := history.FindOrphans(historyManager, appConfig, known)
+	if report.Count() == 0 {
+		fmt.Printf("No orphaned records found for %d hosts.\n", len(known))
+		if !permissionsOK {
+			os.Exit(1)
+		}
+		return
+	}
+	printOrphanReport(report)
+
+	if !doctorFix {
+		fmt.Println("Run \"sshc doctor --fix\" to remove them.")
+		os.Exit(1)
+	}
+	if config.IsDryRun() {
+		fmt.Printf("Dry run: would clean %d orphaned records.\n", report.Count())
+		return
+	}
+	cleaned, err := history.CleanupOrphans(historyManager, appConfig, known)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error cleaning up: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Cleaned %d orphaned records.\n", cleaned.Count())
+	if !permissionsOK {
+		os.Exit(1)
+	}
+}
+
+// checkPermissions lists the files ssh refuses for their permissions and fixes
+// them with --fix, asking before it changes any outside the .ssh directory. It
+// reports whether none are left.
+func checkPermissions() bool {
+	if !config.PermissionChecksSupported {
+		fmt.Println("Permission checks skipped: Windows protects SSH files with ACLs, which are not checked.")
+		return true
+	}
+	sshDir, err := config.GetSSHDirectory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not locate the SSH directory: %v\n", err)
+		return true
+	}
+	var hosts []config.SSHHost
+	var files []string
+	if configFile != "" {
+		hosts, _ = config.ParseSSHConfigFile(configFile)
+		files, _ = config.GetAllConfigFilesFromBase(configFile)
+	} else {
+		hosts, _ = config.ParseSSHConfig()
+		files, _ = config.GetAllConfigFiles()
+	}
+
+	problems := config.CheckPermissions(sshDir, files, hosts)
+	if len(problems) == 0 {
+		fmt.Println("No permission problems found.")
+		return true
+	}
+	for _, problem := range problems {
+		fmt.Printf("Permissions: %s\n", problem)
+	}
+	if !doctorFix {
+		fmt.Println("Run \"sshc doctor --fix\" to fix them.")
+		return false
+	}
+	if config.IsDryRun() {
+		fmt.Printf("Dry run: would fix the permissions of %d files.\n", len(problems))
+		return true
+	}
+
+	outside := false
+	for _, problem := range problems {
+		if !problem.InSSHDir && stdinIsTerminal() {
+			fmt.Print("Some of these files are outside the .ssh directory. Fix them too? [y/N]: ")
+			response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			outside = strings.EqualFold(strings.TrimSpace(response), "y")
+			break
+		}
+	}
+	left, err := config.FixPermissions(problems, outside)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fixing permissions: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Fixed the permissions of %d files.\n", len(problems)-len(left))
+	for _, problem := range left {
+		fmt.Printf("Left alone: %s\n", problem.Path)
+	}
+	return len(left) == 0
+}
+
+// printOrphanReport lists the orphaned records by kind
+func printOrphanReport(report history.OrphanReport) {
+	if len(report.History) > 0 {
+		fmt.Printf("Connection history for missing hosts: %s\n", strings.Join(report.History, ", "))
+	}
+	if len(report.Colors) > 0 {
+		fmt.Printf("Color labels for missing hosts: %s\n", strings.Join(report.Colors, ", "))
+	}
+	if len(report.Pins) > 0 {
+		fmt.Printf("Pinned paths for missing hosts: %s\n", strings.Join(report.Pins, ", "))
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Fix permissions and remove the orphaned records")
+}
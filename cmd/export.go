@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export SSH and Kubernetes hosts to JSON",
+	Long: `Export all SSH and Kubernetes hosts to a JSON file that can be read back with "sshc import".
+Each host carries a "type" field ("ssh" or "k8s"). Without a file argument the JSON is written to stdout.
+
+Examples:
+  sshc export                # Print hosts as JSON
+  sshc export hosts.json     # Write hosts to hosts.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runExport,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import SSH and Kubernetes hosts from a JSON export",
+	Long: `Import hosts from a file created by "sshc export".
+Hosts whose name already exists are skipped. SSH hosts are added to the config selected with -c.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runImport,
+}
+
+func runExport(cmd *cobra.Command, args []string) {
+	var sshHosts []config.SSHHost
+	var err error
+	if configFile != "" {
+		sshHosts, err = config.ParseSSHConfigFile(configFile)
+	} else {
+		sshHosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	k8sHosts, err := config.ParseK8sConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading k8s config: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := config.MarshalExport(sshHosts, k8sHosts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding hosts: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(args) == 0 {
+		fmt.Println(string(data))
+		return
+	}
+
+	if err := os.WriteFile(args[0], append(data, '\n'), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing export file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d SSH and %d k8s hosts to %s\n", len(sshHosts), len(k8sHosts), args[0])
+}
+
+func runImport(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading import file: %v\n", err)
+		os.Exit(1)
+	}
+
+	sshHosts, k8sHosts, err := config.UnmarshalExport(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := config.ImportHosts(sshHosts, k8sHosts, configFile)
+	if result != nil {
+		for _, name := range result.Skipped {
+			fmt.Printf("Skipped '%s': already exists\n", name)
+		}
+		fmt.Printf(changeMessage("Imported %d hosts\n", "Would import %d hosts\n"), len(result.Added))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xvertile/sshc/internal/aliases"
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	aliasPrefix string
+	aliasTag    string
+	aliasShell  string
+)
+
+var exportAliasesCmd = &cobra.Command{
+	Use:   "export-aliases",
+	Short: "Print a shell alias for every SSH host",
+	Long: `Print an alias that connects to each SSH host, to source from a shell rc file. Hosts
+ssh does not find in its default config get -F with the config they were read from.
+Names that are not valid alias names have the other characters replaced with _, with a
+comment giving the original name. Wildcard patterns are left out.
+
+Examples:
+  sshc export-aliases >> ~/.bashrc                  # alias web='ssh web'
+  sshc export-aliases --prefix ssh- --tag prod       # alias ssh-db='ssh db' for hosts tagged prod
+  sshc export-aliases --shell fish > ~/.config/fish/conf.d/sshc.fish`,
+	Args: cobra.NoArgs,
+	Run:  runExportAliases,
+}
+
+func runExportAliases(cmd *cobra.Command, args []string) {
+	var hosts []config.SSHHost
+	var err error
+	opts := aliases.Options{Prefix: aliasPrefix, Tag: aliasTag}
+	if configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(configFile)
+		if opts.ConfigFile, _ = filepath.Abs(configFile); opts.ConfigFile == "" {
+			opts.ConfigFile = configFile
+		}
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+	opts.DefaultFiles, _ = config.GetAllConfigFiles()
+	opts.Home, _ = os.UserHomeDir()
+
+	list, err := aliases.Build(hosts, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	out, err := aliases.Render(list, aliasShell)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(out)
+}
+
+func init() {
+	exportAliasesCmd.Flags().StringVar(&aliasPrefix, "prefix", "", "Prepend this to every alias name, e.g. ssh-")
+	exportAliasesCmd.Flags().StringVar(&aliasTag, "tag", "", "Only include hosts with this tag")
+	exportAliasesCmd.Flags().StringVar(&aliasShell, "shell", aliases.ShellBash, "Write aliases for \"bash\" (also zsh) or abbreviations for \"fish\"")
+	RootCmd.AddCommand(exportAliasesCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/contactsheet"
+	"github.com/xvertile/sshc/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sheetTag     string
+	sheetGroupBy string
+	sheetHTML    bool
+)
+
+var exportMarkdownCmd = &cobra.Command{
+	Use:   "export-markdown [file]",
+	Short: "Export a printable cheat sheet of SSH hosts",
+	Long: `Export SSH hosts as a Markdown table of name, hostname, user, port, jump host, tags,
+options and description, grouped by tag or by config file. With --html the sheet is a
+standalone page in the colors of the active theme. Hosts and groups are sorted, so the
+output only changes when the hosts do. Without a file argument the sheet is written to stdout.
+
+Examples:
+  sshc export-markdown                     # Print the sheet, grouped by tag
+  sshc export-markdown --tag prod HOSTS.md # Only hosts tagged prod
+  sshc export-markdown --group-by file     # One table per config file
+  sshc export-markdown --html hosts.html   # Themed HTML page`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runExportMarkdown,
+}
+
+func runExportMarkdown(cmd *cobra.Command, args []string) {
+	if sheetGroupBy != contactsheet.GroupByTag && sheetGroupBy != contactsheet.GroupByFile {
+		fmt.Fprintf(os.Stderr, "Error: --group-by must be %q or %q\n", contactsheet.GroupByTag, contactsheet.GroupByFile)
+		os.Exit(1)
+	}
+
+	var hosts []config.SSHHost
+	var err error
+	if configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(configFile)
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	home, _ := os.UserHomeDir()
+	groups := contactsheet.Groups(hosts, contactsheet.Options{Tag: sheetTag, GroupBy: sheetGroupBy, Home: home})
+
+	var sheet string
+	if sheetHTML {
+		sheet, err = contactsheet.HTML(groups, themeColors())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering HTML: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		sheet = contactsheet.Markdown(groups)
+	}
+
+	if len(args) == 0 {
+		fmt.Print(sheet)
+		return
+	}
+	if err := os.WriteFile(args[0], []byte(sheet), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing cheat sheet: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d groups to %s\n", len(groups), args[0])
+}
+
+// themeColors returns the colors of the theme selected in the TUI
+func themeColors() contactsheet.Colors {
+	if appConfig, err := config.LoadAppConfig(); err == nil && appConfig.Theme != "" {
+		ui.SetThemeByName(appConfig.Theme)
+	}
+	theme := ui.GetCurrentTheme()
+	return contactsheet.Colors{
+		Background: theme.Background,
+		Foreground: theme.Foreground,
+		Primary:    theme.Primary,
+		Accent:     theme.Accent,
+		Muted:      theme.Muted,
+	}
+}
+
+func init() {
+	exportMarkdownCmd.Flags().StringVar(&sheetTag, "tag", "", "Only include hosts with this tag")
+	exportMarkdownCmd.Flags().StringVar(&sheetGroupBy, "group-by", contactsheet.GroupByTag, "Group hosts by \"tag\" or \"file\"")
+	exportMarkdownCmd.Flags().BoolVar(&sheetHTML, "html", false, "Write an HTML page in the active theme's colors")
+	RootCmd.AddCommand(exportMarkdownCmd)
+}
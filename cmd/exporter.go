@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+	"github.com/xvertile/sshc/internal/exporter"
+	"github.com/xvertile/sshc/internal/share"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterListen      string
+	exporterTag         string
+	exporterInterval    time.Duration
+	exporterTimeout     time.Duration
+	exporterConcurrency int
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Probe hosts periodically and serve their reachability as Prometheus metrics",
+	Long: `Run without the TUI, pinging the hosts (or those with --tag) every --interval the same way
+the host list does, and serve the results at /metrics in the Prometheus text format:
+
+  sshc_host_up{host="..."}               1 when the host accepted a connection and spoke SSH
+  sshc_host_latency_seconds{host="..."}  how long the probe took, for hosts that are up
+  sshc_last_probe_timestamp{host="..."}  when the host was last probed
+
+The config is read again for every round. Stops on Ctrl+C or SIGTERM.
+
+Examples:
+  sshc exporter
+  sshc exporter --listen :9108 --tag lab --interval 60s`,
+	Args: cobra.NoArgs,
+	Run:  runExporter,
+}
+
+func runExporter(cmd *cobra.Command, args []string) {
+	if exporterInterval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --interval must be positive")
+		os.Exit(1)
+	}
+
+	load := func() ([]config.SSHHost, error) {
+		if configFile != "" {
+			return config.ParseSSHConfigFile(configFile)
+		}
+		return config.ParseSSHConfig()
+	}
+	if _, err := load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	concurrency := exporterConcurrency
+	if concurrency <= 0 {
+		if appConfig, err := config.LoadAppConfig(); err == nil {
+			concurrency = appConfig.PingConcurrency
+		}
+	}
+	pingManager := connectivity.NewPingManager(exporterTimeout)
+	pingManager.SetMaxConcurrent(concurrency)
+
+	registry := exporter.NewRegistry()
+	prober := &exporter.Prober{Pinger: pingManager, Load: load, Tag: exporterTag, Registry: registry}
+	server := share.NewServer(exporterListen, exporter.Handler(registry))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+	go prober.Run(ctx, exporterInterval, func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: Could not read SSH config file: %v\n", err)
+	})
+
+	fmt.Printf("Serving metrics on %s%s every %s (Ctrl+C to stop)\n", exporterListen, exporter.MetricsPath, exporterInterval)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+		fmt.Println("Stopped exporting")
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(exporterCmd)
+
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", exporter.DefaultAddr, "Address to serve metrics on")
+	exporterCmd.Flags().StringVar(&exporterTag, "tag", "", "Only probe hosts with this tag")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", 60*time.Second, "Time between probe rounds")
+	exporterCmd.Flags().DurationVar(&exporterTimeout, "timeout", 5*time.Second, "Timeout of each probe")
+	exporterCmd.Flags().IntVar(&exporterConcurrency, "concurrency", 0, "Hosts probed at once (default: the ping concurrency of the config)")
+}
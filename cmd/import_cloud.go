@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/cloud"
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// cloudListTimeout bounds how long the cloud CLIs may take to list instances
+const cloudListTimeout = 2 * time.Minute
+
+var (
+	cloudImportTo      string
+	cloudImportYes     bool
+	cloudImportPrivate bool
+	cloudImportUser    string
+
+	awsRegion  string
+	awsFilters []string
+
+	gcloudProject string
+	gcloudFilter  string
+)
+
+var importAWSCmd = &cobra.Command{
+	Use:   "aws",
+	Short: "Preview and import running EC2 instances with the aws CLI",
+	Long: `List instances with "aws ec2 describe-instances" and import the running ones after confirmation.
+Hosts are named after the Name tag of the instance, connect to its public address (or the private one
+with --private) and use ~/.ssh/<key pair>.pem as IdentityFile. The instance's tags become tags such as
+"project=acme", next to "aws". Hosts whose name already exists are marked and skipped.
+
+Examples:
+  sshc import aws --region eu-west-1 --filter tag:Project=acme
+  sshc import aws --private --user ec2-user --to ~/.ssh/config.d/aws`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithTimeout(context.Background(), cloudListTimeout)
+		defer cancel()
+		hosts, skipped, err := cloud.ListAWS(ctx, awsRegion, awsFilters, cloudImportOptions())
+		runCloudImport("EC2", hosts, skipped, err)
+	},
+}
+
+var importGcloudCmd = &cobra.Command{
+	Use:   "gcloud",
+	Short: "Preview and import running Compute Engine instances with the gcloud CLI",
+	Long: `List instances with "gcloud compute instances list" and import the running ones after confirmation.
+Hosts connect to the external address of the instance (or the internal one with --private) as the
+first user of its ssh-keys metadata. The instance's labels become tags such as "env=prod", next to
+"gcp". Hosts whose name already exists are marked and skipped.
+
+Examples:
+  sshc import gcloud --project acme-prod
+  sshc import gcloud --filter labels.env=prod --private`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithTimeout(context.Background(), cloudListTimeout)
+		defer cancel()
+		hosts, skipped, err := cloud.ListGcloud(ctx, gcloudProject, gcloudFilter, cloudImportOptions())
+		runCloudImport("Compute Engine", hosts, skipped, err)
+	},
+}
+
+func cloudImportOptions() cloud.Options {
+	return cloud.Options{PrivateIP: cloudImportPrivate, User: cloudImportUser}
+}
+
+// runCloudImport previews the hosts listed by a cloud CLI and imports those that
+// do not exist yet once confirmed
+func runCloudImport(provider string, hosts []config.SSHHost, skipped []cloud.Skipped, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing %s instances: %v\n", provider, err)
+		os.Exit(1)
+	}
+
+	target := cloudImportTo
+	if target == "" {
+		target = configFile
+	}
+	conflicts, err := config.HostConflicts(hosts, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(formatCloudPreview(provider, hosts, skipped, conflicts))
+	if len(conflicts) == len(hosts) {
+		fmt.Println("Nothing to import.")
+		return
+	}
+
+	if !cloudImportYes {
+		if !stdinIsTerminal() {
+			fmt.Fprintln(os.Stderr, "Error: use --yes to import without a terminal")
+			os.Exit(1)
+		}
+		fmt.Print("Import these hosts? [y/N]: ")
+		response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !strings.EqualFold(strings.TrimSpace(response), "y") {
+			fmt.Println("Nothing imported.")
+			return
+		}
+	}
+
+	var accepted []config.SSHHost
+	for _, host := range hosts {
+		if _, exists := conflicts[host.Name]; !exists {
+			accepted = append(accepted, host)
+		}
+	}
+	result, err := config.ImportHosts(accepted, nil, target)
+	if result != nil {
+		fmt.Printf(changeMessage("Imported %d hosts\n", "Would import %d hosts\n"), len(result.Added))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// formatCloudPreview lists the hosts found in a cloud with their proposed tags,
+// marking those that already exist with "!", followed by the skipped instances
+func formatCloudPreview(provider string, hosts []config.SSHHost, skipped []cloud.Skipped, conflicts map[string]config.SSHHost) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d %s hosts:\n", len(hosts), provider)
+	for _, host := range hosts {
+		line := fmt.Sprintf("%s  %s", host.Name, bundleDestination(host))
+		if host.Identity != "" {
+			line += "  " + host.Identity
+		}
+		if len(host.Tags) > 0 {
+			line += "  [" + strings.Join(host.Tags, ", ") + "]"
+		}
+
+		existing, conflict := conflicts[host.Name]
+		if !conflict {
+			fmt.Fprintf(&b, "  + %s\n", line)
+			continue
+		}
+		note := "already exists, skipped"
+		if bundleDestination(existing) != bundleDestination(host) {
+			note = fmt.Sprintf("already exists as %s, skipped", bundleDestination(existing))
+		}
+		fmt.Fprintf(&b, "  ! %s (%s)\n", line, note)
+	}
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "Skipped %d instances:\n", len(skipped))
+		for _, s := range skipped {
+			fmt.Fprintf(&b, "  - %s\n", s)
+		}
+	}
+	return b.String()
+}
+
+func init() {
+	importCmd.AddCommand(importAWSCmd)
+	importCmd.AddCommand(importGcloudCmd)
+
+	for _, c := range []*cobra.Command{importAWSCmd, importGcloudCmd} {
+		c.Flags().StringVar(&cloudImportTo, "to", "", "Config file to add the hosts to (default: the config selected with -c)")
+		c.Flags().BoolVarP(&cloudImportYes, "yes", "y", false, "Import without asking")
+		c.Flags().BoolVar(&cloudImportPrivate, "private", false, "Connect to private addresses even when there is a public one")
+		c.Flags().StringVarP(&cloudImportUser, "user", "u", "", "User to log in as on every host")
+	}
+
+	importAWSCmd.Flags().StringVar(&awsRegion, "region", "", "Region to list instances in (default: the aws CLI's region)")
+	importAWSCmd.Flags().StringArrayVar(&awsFilters, "filter", nil, "Filter instances by name=value, such as tag:Project=acme (repeatable)")
+
+	importGcloudCmd.Flags().StringVar(&gcloudProject, "project", "", "Project to list instances in (default: the gcloud CLI's project)")
+	importGcloudCmd.Flags().StringVar(&gcloudFilter, "filter", "", "gcloud filter expression, such as labels.env=prod")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/cloud"
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func TestFormatCloudPreviewMarksConflicts(t *testing.T) {
+	hosts := []config.SSHHost{
+		{Name: "acme-web", Hostname: "34.245.10.11", Identity: "~/.ssh/acme-deploy.pem", Tags: []string{"aws", "project=acme"}},
+		{Name: "acme-db", Hostname: "10.20.2.40", Tags: []string{"aws"}},
+	}
+	skipped := []cloud.Skipped{{ID: "i-0d4e5f60718293a41", Reason: "not running (stopped)"}}
+	conflicts := map[string]config.SSHHost{"acme-db": {Name: "acme-db", Hostname: "10.20.2.40"}}
+
+	preview := formatCloudPreview("EC2", hosts, skipped, conflicts)
+	for _, want := range []string{
+		"Found 2 EC2 hosts:",
+		"  + acme-web  34.245.10.11  ~/.ssh/acme-deploy.pem  [aws, project=acme]",
+		"  ! acme-db  10.20.2.40  [aws] (already exists, skipped)",
+		"  - i-0d4e5f60718293a41: not running (stopped)",
+	} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("Preview is missing %q:\n%s", want, preview)
+		}
+	}
+}
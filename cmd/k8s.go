@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// kubeconfigPath is the kubeconfig to discover contexts from
+	kubeconfigPath string
+	// addAllContexts adds a host for every discovered context
+	addAllContexts bool
+)
+
+var k8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Manage Kubernetes hosts",
+}
+
+var k8sContextsCmd = &cobra.Command{
+	Use:   "contexts",
+	Short: "List contexts in a kubeconfig",
+	Long: `List the contexts defined in a kubeconfig together with their default namespace.
+Uses $KUBECONFIG or ~/.kube/config unless --kubeconfig is given.`,
+	Args: cobra.NoArgs,
+	Run:  runK8sContexts,
+}
+
+var k8sAddContextsCmd = &cobra.Command{
+	Use:   "add-contexts [context...]",
+	Short: "Create k8s hosts from kubeconfig contexts",
+	Long: `Create a k8s host for each selected kubeconfig context, using the context's default namespace.
+The pod is left blank; edit the host to set it before connecting.
+
+Examples:
+  sshc k8s add-contexts prod-eu staging   # Add two contexts
+  sshc k8s add-contexts --all             # Add every context`,
+	Run: runK8sAddContexts,
+}
+
+func runK8sContexts(cmd *cobra.Command, args []string) {
+	contexts, err := config.DiscoverK8sContexts(kubeconfigPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(contexts) == 0 {
+		fmt.Println("No contexts found.")
+		return
+	}
+
+	fmt.Printf("%-2s %-30s %-30s %s\n", "", "CONTEXT", "CLUSTER", "NAMESPACE")
+	for _, ctx := range contexts {
+		marker := ""
+		if ctx.Current {
+			marker = "*"
+		}
+		fmt.Printf("%-2s %-30s %-30s %s\n", marker, ctx.Name, ctx.Cluster, ctx.Namespace)
+	}
+}
+
+func runK8sAddContexts(cmd *cobra.Command, args []string) {
+	if len(args) == 0 && !addAllContexts {
+		fmt.Fprintln(os.Stderr, "Error: name at least one context or use --all")
+		os.Exit(1)
+	}
+
+	contexts, err := config.DiscoverK8sContexts(kubeconfigPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	byName := make(map[string]config.K8sContext, len(contexts))
+	for _, ctx := range contexts {
+		byName[ctx.Name] = ctx
+	}
+
+	selected := contexts
+	if !addAllContexts {
+		selected = nil
+		for _, name := range args {
+			ctx, ok := byName[name]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: context '%s' not found in kubeconfig\n", name)
+				os.Exit(1)
+			}
+			selected = append(selected, ctx)
+		}
+	}
+
+	var hosts []config.K8sHost
+	for _, ctx := range selected {
+		hosts = append(hosts, config.K8sHostFromContext(ctx, kubeconfigPath))
+	}
+
+	result, err := config.ImportHosts(nil, hosts, "")
+	if result != nil {
+		for _, name := range result.Skipped {
+			fmt.Printf("Skipped '%s': already exists\n", name)
+		}
+		for _, name := range result.Added {
+			fmt.Printf(changeMessage("Added '%s' (set its pod with the edit form before connecting)\n", "Would add '%s'\n"), name)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(k8sCmd)
+	k8sCmd.AddCommand(k8sContextsCmd)
+	k8sCmd.AddCommand(k8sAddContextsCmd)
+
+	k8sCmd.PersistentFlags().StringVar(&kubeconfigPath, "kubeconfig", "", "Kubeconfig to read (default: $KUBECONFIG or ~/.kube/config)")
+	k8sAddContextsCmd.Flags().BoolVar(&addAllContexts, "all", false, "Add a host for every context")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check SSH hosts for suspicious configuration",
+	Long: `Check every SSH host for settings that ssh accepts but that probably do not do what was intended.
+Exits with status 1 when warnings are found.`,
+	Args: cobra.NoArgs,
+	Run:  runLint,
+}
+
+func runLint(cmd *cobra.Command, args []string) {
+	var hosts []config.SSHHost
+	var err error
+	if configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(configFile)
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	var files []string
+	if configFile != "" {
+		files, err = config.GetAllConfigFilesFromBase(configFile)
+	} else {
+		files, err = config.GetAllConfigFiles()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing SSH config files: %v\n", err)
+		os.Exit(1)
+	}
+
+	warnings := append(config.LintHosts(hosts), config.LintConfigFiles(files)...)
+	if historyManager, err := history.NewHistoryManager(); err == nil {
+		warnings = append(warnings, config.LintConnectFailures(hosts, historyManager.GetConnectFailures())...)
+	}
+	if len(warnings) == 0 {
+		fmt.Printf("No problems found in %d hosts.\n", len(hosts))
+		return
+	}
+
+	for _, warning := range warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+	os.Exit(1)
+}
+
+func init() {
+	RootCmd.AddCommand(lintCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// confirmMaintenance asks for the host name to be typed before connecting to a
+// host under maintenance. Without a terminal to ask on, it refuses.
+func confirmMaintenance(hostName string) bool {
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		return true
+	}
+	maintenance, ok := appConfig.InMaintenance(hostName, time.Now())
+	if !ok {
+		return true
+	}
+
+	until := "until it is ended in the info view"
+	if !maintenance.Until.IsZero() {
+		until = "until " + maintenance.Until.Format("2006-01-02 15:04")
+	}
+	fmt.Printf("%s is under maintenance %s.\n", hostName, until)
+	if !stdinIsTerminal() {
+		fmt.Println("Not connecting without a terminal to confirm on.")
+		return false
+	}
+	fmt.Print("Type the host name to connect anyway: ")
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(response) == hostName
+}
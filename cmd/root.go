@@ -3,16 +3,23 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"strings"
 	"syscall"
-	"time"
 
 	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/hooks"
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/metrics"
+	"github.com/xvertile/sshc/internal/sshenv"
+	"github.com/xvertile/sshc/internal/sshver"
+	"github.com/xvertile/sshc/internal/termtitle"
 	"github.com/xvertile/sshc/internal/ui"
+	"github.com/xvertile/sshc/internal/validation"
 	"github.com/xvertile/sshc/internal/version"
 
 	"github.com/spf13/cobra"
@@ -24,6 +31,18 @@ var AppVersion = "dev"
 // configFile holds the path to the SSH config file
 var configFile string
 
+// dryRun shows the changes commands would make instead of writing them
+var dryRun bool
+
+// debugOverlay opens the TUI with the debug overlay
+var debugOverlay bool
+
+// logFile and logLevel turn on the debug log, see setupLogging
+var (
+	logFile  string
+	logLevel string
+)
+
 // RootCmd is the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "sshc [host]",
@@ -67,6 +86,7 @@ func runInteractiveMode() {
 	}
 
 	if err != nil {
+		sshclog.Error("exit", "err", err)
 		log.Fatalf("Error reading SSH config file: %v", err)
 	}
 
@@ -96,13 +116,27 @@ func runInteractiveMode() {
 		}
 	}
 
-	// Run the interactive TUI
+	// Run the interactive TUI, which opens the debug overlay while metrics are collected
+	metrics.SetEnabled(debugOverlay)
 	if err := ui.RunInteractiveMode(hosts, configFile, AppVersion); err != nil {
+		sshclog.Error("exit", "err", err)
 		log.Fatalf("Error running interactive mode: %v", err)
 	}
 }
 
 func connectToHost(hostName string) {
+	// An ssh:// URI without a user or port may name a configured host
+	if validation.IsURI(hostName) {
+		uri, err := validation.ParseSSHURI(hostName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if uri.User == "" && uri.Port == "" {
+			hostName = uri.Hostname
+		}
+	}
+
 	// Quick check if host exists without full parsing (optimized for connection)
 	var hostFound bool
 	var err error
@@ -118,8 +152,18 @@ func connectToHost(hostName string) {
 	}
 
 	if !hostFound {
-		fmt.Printf("Error: Host '%s' not found in SSH configuration.\n", hostName)
-		fmt.Println("Use 'sshc' to see available hosts.")
+		if !stdinIsTerminal() {
+			fmt.Printf("Error: Host '%s' not found in SSH configuration.\n", hostName)
+			fmt.Println("Use 'sshc' to see available hosts.")
+			os.Exit(1)
+		}
+		hostName = promptUnknownHost(hostName)
+		if hostName == "" {
+			return
+		}
+	}
+
+	if !confirmMaintenance(hostName) {
 		os.Exit(1)
 	}
 
@@ -129,6 +173,15 @@ func connectToHost(hostName string) {
 		// Log the error but don't prevent the connection
 		fmt.Printf("Warning: Could not initialize connection history: %v\n", err)
 	} else {
+		if appConfig, _ := config.LoadAppConfig(); appConfig != nil {
+			historyManager.SetJournal(appConfig.HistoryJournal)
+		}
+		if moved := historyManager.RecoveredFrom(); moved != "" {
+			fmt.Printf("Warning: Connection history was corrupt and has been reset; the old file is at %s\n", moved)
+		}
+		if !confirmConnectRate(hostName, historyManager) {
+			os.Exit(1)
+		}
 		err = historyManager.RecordConnection(hostName)
 		if err != nil {
 			// Log the error but don't prevent the connection
@@ -136,21 +189,65 @@ func connectToHost(hostName string) {
 		}
 	}
 
-	// Build and execute the SSH command
-	fmt.Printf("Connecting to %s...\n", hostName)
+	// Note: We don't add RemoteCommand here because if it's configured in SSH config,
+	// SSH will handle it automatically. Adding it as a command line argument would conflict.
+	runSSH(hooks.Target{Host: hostName}, []string{hostName})
+}
 
-	var sshCmd *exec.Cmd
-	var args []string
+// hostEnvPlan decides how a host's environment overrides are passed. ssh -V is
+// only run when there are overrides.
+func hostEnvPlan(env map[string]string) []sshenv.Override {
+	if len(env) == 0 {
+		return nil
+	}
+	v, err := sshver.Detect()
+	return sshenv.Plan(env, v, err == nil)
+}
 
+// fillTargetFromConfig adds the HostName and User of a configured host to a hook target
+func fillTargetFromConfig(target *hooks.Target) {
+	var host *config.SSHHost
+	var err error
 	if configFile != "" {
-		args = append(args, "-F", configFile)
+		host, err = config.GetSSHHostFromFile(target.Host, configFile)
+	} else {
+		host, err = config.GetSSHHost(target.Host)
+	}
+	if err == nil {
+		target.Hostname = host.Hostname
+		target.User = host.User
 	}
-	args = append(args, hostName)
+}
 
-	// Note: We don't add RemoteCommand here because if it's configured in SSH config,
-	// SSH will handle it automatically. Adding it as a command line argument would conflict.
+// runSSH runs ssh with the given destination arguments, surrounded by the connect
+// hooks, and exits with its status on failure
+func runSSH(target hooks.Target, destination []string) {
+	appConfig, _ := config.LoadAppConfig()
+	connectHooks := hooks.FromAppConfig(appConfig)
+	titleTemplate := termtitle.Template(appConfig)
+	if (connectHooks.Enabled() || titleTemplate != "") && target.Hostname == "" {
+		// Only parse the full config when a hook or the title needs the details
+		fillTargetFromConfig(&target)
+	}
 
-	sshCmd = exec.Command("ssh", args...)
+	if err := connectHooks.RunPre(target, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Not connecting to %s: %v\n", target.Host, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Connecting to %s...\n", target.Host)
+
+	var args []string
+	if configFile != "" {
+		args = append(args, "-F", configFile)
+	}
+	envPlan := hostEnvPlan(appConfig.HostEnvFor(target.Host))
+	args = append(args, sshenv.Args(sshenv.MergeConfigSetEnv(envPlan, target.Host, configFile))...)
+	args = append(args, destination...)
+
+	sshCmd := exec.Command("ssh", args...)
+	sshCmd.Env = sshenv.Environ(envPlan, os.Environ())
+	sshclog.Exec(sshCmd.Args)
 
 	// Set up the command to use the same stdin, stdout, and stderr as the parent process
 	sshCmd.Stdin = os.Stdin
@@ -158,7 +255,24 @@ func connectToHost(hostName string) {
 	sshCmd.Stderr = os.Stderr
 
 	// Execute the SSH command
-	err = sshCmd.Run()
+	title := termtitle.Format(titleTemplate, target.Host, target.Hostname, target.User)
+	if titleTemplate != "" {
+		termtitle.Set(os.Stdout, title)
+	}
+	err := sshCmd.Run()
+	if titleTemplate != "" {
+		termtitle.Restore(os.Stdout)
+	}
+	if postErr := connectHooks.RunPost(target, hooks.ExitCode(err), os.Stdout); postErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", postErr)
+	}
+	if hooks.ExitCode(err) == hooks.SSHErrorExitCode {
+		// Failed attempts let "sshc lint" suggest keepalive settings
+		if historyManager, histErr := history.NewHistoryManager(); histErr == nil {
+			historyManager.SetJournal(appConfig != nil && appConfig.HistoryJournal)
+			historyManager.RecordConnectFailure(target.Host)
+		}
+	}
 	if err != nil {
 		if exitError, ok := err.(*exec.ExitError); ok {
 			// SSH command failed, exit with the same code
@@ -175,11 +289,13 @@ func connectToHost(hostName string) {
 func getVersionWithUpdateCheck() string {
 	versionText := fmt.Sprintf("sshc version %s", AppVersion)
 
-	// Check for updates
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Check for updates, within the configured http_timeout
+	appConfig, _ := config.LoadAppConfig()
+	opts := version.HTTPOptionsFromAppConfig(appConfig)
+	ctx, cancel := context.WithTimeout(context.Background(), opts.RequestTimeout())
 	defer cancel()
 
-	updateInfo, err := version.CheckForUpdates(ctx, AppVersion)
+	updateInfo, err := version.CheckForUpdates(ctx, AppVersion, opts)
 	if err != nil {
 		// Return just version if check fails
 		return versionText + "\n"
@@ -195,8 +311,61 @@ func getVersionWithUpdateCheck() string {
 	return versionText + "\n"
 }
 
+// setupLogging opens the log file given with --log-file or SSHC_LOG. A log that
+// cannot be opened is reported but doesn't stop sshc.
+func setupLogging() {
+	path := logFile
+	if path == "" {
+		path = os.Getenv(sshclog.EnvFile)
+	}
+	if path == "" {
+		return
+	}
+	levelName := logLevel
+	if levelName == "" {
+		levelName = os.Getenv(sshclog.EnvLevel)
+	}
+	level, err := sshclog.ParseLevel(levelName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := sshclog.Open(path, level); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	sshclog.Info("start", "version", AppVersion, "args", strings.Join(sshclog.RedactArgs(os.Args[1:]), " "))
+}
+
+// printDryRunChanges prints the changes a dry run did not write as unified diffs
+func printDryRunChanges(w io.Writer) {
+	changes := config.TakeDryRunChanges()
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Dry run, nothing was written. The changes would have been:")
+	for _, change := range changes {
+		if !change.Changed() {
+			continue
+		}
+		fmt.Fprintln(w, change.Diff())
+	}
+}
+
+// changeMessage returns done, the message reporting a change to the config, or
+// would, the one saying what would have changed, during a dry run
+func changeMessage(done, would string) string {
+	if config.IsDryRun() {
+		return would
+	}
+	return done
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
-func Execute() {
+// It returns the exit code, leaving the exit to main so deferred output is printed.
+func Execute() int {
+	defer sshclog.Recover()
+	defer printDryRunChanges(os.Stdout)
+
 	// Custom error handling for unknown commands that might be host names
 	if err := RootCmd.Execute(); err != nil {
 		// Check if this is an "unknown command" error and the argument might be a host name
@@ -208,18 +377,32 @@ func Execute() {
 				potentialHost := parts[1]
 				// Try to connect to this as a host
 				connectToHost(potentialHost)
-				return
+				return 0
 			}
 		}
+		sshclog.Error("exit", "err", err)
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }
 
 func init() {
 	// Add the config file flag
 	RootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "SSH config file to use (default: ~/.ssh/config)")
+	RootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show the changes to config files as diffs instead of writing them")
+	RootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write a debug log to this file (or set SSHC_LOG)")
+	RootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Level of the debug log: debug, info, warn or error (default: info, or SSHC_LOG_LEVEL)")
+	cobra.OnInitialize(func() { config.SetDryRun(dryRun) }, setupLogging)
+	RootCmd.Flags().BoolVar(&debugOverlay, "debug", false, "Open the TUI with the debug overlay (F12) showing parse and render timings")
 
 	// Set custom version template with update check
 	RootCmd.SetVersionTemplate(getVersionWithUpdateCheck())
+
+	// Write tags as Tag directives when the ssh client understands them. ssh -V only
+	// runs the first time a host with tags is written.
+	config.SetNativeTagsCheck(func() bool {
+		v, err := sshver.Detect()
+		return err == nil && v.SupportsNativeTags()
+	})
 }
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -143,3 +147,41 @@ func TestVersionVariable(t *testing.T) {
 		t.Logf("AppVersion is set to '%s' (expected 'dev' for development)", AppVersion)
 	}
 }
+
+func TestDryRunPrintsDiff(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	if RootCmd.PersistentFlags().Lookup("dry-run") == nil {
+		t.Fatal("Expected --dry-run flag to be defined")
+	}
+
+	path := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(path, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config.SetDryRun(true)
+	defer config.SetDryRun(false)
+	if err := config.AddSSHHostToFile(config.SSHHost{Name: "db", Hostname: "db.example.com"}, path); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	printDryRunChanges(&out)
+	if !strings.Contains(out.String(), "Dry run") || !strings.Contains(out.String(), "+Host db") {
+		t.Errorf("Expected a dry-run diff adding db, got:\n%s", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\n") {
+		t.Errorf("Expected the diff to end with a newline, got %q", out.String())
+	}
+	if got := changeMessage("Imported", "Would import"); got != "Would import" {
+		t.Errorf("changeMessage() = %q during a dry run", got)
+	}
+	if content, _ := os.ReadFile(path); strings.Contains(string(content), "db") {
+		t.Errorf("Expected the config to be left alone, got:\n%s", content)
+	}
+}
@@ -17,6 +17,10 @@ var (
 	tagsOnly bool
 	// namesOnly limits search to host names only
 	namesOnly bool
+	// searchTags keeps only hosts with these tags
+	searchTags []string
+	// searchAnyTag keeps hosts with any of searchTags instead of all of them
+	searchAnyTag bool
 )
 
 var searchCmd = &cobra.Command{
@@ -29,6 +33,8 @@ Examples:
   sshc search web          # Search for hosts containing "web"
   sshc search --tags dev   # Search only in tags for "dev"
   sshc search --names prod # Search only in host names for "prod"
+  sshc search --tag prod --tag web  # List hosts tagged both prod and web
+  sshc search --tag eu --tag us --any-tag  # List hosts tagged eu or us
   sshc search --format json server # Output results in JSON format`,
 	Args: cobra.MaximumNArgs(1),
 	Run:  runSearch,
@@ -63,6 +69,9 @@ func runSearch(cmd *cobra.Command, args []string) {
 
 	// Filter hosts based on search criteria
 	filteredHosts := filterHosts(hosts, query, tagsOnly, namesOnly)
+	if len(searchTags) > 0 {
+		filteredHosts = config.FilterHostsByTags(filteredHosts, searchTags, !searchAnyTag)
+	}
 
 	// Display results
 	if len(filteredHosts) == 0 {
@@ -109,6 +118,11 @@ func filterHosts(hosts []config.SSHHost, query string, tagsOnly, namesOnly bool)
 			if !namesOnly && !matched && strings.Contains(strings.ToLower(host.Hostname), query) {
 				matched = true
 			}
+
+			// Check the description if not names-only
+			if !namesOnly && !matched && strings.Contains(strings.ToLower(host.Description), query) {
+				matched = true
+			}
 		}
 
 		// Search in tags if not names-only
@@ -206,6 +220,7 @@ func outputJSON(hosts []config.SSHHost) {
 		fmt.Printf("    \"identity\": \"%s\",\n", escapeJSON(host.Identity))
 		fmt.Printf("    \"proxy_jump\": \"%s\",\n", escapeJSON(host.ProxyJump))
 		fmt.Printf("    \"options\": \"%s\",\n", escapeJSON(host.Options))
+		fmt.Printf("    \"description\": \"%s\",\n", escapeJSON(host.Description))
 		fmt.Printf("    \"tags\": [")
 		for j, tag := range host.Tags {
 			fmt.Printf("\"%s\"", escapeJSON(tag))
@@ -241,4 +256,6 @@ func init() {
 	searchCmd.Flags().StringVarP(&outputFormat, "format", "f", "table", "Output format (table, json, simple)")
 	searchCmd.Flags().BoolVar(&tagsOnly, "tags", false, "Search only in tags")
 	searchCmd.Flags().BoolVar(&namesOnly, "names", false, "Search only in host names")
+	searchCmd.Flags().StringArrayVar(&searchTags, "tag", nil, "Only list hosts with this tag, ignoring case (repeatable, all must match)")
+	searchCmd.Flags().BoolVar(&searchAnyTag, "any-tag", false, "With several --tag, list hosts with any of them")
 }
@@ -68,6 +68,11 @@ func TestSearchCommandFlags(t *testing.T) {
 	if namesFlag == nil {
 		t.Error("Expected --names flag to be defined")
 	}
+
+	// Check tag filter flags
+	if flags.Lookup("tag") == nil || flags.Lookup("any-tag") == nil {
+		t.Error("Expected --tag and --any-tag flags to be defined")
+	}
 }
 
 func TestSearchCommandHelp(t *testing.T) {
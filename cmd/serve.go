@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/share"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen string
+	serveToken  string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Share your host list read-only with colleagues on the network",
+	Long: `Serve the names, hostnames, users, ports, tags and descriptions of your hosts as JSON,
+so a colleague can browse them with "sshc browse". Keys, options, jump hosts and commands are
+never shared. Requests must carry the token; without --token a random one is generated and
+printed. The config is read again for every request.
+
+Examples:
+  sshc serve
+  sshc serve --listen 192.168.1.10:7843 --token pairing`,
+	Args: cobra.NoArgs,
+	Run:  runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	token := serveToken
+	if token == "" {
+		var err error
+		if token, err = generateShareToken(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating a token: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	load := func() ([]config.SSHHost, error) {
+		if configFile != "" {
+			return config.ParseSSHConfigFile(configFile)
+		}
+		return config.ParseSSHConfig()
+	}
+	if _, err := load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	server := share.NewServer(serveListen, share.NewHandler(load, token))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+
+	fmt.Printf("Sharing hosts on %s (Ctrl+C to stop)\n", serveListen)
+	fmt.Printf("Browse them with: sshc browse http://<this-machine>%s --token %s\n", portOf(serveListen), token)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+		fmt.Println("Stopped sharing")
+	}
+}
+
+// generateShareToken returns a random token for "sshc serve"
+func generateShareToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// portOf returns the ":port" part of a listen address such as ":7843" or "0.0.0.0:7843"
+func portOf(addr string) string {
+	if _, port, err := net.SplitHostPort(addr); err == nil {
+		return ":" + port
+	}
+	return ""
+}
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", share.DefaultAddr, "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Token clients must send (default: a random one, printed at start)")
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotNote       string
+	snapshotRestoreYes bool
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore the whole SSH setup",
+	Long: `Snapshots archive the SSH config with every file it includes, and the files in the sshc
+config directory (app config, snippets, Kubernetes hosts, the new file template), in a tar.gz
+under the backup directory. Private keys are never included.`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Take a snapshot of the SSH setup",
+	Long: `Take a snapshot of the SSH config selected with -c (default: ~/.ssh/config), the files it
+includes and the sshc config directory.
+
+Examples:
+  sshc snapshot create
+  sshc snapshot create --note "before migration"`,
+	Args: cobra.NoArgs,
+	Run:  runSnapshotCreate,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List snapshots, newest first",
+	Args:  cobra.NoArgs,
+	Run:   runSnapshotList,
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore every file of a snapshot",
+	Long: `Write every file of a snapshot back to where it was, after confirmation. Directories that
+no longer exist are created with mode 0700, and files that are not in the snapshot are left
+alone. A snapshot of the current state is taken first, so a restore can be undone by
+restoring that one.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSnapshotRestore,
+}
+
+func runSnapshotCreate(cmd *cobra.Command, args []string) {
+	snapshot, err := config.CreateSnapshot(configFile, snapshotNote)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if snapshot.Path == "" {
+		fmt.Printf("Dry run, would take a snapshot of %d files\n", len(snapshot.Files))
+		return
+	}
+	fmt.Printf("Snapshot %s: %d files in %s\n", snapshot.ID, len(snapshot.Files), snapshot.Path)
+}
+
+func runSnapshotList(cmd *cobra.Command, args []string) {
+	snapshots, err := config.ListSnapshots()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(snapshots) == 0 {
+		fmt.Println("No snapshots yet. Take one with \"sshc snapshot create\".")
+		return
+	}
+	writeSnapshotList(os.Stdout, snapshots)
+}
+
+// writeSnapshotList prints one line per snapshot with its ID, date, file count and note
+func writeSnapshotList(w io.Writer, snapshots []config.Snapshot) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCREATED\tFILES\tNOTE")
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", snapshot.ID, snapshot.Created.Format("2006-01-02 15:04"), len(snapshot.Files), snapshot.Note)
+	}
+	tw.Flush()
+}
+
+func runSnapshotRestore(cmd *cobra.Command, args []string) {
+	snapshot, err := config.FindSnapshot(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Snapshot %s from %s", snapshot.ID, snapshot.Created.Format("2006-01-02 15:04"))
+	if snapshot.Note != "" {
+		fmt.Printf(" (%s)", snapshot.Note)
+	}
+	fmt.Printf(" has %d files:\n", len(snapshot.Files))
+	for _, name := range snapshot.Files {
+		fmt.Printf("  %s\n", name)
+	}
+
+	if !snapshotRestoreYes && !config.IsDryRun() {
+		if !stdinIsTerminal() {
+			fmt.Fprintln(os.Stderr, "Error: use --yes to restore without a terminal")
+			os.Exit(1)
+		}
+		fmt.Print("Overwrite the current files with these? [y/N]: ")
+		response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !strings.EqualFold(strings.TrimSpace(response), "y") {
+			fmt.Println("Nothing restored.")
+			return
+		}
+	}
+
+	restored, safety, err := config.RestoreSnapshot(snapshot, configFile)
+	if safety != nil && safety.Path != "" {
+		fmt.Printf("Saved the previous state as snapshot %s\n", safety.ID)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf(changeMessage("Restored %d files\n", "Would restore %d files\n"), len(restored))
+}
+
+func init() {
+	RootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd, snapshotListCmd, snapshotRestoreCmd)
+
+	snapshotCreateCmd.Flags().StringVar(&snapshotNote, "note", "", "Note to keep with the snapshot")
+	snapshotRestoreCmd.Flags().BoolVarP(&snapshotRestoreYes, "yes", "y", false, "Restore without asking")
+}
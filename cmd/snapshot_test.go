@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func TestWriteSnapshotList(t *testing.T) {
+	var out strings.Builder
+	writeSnapshotList(&out, []config.Snapshot{
+		{ID: "20260309-150405", Created: time.Date(2026, 3, 9, 15, 4, 5, 0, time.UTC), Note: "before migration", Files: []string{"home/.ssh/config", "home/.ssh/conf.d/work"}},
+		{ID: "20260301-090000", Created: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), Files: []string{"home/.ssh/config"}},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and two snapshots, got:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[1]); len(fields) < 6 || fields[0] != "20260309-150405" || fields[3] != "2" || !strings.HasSuffix(lines[1], "before migration") {
+		t.Errorf("Unexpected line %q", lines[1])
+	}
+}
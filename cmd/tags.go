@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshver"
+
+	"github.com/spf13/cobra"
+)
+
+// tagsMigrateForce migrates even when the ssh client is too old for Tag directives
+var tagsMigrateForce bool
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Manage host tags",
+}
+
+var tagsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Convert \"# Tags:\" comments into native Tag directives",
+	Long: `Rewrite the "# Tags:" comment above each host as Tag directives inside its block,
+in the main config and every included file. Tag directives need OpenSSH 9.4 or newer;
+older clients reject the config, so the migration refuses to run with one unless --force is given.
+Tags containing spaces or quotes stay in the comment.`,
+	Args: cobra.NoArgs,
+	Run:  runTagsMigrate,
+}
+
+func runTagsMigrate(cmd *cobra.Command, args []string) {
+	if !tagsMigrateForce {
+		v, err := sshver.Detect()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: could not determine the ssh version: %v\nUse --force to migrate anyway.\n", err)
+			os.Exit(1)
+		}
+		if !v.SupportsNativeTags() {
+			fmt.Fprintf(os.Stderr, "Error: %s does not support Tag directives (OpenSSH 9.4 or newer is needed).\nUse --force to migrate anyway.\n", v)
+			os.Exit(1)
+		}
+	}
+
+	var files []string
+	var err error
+	if configFile != "" {
+		files, err = config.GetAllConfigFilesFromBase(configFile)
+	} else {
+		files, err = config.GetAllConfigFiles()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding config files: %v\n", err)
+		os.Exit(1)
+	}
+	files = config.WritableConfigFiles(files)
+	sort.Strings(files)
+
+	total := 0
+	for _, file := range files {
+		migrated, err := config.MigrateTagComments(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error migrating %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		for _, host := range migrated {
+			fmt.Printf("%s: %s\n", file, host)
+		}
+		total += len(migrated)
+	}
+
+	if total == 0 {
+		fmt.Println("No tag comments to migrate.")
+		return
+	}
+	fmt.Printf(changeMessage("Migrated the tags of %d hosts.\n", "Would migrate the tags of %d hosts.\n"), total)
+}
+
+func init() {
+	RootCmd.AddCommand(tagsCmd)
+	tagsCmd.AddCommand(tagsMigrateCmd)
+
+	tagsMigrateCmd.Flags().BoolVar(&tagsMigrateForce, "force", false, "Migrate even if the local ssh client does not support Tag directives")
+}
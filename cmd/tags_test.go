@@ -0,0 +1,20 @@
+package cmd
+
+import "testing"
+
+func TestTagsCommandRegistration(t *testing.T) {
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "tags" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Tags command not found in root command")
+	}
+
+	if tagsMigrateCmd.Flags().Lookup("force") == nil {
+		t.Error("Expected --force flag to be defined")
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/hooks"
+	"github.com/xvertile/sshc/internal/ui"
+	"github.com/xvertile/sshc/internal/validation"
+)
+
+// hostArgument is a host given on the command line as [user@]host[:port]
+type hostArgument struct {
+	User     string
+	Hostname string
+	Port     string
+}
+
+// parseHostArgument splits a [user@]host[:port] argument or an ssh:// URI. IPv6
+// addresses take a port only when bracketed, as in [2001:db8::1]:2222.
+func parseHostArgument(arg string) hostArgument {
+	var target hostArgument
+	if uri, err := validation.ParseSSHURI(arg); err == nil {
+		return hostArgument{User: uri.User, Hostname: uri.Hostname, Port: uri.Port}
+	}
+
+	host := arg
+	if i := strings.LastIndex(arg, "@"); i > 0 {
+		target.User = arg[:i]
+		host = arg[i+1:]
+	}
+
+	if strings.HasPrefix(host, "[") {
+		if end := strings.Index(host, "]"); end > 0 {
+			rest := host[end+1:]
+			host = host[1:end]
+			if port, ok := strings.CutPrefix(rest, ":"); ok && isPort(port) {
+				target.Port = port
+			}
+		}
+	} else if strings.Count(host, ":") == 1 {
+		name, port, _ := strings.Cut(host, ":")
+		if isPort(port) {
+			host = name
+			target.Port = port
+		}
+	}
+
+	target.Hostname = host
+	return target
+}
+
+// isPort reports whether s is a valid TCP port number
+func isPort(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// isAddress reports whether the host is an IP address or a fully qualified domain name
+func (a hostArgument) isAddress() bool {
+	host, _, _ := strings.Cut(a.Hostname, "%") // IPv6 zone
+	return net.ParseIP(host) != nil || strings.Contains(a.Hostname, ".")
+}
+
+// newHost returns the host to pre-fill the add form with. The hostname is only
+// filled in when the argument clearly names a machine rather than an alias.
+func (a hostArgument) newHost() config.SSHHost {
+	host := config.SSHHost{Name: a.Hostname, User: a.User, Port: a.Port}
+	if a.isAddress() || a.User != "" || a.Port != "" {
+		host.Hostname = a.Hostname
+	}
+	return host
+}
+
+// sshArgs returns the ssh arguments that reach the host without a config entry
+func (a hostArgument) sshArgs() []string {
+	var args []string
+	if a.Port != "" {
+		args = append(args, "-p", a.Port)
+	}
+	destination := a.Hostname
+	if a.User != "" {
+		destination = a.User + "@" + destination
+	}
+	return append(args, destination)
+}
+
+// stdinIsTerminal reports whether sshc can prompt the user
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// promptUnknownHost offers to connect to a host missing from the SSH config directly
+// or to add it first. It returns the configured host to connect to, or "" when done.
+func promptUnknownHost(arg string) string {
+	target := parseHostArgument(arg)
+
+	fmt.Printf("Host '%s' is not in your SSH configuration.\n", arg)
+	fmt.Printf("  [c] Connect anyway (ssh %s)\n", strings.Join(target.sshArgs(), " "))
+	fmt.Println("  [a] Add it to your config, then connect")
+	fmt.Println("  [q] Quit")
+	fmt.Print("Choice [c/a/Q]: ")
+
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "c":
+		runSSH(hooks.Target{Host: arg, Hostname: target.Hostname, User: target.User}, target.sshArgs())
+		return ""
+	case "a":
+		name, err := ui.RunAddFormForHost(target.newHost(), configFile)
+		if err != nil {
+			fmt.Printf("Error adding host: %v\n", err)
+			os.Exit(1)
+		}
+		if name == "" {
+			fmt.Println("Host not added.")
+		}
+		return name
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseHostArgument(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    hostArgument
+		address bool
+	}{
+		{"web", hostArgument{Hostname: "web"}, false},
+		{"web.example.com", hostArgument{Hostname: "web.example.com"}, true},
+		{"deploy@web", hostArgument{User: "deploy", Hostname: "web"}, false},
+		{"10.0.0.5:2222", hostArgument{Hostname: "10.0.0.5", Port: "2222"}, true},
+		{"deploy@10.0.0.5:2222", hostArgument{User: "deploy", Hostname: "10.0.0.5", Port: "2222"}, true},
+		{"web:notaport", hostArgument{Hostname: "web:notaport"}, false},
+		{"web:70000", hostArgument{Hostname: "web:70000"}, false},
+		{"a@b@host", hostArgument{User: "a@b", Hostname: "host"}, false},
+
+		// IPv6
+		{"::1", hostArgument{Hostname: "::1"}, true},
+		{"2001:db8::1", hostArgument{Hostname: "2001:db8::1"}, true},
+		{"root@2001:db8::1", hostArgument{User: "root", Hostname: "2001:db8::1"}, true},
+		{"[2001:db8::1]", hostArgument{Hostname: "2001:db8::1"}, true},
+		{"[2001:db8::1]:2222", hostArgument{Hostname: "2001:db8::1", Port: "2222"}, true},
+		{"root@[::1]:22", hostArgument{User: "root", Hostname: "::1", Port: "22"}, true},
+		{"fe80::1%eth0", hostArgument{Hostname: "fe80::1%eth0"}, true},
+
+		// ssh:// URIs
+		{"ssh://deploy@10.0.0.5:2222", hostArgument{User: "deploy", Hostname: "10.0.0.5", Port: "2222"}, true},
+		{"ssh://root@[2001:db8::1]", hostArgument{User: "root", Hostname: "2001:db8::1"}, true},
+		{"ssh://git@gitlab.example.com/group/repo.git", hostArgument{User: "git", Hostname: "gitlab.example.com"}, true},
+	}
+
+	for _, tt := range tests {
+		got := parseHostArgument(tt.arg)
+		if got != tt.want {
+			t.Errorf("parseHostArgument(%q) = %+v, want %+v", tt.arg, got, tt.want)
+		}
+		if got.isAddress() != tt.address {
+			t.Errorf("parseHostArgument(%q).isAddress() = %v, want %v", tt.arg, got.isAddress(), tt.address)
+		}
+	}
+}
+
+func TestHostArgumentNewHost(t *testing.T) {
+	// A bare name is probably an alias, so only the name is filled in
+	if host := parseHostArgument("web").newHost(); host.Name != "web" || host.Hostname != "" {
+		t.Errorf("Unexpected host for a bare name: %+v", host)
+	}
+
+	host := parseHostArgument("deploy@[2001:db8::1]:2222").newHost()
+	if host.Name != "2001:db8::1" || host.Hostname != "2001:db8::1" || host.User != "deploy" || host.Port != "2222" {
+		t.Errorf("Unexpected host for a full address: %+v", host)
+	}
+}
+
+func TestHostArgumentSSHArgs(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want []string
+	}{
+		{"web", []string{"web"}},
+		{"deploy@10.0.0.5:2222", []string{"-p", "2222", "deploy@10.0.0.5"}},
+		{"[2001:db8::1]:2222", []string{"-p", "2222", "2001:db8::1"}},
+		{"root@2001:db8::1", []string{"root@2001:db8::1"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseHostArgument(tt.arg).sshArgs(); !slices.Equal(got, tt.want) {
+			t.Errorf("sshArgs(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
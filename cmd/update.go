@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"time"
 
+	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/version"
 
 	"github.com/spf13/cobra"
@@ -34,11 +34,13 @@ Examples:
 func runUpdate(cmd *cobra.Command, args []string) {
 	fmt.Println("Checking for updates...")
 
-	// Check for updates
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Check for updates, within the configured http_timeout
+	appConfig, _ := config.LoadAppConfig()
+	opts := version.HTTPOptionsFromAppConfig(appConfig)
+	ctx, cancel := context.WithTimeout(context.Background(), opts.RequestTimeout())
 	defer cancel()
 
-	updateInfo, err := version.CheckForUpdates(ctx, AppVersion)
+	updateInfo, err := version.CheckForUpdates(ctx, AppVersion, opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error checking for updates: %v\n", err)
 		os.Exit(1)
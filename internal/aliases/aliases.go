@@ -0,0 +1,193 @@
+// Package aliases generates shell aliases that connect to SSH hosts, for shell
+// rc files
+package aliases
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/shellquote"
+	"github.com/xvertile/sshc/internal/validation"
+)
+
+// Shells the aliases can be written for
+const (
+	ShellBash = "bash" // alias definitions, also read by zsh and sh
+	ShellFish = "fish" // abbr definitions
+)
+
+// Options selects the hosts and the form of the aliases
+type Options struct {
+	Prefix string // Prepended to every alias name, e.g. "ssh-"
+	Tag    string // Only hosts with this tag, "" for all
+
+	// Config file passed with -F to reach the hosts ssh does not read by
+	// default, "" when the hosts come from the default config
+	ConfigFile string
+	// Files ssh reads without -F: the default config and its Includes
+	DefaultFiles []string
+	// Home directory, written as ~ in paths so the aliases work for the same user
+	// on other machines
+	Home string
+}
+
+// Alias is the definition of one alias
+type Alias struct {
+	Name     string   // Name of the alias, sanitized
+	HostName string   // Host it connects to
+	Original string   // Name the alias was sanitized from, "" when it is the host name
+	Args     []string // ssh arguments
+}
+
+// validName matches the alias names both shells accept without quoting
+var validName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// Build returns the aliases of hosts, sorted by name. Wildcard patterns and names
+// ssh would take for an option are left out, and a name defined twice keeps its
+// first block as ssh does.
+func Build(hosts []config.SSHHost, opts Options) ([]Alias, error) {
+	if opts.Prefix != "" && !validName.MatchString(opts.Prefix) {
+		return nil, fmt.Errorf("invalid prefix %q: use letters, digits, _, . and -", opts.Prefix)
+	}
+	if opts.Tag != "" {
+		hosts = config.FilterHostsByTags(hosts, []string{opts.Tag}, false)
+	}
+
+	var aliases []Alias
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if seen[host.Name] || validation.IsHostPattern(host.Name) || strings.HasPrefix(host.Name, "-") {
+			continue
+		}
+		seen[host.Name] = true
+
+		alias := Alias{Name: opts.Prefix + host.Name, HostName: host.Name}
+		if !validName.MatchString(alias.Name) {
+			alias.Original = alias.Name
+			alias.Name = sanitizeName(alias.Name)
+		}
+		alias.Args = append(configArgs(host, opts), host.Name)
+		aliases = append(aliases, alias)
+	}
+
+	// A host whose name needed no change keeps it when a sanitized name collides
+	// with it, other collisions are numbered in order
+	sort.SliceStable(aliases, func(i, j int) bool {
+		a, b := aliases[i], aliases[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if (a.Original == "") != (b.Original == "") {
+			return a.Original == ""
+		}
+		return a.HostName < b.HostName
+	})
+	used := make(map[string]bool)
+	for i := range aliases {
+		name := aliases[i].Name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", aliases[i].Name, n)
+		}
+		if name != aliases[i].Name {
+			if aliases[i].Original == "" {
+				aliases[i].Original = aliases[i].Name
+			}
+			aliases[i].Name = name
+		}
+		used[name] = true
+	}
+	sort.SliceStable(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases, nil
+}
+
+// sanitizeName replaces the characters an alias name cannot hold with _
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		case (r == '.' || r == '-') && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// configArgs returns -F and the config file when ssh would not find the host in
+// its default config
+func configArgs(host config.SSHHost, opts Options) []string {
+	if host.SourceFile == "" || slices.Contains(opts.DefaultFiles, host.SourceFile) {
+		return nil
+	}
+	configFile := opts.ConfigFile
+	if configFile == "" {
+		configFile = host.SourceFile
+	}
+	return []string{"-F", tildePath(configFile, opts.Home)}
+}
+
+// tildePath writes a path below home with a leading ~
+func tildePath(path, home string) string {
+	if home != "" && strings.HasPrefix(path, home+"/") {
+		return "~" + strings.TrimPrefix(path, home)
+	}
+	return path
+}
+
+// Render writes aliases as definitions for shell
+func Render(aliases []Alias, shell string) (string, error) {
+	var quote func(string) string
+	var define string
+	switch shell {
+	case ShellBash:
+		quote, define = shellquote.Quote, "alias %s=%s\n"
+	case ShellFish:
+		quote, define = fishQuote, "abbr --add %s %s\n"
+	default:
+		return "", fmt.Errorf("unsupported shell %q: use %q or %q", shell, ShellBash, ShellFish)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# SSH aliases generated by sshc export-aliases\n")
+	for _, alias := range aliases {
+		if alias.Original != "" {
+			fmt.Fprintf(&b, "# %s: sanitized from %q\n", alias.Name, alias.Original)
+		}
+		words := []string{"ssh"}
+		for _, arg := range alias.Args {
+			words = append(words, quoteWord(arg, quote))
+		}
+		fmt.Fprintf(&b, define, alias.Name, quote(strings.Join(words, " ")))
+	}
+	return b.String(), nil
+}
+
+// safeWord matches words both shells read as they are
+var safeWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// quoteWord quotes an argument for the command the alias runs, leaving a
+// leading ~/ unquoted so the shell still expands it
+func quoteWord(word string, quote func(string) string) string {
+	prefix := ""
+	if strings.HasPrefix(word, "~/") {
+		prefix, word = "~/", word[2:]
+	}
+	if safeWord.MatchString(word) {
+		return prefix + word
+	}
+	return prefix + quote(word)
+}
+
+// fishQuote quotes s as a single fish word, where \ and ' are escaped inside
+// single quotes
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,113 @@
+package aliases
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares output with testdata/name
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Output does not match %s:\n%s", path, got)
+	}
+}
+
+func testHosts() []config.SSHHost {
+	return []config.SSHHost{
+		{Name: "web2", Tags: []string{"prod"}, SourceFile: "/home/me/.ssh/config"},
+		{Name: "prod-db", Tags: []string{"prod"}, SourceFile: "/home/me/.ssh/work.conf"},
+		{Name: "db.replica", Tags: []string{"prod"}, SourceFile: "/home/me/.ssh/conf.d/db"},
+		{Name: "*.internal", SourceFile: "/home/me/.ssh/config"},
+		{Name: "it's", SourceFile: "/home/me/.ssh/config"},
+		{Name: "web:1", Tags: []string{"prod"}, SourceFile: "/home/me/.ssh/config"},
+		{Name: "web_1", SourceFile: "/home/me/.ssh/config"},
+		{Name: "-lab", SourceFile: "/home/me/.ssh/config"},
+		{Name: "lab", SourceFile: "/srv/shared configs/lab's.conf"},
+		{Name: "web2", SourceFile: "/home/me/.ssh/config"},
+	}
+}
+
+func testOptions() Options {
+	return Options{
+		DefaultFiles: []string{"/home/me/.ssh/config", "/home/me/.ssh/conf.d/db"},
+		Home:         "/home/me",
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	aliases, err := Build(testHosts(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, shell := range []string{ShellBash, ShellFish} {
+		t.Run(shell, func(t *testing.T) {
+			out, err := Render(aliases, shell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, "aliases."+shell+".golden", out)
+		})
+	}
+}
+
+func TestBuildPrefixAndTag(t *testing.T) {
+	opts := testOptions()
+	opts.Prefix = "ssh-"
+	opts.Tag = "prod"
+	aliases, err := Build(testHosts(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, alias := range aliases {
+		names = append(names, alias.Name)
+	}
+	want := []string{"ssh-db.replica", "ssh-prod-db", "ssh-web2", "ssh-web_1"}
+	if len(names) != len(want) {
+		t.Fatalf("names = %q, want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("names = %q, want %q", names, want)
+			break
+		}
+	}
+	if aliases[3].Original != "ssh-web:1" {
+		t.Errorf("Original = %q, want the unsanitized name", aliases[3].Original)
+	}
+
+	// Hosts read through -c are reached with that config, not their own file
+	opts.ConfigFile = "/home/me/.ssh/root.conf"
+	aliases, err = Build(testHosts(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aliases[1].Args; len(got) != 3 || got[1] != "~/.ssh/root.conf" {
+		t.Errorf("prod-db args = %q, want -F ~/.ssh/root.conf", got)
+	}
+
+	opts.Prefix = "s sh"
+	if _, err := Build(testHosts(), opts); err == nil {
+		t.Error("Expected an error for a prefix with a space")
+	}
+	if _, err := Render(nil, "tcsh"); err == nil {
+		t.Error("Expected an error for an unsupported shell")
+	}
+}
@@ -0,0 +1,135 @@
+// Package browser opens the web pages kept for hosts in the default browser,
+// directly or through a local port forward
+package browser
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+// OpenCommand returns the program and arguments that open rawURL in the default
+// browser on goos. Windows uses the URL handler of rundll32 rather than start,
+// which cmd would split at every & of a query.
+func OpenCommand(goos, rawURL string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		return "open", []string{rawURL}, nil
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", rawURL}, nil
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly", "solaris", "illumos":
+		return "xdg-open", []string{rawURL}, nil
+	}
+	return "", nil, fmt.Errorf("opening a browser is not supported on %s", goos)
+}
+
+// Open opens rawURL in the default browser without waiting for it
+func Open(rawURL string) error {
+	name, args, err := OpenCommand(runtime.GOOS, rawURL)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(name, args...)
+	sshclog.Exec(cmd.Args)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// Target is the host a URL template is filled in for
+type Target struct {
+	Host     string // Name in the SSH config
+	Hostname string // Address, the name when the host sets none
+	User     string
+}
+
+// Expand fills the {host}, {hostname} and {user} placeholders of a URL template.
+// A template without a scheme gets http://.
+func Expand(template string, target Target) string {
+	if target.Hostname == "" {
+		target.Hostname = target.Host
+	}
+	expanded := strings.NewReplacer("{host}", target.Host, "{hostname}", target.Hostname, "{user}", target.User).
+		Replace(strings.TrimSpace(template))
+	if !strings.Contains(expanded, "://") {
+		expanded = "http://" + expanded
+	}
+	return expanded
+}
+
+// Forward is a local port forward: connections to BindAddress:LocalPort reach
+// RemoteHost:RemotePort from the SSH host
+type Forward struct {
+	BindAddress string
+	LocalPort   string
+	RemoteHost  string
+	RemotePort  string
+}
+
+// Arg returns the forward as the value of ssh -L
+func (f Forward) Arg() string {
+	if f.BindAddress != "" {
+		return fmt.Sprintf("%s:%s:%s:%s", f.BindAddress, f.LocalPort, f.RemoteHost, f.RemotePort)
+	}
+	return fmt.Sprintf("%s:%s:%s", f.LocalPort, f.RemoteHost, f.RemotePort)
+}
+
+// LocalAddress returns where the forward listens, for dialing it
+func (f Forward) LocalAddress() string {
+	host := f.BindAddress
+	if host == "" || host == "*" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, f.LocalPort)
+}
+
+// ThroughForward returns rawURL rewritten to go through forward, and whether the
+// forward leads to the URL's host and port. A forward to the loopback of the SSH
+// host serves URLs naming the host's address, hostname, as well as loopback ones.
+func ThroughForward(rawURL string, forward Forward, hostname string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || forward.LocalPort == "" {
+		return "", false
+	}
+	if urlPort(u) != forward.RemotePort {
+		return "", false
+	}
+	host := u.Hostname()
+	if !strings.EqualFold(host, forward.RemoteHost) &&
+		!(isLoopback(forward.RemoteHost) && (isLoopback(host) || strings.EqualFold(host, hostname))) {
+		return "", false
+	}
+
+	u.Host = forward.LocalAddress()
+	return u.String(), true
+}
+
+// urlPort returns the port of u, the default of its scheme when it names none
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	}
+	return ""
+}
+
+// isLoopback reports whether host names the machine itself
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
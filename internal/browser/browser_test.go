@@ -0,0 +1,97 @@
+package browser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOpenCommand(t *testing.T) {
+	const url = "http://nas:5000/?a=1&b=2"
+	tests := []struct {
+		goos string
+		name string
+		args []string
+	}{
+		{"linux", "xdg-open", []string{url}},
+		{"freebsd", "xdg-open", []string{url}},
+		{"darwin", "open", []string{url}},
+		{"windows", "rundll32", []string{"url.dll,FileProtocolHandler", url}},
+	}
+	for _, tt := range tests {
+		name, args, err := OpenCommand(tt.goos, url)
+		if err != nil || name != tt.name || !slices.Equal(args, tt.args) {
+			t.Errorf("OpenCommand(%q) = %q %q, %v, want %q %q", tt.goos, name, args, err, tt.name, tt.args)
+		}
+	}
+	if _, _, err := OpenCommand("plan9", url); err == nil {
+		t.Error("OpenCommand(plan9) did not fail")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	target := Target{Host: "nas", Hostname: "10.0.0.5", User: "admin"}
+	tests := []struct {
+		template string
+		target   Target
+		want     string
+	}{
+		{"https://{hostname}:5001", target, "https://10.0.0.5:5001"},
+		{"{host}.lan:8080/{user}", target, "http://nas.lan:8080/admin"},
+		{"  http://{hostname}/  ", Target{Host: "web"}, "http://web/"},
+	}
+	for _, tt := range tests {
+		if got := Expand(tt.template, tt.target); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestForward(t *testing.T) {
+	f := Forward{LocalPort: "8080", RemoteHost: "localhost", RemotePort: "80"}
+	if got := f.Arg(); got != "8080:localhost:80" {
+		t.Errorf("Arg() = %q", got)
+	}
+	if got := f.LocalAddress(); got != "localhost:8080" {
+		t.Errorf("LocalAddress() = %q", got)
+	}
+
+	f.BindAddress = "127.0.0.2"
+	if got := f.Arg(); got != "127.0.0.2:8080:localhost:80" {
+		t.Errorf("Arg() = %q", got)
+	}
+	if got := f.LocalAddress(); got != "127.0.0.2:8080" {
+		t.Errorf("LocalAddress() = %q", got)
+	}
+
+	f.BindAddress = "*"
+	if got := f.LocalAddress(); got != "localhost:8080" {
+		t.Errorf("LocalAddress() with a wildcard bind = %q", got)
+	}
+}
+
+func TestThroughForward(t *testing.T) {
+	loopback := Forward{LocalPort: "9000", RemoteHost: "localhost", RemotePort: "3000"}
+	internal := Forward{LocalPort: "8443", RemoteHost: "grafana.internal", RemotePort: "443"}
+	tests := []struct {
+		url     string
+		forward Forward
+		want    string
+		ok      bool
+	}{
+		{"http://localhost:3000/d/abc?x=1", loopback, "http://localhost:9000/d/abc?x=1", true},
+		{"http://127.0.0.1:3000/", loopback, "http://localhost:9000/", true},
+		{"http://10.0.0.5:3000/", loopback, "http://localhost:9000/", true}, // The SSH host's address
+		{"http://other:3000/", loopback, "", false},
+		{"http://localhost:3001/", loopback, "", false},
+		{"https://grafana.internal/login", internal, "https://localhost:8443/login", true},
+		{"http://grafana.internal/login", internal, "", false}, // Port 80
+		{"https://GRAFANA.internal:443/", internal, "https://localhost:8443/", true},
+		{"not a url", internal, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ThroughForward(tt.url, tt.forward, "10.0.0.5")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ThroughForward(%q, %+v) = %q, %v, want %q, %v", tt.url, tt.forward, got, ok, tt.want, tt.ok)
+		}
+	}
+}
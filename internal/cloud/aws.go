@@ -0,0 +1,120 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// AWSTag is the tag every host imported from AWS gets
+const AWSTag = "aws"
+
+// awsOutput is the part of "aws ec2 describe-instances --output json" that is read
+type awsOutput struct {
+	Reservations []struct {
+		Instances []awsInstance `json:"Instances"`
+	} `json:"Reservations"`
+}
+
+type awsInstance struct {
+	InstanceID       string `json:"InstanceId"`
+	KeyName          string `json:"KeyName"`
+	PublicIPAddress  string `json:"PublicIpAddress"`
+	PrivateIPAddress string `json:"PrivateIpAddress"`
+	State            struct {
+		Name string `json:"Name"`
+	} `json:"State"`
+	Tags []struct {
+		Key   string `json:"Key"`
+		Value string `json:"Value"`
+	} `json:"Tags"`
+}
+
+// AWSArgs returns the arguments of "aws ec2 describe-instances" for a region and
+// filters such as "tag:Project=acme" or "instance-type=t3.micro". An empty region
+// leaves it to the CLI's configuration.
+func AWSArgs(region string, filters []string) ([]string, error) {
+	args := []string{"ec2", "describe-instances", "--output", "json"}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+	if len(filters) > 0 {
+		args = append(args, "--filters")
+		for _, filter := range filters {
+			name, values, ok := strings.Cut(filter, "=")
+			if !ok || name == "" || values == "" {
+				return nil, fmt.Errorf("invalid filter %q: expected name=value, such as tag:Project=acme", filter)
+			}
+			args = append(args, fmt.Sprintf("Name=%s,Values=%s", name, values))
+		}
+	}
+	return args, nil
+}
+
+// ListAWS runs the aws CLI and returns the hosts of the instances it lists
+func ListAWS(ctx context.Context, region string, filters []string, opts Options) ([]config.SSHHost, []Skipped, error) {
+	args, err := AWSArgs(region, filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := runCLI(ctx, "aws", args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParseAWS(output, opts)
+}
+
+// ParseAWS turns the output of "aws ec2 describe-instances --output json" into
+// hosts. Instances are named after their Name tag, or their ID without one, and
+// use the key pair they were launched with as ~/.ssh/<key pair>.pem. Instances
+// that are not running or have no address are skipped.
+func ParseAWS(data []byte, opts Options) ([]config.SSHHost, []Skipped, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil, errNoOutput
+	}
+	var output awsOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse aws output: %w", err)
+	}
+
+	var hosts []config.SSHHost
+	var skipped []Skipped
+	for _, reservation := range output.Reservations {
+		for _, instance := range reservation.Instances {
+			if instance.State.Name != "running" {
+				skipped = append(skipped, Skipped{ID: instance.InstanceID, Reason: "not running (" + instance.State.Name + ")"})
+				continue
+			}
+
+			name := instance.InstanceID
+			var tags []string
+			for _, tag := range instance.Tags {
+				switch {
+				case tag.Key == "Name":
+					if strings.TrimSpace(tag.Value) != "" {
+						name = tag.Value
+					}
+				case strings.HasPrefix(tag.Key, "aws:"):
+					// Tags AWS sets itself, such as aws:autoscaling:groupName
+				default:
+					tags = append(tags, proposedTag(tag.Key, tag.Value))
+				}
+			}
+
+			host, ok := newHost(name, instance.PublicIPAddress, instance.PrivateIPAddress, AWSTag, tags, opts)
+			if !ok {
+				skipped = append(skipped, Skipped{ID: instance.InstanceID, Reason: "no address to connect to"})
+				continue
+			}
+			if instance.KeyName != "" {
+				host.Identity = "~/.ssh/" + instance.KeyName + ".pem"
+			}
+			hosts = append(hosts, host)
+		}
+	}
+	uniqueNames(hosts)
+	return hosts, skipped, nil
+}
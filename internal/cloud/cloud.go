@@ -0,0 +1,116 @@
+// Package cloud turns the instances listed by the aws and gcloud CLIs into SSH
+// hosts. It reads the JSON the CLIs print, so no SDK or credentials are needed
+// beyond what the CLIs already use.
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/validation"
+)
+
+// Options controls how instances become hosts
+type Options struct {
+	PrivateIP bool   // Connect to the private address even when there is a public one
+	User      string // Login user of every host; unset keeps what the instance suggests
+}
+
+// Skipped is an instance that was not turned into a host
+type Skipped struct {
+	ID     string
+	Reason string
+}
+
+func (s Skipped) String() string {
+	return fmt.Sprintf("%s: %s", s.ID, s.Reason)
+}
+
+// runCLI runs a cloud CLI and returns its standard output; tests replace it
+var runCLI = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("the %s CLI is not installed or not in PATH", name)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s failed: %s", name, message)
+		}
+		return nil, fmt.Errorf("%s failed: %w", name, err)
+	}
+	return output, nil
+}
+
+// hostName turns an instance name into a host name ssh accepts: whitespace and
+// characters that are special in ssh config become dashes
+func hostName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" \t\n\r#,*?![]\"'", r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if len(name) > 50 {
+		name = name[:50]
+	}
+	return name
+}
+
+// proposedTag turns a cloud tag or label into an sshc tag, such as "project=acme".
+// Tags are written comma separated, so commas and whitespace become dashes.
+func proposedTag(key, value string) string {
+	tag := strings.ToLower(key)
+	if value != "" {
+		tag += "=" + value
+	}
+	return strings.Map(func(r rune) rune {
+		if r == ',' || r == ' ' || r == '\t' || r == '\n' {
+			return '-'
+		}
+		return r
+	}, tag)
+}
+
+// uniqueNames appends -2, -3 and so on to hosts sharing a name, in order
+func uniqueNames(hosts []config.SSHHost) {
+	seen := make(map[string]int)
+	for i := range hosts {
+		name := hosts[i].Name
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			hosts[i].Name = fmt.Sprintf("%s-%d", name, n)
+		}
+	}
+}
+
+// newHost builds the host of an instance, picking its address per opts and
+// tagging it with the provider followed by its cloud tags. ok is false when the
+// instance has no address to connect to.
+func newHost(name, publicIP, privateIP, provider string, cloudTags []string, opts Options) (config.SSHHost, bool) {
+	address := publicIP
+	if opts.PrivateIP || address == "" {
+		address = privateIP
+	}
+	if address == "" || !validation.ValidateHostname(address) {
+		return config.SSHHost{}, false
+	}
+	sort.Strings(cloudTags)
+	return config.SSHHost{
+		Name:     hostName(name),
+		Hostname: address,
+		User:     opts.User,
+		Tags:     append([]string{provider}, cloudTags...),
+	}, true
+}
+
+// errNoOutput is returned when a CLI printed nothing where JSON was expected
+var errNoOutput = errors.New("the CLI printed nothing")
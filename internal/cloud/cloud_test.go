@@ -0,0 +1,126 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseAWS(t *testing.T) {
+	hosts, skipped, err := ParseAWS(readFixture(t, "aws-describe-instances.json"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []config.SSHHost{
+		{Name: "acme-web", Hostname: "34.245.10.11", Identity: "~/.ssh/acme-deploy.pem", Tags: []string{"aws", "env=prod", "project=acme"}},
+		{Name: "acme-web-2", Hostname: "34.245.10.12", Identity: "~/.ssh/acme-deploy.pem", Tags: []string{"aws", "project=acme"}},
+		{Name: "i-0c3d4e5f607182930", Hostname: "10.20.2.40", Tags: []string{"aws", "project=acme"}},
+	}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts =\n%+v\nwant\n%+v", hosts, want)
+	}
+
+	wantSkipped := []Skipped{
+		{ID: "i-0d4e5f60718293a41", Reason: "not running (stopped)"},
+		{ID: "i-0e5f60718293a4b52", Reason: "no address to connect to"},
+	}
+	if !slices.Equal(skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+}
+
+func TestParseAWSPrivateAndUser(t *testing.T) {
+	hosts, _, err := ParseAWS(readFixture(t, "aws-describe-instances.json"), Options{PrivateIP: true, User: "ec2-user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 3 || hosts[0].Hostname != "10.20.1.15" || hosts[0].User != "ec2-user" {
+		t.Errorf("first host = %+v, want the private address and ec2-user", hosts[0])
+	}
+}
+
+func TestParseGcloud(t *testing.T) {
+	hosts, skipped, err := ParseGcloud(readFixture(t, "gcloud-instances-list.json"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []config.SSHHost{
+		{Name: "api-1", Hostname: "35.195.20.7", User: "alice", Tags: []string{"gcp", "env=prod", "team=platform"}},
+		{Name: "worker-1", Hostname: "10.132.0.6", Tags: []string{"gcp"}},
+	}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts =\n%+v\nwant\n%+v", hosts, want)
+	}
+	if wantSkipped := []Skipped{{ID: "old-api", Reason: "not running (terminated)"}}; !slices.Equal(skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+
+	// A user given on the command line wins over the one of the ssh-keys metadata
+	hosts, _, _ = ParseGcloud(readFixture(t, "gcloud-instances-list.json"), Options{User: "deploy", PrivateIP: true})
+	if hosts[0].User != "deploy" || hosts[0].Hostname != "10.132.0.5" {
+		t.Errorf("first host = %+v, want deploy at the private address", hosts[0])
+	}
+}
+
+func TestParseRejectsBadOutput(t *testing.T) {
+	if _, _, err := ParseAWS([]byte("  \n"), Options{}); !errors.Is(err, errNoOutput) {
+		t.Errorf("ParseAWS(empty) error = %v, want errNoOutput", err)
+	}
+	if _, _, err := ParseGcloud([]byte("Listed 0 items."), Options{}); err == nil {
+		t.Error("ParseGcloud(text) succeeded, want an error")
+	}
+}
+
+func TestAWSArgs(t *testing.T) {
+	args, err := AWSArgs("eu-west-1", []string{"tag:Project=acme", "instance-type=t3.micro,t3.small"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ec2", "describe-instances", "--output", "json", "--region", "eu-west-1",
+		"--filters", "Name=tag:Project,Values=acme", "Name=instance-type,Values=t3.micro,t3.small",
+	}
+	if !slices.Equal(args, want) {
+		t.Errorf("AWSArgs() = %v, want %v", args, want)
+	}
+	if _, err := AWSArgs("", []string{"tag:Project"}); err == nil {
+		t.Error("AWSArgs() accepted a filter without a value")
+	}
+}
+
+func TestListGcloudRunsCLI(t *testing.T) {
+	original := runCLI
+	defer func() { runCLI = original }()
+	var gotName string
+	var gotArgs []string
+	runCLI = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		gotName, gotArgs = name, args
+		return []byte("[]"), nil
+	}
+
+	hosts, skipped, err := ListGcloud(context.Background(), "acme-prod", "labels.env=prod", Options{})
+	if err != nil || len(hosts) != 0 || len(skipped) != 0 {
+		t.Fatalf("ListGcloud() = %v, %v, %v, want nothing", hosts, skipped, err)
+	}
+	want := []string{"compute", "instances", "list", "--format=json", "--project", "acme-prod", "--filter", "labels.env=prod"}
+	if gotName != "gcloud" || !slices.Equal(gotArgs, want) {
+		t.Errorf("ran %s %v, want gcloud %v", gotName, gotArgs, want)
+	}
+}
@@ -0,0 +1,123 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// GCPTag is the tag every host imported from Google Cloud gets
+const GCPTag = "gcp"
+
+// gcloudInstance is the part of an instance of "gcloud compute instances list
+// --format=json" that is read
+type gcloudInstance struct {
+	Name              string            `json:"name"`
+	Status            string            `json:"status"`
+	Labels            map[string]string `json:"labels"`
+	NetworkInterfaces []struct {
+		NetworkIP     string `json:"networkIP"`
+		AccessConfigs []struct {
+			NatIP string `json:"natIP"`
+		} `json:"accessConfigs"`
+	} `json:"networkInterfaces"`
+	Metadata struct {
+		Items []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"items"`
+	} `json:"metadata"`
+}
+
+// GcloudArgs returns the arguments of "gcloud compute instances list" for a
+// project and a gcloud filter expression, either of which may be empty
+func GcloudArgs(project, filter string) []string {
+	args := []string{"compute", "instances", "list", "--format=json"}
+	if project != "" {
+		args = append(args, "--project", project)
+	}
+	if filter != "" {
+		args = append(args, "--filter", filter)
+	}
+	return args
+}
+
+// ListGcloud runs the gcloud CLI and returns the hosts of the instances it lists
+func ListGcloud(ctx context.Context, project, filter string, opts Options) ([]config.SSHHost, []Skipped, error) {
+	output, err := runCLI(ctx, "gcloud", GcloudArgs(project, filter)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParseGcloud(output, opts)
+}
+
+// ParseGcloud turns the output of "gcloud compute instances list --format=json"
+// into hosts. The user of a host is the first one with a key in the instance's
+// ssh-keys metadata, unless opts sets one. Instances that are not running or have
+// no address are skipped.
+func ParseGcloud(data []byte, opts Options) ([]config.SSHHost, []Skipped, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil, errNoOutput
+	}
+	var instances []gcloudInstance
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse gcloud output: %w", err)
+	}
+
+	var hosts []config.SSHHost
+	var skipped []Skipped
+	for _, instance := range instances {
+		if instance.Status != "RUNNING" {
+			skipped = append(skipped, Skipped{ID: instance.Name, Reason: "not running (" + strings.ToLower(instance.Status) + ")"})
+			continue
+		}
+
+		var publicIP, privateIP string
+		for _, nic := range instance.NetworkInterfaces {
+			if privateIP == "" {
+				privateIP = nic.NetworkIP
+			}
+			for _, access := range nic.AccessConfigs {
+				if publicIP == "" {
+					publicIP = access.NatIP
+				}
+			}
+		}
+
+		var tags []string
+		for key, value := range instance.Labels {
+			tags = append(tags, proposedTag(key, value))
+		}
+
+		host, ok := newHost(instance.Name, publicIP, privateIP, GCPTag, tags, opts)
+		if !ok {
+			skipped = append(skipped, Skipped{ID: instance.Name, Reason: "no address to connect to"})
+			continue
+		}
+		if host.User == "" {
+			host.User = gcloudKeyUser(instance)
+		}
+		hosts = append(hosts, host)
+	}
+	uniqueNames(hosts)
+	return hosts, skipped, nil
+}
+
+// gcloudKeyUser returns the user of the first key in the ssh-keys metadata of an
+// instance, whose lines read "user:ssh-ed25519 AAAA... comment"
+func gcloudKeyUser(instance gcloudInstance) string {
+	for _, item := range instance.Metadata.Items {
+		if item.Key != "ssh-keys" {
+			continue
+		}
+		for _, line := range strings.Split(item.Value, "\n") {
+			if user, key, ok := strings.Cut(strings.TrimSpace(line), ":"); ok && user != "" && key != "" {
+				return user
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Algorithm presets. Legacy re-enables the algorithms old network gear and
+// appliances still need, which current OpenSSH turns off by default.
+const (
+	AlgorithmsDefault = "Default" // None of the algorithm directives are set
+	AlgorithmsLegacy  = "Legacy"
+	AlgorithmsCustom  = "Custom" // The directives are set, but not to the preset's values
+)
+
+// AlgorithmPresets lists the presets in the order a selector cycles through them
+var AlgorithmPresets = []string{AlgorithmsDefault, AlgorithmsLegacy}
+
+// AlgorithmKeywords are the algorithm directives, in the order they are written
+var AlgorithmKeywords = []string{"KexAlgorithms", "HostKeyAlgorithms", "PubkeyAcceptedAlgorithms", "Ciphers", "MACs"}
+
+// algorithmAliases maps the names older OpenSSH used to their current keyword
+var algorithmAliases = map[string]string{
+	"pubkeyacceptedkeytypes": "PubkeyAcceptedAlgorithms",
+}
+
+// presetKeywords are the names presets write a keyword under, where it differs.
+// OpenSSH before 8.5 rejects PubkeyAcceptedAlgorithms, while every version still
+// reads its old name.
+var presetKeywords = map[string]string{
+	"PubkeyAcceptedAlgorithms": "PubkeyAcceptedKeyTypes",
+}
+
+// legacyAlgorithmValues are the values of the Legacy preset, as documented by
+// OpenSSH for servers that only offer SHA-1 key exchange, RSA/SHA-1 signatures
+// and CBC ciphers. DSA is left out, recent OpenSSH is built without it.
+var legacyAlgorithmValues = AlgorithmSettings{
+	KexAlgorithms:            "+diffie-hellman-group1-sha1,diffie-hellman-group14-sha1",
+	HostKeyAlgorithms:        "+ssh-rsa",
+	PubkeyAcceptedAlgorithms: "+ssh-rsa",
+	Ciphers:                  "+aes128-cbc,3des-cbc",
+}
+
+// legacyAlgorithms are the algorithms the legacy lint flags, with the prefixes
+// that cover whole families
+var legacyAlgorithms = []string{
+	"diffie-hellman-group1-sha1", "diffie-hellman-group14-sha1", "diffie-hellman-group-exchange-sha1",
+	"ssh-rsa", "ssh-dss",
+	"3des-cbc", "aes128-cbc", "aes192-cbc", "aes256-cbc", "blowfish-cbc", "cast128-cbc", "arcfour",
+	"hmac-md5", "hmac-sha1-96",
+}
+
+// LegacyTag is the tag that marks a host as needing legacy algorithms
+const LegacyTag = "legacy"
+
+// AlgorithmSettings are the algorithm directives of a host, as written: a list
+// replacing the defaults, or one starting with +, - or ^ to append, remove or
+// put first. Empty when unset.
+type AlgorithmSettings struct {
+	KexAlgorithms            string
+	HostKeyAlgorithms        string
+	PubkeyAcceptedAlgorithms string
+	Ciphers                  string
+	MACs                     string
+}
+
+// field returns the setting of a keyword of AlgorithmKeywords
+func (s *AlgorithmSettings) field(keyword string) *string {
+	switch keyword {
+	case "KexAlgorithms":
+		return &s.KexAlgorithms
+	case "HostKeyAlgorithms":
+		return &s.HostKeyAlgorithms
+	case "PubkeyAcceptedAlgorithms":
+		return &s.PubkeyAcceptedAlgorithms
+	case "Ciphers":
+		return &s.Ciphers
+	case "MACs":
+		return &s.MACs
+	}
+	return nil
+}
+
+// Get returns the setting of an algorithm keyword
+func (s AlgorithmSettings) Get(keyword string) string {
+	if field := s.field(keyword); field != nil {
+		return *field
+	}
+	return ""
+}
+
+// IsZero reports whether none of the directives are set
+func (s AlgorithmSettings) IsZero() bool {
+	return s == AlgorithmSettings{}
+}
+
+// algorithmKeyword returns the canonical keyword of an algorithm directive in
+// any case, or "" when keyword is none
+func algorithmKeyword(keyword string) string {
+	for _, k := range AlgorithmKeywords {
+		if strings.EqualFold(k, keyword) {
+			return k
+		}
+	}
+	return algorithmAliases[strings.ToLower(keyword)]
+}
+
+// IsAlgorithmKeyword reports whether keyword is one of the algorithm directives
+func IsAlgorithmKeyword(keyword string) bool {
+	return algorithmKeyword(keyword) != ""
+}
+
+// ParseAlgorithmSettings reads the algorithm directives from options, one line per
+// option as stored in SSHHost.Options. As in ssh, the first value wins.
+func ParseAlgorithmSettings(options string) AlgorithmSettings {
+	var settings AlgorithmSettings
+	for _, line := range strings.Split(options, "\n") {
+		keyword, value := splitOption(line)
+		if field := settings.field(algorithmKeyword(keyword)); field != nil && *field == "" {
+			*field = value
+		}
+	}
+	return settings
+}
+
+// sameAlgorithmValue reports whether two values of a directive are the same: the
+// same prefix and the same algorithms in any order and case
+func sameAlgorithmValue(a, b string) bool {
+	prefix := func(s string) (byte, []string) {
+		var p byte
+		if s != "" && strings.ContainsRune("+-^", rune(s[0])) {
+			p, s = s[0], s[1:]
+		}
+		names := strings.Split(strings.ToLower(s), ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		slices.Sort(names)
+		return p, names
+	}
+	pa, na := prefix(a)
+	pb, nb := prefix(b)
+	return pa == pb && slices.Equal(na, nb)
+}
+
+// DetectAlgorithmPreset returns the preset options match: Default when none of
+// the algorithm directives are set, Legacy when they are set to its values, and
+// Custom otherwise
+func DetectAlgorithmPreset(options string) string {
+	settings := ParseAlgorithmSettings(options)
+	if settings.IsZero() {
+		return AlgorithmsDefault
+	}
+	for _, keyword := range AlgorithmKeywords {
+		got, want := settings.Get(keyword), legacyAlgorithmValues.Get(keyword)
+		if (got == "") != (want == "") || (got != "" && !sameAlgorithmValue(got, want)) {
+			return AlgorithmsCustom
+		}
+	}
+	return AlgorithmsLegacy
+}
+
+// ApplyAlgorithmPreset replaces the algorithm directives in options with those of
+// preset, keeping every other option. Default removes them, Custom leaves options as is.
+func ApplyAlgorithmPreset(options, preset string) string {
+	if preset == AlgorithmsCustom {
+		return options
+	}
+
+	var lines []string
+	for _, line := range strings.Split(options, "\n") {
+		keyword, _ := splitOption(line)
+		if strings.TrimSpace(line) == "" || IsAlgorithmKeyword(keyword) {
+			continue
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	if preset == AlgorithmsLegacy {
+		for _, keyword := range AlgorithmKeywords {
+			if value := legacyAlgorithmValues.Get(keyword); value != "" {
+				if written, ok := presetKeywords[keyword]; ok {
+					keyword = written
+				}
+				lines = append(lines, keyword+" "+value)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DescribeAlgorithmSettings lists the directives that are set, e.g.
+// "KexAlgorithms +diffie-hellman-group1-sha1, HostKeyAlgorithms +ssh-rsa"
+func DescribeAlgorithmSettings(settings AlgorithmSettings) string {
+	var parts []string
+	for _, keyword := range AlgorithmKeywords {
+		if value := settings.Get(keyword); value != "" {
+			parts = append(parts, keyword+" "+value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DescribeAlgorithmPreset lists the directives a preset sets
+func DescribeAlgorithmPreset(preset string) string {
+	if preset != AlgorithmsLegacy {
+		return ""
+	}
+	return DescribeAlgorithmSettings(legacyAlgorithmValues)
+}
+
+// EnabledLegacyAlgorithms returns the legacy algorithms the settings turn on. A
+// list removing algorithms with - turns none on.
+func EnabledLegacyAlgorithms(settings AlgorithmSettings) []string {
+	var enabled []string
+	for _, keyword := range AlgorithmKeywords {
+		value := settings.Get(keyword)
+		if value == "" || value[0] == '-' {
+			continue
+		}
+		for _, name := range strings.Split(strings.TrimLeft(value, "+^"), ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if isLegacyAlgorithm(name) && !slices.Contains(enabled, name) {
+				enabled = append(enabled, name)
+			}
+		}
+	}
+	return enabled
+}
+
+// isLegacyAlgorithm reports whether an algorithm name is one of legacyAlgorithms,
+// or a variant of one such as hmac-md5-etm@openssh.com or arcfour256
+func isLegacyAlgorithm(name string) bool {
+	for _, legacy := range legacyAlgorithms {
+		if name == legacy || strings.HasPrefix(name, legacy+"-") || strings.HasPrefix(name, legacy+"@") ||
+			(legacy == "arcfour" && strings.HasPrefix(name, legacy)) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintLegacyAlgorithms warns when a host turns on legacy algorithms without being
+// tagged as a legacy device, where they are more likely left over than needed
+func lintLegacyAlgorithms(host SSHHost) []string {
+	enabled := EnabledLegacyAlgorithms(ParseAlgorithmSettings(host.Options))
+	if len(enabled) == 0 || slices.ContainsFunc(host.Tags, func(tag string) bool { return strings.EqualFold(tag, LegacyTag) }) {
+		return nil
+	}
+	return []string{fmt.Sprintf("legacy algorithms are enabled (%s) but the host is not tagged %q",
+		strings.Join(enabled, ", "), LegacyTag)}
+}
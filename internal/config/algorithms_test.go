@@ -0,0 +1,111 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseAlgorithmSettings(t *testing.T) {
+	options := "Compression yes\nkexalgorithms +diffie-hellman-group1-sha1\nHostKeyAlgorithms=+ssh-rsa\nPubkeyAcceptedKeyTypes +ssh-rsa\nKexAlgorithms curve25519-sha256"
+	got := ParseAlgorithmSettings(options)
+	want := AlgorithmSettings{
+		KexAlgorithms:            "+diffie-hellman-group1-sha1",
+		HostKeyAlgorithms:        "+ssh-rsa",
+		PubkeyAcceptedAlgorithms: "+ssh-rsa",
+	}
+	if got != want {
+		t.Errorf("ParseAlgorithmSettings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDetectAlgorithmPreset(t *testing.T) {
+	legacy := "KexAlgorithms +diffie-hellman-group1-sha1,diffie-hellman-group14-sha1\nHostKeyAlgorithms +ssh-rsa\nPubkeyAcceptedAlgorithms +ssh-rsa\nCiphers +aes128-cbc,3des-cbc"
+	tests := []struct {
+		name    string
+		options string
+		want    string
+	}{
+		{"no options", "", AlgorithmsDefault},
+		{"unrelated options", "Compression yes\nServerAliveInterval 30", AlgorithmsDefault},
+		{"legacy", legacy, AlgorithmsLegacy},
+		{"legacy, other order and case", "ciphers +3DES-CBC,aes128-cbc\nCompression yes\nPubkeyAcceptedKeyTypes=+ssh-rsa\nHostKeyAlgorithms +ssh-rsa\nKexAlgorithms +diffie-hellman-group14-sha1,diffie-hellman-group1-sha1", AlgorithmsLegacy},
+		{"part of legacy", "KexAlgorithms +diffie-hellman-group1-sha1\nHostKeyAlgorithms +ssh-rsa", AlgorithmsCustom},
+		{"replacing list", strings.Replace(legacy, "HostKeyAlgorithms +ssh-rsa", "HostKeyAlgorithms ssh-rsa", 1), AlgorithmsCustom},
+		{"extra directive", legacy + "\nMACs +hmac-md5", AlgorithmsCustom},
+	}
+	for _, tt := range tests {
+		if got := DetectAlgorithmPreset(tt.options); got != tt.want {
+			t.Errorf("%s: DetectAlgorithmPreset() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyAlgorithmPreset(t *testing.T) {
+	options := "Compression yes\nKexAlgorithms +diffie-hellman-group1-sha1\nForwardAgent no"
+
+	legacy := ApplyAlgorithmPreset(options, AlgorithmsLegacy)
+	want := "Compression yes\nForwardAgent no\nKexAlgorithms +diffie-hellman-group1-sha1,diffie-hellman-group14-sha1\nHostKeyAlgorithms +ssh-rsa\nPubkeyAcceptedKeyTypes +ssh-rsa\nCiphers +aes128-cbc,3des-cbc"
+	if legacy != want {
+		t.Errorf("ApplyAlgorithmPreset(Legacy) = %q, want %q", legacy, want)
+	}
+	for _, preset := range AlgorithmPresets {
+		if got := DetectAlgorithmPreset(ApplyAlgorithmPreset(legacy, preset)); got != preset {
+			t.Errorf("DetectAlgorithmPreset(ApplyAlgorithmPreset(%s)) = %s", preset, got)
+		}
+	}
+	if got := ApplyAlgorithmPreset(legacy, AlgorithmsDefault); got != "Compression yes\nForwardAgent no" {
+		t.Errorf("ApplyAlgorithmPreset(Default) = %q", got)
+	}
+	if got := ApplyAlgorithmPreset(options, AlgorithmsCustom); got != options {
+		t.Errorf("ApplyAlgorithmPreset(Custom) = %q, want options unchanged", got)
+	}
+}
+
+func TestAlgorithmPresetRoundTripsThroughTheConfig(t *testing.T) {
+	configFile := filepath.Join(setupAuditTest(t), "config")
+	writeFile(t, configFile, "Host switch\n    HostName 10.0.0.2\n")
+	host := SSHHost{Name: "switch", Hostname: "10.0.0.2", Options: ApplyAlgorithmPreset("", AlgorithmsLegacy)}
+	if err := UpdateSSHHostInFile("switch", host, configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil || len(hosts) != 1 {
+		t.Fatalf("ParseSSHConfigFile() = %v, %v", hosts, err)
+	}
+	if got := DetectAlgorithmPreset(hosts[0].Options); got != AlgorithmsLegacy {
+		t.Errorf("preset after saving = %s, options %q", got, hosts[0].Options)
+	}
+}
+
+func TestEnabledLegacyAlgorithms(t *testing.T) {
+	settings := AlgorithmSettings{
+		KexAlgorithms:     "^diffie-hellman-group1-sha1,curve25519-sha256",
+		HostKeyAlgorithms: "-ssh-rsa",
+		Ciphers:           "aes256-ctr,arcfour256",
+		MACs:              "+hmac-md5-etm@openssh.com",
+	}
+	want := []string{"diffie-hellman-group1-sha1", "arcfour256", "hmac-md5-etm@openssh.com"}
+	if got := EnabledLegacyAlgorithms(settings); !slices.Equal(got, want) {
+		t.Errorf("EnabledLegacyAlgorithms() = %v, want %v", got, want)
+	}
+}
+
+func TestLintLegacyAlgorithms(t *testing.T) {
+	legacy := ApplyAlgorithmPreset("", AlgorithmsLegacy)
+	hosts := []SSHHost{
+		{Name: "switch", Options: legacy, Tags: []string{"network", "Legacy"}},
+		{Name: "web", Options: legacy, SourceFile: "/etc/ssh/config", Line: 7},
+		{Name: "modern", Options: "KexAlgorithms -diffie-hellman-group14-sha1"},
+	}
+
+	warnings := LintHosts(hosts)
+	if len(warnings) != 1 || warnings[0].Host != "web" {
+		t.Fatalf("Expected a warning for web only, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0].Message, "ssh-rsa") || !strings.Contains(warnings[0].Message, `tagged "legacy"`) {
+		t.Errorf("Unexpected warning message: %s", warnings[0].Message)
+	}
+}
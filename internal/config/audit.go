@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xvertile/sshc/internal/configtext"
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+// Operations recorded in the audit log
+const (
+	AuditAdd    = "add"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+	AuditMove   = "move"
+	AuditTag    = "tag"
+	AuditMerge  = "merge"
+)
+
+// auditMaxSize is the size at which the audit log is rolled over to audit.log.1
+const auditMaxSize = 5 * 1024 * 1024
+
+// auditContextLines is the number of unchanged lines shown around each change
+const auditContextLines = 3
+
+// AuditEntry is a single line in the audit log
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user,omitempty"`
+	Operation string    `json:"operation"`
+	Host      string    `json:"host"`
+	File      string    `json:"file"`
+	Diff      string    `json:"diff,omitempty"`
+}
+
+// auditMutex serializes appends and rotation of the audit log
+var auditMutex sync.Mutex
+
+// auditErrorHandler is called when an audit entry cannot be written
+var auditErrorHandler = func(err error) {
+	fmt.Fprintf(os.Stderr, "Warning: could not write audit log: %v\n", err)
+}
+
+// SetAuditErrorHandler replaces how audit log failures are reported.
+// Failing to audit never fails the modification itself.
+func SetAuditErrorHandler(handler func(error)) {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+	auditErrorHandler = handler
+}
+
+// GetAuditLogPath returns the path to the audit log
+func GetAuditLogPath() (string, error) {
+	// The rolled over log moves along with the current one
+	if _, err := StateFilePath("audit.log.1"); err != nil {
+		return "", err
+	}
+	return StateFilePath("audit.log")
+}
+
+// writeConfigFile writes a modified config file and records the change in the audit log.
+// A symlinked config file is written through to its target.
+func writeConfigFile(configPath, operation, hostName string, before []byte, after string) error {
+	configPath = resolveConfigPath(configPath)
+	if err := checkWritable(configPath, before); err != nil {
+		return err
+	}
+	if recordDryRun(ConfigChange{Path: configPath, Host: hostName, Before: string(before), After: after}) {
+		return nil
+	}
+	sshclog.Write(configPath, operation)
+	if err := writeFileRetrying(configPath, []byte(after), 0600); err != nil {
+		return err
+	}
+	recordAudit(operation, hostName, configPath, string(before), after)
+	return nil
+}
+
+// recordAudit appends an audit entry, reporting but not returning failures
+func recordAudit(operation, hostName, filePath, before, after string) {
+	diff := unifiedDiff(filePath, before, after)
+	if operation == AuditUpdate && onlyTagsChanged(diff) {
+		operation = AuditTag
+	}
+
+	entry := AuditEntry{
+		Time:      time.Now(),
+		Operation: operation,
+		Host:      hostName,
+		File:      filePath,
+		Diff:      diff,
+	}
+	if current, err := user.Current(); err == nil {
+		entry.User = current.Username
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+	if err := appendAuditEntry(entry); err != nil && auditErrorHandler != nil {
+		auditErrorHandler(err)
+	}
+}
+
+// appendAuditEntry writes one JSON line, rotating the log when it grows too large
+func appendAuditEntry(entry AuditEntry) error {
+	logPath, err := GetAuditLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(logPath); err == nil && info.Size() >= auditMaxSize {
+		if err := os.Rename(logPath, logPath+".1"); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// ReadAuditLog returns audit entries recorded at or after since, oldest first.
+// The rolled-over log is included.
+func ReadAuditLog(since time.Time) ([]AuditEntry, error) {
+	logPath, err := GetAuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []AuditEntry
+	for _, path := range []string{logPath + ".1", logPath} {
+		fileEntries, err := readAuditFile(path, since)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	return entries, nil
+}
+
+// readAuditFile reads the entries of one audit log file, skipping lines it cannot parse
+func readAuditFile(path string, since time.Time) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		var entry AuditEntry
+		if err := json.Unmarshal(line, &entry); err == nil && !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+		if readErr == io.EOF {
+			return entries, nil
+		}
+		if readErr != nil {
+			return entries, readErr
+		}
+	}
+}
+
+// onlyTagsChanged reports whether every changed line in a diff is a "# Tags:" comment
+// or a Tag directive
+func onlyTagsChanged(diff string) bool {
+	changed := false
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			if changedLine := strings.TrimSpace(line[1:]); !strings.HasPrefix(changedLine, configtext.TagsPrefix) && !configtext.IsTagDirective(changedLine) {
+				return false
+			}
+			changed = true
+		}
+	}
+	return changed
+}
+
+// unifiedDiff returns a unified diff of the changed region between two file contents
+func unifiedDiff(filePath, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	// Line endings are left out so CRLF files diff cleanly
+	var a, b []string
+	if before != "" {
+		a, _ = configtext.Split(before)
+	}
+	if after != "" {
+		b, _ = configtext.Split(after)
+	}
+
+	// Only the changed region needs a real diff; configs share long prefixes and suffixes
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	start := max(prefix-auditContextLines, 0)
+	aEnd := min(len(a)-suffix+auditContextLines, len(a))
+	bEnd := min(len(b)-suffix+auditContextLines, len(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", filePath, filePath)
+	fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", start+1, aEnd-start, start+1, bEnd-start)
+	for _, line := range diffLines(a[start:aEnd], b[start:bEnd]) {
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// diffLines returns the lines of a and b prefixed with " ", "-" or "+" using a longest common subsequence
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+"+b[j])
+	}
+	return lines
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupAuditTest points the sshc config dir at a temp directory
+func setupAuditTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+	return tempDir
+}
+
+func TestAuditRecordsModifications(t *testing.T) {
+	tempDir := setupAuditTest(t)
+
+	configFile := filepath.Join(tempDir, "config")
+	configContent := `Host web
+    HostName web.example.com
+    User deploy
+
+Host db
+    HostName db.example.com
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	if err := AddSSHHostToFile(SSHHost{Name: "cache", Hostname: "cache.example.com"}, configFile); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+	if err := UpdateSSHHostInFile("web", SSHHost{Name: "web", Hostname: "web.example.com", User: "deploy", Tags: []string{"prod"}}, configFile); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+	if err := UpdateSSHHostInFile("db", SSHHost{Name: "db", Hostname: "db2.example.com"}, configFile); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+	if err := DeleteSSHHostFromFile("cache", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+
+	entries, err := ReadAuditLog(time.Time{})
+	if err != nil {
+		t.Fatalf("ReadAuditLog() error = %v", err)
+	}
+
+	expected := []struct{ operation, host string }{
+		{AuditAdd, "cache"},
+		{AuditTag, "web"},
+		{AuditUpdate, "db"},
+		{AuditDelete, "cache"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d audit entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+	for i, want := range expected {
+		if entries[i].Operation != want.operation || entries[i].Host != want.host || entries[i].File != configFile {
+			t.Errorf("entry %d = %s %s %s, want %s %s", i, entries[i].Operation, entries[i].Host, entries[i].File, want.operation, want.host)
+		}
+	}
+
+	if !strings.Contains(entries[2].Diff, "-    HostName db.example.com") || !strings.Contains(entries[2].Diff, "+    HostName db2.example.com") {
+		t.Errorf("Expected the update diff to show the hostname change, got:\n%s", entries[2].Diff)
+	}
+	if strings.Contains(entries[0].Diff, "Host web") {
+		t.Errorf("Expected the add diff to be limited to the affected block, got:\n%s", entries[0].Diff)
+	}
+
+	if recent, err := ReadAuditLog(time.Now().Add(time.Hour)); err != nil || len(recent) != 0 {
+		t.Errorf("Expected no entries in the future, got %d (err %v)", len(recent), err)
+	}
+}
+
+func TestAuditRotation(t *testing.T) {
+	setupAuditTest(t)
+
+	logPath, err := GetAuditLogPath()
+	if err != nil {
+		t.Fatalf("GetAuditLogPath() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+
+	// A full log is rolled over, replacing any previous rolled file
+	if err := os.WriteFile(logPath+".1", []byte("old\n"), 0600); err != nil {
+		t.Fatalf("Failed to create rolled log: %v", err)
+	}
+	if err := os.WriteFile(logPath, []byte(strings.Repeat("x", auditMaxSize)), 0600); err != nil {
+		t.Fatalf("Failed to create full log: %v", err)
+	}
+
+	recordAudit(AuditAdd, "web", "/tmp/config", "", "Host web\n")
+
+	info, err := os.Stat(logPath)
+	if err != nil || info.Size() >= auditMaxSize {
+		t.Fatalf("Expected a fresh audit log after rotation, got %v (err %v)", info, err)
+	}
+	rolled, err := os.Stat(logPath + ".1")
+	if err != nil || rolled.Size() != auditMaxSize {
+		t.Errorf("Expected the full log to be kept as audit.log.1, got %v (err %v)", rolled, err)
+	}
+
+	entries, err := ReadAuditLog(time.Time{})
+	if err != nil {
+		t.Fatalf("ReadAuditLog() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Host != "web" {
+		t.Errorf("Expected one readable entry, got %+v", entries)
+	}
+}
+
+func TestAuditFailureIsNotFatal(t *testing.T) {
+	tempDir := setupAuditTest(t)
+
+	// Make the sshc config dir a file so the audit log cannot be created
+	if err := os.WriteFile(filepath.Join(tempDir, "sshc"), nil, 0600); err != nil {
+		t.Fatalf("Failed to block config dir: %v", err)
+	}
+
+	var reported error
+	previous := auditErrorHandler
+	SetAuditErrorHandler(func(err error) { reported = err })
+	defer SetAuditErrorHandler(previous)
+
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := writeConfigFile(configFile, AuditUpdate, "web", []byte("Host web\n"), "Host web\n    User root\n"); err != nil {
+		t.Fatalf("writeConfigFile() error = %v", err)
+	}
+
+	if reported == nil {
+		t.Error("Expected the audit failure to be reported")
+	}
+	content, _ := os.ReadFile(configFile)
+	if !strings.Contains(string(content), "User root") {
+		t.Error("Expected the config write to succeed despite the audit failure")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni"
+	after := "a\nb\nc\nd\nE\nf\ng\nh\ni"
+
+	diff := unifiedDiff("config", before, after)
+	expected := `--- config
++++ config
+@@ -2,7 +2,7 @@
+ b
+ c
+ d
+-e
++E
+ f
+ g
+ h`
+	if diff != expected {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", diff, expected)
+	}
+
+	if unifiedDiff("config", before, before) != "" {
+		t.Error("Expected no diff for identical content")
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/keys"
+	sshclog "github.com/xvertile/sshc/internal/log"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// bundleMagic starts every bundle file and is authenticated along with the contents
+const bundleMagic = "SSHC-BUNDLE-1\n"
+
+// Sizes and scrypt cost of the bundle encryption
+const (
+	bundleSaltSize = 16
+	bundleKeySize  = 32
+	bundleScryptN  = 1 << 15
+	bundleScryptR  = 8
+	bundleScryptP  = 1
+)
+
+// ErrBundlePassphrase is returned when a bundle cannot be decrypted, which is
+// almost always a wrong passphrase
+var ErrBundlePassphrase = errors.New("wrong passphrase or damaged bundle")
+
+// Bundle is a set of host definitions shared with someone else, with the public
+// keys their IdentityFiles refer to. It never contains private keys.
+type Bundle struct {
+	Version    int         `json:"version"`
+	Hosts      []SSHHost   `json:"hosts"`
+	PublicKeys []BundleKey `json:"public_keys,omitempty"`
+}
+
+// BundleKey is a public key file in a bundle
+type BundleKey struct {
+	Name string `json:"name"` // File name, e.g. "id_ed25519.pub"
+	Key  string `json:"key"`  // The key in authorized_keys format
+}
+
+// NewBundle puts hosts in a bundle. With withKeys, the public key of every
+// IdentityFile is added too; it is read from the .pub file next to the identity,
+// and anything that is not a public key is refused.
+func NewBundle(hosts []SSHHost, withKeys bool) (*Bundle, error) {
+	bundle := &Bundle{Version: exportFormatVersion, Hosts: hosts}
+	if !withKeys {
+		return bundle, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if host.Identity == "" {
+			continue
+		}
+		path := keys.PublicKeyPath(host.Identity)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		key, err := keys.ReadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("public key of host '%s': %w", host.Name, err)
+		}
+		bundle.PublicKeys = append(bundle.PublicKeys, BundleKey{Name: filepath.Base(path), Key: key})
+	}
+	return bundle, nil
+}
+
+// validate checks a decrypted bundle before anything of it is used
+func (b *Bundle) validate() error {
+	if b.Version > exportFormatVersion {
+		return fmt.Errorf("bundle version %d is newer than supported version %d", b.Version, exportFormatVersion)
+	}
+	for i, host := range b.Hosts {
+		if host.Name == "" {
+			return fmt.Errorf("host #%d has no name", i+1)
+		}
+	}
+	for _, key := range b.PublicKeys {
+		if key.Name != filepath.Base(key.Name) || strings.ContainsAny(key.Name, `/\`) || !strings.HasSuffix(key.Name, ".pub") {
+			return fmt.Errorf("public key %q: invalid file name", key.Name)
+		}
+		if _, err := keys.ParsePublicKey(key.Key); err != nil {
+			return fmt.Errorf("public key %s: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// SealBundle encodes a bundle and encrypts it with AES-GCM under a key derived
+// from passphrase with scrypt
+func SealBundle(b *Bundle, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("a passphrase is required")
+	}
+	plaintext, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, bundleSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := bundleCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(bundleMagic), salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte(bundleMagic)), nil
+}
+
+// OpenBundle decrypts a bundle written by SealBundle and checks its contents
+func OpenBundle(data, passphrase []byte) (*Bundle, error) {
+	if !bytes.HasPrefix(data, []byte(bundleMagic)) {
+		return nil, errors.New("not an sshc bundle")
+	}
+	data = data[len(bundleMagic):]
+	if len(data) < bundleSaltSize {
+		return nil, ErrBundlePassphrase
+	}
+	salt, data := data[:bundleSaltSize], data[bundleSaltSize:]
+
+	aead, err := bundleCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, ErrBundlePassphrase
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(bundleMagic))
+	if err != nil {
+		return nil, ErrBundlePassphrase
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(plaintext, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if err := bundle.validate(); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	return &bundle, nil
+}
+
+// bundleCipher derives the AES-GCM cipher of a bundle from the passphrase and salt
+func bundleCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, bundleScryptN, bundleScryptR, bundleScryptP, bundleKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// BundleConflicts returns the hosts of configFile (or the default SSH config when
+// empty) that have the same name as a host in the bundle, keyed by name
+func BundleConflicts(b *Bundle, configFile string) (map[string]SSHHost, error) {
+	return HostConflicts(b.Hosts, configFile)
+}
+
+// HostConflicts returns the hosts of configFile (or the default SSH config when
+// empty) that have the same name as one of hosts, keyed by name
+func HostConflicts(hosts []SSHHost, configFile string) (map[string]SSHHost, error) {
+	var existing []SSHHost
+	var err error
+	if configFile != "" {
+		existing, err = ParseSSHConfigFile(configFile)
+	} else {
+		existing, err = ParseSSHConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		names[host.Name] = true
+	}
+	conflicts := make(map[string]SSHHost)
+	for _, host := range existing {
+		if names[host.Name] {
+			conflicts[host.Name] = host
+		}
+	}
+	return conflicts, nil
+}
+
+// WriteBundleKeys saves the public keys of a bundle in dir. Existing files are
+// left alone and reported as skipped.
+func WriteBundleKeys(b *Bundle, dir string) (written, skipped []string, err error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, nil, err
+	}
+	for _, key := range b.PublicKeys {
+		path := filepath.Join(dir, key.Name)
+		sshclog.Write(path, "bundle key")
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			skipped = append(skipped, path)
+			continue
+		}
+		if err != nil {
+			return written, skipped, err
+		}
+		_, err = file.WriteString(key.Key + "\n")
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, skipped, err
+		}
+		written = append(written, path)
+	}
+	return written, skipped, nil
+}
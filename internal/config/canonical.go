@@ -0,0 +1,97 @@
+package config
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// defaultCanonicalizeMaxDots is ssh's default for CanonicalizeMaxDots
+const defaultCanonicalizeMaxDots = 1
+
+// setResolutionOption records the host key and canonicalization directives of a host
+func (h *SSHHost) setResolutionOption(key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	switch key {
+	case "hostkeyalias":
+		h.HostKeyAlias = values[0]
+	case "canonicalizehostname":
+		h.CanonicalizeHostname = strings.ToLower(values[0])
+	case "canonicaldomains":
+		h.CanonicalDomains = append([]string(nil), values...)
+	case "canonicalizemaxdots":
+		h.CanonicalizeMaxDots = values[0]
+	}
+}
+
+// targetHostname returns the HostName of the host, or its name when none is set
+func (h SSHHost) targetHostname() string {
+	if h.Hostname != "" {
+		return h.Hostname
+	}
+	return h.Name
+}
+
+// canonicalizes reports whether ssh rewrites the host's name using CanonicalDomains
+func (h SSHHost) canonicalizes() bool {
+	switch h.CanonicalizeHostname {
+	case "yes":
+		// ssh leaves proxied connections alone unless canonicalization is "always"
+		if h.ProxyJump != "" || h.ProxyCommand != "" {
+			return false
+		}
+	case "always":
+	default:
+		return false
+	}
+	if len(h.CanonicalDomains) == 0 {
+		return false
+	}
+
+	name := h.targetHostname()
+	if strings.HasSuffix(name, ".") || net.ParseIP(name) != nil {
+		return false
+	}
+
+	maxDots := defaultCanonicalizeMaxDots
+	if n, err := strconv.Atoi(h.CanonicalizeMaxDots); err == nil && n >= 0 {
+		maxDots = n
+	}
+	return strings.Count(name, ".") <= maxDots
+}
+
+// CanonicalHostnames returns the names ssh tries for the host, in order. With
+// canonicalization these are the name in each CanonicalDomains entry followed by
+// the name itself; otherwise it is just the hostname.
+func (h SSHHost) CanonicalHostnames() []string {
+	name := h.targetHostname()
+	if !h.canonicalizes() {
+		return []string{name}
+	}
+
+	names := make([]string, 0, len(h.CanonicalDomains)+1)
+	for _, domain := range h.CanonicalDomains {
+		names = append(names, name+"."+strings.Trim(domain, "."))
+	}
+	return append(names, name)
+}
+
+// CanonicalTarget returns the first name ssh tries for the host
+func (h SSHHost) CanonicalTarget() string {
+	return h.CanonicalHostnames()[0]
+}
+
+// KnownHostsName returns the name the host's key is stored under in known_hosts.
+// A HostKeyAlias is used as is; otherwise a port other than 22 is added in brackets.
+func (h SSHHost) KnownHostsName() string {
+	if h.HostKeyAlias != "" {
+		return h.HostKeyAlias
+	}
+	name := h.CanonicalTarget()
+	if h.Port == "" || h.Port == "22" {
+		return name
+	}
+	return "[" + name + "]:" + h.Port
+}
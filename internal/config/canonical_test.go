@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalHostnamesFromConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config")
+	content := `Host db
+    HostName db1
+    CanonicalizeHostname yes
+    CanonicalDomains internal.example.com example.org
+
+Host db-keyed
+    HostName db1
+    Port 2222
+    HostKeyAlias db-primary
+    CanonicalizeHostname yes
+    CanonicalDomains internal.example.com
+
+Host db-jump
+    HostName db1
+    ProxyJump bastion
+    CanonicalizeHostname yes
+    CanonicalDomains internal.example.com
+
+Host db-plain
+    HostName db1
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]SSHHost)
+	for _, host := range hosts {
+		byName[host.Name] = host
+	}
+
+	db := byName["db"]
+	want := []string{"db1.internal.example.com", "db1.example.org", "db1"}
+	if got := db.CanonicalHostnames(); !slices.Equal(got, want) {
+		t.Errorf("CanonicalHostnames() = %v, want %v", got, want)
+	}
+	if got := db.KnownHostsName(); got != "db1.internal.example.com" {
+		t.Errorf("KnownHostsName() = %q", got)
+	}
+	// The directives are still written back through Options
+	if !strings.Contains(db.Options, "CanonicalDomains internal.example.com example.org") {
+		t.Errorf("Expected CanonicalDomains to stay in Options, got %q", db.Options)
+	}
+
+	if got := byName["db-keyed"].KnownHostsName(); got != "db-primary" {
+		t.Errorf("Expected the HostKeyAlias to name the known_hosts entry, got %q", got)
+	}
+
+	// Canonicalization "yes" does not apply to proxied connections
+	if got := byName["db-jump"].CanonicalTarget(); got != "db1" {
+		t.Errorf("Expected proxied host to keep its name, got %q", got)
+	}
+	if got := byName["db-plain"].CanonicalHostnames(); !slices.Equal(got, []string{"db1"}) {
+		t.Errorf("Expected no canonicalization without the option, got %v", got)
+	}
+}
+
+func TestCanonicalizeMaxDots(t *testing.T) {
+	host := SSHHost{
+		Name:                 "web",
+		Hostname:             "web.eu",
+		CanonicalizeHostname: "always",
+		CanonicalDomains:     []string{"example.com"},
+	}
+	if got := host.CanonicalTarget(); got != "web.eu.example.com" {
+		t.Errorf("Expected one dot to be canonicalized by default, got %q", got)
+	}
+
+	host.CanonicalizeMaxDots = "0"
+	if got := host.CanonicalTarget(); got != "web.eu" {
+		t.Errorf("Expected CanonicalizeMaxDots 0 to skip dotted names, got %q", got)
+	}
+
+	host.Hostname = "10.0.0.1"
+	host.CanonicalizeMaxDots = "5"
+	if got := host.CanonicalTarget(); got != "10.0.0.1" {
+		t.Errorf("Expected addresses not to be canonicalized, got %q", got)
+	}
+
+	host.Hostname = "web.example.com."
+	if got := host.CanonicalTarget(); got != "web.example.com." {
+		t.Errorf("Expected fully qualified names not to be canonicalized, got %q", got)
+	}
+
+	host.Hostname = "db1"
+	host.Port = "2222"
+	if got := host.KnownHostsName(); got != "[db1.example.com]:2222" {
+		t.Errorf("Expected the port in the known_hosts name, got %q", got)
+	}
+}
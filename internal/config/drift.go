@@ -0,0 +1,351 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemSSHConfigPath is the system-wide config ssh reads after the user's own
+var SystemSSHConfigPath = "/etc/ssh/ssh_config"
+
+// sshGTimeout bounds a run of ssh -G, which only reads config files
+const sshGTimeout = 10 * time.Second
+
+// runSSHG runs ssh with args and returns its standard output; tests replace it
+var runSSHG = func(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "ssh", args...).Output()
+}
+
+// currentUsername returns the login name ssh uses when no User is set
+var currentUsername = func() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return ""
+}
+
+// ResolvedConfig is the configuration ssh -G prints for a host, as values by
+// lowercase keyword. Keywords ssh prints more than once keep every value.
+type ResolvedConfig map[string][]string
+
+// ParseSSHG reads the output of ssh -G. Lines that are not a keyword followed by
+// its value, such as warnings, are skipped; keywords sshc does not know are kept.
+func ParseSSHG(output string) ResolvedConfig {
+	resolved := make(ResolvedConfig)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		keyword, value, _ := strings.Cut(line, " ")
+		if !isSSHGKeyword(keyword) {
+			continue
+		}
+		keyword = strings.ToLower(keyword)
+		resolved[keyword] = append(resolved[keyword], strings.TrimSpace(value))
+	}
+	return resolved
+}
+
+// isSSHGKeyword reports whether s can be a keyword of ssh -G, which are letters
+// and digits only
+func isSSHGKeyword(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveWithSSH asks the local ssh for the configuration it would use for
+// hostName, reading configFile instead of the default configs when set
+func ResolveWithSSH(ctx context.Context, hostName, configFile string) (ResolvedConfig, error) {
+	ctx, cancel := context.WithTimeout(ctx, sshGTimeout)
+	defer cancel()
+
+	args := []string{"-G"}
+	if configFile != "" {
+		args = append(args, "-F", configFile)
+	}
+	args = append(args, "--", hostName)
+
+	output, err := runSSHG(ctx, args...)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("ssh -G %s: %s", hostName, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("ssh -G %s: %w", hostName, err)
+	}
+	return ParseSSHG(string(output)), nil
+}
+
+// Drift is a setting on which ssh and sshc's view of a host disagree
+type Drift struct {
+	Keyword string // As written in the config, or as ssh names it when sshc has no value
+	SSHC    string // Value sshc resolves, "" for none
+	SSHG    string // Value ssh -G reports
+	Source  string // Where the ssh -G value probably comes from
+	Multi   bool   // ssh collects the keyword from every block, so it is not adopted as one value
+}
+
+// Adoptable reports whether the ssh -G value can be written into the host's block
+func (d Drift) Adoptable() bool {
+	return !d.Multi
+}
+
+// watchedDefault is a setting checked even when sshc resolves no value for it,
+// and the value ssh uses when nothing sets it
+type watchedDefault struct {
+	keyword string
+	value   string
+}
+
+// watchedDefaults are the settings that change how ssh connects the most. A value
+// other than the default that sshc does not know about comes from elsewhere.
+var watchedDefaults = []watchedDefault{
+	{"HostName", ""}, // The host's name
+	{"User", ""},     // The local user
+	{"Port", "22"},
+	{"ProxyJump", "none"},
+	{"ProxyCommand", "none"},
+	{"ForwardAgent", "no"},
+	{"Compression", "no"},
+	{"StrictHostKeyChecking", "ask"},
+	{"IdentitiesOnly", "no"},
+	{"RequestTTY", "auto"},
+	{"RemoteCommand", "none"},
+	{"ServerAliveInterval", "0"},
+	{"ConnectTimeout", "none"},
+}
+
+// timeKeywords take a time value, which ssh -G prints in seconds
+var timeKeywords = map[string]bool{
+	"serveraliveinterval": true,
+	"connecttimeout":      true,
+	"forwardx11timeout":   true,
+	"controlpersist":      true,
+}
+
+// unknownDriftSource explains a value that no config sshc reads sets
+const unknownDriftSource = "a Match block, or a directive sshc does not evaluate"
+
+// DiffResolved compares sshc's view of a host with what ssh -G reports for it.
+// Settings ssh -G does not print are not compared, so output of older and newer
+// ssh versions both work. system is the host resolved against the system-wide
+// config, used to tell where a value comes from; it may be nil.
+func DiffResolved(effective *EffectiveHost, resolved ResolvedConfig, system *EffectiveHost) []Drift {
+	home, _ := os.UserHomeDir()
+	normalize := func(keyword, value string) string {
+		return normalizeSSHValue(keyword, value, effective.Name, home)
+	}
+
+	var order []string
+	settings := make(map[string][]EffectiveSetting)
+	for _, setting := range effective.Settings {
+		key := strings.ToLower(setting.Keyword)
+		if _, seen := settings[key]; !seen {
+			order = append(order, key)
+		}
+		settings[key] = append(settings[key], setting)
+	}
+
+	source := func(key, value string) string {
+		if system != nil {
+			for _, setting := range system.Settings {
+				if strings.EqualFold(setting.Keyword, key) && normalize(key, setting.Value) == normalize(key, value) {
+					return fmt.Sprintf("%s (Host %s)", setting.Location(), setting.Pattern)
+				}
+			}
+		}
+		return unknownDriftSource
+	}
+
+	var drifts []Drift
+	for _, key := range order {
+		values, ok := resolved[key]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		own := settings[key]
+
+		if multiValueKeywords[key] {
+			reported := make(map[string]bool)
+			for _, value := range values {
+				reported[normalize(key, value)] = true
+			}
+			var missing []string
+			for _, setting := range own {
+				if !reported[normalize(key, setting.Value)] {
+					missing = append(missing, setting.Value)
+				}
+			}
+			if len(missing) > 0 {
+				drifts = append(drifts, Drift{
+					Keyword: own[0].Keyword,
+					SSHC:    strings.Join(missing, ", "),
+					SSHG:    strings.Join(values, ", "),
+					Source:  unknownDriftSource,
+					Multi:   true,
+				})
+			}
+			continue
+		}
+
+		if normalize(key, own[0].Value) != normalize(key, values[0]) {
+			drifts = append(drifts, Drift{Keyword: own[0].Keyword, SSHC: own[0].Value, SSHG: values[0], Source: source(key, values[0])})
+		}
+	}
+
+	for _, watched := range watchedDefaults {
+		key := strings.ToLower(watched.keyword)
+		values, ok := resolved[key]
+		if _, set := settings[key]; set || !ok || len(values) == 0 {
+			continue
+		}
+		def := watched.value
+		switch key {
+		case "hostname":
+			def = effective.Name
+		case "user":
+			def = currentUsername()
+		}
+		if normalize(key, values[0]) != normalize(key, def) {
+			drifts = append(drifts, Drift{Keyword: watched.keyword, SSHG: values[0], Source: source(key, values[0])})
+		}
+	}
+	return drifts
+}
+
+// normalizeSSHValue puts a value in the form ssh -G prints it, so that equal
+// settings written differently compare equal
+func normalizeSSHValue(keyword, value, hostName, home string) string {
+	value = strings.Join(strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)), " ")
+	if home != "" && (value == "~" || strings.HasPrefix(value, "~/")) {
+		value = home + value[1:]
+	}
+	if keyword == "hostname" {
+		value = strings.ReplaceAll(strings.ReplaceAll(value, "%h", hostName), "%%", "%")
+	}
+	if timeKeywords[keyword] {
+		if seconds, ok := parseSSHTime(value); ok {
+			value = strconv.Itoa(seconds)
+		}
+	}
+
+	value = strings.ToLower(value)
+	switch value {
+	case "true":
+		return "yes"
+	case "false":
+		return "no"
+	case "none":
+		return ""
+	}
+	return value
+}
+
+// parseSSHTime reads an ssh time value such as "90", "1m30s" or "2h", in seconds
+func parseSSHTime(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total, number := 0, ""
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case units[c|0x20] > 0 && number != "":
+			n, _ := strconv.Atoi(number)
+			total += n * units[c|0x20]
+			number = ""
+		default:
+			return 0, false
+		}
+	}
+	if number != "" {
+		n, _ := strconv.Atoi(number)
+		total += n
+	}
+	return total, true
+}
+
+// setDirective sets keyword to value in the host, in the field sshc keeps it in
+// or else among its Options, replacing an option with the same keyword
+func (h *SSHHost) setDirective(keyword, value string) {
+	switch strings.ToLower(keyword) {
+	case "hostname":
+		h.Hostname = value
+	case "user":
+		h.User = value
+	case "port":
+		h.Port = value
+	case "identityfile":
+		h.Identity = value
+	case "proxyjump":
+		h.ProxyJump = value
+	case "proxycommand":
+		h.ProxyCommand = value
+	case "remotecommand":
+		h.RemoteCommand = value
+	case "requesttty":
+		h.RequestTTY = value
+	default:
+		var lines []string
+		replaced := false
+		for _, line := range strings.Split(h.Options, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if k, _ := splitOption(line); strings.EqualFold(k, keyword) {
+				if replaced {
+					continue
+				}
+				line, replaced = keyword+" "+value, true
+			}
+			lines = append(lines, strings.TrimSpace(line))
+		}
+		if !replaced {
+			lines = append(lines, keyword+" "+value)
+		}
+		h.Options = strings.Join(lines, "\n")
+	}
+}
+
+// AdoptDrift writes the value ssh -G reports into the host's own block, read from
+// configFile, or from the default config and its includes when configFile is ""
+func AdoptDrift(hostName string, drift Drift, configFile string) error {
+	if !drift.Adoptable() {
+		return fmt.Errorf("%s takes a value from every matching block, edit the host to change it", drift.Keyword)
+	}
+
+	var host *SSHHost
+	var err error
+	if configFile != "" {
+		host, err = GetSSHHostFromFile(hostName, configFile)
+	} else {
+		host, err = GetSSHHost(hostName)
+	}
+	if err != nil {
+		return err
+	}
+
+	host.setDirective(drift.Keyword, drift.SSHG)
+	return UpdateSSHHostInFile(hostName, *host, host.SourceFile)
+}
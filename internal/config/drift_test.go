@@ -0,0 +1,185 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// driftConfig is the config the captured ssh -G outputs in testdata were made from
+const driftConfig = `Host web
+    HostName web.example.com
+    User deploy
+    Port 2222
+    IdentityFile ~/.ssh/id_web
+    ServerAliveInterval 1m
+    ForwardAgent yes
+    ProxyJump bastion
+`
+
+func readSSHGFixture(t *testing.T, name string) ResolvedConfig {
+	t.Helper()
+	output, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ParseSSHG(string(output))
+}
+
+func TestParseSSHG(t *testing.T) {
+	resolved := ParseSSHG(`Pseudo-terminal will not be allocated because stdin is not a terminal.
+user deploy
+canonicalizePermittedcnames none
+canonicaldomains
+identityfile ~/.ssh/id_rsa
+identityfile ~/.ssh/id_ed25519
+futureoption  some value
+`)
+
+	if got := resolved["user"]; len(got) != 1 || got[0] != "deploy" {
+		t.Errorf("user = %q", got)
+	}
+	if got := resolved["identityfile"]; len(got) != 2 || got[1] != "~/.ssh/id_ed25519" {
+		t.Errorf("identityfile = %q, want both values", got)
+	}
+	if got := resolved["canonicalizepermittedcnames"]; len(got) != 1 {
+		t.Errorf("mixed case keyword = %q, want it lowercased", got)
+	}
+	if got, ok := resolved["canonicaldomains"]; !ok || got[0] != "" {
+		t.Errorf("keyword without value = %q, %v", got, ok)
+	}
+	if got := resolved["futureoption"]; len(got) != 1 || got[0] != "some value" {
+		t.Errorf("unknown keyword = %q, want it kept", got)
+	}
+	if _, ok := resolved["pseudo-terminal"]; ok || len(resolved) != 5 {
+		t.Errorf("warning line was parsed: %v", resolved)
+	}
+}
+
+func setupDriftTest(t *testing.T) (configPath string, effective *EffectiveHost) {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv("USERPROFILE", tempDir)
+	originalUsername := currentUsername
+	currentUsername = func() string { return "alice" }
+	t.Cleanup(func() { currentUsername = originalUsername })
+
+	configPath = filepath.Join(tempDir, "config")
+	writeFile(t, configPath, driftConfig)
+	effective, err := ResolveEffectiveHost("web", configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return configPath, effective
+}
+
+func TestDiffResolvedMatchingOutput(t *testing.T) {
+	_, effective := setupDriftTest(t)
+
+	// Captured from OpenSSH 9.2 reading driftConfig alone
+	if drifts := DiffResolved(effective, readSSHGFixture(t, "ssh-G-9.2p1.txt"), nil); len(drifts) != 0 {
+		t.Errorf("DiffResolved() = %+v, want no drift", drifts)
+	}
+}
+
+func TestDiffResolvedFindsDrift(t *testing.T) {
+	_, effective := setupDriftTest(t)
+	systemConfig := filepath.Join(t.TempDir(), "ssh_config")
+	writeFile(t, systemConfig, "Host *\n    StrictHostKeyChecking no\n    SendEnv LANG LC_*\n")
+	system, err := ResolveEffectiveHost("web", systemConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// OpenSSH 8.2 output of a setup where a Match block sets the user and the
+	// system config turns off host key checking
+	drifts := DiffResolved(effective, readSSHGFixture(t, "ssh-G-8.2p1.txt"), system)
+
+	byKeyword := make(map[string]Drift)
+	for _, drift := range drifts {
+		byKeyword[drift.Keyword] = drift
+	}
+	if len(byKeyword) != 4 {
+		t.Errorf("got drift on %d settings, want 4: %+v", len(byKeyword), drifts)
+	}
+
+	if user := byKeyword["User"]; user.SSHC != "deploy" || user.SSHG != "admin" || user.Source != unknownDriftSource {
+		t.Errorf("User drift = %+v", user)
+	}
+	if strict := byKeyword["StrictHostKeyChecking"]; strict.SSHC != "" || strict.SSHG != "no" || !strings.HasPrefix(strict.Source, systemConfig+":2") {
+		t.Errorf("StrictHostKeyChecking drift = %+v, want it traced to the system config", strict)
+	}
+	if compression := byKeyword["Compression"]; compression.SSHG != "yes" || !compression.Adoptable() {
+		t.Errorf("Compression drift = %+v", compression)
+	}
+	if identity := byKeyword["IdentityFile"]; identity.SSHC != "~/.ssh/id_web" || identity.Adoptable() {
+		t.Errorf("IdentityFile drift = %+v, want a list that cannot be adopted", identity)
+	}
+}
+
+func TestNormalizeSSHValue(t *testing.T) {
+	tests := []struct {
+		keyword, a, b string
+	}{
+		{"serveraliveinterval", "1m30s", "90"},
+		{"connecttimeout", "none", ""},
+		{"pubkeyauthentication", "true", "yes"},
+		{"hostname", "%h.example.com", "web.example.com"},
+		{"identityfile", "~/.ssh/id_web", "/home/alice/.ssh/id_web"},
+		{"user", `"Deploy"`, "deploy"},
+	}
+	for _, tt := range tests {
+		a := normalizeSSHValue(tt.keyword, tt.a, "web", "/home/alice")
+		b := normalizeSSHValue(tt.keyword, tt.b, "web", "/home/alice")
+		if a != b {
+			t.Errorf("%s: %q normalizes to %q, %q to %q", tt.keyword, tt.a, a, tt.b, b)
+		}
+	}
+}
+
+func TestResolveWithSSH(t *testing.T) {
+	var gotArgs []string
+	originalRun := runSSHG
+	defer func() { runSSHG = originalRun }()
+	runSSHG = func(ctx context.Context, args ...string) ([]byte, error) {
+		gotArgs = args
+		return []byte("user deploy\nport 22\n"), nil
+	}
+
+	resolved, err := ResolveWithSSH(context.Background(), "web", "/tmp/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(gotArgs, " ") != "-G -F /tmp/config -- web" {
+		t.Errorf("args = %q", gotArgs)
+	}
+	if resolved["port"][0] != "22" {
+		t.Errorf("resolved = %v", resolved)
+	}
+}
+
+func TestAdoptDrift(t *testing.T) {
+	setupAuditTest(t)
+	configPath, _ := setupDriftTest(t)
+
+	if err := AdoptDrift("web", Drift{Keyword: "User", SSHC: "deploy", SSHG: "admin"}, configPath); err != nil {
+		t.Fatalf("AdoptDrift(User) error = %v", err)
+	}
+	if err := AdoptDrift("web", Drift{Keyword: "StrictHostKeyChecking", SSHG: "no"}, configPath); err != nil {
+		t.Fatalf("AdoptDrift(StrictHostKeyChecking) error = %v", err)
+	}
+	if err := AdoptDrift("web", Drift{Keyword: "IdentityFile", SSHG: "~/.ssh/id_rsa", Multi: true}, configPath); err == nil {
+		t.Error("AdoptDrift() of a list succeeded, want an error")
+	}
+
+	host, err := GetSSHHostFromFile("web", configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host.User != "admin" || !strings.Contains(host.Options, "StrictHostKeyChecking no") || !strings.Contains(host.Options, "ServerAliveInterval 1m") {
+		t.Errorf("host after adopting = %+v", host)
+	}
+}
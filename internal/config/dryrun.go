@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+var (
+	dryRunMutex   sync.Mutex
+	dryRun        bool
+	dryRunChanges []ConfigChange
+)
+
+// SetDryRun turns dry-run mode on or off. In dry-run mode no config file, backup or
+// audit entry is written; the changes are kept for TakeDryRunChanges instead.
+func SetDryRun(enabled bool) {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+	dryRun = enabled
+	dryRunChanges = nil
+}
+
+// IsDryRun reports whether dry-run mode is on
+func IsDryRun() bool {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+	return dryRun
+}
+
+// TakeDryRunChanges returns the changes that were not written since the last call
+func TakeDryRunChanges() []ConfigChange {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+	changes := dryRunChanges
+	dryRunChanges = nil
+	return changes
+}
+
+// recordDryRun keeps change instead of writing it when dry-run mode is on, and
+// reports whether it did
+func recordDryRun(change ConfigChange) bool {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+	if !dryRun {
+		return false
+	}
+	dryRunChanges = append(dryRunChanges, change)
+	return true
+}
+
+// writeDataFile writes a file sshc manages outside the SSH config, such as the
+// snippet library, unless dry-run mode is on
+func writeDataFile(path string, data []byte, perm os.FileMode) error {
+	if IsDryRun() {
+		before, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		recordDryRun(ConfigChange{Path: path, Before: string(before), After: string(data)})
+		return nil
+	}
+	path = resolveConfigPath(path)
+	sshclog.Write(path, "write")
+	return writeFileRetrying(path, data, perm)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRunLeavesConfigUnchanged(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	original := "# Tags: prod\nHost web\n    HostName web.example.com\n    User deploy\n\nHost db\n    HostName db.example.com\n"
+	writeFile(t, configFile, original)
+
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	tests := []struct {
+		name    string
+		mutate  func() error
+		changed string // A line the diff must add or remove
+	}{
+		{"add", func() error {
+			return AddSSHHostToFile(SSHHost{Name: "cache", Hostname: "cache.example.com"}, configFile)
+		}, "+Host cache"},
+		{"update", func() error {
+			return UpdateSSHHostInFile("web", SSHHost{Name: "web", Hostname: "web2.example.com", User: "deploy"}, configFile)
+		}, "+    HostName web2.example.com"},
+		{"delete", func() error {
+			return DeleteSSHHostFromFile("db", configFile)
+		}, "-Host db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.mutate(); err != nil {
+				t.Fatalf("%s error = %v", tt.name, err)
+			}
+
+			content, err := os.ReadFile(configFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != original {
+				t.Errorf("Expected the config to be byte-identical, got:\n%s", content)
+			}
+
+			changes := TakeDryRunChanges()
+			if len(changes) != 1 {
+				t.Fatalf("Expected one recorded change, got %d", len(changes))
+			}
+			if changes[0].Path != configFile || changes[0].Before != original {
+				t.Errorf("Expected the change to start from %s as it is on disk, got %+v", configFile, changes[0])
+			}
+			diff := changes[0].Diff()
+			if diff == "" || !strings.Contains(diff, tt.changed) {
+				t.Errorf("Expected the diff to contain %q, got:\n%s", tt.changed, diff)
+			}
+		})
+	}
+
+	// Neither a backup nor an audit entry was written
+	if _, err := os.Stat(filepath.Join(tempDir, "sshc", "backups")); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup directory, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "sshc", "audit.log")); !os.IsNotExist(err) {
+		t.Errorf("Expected no audit log, stat error = %v", err)
+	}
+}
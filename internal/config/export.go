@@ -0,0 +1,128 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Host types used as the discriminator in export files
+const (
+	ExportTypeSSH = "ssh"
+	ExportTypeK8s = "k8s"
+)
+
+// exportFormatVersion is bumped when the export file layout changes incompatibly
+const exportFormatVersion = 1
+
+// ExportEntry is a single host in an export file
+type ExportEntry struct {
+	Type string   `json:"type"`
+	SSH  *SSHHost `json:"ssh,omitempty"`
+	K8s  *K8sHost `json:"k8s,omitempty"`
+}
+
+// ExportFile is the JSON document produced by export and read by import
+type ExportFile struct {
+	Version int           `json:"version"`
+	Hosts   []ExportEntry `json:"hosts"`
+}
+
+// ImportResult summarizes what an import added and skipped
+type ImportResult struct {
+	Added   []string
+	Skipped []string // Hosts that already exist
+}
+
+// MarshalExport encodes SSH and k8s hosts as an export document
+func MarshalExport(sshHosts []SSHHost, k8sHosts []K8sHost) ([]byte, error) {
+	file := ExportFile{Version: exportFormatVersion}
+	for i := range sshHosts {
+		file.Hosts = append(file.Hosts, ExportEntry{Type: ExportTypeSSH, SSH: &sshHosts[i]})
+	}
+	for i := range k8sHosts {
+		file.Hosts = append(file.Hosts, ExportEntry{Type: ExportTypeK8s, K8s: &k8sHosts[i]})
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// UnmarshalExport decodes an export document, checking every entry matches its type
+func UnmarshalExport(data []byte) ([]SSHHost, []K8sHost, error) {
+	var file ExportFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse export file: %w", err)
+	}
+	if file.Version > exportFormatVersion {
+		return nil, nil, fmt.Errorf("export file version %d is newer than supported version %d", file.Version, exportFormatVersion)
+	}
+
+	var sshHosts []SSHHost
+	var k8sHosts []K8sHost
+	for i, entry := range file.Hosts {
+		switch entry.Type {
+		case ExportTypeSSH:
+			if entry.SSH == nil || entry.SSH.Name == "" {
+				return nil, nil, fmt.Errorf("host #%d: ssh entry is missing its host definition", i+1)
+			}
+			sshHosts = append(sshHosts, *entry.SSH)
+		case ExportTypeK8s:
+			if entry.K8s == nil || entry.K8s.Name == "" {
+				return nil, nil, fmt.Errorf("host #%d: k8s entry is missing its host definition", i+1)
+			}
+			k8sHosts = append(k8sHosts, *entry.K8s)
+		default:
+			return nil, nil, fmt.Errorf("host #%d: unknown type %q (expected %q or %q)", i+1, entry.Type, ExportTypeSSH, ExportTypeK8s)
+		}
+	}
+
+	return sshHosts, k8sHosts, nil
+}
+
+// ImportHosts adds the given hosts, skipping any name that already exists.
+// SSH hosts go to configFile, or the default SSH config when empty.
+func ImportHosts(sshHosts []SSHHost, k8sHosts []K8sHost, configFile string) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	for _, host := range sshHosts {
+		var exists bool
+		var err error
+		if configFile != "" {
+			exists, err = HostExistsInFile(host.Name, configFile)
+		} else {
+			exists, err = HostExists(host.Name)
+		}
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			result.Skipped = append(result.Skipped, host.Name)
+			continue
+		}
+
+		if configFile != "" {
+			err = AddSSHHostToFile(host, configFile)
+		} else {
+			err = AddSSHHost(host)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to import host '%s': %w", host.Name, err)
+		}
+		result.Added = append(result.Added, host.Name)
+	}
+
+	for _, host := range k8sHosts {
+		exists, err := K8sHostExists(host.Name)
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			result.Skipped = append(result.Skipped, host.Name)
+			continue
+		}
+		if err := AddK8sHost(host); err != nil {
+			return result, fmt.Errorf("failed to import k8s host '%s': %w", host.Name, err)
+		}
+		result.Added = append(result.Added, host.Name)
+	}
+
+	return result, nil
+}
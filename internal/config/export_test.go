@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	sshHosts := []SSHHost{
+		{Name: "web", Hostname: "web.example.com", User: "deploy", Port: "22", Tags: []string{"prod"}, Expires: "2030-01-01", SourceFile: "/tmp/config"},
+	}
+	k8sHosts := []K8sHost{
+		{Name: "api-pod", Namespace: "default", Pod: "api-0", Context: "prod", Shell: "/bin/sh"},
+	}
+
+	data, err := MarshalExport(sshHosts, k8sHosts)
+	if err != nil {
+		t.Fatalf("MarshalExport() error = %v", err)
+	}
+	if strings.Contains(string(data), "/tmp/config") {
+		t.Error("Expected source file to be left out of the export")
+	}
+
+	gotSSH, gotK8s, err := UnmarshalExport(data)
+	if err != nil {
+		t.Fatalf("UnmarshalExport() error = %v", err)
+	}
+	if len(gotSSH) != 1 || gotSSH[0].Hostname != "web.example.com" || gotSSH[0].Expires != "2030-01-01" || len(gotSSH[0].Tags) != 1 {
+		t.Errorf("Unexpected SSH hosts: %+v", gotSSH)
+	}
+	if len(gotK8s) != 1 || gotK8s[0].Pod != "api-0" || gotK8s[0].Context != "prod" {
+		t.Errorf("Unexpected k8s hosts: %+v", gotK8s)
+	}
+}
+
+func TestUnmarshalExportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{"hosts": [`},
+		{"unknown type", `{"version": 1, "hosts": [{"type": "rdp"}]}`},
+		{"type without body", `{"version": 1, "hosts": [{"type": "k8s"}]}`},
+		{"future version", `{"version": 99, "hosts": []}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := UnmarshalExport([]byte(tt.data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestImportHostsSkipsExisting(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "ssh_config")
+	if err := os.WriteFile(configFile, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	sshHosts := []SSHHost{
+		{Name: "web", Hostname: "other.example.com"},
+		{Name: "db", Hostname: "db.example.com"},
+	}
+	k8sHosts := []K8sHost{{Name: "prod-eu", Namespace: "payments", Context: "prod-eu"}}
+
+	result, err := ImportHosts(sshHosts, k8sHosts, configFile)
+	if err != nil {
+		t.Fatalf("ImportHosts() error = %v", err)
+	}
+	if strings.Join(result.Added, ",") != "db,prod-eu" || strings.Join(result.Skipped, ",") != "web" {
+		t.Errorf("Unexpected import result: %+v", result)
+	}
+
+	hosts, err := ParseK8sConfig()
+	if err != nil {
+		t.Fatalf("ParseK8sConfig() error = %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "prod-eu" {
+		t.Errorf("Unexpected k8s hosts after import: %+v", hosts)
+	}
+}
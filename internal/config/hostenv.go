@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envNamePattern matches the names of environment variables that can be overridden
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// HostEnvFor returns the environment overrides of a host
+func (c *AppConfig) HostEnvFor(hostName string) map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.HostEnv[hostName]
+}
+
+// SetHostEnv replaces the environment overrides of a host, removing them when env
+// is empty. It reports whether they changed.
+func (c *AppConfig) SetHostEnv(hostName string, env map[string]string) bool {
+	if maps.Equal(c.HostEnv[hostName], env) {
+		return false
+	}
+	if len(env) == 0 {
+		delete(c.HostEnv, hostName)
+		return true
+	}
+	if c.HostEnv == nil {
+		c.HostEnv = make(map[string]map[string]string)
+	}
+	c.HostEnv[hostName] = maps.Clone(env)
+	return true
+}
+
+// ParseHostEnv reads environment overrides written as NAME=value pairs separated
+// by spaces or commas, such as "TERM=vt100 LANG=C". Values cannot hold spaces
+// or quotes, which ssh's SetEnv would split or strip.
+func ParseHostEnv(s string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, pair := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment override %q: use NAME=value, such as TERM=vt100", pair)
+		}
+		if value == "" || strings.ContainsAny(value, `"'\`) {
+			return nil, fmt.Errorf("invalid value for %s: it must be set and have no quotes", name)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// FormatHostEnv writes environment overrides as ParseHostEnv reads them, sorted by name
+func FormatHostEnv(env map[string]string) string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + env[name]
+	}
+	return strings.Join(pairs, " ")
+}
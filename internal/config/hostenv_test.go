@@ -0,0 +1,46 @@
+package config
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseHostEnv(t *testing.T) {
+	env, err := ParseHostEnv(" TERM=vt100, LANG=C.UTF-8\tLC_ALL=C ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"TERM": "vt100", "LANG": "C.UTF-8", "LC_ALL": "C"}
+	if !maps.Equal(env, want) {
+		t.Errorf("ParseHostEnv() = %v, want %v", env, want)
+	}
+	if got := FormatHostEnv(env); got != "LANG=C.UTF-8 LC_ALL=C TERM=vt100" {
+		t.Errorf("FormatHostEnv() = %q", got)
+	}
+
+	if env, err := ParseHostEnv("  "); err != nil || len(env) != 0 {
+		t.Errorf("ParseHostEnv(blank) = %v, %v, want nothing", env, err)
+	}
+	for _, bad := range []string{"TERM", "1TERM=x", "TE-RM=x", "LANG=", `LANG="C"`} {
+		if _, err := ParseHostEnv(bad); err == nil {
+			t.Errorf("ParseHostEnv(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestSetHostEnv(t *testing.T) {
+	c := &AppConfig{}
+	if !c.SetHostEnv("router", map[string]string{"TERM": "vt100"}) || c.HostEnvFor("router")["TERM"] != "vt100" {
+		t.Fatalf("HostEnv after SetHostEnv() = %v", c.HostEnv)
+	}
+	if c.SetHostEnv("router", map[string]string{"TERM": "vt100"}) {
+		t.Error("SetHostEnv() reported a change for the same overrides")
+	}
+	if got := (*AppConfig)(nil).HostEnvFor("router"); got != nil {
+		t.Errorf("HostEnvFor on a nil config = %v", got)
+	}
+
+	if !c.SetHostEnv("router", nil) || len(c.HostEnv) != 0 {
+		t.Errorf("HostEnv after clearing = %v", c.HostEnv)
+	}
+}
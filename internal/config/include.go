@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/pkg/sshconfig"
+)
+
+// ResolveIncludePath expands ~ in an Include pattern or path and makes it absolute,
+// relative to the directory of the config file at configPath
+func ResolveIncludePath(pattern, configPath string) (string, error) {
+	return sshconfig.ResolveIncludePath(pattern, configPath)
+}
+
+// GetIncludePatterns returns the Include patterns found in baseConfigPath and the files
+// it includes, as absolute glob patterns
+func GetIncludePatterns(baseConfigPath string) ([]string, error) {
+	files, err := GetAllConfigFilesFromBase(baseConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		filePatterns, err := includePatternsInFile(file)
+		if err != nil {
+			continue
+		}
+		for _, pattern := range filePatterns {
+			if !seen[pattern] {
+				seen[pattern] = true
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns, nil
+}
+
+// includePatternsInFile returns the resolved Include patterns of a single config file
+func includePatternsInFile(configPath string) ([]string, error) {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(configtext.TrimLine(scanner.Text()))
+		if len(parts) < 2 || strings.ToLower(parts[0]) != "include" {
+			continue
+		}
+		pattern, err := ResolveIncludePath(strings.Join(parts[1:], " "), configPath)
+		if err != nil {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, scanner.Err()
+}
+
+// CheckIncludeTarget returns an error explaining why a new config file at path
+// would not be read, or nil if one of the Include patterns picks it up
+func CheckIncludeTarget(path string, patterns []string) error {
+	path = filepath.Clean(path)
+
+	matched := false
+	for _, pattern := range patterns {
+		if ok, err := filepath.Match(pattern, path); err == nil && ok {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return fmt.Errorf("no Include directive matches %s, so ssh will not read it", path)
+	}
+
+	if configtext.NotConfig(path) {
+		return fmt.Errorf("%s matches an Include, but sshc skips files with that name", filepath.Base(path))
+	}
+	return nil
+}
+
+// CreateIncludedConfigFile creates a config file readable only by the user, along
+// with any missing parent directories. It starts from the new file template if
+// there is one, and is empty otherwise.
+func CreateIncludedConfigFile(path string) error {
+	// A dry run leaves the file to be shown as new in the diff of the first host added to it
+	if IsDryRun() {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	sshclog.Write(path, "create include")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists", path)
+		}
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	if err := writeNewConfigFile(file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
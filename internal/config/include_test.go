@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestGetIncludePatternsFollowsNestedIncludes(t *testing.T) {
+	tempDir := t.TempDir()
+	mainConfig := filepath.Join(tempDir, "config")
+	teamDir := filepath.Join(tempDir, "teams", "infra")
+	if err := os.MkdirAll(teamDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// The main config includes every team's base file, which includes its own hosts directory
+	writeFile(t, mainConfig, "Include teams/*/base\n\nHost main\n    HostName main.example\n")
+	writeFile(t, filepath.Join(teamDir, "base"), "include hosts.d/*.conf\nInclude /etc/ssh/ssh_config.d/*\n")
+
+	patterns, err := GetIncludePatterns(mainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(tempDir, "teams", "*", "base"),
+		filepath.Join(teamDir, "hosts.d", "*.conf"),
+		"/etc/ssh/ssh_config.d/*",
+	}
+	for _, pattern := range want {
+		if !slices.Contains(patterns, pattern) {
+			t.Errorf("Expected pattern %s in %v", pattern, patterns)
+		}
+	}
+	if len(patterns) != len(want) {
+		t.Errorf("Expected %d patterns, got %v", len(want), patterns)
+	}
+}
+
+func TestIncludeGlobOrdersByFullPath(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	// Directory by directory, a would come before a-b; by whole path '-' sorts before '/'
+	for _, team := range []string{"a", "a-b", "B"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, "teams", team), 0700); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(tempDir, "teams", team, "hosts"),
+			"Host "+team+"-web\n    HostName "+team+".example\n\nHost shared\n    HostName "+team+".shared\n")
+	}
+	writeFile(t, configFile, "Include teams/*/hosts\n")
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	var got []string
+	for _, host := range hosts {
+		got = append(got, fmt.Sprintf("%s@%d", host.Name, host.Order))
+	}
+	want := []string{"B-web@0", "shared@1", "a-b-web@2", "shared@3", "a-web@4", "shared@5"}
+	if !slices.Equal(got, want) {
+		t.Errorf("hosts = %q, want %q", got, want)
+	}
+
+	// The first definition of a name is the one used
+	host, err := GetSSHHostFromFile("shared", configFile)
+	if err != nil || host.Hostname != "B.shared" {
+		t.Errorf("GetSSHHostFromFile(shared) = %+v, %v, want the one from teams/B", host, err)
+	}
+}
+
+func TestCheckIncludeTarget(t *testing.T) {
+	patterns := []string{
+		"/home/u/.ssh/conf.d/*.conf",
+		"/home/u/.ssh/teams/*/hosts/[a-m]*",
+		"/home/u/.ssh/single",
+	}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"/home/u/.ssh/conf.d/web.conf", true},
+		{"/home/u/.ssh/conf.d/../conf.d/db.conf", true},
+		{"/home/u/.ssh/conf.d/web.cfg", false},
+		// A single * does not cross directory levels
+		{"/home/u/.ssh/conf.d/sub/web.conf", false},
+		{"/home/u/.ssh/teams/infra/hosts/db", true},
+		{"/home/u/.ssh/teams/infra/hosts/web", false},
+		{"/home/u/.ssh/teams/infra/nested/hosts/db", false},
+		{"/home/u/.ssh/single", true},
+		{"/home/u/.ssh/other", false},
+		// Matches, but sshc would not parse it
+		{"/home/u/.ssh/teams/infra/hosts/notes.md", false},
+		{"/home/u/.ssh/teams/infra/hosts/db.backup", false},
+	}
+
+	for _, tt := range tests {
+		err := CheckIncludeTarget(tt.path, patterns)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckIncludeTarget(%s) = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+	}
+
+	if err := CheckIncludeTarget("/home/u/.ssh/conf.d/web.conf", nil); err == nil {
+		t.Error("Expected a warning when the config has no Include directives")
+	}
+}
+
+func TestCreateIncludedConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "conf.d", "new.conf")
+
+	if err := CreateIncludedConfigFile(path); err != nil {
+		t.Fatalf("CreateIncludedConfigFile() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	if err := CreateIncludedConfigFile(path); err == nil {
+		t.Error("Expected an error when the file already exists")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,341 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+)
+
+// EffectiveSetting is a directive ssh would use for a host, and where it comes from
+type EffectiveSetting struct {
+	Keyword    string // As written in the config, e.g. "User"
+	Value      string
+	Pattern    string // Host line of the block that sets it, e.g. "*.prod !db"
+	SourceFile string
+	Line       int  // Line of SourceFile the directive is on
+	Inherited  bool // Set by a wildcard block rather than the host's own block
+	Overrides  bool // The host's own block sets it too, but this earlier block wins
+}
+
+// Location returns where the setting is written, as file:line
+func (s EffectiveSetting) Location() string {
+	return fmt.Sprintf("%s:%d", s.SourceFile, s.Line)
+}
+
+// EffectiveHost is the result of resolving a host against every matching block
+type EffectiveHost struct {
+	Name     string
+	Settings []EffectiveSetting
+}
+
+// Inherited returns the settings that come from wildcard blocks
+func (e *EffectiveHost) Inherited() []EffectiveSetting {
+	var inherited []EffectiveSetting
+	for _, setting := range e.Settings {
+		if setting.Inherited {
+			inherited = append(inherited, setting)
+		}
+	}
+	return inherited
+}
+
+// multiValueKeywords are directives ssh collects from every matching block
+// instead of keeping the first value
+var multiValueKeywords = map[string]bool{
+	"identityfile":    true,
+	"certificatefile": true,
+	"localforward":    true,
+	"remoteforward":   true,
+	"dynamicforward":  true,
+	"sendenv":         true,
+}
+
+// configBlock is one Host block with its directives in file order
+type configBlock struct {
+	patterns   []string
+	line       string
+	sourceFile string
+	guards     []blockGuard
+	directives []configDirective
+}
+
+// blockGuard is the Host line around an Include. ssh only reads the included
+// files for hosts that line matches, so every block in them depends on it.
+type blockGuard struct {
+	patterns []string
+	line     string
+}
+
+// configDirective is a directive of a block and the line it is on
+type configDirective struct {
+	keyword string
+	value   string
+	line    int
+}
+
+// appliesTo reports whether the block, and the Host lines of the Includes it was
+// read through, match hostName. own is set when the block names the host literally.
+func (b configBlock) appliesTo(hostName string) (applies, own bool) {
+	for _, guard := range b.guards {
+		if !guardMatches(guard.patterns, hostName) {
+			return false, false
+		}
+	}
+	own = blockNamesHost(b, hostName)
+	return own || hostMatchesPatterns(hostName, b.patterns), own
+}
+
+// guardMatches reports whether a Host line around an Include matches hostName
+func guardMatches(patterns []string, hostName string) bool {
+	return hostMatchesPatterns(hostName, patterns) || blockNamesHost(configBlock{patterns: patterns}, hostName)
+}
+
+// ResolveEffectiveHost works out the settings ssh would apply to hostName from
+// configPath and the files it includes. Like ssh, the first value of a directive
+// wins, so a wildcard block above the host's own block takes precedence over it,
+// also when one of them is in an included file: blocks are taken in the order ssh
+// reads them, with an Include read where it appears. Match blocks are skipped, as
+// they depend on more than the host name.
+func ResolveEffectiveHost(hostName, configPath string) (*EffectiveHost, error) {
+	blocks, err := loadConfigBlocks(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keywords the host's own blocks set, to mark earlier wildcard values as overriding them
+	ownKeywords := make(map[string]bool)
+	for _, block := range blocks {
+		if _, own := block.appliesTo(hostName); own {
+			for _, directive := range block.directives {
+				ownKeywords[strings.ToLower(directive.keyword)] = true
+			}
+		}
+	}
+
+	effective := &EffectiveHost{Name: hostName}
+	seen := make(map[string]bool)
+	for _, block := range blocks {
+		applies, own := block.appliesTo(hostName)
+		if !applies {
+			continue
+		}
+		for _, directive := range block.directives {
+			key := strings.ToLower(directive.keyword)
+			if seen[key] && !multiValueKeywords[key] {
+				continue
+			}
+			seen[key] = true
+			effective.Settings = append(effective.Settings, EffectiveSetting{
+				Keyword:    directive.keyword,
+				Value:      directive.value,
+				Pattern:    block.line,
+				SourceFile: block.sourceFile,
+				Line:       directive.line,
+				Inherited:  !own,
+				Overrides:  !own && ownKeywords[key] && !multiValueKeywords[key],
+			})
+		}
+	}
+	return effective, nil
+}
+
+// ResolveSettings works out the value ssh would use for each of keywords, for
+// every host in hostNames, reading configPath only once. The result maps a host
+// name to its values by lowercase keyword; keywords no block sets are left out.
+func ResolveSettings(hostNames, keywords []string, configPath string) (map[string]map[string]string, error) {
+	blocks, err := loadConfigBlocks(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		wanted[strings.ToLower(keyword)] = true
+	}
+	settings := make(map[string]map[string]string, len(hostNames))
+	for _, hostName := range hostNames {
+		values := make(map[string]string)
+		for _, block := range blocks {
+			if applies, _ := block.appliesTo(hostName); !applies {
+				continue
+			}
+			for _, directive := range block.directives {
+				key := strings.ToLower(directive.keyword)
+				if _, seen := values[key]; wanted[key] && !seen {
+					values[key] = directive.value
+				}
+			}
+		}
+		settings[hostName] = values
+	}
+	return settings, nil
+}
+
+// loadConfigBlocks reads the Host blocks of configPath and its includes, in the
+// order ssh reads them
+func loadConfigBlocks(configPath string) ([]configBlock, error) {
+	return readConfigBlocks(configPath, make(map[string]bool), nil)
+}
+
+// readConfigBlocks reads the Host blocks of configPath in the order ssh reads
+// them, expanding Include directives where they appear. guards are the Host lines
+// around the Include that led to configPath.
+func readConfigBlocks(configPath string, processedFiles map[string]bool, guards []blockGuard) ([]configBlock, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if processedFiles[absPath] {
+		return nil, nil
+	}
+	processedFiles[absPath] = true
+
+	file, err := os.Open(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var blocks []configBlock
+	var current *configBlock
+	// Directives of an included file above its first Host line belong to the
+	// Host line around the Include
+	if len(guards) > 0 {
+		enclosing := guards[len(guards)-1]
+		current = &configBlock{patterns: enclosing.patterns, line: enclosing.line, sourceFile: configPath, guards: guards}
+	}
+	inMatch := false
+	flush := func() {
+		if current != nil {
+			blocks = append(blocks, *current)
+			current = nil
+		}
+	}
+
+	lineNumber := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+		line := configtext.TrimLine(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, value := configtext.SplitDirective(line)
+		if value == "" {
+			continue
+		}
+
+		switch strings.ToLower(keyword) {
+		case "host":
+			flush()
+			inMatch = false
+			current = &configBlock{patterns: configtext.SplitHostNames(value), line: value, sourceFile: configPath, guards: guards}
+		case "match":
+			flush()
+			inMatch = true
+		case "include":
+			pattern, err := ResolveIncludePath(value, configPath)
+			if err != nil {
+				continue
+			}
+			matches, err := configtext.Glob(pattern)
+			if err != nil {
+				continue
+			}
+
+			// Inside a Host block the included files only apply to the hosts it
+			// matches, and the block goes on after the Include
+			enclosing := current
+			includeGuards := guards
+			if enclosing != nil && !inMatch {
+				includeGuards = append(append([]blockGuard(nil), guards...), blockGuard{patterns: enclosing.patterns, line: enclosing.line})
+			}
+			flush()
+			for _, match := range matches {
+				if info, err := os.Stat(match); err != nil || info.IsDir() || configtext.NotConfig(match) {
+					continue
+				}
+				included, err := readConfigBlocks(match, processedFiles, includeGuards)
+				if err != nil {
+					continue
+				}
+				blocks = append(blocks, included...)
+			}
+			if enclosing != nil {
+				current = &configBlock{patterns: enclosing.patterns, line: enclosing.line, sourceFile: configPath, guards: enclosing.guards}
+			}
+		case "tag":
+			// sshc keeps tags per host; they are not settings to inherit
+		default:
+			if current != nil && !inMatch {
+				current.directives = append(current.directives, configDirective{keyword: keyword, value: value, line: lineNumber})
+			}
+		}
+	}
+	flush()
+	return blocks, scanner.Err()
+}
+
+// blockNamesHost reports whether the block declares hostName literally
+func blockNamesHost(block configBlock, hostName string) bool {
+	for _, pattern := range block.patterns {
+		if !configtext.IsPattern(pattern) && pattern == hostName {
+			return true
+		}
+	}
+	return false
+}
+
+// hostMatchesPatterns applies ssh's rules for a Host line: some pattern must
+// match and no negated pattern may
+func hostMatchesPatterns(hostName string, patterns []string) bool {
+	name := strings.ToLower(hostName)
+	matched := false
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if strings.HasPrefix(pattern, "!") {
+			if matchWildcard(pattern[1:], name) {
+				return false
+			}
+			continue
+		}
+		if matchWildcard(pattern, name) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchWildcard matches name against a pattern where * matches any run of
+// characters and ? any single character
+func matchWildcard(pattern, name string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(name); i >= 0; i-- {
+				if matchWildcard(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if name == "" {
+				return false
+			}
+		default:
+			if name == "" || name[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return name == ""
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveEffectiveHost(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	includedFile := filepath.Join(tempDir, "prod.conf")
+	writeFile(t, includedFile, "Host *.prod\n    ForwardAgent yes\n")
+	writeFile(t, configFile, `Include prod.conf
+
+Host web.prod
+    HostName 10.0.0.1
+    Port 2222
+
+Host * !db.prod
+    User admin
+    Port 22
+    IdentityFile ~/.ssh/shared
+
+Match exec "true"
+    User nobody
+`)
+
+	effective, err := ResolveEffectiveHost("web.prod", configFile)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveHost() error = %v", err)
+	}
+
+	want := []EffectiveSetting{
+		{Keyword: "ForwardAgent", Value: "yes", Pattern: "*.prod", SourceFile: includedFile, Line: 2, Inherited: true},
+		{Keyword: "HostName", Value: "10.0.0.1", Pattern: "web.prod", SourceFile: configFile, Line: 4},
+		{Keyword: "Port", Value: "2222", Pattern: "web.prod", SourceFile: configFile, Line: 5},
+		{Keyword: "User", Value: "admin", Pattern: "* !db.prod", SourceFile: configFile, Line: 8, Inherited: true},
+		{Keyword: "IdentityFile", Value: "~/.ssh/shared", Pattern: "* !db.prod", SourceFile: configFile, Line: 10, Inherited: true},
+	}
+	if len(effective.Settings) != len(want) {
+		t.Fatalf("Expected %d settings, got %+v", len(want), effective.Settings)
+	}
+	for i, setting := range effective.Settings {
+		if setting != want[i] {
+			t.Errorf("Setting %d = %+v, want %+v", i, setting, want[i])
+		}
+	}
+	if inherited := effective.Inherited(); len(inherited) != 3 {
+		t.Errorf("Expected 3 inherited settings, got %+v", inherited)
+	}
+
+	// The negated pattern keeps db.prod out of the second wildcard block
+	effective, err = ResolveEffectiveHost("db.prod", configFile)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveHost() error = %v", err)
+	}
+	for _, setting := range effective.Settings {
+		if setting.Keyword == "User" {
+			t.Errorf("Expected no User for db.prod, got %+v", setting)
+		}
+	}
+}
+
+func TestResolveEffectiveHostEarlierWildcardWins(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, "Host web*\n    User root\n\nHost web1\n    User deploy\n")
+
+	effective, err := ResolveEffectiveHost("web1", configFile)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveHost() error = %v", err)
+	}
+	if len(effective.Settings) != 1 {
+		t.Fatalf("Expected one setting, got %+v", effective.Settings)
+	}
+	if got := effective.Settings[0]; got.Value != "root" || !got.Inherited || !got.Overrides {
+		t.Errorf("Expected the wildcard User to win over the host's own, got %+v", got)
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, `Host web1
+    HostName 10.0.0.1
+    ControlPath ~/.ssh/cm-web1
+
+Host *
+    User admin
+    ControlPath ~/.ssh/cm-%C
+    ForwardAgent yes
+`)
+
+	settings, err := ResolveSettings([]string{"web1", "db1"}, []string{"HostName", "User", "ControlPath"}, configFile)
+	if err != nil {
+		t.Fatalf("ResolveSettings() error = %v", err)
+	}
+	want := map[string]map[string]string{
+		"web1": {"hostname": "10.0.0.1", "user": "admin", "controlpath": "~/.ssh/cm-web1"},
+		"db1":  {"user": "admin", "controlpath": "~/.ssh/cm-%C"},
+	}
+	for host, values := range want {
+		if !reflect.DeepEqual(settings[host], values) {
+			t.Errorf("settings of %s = %v, want %v", host, settings[host], values)
+		}
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*", "anything", true},
+		{"web?", "web1", true},
+		{"web?", "web12", false},
+		{"*.example.com", "db.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"a*b*c", "aXXbYc", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		if got := matchWildcard(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchWildcard(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEffectiveSettingLinesAcrossIncludes(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	teamFile := filepath.Join(tempDir, "team.conf")
+	defaultsFile := filepath.Join(tempDir, "defaults.conf")
+	writeFile(t, configFile, "# Personal config\nInclude team.conf\n")
+	writeFile(t, teamFile, "Include defaults.conf\n\nHost web\n    # Tags: prod\n    HostName 10.0.0.1\n    User deploy\n")
+	writeFile(t, defaultsFile, "Host *\n    User nobody\n\n    ServerAliveInterval 30\n")
+
+	effective, err := ResolveEffectiveHost("web", configFile)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveHost() error = %v", err)
+	}
+
+	want := map[string]string{
+		"HostName":            teamFile + ":5",
+		"User":                defaultsFile + ":2",
+		"ServerAliveInterval": defaultsFile + ":4",
+	}
+	if len(effective.Settings) != len(want) {
+		t.Fatalf("Got %d settings, want %d: %+v", len(effective.Settings), len(want), effective.Settings)
+	}
+	for _, setting := range effective.Settings {
+		if got := setting.Location(); got != want[setting.Keyword] {
+			t.Errorf("%s is at %s, want %s", setting.Keyword, got, want[setting.Keyword])
+		}
+	}
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	var web *SSHHost
+	for i := range hosts {
+		if hosts[i].Name == "web" {
+			web = &hosts[i]
+		}
+	}
+	if web == nil {
+		t.Fatalf("Host web not parsed: %+v", hosts)
+	}
+	if web.SourceFile != teamFile || web.Line != 3 {
+		t.Errorf("Host web is at %s:%d, want %s:3", web.SourceFile, web.Line, teamFile)
+	}
+	if web.DirectiveLines["hostname"] != 5 || web.DirectiveLines["user"] != 6 {
+		t.Errorf("DirectiveLines = %v, want hostname on 5 and user on 6", web.DirectiveLines)
+	}
+}
+
+func TestResolveEffectiveHostIncludeOrder(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, filepath.Join(tempDir, "hosts.conf"), "Host myhost\n    User second\n    HostName 10.0.0.1\n")
+
+	// "Host *" comes first, so ssh -G myhost reports "user first": the Include is
+	// read where it sits, after the wildcard has already set User
+	writeFile(t, configFile, "Host *\n    User first\n\nInclude hosts.conf\n")
+	effective, err := ResolveEffectiveHost("myhost", configFile)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveHost() error = %v", err)
+	}
+	if user := effectiveValue(effective, "User"); user == nil || user.Value != "first" || !user.Overrides {
+		t.Errorf("User = %+v, want first, overriding the host's own value", user)
+	}
+
+	// With the Include first, ssh -G myhost reports "user second"
+	writeFile(t, configFile, "Include hosts.conf\n\nHost *\n    User first\n")
+	effective, _ = ResolveEffectiveHost("myhost", configFile)
+	if user := effectiveValue(effective, "User"); user == nil || user.Value != "second" || user.Inherited {
+		t.Errorf("User = %+v, want second from the host's own block", user)
+	}
+}
+
+func TestResolveEffectiveHostIncludeInsideHostBlock(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, filepath.Join(tempDir, "web.conf"), "Port 2222\n\nHost *\n    Compression yes\n")
+
+	// ssh only reads web.conf for hosts the enclosing "Host web*" matches, and the
+	// directives after the Include still belong to that block
+	writeFile(t, configFile, "Host web*\n    User deploy\n    Include web.conf\n    ForwardAgent yes\n\nHost db\n    HostName 10.0.0.2\n")
+
+	effective, err := ResolveEffectiveHost("web1", configFile)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveHost() error = %v", err)
+	}
+	for keyword, want := range map[string]string{"User": "deploy", "Port": "2222", "Compression": "yes", "ForwardAgent": "yes"} {
+		if setting := effectiveValue(effective, keyword); setting == nil || setting.Value != want {
+			t.Errorf("web1 %s = %+v, want %s", keyword, setting, want)
+		}
+	}
+
+	effective, _ = ResolveEffectiveHost("db", configFile)
+	for _, keyword := range []string{"Port", "Compression", "ForwardAgent"} {
+		if setting := effectiveValue(effective, keyword); setting != nil {
+			t.Errorf("db %s = %+v, want unset: web.conf only applies to web*", keyword, setting)
+		}
+	}
+}
+
+func TestParseSSHConfigOrdersIncludedHosts(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, filepath.Join(tempDir, "inner.conf"), "Host inner\n    HostName 10.0.0.2\n")
+	writeFile(t, configFile, "Host outer outer-alias\n    Include inner.conf\n    HostName 10.0.0.1\n\nHost last\n    HostName 10.0.0.3\n")
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	want := []struct {
+		name  string
+		order int
+	}{{"outer", 0}, {"outer-alias", 0}, {"inner", 1}, {"last", 2}}
+	if len(hosts) != len(want) {
+		t.Fatalf("Got %d hosts, want %d", len(hosts), len(want))
+	}
+	for i, w := range want {
+		if hosts[i].Name != w.name || hosts[i].Order != w.order {
+			t.Errorf("hosts[%d] = %s (order %d), want %s (order %d)", i, hosts[i].Name, hosts[i].Order, w.name, w.order)
+		}
+	}
+	if hosts[0].Hostname != "10.0.0.1" {
+		t.Errorf("outer HostName = %q, want the directive after the Include kept", hosts[0].Hostname)
+	}
+}
+
+// effectiveValue returns the first setting of keyword, or nil
+func effectiveValue(effective *EffectiveHost, keyword string) *EffectiveSetting {
+	for i := range effective.Settings {
+		if effective.Settings[i].Keyword == keyword {
+			return &effective.Settings[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"net"
+	"slices"
+	"strings"
+)
+
+// JumpGraph holds the ProxyJump relationships between the hosts of a config:
+// for each host, the hosts it is reached through
+type JumpGraph struct {
+	hops   map[string][]string // ProxyJump hops by host, "" where a hop is not a host of the config
+	chains map[string][]string
+	cyclic map[string]bool
+}
+
+// BuildJumpGraph works out the jump hosts of every host from their ProxyJump.
+// Hops are matched to hosts by name, ignoring a user or port given with them;
+// hops that are not hosts of the config are left out of the chains.
+func BuildJumpGraph(hosts []SSHHost) *JumpGraph {
+	names := make(map[string]string, len(hosts))
+	for _, host := range hosts {
+		names[strings.ToLower(host.Name)] = host.Name
+	}
+
+	g := &JumpGraph{
+		hops:   make(map[string][]string),
+		chains: make(map[string][]string),
+		cyclic: make(map[string]bool),
+	}
+	for _, host := range hosts {
+		if host.ProxyJump == "" || strings.EqualFold(host.ProxyJump, "none") {
+			continue
+		}
+		var hops []string
+		for _, hop := range strings.Split(host.ProxyJump, ",") {
+			hops = append(hops, names[strings.ToLower(jumpHopName(hop))])
+		}
+		g.hops[host.Name] = hops
+	}
+	for _, host := range hosts {
+		g.chains[host.Name] = g.chain(host.Name, map[string]bool{})
+	}
+	return g
+}
+
+// jumpHopName returns the host of a ProxyJump hop, [user@]host[:port]
+func jumpHopName(hop string) string {
+	hop = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(hop), "ssh://"))
+	if i := strings.LastIndexByte(hop, '@'); i >= 0 {
+		hop = hop[i+1:]
+	}
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		return host
+	}
+	return hop
+}
+
+// chain follows the hops of name outwards. ssh reaches the first hop with that
+// host's own ProxyJump and the later ones through the hops before them, so only
+// the first hop is followed further. visiting holds the hosts on the way here;
+// coming back to one of them is a cycle, which ends the chain.
+func (g *JumpGraph) chain(name string, visiting map[string]bool) []string {
+	if visiting[name] {
+		g.cyclic[name] = true
+		return nil
+	}
+	hops := g.hops[name]
+	if len(hops) == 0 {
+		return nil
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	var chain []string
+	if hops[0] != "" {
+		chain = g.chain(hops[0], visiting)
+		if g.cyclic[hops[0]] {
+			g.cyclic[name] = true
+		}
+	}
+	for _, hop := range hops {
+		if hop != "" && !slices.Contains(chain, hop) {
+			chain = append(chain, hop)
+		}
+	}
+	// A host that jumps through itself
+	if i := slices.Index(chain, name); i >= 0 {
+		g.cyclic[name] = true
+		chain = slices.Delete(chain, i, i+1)
+	}
+	return chain
+}
+
+// Chain returns the hosts ssh goes through to reach name, the one it contacts
+// first leading, or nil for a host reached directly
+func (g *JumpGraph) Chain(name string) []string {
+	return g.chains[name]
+}
+
+// InCycle reports whether the ProxyJump chain of name runs into a cycle, in which
+// case ssh cannot reach it and the chain is cut where the cycle closes
+func (g *JumpGraph) InCycle(name string) bool {
+	return g.cyclic[name]
+}
+
+// WithBastions returns names with the hosts of their chains added, each one
+// before the first host reached through it
+func (g *JumpGraph) WithBastions(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var ordered []string
+	for _, name := range names {
+		for _, bastion := range g.Chain(name) {
+			if !seen[bastion] {
+				seen[bastion] = true
+				ordered = append(ordered, bastion)
+			}
+		}
+		if !seen[name] {
+			seen[name] = true
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// twoLevelBastionConfig reaches the private hosts through an inner bastion that
+// is itself only reachable through the edge bastion
+const twoLevelBastionConfig = `Host edge
+    HostName edge.example.com
+
+Host inner
+    HostName 10.0.0.2
+    ProxyJump admin@edge:2222
+
+Host db web
+    HostName %h.internal
+    ProxyJump inner
+
+Host reports
+    HostName reports.internal
+    ProxyJump edge,inner
+
+Host partner
+    HostName partner.internal
+    ProxyJump ops@gw.partner.example,inner
+
+Host public
+    HostName public.example.com
+    ProxyJump none
+`
+
+func TestBuildJumpGraph(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	writeFile(t, configFile, twoLevelBastionConfig)
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := BuildJumpGraph(hosts)
+
+	tests := map[string][]string{
+		"edge":    nil,
+		"inner":   {"edge"},
+		"db":      {"edge", "inner"},
+		"web":     {"edge", "inner"},
+		"reports": {"edge", "inner"},
+		"partner": {"inner"}, // The partner gateway is not a host of the config
+		"public":  nil,
+		"missing": nil,
+	}
+	for name, want := range tests {
+		if got := g.Chain(name); !slices.Equal(got, want) {
+			t.Errorf("Chain(%s) = %v, want %v", name, got, want)
+		}
+		if g.InCycle(name) {
+			t.Errorf("InCycle(%s) = true", name)
+		}
+	}
+
+	got := g.WithBastions([]string{"public", "db", "web", "edge"})
+	if want := []string{"public", "edge", "inner", "db", "web"}; !slices.Equal(got, want) {
+		t.Errorf("WithBastions() = %v, want %v", got, want)
+	}
+}
+
+func TestJumpGraphCycles(t *testing.T) {
+	g := BuildJumpGraph([]SSHHost{
+		{Name: "a", ProxyJump: "b"},
+		{Name: "b", ProxyJump: "c"},
+		{Name: "c", ProxyJump: "a"},
+		{Name: "self", ProxyJump: "self"},
+		{Name: "behind", ProxyJump: "a"},
+		{Name: "fine", ProxyJump: "Self2"},
+		{Name: "self2"},
+	})
+
+	for _, name := range []string{"a", "b", "c", "self", "behind"} {
+		if !g.InCycle(name) {
+			t.Errorf("InCycle(%s) = false", name)
+		}
+		if slices.Contains(g.Chain(name), name) {
+			t.Errorf("Chain(%s) = %v contains the host itself", name, g.Chain(name))
+		}
+	}
+	if got := g.Chain("a"); !slices.Equal(got, []string{"c", "b"}) {
+		t.Errorf("Chain(a) = %v, want the cycle cut before a", got)
+	}
+	if g.InCycle("fine") || !slices.Equal(g.Chain("fine"), []string{"self2"}) {
+		t.Errorf("Chain(fine) = %v, cycle %v; want self2 matched case-insensitively", g.Chain("fine"), g.InCycle("fine"))
+	}
+}
@@ -3,10 +3,79 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+// List view actions that can be bound to a key
+const (
+	ActionHelp          = "help"
+	ActionInfo          = "info"
+	ActionEdit          = "edit"
+	ActionDelete        = "delete"
+	ActionDeleteExpired = "delete-expired"
+	ActionMove          = "move"
+	ActionPing          = "ping"
+	ActionTransfer      = "transfer"
+	ActionForward       = "forward"
+	ActionTheme         = "theme"
+	ActionAdd           = "add"
+	ActionK8sAdd        = "k8s-add"
+	ActionKeyUpload     = "key-upload"
+	ActionSortCycle     = "sort-cycle"
+	ActionSortName      = "sort-name"
+	ActionSortRecent    = "sort-recent"
+	ActionSearch        = "search"
+	ActionTagFilter     = "tag-filter"
+	ActionTimeFormat    = "time-format"
+	ActionDualBrowser   = "dual-browser"
+	ActionDashboard     = "dashboard"
+	ActionSnippets      = "snippets"
+	ActionOnboard       = "onboard"
+	ActionVerboseSSH    = "verbose-connect"
+	ActionCollapse      = "collapse-blocks"
+	ActionMount         = "mount"
+	ActionHistory       = "history"
+	ActionWait          = "wait-for-host"
+	ActionReload        = "reload"
+	ActionMark          = "mark"
+	ActionVerify        = "verify"
+	ActionMessages      = "messages"
+	ActionJumpConnect   = "jump-connect"
+	ActionSaveWorkspace = "save-workspace"
+	ActionWorkspaces    = "workspaces"
+	ActionOSInfo        = "os-info"
+	ActionOpenURL       = "open-url"
+	ActionColumns       = "columns"
 )
 
+// MovementKeys move the cursor in the host list on every keyboard layout, so no
+// action can be bound to them. "g" starts the "gg" jump to the first host.
+var MovementKeys = []string{
+	"up", "down", "k", "j",
+	"pgup", "pgdown", "ctrl+u", "ctrl+d",
+	"home", "end", "g", "G",
+}
+
+// IsMovementKey reports whether key is one of MovementKeys
+func IsMovementKey(key string) bool {
+	return slices.Contains(MovementKeys, key)
+}
+
+// formerDefaultKeys are the defaults of actions that moved to make way for
+// MovementKeys. Configs saved with them get the new default instead.
+var formerDefaultKeys = map[string]string{
+	ActionKeyUpload: "k",
+	ActionDashboard: "ctrl+d",
+}
+
 // KeyBindings represents configurable key bindings for the application
 type KeyBindings struct {
 	// Quit keys - keys that will quit the application
@@ -14,14 +83,180 @@ type KeyBindings struct {
 
 	// DisableEscQuit - if true, ESC key won't quit the application (useful for vim users)
 	DisableEscQuit bool `json:"disable_esc_quit"`
+
+	// Actions maps list view action names to the key that triggers them
+	Actions map[string]string `json:"actions"`
 }
 
 // AppConfig represents the main application configuration
 type AppConfig struct {
 	KeyBindings       KeyBindings `json:"key_bindings"`
 	Theme             string      `json:"theme"`
-	SortMode          string      `json:"sort_mode"`           // "name" or "recent"
-	StartInSearchMode bool        `json:"start_in_search_mode"` // Start with search focused
+	ThemeVariant      string      `json:"theme_variant,omitempty"` // auto (default), dark or light
+	SortMode          string      `json:"sort_mode"`               // "name" or "recent"
+	TimeFormat        string      `json:"time_format,omitempty"`   // "relative" (default) or "absolute"
+	StartInSearchMode bool        `json:"start_in_search_mode"`    // Start with search focused
+	HTTPProxy         string      `json:"http_proxy,omitempty"`    // Overrides HTTPS_PROXY/HTTP_PROXY for update checks
+	HTTPTimeout       int         `json:"http_timeout,omitempty"`  // Seconds, 0 uses the default
+
+	// CollapseBlocks shows each multi-host block as a single row until it is expanded
+	CollapseBlocks bool `json:"collapse_blocks,omitempty"`
+
+	// HiddenColumns lists the columns of ListColumns left out of the host list. Name
+	// and hostname are always shown.
+	HiddenColumns []string `json:"hidden_columns,omitempty"`
+
+	// WaitTimeout is how long, in seconds, waiting for a host keeps probing it; 0 uses
+	// connectivity.DefaultWaitTimeout
+	WaitTimeout int `json:"wait_timeout,omitempty"`
+
+	// InteractiveCommands extends DefaultInteractiveCommands
+	InteractiveCommands []string `json:"interactive_commands,omitempty"`
+
+	// PingConcurrency caps simultaneous pings, 0 uses connectivity.DefaultMaxConcurrentPings
+	PingConcurrency int `json:"ping_concurrency,omitempty"`
+
+	// HostColors maps host names to a theme color slot such as "accent-2"
+	HostColors map[string]string `json:"host_colors,omitempty"`
+
+	// Commands run before and after every connection, with {host}, {hostname}, {user}
+	// and, after, {exit_code} placeholders. They are split into arguments without a
+	// shell unless HookShell is set. HookTimeout is in seconds, 0 uses the default.
+	PreConnectHook  string `json:"pre_connect_hook,omitempty"`
+	PostConnectHook string `json:"post_connect_hook,omitempty"`
+	HookShell       bool   `json:"hook_shell,omitempty"`
+	HookTimeout     int    `json:"hook_timeout,omitempty"`
+
+	// AnnotationCommand is run at startup and on reload with the host list as JSON on
+	// stdin, and prints a short note per host shown next to its tags. The timeout is
+	// in seconds, 0 uses the default.
+	AnnotationCommand string `json:"annotation_command,omitempty"`
+	AnnotationTimeout int    `json:"annotation_timeout,omitempty"`
+
+	// HealthProbeCommand replaces the remote command the dashboard runs on each host
+	HealthProbeCommand string `json:"health_probe_command,omitempty"`
+
+	// WindowTitle is the terminal title while connected, with {name}, {hostname} and
+	// {user} placeholders; "off" leaves the title alone
+	WindowTitle string `json:"window_title,omitempty"`
+
+	// CompactListWidth is the terminal width below which hosts are listed one per line
+	// instead of in a table; 0 uses DefaultCompactListWidth and -1 always keeps the table
+	CompactListWidth int `json:"compact_list_width,omitempty"`
+
+	// Workspaces are saved searches with their sort mode, grouping and columns
+	Workspaces []Workspace `json:"workspaces,omitempty"`
+
+	// Maintenance maps host names under maintenance to when it ends
+	Maintenance map[string]Maintenance `json:"maintenance,omitempty"`
+
+	// ConnectRate is the connect rate checked on every host, and UsageLimits the
+	// limits of single hosts, which take precedence
+	ConnectRate UsageLimit            `json:"connect_rate"`
+	UsageLimits map[string]UsageLimit `json:"usage_limits,omitempty"`
+
+	// OSInfoTTL is how long, in hours, the OS and uptime found on a host are used
+	// before it is probed again; 0 uses connectivity.DefaultOSInfoTTL. With
+	// OSInfoOnPing the hosts whose info is older are probed along with ping-all.
+	OSInfoTTL    int  `json:"os_info_ttl,omitempty"`
+	OSInfoOnPing bool `json:"os_info_on_ping,omitempty"`
+
+	// HostURLs maps host names to the web pages they serve, such as an admin UI.
+	// They are templates with {host}, {hostname} and {user} placeholders.
+	HostURLs map[string][]string `json:"host_urls,omitempty"`
+
+	// PinnedPaths maps host names to the remote paths pinned in the remote browser
+	PinnedPaths map[string][]PinnedPath `json:"pinned_paths,omitempty"`
+
+	// HostEnv maps host names to the environment variables their sessions get,
+	// such as TERM=vt100 for a device that does not know xterm-256color
+	HostEnv map[string]map[string]string `json:"host_env,omitempty"`
+
+	// KeyBindingWarnings describes the bindings of the file that could not be used
+	// and were reset when it was loaded
+	KeyBindingWarnings []string `json:"-"`
+
+	// HistoryJournal appends each connection to a journal next to the history file
+	// instead of rewriting the whole file
+	HistoryJournal bool `json:"history_journal,omitempty"`
+}
+
+// DefaultCompactListWidth is the terminal width below which the table gets too cramped to read
+const DefaultCompactListWidth = 60
+
+// SetHostColor assigns a color slot to a host, or removes its color when slot is empty
+func (c *AppConfig) SetHostColor(hostName, slot string) {
+	if slot == "" {
+		delete(c.HostColors, hostName)
+		return
+	}
+	if c.HostColors == nil {
+		c.HostColors = make(map[string]string)
+	}
+	c.HostColors[hostName] = slot
+}
+
+// RenameHostColor moves the color of a renamed host to its new name
+func (c *AppConfig) RenameHostColor(oldName, newName string) bool {
+	slot, ok := c.HostColors[oldName]
+	if !ok || oldName == newName {
+		return false
+	}
+	delete(c.HostColors, oldName)
+	c.HostColors[newName] = slot
+	return true
+}
+
+// PruneHostColors removes the colors of hosts that are not in hostNames and returns their names
+func (c *AppConfig) PruneHostColors(hostNames []string) []string {
+	existing := make(map[string]bool, len(hostNames))
+	for _, name := range hostNames {
+		existing[name] = true
+	}
+
+	var pruned []string
+	for name := range c.HostColors {
+		if !existing[name] {
+			pruned = append(pruned, name)
+			delete(c.HostColors, name)
+		}
+	}
+	sort.Strings(pruned)
+	return pruned
+}
+
+// DefaultInteractiveCommands are RemoteCommand programs that need a terminal to be useful
+var DefaultInteractiveCommands = []string{
+	"bash", "sh", "zsh", "fish", "ksh", "dash", "su",
+	"tmux", "screen", "htop", "top", "btop",
+	"vim", "vi", "nvim", "nano", "emacs", "less", "more", "man",
+	"mysql", "psql", "redis-cli", "python", "python3", "irb", "node",
+}
+
+// InteractiveCommandList returns the default interactive commands plus the configured ones
+func (c *AppConfig) InteractiveCommandList() []string {
+	commands := append([]string(nil), DefaultInteractiveCommands...)
+	if c != nil {
+		commands = append(commands, c.InteractiveCommands...)
+	}
+	return commands
+}
+
+// CompactListThreshold returns the width below which the compact host list is used,
+// or 0 when it is turned off
+func (c *AppConfig) CompactListThreshold() int {
+	if c == nil || c.CompactListWidth == 0 {
+		return DefaultCompactListWidth
+	}
+	return max(c.CompactListWidth, 0)
+}
+
+// HTTPTimeoutDuration returns the configured HTTP timeout, or 0 when unset
+func (c *AppConfig) HTTPTimeoutDuration() time.Duration {
+	if c == nil || c.HTTPTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.HTTPTimeout) * time.Second
 }
 
 // GetDefaultKeyBindings returns the default key bindings configuration
@@ -29,6 +264,51 @@ func GetDefaultKeyBindings() KeyBindings {
 	return KeyBindings{
 		QuitKeys:       []string{"q", "ctrl+c"}, // Default keeps current behavior minus ESC
 		DisableEscQuit: false,                   // Default to false for backward compatibility
+		Actions:        GetDefaultActionKeys(),
+	}
+}
+
+// GetDefaultActionKeys returns the default key for every list view action
+func GetDefaultActionKeys() map[string]string {
+	return map[string]string{
+		ActionHelp:          "h",
+		ActionInfo:          "i",
+		ActionEdit:          "e",
+		ActionDelete:        "d",
+		ActionDeleteExpired: "X",
+		ActionMove:          "m",
+		ActionPing:          "p",
+		ActionTransfer:      "t",
+		ActionForward:       "f",
+		ActionTheme:         "c",
+		ActionAdd:           "a",
+		ActionK8sAdd:        "K",
+		ActionKeyUpload:     "U",
+		ActionSortCycle:     "s",
+		ActionSortName:      "n",
+		ActionSortRecent:    "r",
+		ActionSearch:        "/",
+		ActionTagFilter:     "#",
+		ActionTimeFormat:    "z",
+		ActionDualBrowser:   "ctrl+t",
+		ActionDashboard:     "D",
+		ActionSnippets:      "ctrl+x",
+		ActionOnboard:       "O",
+		ActionVerboseSSH:    "ctrl+v",
+		ActionCollapse:      "C",
+		ActionMount:         "M",
+		ActionHistory:       "H",
+		ActionWait:          "ctrl+w",
+		ActionReload:        "R",
+		ActionMark:          "space",
+		ActionVerify:        "V",
+		ActionMessages:      "!",
+		ActionJumpConnect:   "J",
+		ActionSaveWorkspace: "ctrl+s",
+		ActionWorkspaces:    "ctrl+o",
+		ActionOSInfo:        "ctrl+e",
+		ActionOpenURL:       "b",
+		ActionColumns:       "L",
 	}
 }
 
@@ -90,14 +370,25 @@ func LoadAppConfig() (*AppConfig, error) {
 		return nil, err
 	}
 
-	// Validate and fill in missing fields with defaults
+	// Validate and fill in missing fields with defaults. Bindings that cannot be
+	// used are reset on their own, so the rest of the file survives the next save.
+	fileActions := maps.Clone(config.KeyBindings.Actions)
 	config = mergeWithDefaults(config)
+	config.KeyBindingWarnings = config.KeyBindings.repair(fileActions)
+	for _, warning := range config.KeyBindingWarnings {
+		sshclog.Warn("key binding in "+configPath, "problem", warning)
+	}
 
 	return &config, nil
 }
 
 // SaveAppConfig saves the application configuration to file
 func SaveAppConfig(config *AppConfig) error {
+	// Preferences such as the sort mode or theme are not kept during a dry run
+	if IsDryRun() {
+		return nil
+	}
+
 	if config == nil {
 		return errors.New("config cannot be nil")
 	}
@@ -118,7 +409,8 @@ func SaveAppConfig(config *AppConfig) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	sshclog.Write(configPath, "app config")
+	return writeFileRetrying(configPath, data, 0644)
 }
 
 // mergeWithDefaults ensures all required fields are set with defaults if missing
@@ -130,11 +422,27 @@ func mergeWithDefaults(config AppConfig) AppConfig {
 		config.KeyBindings.QuitKeys = defaults.KeyBindings.QuitKeys
 	}
 
+	// Fill in any action the user did not rebind
+	if config.KeyBindings.Actions == nil {
+		config.KeyBindings.Actions = make(map[string]string)
+	}
+	for action, key := range defaults.KeyBindings.Actions {
+		bound := config.KeyBindings.Actions[action]
+		if bound == "" || bound == formerDefaultKeys[action] {
+			config.KeyBindings.Actions[action] = key
+		}
+	}
+
 	// If Theme is empty, use default
 	if config.Theme == "" {
 		config.Theme = defaults.Theme
 	}
 
+	// Anything but dark or light follows the terminal
+	if config.ThemeVariant != "dark" && config.ThemeVariant != "light" {
+		config.ThemeVariant = ""
+	}
+
 	// If SortMode is empty or invalid, use default
 	if config.SortMode != "name" && config.SortMode != "recent" {
 		config.SortMode = defaults.SortMode
@@ -158,4 +466,127 @@ func (kb *KeyBindings) ShouldQuitOnKey(key string) bool {
 	}
 
 	return false
-}
\ No newline at end of file
+}
+
+// Validate checks that every action is known, that no action is bound to one of
+// MovementKeys and that no key is assigned twice, either to two actions or to an
+// action and a quit key
+func (kb *KeyBindings) Validate() error {
+	known := GetDefaultActionKeys()
+
+	// Iterate in a stable order so errors are reproducible
+	actions := make([]string, 0, len(kb.Actions))
+	for action := range kb.Actions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	owners := make(map[string]string)
+	for _, key := range kb.QuitKeys {
+		owners[key] = "quit"
+	}
+
+	for _, action := range actions {
+		if _, ok := known[action]; !ok {
+			return fmt.Errorf("unknown action %q", action)
+		}
+		key := kb.Actions[action]
+		if key == "" {
+			continue
+		}
+		if IsMovementKey(key) {
+			return fmt.Errorf("key %q of %q is reserved for moving in the list", key, action)
+		}
+		if owner, exists := owners[key]; exists {
+			return fmt.Errorf("key %q is assigned to both %q and %q", key, owner, action)
+		}
+		owners[key] = action
+	}
+
+	return nil
+}
+
+// repair resets the bindings Validate would reject. fileActions are the bindings
+// as the config file has them: those the user chose win over keys other actions
+// only hold by default, which are left unbound instead. A user binding on a
+// movement or quit key, or on the key of an earlier user binding, goes back to its
+// default. It returns a description of each change.
+func (kb *KeyBindings) repair(fileActions map[string]string) []string {
+	defaults := GetDefaultActionKeys()
+	var warnings []string
+
+	actions := make([]string, 0, len(kb.Actions))
+	for action := range kb.Actions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	var chosen, byDefault []string
+	for _, action := range actions {
+		key := fileActions[action]
+		switch {
+		case defaults[action] == "":
+			warnings = append(warnings, fmt.Sprintf("unknown action %q is ignored", action))
+			delete(kb.Actions, action)
+		case key != "" && key != defaults[action] && key != formerDefaultKeys[action]:
+			chosen = append(chosen, action)
+		default:
+			byDefault = append(byDefault, action)
+		}
+	}
+
+	owners := make(map[string]string)
+	for _, key := range kb.QuitKeys {
+		owners[key] = "quit"
+	}
+	for _, action := range chosen {
+		key := kb.Actions[action]
+		var problem string
+		if IsMovementKey(key) {
+			problem = "is reserved for moving in the list"
+		} else if owner, exists := owners[key]; exists {
+			problem = fmt.Sprintf("is already assigned to %q", owner)
+		}
+		if problem == "" {
+			owners[key] = action
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("key %q of %q %s; using %q", key, action, problem, defaults[action]))
+		kb.Actions[action] = defaults[action]
+		byDefault = append(byDefault, action)
+	}
+
+	sort.Strings(byDefault)
+	for _, action := range byDefault {
+		key := kb.Actions[action]
+		if key == "" {
+			continue
+		}
+		if owner, exists := owners[key]; exists {
+			warnings = append(warnings, fmt.Sprintf("%q has no key: its default %q is assigned to %q", action, key, owner))
+			kb.Actions[action] = ""
+			continue
+		}
+		owners[key] = action
+	}
+	return warnings
+}
+
+// ActionForKey returns the action bound to the given key, or an empty string
+func (kb *KeyBindings) ActionForKey(key string) string {
+	for action, k := range kb.Actions {
+		if k == key {
+			return action
+		}
+	}
+	return ""
+}
+
+// KeyForAction returns the key bound to the given action, falling back to the
+// default when the bindings do not list it. An action left without a key has "".
+func (kb *KeyBindings) KeyForAction(action string) string {
+	if key, ok := kb.Actions[action]; ok {
+		return key
+	}
+	return GetDefaultActionKeys()[action]
+}
@@ -178,4 +178,197 @@ func TestSaveAndLoadAppConfigIntegration(t *testing.T) {
 	if len(loadedConfig.KeyBindings.QuitKeys) != 1 || loadedConfig.KeyBindings.QuitKeys[0] != "q" {
 		t.Errorf("Expected quit keys to be ['q'], got %v", loadedConfig.KeyBindings.QuitKeys)
 	}
-}
\ No newline at end of file
+}
+func TestDefaultActionKeysAreValid(t *testing.T) {
+	kb := GetDefaultKeyBindings()
+	if err := kb.Validate(); err != nil {
+		t.Errorf("Default key bindings should be valid, got: %v", err)
+	}
+
+	if action := kb.ActionForKey("h"); action != ActionHelp {
+		t.Errorf("Expected 'h' to map to %q, got %q", ActionHelp, action)
+	}
+}
+
+func TestMergeWithDefaultsFillsActions(t *testing.T) {
+	merged := mergeWithDefaults(AppConfig{
+		KeyBindings: KeyBindings{
+			Actions: map[string]string{ActionHelp: "?"},
+		},
+	})
+
+	if key := merged.KeyBindings.KeyForAction(ActionHelp); key != "?" {
+		t.Errorf("Expected help to stay bound to '?', got %q", key)
+	}
+	if key := merged.KeyBindings.KeyForAction(ActionEdit); key != "e" {
+		t.Errorf("Expected edit to fall back to 'e', got %q", key)
+	}
+}
+
+func TestMergeWithDefaultsMovesFormerDefaultsOffMovementKeys(t *testing.T) {
+	// A config saved before k and ctrl+d moved the cursor still lists them
+	saved := GetDefaultActionKeys()
+	saved[ActionKeyUpload] = "k"
+	saved[ActionDashboard] = "ctrl+d"
+	merged := mergeWithDefaults(AppConfig{KeyBindings: KeyBindings{Actions: saved}})
+
+	if err := merged.KeyBindings.Validate(); err != nil {
+		t.Fatalf("Validate() after merging = %v", err)
+	}
+	if key := merged.KeyBindings.KeyForAction(ActionKeyUpload); key != "U" {
+		t.Errorf("key-upload = %q, want the new default U", key)
+	}
+	if key := merged.KeyBindings.KeyForAction(ActionDashboard); key != "D" {
+		t.Errorf("dashboard = %q, want the new default D", key)
+	}
+}
+
+func TestDefaultActionKeysAvoidMovementKeys(t *testing.T) {
+	for action, key := range GetDefaultActionKeys() {
+		if IsMovementKey(key) {
+			t.Errorf("action %q defaults to movement key %q", action, key)
+		}
+	}
+}
+
+func TestValidateKeyBindings(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "help moved off h for vim navigation",
+			actions: map[string]string{ActionHelp: "?", ActionSortCycle: "S"},
+			wantErr: false,
+		},
+		{
+			name:    "two actions on the same key",
+			actions: map[string]string{ActionHelp: "x", ActionPing: "x"},
+			wantErr: true,
+		},
+		{
+			name:    "action on a quit key",
+			actions: map[string]string{ActionTheme: "q"},
+			wantErr: true,
+		},
+		{
+			name:    "action on a movement key",
+			actions: map[string]string{ActionInfo: "j"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown action",
+			actions: map[string]string{"launch-missiles": "L"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := KeyBindings{
+				QuitKeys: []string{"q", "ctrl+c"},
+				Actions:  tt.actions,
+			}
+			err := kb.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInteractiveCommandList(t *testing.T) {
+	var nilConfig *AppConfig
+	if got := nilConfig.InteractiveCommandList(); len(got) != len(DefaultInteractiveCommands) {
+		t.Errorf("Expected the defaults without a config, got %v", got)
+	}
+
+	defaults := len(DefaultInteractiveCommands)
+	config := &AppConfig{InteractiveCommands: []string{"k9s"}}
+	got := config.InteractiveCommandList()
+	if len(got) != defaults+1 || got[len(got)-1] != "k9s" {
+		t.Errorf("Expected configured commands to extend the defaults, got %v", got)
+	}
+	got[0] = "changed"
+	if len(DefaultInteractiveCommands) != defaults || DefaultInteractiveCommands[0] == "changed" {
+		t.Error("InteractiveCommandList must not modify DefaultInteractiveCommands")
+	}
+}
+
+func TestHostColors(t *testing.T) {
+	var c AppConfig
+	c.SetHostColor("web", "accent-1")
+	c.SetHostColor("db", "accent-3")
+	c.SetHostColor("old", "accent-2")
+
+	if !c.RenameHostColor("web", "web-prod") {
+		t.Error("Expected the color of a renamed host to move")
+	}
+	if c.RenameHostColor("missing", "other") {
+		t.Error("Expected no change for a host without a color")
+	}
+	if c.HostColors["web-prod"] != "accent-1" {
+		t.Errorf("Expected web-prod to keep accent-1, got %v", c.HostColors)
+	}
+
+	pruned := c.PruneHostColors([]string{"web-prod", "db"})
+	if len(pruned) != 1 || pruned[0] != "old" {
+		t.Errorf("Expected old to be pruned, got %v", pruned)
+	}
+	if len(c.HostColors) != 2 {
+		t.Errorf("Expected two colors left, got %v", c.HostColors)
+	}
+
+	c.SetHostColor("db", "")
+	if _, ok := c.HostColors["db"]; ok {
+		t.Error("Expected an empty slot to remove the color")
+	}
+}
+
+func TestLoadAppConfigRepairsKeyBindings(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	configPath, err := GetAppConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// j is a movement key now, p only belongs to ping by default, and x is taken twice
+	data := `{
+  "key_bindings": {"quit_keys": ["q"], "actions": {"info": "j", "history": "p", "edit": "x", "move": "x", "launch": "L"}},
+  "host_colors": {"web": "accent-1"}
+}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadAppConfig()
+	if err != nil {
+		t.Fatalf("LoadAppConfig() error = %v", err)
+	}
+	if c.HostColors["web"] != "accent-1" {
+		t.Error("Expected the rest of the config to be kept")
+	}
+
+	kb := c.KeyBindings
+	want := map[string]string{ActionInfo: "i", ActionHistory: "p", ActionPing: "", ActionEdit: "x", ActionMove: "m"}
+	for action, key := range want {
+		if got := kb.KeyForAction(action); got != key {
+			t.Errorf("KeyForAction(%q) = %q, want %q", action, got, key)
+		}
+	}
+	if _, ok := kb.Actions["launch"]; ok {
+		t.Error("Expected the unknown action to be dropped")
+	}
+	if len(c.KeyBindingWarnings) != 4 {
+		t.Errorf("KeyBindingWarnings = %q, want 4", c.KeyBindingWarnings)
+	}
+	if err := kb.Validate(); err != nil {
+		t.Errorf("Validate() after repair = %v", err)
+	}
+}
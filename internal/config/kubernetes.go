@@ -12,14 +12,34 @@ import (
 
 // K8sHost represents a Kubernetes pod connection configuration
 type K8sHost struct {
-	Name       string   `yaml:"name"`
-	Namespace  string   `yaml:"namespace"`
-	Pod        string   `yaml:"pod"`
-	Container  string   `yaml:"container,omitempty"`
-	Context    string   `yaml:"context,omitempty"`
-	Kubeconfig string   `yaml:"kubeconfig,omitempty"`
-	Shell      string   `yaml:"shell,omitempty"`
-	Tags       []string `yaml:"tags,omitempty"`
+	Name       string   `yaml:"name" json:"name"`
+	Namespace  string   `yaml:"namespace" json:"namespace"`
+	Pod        string   `yaml:"pod" json:"pod"`
+	Container  string   `yaml:"container,omitempty" json:"container,omitempty"`
+	Context    string   `yaml:"context,omitempty" json:"context,omitempty"`
+	Kubeconfig string   `yaml:"kubeconfig,omitempty" json:"kubeconfig,omitempty"`
+	Shell      string   `yaml:"shell,omitempty" json:"shell,omitempty"`
+	Tags       []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// K8sContext is a context discovered in a kubeconfig file
+type K8sContext struct {
+	Name      string
+	Cluster   string
+	Namespace string // Default namespace of the context, "default" when unset
+	Current   bool
+}
+
+// kubeconfigFile holds the parts of a kubeconfig that context discovery needs
+type kubeconfigFile struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
 }
 
 // K8sConfig represents the kubernetes configuration file structure
@@ -83,6 +103,11 @@ func ParseK8sConfig() ([]K8sHost, error) {
 
 // SaveK8sConfig saves the k8s configuration to file
 func SaveK8sConfig(hosts []K8sHost) error {
+	return saveK8sConfig(hosts, AuditUpdate, "")
+}
+
+// saveK8sConfig writes the k8s hosts, recording the change to hostName in the audit log
+func saveK8sConfig(hosts []K8sHost, operation, hostName string) error {
 	k8sMutex.Lock()
 	defer k8sMutex.Unlock()
 
@@ -103,7 +128,12 @@ func SaveK8sConfig(hosts []K8sHost) error {
 		return fmt.Errorf("failed to marshal k8s config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	before, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read k8s config: %w", err)
+	}
+
+	if err := writeConfigFile(configPath, operation, hostName, before, string(data)); err != nil {
 		return fmt.Errorf("failed to write k8s config: %w", err)
 	}
 
@@ -130,7 +160,7 @@ func AddK8sHost(host K8sHost) error {
 	}
 
 	hosts = append(hosts, host)
-	return SaveK8sConfig(hosts)
+	return saveK8sConfig(hosts, AuditAdd, host.Name)
 }
 
 // UpdateK8sHost updates an existing k8s host
@@ -157,7 +187,7 @@ func UpdateK8sHost(oldName string, newHost K8sHost) error {
 		return fmt.Errorf("k8s host '%s' not found", oldName)
 	}
 
-	return SaveK8sConfig(hosts)
+	return saveK8sConfig(hosts, AuditUpdate, oldName)
 }
 
 // DeleteK8sHost removes a k8s host from the config
@@ -181,7 +211,7 @@ func DeleteK8sHost(name string) error {
 		return fmt.Errorf("k8s host '%s' not found", name)
 	}
 
-	return SaveK8sConfig(newHosts)
+	return saveK8sConfig(newHosts, AuditDelete, name)
 }
 
 // GetK8sHost retrieves a specific k8s host by name
@@ -200,6 +230,71 @@ func GetK8sHost(name string) (*K8sHost, error) {
 	return nil, fmt.Errorf("k8s host '%s' not found", name)
 }
 
+// GetDefaultKubeconfigPath returns the first path in $KUBECONFIG, or ~/.kube/config
+func GetDefaultKubeconfigPath() (string, error) {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)[0], nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".kube", "config"), nil
+}
+
+// DiscoverK8sContexts lists the contexts defined in a kubeconfig file.
+// An empty path uses the default kubeconfig location.
+func DiscoverK8sContexts(kubeconfigPath string) ([]K8sContext, error) {
+	if kubeconfigPath == "" {
+		var err error
+		kubeconfigPath, err = GetDefaultKubeconfigPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	var kubeconfig kubeconfigFile
+	if err := yaml.Unmarshal(data, &kubeconfig); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %s: %w", kubeconfigPath, err)
+	}
+
+	var contexts []K8sContext
+	for i, ctx := range kubeconfig.Contexts {
+		if ctx.Name == "" {
+			return nil, fmt.Errorf("invalid kubeconfig %s: context #%d has no name", kubeconfigPath, i+1)
+		}
+		namespace := ctx.Context.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		contexts = append(contexts, K8sContext{
+			Name:      ctx.Name,
+			Cluster:   ctx.Context.Cluster,
+			Namespace: namespace,
+			Current:   ctx.Name == kubeconfig.CurrentContext,
+		})
+	}
+
+	return contexts, nil
+}
+
+// K8sHostFromContext creates a k8s host for a discovered context.
+// The pod is left blank and must be set before connecting.
+func K8sHostFromContext(ctx K8sContext, kubeconfigPath string) K8sHost {
+	return K8sHost{
+		Name:       ctx.Name,
+		Namespace:  ctx.Namespace,
+		Context:    ctx.Name,
+		Kubeconfig: kubeconfigPath,
+		Shell:      "/bin/bash",
+	}
+}
+
 // BuildKubectlCommand builds the kubectl exec command for a k8s host
 func (h *K8sHost) BuildKubectlCommand() *exec.Cmd {
 	args := []string{}
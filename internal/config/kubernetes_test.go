@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverK8sContexts(t *testing.T) {
+	tempDir := t.TempDir()
+
+	kubeconfig := filepath.Join(tempDir, "config")
+	content := `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: eu-cluster
+  cluster:
+    server: https://eu.example.com
+contexts:
+- name: prod-eu
+  context:
+    cluster: eu-cluster
+    namespace: payments
+- name: staging
+  context:
+    cluster: staging-cluster
+users: []
+`
+	if err := os.WriteFile(kubeconfig, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to create kubeconfig: %v", err)
+	}
+
+	contexts, err := DiscoverK8sContexts(kubeconfig)
+	if err != nil {
+		t.Fatalf("DiscoverK8sContexts() error = %v", err)
+	}
+
+	expected := []K8sContext{
+		{Name: "prod-eu", Cluster: "eu-cluster", Namespace: "payments"},
+		{Name: "staging", Cluster: "staging-cluster", Namespace: "default", Current: true},
+	}
+	if len(contexts) != len(expected) {
+		t.Fatalf("Expected %d contexts, got %d: %+v", len(expected), len(contexts), contexts)
+	}
+	for i, want := range expected {
+		if contexts[i] != want {
+			t.Errorf("context %d = %+v, want %+v", i, contexts[i], want)
+		}
+	}
+
+	host := K8sHostFromContext(contexts[0], kubeconfig)
+	if host.Name != "prod-eu" || host.Context != "prod-eu" || host.Namespace != "payments" || host.Pod != "" || host.Kubeconfig != kubeconfig {
+		t.Errorf("Unexpected host from context: %+v", host)
+	}
+}
+
+func TestDiscoverK8sContextsMalformed(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not yaml", "contexts: [unclosed"},
+		{"contexts is not a list", "contexts: prod"},
+		{"context without name", "contexts:\n- context:\n    cluster: a\n"},
+		{"binary garbage", "\x00\x01\x02\tkey: : :"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeconfig := filepath.Join(tempDir, "config")
+			if err := os.WriteFile(kubeconfig, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to create kubeconfig: %v", err)
+			}
+			if _, err := DiscoverK8sContexts(kubeconfig); err == nil {
+				t.Error("Expected an error for malformed kubeconfig")
+			}
+		})
+	}
+
+	if _, err := DiscoverK8sContexts(filepath.Join(tempDir, "missing")); err == nil {
+		t.Error("Expected an error for a missing kubeconfig")
+	}
+}
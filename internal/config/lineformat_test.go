@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditingCRLFConfigOnlyChangesThatBlock(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	original := "\ufeff" + strings.Join([]string{
+		"Host web",
+		"    HostName web.example.com",
+		"    User deploy",
+		"",
+		"Host db",
+		"    HostName db.example.com",
+		"",
+	}, "\r\n")
+	writeFile(t, configFile, original)
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Name != "web" || hosts[0].User != "deploy" {
+		t.Fatalf("Expected web and db to parse despite the BOM, got %+v", hosts)
+	}
+
+	// The first block sits right after the BOM
+	if err := UpdateSSHHostInFile("web", SSHHost{Name: "web", Hostname: "web.example.com", User: "admin"}, configFile); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+	content, _ := os.ReadFile(configFile)
+	want := strings.Replace(original, "User deploy", "User admin", 1)
+	if string(content) != want {
+		t.Errorf("Expected only the User line to change:\n%q\nwant:\n%q", content, want)
+	}
+
+	if err := AddSSHHostToFile(SSHHost{Name: "cache", Hostname: "cache.example.com"}, configFile); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+	if err := DeleteSSHHostFromFile("db", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+	content, _ = os.ReadFile(configFile)
+	if !strings.HasPrefix(string(content), "\ufeff") {
+		t.Error("Expected the BOM to be kept")
+	}
+	if strings.Count(string(content), "\n") != strings.Count(string(content), "\r\n") {
+		t.Errorf("Expected CRLF line endings throughout, got %q", content)
+	}
+	if exists, _ := HostExistsInSpecificFile("cache", configFile); !exists {
+		t.Error("Expected cache to be added")
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+)
+
+// LintWarning is a problem found in a host that ssh accepts but probably does not do what was intended
+type LintWarning struct {
+	Host    string
+	File    string
+	Line    int // Line of File the problem is on, 0 if unknown
+	Message string
+}
+
+// String formats the warning for display
+func (w LintWarning) String() string {
+	if w.Host == "" {
+		if w.Line > 0 {
+			return fmt.Sprintf("%s: line %d: %s", w.File, w.Line, w.Message)
+		}
+		return fmt.Sprintf("%s: %s", w.File, w.Message)
+	}
+	if w.File == "" {
+		return fmt.Sprintf("%s: %s", w.Host, w.Message)
+	}
+	if w.Line > 0 {
+		return fmt.Sprintf("%s (%s:%d): %s", w.Host, w.File, w.Line, w.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", w.Host, w.File, w.Message)
+}
+
+// lintRule checks a single host and returns a message for each problem found
+type lintRule func(host SSHHost) []string
+
+// lintRules are applied to every host by LintHosts
+var lintRules = []lintRule{
+	lintProxyConflict,
+	lintLegacyAlgorithms,
+}
+
+// LintHosts checks hosts for suspicious configurations
+func LintHosts(hosts []SSHHost) []LintWarning {
+	var warnings []LintWarning
+	for _, host := range hosts {
+		for _, rule := range lintRules {
+			for _, message := range rule(host) {
+				warnings = append(warnings, LintWarning{Host: host.Name, File: host.SourceFile, Line: host.Line, Message: message})
+			}
+		}
+	}
+	return warnings
+}
+
+// lintProxyConflict warns when both ProxyJump and ProxyCommand are set, since ssh only uses the first one
+func lintProxyConflict(host SSHHost) []string {
+	if host.ProxyJump != "" && host.ProxyCommand != "" {
+		jumpLine, commandLine := host.DirectiveLines["proxyjump"], host.DirectiveLines["proxycommand"]
+		if jumpLine > 0 && commandLine > 0 {
+			ignored, line := "ProxyCommand", commandLine
+			if commandLine < jumpLine {
+				ignored, line = "ProxyJump", jumpLine
+			}
+			return []string{fmt.Sprintf("both ProxyJump and ProxyCommand are set; ssh ignores the %s on line %d", ignored, line)}
+		}
+		return []string{"both ProxyJump and ProxyCommand are set; ssh uses whichever appears first and ignores the other"}
+	}
+	return nil
+}
+
+// LintConfigFiles checks config files for problems that belong to no host, such as
+// tags comments that are not followed by a host
+func LintConfigFiles(files []string) []LintWarning {
+	var warnings []LintWarning
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range orphanedTagComments(string(content)) {
+			warnings = append(warnings, LintWarning{
+				File:    file,
+				Line:    line,
+				Message: "tags comment is not followed by a host, so its tags are ignored",
+			})
+		}
+	}
+	return warnings
+}
+
+// orphanedTagComments returns the line numbers of "# Tags:" comments the parser attaches
+// to no host: those before a Match or wildcard-only Host, before a global directive, or
+// at the end of the file
+func orphanedTagComments(content string) []int {
+	lines, _ := configtext.Split(content)
+	var orphaned, pending []int
+	inHost := false
+
+	for i, line := range lines {
+		line = configtext.TrimLine(line)
+		if tags, ok := strings.CutPrefix(line, configtext.TagsPrefix); ok {
+			if strings.TrimSpace(tags) != "" {
+				pending = append(pending, i+1)
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch key := strings.ToLower(fields[0]); {
+		case key == "host":
+			names, _ := configtext.HostNames(line)
+			inHost = slices.ContainsFunc(names, func(name string) bool {
+				return !configtext.IsPattern(name)
+			})
+			if !inHost {
+				orphaned = append(orphaned, pending...)
+			}
+		case key == "match", !inHost:
+			inHost = false
+			orphaned = append(orphaned, pending...)
+		}
+		pending = nil
+	}
+	return append(orphaned, pending...)
+}
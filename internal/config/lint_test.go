@@ -0,0 +1,81 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLintHostsProxyConflict(t *testing.T) {
+	hosts := []SSHHost{
+		{Name: "jump-only", ProxyJump: "bastion"},
+		{Name: "command-only", ProxyCommand: "ssh -W %h:%p bastion"},
+		{Name: "both", ProxyJump: "bastion", ProxyCommand: "ssh -W %h:%p bastion", SourceFile: "/etc/ssh/config"},
+	}
+
+	warnings := LintHosts(hosts)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
+	}
+	if warnings[0].Host != "both" || warnings[0].File != "/etc/ssh/config" {
+		t.Errorf("Unexpected warning target: %+v", warnings[0])
+	}
+	if !strings.Contains(warnings[0].String(), "ProxyJump and ProxyCommand") {
+		t.Errorf("Unexpected warning message: %s", warnings[0])
+	}
+}
+
+func TestOrphanedTagComments(t *testing.T) {
+	content := `# Tags: global
+ServerAliveInterval 30
+
+# Tags: web
+Host web
+    HostName web.example.com
+
+# Tags: defaults
+Host *
+    User admin
+
+# Tags: matched
+Match host db
+    User postgres
+
+# Tags: trailing
+`
+	if got, want := orphanedTagComments(content), []int{1, 8, 12, 16}; !slices.Equal(got, want) {
+		t.Errorf("orphanedTagComments() = %v, want %v", got, want)
+	}
+
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, content)
+	warnings := LintConfigFiles([]string{configFile})
+	if len(warnings) != 4 {
+		t.Fatalf("Expected 4 warnings, got %v", warnings)
+	}
+	if want := configFile + ": line 1: tags comment"; !strings.HasPrefix(warnings[0].String(), want) {
+		t.Errorf("Unexpected warning %q", warnings[0])
+	}
+}
+
+func TestLintHostsProxyConflictNamesIgnoredLine(t *testing.T) {
+	hosts := []SSHHost{{
+		Name:           "both",
+		ProxyJump:      "bastion",
+		ProxyCommand:   "ssh -W %h:%p bastion",
+		SourceFile:     "/etc/ssh/config",
+		Line:           4,
+		DirectiveLines: map[string]int{"proxycommand": 5, "proxyjump": 7},
+	}}
+
+	warnings := LintHosts(hosts)
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(warnings), warnings)
+	}
+	want := "both (/etc/ssh/config:4): both ProxyJump and ProxyCommand are set; ssh ignores the ProxyJump on line 7"
+	if got := warnings[0].String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maintenance marks a host as under maintenance, so that connecting to it needs
+// to be confirmed
+type Maintenance struct {
+	Until time.Time `json:"until,omitempty"` // Zero until maintenance is ended by hand
+}
+
+// Active reports whether the maintenance still holds at now
+func (m Maintenance) Active(now time.Time) bool {
+	return m.Until.IsZero() || now.Before(m.Until)
+}
+
+// InMaintenance returns the maintenance of a host, if it still holds at now
+func (c *AppConfig) InMaintenance(hostName string, now time.Time) (Maintenance, bool) {
+	if c == nil {
+		return Maintenance{}, false
+	}
+	maintenance, ok := c.Maintenance[hostName]
+	if !ok || !maintenance.Active(now) {
+		return Maintenance{}, false
+	}
+	return maintenance, true
+}
+
+// SetMaintenance puts hosts under maintenance until the given time, or until it
+// is ended by hand when until is zero
+func (c *AppConfig) SetMaintenance(hostNames []string, until time.Time) {
+	if c.Maintenance == nil {
+		c.Maintenance = make(map[string]Maintenance)
+	}
+	for _, name := range hostNames {
+		c.Maintenance[name] = Maintenance{Until: until}
+	}
+}
+
+// ClearMaintenance ends the maintenance of hosts
+func (c *AppConfig) ClearMaintenance(hostNames []string) {
+	for _, name := range hostNames {
+		delete(c.Maintenance, name)
+	}
+}
+
+// RenameMaintenance moves the maintenance of a renamed host to its new name
+func (c *AppConfig) RenameMaintenance(oldName, newName string) bool {
+	maintenance, ok := c.Maintenance[oldName]
+	if !ok || oldName == newName {
+		return false
+	}
+	delete(c.Maintenance, oldName)
+	c.Maintenance[newName] = maintenance
+	return true
+}
+
+// ExpireMaintenance removes the maintenance whose end time has passed at now and
+// returns the hosts it was removed from. It is called when the config is loaded
+// or reloaded rather than on a timer.
+func (c *AppConfig) ExpireMaintenance(now time.Time) []string {
+	var expired []string
+	for name, maintenance := range c.Maintenance {
+		if !maintenance.Active(now) {
+			delete(c.Maintenance, name)
+			expired = append(expired, name)
+		}
+	}
+	sort.Strings(expired)
+	return expired
+}
+
+// ParseMaintenanceUntil reads when a maintenance ends: "" for no end, a duration
+// from now such as "90m", "2h" or "3d", or a local time as "2006-01-02 15:04" or
+// "2006-01-02"
+func ParseMaintenanceUntil(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration <= 0 {
+			return time.Time{}, fmt.Errorf("maintenance must end in the future, got %s", value)
+		}
+		return now.Add(duration), nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if until, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			if !until.After(now) {
+				return time.Time{}, fmt.Errorf("maintenance must end in the future, got %s", value)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid end %q, use a duration such as 2h or 3d, or a time as YYYY-MM-DD HH:MM", value)
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceExpiresLazily(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	c := &AppConfig{}
+	c.SetMaintenance([]string{"db-1", "db-2"}, now.Add(2*time.Hour))
+	c.SetMaintenance([]string{"legacy"}, time.Time{})
+
+	if _, ok := c.InMaintenance("db-1", now); !ok {
+		t.Error("db-1 is not in maintenance right after it was set")
+	}
+	if _, ok := c.InMaintenance("web", now); ok {
+		t.Error("web is in maintenance without it being set")
+	}
+
+	// Past the end, the maintenance no longer holds even before it is expired
+	later := now.Add(2*time.Hour + time.Second)
+	if _, ok := c.InMaintenance("db-1", later); ok {
+		t.Error("db-1 is still in maintenance after its end")
+	}
+	if maintenance, ok := c.InMaintenance("legacy", later.AddDate(1, 0, 0)); !ok || !maintenance.Until.IsZero() {
+		t.Error("maintenance without an end stopped on its own")
+	}
+	if len(c.Maintenance) != 3 {
+		t.Fatalf("maintenance was removed before being expired: %v", c.Maintenance)
+	}
+
+	if expired := c.ExpireMaintenance(now.Add(time.Hour)); len(expired) != 0 {
+		t.Errorf("ExpireMaintenance() before the end = %v, want nothing", expired)
+	}
+	if expired := c.ExpireMaintenance(later); !slices.Equal(expired, []string{"db-1", "db-2"}) {
+		t.Errorf("ExpireMaintenance() = %v, want db-1 and db-2", expired)
+	}
+	if _, ok := c.Maintenance["legacy"]; !ok || len(c.Maintenance) != 1 {
+		t.Errorf("maintenance after expiry = %v, want only legacy", c.Maintenance)
+	}
+
+	c.ClearMaintenance([]string{"legacy"})
+	if len(c.Maintenance) != 0 {
+		t.Errorf("maintenance after clearing = %v", c.Maintenance)
+	}
+}
+
+func TestMaintenanceSurvivesSave(t *testing.T) {
+	until := time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)
+	c := &AppConfig{}
+	c.SetMaintenance([]string{"db-1"}, until)
+	c.SetMaintenance([]string{"legacy"}, time.Time{})
+	if !c.RenameMaintenance("db-1", "db-primary") {
+		t.Fatal("RenameMaintenance() = false")
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded AppConfig
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Maintenance["db-primary"].Until; !got.Equal(until) {
+		t.Errorf("db-primary ends at %v after a round trip, want %v", got, until)
+	}
+	if _, ok := loaded.Maintenance["legacy"]; !ok {
+		t.Error("maintenance without an end was lost in a round trip")
+	}
+}
+
+func TestParseMaintenanceUntil(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"90m", now.Add(90 * time.Minute), false},
+		{" 2h ", now.Add(2 * time.Hour), false},
+		{"3d", now.AddDate(0, 0, 3), false},
+		{"2026-03-14 18:00", time.Date(2026, 3, 14, 18, 0, 0, 0, time.UTC), false},
+		{"2026-03-16", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), false},
+		{"2026-03-14 08:00", time.Time{}, true},
+		{"-1h", time.Time{}, true},
+		{"tomorrow", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMaintenanceUntil(tt.value, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("ParseMaintenanceUntil(%q) = %v, %v; want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+)
+
+// BlockConflict is a pair of Host blocks in different files that declare some of
+// the same names. ssh reads First before Second, so each setting of First wins.
+type BlockConflict struct {
+	Names  []string // Names both blocks declare
+	First  SSHHost
+	Second SSHHost
+}
+
+// String describes the conflict for the message drawer
+func (c BlockConflict) String() string {
+	return fmt.Sprintf("%s declared in both %s:%d and %s:%d", strings.Join(c.Names, ", "),
+		c.First.SourceFile, c.First.Line, c.Second.SourceFile, c.Second.Line)
+}
+
+// blockNames returns every name of the Host line a host was declared on
+func blockNames(host SSHHost) []string {
+	if len(host.BlockNames) > 0 {
+		return host.BlockNames
+	}
+	return []string{host.Name}
+}
+
+// FindBlockConflicts returns the pairs of blocks from different files that declare
+// a name in common, in the order ssh reads them. Two blocks of the same file are
+// left alone, editing one of them is unambiguous.
+func FindBlockConflicts(hosts []SSHHost) []BlockConflict {
+	var blocks []SSHHost
+	for _, host := range UniqueHostBlocks(hosts) {
+		if host.Line > 0 && host.SourceFile != "" {
+			blocks = append(blocks, host)
+		}
+	}
+	slices.SortStableFunc(blocks, func(a, b SSHHost) int { return cmp.Compare(a.Order, b.Order) })
+
+	byName := make(map[string][]int)
+	for i, block := range blocks {
+		for _, name := range blockNames(block) {
+			if !configtext.IsPattern(name) && !slices.Contains(byName[name], i) {
+				byName[name] = append(byName[name], i)
+			}
+		}
+	}
+
+	type pair struct{ first, second int }
+	var pairs []pair
+	shared := make(map[pair][]string)
+	for i, block := range blocks {
+		for _, name := range blockNames(block) {
+			for _, j := range byName[name] {
+				if j <= i || blocks[j].SourceFile == block.SourceFile {
+					continue
+				}
+				p := pair{i, j}
+				if _, ok := shared[p]; !ok {
+					pairs = append(pairs, p)
+				}
+				if !slices.Contains(shared[p], name) {
+					shared[p] = append(shared[p], name)
+				}
+			}
+		}
+	}
+	slices.SortFunc(pairs, func(a, b pair) int {
+		return cmp.Or(cmp.Compare(a.first, b.first), cmp.Compare(a.second, b.second))
+	})
+
+	conflicts := make([]BlockConflict, 0, len(pairs))
+	for _, p := range pairs {
+		conflicts = append(conflicts, BlockConflict{Names: shared[p], First: blocks[p.first], Second: blocks[p.second]})
+	}
+	return conflicts
+}
+
+// MergeSide picks which block a directive of a merge is taken from
+type MergeSide int
+
+const (
+	MergeFirst MergeSide = iota
+	MergeSecond
+)
+
+// MergeDirective is one setting of two conflicting blocks. A directive given more
+// than once, such as LocalForward, has its values joined by newlines.
+type MergeDirective struct {
+	Key    string
+	First  string // "" when the first block does not set it
+	Second string
+}
+
+// Differs reports whether the blocks disagree on the directive
+func (d MergeDirective) Differs() bool {
+	return d.First != d.Second
+}
+
+// Value returns the directive's value on side
+func (d MergeDirective) Value(side MergeSide) string {
+	if side == MergeSecond {
+		return d.Second
+	}
+	return d.First
+}
+
+// mergeField is a setting sshc keeps in its own SSHHost field rather than in Options
+type mergeField struct {
+	key string
+	get func(SSHHost) string
+	set func(*SSHHost, string)
+}
+
+// mergeFields are compared before the options, in the order blocks are written
+var mergeFields = []mergeField{
+	{"Description", func(h SSHHost) string { return h.Description }, func(h *SSHHost, v string) { h.Description = v }},
+	{"Tags", func(h SSHHost) string { return strings.Join(h.Tags, ", ") }, func(h *SSHHost, v string) { h.Tags = splitMergedTags(v) }},
+	{"Expires", func(h SSHHost) string { return h.Expires }, func(h *SSHHost, v string) { h.Expires = v }},
+	{"HostName", func(h SSHHost) string { return h.Hostname }, func(h *SSHHost, v string) { h.Hostname = v }},
+	{"User", func(h SSHHost) string { return h.User }, func(h *SSHHost, v string) { h.User = v }},
+	{"Port", mergedPort, func(h *SSHHost, v string) { h.Port = v }},
+	{"IdentityFile", func(h SSHHost) string { return h.Identity }, func(h *SSHHost, v string) { h.Identity = v }},
+	{"ProxyJump", func(h SSHHost) string { return h.ProxyJump }, func(h *SSHHost, v string) { h.ProxyJump = v }},
+	{"ProxyCommand", func(h SSHHost) string { return h.ProxyCommand }, func(h *SSHHost, v string) { h.ProxyCommand = v }},
+	{"RemoteCommand", func(h SSHHost) string { return h.RemoteCommand }, func(h *SSHHost, v string) { h.RemoteCommand = v }},
+	{"RequestTTY", func(h SSHHost) string { return h.RequestTTY }, func(h *SSHHost, v string) { h.RequestTTY = v }},
+}
+
+// mergedPort returns the port a block sets. The parser fills in 22 for blocks
+// without a Port, which only counts when the directive is written.
+func mergedPort(h SSHHost) string {
+	if _, set := h.DirectiveLines["port"]; set || h.Port != "22" {
+		return h.Port
+	}
+	return ""
+}
+
+// splitMergedTags reads back the tags joined by the Tags directive of a merge
+func splitMergedTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tags = configtext.MergeTags(tags, strings.TrimSpace(tag))
+	}
+	return slices.DeleteFunc(tags, func(tag string) bool { return tag == "" })
+}
+
+// optionValues returns the options of a block by lowercase keyword, with the
+// keywords as first written in the order they first appear
+func optionValues(options string) (keywords []string, values map[string][]string) {
+	values = make(map[string][]string)
+	for _, line := range strings.Split(options, "\n") {
+		keyword, value := splitOption(line)
+		if keyword == "" {
+			continue
+		}
+		lower := strings.ToLower(keyword)
+		if _, seen := values[lower]; !seen {
+			keywords = append(keywords, keyword)
+		}
+		values[lower] = append(values[lower], value)
+	}
+	return keywords, values
+}
+
+// DiffBlocks lists every directive either block sets, those of first in order
+// followed by the ones only second has. Option keywords are compared in any case.
+func DiffBlocks(first, second SSHHost) []MergeDirective {
+	var directives []MergeDirective
+	for _, field := range mergeFields {
+		if d := (MergeDirective{Key: field.key, First: field.get(first), Second: field.get(second)}); d.First != "" || d.Second != "" {
+			directives = append(directives, d)
+		}
+	}
+
+	firstKeywords, firstValues := optionValues(first.Options)
+	secondKeywords, secondValues := optionValues(second.Options)
+	for _, keyword := range append(firstKeywords, secondKeywords...) {
+		lower := strings.ToLower(keyword)
+		if slices.ContainsFunc(directives, func(d MergeDirective) bool { return strings.EqualFold(d.Key, keyword) }) {
+			continue
+		}
+		directives = append(directives, MergeDirective{
+			Key:    keyword,
+			First:  strings.Join(firstValues[lower], "\n"),
+			Second: strings.Join(secondValues[lower], "\n"),
+		})
+	}
+	return directives
+}
+
+// DefaultMergeChoices picks, for each directive, the value ssh uses today: the
+// first block's, unless only the second sets it
+func DefaultMergeChoices(directives []MergeDirective) []MergeSide {
+	choices := make([]MergeSide, len(directives))
+	for i, d := range directives {
+		if d.First == "" && d.Second != "" {
+			choices[i] = MergeSecond
+		}
+	}
+	return choices
+}
+
+// MergedNames returns the names of the merged block: those of first, then the
+// ones only second declares
+func MergedNames(conflict BlockConflict) []string {
+	names := slices.Clone(blockNames(conflict.First))
+	for _, name := range blockNames(conflict.Second) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MergeBlocks builds the block that replaces both blocks of a conflict, taking each
+// directive from the side chosen for it. A side that does not set a directive
+// drops it from the merge.
+func MergeBlocks(conflict BlockConflict, directives []MergeDirective, choices []MergeSide) SSHHost {
+	names := MergedNames(conflict)
+	merged := SSHHost{Name: names[0]}
+	if len(names) > 1 {
+		merged.BlockNames = names
+	}
+
+	var options []string
+	for i, d := range directives {
+		side := MergeFirst
+		if i < len(choices) {
+			side = choices[i]
+		}
+		value := d.Value(side)
+		if value == "" {
+			continue
+		}
+		if field := slices.IndexFunc(mergeFields, func(f mergeField) bool { return f.key == d.Key }); field >= 0 {
+			mergeFields[field].set(&merged, value)
+			continue
+		}
+		for _, v := range strings.Split(value, "\n") {
+			options = append(options, d.Key+" "+v)
+		}
+	}
+	merged.Options = strings.Join(options, "\n")
+	return merged
+}
+
+// ResolveBlockConflict writes merged in place of the block of the conflict that is
+// in targetFile, and removes the other block from its file, or comments it out
+// when commentOut is set. Both files are written or neither is.
+func ResolveBlockConflict(conflict BlockConflict, merged SSHHost, targetFile string, commentOut bool) error {
+	keep, other := conflict.First, conflict.Second
+	switch targetFile {
+	case conflict.First.SourceFile:
+	case conflict.Second.SourceFile:
+		keep, other = other, keep
+	default:
+		return fmt.Errorf("'%s' holds neither block of %s", targetFile, strings.Join(conflict.Names, ", "))
+	}
+	for _, block := range []SSHHost{keep, other} {
+		if block.ReadOnly {
+			return fmt.Errorf("%w: %s", ErrReadOnlyFile, block.SourceFile)
+		}
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	names := blockNames(merged)
+	var rewrites []fileRewrite
+	for _, block := range []SSHHost{keep, other} {
+		content, err := os.ReadFile(block.SourceFile)
+		if err != nil {
+			return err
+		}
+		lines, format := configtext.Split(string(content))
+		start, end, err := blockSpan(lines, block)
+		if err != nil {
+			return err
+		}
+
+		var replacement []string
+		switch {
+		case block.SourceFile == keep.SourceFile:
+			replacement = formatHostBlock(names, merged)
+		case commentOut:
+			replacement = append(replacement, fmt.Sprintf("# Merged into %s", keep.SourceFile))
+			for _, line := range lines[start:end] {
+				if strings.TrimSpace(line) != "" {
+					line = "# " + line
+				}
+				replacement = append(replacement, line)
+			}
+		default:
+			// Take the blank line after the block with it
+			if end < len(lines) && strings.TrimSpace(lines[end]) == "" && end+1 < len(lines) {
+				end++
+			}
+		}
+		after := slices.Concat(lines[:start:start], replacement, lines[end:])
+		rewrites = append(rewrites, fileRewrite{path: block.SourceFile, before: content, after: format.Join(after), hosts: names})
+	}
+	return applyRewrites(rewrites, AuditMerge)
+}
+
+// blockSpan returns the lines of the block a host was parsed from: its metadata
+// comments, its Host line and its directives, as found by configtext.BlockEnd. It fails when the file changed since
+// and the host's line no longer declares it.
+func blockSpan(lines []string, host SSHHost) (int, int, error) {
+	i := host.Line - 1
+	if i < 0 || i >= len(lines) {
+		return 0, 0, fmt.Errorf("%s changed on disk, reload before merging", host.SourceFile)
+	}
+	if names, ok := configtext.HostNames(configtext.TrimLine(lines[i])); !ok || !slices.Contains(names, host.Name) {
+		return 0, 0, fmt.Errorf("%s changed on disk, reload before merging", host.SourceFile)
+	}
+
+	start := i
+	for j := i - 1; j >= 0; j-- {
+		line := configtext.TrimLine(lines[j])
+		if configtext.IsMetadataComment(line) {
+			start = j
+		} else if line != "" {
+			break
+		}
+	}
+
+	return start, configtext.BlockEnd(lines, i), nil
+}
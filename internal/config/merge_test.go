@@ -0,0 +1,379 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConflictingConfigs writes a main config including override.conf after its
+// own blocks, and returns the paths of both
+func writeConflictingConfigs(t *testing.T, base, override string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.conf")
+	overridePath := filepath.Join(dir, "override.conf")
+	writeFile(t, basePath, base+"\nInclude "+overridePath+"\n")
+	writeFile(t, overridePath, override)
+	return basePath, overridePath
+}
+
+func parseConflicts(t *testing.T, configPath string) []BlockConflict {
+	t.Helper()
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	return FindBlockConflicts(hosts)
+}
+
+func TestFindBlockConflicts(t *testing.T) {
+	basePath, overridePath := writeConflictingConfigs(t, `Host web1 web2
+    HostName web.example.com
+
+Host db
+    HostName db.example.com
+
+Host cache
+    HostName cache.example.com
+
+Host *.internal
+    User ops
+`, `Host web2 web1 web3
+    HostName web.override.com
+
+Host cache
+    HostName cache.override.com
+
+Host *.internal
+    User admin
+
+Host other
+    HostName other.example.com
+`)
+
+	conflicts := parseConflicts(t, basePath)
+	if len(conflicts) != 2 {
+		t.Fatalf("FindBlockConflicts() = %d conflicts, want 2: %v", len(conflicts), conflicts)
+	}
+
+	web := conflicts[0]
+	if !reflect.DeepEqual(web.Names, []string{"web1", "web2"}) {
+		t.Errorf("Names = %v, want [web1 web2]", web.Names)
+	}
+	if web.First.SourceFile != basePath || web.First.Line != 1 {
+		t.Errorf("First = %s:%d, want %s:1", web.First.SourceFile, web.First.Line, basePath)
+	}
+	if web.Second.SourceFile != overridePath || web.Second.Line != 1 {
+		t.Errorf("Second = %s:%d, want %s:1", web.Second.SourceFile, web.Second.Line, overridePath)
+	}
+	if want := "web1, web2 declared in both " + basePath + ":1 and " + overridePath + ":1"; web.String() != want {
+		t.Errorf("String() = %q, want %q", web.String(), want)
+	}
+
+	if !reflect.DeepEqual(conflicts[1].Names, []string{"cache"}) {
+		t.Errorf("second conflict Names = %v, want [cache]", conflicts[1].Names)
+	}
+}
+
+func TestFindBlockConflictsIgnoresSameFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	writeFile(t, configPath, "Host web\n    HostName a.example.com\n\nHost web\n    HostName b.example.com\n")
+	if conflicts := parseConflicts(t, configPath); len(conflicts) != 0 {
+		t.Errorf("FindBlockConflicts() = %v, want none", conflicts)
+	}
+}
+
+func TestFindBlockConflictsFollowsReadOrder(t *testing.T) {
+	// The Include comes first, so ssh reads the override before the base block
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.conf")
+	overridePath := filepath.Join(dir, "override.conf")
+	writeFile(t, basePath, "Include "+overridePath+"\n\nHost web\n    HostName base.example.com\n")
+	writeFile(t, overridePath, "Host web\n    HostName override.example.com\n")
+
+	hosts, err := ParseSSHConfigFile(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The list view hands over hosts sorted for display, not in read order
+	hosts[0], hosts[1] = hosts[1], hosts[0]
+
+	conflicts := FindBlockConflicts(hosts)
+	if len(conflicts) != 1 {
+		t.Fatalf("FindBlockConflicts() = %v, want 1 conflict", conflicts)
+	}
+	if conflicts[0].First.SourceFile != overridePath {
+		t.Errorf("First is from %s, want %s", conflicts[0].First.SourceFile, overridePath)
+	}
+}
+
+func TestDiffBlocks(t *testing.T) {
+	first := SSHHost{
+		Name: "web", Hostname: "web.example.com", User: "deploy", Port: "22",
+		Tags:           []string{"prod"},
+		Options:        "ServerAliveInterval 30\nLocalForward 8080 localhost:80\nLocalForward 8443 localhost:443",
+		DirectiveLines: map[string]int{},
+	}
+	second := SSHHost{
+		Name: "web", Hostname: "web.override.com", Port: "2222", Identity: "~/.ssh/web",
+		Tags:           []string{"prod"},
+		Options:        "serveraliveinterval=60\nCompression yes",
+		DirectiveLines: map[string]int{"port": 2},
+	}
+
+	want := []MergeDirective{
+		{Key: "Tags", First: "prod", Second: "prod"},
+		{Key: "HostName", First: "web.example.com", Second: "web.override.com"},
+		{Key: "User", First: "deploy"},
+		{Key: "Port", Second: "2222"},
+		{Key: "IdentityFile", Second: "~/.ssh/web"},
+		{Key: "ServerAliveInterval", First: "30", Second: "60"},
+		{Key: "LocalForward", First: "8080 localhost:80\n8443 localhost:443"},
+		{Key: "Compression", Second: "yes"},
+	}
+	if got := DiffBlocks(first, second); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffBlocks() =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestDiffBlocksWrittenDefaultPort(t *testing.T) {
+	// Port 22 written out still beats the port of a later block
+	first := SSHHost{Name: "web", Port: "22", DirectiveLines: map[string]int{"port": 3}}
+	second := SSHHost{Name: "web", Port: "2222", DirectiveLines: map[string]int{"port": 3}}
+	directives := DiffBlocks(first, second)
+	if len(directives) != 1 || directives[0] != (MergeDirective{Key: "Port", First: "22", Second: "2222"}) {
+		t.Errorf("DiffBlocks() = %v, want Port 22 against 2222", directives)
+	}
+}
+
+func TestDefaultMergeChoices(t *testing.T) {
+	directives := []MergeDirective{
+		{Key: "HostName", First: "a", Second: "b"},
+		{Key: "User", First: "deploy"},
+		{Key: "Port", Second: "2222"},
+		{Key: "Compression", First: "yes", Second: "yes"},
+	}
+	want := []MergeSide{MergeFirst, MergeFirst, MergeSecond, MergeFirst}
+	if got := DefaultMergeChoices(directives); !reflect.DeepEqual(got, want) {
+		t.Errorf("DefaultMergeChoices() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeBlocks(t *testing.T) {
+	conflict := BlockConflict{
+		Names:  []string{"web1", "web2"},
+		First:  SSHHost{Name: "web1", BlockNames: []string{"web1", "web2"}},
+		Second: SSHHost{Name: "web2", BlockNames: []string{"web2", "web1", "web3"}},
+	}
+	directives := []MergeDirective{
+		{Key: "Tags", First: "prod, web", Second: "staging"},
+		{Key: "HostName", First: "web.example.com", Second: "web.override.com"},
+		{Key: "User", First: "deploy"},
+		{Key: "Port", Second: "2222"},
+		{Key: "ServerAliveInterval", First: "30", Second: "60"},
+		{Key: "LocalForward", First: "8080 localhost:80\n8443 localhost:443"},
+	}
+	choices := []MergeSide{MergeFirst, MergeSecond, MergeSecond, MergeSecond, MergeSecond, MergeFirst}
+
+	got := MergeBlocks(conflict, directives, choices)
+	want := SSHHost{
+		Name:       "web1",
+		BlockNames: []string{"web1", "web2", "web3"},
+		Tags:       []string{"prod", "web"},
+		Hostname:   "web.override.com",
+		Port:       "2222",
+		Options:    "ServerAliveInterval 60\nLocalForward 8080 localhost:80\nLocalForward 8443 localhost:443",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeBlocks() =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestMergeBlocksDefaultsMatchSSH(t *testing.T) {
+	// With the default choices the merged block resolves as both blocks did
+	basePath, _ := writeConflictingConfigs(t,
+		"Host web\n    HostName web.example.com\n    User deploy\n",
+		"Host web\n    HostName web.override.com\n    Port 2222\n    Compression yes\n")
+	conflict := parseConflicts(t, basePath)[0]
+	directives := DiffBlocks(conflict.First, conflict.Second)
+
+	got := MergeBlocks(conflict, directives, DefaultMergeChoices(directives))
+	if got.Hostname != "web.example.com" || got.User != "deploy" || got.Port != "2222" || got.Options != "Compression yes" {
+		t.Errorf("MergeBlocks() = %+v", got)
+	}
+}
+
+const (
+	mergeBase = `# Tags: prod
+Host web1 web2
+    HostName web.example.com
+    User deploy
+
+Host db
+    HostName db.example.com
+`
+	mergeOverride = `Host cache
+    HostName cache.example.com
+
+Host web2 web1
+    HostName web.override.com
+    Port 2222
+
+Host other
+    HostName other.example.com
+`
+)
+
+func TestResolveBlockConflictRemovesOther(t *testing.T) {
+	setupAuditTest(t)
+	basePath, overridePath := writeConflictingConfigs(t, mergeBase, mergeOverride)
+	conflict := parseConflicts(t, basePath)[0]
+	directives := DiffBlocks(conflict.First, conflict.Second)
+	merged := MergeBlocks(conflict, directives, DefaultMergeChoices(directives))
+
+	if err := ResolveBlockConflict(conflict, merged, basePath, false); err != nil {
+		t.Fatalf("ResolveBlockConflict() error = %v", err)
+	}
+
+	base, _ := os.ReadFile(basePath)
+	wantBase := `# Tags: prod
+Host web1 web2
+    HostName web.example.com
+    User deploy
+    Port 2222
+
+Host db
+    HostName db.example.com
+
+Include ` + overridePath + "\n"
+	if string(base) != wantBase {
+		t.Errorf("base.conf =\n%s\nwant\n%s", base, wantBase)
+	}
+
+	override, _ := os.ReadFile(overridePath)
+	wantOverride := `Host cache
+    HostName cache.example.com
+
+Host other
+    HostName other.example.com
+`
+	if string(override) != wantOverride {
+		t.Errorf("override.conf =\n%s\nwant\n%s", override, wantOverride)
+	}
+
+	if conflicts := parseConflicts(t, basePath); len(conflicts) != 0 {
+		t.Errorf("conflicts after merge = %v, want none", conflicts)
+	}
+
+	entries, err := ReadAuditLog(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Operation != AuditMerge || entries[0].Host != "web1, web2" {
+		t.Errorf("audit entries = %+v, want two merges of web1, web2", entries)
+	}
+}
+
+func TestResolveBlockConflictCommentsOutOther(t *testing.T) {
+	setupAuditTest(t)
+	basePath, overridePath := writeConflictingConfigs(t, mergeBase, mergeOverride)
+	conflict := parseConflicts(t, basePath)[0]
+	directives := DiffBlocks(conflict.First, conflict.Second)
+	merged := MergeBlocks(conflict, directives, []MergeSide{MergeFirst, MergeSecond, MergeFirst, MergeSecond})
+
+	if err := ResolveBlockConflict(conflict, merged, overridePath, true); err != nil {
+		t.Fatalf("ResolveBlockConflict() error = %v", err)
+	}
+
+	override, _ := os.ReadFile(overridePath)
+	wantOverride := `Host cache
+    HostName cache.example.com
+
+# Tags: prod
+Host web1 web2
+    HostName web.override.com
+    User deploy
+    Port 2222
+
+Host other
+    HostName other.example.com
+`
+	if string(override) != wantOverride {
+		t.Errorf("override.conf =\n%s\nwant\n%s", override, wantOverride)
+	}
+
+	base, _ := os.ReadFile(basePath)
+	wantBase := `# Merged into ` + overridePath + `
+# # Tags: prod
+# Host web1 web2
+#     HostName web.example.com
+#     User deploy
+
+Host db
+    HostName db.example.com
+
+Include ` + overridePath + "\n"
+	if string(base) != wantBase {
+		t.Errorf("base.conf =\n%s\nwant\n%s", base, wantBase)
+	}
+
+	hosts, err := ParseSSHConfigFile(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range hosts {
+		if host.Name == "web1" && host.Hostname != "web.override.com" {
+			t.Errorf("web1 resolves to %s, want web.override.com", host.Hostname)
+		}
+	}
+}
+
+func TestResolveBlockConflictKeepsLineEndings(t *testing.T) {
+	setupAuditTest(t)
+	basePath, overridePath := writeConflictingConfigs(t,
+		"Host web\r\n    HostName web.example.com\r\n",
+		"Host web\r\n    HostName web.override.com\r\n\r\nHost other\r\n    HostName other.example.com\r\n")
+	conflict := parseConflicts(t, basePath)[0]
+	directives := DiffBlocks(conflict.First, conflict.Second)
+	merged := MergeBlocks(conflict, directives, DefaultMergeChoices(directives))
+
+	if err := ResolveBlockConflict(conflict, merged, basePath, false); err != nil {
+		t.Fatalf("ResolveBlockConflict() error = %v", err)
+	}
+	override, _ := os.ReadFile(overridePath)
+	if want := "Host other\r\n    HostName other.example.com\r\n"; string(override) != want {
+		t.Errorf("override.conf = %q, want %q", override, want)
+	}
+}
+
+func TestResolveBlockConflictRefusesStaleOrReadOnly(t *testing.T) {
+	setupAuditTest(t)
+	basePath, overridePath := writeConflictingConfigs(t, mergeBase, mergeOverride)
+	conflict := parseConflicts(t, basePath)[0]
+	merged := MergeBlocks(conflict, nil, nil)
+
+	if err := ResolveBlockConflict(conflict, merged, filepath.Join(t.TempDir(), "elsewhere"), false); err == nil {
+		t.Error("ResolveBlockConflict() to a third file succeeded, want an error")
+	}
+
+	readOnly := conflict
+	readOnly.Second.ReadOnly = true
+	if err := ResolveBlockConflict(readOnly, merged, basePath, false); !errors.Is(err, ErrReadOnlyFile) {
+		t.Errorf("ResolveBlockConflict() with a read-only file error = %v, want ErrReadOnlyFile", err)
+	}
+
+	// The override changed since it was parsed, so its block moved
+	writeFile(t, overridePath, "Host new\n    HostName new.example.com\n\n"+mergeOverride)
+	err := ResolveBlockConflict(conflict, merged, basePath, false)
+	if err == nil || !strings.Contains(err.Error(), "changed on disk") {
+		t.Errorf("ResolveBlockConflict() after a change error = %v, want changed on disk", err)
+	}
+	if base, _ := os.ReadFile(basePath); !strings.HasPrefix(string(base), mergeBase) {
+		t.Errorf("base.conf was written despite the error:\n%s", base)
+	}
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+// writeMovedFile writes a file during a move; tests replace it to make a write fail
+var writeMovedFile = os.WriteFile
+
+// fileRewrite is the new content of one file touched by a move
+type fileRewrite struct {
+	path   string
+	before []byte
+	after  string
+	hosts  []string // Hosts moved out of or into the file
+}
+
+// MoveHostsToFile moves hosts from the default SSH config and its includes to
+// targetConfigFile in one operation. Every source file is rewritten once and the
+// target is appended to once, each after a single backup. A host sharing its Host
+// line with hosts that stay is split off that line; hosts of the same line that
+// all move keep sharing one. If a write fails, the files already written are
+// restored and nothing is moved.
+func MoveHostsToFile(hostNames []string, targetConfigFile string) error {
+	return MoveHostsToFileWithOptions(hostNames, targetConfigFile, MoveOptions{})
+}
+
+// MoveHostsToFileWithOptions moves hosts like MoveHostsToFile, writing them as opts
+// says. A move whose hosts would jump through a host the target file does not see
+// fails with ErrUnresolvedJump.
+func MoveHostsToFileWithOptions(hostNames []string, targetConfigFile string, opts MoveOptions) error {
+	if len(hostNames) == 0 {
+		return nil
+	}
+	target, err := filepath.Abs(targetConfigFile)
+	if err != nil {
+		return err
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	hosts, err := ParseSSHConfig()
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]SSHHost, len(hosts))
+	for _, host := range hosts {
+		if _, seen := byName[host.Name]; !seen {
+			byName[host.Name] = host
+		}
+	}
+
+	// Check every host before touching any file
+	moving := make(map[string]bool, len(hostNames))
+	var sources []string
+	namesBySource := make(map[string][]string)
+	for _, name := range hostNames {
+		host, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("host '%s' not found in any configuration file", name)
+		}
+		if host.SourceFile == target {
+			return fmt.Errorf("host '%s' is already in the target config file '%s'", name, targetConfigFile)
+		}
+		if host.ReadOnly {
+			return fmt.Errorf("%w: %s", ErrReadOnlyFile, host.SourceFile)
+		}
+		exists, err := HostExistsInFile(name, target)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("host '%s' already exists in '%s'", name, targetConfigFile)
+		}
+		if moving[name] {
+			continue
+		}
+		moving[name] = true
+		if _, ok := namesBySource[host.SourceFile]; !ok {
+			sources = append(sources, host.SourceFile)
+		}
+		namesBySource[host.SourceFile] = append(namesBySource[host.SourceFile], name)
+	}
+	for _, finding := range analyzeMove(hosts, hostNames, target, moveScope(target)) {
+		if finding.Blocking() {
+			return fmt.Errorf("%w: %s", ErrUnresolvedJump, finding)
+		}
+	}
+
+	var rewrites []fileRewrite
+	for _, source := range sources {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		after := string(content)
+		for _, name := range namesBySource[source] {
+			if after, err = removeHostFromContent(after, name); err != nil {
+				return err
+			}
+		}
+		rewrites = append(rewrites, fileRewrite{path: source, before: content, after: after, hosts: namesBySource[source]})
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	newline := configtext.DetectFormat(string(content)).Newline()
+	after := string(content)
+	var moved []string
+	for _, names := range movedBlocks(hostNames, byName, moving) {
+		host := byName[names[0]]
+		if opts.AbsolutePaths {
+			host = withAbsoluteKeyPaths(host)
+		}
+		after += newline + strings.Join(formatHostBlock(names, host), newline) + newline
+		moved = append(moved, names...)
+	}
+	rewrites = append(rewrites, fileRewrite{path: target, before: content, after: after, hosts: moved})
+
+	return applyRewrites(rewrites, AuditMove)
+}
+
+// movedBlocks groups the moving hosts by the Host line they come from, in the
+// order they were given, so hosts of one line are written as one block
+func movedBlocks(hostNames []string, byName map[string]SSHHost, moving map[string]bool) [][]string {
+	var blocks [][]string
+	done := make(map[string]bool)
+	for _, name := range hostNames {
+		if done[name] {
+			continue
+		}
+		names := []string{name}
+		for _, alias := range byName[name].BlockNames {
+			if alias != name && moving[alias] && !done[alias] {
+				names = append(names, alias)
+			}
+		}
+		for _, n := range names {
+			done[n] = true
+		}
+		// Keep the order of the original Host line
+		if blockNames := byName[name].BlockNames; len(blockNames) > 0 {
+			slices.SortStableFunc(names, func(a, b string) int {
+				return slices.Index(blockNames, a) - slices.Index(blockNames, b)
+			})
+		}
+		blocks = append(blocks, names)
+	}
+	return blocks
+}
+
+// applyRewrites writes every file of a move or merge, after checking they are all
+// writable and backing each up once. When a write fails, the files written so far
+// are restored to their previous content.
+func applyRewrites(rewrites []fileRewrite, operation string) error {
+	for _, rewrite := range rewrites {
+		if err := checkWritable(rewrite.path, rewrite.before); err != nil {
+			return err
+		}
+	}
+	if IsDryRun() {
+		for _, rewrite := range rewrites {
+			recordDryRun(ConfigChange{Path: rewrite.path, Host: strings.Join(rewrite.hosts, ", "), Before: string(rewrite.before), After: rewrite.after})
+		}
+		return nil
+	}
+
+	for _, rewrite := range rewrites {
+		if _, err := os.Stat(rewrite.path); err == nil {
+			if err := backupConfig(rewrite.path); err != nil {
+				return fmt.Errorf("failed to create backup: %w", err)
+			}
+		}
+	}
+
+	for i, rewrite := range rewrites {
+		sshclog.Write(rewrite.path, operation)
+		path := resolveConfigPath(rewrite.path)
+		err := retryTransient(path, func() error {
+			return writeMovedFile(path, []byte(rewrite.after), 0600)
+		})
+		if err != nil {
+			if restoreErr := restoreRewrites(rewrites[:i+1]); restoreErr != nil {
+				return fmt.Errorf("failed to write %s: %w (restoring the other files failed too: %v)", rewrite.path, err, restoreErr)
+			}
+			return fmt.Errorf("failed to write %s, nothing was changed: %w", rewrite.path, err)
+		}
+	}
+
+	for _, rewrite := range rewrites {
+		recordAudit(operation, strings.Join(rewrite.hosts, ", "), rewrite.path, string(rewrite.before), rewrite.after)
+	}
+	return nil
+}
+
+// restoreRewrites puts back the previous content of files, removing the ones the
+// move created
+func restoreRewrites(rewrites []fileRewrite) error {
+	var failed []string
+	for _, rewrite := range rewrites {
+		var err error
+		if rewrite.before == nil {
+			err = os.Remove(rewrite.path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			sshclog.Write(rewrite.path, "undo move")
+			err = writeFileRetrying(rewrite.path, rewrite.before, 0600)
+		}
+		if err != nil {
+			failed = append(failed, rewrite.path)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not restore %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
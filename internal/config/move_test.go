@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupMoveTest writes a default config including two files, plus an empty target
+func setupMoveTest(t *testing.T) (sshDir string, files map[string]string) {
+	t.Helper()
+	tempDir := setupAuditTest(t)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("USERPROFILE", tempDir)
+
+	sshDir = filepath.Join(tempDir, ".ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	files = map[string]string{
+		"config": `Include a.conf
+Include b.conf
+
+Host web1 web2
+    HostName web.example.com
+    User deploy
+
+Host db
+    HostName db.example.com
+`,
+		"a.conf": `Host api
+    HostName api.example.com
+    Port 2222
+
+Host cache
+    HostName cache.example.com
+`,
+		"b.conf": `# Tags: mail
+Host mail
+    HostName mail.example.com
+`,
+		"moved.conf": "Host existing\n    HostName existing.example.com\n",
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(sshDir, name), content)
+	}
+	return sshDir, files
+}
+
+func readConfig(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestMoveHostsToFileFromThreeSources(t *testing.T) {
+	sshDir, files := setupMoveTest(t)
+	target := filepath.Join(sshDir, "moved.conf")
+
+	if err := MoveHostsToFile([]string{"mail", "web1", "api", "db"}, target); err != nil {
+		t.Fatalf("MoveHostsToFile() error = %v", err)
+	}
+
+	main := readConfig(t, filepath.Join(sshDir, "config"))
+	if !strings.Contains(main, "Host web2\n") || strings.Contains(main, "web1") || strings.Contains(main, "Host db") {
+		t.Errorf("config should keep only web2:\n%s", main)
+	}
+	if a := readConfig(t, filepath.Join(sshDir, "a.conf")); strings.Contains(a, "Host api") || !strings.Contains(a, "Host cache") {
+		t.Errorf("a.conf should keep only cache:\n%s", a)
+	}
+	if b := readConfig(t, filepath.Join(sshDir, "b.conf")); strings.Contains(b, "mail") {
+		t.Errorf("b.conf should be empty of mail:\n%s", b)
+	}
+
+	moved := readConfig(t, target)
+	if !strings.HasPrefix(moved, files["moved.conf"]) {
+		t.Errorf("target lost its content:\n%s", moved)
+	}
+	for _, want := range []string{"Host mail\n", "Host web1\n", "Host api\n", "Host db\n", "Port 2222", "User deploy", "# Tags: mail"} {
+		if !strings.Contains(moved, want) {
+			t.Errorf("target is missing %q:\n%s", want, moved)
+		}
+	}
+	if strings.Contains(moved, "web2") {
+		t.Errorf("web2 was not selected but was moved:\n%s", moved)
+	}
+
+	hosts, err := ParseSSHConfigFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 5 {
+		t.Errorf("target has %d hosts, want 5", len(hosts))
+	}
+
+	// Every touched file was backed up once, with its content before the move
+	backupDir, err := GetSSHMBackupDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if backup := readConfig(t, filepath.Join(backupDir, name+".backup")); backup != content {
+			t.Errorf("backup of %s = %q, want %q", name, backup, content)
+		}
+	}
+
+	entries, err := ReadAuditLog(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("got %d audit entries, want one per touched file", len(entries))
+	}
+}
+
+func TestMoveHostsToFileKeepsWholeBlockTogether(t *testing.T) {
+	sshDir, _ := setupMoveTest(t)
+	target := filepath.Join(sshDir, "moved.conf")
+
+	if err := MoveHostsToFile([]string{"web2", "web1"}, target); err != nil {
+		t.Fatalf("MoveHostsToFile() error = %v", err)
+	}
+	if moved := readConfig(t, target); !strings.Contains(moved, "Host web1 web2\n") {
+		t.Errorf("a fully selected block should stay one block:\n%s", moved)
+	}
+	if main := readConfig(t, filepath.Join(sshDir, "config")); strings.Contains(main, "web") {
+		t.Errorf("config still has the block:\n%s", main)
+	}
+}
+
+func TestMoveHostsToFileRollsBackOnWriteFailure(t *testing.T) {
+	sshDir, files := setupMoveTest(t)
+	target := filepath.Join(sshDir, "moved.conf")
+
+	// Fail on the target, written after every source
+	writeMovedFile = func(name string, data []byte, perm os.FileMode) error {
+		if name == target {
+			return errors.New("disk full")
+		}
+		return os.WriteFile(name, data, perm)
+	}
+	defer func() { writeMovedFile = os.WriteFile }()
+
+	err := MoveHostsToFile([]string{"web1", "api", "mail"}, target)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("MoveHostsToFile() error = %v, want the write failure", err)
+	}
+	for name, content := range files {
+		if got := readConfig(t, filepath.Join(sshDir, name)); got != content {
+			t.Errorf("%s was not restored:\n%s", name, got)
+		}
+	}
+	if entries, _ := ReadAuditLog(time.Time{}); len(entries) != 0 {
+		t.Errorf("a rolled back move was audited: %+v", entries)
+	}
+}
+
+func TestMoveHostsToFileChecksEveryHostFirst(t *testing.T) {
+	sshDir, files := setupMoveTest(t)
+	target := filepath.Join(sshDir, "moved.conf")
+	files["moved.conf"] += "\nHost db\n    HostName other-db.example.com\n"
+	writeFile(t, target, files["moved.conf"])
+
+	for _, names := range [][]string{
+		{"api", "missing"},
+		{"api", "db"},
+	} {
+		if err := MoveHostsToFile(names, target); err == nil {
+			t.Errorf("MoveHostsToFile(%v) succeeded, want an error", names)
+		}
+	}
+	for name, content := range files {
+		if got := readConfig(t, filepath.Join(sshDir, name)); got != content {
+			t.Errorf("%s was modified by a rejected move:\n%s", name, got)
+		}
+	}
+}
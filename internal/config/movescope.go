@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+)
+
+// ErrUnresolvedJump is returned when a moved host would jump through a host ssh
+// does not see from the target file
+var ErrUnresolvedJump = errors.New("jump host not defined for the target file")
+
+// MoveFindingKind is the kind of hazard found before a move
+type MoveFindingKind int
+
+const (
+	// MoveRelativePath is a key path written relative to the source file's directory
+	MoveRelativePath MoveFindingKind = iota
+	// MoveUnresolvedJump is a ProxyJump hop defined by a host the target file does not see
+	MoveUnresolvedJump
+)
+
+// MoveFinding is something that breaks when a host is written to another file
+type MoveFinding struct {
+	Host     string
+	Kind     MoveFindingKind
+	Keyword  string // Directive holding Value, such as IdentityFile
+	Value    string
+	Absolute string // What a relative path resolves to from the source file
+	Source   string // File the host or the jump host is defined in
+}
+
+// Blocking reports whether the move must not go ahead with the finding
+func (f MoveFinding) Blocking() bool {
+	return f.Kind == MoveUnresolvedJump
+}
+
+// String describes the finding for display
+func (f MoveFinding) String() string {
+	if f.Kind == MoveUnresolvedJump {
+		return fmt.Sprintf("%s: ProxyJump %s is only defined in %s, which the target file does not include", f.Host, f.Value, f.Source)
+	}
+	return fmt.Sprintf("%s: %s %s is relative to %s (%s)", f.Host, f.Keyword, f.Value, filepath.Dir(f.Source), f.Absolute)
+}
+
+// MoveOptions changes how hosts are written to the target file
+type MoveOptions struct {
+	// AbsolutePaths rewrites the relative key paths of the hosts to absolute ones,
+	// resolved from the directory of the file they come from
+	AbsolutePaths bool
+}
+
+// AnalyzeMove looks for what breaks when hosts of the default SSH config are moved
+// to targetConfigFile: key paths relative to a directory the target is not in,
+// and jump hosts defined in files ssh does not read along with the target
+func AnalyzeMove(hostNames []string, targetConfigFile string) ([]MoveFinding, error) {
+	target, err := filepath.Abs(targetConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := ParseSSHConfig()
+	if err != nil {
+		return nil, err
+	}
+	return analyzeMove(hosts, hostNames, target, moveScope(target)), nil
+}
+
+// moveScope returns the files ssh reads along with target: the whole default
+// config when target is part of it, else target and what it includes
+func moveScope(target string) []string {
+	if files, err := GetAllConfigFiles(); err == nil && slices.Contains(files, target) {
+		return files
+	}
+	files, _ := GetAllConfigFilesFromBase(target)
+	return files
+}
+
+// analyzeMove finds the hazards of moving hostNames to target, given the hosts of
+// the config and the files in target's scope
+func analyzeMove(hosts []SSHHost, hostNames []string, target string, scope []string) []MoveFinding {
+	byName := make(map[string]SSHHost, len(hosts))
+	visible := make(map[string]bool)
+	for _, host := range hosts {
+		if _, seen := byName[host.Name]; !seen {
+			byName[host.Name] = host
+		}
+		if slices.Contains(scope, host.SourceFile) {
+			visible[host.Name] = true
+		}
+	}
+	for _, name := range hostNames {
+		visible[name] = true // Moved along
+	}
+
+	var findings []MoveFinding
+	for _, name := range hostNames {
+		host, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if filepath.Dir(host.SourceFile) != filepath.Dir(target) {
+			for _, path := range hostKeyPaths(host) {
+				if value := strings.Trim(path[1], `"`); isRelativeKeyPath(value) {
+					findings = append(findings, MoveFinding{
+						Host:     name,
+						Kind:     MoveRelativePath,
+						Keyword:  path[0],
+						Value:    value,
+						Absolute: filepath.Join(filepath.Dir(host.SourceFile), value),
+						Source:   host.SourceFile,
+					})
+				}
+			}
+		}
+		if host.ProxyJump == "" || strings.EqualFold(host.ProxyJump, "none") {
+			continue
+		}
+		for _, hop := range strings.Split(host.ProxyJump, ",") {
+			hopName := jumpHopName(hop)
+			// A hop no Host block defines is an address, which resolves anywhere
+			if jump, defined := byName[hopName]; defined && !visible[hopName] {
+				findings = append(findings, MoveFinding{
+					Host:    name,
+					Kind:    MoveUnresolvedJump,
+					Keyword: "ProxyJump",
+					Value:   hopName,
+					Source:  jump.SourceFile,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// keyPathKeywords are the directives naming key files, which move with a host
+var keyPathKeywords = []string{"IdentityFile", "CertificateFile"}
+
+// hostKeyPaths returns the keyword and value of each key file a host names
+func hostKeyPaths(host SSHHost) [][2]string {
+	var paths [][2]string
+	if host.Identity != "" {
+		paths = append(paths, [2]string{"IdentityFile", host.Identity})
+	}
+	for _, line := range strings.Split(host.Options, "\n") {
+		keyword, value := splitOption(line)
+		for _, k := range keyPathKeywords {
+			if strings.EqualFold(keyword, k) && value != "" {
+				paths = append(paths, [2]string{k, value})
+			}
+		}
+	}
+	return paths
+}
+
+// isRelativeKeyPath reports whether a key path depends on the directory it is
+// read from. Paths from home, tokens and variables do not.
+func isRelativeKeyPath(value string) bool {
+	if value == "" || strings.EqualFold(value, "none") || filepath.IsAbs(value) {
+		return false
+	}
+	return !strings.HasPrefix(value, "~") && !strings.HasPrefix(value, "%") && !strings.HasPrefix(value, "$")
+}
+
+// withAbsoluteKeyPaths returns host with its relative key paths resolved from the
+// directory of its source file
+func withAbsoluteKeyPaths(host SSHHost) SSHHost {
+	dir := filepath.Dir(host.SourceFile)
+	absolute := func(value string) string {
+		if value = strings.Trim(value, `"`); isRelativeKeyPath(value) {
+			return filepath.Join(dir, value)
+		}
+		return value
+	}
+	if host.Identity != "" {
+		host.Identity = absolute(host.Identity)
+	}
+	lines := strings.Split(host.Options, "\n")
+	for i, line := range lines {
+		keyword, value := splitOption(line)
+		for _, k := range keyPathKeywords {
+			if strings.EqualFold(keyword, k) && isRelativeKeyPath(strings.Trim(value, `"`)) {
+				lines[i] = keyword + " " + configtext.QuoteValue(absolute(value))
+			}
+		}
+	}
+	host.Options = strings.Join(lines, "\n")
+	return host
+}
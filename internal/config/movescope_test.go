@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeMoveFlagsRelativeKeyPaths(t *testing.T) {
+	source := filepath.FromSlash("/home/me/.ssh/config")
+	hosts := []SSHHost{{
+		Name:       "web",
+		Identity:   "keys/id_web",
+		Options:    "CertificateFile \"certs/web cert.pub\"\nIdentitiesOnly yes",
+		SourceFile: source,
+	}, {
+		Name:       "api",
+		Identity:   "~/.ssh/id_api",
+		Options:    "CertificateFile %d/.ssh/api-cert.pub",
+		SourceFile: source,
+	}}
+	scope := []string{source}
+
+	findings := analyzeMove(hosts, []string{"web", "api"}, filepath.FromSlash("/home/me/.ssh/work/hosts.conf"), scope)
+	if len(findings) != 2 {
+		t.Fatalf("findings = %v, want the two relative paths of web", findings)
+	}
+	for i, want := range []struct{ keyword, absolute string }{
+		{"IdentityFile", "/home/me/.ssh/keys/id_web"},
+		{"CertificateFile", "/home/me/.ssh/certs/web cert.pub"},
+	} {
+		f := findings[i]
+		if f.Host != "web" || f.Kind != MoveRelativePath || f.Keyword != want.keyword || f.Absolute != filepath.FromSlash(want.absolute) || f.Blocking() {
+			t.Errorf("finding %d = %+v, want %s resolved to %s", i, f, want.keyword, want.absolute)
+		}
+	}
+
+	// Paths stay valid within the same directory
+	if findings := analyzeMove(hosts, []string{"web"}, filepath.FromSlash("/home/me/.ssh/other.conf"), scope); len(findings) != 0 {
+		t.Errorf("findings in the same directory = %v", findings)
+	}
+}
+
+func TestAnalyzeMoveFlagsUnresolvedJumps(t *testing.T) {
+	main, sibling, target := "/ssh/config", "/ssh/bastions.conf", "/ssh/standalone.conf"
+	hosts := []SSHHost{
+		{Name: "bastion", SourceFile: sibling},
+		{Name: "edge", SourceFile: main},
+		{Name: "inner", ProxyJump: "deploy@bastion:2222,edge", SourceFile: main},
+		{Name: "viaaddress", ProxyJump: "jump.example.com", SourceFile: main},
+		{Name: "chained", ProxyJump: "inner", SourceFile: main},
+	}
+
+	// Every file of the config sees every host
+	if findings := analyzeMove(hosts, []string{"inner"}, sibling, []string{main, sibling}); len(findings) != 0 {
+		t.Errorf("findings within the config = %v", findings)
+	}
+
+	// A file read on its own sees its hosts and the moved ones
+	findings := analyzeMove(hosts, []string{"inner", "viaaddress", "chained"}, target, []string{target})
+	if len(findings) != 2 {
+		t.Fatalf("findings = %v, want bastion and edge", findings)
+	}
+	for i, hop := range []string{"bastion", "edge"} {
+		if f := findings[i]; f.Host != "inner" || f.Value != hop || !f.Blocking() {
+			t.Errorf("finding %d = %+v, want inner jumping through %s", i, f, hop)
+		}
+	}
+	if !strings.Contains(findings[0].String(), "only defined in /ssh/bastions.conf") {
+		t.Errorf("String() = %q", findings[0])
+	}
+}
+
+func TestMoveHostsToFileWithOptions(t *testing.T) {
+	sshDir, _ := setupMoveTest(t)
+	writeFile(t, filepath.Join(sshDir, "a.conf"), "Host api\n    HostName api.example.com\n    IdentityFile keys/id_api\n    ProxyJump db\n\nHost cache\n    HostName cache.example.com\n    IdentityFile keys/id_cache\n")
+	work := filepath.Join(sshDir, "work")
+	if err := os.Mkdir(work, 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(work, "hosts.conf"), "")
+	writeFile(t, filepath.Join(sshDir, "config"), "Include a.conf\nInclude work/hosts.conf\n\nHost db\n    HostName db.example.com\n")
+
+	findings, err := AnalyzeMove([]string{"cache"}, filepath.Join(work, "hosts.conf"))
+	if err != nil || len(findings) != 1 || findings[0].Kind != MoveRelativePath {
+		t.Fatalf("AnalyzeMove() = %v, %v", findings, err)
+	}
+	if err := MoveHostsToFileWithOptions([]string{"cache"}, filepath.Join(work, "hosts.conf"), MoveOptions{AbsolutePaths: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "IdentityFile " + filepath.Join(sshDir, "keys", "id_cache")
+	if moved := readConfig(t, filepath.Join(work, "hosts.conf")); !strings.Contains(moved, want) {
+		t.Errorf("hosts.conf =\n%s\nwant %s", moved, want)
+	}
+
+	// moved.conf is not included, so db is not defined for it
+	err = MoveHostsToFileWithOptions([]string{"api"}, filepath.Join(sshDir, "moved.conf"), MoveOptions{})
+	if !errors.Is(err, ErrUnresolvedJump) {
+		t.Fatalf("MoveHostsToFileWithOptions() = %v, want ErrUnresolvedJump", err)
+	}
+	if !strings.Contains(readConfig(t, filepath.Join(sshDir, "a.conf")), "Host api") {
+		t.Error("the blocked move changed the source file")
+	}
+}
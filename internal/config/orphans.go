@@ -0,0 +1,62 @@
+package config
+
+import "sort"
+
+// KnownHostNames returns every host name the config knows of: the hosts of the
+// default SSH config and of configFile when it is set, with every alias of a
+// multi-host block, and the Kubernetes hosts. Records keyed by any other name
+// belong to a host that no longer exists.
+func KnownHostNames(configFile string) ([]string, error) {
+	hosts, err := ParseSSHConfig()
+	if err != nil {
+		return nil, err
+	}
+	if configFile != "" {
+		fileHosts, err := ParseSSHConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, fileHosts...)
+	}
+
+	known := make(map[string]bool)
+	for _, host := range hosts {
+		known[host.Name] = true
+		for _, name := range host.BlockNames {
+			known[name] = true
+		}
+	}
+	if K8sConfigExists() {
+		k8sHosts, err := ParseK8sConfig()
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range k8sHosts {
+			known[host.Name] = true
+		}
+	}
+
+	names := make([]string, 0, len(known))
+	for name := range known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// OrphanedHostColors returns the hosts with a color label that are not in
+// hostNames, without removing them
+func (c *AppConfig) OrphanedHostColors(hostNames []string) []string {
+	existing := make(map[string]bool, len(hostNames))
+	for _, name := range hostNames {
+		existing[name] = true
+	}
+	var orphaned []string
+	for name := range c.HostColors {
+		if !existing[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
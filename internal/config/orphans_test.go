@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestKnownHostNamesKeepsAliases(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("USERPROFILE", tempDir)
+	sshDir := filepath.Join(tempDir, ".ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(sshDir, "config")
+	writeFile(t, configFile, "# Tags: prod\nHost web web-alias\n    HostName 10.0.0.1\n\nHost db\n    HostName 10.0.0.2\n")
+	extraFile := filepath.Join(tempDir, "extra")
+	writeFile(t, extraFile, "Host lab\n    HostName 10.0.0.3\n")
+
+	if err := DeleteSSHHostFromFile("web", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+
+	known, err := KnownHostNames("")
+	if err != nil {
+		t.Fatalf("KnownHostNames() error = %v", err)
+	}
+	if want := []string{"db", "web-alias"}; !slices.Equal(known, want) {
+		t.Errorf("KnownHostNames() = %v, want %v", known, want)
+	}
+
+	known, _ = KnownHostNames(extraFile)
+	if !slices.Contains(known, "lab") {
+		t.Errorf("KnownHostNames(extra) = %v, want the hosts of the given file too", known)
+	}
+
+	appConfig := &AppConfig{HostColors: map[string]string{"web": "accent-1", "web-alias": "accent-2"}}
+	if got := appConfig.OrphanedHostColors(known); !slices.Equal(got, []string{"web"}) {
+		t.Errorf("OrphanedHostColors() = %v, want [web]", got)
+	}
+	if len(appConfig.HostColors) != 2 {
+		t.Error("Expected OrphanedHostColors to leave the labels alone")
+	}
+}
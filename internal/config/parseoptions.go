@@ -0,0 +1,37 @@
+package config
+
+import (
+	"github.com/xvertile/sshc/internal/metrics"
+	"github.com/xvertile/sshc/pkg/sshconfig"
+)
+
+// ParseOptions sets what ParseSSHConfigFileWithOptions does with the parts of a
+// config it cannot read
+type ParseOptions = sshconfig.ParseOptions
+
+// ParseProblem is a line or include the parser skipped
+type ParseProblem = sshconfig.ParseProblem
+
+// ParseErrors is the error of a strict parse, with a problem per line
+type ParseErrors = sshconfig.ParseErrors
+
+// ParseSSHConfigFileWithOptions parses a config like ParseSSHConfigFile and also
+// returns the lines and includes it skipped when opts.CollectWarnings is set. With
+// opts.Strict, finding any of them fails the parse with a ParseErrors.
+func ParseSSHConfigFileWithOptions(configPath string, opts ParseOptions) ([]SSHHost, []ParseProblem, error) {
+	if err := createMainConfig(configPath); err != nil {
+		return nil, nil, err
+	}
+
+	metrics.ConfigParse.Reset()
+	parsed, problems, err := sshconfig.ParseFileWithOptions(configPath, opts)
+	var hosts []SSHHost
+	if parsed != nil {
+		hosts = make([]SSHHost, 0, len(parsed))
+		for _, host := range parsed {
+			hosts = append(hosts, fromPublic(host))
+		}
+	}
+	logParse(configPath, hosts, err)
+	return hosts, problems, err
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOptionsReportsProblems(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config")
+	longPath := filepath.Join(tempDir, "long.conf")
+	writeFile(t, longPath, "Host long\n    LocalCommand "+strings.Repeat("x", 70*1024)+"\n")
+	writeFile(t, configPath, `ServerAliveInterval 30
+Include ~/missing/*.conf
+Include long.conf
+
+Host web
+    HostName web.example.com
+    ForwardAgent
+    ProxyCommand "nc %h %p
+`)
+	t.Setenv("HOME", "")
+
+	tests := []struct {
+		line    int
+		file    string
+		message string
+	}{
+		{1, configPath, "ServerAliveInterval before the first Host is not read"},
+		{2, configPath, "Include ~/missing/*.conf: failed to get home directory"},
+		{0, longPath, "cannot read included file: bufio.Scanner: token too long"},
+		{7, configPath, `cannot split "ForwardAgent" into a keyword and a value`},
+		{8, configPath, `unterminated quote in "ProxyCommand \"nc %h %p"`},
+	}
+	check := func(mode string, problems []ParseProblem) {
+		t.Helper()
+		if len(problems) != len(tests) {
+			t.Fatalf("%s: problems = %v, want %d", mode, problems, len(tests))
+		}
+		for i, tt := range tests {
+			p := problems[i]
+			if p.File != tt.file || p.Line != tt.line || !strings.HasPrefix(p.Message, tt.message) {
+				t.Errorf("%s: problem %d = %s, want %s:%d: %s", mode, i, p, tt.file, tt.line, tt.message)
+			}
+		}
+	}
+
+	// Lenient with warnings: the hosts are read and the problems returned
+	hosts, warnings, err := ParseSSHConfigFileWithOptions(configPath, ParseOptions{CollectWarnings: true})
+	if err != nil || len(hosts) != 1 || hosts[0].Name != "web" {
+		t.Fatalf("lenient parse = %v, %v", hosts, err)
+	}
+	check("warnings", warnings)
+
+	// Strict: the parse fails listing each problem
+	hosts, warnings, err = ParseSSHConfigFileWithOptions(configPath, ParseOptions{Strict: true})
+	var parseErrors ParseErrors
+	if !errors.As(err, &parseErrors) || hosts != nil || warnings != nil {
+		t.Fatalf("strict parse = %v, %v, %v, want ParseErrors", hosts, warnings, err)
+	}
+	check("strict", parseErrors)
+	if !strings.Contains(err.Error(), configPath+":7: cannot split") {
+		t.Errorf("error does not give file:line:\n%v", err)
+	}
+}
+
+func TestParseOptionsDefaultStaysLenient(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	writeFile(t, configPath, "User root\nHost web\n    HostName web.example.com\n    ForwardAgent\n")
+
+	for _, opts := range []ParseOptions{{}, {CollectWarnings: true}} {
+		hosts, warnings, err := ParseSSHConfigFileWithOptions(configPath, opts)
+		if err != nil || len(hosts) != 1 || hosts[0].Hostname != "web.example.com" {
+			t.Errorf("%+v: parse = %v, %v", opts, hosts, err)
+		}
+		if want := map[bool]int{false: 0, true: 2}[opts.CollectWarnings]; len(warnings) != want {
+			t.Errorf("%+v: warnings = %v, want %d", opts, warnings, want)
+		}
+	}
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil || len(hosts) != 1 {
+		t.Errorf("ParseSSHConfigFile() = %v, %v", hosts, err)
+	}
+
+	// A clean config passes strict parsing
+	writeFile(t, configPath, "Include none/*.conf\nHost web\n    HostName web.example.com\n")
+	if hosts, _, err := ParseSSHConfigFileWithOptions(configPath, ParseOptions{Strict: true}); err != nil || len(hosts) != 1 {
+		t.Errorf("strict parse of a clean config = %v, %v", hosts, err)
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// dataKind is a kind of data sshc stores, each kind in its own base directory
+type dataKind int
+
+const (
+	configData dataKind = iota // Settings users may keep in a dotfile repo
+	stateData                  // History and logs that should persist but not be shared
+	cacheData                  // Data that can be rebuilt at any time
+)
+
+// dataDir resolves the sshc directory for a kind of data on goos. Windows uses
+// %APPDATA% for config and %LOCALAPPDATA% for the rest; elsewhere, and when those
+// are unset, the XDG base directories apply. Relative XDG values are ignored, as
+// the specification asks.
+func dataDir(kind dataKind, goos, homeDir string, getenv func(string) string) string {
+	if goos == "windows" {
+		switch kind {
+		case configData:
+			if appData := getenv("APPDATA"); appData != "" {
+				return filepath.Join(appData, "sshc")
+			}
+		case stateData:
+			if localAppData := getenv("LOCALAPPDATA"); localAppData != "" {
+				return filepath.Join(localAppData, "sshc")
+			}
+		case cacheData:
+			if localAppData := getenv("LOCALAPPDATA"); localAppData != "" {
+				return filepath.Join(localAppData, "sshc", "cache")
+			}
+		}
+	}
+
+	variable, fallback := "XDG_CONFIG_HOME", filepath.Join(homeDir, ".config")
+	switch kind {
+	case stateData:
+		variable, fallback = "XDG_STATE_HOME", filepath.Join(homeDir, ".local", "state")
+	case cacheData:
+		variable, fallback = "XDG_CACHE_HOME", filepath.Join(homeDir, ".cache")
+	}
+	dir := getenv(variable)
+	if filepath.IsAbs(dir) {
+		return filepath.Join(dir, "sshc")
+	}
+	if dir != "" {
+		warnRelativeXDG(variable, dir)
+	}
+	return filepath.Join(fallback, "sshc")
+}
+
+// warnedXDG holds the variables already warned about, so each is reported once
+var warnedXDG sync.Map
+
+// warnRelativeXDG tells the user a relative XDG variable is ignored, replaced in tests
+var warnRelativeXDG = func(variable, value string) {
+	if _, warned := warnedXDG.LoadOrStore(variable, true); warned {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: ignoring %s=%q, it must be an absolute path\n", variable, value)
+}
+
+// resolveDataDir resolves the directory for kind on the running system
+func resolveDataDir(kind dataKind) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return dataDir(kind, runtime.GOOS, homeDir, os.Getenv), nil
+}
+
+// GetSSHMConfigDir returns the SSHM config directory
+func GetSSHMConfigDir() (string, error) {
+	return resolveDataDir(configData)
+}
+
+// GetSSHMStateDir returns the directory for history and logs
+func GetSSHMStateDir() (string, error) {
+	return resolveDataDir(stateData)
+}
+
+// GetSSHMCacheDir returns the directory for data sshc can rebuild, such as caches
+func GetSSHMCacheDir() (string, error) {
+	return resolveDataDir(cacheData)
+}
+
+// StateFilePath returns the path of a file in the state directory, creating the
+// directory. A file of that name left in the config directory by an older version
+// is moved there first.
+func StateFilePath(name string) (string, error) {
+	return dataFilePath(stateData, name)
+}
+
+// CacheFilePath returns the path of a file in the cache directory, creating the
+// directory. A file of that name left in the config directory is moved there first.
+func CacheFilePath(name string) (string, error) {
+	return dataFilePath(cacheData, name)
+}
+
+func dataFilePath(kind dataKind, name string) (string, error) {
+	dir, err := resolveDataDir(kind)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+
+	if configDir, err := GetSSHMConfigDir(); err == nil {
+		if err := migrateFile(filepath.Join(configDir, name), path); err != nil {
+			return "", fmt.Errorf("failed to move %s to %s: %w", name, dir, err)
+		}
+	}
+	return path, nil
+}
+
+// migrateFile moves oldPath to newPath unless something is already at newPath, so
+// the move happens once and never overwrites newer data
+func migrateFile(oldPath, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	if _, err := os.Lstat(newPath); err == nil {
+		return nil
+	}
+	info, err := os.Lstat(oldPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.Rename(oldPath, newPath); err == nil {
+		return nil
+	}
+
+	// Rename fails across filesystems, so copy and remove the original instead
+	src, err := os.Open(oldPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(newPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(newPath)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	return os.Remove(oldPath)
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDataDir(t *testing.T) {
+	home := filepath.FromSlash("/home/me")
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+	xdg := env(map[string]string{
+		"XDG_CONFIG_HOME": filepath.FromSlash("/xdg/config"),
+		"XDG_STATE_HOME":  filepath.FromSlash("/xdg/state"),
+		"XDG_CACHE_HOME":  filepath.FromSlash("/xdg/cache"),
+	})
+	windows := env(map[string]string{
+		"APPDATA":      filepath.FromSlash("/Users/me/AppData/Roaming"),
+		"LOCALAPPDATA": filepath.FromSlash("/Users/me/AppData/Local"),
+	})
+
+	tests := []struct {
+		name   string
+		kind   dataKind
+		goos   string
+		getenv func(string) string
+		want   string
+	}{
+		{"linux config default", configData, "linux", env(nil), "/home/me/.config/sshc"},
+		{"linux state default", stateData, "linux", env(nil), "/home/me/.local/state/sshc"},
+		{"linux cache default", cacheData, "linux", env(nil), "/home/me/.cache/sshc"},
+		{"linux config xdg", configData, "linux", xdg, "/xdg/config/sshc"},
+		{"linux state xdg", stateData, "linux", xdg, "/xdg/state/sshc"},
+		{"linux cache xdg", cacheData, "linux", xdg, "/xdg/cache/sshc"},
+		{"relative xdg ignored", stateData, "linux", env(map[string]string{"XDG_STATE_HOME": "state"}), "/home/me/.local/state/sshc"},
+		{"darwin state xdg", stateData, "darwin", xdg, "/xdg/state/sshc"},
+		{"darwin cache default", cacheData, "darwin", env(nil), "/home/me/.cache/sshc"},
+		{"windows config", configData, "windows", windows, "/Users/me/AppData/Roaming/sshc"},
+		{"windows state", stateData, "windows", windows, "/Users/me/AppData/Local/sshc"},
+		{"windows cache", cacheData, "windows", windows, "/Users/me/AppData/Local/sshc/cache"},
+		{"windows without env", stateData, "windows", env(nil), "/home/me/.local/state/sshc"},
+	}
+
+	original := warnRelativeXDG
+	warnRelativeXDG = func(string, string) {}
+	t.Cleanup(func() { warnRelativeXDG = original })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dataDir(tt.kind, tt.goos, home, tt.getenv); got != filepath.FromSlash(tt.want) {
+				t.Errorf("dataDir() = %s, want %s", got, filepath.FromSlash(tt.want))
+			}
+		})
+	}
+}
+
+func TestDataDirWarnsAboutRelativeXDG(t *testing.T) {
+	var warned []string
+	original := warnRelativeXDG
+	warnRelativeXDG = func(variable, value string) { warned = append(warned, variable+"="+value) }
+	t.Cleanup(func() { warnRelativeXDG = original })
+
+	getenv := func(name string) string {
+		return map[string]string{"XDG_CONFIG_HOME": "dotfiles/config", "XDG_CACHE_HOME": "/xdg/cache"}[name]
+	}
+	dataDir(configData, "linux", "/home/me", getenv)
+	dataDir(cacheData, "linux", "/home/me", getenv)
+	dataDir(stateData, "linux", "/home/me", getenv)
+
+	if len(warned) != 1 || warned[0] != "XDG_CONFIG_HOME=dotfiles/config" {
+		t.Errorf("Expected a warning for the relative XDG_CONFIG_HOME only, got %v", warned)
+	}
+}
+
+func TestStateFilePathMovesOldFileOnce(t *testing.T) {
+	configHome, stateHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("APPDATA", configHome)
+	t.Setenv("XDG_STATE_HOME", stateHome)
+	t.Setenv("LOCALAPPDATA", stateHome)
+
+	oldPath := filepath.Join(configHome, "sshc", "audit.log")
+	if err := os.MkdirAll(filepath.Dir(oldPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, oldPath, "old entries\n")
+
+	path, err := StateFilePath("audit.log")
+	if err != nil {
+		t.Fatalf("StateFilePath() error = %v", err)
+	}
+	if want := filepath.Join(stateHome, "sshc", "audit.log"); path != want {
+		t.Fatalf("StateFilePath() = %s, want %s", path, want)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "old entries\n" {
+		t.Errorf("Expected the old file in the state directory, got %q (err %v)", data, err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the old file to be moved rather than copied, stat error = %v", err)
+	}
+
+	// A file that reappears in the config directory does not replace newer state
+	writeFile(t, path, "new entries\n")
+	writeFile(t, oldPath, "stale entries\n")
+	if _, err := StateFilePath("audit.log"); err != nil {
+		t.Fatalf("StateFilePath() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new entries\n" {
+		t.Errorf("Expected the state file to be kept, got %q", data)
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Errorf("Expected the config directory file to be left alone, stat error = %v", err)
+	}
+}
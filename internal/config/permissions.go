@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/shellquote"
+)
+
+// Kinds of paths CheckPermissions looks at
+const (
+	PermissionSSHDir   = "SSH directory"
+	PermissionConfig   = "config file"
+	PermissionIdentity = "identity file"
+)
+
+// PermissionProblem is a path whose permissions make ssh refuse it or complain
+type PermissionProblem struct {
+	Path     string
+	Kind     string      // One of the Permission kinds
+	Mode     os.FileMode // Permission bits the path has
+	Want     os.FileMode // Permission bits FixPermissions gives it
+	InSSHDir bool        // The path, after symlinks, lies within the .ssh directory
+}
+
+// String describes the problem and the command fixing it
+func (p PermissionProblem) String() string {
+	access := "writable"
+	if p.Kind == PermissionIdentity && p.Mode&0022 == 0 {
+		access = "readable"
+	}
+	return fmt.Sprintf("%s %s is %s by group or others (%03o); run: %s", p.Kind, p.Path, access, p.Mode, p.Command())
+}
+
+// Command returns the chmod command fixing the problem
+func (p PermissionProblem) Command() string {
+	return fmt.Sprintf("chmod %o %s", p.Want, shellquote.Word(p.Path))
+}
+
+// CheckPermissions returns the paths ssh would refuse because of their permissions:
+// the .ssh directory and config files writable by group or others, and identity
+// files of hosts accessible to them. Missing paths are skipped, and so is
+// everything on platforms where permission bits mean nothing to ssh.
+func CheckPermissions(sshDir string, configFiles []string, hosts []SSHHost) []PermissionProblem {
+	if !PermissionChecksSupported {
+		return nil
+	}
+
+	var problems []PermissionProblem
+	seen := make(map[string]bool)
+	check := func(path, kind string, bad, want os.FileMode) {
+		if path == "" || seen[path] {
+			return
+		}
+		seen[path] = true
+		info, err := os.Stat(path)
+		if err != nil || info.Mode().Perm()&bad == 0 {
+			return
+		}
+		problems = append(problems, PermissionProblem{
+			Path:     path,
+			Kind:     kind,
+			Mode:     info.Mode().Perm(),
+			Want:     want,
+			InSSHDir: withinDir(path, sshDir),
+		})
+	}
+
+	check(sshDir, PermissionSSHDir, 0022, 0700)
+	for _, file := range configFiles {
+		check(file, PermissionConfig, 0022, 0600)
+	}
+	for _, host := range hosts {
+		for _, keyPath := range hostKeyPaths(host) {
+			if keyPath[0] == "IdentityFile" {
+				check(identityFilePath(keyPath[1], host.SourceFile), PermissionIdentity, 0077, 0600)
+			}
+		}
+	}
+	return problems
+}
+
+// identityFilePath returns the file an IdentityFile value names, or "" when it
+// depends on tokens or variables only ssh expands
+func identityFilePath(value, sourceFile string) string {
+	value = strings.Trim(value, `"`)
+	if value == "" || strings.EqualFold(value, "none") || strings.ContainsAny(value, "%$") {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(value, "~/"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(homeDir, rest)
+	}
+	if isRelativeKeyPath(value) {
+		return filepath.Join(filepath.Dir(sourceFile), value)
+	}
+	return value
+}
+
+// withinDir reports whether path, with symlinks followed, is dir or lies below it
+func withinDir(path, dir string) bool {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// FixPermissions gives each problem's path the permissions it wants. Paths
+// outside the .ssh directory are only changed when outside is set. It returns
+// the problems left unfixed, stopping at the first chmod that fails.
+func FixPermissions(problems []PermissionProblem, outside bool) ([]PermissionProblem, error) {
+	var left []PermissionProblem
+	for i, problem := range problems {
+		if !problem.InSSHDir && !outside {
+			left = append(left, problem)
+			continue
+		}
+		if err := os.Chmod(problem.Path, problem.Want); err != nil {
+			return append(left, problems[i:]...), fmt.Errorf("failed to change permissions of %s: %w", problem.Path, err)
+		}
+	}
+	return left, nil
+}
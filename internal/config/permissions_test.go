@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeWithMode creates a file with exactly the given permissions, whatever the umask
+func writeWithMode(t *testing.T, path string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("Host x\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	if !PermissionChecksSupported {
+		t.Skip("permission bits are not checked on this platform")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.Mkdir(sshDir, 0775); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(sshDir, 0775)
+
+	mainConfig := filepath.Join(sshDir, "config")
+	included := filepath.Join(sshDir, "conf.d", "work.conf")
+	outside := filepath.Join(home, "dotfiles", "hosts.conf")
+	writeWithMode(t, mainConfig, 0664)
+	writeWithMode(t, included, 0644)
+	writeWithMode(t, outside, 0666)
+	writeWithMode(t, filepath.Join(sshDir, "id_loose"), 0640)
+	writeWithMode(t, filepath.Join(sshDir, "keys", "id_relative"), 0604)
+	writeWithMode(t, filepath.Join(sshDir, "id_tight"), 0600)
+
+	hosts := []SSHHost{
+		{Name: "loose", Identity: "~/.ssh/id_loose", SourceFile: mainConfig},
+		{Name: "shared", Identity: "~/.ssh/id_loose", SourceFile: mainConfig},
+		{Name: "relative", Options: "IdentityFile keys/id_relative", SourceFile: mainConfig},
+		{Name: "tight", Identity: filepath.Join(sshDir, "id_tight"), SourceFile: mainConfig},
+		{Name: "missing", Identity: "~/.ssh/id_missing", SourceFile: mainConfig},
+		{Name: "token", Identity: "~/.ssh/id_%h", SourceFile: mainConfig},
+	}
+
+	problems := CheckPermissions(sshDir, []string{mainConfig, included, outside}, hosts)
+	want := []struct {
+		path     string
+		kind     string
+		want     os.FileMode
+		inSSHDir bool
+	}{
+		{sshDir, PermissionSSHDir, 0700, true},
+		{mainConfig, PermissionConfig, 0600, true},
+		{outside, PermissionConfig, 0600, false},
+		{filepath.Join(sshDir, "id_loose"), PermissionIdentity, 0600, true},
+		{filepath.Join(sshDir, "keys", "id_relative"), PermissionIdentity, 0600, true},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("CheckPermissions() = %v, want %d problems", problems, len(want))
+	}
+	for i, w := range want {
+		p := problems[i]
+		if p.Path != w.path || p.Kind != w.kind || p.Want != w.want || p.InSSHDir != w.inSSHDir {
+			t.Errorf("problem %d = %+v, want %s %s to become %o", i, p, w.kind, w.path, w.want)
+		}
+	}
+	if got, want := problems[0].Command(), "chmod 700 "+sshDir; got != want {
+		t.Errorf("Command() = %q, want %q", got, want)
+	}
+
+	// Only the .ssh directory is fixed without asking
+	left, err := FixPermissions(problems, false)
+	if err != nil {
+		t.Fatalf("FixPermissions() error = %v", err)
+	}
+	if len(left) != 1 || left[0].Path != outside {
+		t.Errorf("FixPermissions() left %v, want only the file outside .ssh", left)
+	}
+	if info, _ := os.Stat(outside); info.Mode().Perm() != 0666 {
+		t.Errorf("file outside .ssh has mode %o, want it untouched", info.Mode().Perm())
+	}
+	if remaining := CheckPermissions(sshDir, []string{mainConfig, included, outside}, hosts); len(remaining) != 1 {
+		t.Errorf("CheckPermissions() after fixing = %v, want only the file outside .ssh", remaining)
+	}
+
+	if left, err := FixPermissions(left, true); err != nil || len(left) != 0 {
+		t.Errorf("FixPermissions(outside) = %v, %v, want everything fixed", left, err)
+	}
+	if info, _ := os.Stat(outside); info.Mode().Perm() != 0600 {
+		t.Errorf("file outside .ssh has mode %o after confirming, want 600", info.Mode().Perm())
+	}
+}
+
+func TestCheckPermissionsFollowsSymlinksOutOfSSHDir(t *testing.T) {
+	if !PermissionChecksSupported {
+		t.Skip("permission bits are not checked on this platform")
+	}
+	home := t.TempDir()
+	sshDir := filepath.Join(home, ".ssh")
+	target := filepath.Join(home, "dotfiles", "config")
+	writeWithMode(t, target, 0664)
+	link := filepath.Join(sshDir, "config")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	problems := CheckPermissions(sshDir, []string{link}, nil)
+	if len(problems) != 1 || problems[0].InSSHDir {
+		t.Fatalf("CheckPermissions() = %+v, want the linked config outside .ssh", problems)
+	}
+	if left, _ := FixPermissions(problems, false); len(left) != 1 {
+		t.Errorf("FixPermissions() fixed a file outside .ssh through its link")
+	}
+}
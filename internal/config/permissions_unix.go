@@ -4,6 +4,10 @@ package config
 
 import "os"
 
+// PermissionChecksSupported reports whether ssh enforces the permission bits
+// CheckPermissions looks at
+const PermissionChecksSupported = true
+
 // SetSecureFilePermissions configures secure permissions on Unix systems
 func SetSecureFilePermissions(filepath string) error {
 	// Set file permissions to 0600 (owner read/write only)
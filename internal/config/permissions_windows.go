@@ -6,6 +6,11 @@ import (
 	"os"
 )
 
+// PermissionChecksSupported reports whether ssh enforces the permission bits
+// CheckPermissions looks at. Windows guards keys with ACLs instead, which are
+// not checked.
+const PermissionChecksSupported = false
+
 // SetSecureFilePermissions configures secure permissions on Windows
 func SetSecureFilePermissions(filepath string) error {
 	// On Windows, file permissions work differently
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"path"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MaxPinnedPaths is how many remote paths can be pinned per host. Pinning one
+// more drops the pin used longest ago.
+const MaxPinnedPaths = 10
+
+// PinnedPath is a remote path pinned for a host, offered at the top of the
+// remote browser
+type PinnedPath struct {
+	Path     string    `json:"path"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// PinnedPathsFor returns the paths pinned for a host, in the order they were pinned
+func (c *AppConfig) PinnedPathsFor(hostName string) []PinnedPath {
+	if c == nil {
+		return nil
+	}
+	return c.PinnedPaths[hostName]
+}
+
+// cleanRemotePath normalizes a remote path so it is pinned once however it is written
+func cleanRemotePath(remotePath string) string {
+	if remotePath == "" {
+		return ""
+	}
+	return path.Clean(remotePath)
+}
+
+// PinPath pins a remote path for a host, or marks it used if it is pinned already.
+// It returns the path evicted to stay within MaxPinnedPaths, if any.
+func (c *AppConfig) PinPath(hostName, remotePath string, now time.Time) string {
+	remotePath = cleanRemotePath(remotePath)
+	if remotePath == "" || c.TouchPinnedPath(hostName, remotePath, now) {
+		return ""
+	}
+	if c.PinnedPaths == nil {
+		c.PinnedPaths = make(map[string][]PinnedPath)
+	}
+	pins := c.PinnedPaths[hostName]
+	evicted := ""
+	if len(pins) >= MaxPinnedPaths {
+		oldest := 0
+		for i, pin := range pins {
+			if pin.LastUsed.Before(pins[oldest].LastUsed) {
+				oldest = i
+			}
+		}
+		evicted = pins[oldest].Path
+		pins = slices.Delete(pins, oldest, oldest+1)
+	}
+	c.PinnedPaths[hostName] = append(pins, PinnedPath{Path: remotePath, LastUsed: now})
+	return evicted
+}
+
+// TouchPinnedPath marks a pinned path as used at now, reporting whether it is pinned
+func (c *AppConfig) TouchPinnedPath(hostName, remotePath string, now time.Time) bool {
+	pins := c.PinnedPathsFor(hostName)
+	i := slices.IndexFunc(pins, func(pin PinnedPath) bool { return pin.Path == cleanRemotePath(remotePath) })
+	if i < 0 {
+		return false
+	}
+	pins[i].LastUsed = now
+	return true
+}
+
+// UnpinPath removes a pinned path of a host, reporting whether it was pinned
+func (c *AppConfig) UnpinPath(hostName, remotePath string) bool {
+	pins := c.PinnedPathsFor(hostName)
+	i := slices.IndexFunc(pins, func(pin PinnedPath) bool { return pin.Path == cleanRemotePath(remotePath) })
+	if i < 0 {
+		return false
+	}
+	if pins = slices.Delete(pins, i, i+1); len(pins) == 0 {
+		delete(c.PinnedPaths, hostName)
+	} else {
+		c.PinnedPaths[hostName] = pins
+	}
+	return true
+}
+
+// ReplacePinnedPath changes a pinned path in place. It reports false when the old
+// path is not pinned or the new one already is.
+func (c *AppConfig) ReplacePinnedPath(hostName, oldPath, newPath string) bool {
+	pins := c.PinnedPathsFor(hostName)
+	oldPath, newPath = cleanRemotePath(oldPath), cleanRemotePath(newPath)
+	i := slices.IndexFunc(pins, func(pin PinnedPath) bool { return pin.Path == oldPath })
+	if i < 0 || newPath == "" || slices.ContainsFunc(pins, func(pin PinnedPath) bool { return pin.Path == newPath }) {
+		return false
+	}
+	pins[i].Path = newPath
+	return true
+}
+
+// RenamePinnedPaths moves the pinned paths of a renamed host to its new name
+func (c *AppConfig) RenamePinnedPaths(oldName, newName string) bool {
+	pins, ok := c.PinnedPaths[oldName]
+	if !ok || oldName == newName {
+		return false
+	}
+	delete(c.PinnedPaths, oldName)
+	c.PinnedPaths[newName] = pins
+	return true
+}
+
+// OrphanedPinnedPaths returns the hosts with pinned paths that are not in
+// hostNames, without removing them
+func (c *AppConfig) OrphanedPinnedPaths(hostNames []string) []string {
+	var orphaned []string
+	for name := range c.PinnedPaths {
+		if !slices.Contains(hostNames, name) {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+// PrunePinnedPaths removes the pinned paths of hosts that are not in hostNames
+// and returns their names
+func (c *AppConfig) PrunePinnedPaths(hostNames []string) []string {
+	orphaned := c.OrphanedPinnedPaths(hostNames)
+	for _, name := range orphaned {
+		delete(c.PinnedPaths, name)
+	}
+	return orphaned
+}
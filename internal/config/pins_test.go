@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func pinnedPaths(pins []PinnedPath) []string {
+	paths := make([]string, len(pins))
+	for i, pin := range pins {
+		paths[i] = pin.Path
+	}
+	return paths
+}
+
+func TestPinPathEvictsLeastRecentlyUsed(t *testing.T) {
+	start := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	var c AppConfig
+	for i := range MaxPinnedPaths {
+		if evicted := c.PinPath("web", fmt.Sprintf("/srv/%d", i), start.Add(time.Duration(i)*time.Minute)); evicted != "" {
+			t.Fatalf("PinPath() evicted %q below the limit", evicted)
+		}
+	}
+
+	// Using the oldest pins spares them, so /srv/2 is used longest ago
+	c.TouchPinnedPath("web", "/srv/0", start.Add(time.Hour))
+	c.TouchPinnedPath("web", "/srv/1/", start.Add(time.Hour))
+	if evicted := c.PinPath("web", "/var/www/releases/current", start.Add(2*time.Hour)); evicted != "/srv/2" {
+		t.Errorf("PinPath() evicted %q, want /srv/2", evicted)
+	}
+	pins := pinnedPaths(c.PinnedPathsFor("web"))
+	if len(pins) != MaxPinnedPaths || pins[2] != "/srv/3" || pins[MaxPinnedPaths-1] != "/var/www/releases/current" {
+		t.Errorf("pins = %v", pins)
+	}
+
+	// Pinning a path again only marks it used
+	if evicted := c.PinPath("web", "/srv/3/", start.Add(3*time.Hour)); evicted != "" || len(c.PinnedPathsFor("web")) != MaxPinnedPaths {
+		t.Errorf("PinPath() of a pinned path evicted %q, pins = %v", evicted, pinnedPaths(c.PinnedPathsFor("web")))
+	}
+	if evicted := c.PinPath("web", "/etc/nginx", start.Add(4*time.Hour)); evicted != "/srv/4" {
+		t.Errorf("PinPath() evicted %q, want /srv/4", evicted)
+	}
+	if len(c.PinnedPathsFor("db")) != 0 {
+		t.Error("pins of one host leaked to another")
+	}
+}
+
+func TestEditPinnedPaths(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	var c AppConfig
+	c.PinPath("web", "/etc/nginx/sites-enabled", now)
+	c.PinPath("web", "/var/log", now)
+
+	if !c.ReplacePinnedPath("web", "/var/log", "/var/log/nginx") || c.ReplacePinnedPath("web", "/var/log/nginx", "/etc/nginx/sites-enabled") {
+		t.Error("ReplacePinnedPath() should change a pin but not onto another pin")
+	}
+	if !c.UnpinPath("web", "/etc/nginx/sites-enabled") || c.UnpinPath("web", "/etc/nginx/sites-enabled") {
+		t.Error("UnpinPath() should remove a pin once")
+	}
+	if pins := pinnedPaths(c.PinnedPathsFor("web")); len(pins) != 1 || pins[0] != "/var/log/nginx" {
+		t.Errorf("pins = %v", pins)
+	}
+
+	if !c.RenamePinnedPaths("web", "web-1") || len(c.PinnedPathsFor("web-1")) != 1 {
+		t.Errorf("pins after the rename = %v", c.PinnedPaths)
+	}
+	c.PinPath("gone", "/tmp", now)
+	if pruned := c.PrunePinnedPaths([]string{"web-1"}); len(pruned) != 1 || pruned[0] != "gone" {
+		t.Errorf("PrunePinnedPaths() = %v", pruned)
+	}
+	c.UnpinPath("web-1", "/var/log/nginx")
+	if _, ok := c.PinnedPaths["web-1"]; ok {
+		t.Error("a host without pins kept its entry")
+	}
+}
+
+func TestPinnedPathsRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	c := GetDefaultAppConfig()
+	c.PinPath("web", "/var/www/releases/current", now)
+	c.PinPath("web", "/etc/nginx/sites-enabled", now.Add(time.Minute))
+	if err := SaveAppConfig(&c); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadAppConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pins := loaded.PinnedPathsFor("web")
+	if len(pins) != 2 || pins[0].Path != "/var/www/releases/current" || !pins[1].LastUsed.Equal(now.Add(time.Minute)) {
+		t.Errorf("loaded pins = %+v", pins)
+	}
+}
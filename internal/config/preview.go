@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ConfigChange is a modification of a config file that has been computed but not written
+type ConfigChange struct {
+	Path   string
+	Host   string // Host name recorded in the audit log
+	Before string
+	After  string
+}
+
+// Changed reports whether applying the change would modify the file
+func (c *ConfigChange) Changed() bool {
+	return c.Before != c.After
+}
+
+// Diff returns the change as a unified diff, or an empty string when nothing changes
+func (c *ConfigChange) Diff() string {
+	return unifiedDiff(c.Path, c.Before, c.After)
+}
+
+// PreviewSSHHostUpdate returns the change UpdateSSHHostInFile would make, without writing it
+func PreviewSSHHostUpdate(oldName string, newHost SSHHost, configPath string) (*ConfigChange, error) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := updatedHostContent(string(content), oldName, newHost)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigChange{Path: configPath, Host: oldName, Before: string(content), After: after}, nil
+}
+
+// PreviewMultiHostBlockUpdate returns the change UpdateMultiHostBlock would make, without writing it
+func PreviewMultiHostBlockUpdate(originalHosts, newHosts []string, commonProperties SSHHost, configPath string) (*ConfigChange, error) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := updatedMultiHostContent(string(content), originalHosts, newHosts, commonProperties)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigChange{Path: configPath, Host: strings.Join(originalHosts, " "), Before: string(content), After: after}, nil
+}
+
+// ApplyConfigChange writes a previewed change. Nothing is written if the file was
+// modified after the preview, since the change would no longer be what was shown.
+func ApplyConfigChange(change *ConfigChange) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	content, err := os.ReadFile(change.Path)
+	if err != nil {
+		return err
+	}
+	if string(content) != change.Before {
+		return fmt.Errorf("%s was modified after the preview, review the edit again", change.Path)
+	}
+
+	// Create backup before modification
+	if err := backupConfig(change.Path); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	return writeConfigFile(change.Path, AuditUpdate, change.Host, content, change.After)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const previewTestConfig = `# Team hosts
+# Tags: web
+Host web1 web2
+    HostName web.example.com
+    User deploy
+
+Host db
+    HostName db.example.com
+`
+
+func TestPreviewMatchesWrittenContent(t *testing.T) {
+	tempDir := setupAuditTest(t)
+
+	tests := []struct {
+		name    string
+		preview func(path string) (*ConfigChange, error)
+		write   func(path string) error
+	}{
+		{
+			name: "single host",
+			preview: func(path string) (*ConfigChange, error) {
+				return PreviewSSHHostUpdate("db", SSHHost{Name: "db", Hostname: "db2.example.com", Tags: []string{"data"}}, path)
+			},
+			write: func(path string) error {
+				return UpdateSSHHostInFile("db", SSHHost{Name: "db", Hostname: "db2.example.com", Tags: []string{"data"}}, path)
+			},
+		},
+		{
+			name: "split from multi-host block",
+			preview: func(path string) (*ConfigChange, error) {
+				return PreviewSSHHostUpdate("web2", SSHHost{Name: "web2", Hostname: "web2.example.com", User: "deploy"}, path)
+			},
+			write: func(path string) error {
+				return UpdateSSHHostInFile("web2", SSHHost{Name: "web2", Hostname: "web2.example.com", User: "deploy"}, path)
+			},
+		},
+		{
+			name: "multi-host block",
+			preview: func(path string) (*ConfigChange, error) {
+				return PreviewMultiHostBlockUpdate([]string{"web1", "web2"}, []string{"web1", "web3"}, SSHHost{Hostname: "web.example.com", Port: "2222"}, path)
+			},
+			write: func(path string) error {
+				return UpdateMultiHostBlock([]string{"web1", "web2"}, []string{"web1", "web3"}, SSHHost{Hostname: "web.example.com", Port: "2222"}, path)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previewPath := filepath.Join(tempDir, "preview", tt.name)
+			writePath := filepath.Join(tempDir, "write", tt.name)
+			for _, path := range []string{previewPath, writePath} {
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, path, previewTestConfig)
+			}
+
+			change, err := tt.preview(previewPath)
+			if err != nil {
+				t.Fatalf("preview error = %v", err)
+			}
+			if !change.Changed() || change.Diff() == "" {
+				t.Fatal("Expected a change, got none")
+			}
+
+			// Previewing must not touch the file
+			content, _ := os.ReadFile(previewPath)
+			if string(content) != previewTestConfig {
+				t.Error("Preview modified the config file")
+			}
+
+			if err := ApplyConfigChange(change); err != nil {
+				t.Fatalf("ApplyConfigChange() error = %v", err)
+			}
+			if err := tt.write(writePath); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			applied, _ := os.ReadFile(previewPath)
+			written, _ := os.ReadFile(writePath)
+			if string(applied) != change.After {
+				t.Errorf("Applied content differs from the preview:\n%s\nwant:\n%s", applied, change.After)
+			}
+			if string(written) != change.After {
+				t.Errorf("Direct write differs from the preview:\n%s\nwant:\n%s", written, change.After)
+			}
+		})
+	}
+}
+
+func TestPreviewDiffShowsTagsComment(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, previewTestConfig)
+
+	change, err := PreviewSSHHostUpdate("db", SSHHost{Name: "db", Hostname: "db.example.com", Tags: []string{"data"}}, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(change.Diff(), "\n+# Tags: data\n") {
+		t.Errorf("Expected the tags comment as an added line, got:\n%s", change.Diff())
+	}
+}
+
+func TestApplyConfigChangeRejectsStalePreview(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, previewTestConfig)
+
+	change, err := PreviewSSHHostUpdate("db", SSHHost{Name: "db", Hostname: "db2.example.com"}, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Someone else edits the file before the preview is confirmed
+	edited := previewTestConfig + "\nHost cache\n    HostName cache.example.com\n"
+	writeFile(t, configFile, edited)
+
+	if err := ApplyConfigChange(change); err == nil {
+		t.Error("Expected an error applying a stale preview")
+	}
+	content, _ := os.ReadFile(configFile)
+	if string(content) != edited {
+		t.Error("A stale preview overwrote the file")
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+	"github.com/xvertile/sshc/pkg/sshconfig"
+)
+
+// ErrReadOnlyFile is returned for writes to a config file marked read-only
+var ErrReadOnlyFile = sshconfig.ErrReadOnlyFile
+
+// IsReadOnlyFile reports whether a config file is marked read-only. Only the comment
+// header at the top of the file is read.
+func IsReadOnlyFile(configPath string) bool {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := configtext.TrimLine(scanner.Text())
+		if configtext.IsReadOnlyMarker(line) {
+			return true
+		}
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return false
+}
+
+// WritableConfigFiles returns the files that are not marked read-only
+func WritableConfigFiles(files []string) []string {
+	var writable []string
+	for _, file := range files {
+		if !IsReadOnlyFile(file) {
+			writable = append(writable, file)
+		}
+	}
+	return writable
+}
+
+// checkWritable returns an error wrapping ErrReadOnlyFile when content of configPath
+// is marked read-only
+func checkWritable(configPath string, content []byte) error {
+	if configtext.HasReadOnlyMarker(string(content)) {
+		return fmt.Errorf("%w: %s", ErrReadOnlyFile, configPath)
+	}
+	return nil
+}
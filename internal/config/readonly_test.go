@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const readOnlyConfig = `# Generated by Ansible, do not edit
+# sshm: readonly
+
+Host generated
+    HostName generated.example.com
+`
+
+func TestReadOnlyMarker(t *testing.T) {
+	tests := []struct {
+		content  string
+		readOnly bool
+	}{
+		{readOnlyConfig, true},
+		{"\ufeff#   SSHM:  ReadOnly\r\nHost a\r\n", true},
+		{"# sshc: readonly\n", true},
+		// Only the comment header at the top counts
+		{"Host a\n    HostName a\n# sshm: readonly\n", false},
+		{"# sshm: readonly please\nHost a\n", false},
+		{"", false},
+	}
+	tempDir := t.TempDir()
+	for i, tt := range tests {
+		path := filepath.Join(tempDir, "config"+string(rune('a'+i)))
+		writeFile(t, path, tt.content)
+		if got := IsReadOnlyFile(path); got != tt.readOnly {
+			t.Errorf("IsReadOnlyFile(%q) = %v, want %v", tt.content, got, tt.readOnly)
+		}
+	}
+}
+
+func TestParseMarksReadOnlyHosts(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, "Include generated.conf\n\nHost manual\n    HostName manual.example.com\n")
+	writeFile(t, filepath.Join(tempDir, "generated.conf"), readOnlyConfig)
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readOnly := make(map[string]bool)
+	for _, host := range hosts {
+		readOnly[host.Name] = host.ReadOnly
+	}
+	if !readOnly["generated"] || readOnly["manual"] {
+		t.Errorf("Expected only the generated host to be read-only, got %v", readOnly)
+	}
+
+	files, err := GetAllConfigFilesFromBase(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writable := WritableConfigFiles(files)
+	if len(writable) != 1 || filepath.Base(writable[0]) != "config" {
+		t.Errorf("WritableConfigFiles() = %v", writable)
+	}
+}
+
+// assertReadOnlyRejected fails the test unless err is a read-only error and path
+// still holds readOnlyConfig
+func assertReadOnlyRejected(t *testing.T, operation string, err error, path string) {
+	t.Helper()
+	if !errors.Is(err, ErrReadOnlyFile) {
+		t.Errorf("%s: expected ErrReadOnlyFile, got %v", operation, err)
+	}
+	content, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if string(content) != readOnlyConfig {
+		t.Errorf("%s: read-only file was modified:\n%s", operation, content)
+	}
+}
+
+func TestWritesToReadOnlyFileAreRejected(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("USERPROFILE", tempDir)
+
+	sshDir := filepath.Join(tempDir, ".ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	mainConfig := filepath.Join(sshDir, "config")
+	generated := filepath.Join(sshDir, "generated.conf")
+	mainContent := "Include generated.conf\n\nHost manual\n    HostName manual.example.com\n"
+	writeFile(t, mainConfig, mainContent)
+	writeFile(t, generated, readOnlyConfig)
+
+	err := AddSSHHostToFile(SSHHost{Name: "new", Hostname: "new.example.com"}, generated)
+	assertReadOnlyRejected(t, "add", err, generated)
+
+	err = UpdateSSHHostInFile("generated", SSHHost{Name: "generated", Hostname: "other.example.com"}, generated)
+	assertReadOnlyRejected(t, "update", err, generated)
+
+	err = DeleteSSHHostFromFile("generated", generated)
+	assertReadOnlyRejected(t, "delete", err, generated)
+
+	// Moving out of the file must not leave a copy behind in the target
+	err = MoveHostToFile("generated", mainConfig)
+	assertReadOnlyRejected(t, "move out", err, generated)
+	if content, _ := os.ReadFile(mainConfig); string(content) != mainContent {
+		t.Errorf("move out: target was modified:\n%s", content)
+	}
+
+	// Moving into the file is rejected and keeps the host where it was
+	err = MoveHostToFile("manual", generated)
+	assertReadOnlyRejected(t, "move in", err, generated)
+	if content, _ := os.ReadFile(mainConfig); !strings.Contains(string(content), "Host manual") {
+		t.Errorf("move in: host was removed from its source:\n%s", content)
+	}
+}
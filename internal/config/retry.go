@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+// writeAttempts is how many times a write failing with a transient error is tried
+const writeAttempts = 3
+
+// writeRetryDelay is the wait before the first retry; it doubles before each next one
+var writeRetryDelay = 100 * time.Millisecond
+
+// retrySleep waits between attempts; tests replace it
+var retrySleep = time.Sleep
+
+// writeFileOnce makes a single attempt at writing a file; tests replace it to
+// make writes fail
+var writeFileOnce = os.WriteFile
+
+// IsTransientWriteError reports whether err is an error network filesystems
+// return for a moment and that is worth retrying, such as ESTALE or EIO on NFS
+func IsTransientWriteError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	for _, transient := range transientErrnos {
+		if errno == transient {
+			return true
+		}
+	}
+	return false
+}
+
+// retryTransient runs write until it succeeds, fails with an error that is not
+// transient, or has failed writeAttempts times, waiting longer before each retry.
+// Each retry is logged when logging is enabled.
+func retryTransient(path string, write func() error) error {
+	delay := writeRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err == nil || !IsTransientWriteError(err) {
+			return err
+		}
+		if attempt == writeAttempts {
+			return fmt.Errorf("%w (failed %d times)", err, writeAttempts)
+		}
+		sshclog.Warn("write failed, retrying", "file", path, "attempt", attempt, "delay", delay, "err", err)
+		retrySleep(delay)
+		delay *= 2
+	}
+}
+
+// writeFileRetrying writes a file like os.WriteFile, retrying transient failures
+func writeFileRetrying(path string, data []byte, perm os.FileMode) error {
+	return retryTransient(path, func() error {
+		return writeFileOnce(path, data, perm)
+	})
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// failWrites makes the next writes fail with errs in turn, then write normally,
+// and records the waits between attempts
+func failWrites(t *testing.T, errs ...error) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	attempts := 0
+	writeFileOnce = func(name string, data []byte, perm os.FileMode) error {
+		attempts++
+		if attempts <= len(errs) {
+			return &os.PathError{Op: "open", Path: name, Err: errs[attempts-1]}
+		}
+		return os.WriteFile(name, data, perm)
+	}
+	retrySleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() {
+		writeFileOnce = os.WriteFile
+		retrySleep = time.Sleep
+	})
+	return &delays
+}
+
+func TestIsTransientWriteError(t *testing.T) {
+	transient := transientErrnos[0]
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{transient, true},
+		{&os.PathError{Op: "write", Path: "/nfs/home/.ssh/config", Err: transient}, true},
+		{fmt.Errorf("failed to save: %w", &os.LinkError{Op: "rename", Old: "a", New: "b", Err: transient}), true},
+		{&os.PathError{Op: "open", Path: "/home/.ssh/config", Err: syscall.ENOENT}, false},
+		{&os.PathError{Op: "open", Path: "/home/.ssh/config", Err: syscall.EACCES}, false},
+		{errors.New("disk on fire"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransientWriteError(tt.err); got != tt.want {
+			t.Errorf("IsTransientWriteError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWriteRetriesTransientFailures(t *testing.T) {
+	transient := transientErrnos[0]
+	delays := failWrites(t, transient, transient)
+	configPath := filepath.Join(t.TempDir(), "config")
+
+	if err := AddSSHHostToFile(SSHHost{Name: "web", Hostname: "10.0.0.1"}, configPath); err != nil {
+		t.Fatalf("AddSSHHostToFile() = %v, want the third attempt to succeed", err)
+	}
+	if data, _ := os.ReadFile(configPath); !strings.Contains(string(data), "Host web") {
+		t.Errorf("config = %q, want the host written", data)
+	}
+	if want := []time.Duration{writeRetryDelay, 2 * writeRetryDelay}; !slices.Equal(*delays, want) {
+		t.Errorf("waited %v between attempts, want %v", *delays, want)
+	}
+}
+
+func TestWriteGivesUpAfterThreeAttempts(t *testing.T) {
+	transient := transientErrnos[0]
+	delays := failWrites(t, transient, transient, transient, transient)
+	path := filepath.Join(t.TempDir(), "snippets.json")
+
+	err := writeFileRetrying(path, []byte("{}"), 0600)
+	if !errors.Is(err, transient) || !IsTransientWriteError(err) {
+		t.Fatalf("writeFileRetrying() = %v, want the transient error", err)
+	}
+	if !strings.Contains(err.Error(), "failed 3 times") {
+		t.Errorf("error = %q, want the number of attempts", err)
+	}
+	if len(*delays) != writeAttempts-1 {
+		t.Errorf("waited %d times, want %d", len(*delays), writeAttempts-1)
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Error("file was written after giving up")
+	}
+}
+
+func TestWriteDoesNotRetryOtherFailures(t *testing.T) {
+	delays := failWrites(t, syscall.EACCES)
+	err := writeFileRetrying(filepath.Join(t.TempDir(), "config.json"), []byte("{}"), 0600)
+	if !errors.Is(err, syscall.EACCES) || len(*delays) != 0 {
+		t.Errorf("writeFileRetrying() = %v after %d retries, want EACCES at once", err, len(*delays))
+	}
+}
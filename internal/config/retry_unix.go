@@ -0,0 +1,10 @@
+//go:build !windows
+
+package config
+
+import "syscall"
+
+// transientErrnos are the errors of a write that may succeed when tried again:
+// a stale NFS file handle, an I/O error of the network filesystem, and
+// interrupted or temporarily unavailable calls
+var transientErrnos = []syscall.Errno{syscall.ESTALE, syscall.EIO, syscall.EINTR, syscall.EAGAIN}
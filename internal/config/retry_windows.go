@@ -0,0 +1,9 @@
+//go:build windows
+
+package config
+
+import "syscall"
+
+// transientErrnos are the errors of a write to a network share that may succeed
+// when tried again: ERROR_UNEXP_NET_ERR, ERROR_NETNAME_DELETED and ERROR_SEM_TIMEOUT
+var transientErrnos = []syscall.Errno{59, 64, 121}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Connection robustness presets, which set how quickly ssh gives up on an
+// unreachable host and on a connection that stopped answering
+const (
+	RobustnessOff        = "Off"
+	RobustnessNormal     = "Normal"
+	RobustnessAggressive = "Aggressive"
+	RobustnessCustom     = "Custom" // The directives are set, but to no preset's values
+)
+
+// RobustnessPresets lists the presets in the order a selector cycles through them
+var RobustnessPresets = []string{RobustnessOff, RobustnessNormal, RobustnessAggressive}
+
+// RobustnessKeywords are the directives the presets set, in the order they are written
+var RobustnessKeywords = []string{"ServerAliveInterval", "ServerAliveCountMax", "ConnectTimeout"}
+
+// robustnessValues maps each preset to its values of RobustnessKeywords
+var robustnessValues = map[string][]string{
+	RobustnessNormal:     {"30", "3", "10"},
+	RobustnessAggressive: {"10", "2", "5"},
+}
+
+// IsRobustnessKeyword reports whether the presets set keyword, in any case
+func IsRobustnessKeyword(keyword string) bool {
+	for _, k := range RobustnessKeywords {
+		if strings.EqualFold(k, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOption splits an option line such as "ServerAliveInterval 30" or
+// "ServerAliveInterval=30" into its keyword and value
+func splitOption(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	keyword, value, _ := strings.Cut(line, " ")
+	if k, v, ok := strings.Cut(line, "="); ok && !strings.Contains(k, " ") {
+		keyword, value = k, v
+	}
+	return keyword, strings.TrimSpace(value)
+}
+
+// RobustnessSettings returns the values of the robustness directives in options,
+// one line per option as stored in SSHHost.Options, keyed by canonical keyword
+func RobustnessSettings(options string) map[string]string {
+	settings := make(map[string]string)
+	for _, line := range strings.Split(options, "\n") {
+		keyword, value := splitOption(line)
+		for _, k := range RobustnessKeywords {
+			if strings.EqualFold(k, keyword) {
+				if _, seen := settings[k]; !seen {
+					settings[k] = value
+				}
+			}
+		}
+	}
+	return settings
+}
+
+// DetectRobustnessPreset returns the preset options match: Off when none of the
+// robustness directives are set, the preset whose values they all have, and
+// Custom otherwise
+func DetectRobustnessPreset(options string) string {
+	settings := RobustnessSettings(options)
+	if len(settings) == 0 {
+		return RobustnessOff
+	}
+	for _, preset := range RobustnessPresets {
+		values, ok := robustnessValues[preset]
+		if !ok || len(settings) != len(values) {
+			continue
+		}
+		matches := true
+		for i, keyword := range RobustnessKeywords {
+			if settings[keyword] != values[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return preset
+		}
+	}
+	return RobustnessCustom
+}
+
+// ApplyRobustnessPreset replaces the robustness directives in options with those
+// of preset, keeping every other option. Off removes them, Custom leaves options as is.
+func ApplyRobustnessPreset(options, preset string) string {
+	if preset == RobustnessCustom {
+		return options
+	}
+
+	var lines []string
+	for _, line := range strings.Split(options, "\n") {
+		keyword, _ := splitOption(line)
+		if strings.TrimSpace(line) == "" || IsRobustnessKeyword(keyword) {
+			continue
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	for i, value := range robustnessValues[preset] {
+		lines = append(lines, RobustnessKeywords[i]+" "+value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DescribeRobustnessPreset lists the directives a preset sets, e.g.
+// "ServerAliveInterval 30, ServerAliveCountMax 3, ConnectTimeout 10"
+func DescribeRobustnessPreset(preset string) string {
+	var parts []string
+	for i, value := range robustnessValues[preset] {
+		parts = append(parts, RobustnessKeywords[i]+" "+value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LintConnectFailures suggests a robustness preset for hosts that have failed to
+// connect, according to failures (failed attempts keyed by host name), and set
+// none of the robustness directives themselves
+func LintConnectFailures(hosts []SSHHost, failures map[string]int) []LintWarning {
+	var warnings []LintWarning
+	for _, host := range hosts {
+		count := failures[host.Name]
+		if count == 0 || DetectRobustnessPreset(host.Options) != RobustnessOff {
+			continue
+		}
+		attempts := "attempt"
+		if count > 1 {
+			attempts = "attempts"
+		}
+		warnings = append(warnings, LintWarning{
+			Host: host.Name,
+			File: host.SourceFile,
+			Line: host.Line,
+			Message: fmt.Sprintf("%d failed connection %s recorded; the %s connection preset (%s) makes ssh give up on a dead link sooner",
+				count, attempts, RobustnessNormal, DescribeRobustnessPreset(RobustnessNormal)),
+		})
+	}
+	return warnings
+}
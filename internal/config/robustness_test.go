@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectRobustnessPreset(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		want    string
+	}{
+		{"no options", "", RobustnessOff},
+		{"unrelated options", "Compression yes\nForwardAgent no", RobustnessOff},
+		{"normal", "ServerAliveInterval 30\nServerAliveCountMax 3\nConnectTimeout 10", RobustnessNormal},
+		{"normal, other case and order", "connecttimeout 10\nCompression yes\nserveralivecountmax 3\nServerAliveInterval=30", RobustnessNormal},
+		{"aggressive", "ServerAliveInterval 10\nServerAliveCountMax 2\nConnectTimeout 5", RobustnessAggressive},
+		{"partial preset", "ServerAliveInterval 30\nServerAliveCountMax 3", RobustnessCustom},
+		{"other values", "ServerAliveInterval 60", RobustnessCustom},
+		{"first value wins like ssh", "ServerAliveInterval 10\nServerAliveCountMax 2\nConnectTimeout 5\nConnectTimeout 99", RobustnessAggressive},
+	}
+	for _, tt := range tests {
+		if got := DetectRobustnessPreset(tt.options); got != tt.want {
+			t.Errorf("%s: DetectRobustnessPreset() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyRobustnessPreset(t *testing.T) {
+	options := "Compression yes\nServerAliveInterval 60\nForwardAgent no"
+
+	normal := ApplyRobustnessPreset(options, RobustnessNormal)
+	if want := "Compression yes\nForwardAgent no\nServerAliveInterval 30\nServerAliveCountMax 3\nConnectTimeout 10"; normal != want {
+		t.Errorf("ApplyRobustnessPreset(Normal) = %q, want %q", normal, want)
+	}
+
+	// Every preset reads back as itself, keeping the other options
+	for _, preset := range RobustnessPresets {
+		applied := ApplyRobustnessPreset(normal, preset)
+		if got := DetectRobustnessPreset(applied); got != preset {
+			t.Errorf("DetectRobustnessPreset(ApplyRobustnessPreset(%s)) = %s", preset, got)
+		}
+		if !strings.Contains(applied, "Compression yes") || !strings.Contains(applied, "ForwardAgent no") {
+			t.Errorf("ApplyRobustnessPreset(%s) dropped other options: %q", preset, applied)
+		}
+	}
+
+	if got := ApplyRobustnessPreset(options, RobustnessOff); got != "Compression yes\nForwardAgent no" {
+		t.Errorf("ApplyRobustnessPreset(Off) = %q", got)
+	}
+	if got := ApplyRobustnessPreset(options, RobustnessCustom); got != options {
+		t.Errorf("ApplyRobustnessPreset(Custom) = %q, want options unchanged", got)
+	}
+}
+
+func TestLintConnectFailures(t *testing.T) {
+	hosts := []SSHHost{
+		{Name: "flaky", SourceFile: "/etc/ssh/config", Line: 3},
+		{Name: "tuned", Options: "ServerAliveInterval 15"},
+		{Name: "stable"},
+	}
+	failures := map[string]int{"flaky": 3, "tuned": 5}
+
+	warnings := LintConnectFailures(hosts, failures)
+	if len(warnings) != 1 || warnings[0].Host != "flaky" || warnings[0].Line != 3 {
+		t.Fatalf("Expected a warning for flaky only, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0].Message, "3 failed connection attempts") || !strings.Contains(warnings[0].Message, "Normal") {
+		t.Errorf("Unexpected message: %s", warnings[0].Message)
+	}
+}
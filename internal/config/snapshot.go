@@ -0,0 +1,394 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+// snapshotManifestName is the first entry of every snapshot archive
+const snapshotManifestName = "manifest.json"
+
+// snapshotIDFormat names snapshots after the time they were taken
+const snapshotIDFormat = "20060102-150405"
+
+// Archive names of snapshot files start with one of these: files in the home
+// directory are kept relative to it, anything else by its absolute path
+const (
+	snapshotHomePrefix = "home/"
+	snapshotRootPrefix = "root/"
+)
+
+// Snapshot is an archive of the whole SSH setup: the SSH config with every file
+// it includes and the files in the sshc config directory. It never contains
+// private keys.
+type Snapshot struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+	Note    string    `json:"note,omitempty"`
+	Files   []string  `json:"files"` // Archive names, such as "home/.ssh/config"
+	Path    string    `json:"-"`     // Where the archive is
+}
+
+// GetSnapshotDir returns the directory that holds the snapshot archives
+func GetSnapshotDir() (string, error) {
+	backupDir, err := GetSSHMBackupDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(backupDir, "snapshots"), nil
+}
+
+// SnapshotFiles returns the files a snapshot holds: configFile (or the default SSH
+// config when empty) with the files it includes, and the files in the sshc config
+// directory. Files holding a private key are left out.
+func SnapshotFiles(configFile string) ([]string, error) {
+	if configFile == "" {
+		var err error
+		if configFile, err = GetDefaultSSHConfigPath(); err != nil {
+			return nil, err
+		}
+	}
+	configFiles, err := GetAllConfigFilesFromBase(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := configFiles
+	if configDir, err := GetSSHMConfigDir(); err == nil {
+		entries, err := os.ReadDir(configDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			candidates = append(candidates, filepath.Join(configDir, entry.Name()))
+		}
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, file := range candidates {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		info, err := os.Stat(file)
+		if err != nil || !info.Mode().IsRegular() || holdsPrivateKey(file) {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// holdsPrivateKey reports whether the file at path contains a private key
+func holdsPrivateKey(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && bytes.Contains(data, []byte("PRIVATE KEY-----"))
+}
+
+// CreateSnapshot archives the files listed by SnapshotFiles in a new snapshot.
+// In dry-run mode nothing is written and the snapshot returned has no Path.
+func CreateSnapshot(configFile, note string) (*Snapshot, error) {
+	files, err := SnapshotFiles(configFile)
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	snapshot := &Snapshot{ID: now.Format(snapshotIDFormat), Created: now, Note: note}
+	for _, file := range files {
+		snapshot.Files = append(snapshot.Files, snapshotEntryName(file, homeDir))
+	}
+	if IsDryRun() {
+		return snapshot, nil
+	}
+
+	dir, err := GetSnapshotDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	// Two snapshots in the same second get a suffix
+	baseID := snapshot.ID
+	var archive *os.File
+	for n := 2; ; n++ {
+		snapshot.Path = filepath.Join(dir, snapshot.ID+".tar.gz")
+		archive, err = os.OpenFile(snapshot.Path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if !errors.Is(err, os.ErrExist) {
+			break
+		}
+		snapshot.ID = fmt.Sprintf("%s-%d", baseID, n)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	sshclog.Write(snapshot.Path, "snapshot")
+	err = writeSnapshot(archive, snapshot, files)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(snapshot.Path)
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// writeSnapshot writes the manifest of snapshot followed by files as a tar.gz
+func writeSnapshot(w io.Writer, snapshot *Snapshot, files []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeSnapshotEntry(tw, snapshotManifestName, manifest, 0600, snapshot.Created); err != nil {
+		return err
+	}
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := writeSnapshotEntry(tw, snapshot.Files[i], data, info.Mode().Perm(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeSnapshotEntry(tw *tar.Writer, name string, data []byte, perm os.FileMode, modTime time.Time) error {
+	header := &tar.Header{
+		Name:     name,
+		Mode:     int64(perm),
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// snapshotEntryName returns the archive name of file
+func snapshotEntryName(file, homeDir string) string {
+	if rel, err := filepath.Rel(homeDir, file); err == nil && filepath.IsLocal(rel) {
+		return snapshotHomePrefix + filepath.ToSlash(rel)
+	}
+	return snapshotRootPrefix + strings.TrimPrefix(filepath.ToSlash(file), "/")
+}
+
+// snapshotEntryPath returns where the file archived as name is restored to. Names
+// that could point outside their base directory are refused.
+func snapshotEntryPath(name, homeDir string) (string, error) {
+	if path.Clean(name) != name {
+		return "", fmt.Errorf("invalid file name %q in snapshot", name)
+	}
+	switch {
+	case strings.HasPrefix(name, snapshotHomePrefix):
+		rel := filepath.FromSlash(strings.TrimPrefix(name, snapshotHomePrefix))
+		if !filepath.IsLocal(rel) {
+			return "", fmt.Errorf("invalid file name %q in snapshot", name)
+		}
+		return filepath.Join(homeDir, rel), nil
+	case strings.HasPrefix(name, snapshotRootPrefix):
+		rest := filepath.FromSlash(strings.TrimPrefix(name, snapshotRootPrefix))
+		if filepath.IsAbs(rest) {
+			return rest, nil // A path with a volume name, such as C:\
+		}
+		if !filepath.IsLocal(rest) {
+			return "", fmt.Errorf("invalid file name %q in snapshot", name)
+		}
+		return filepath.Join(string(filepath.Separator), rest), nil
+	}
+	return "", fmt.Errorf("invalid file name %q in snapshot", name)
+}
+
+// ListSnapshots returns the snapshots in the snapshot directory, newest first.
+// Archives that cannot be read are skipped.
+func ListSnapshots() ([]Snapshot, error) {
+	dir, err := GetSnapshotDir()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tar.gz"))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, match := range matches {
+		snapshot, err := readSnapshotManifest(match)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+	return snapshots, nil
+}
+
+// readSnapshotManifest reads the manifest at the start of a snapshot archive
+func readSnapshotManifest(archivePath string) (*Snapshot, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != snapshotManifestName {
+		return nil, errors.New("not an sshc snapshot")
+	}
+	var snapshot Snapshot
+	if err := json.NewDecoder(tr).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	snapshot.Path = archivePath
+	return &snapshot, nil
+}
+
+// FindSnapshot returns the snapshot with the given ID
+func FindSnapshot(id string) (*Snapshot, error) {
+	dir, err := GetSnapshotDir()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" || filepath.Base(id) != id {
+		return nil, fmt.Errorf("invalid snapshot ID %q", id)
+	}
+	snapshot, err := readSnapshotManifest(filepath.Join(dir, id+".tar.gz"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshot writes every file of a snapshot back to where it was, creating
+// missing directories with mode 0700. A snapshot of the current state, using
+// configFile like CreateSnapshot, is taken first and returned. Files that are
+// not in the snapshot are left alone.
+func RestoreSnapshot(snapshot *Snapshot, configFile string) (restored []string, safety *Snapshot, err error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Everything is read and checked before anything is written
+	type snapshotFile struct {
+		path string
+		data []byte
+		perm os.FileMode
+	}
+	var files []snapshotFile
+	err = readSnapshotFiles(snapshot.Path, func(header *tar.Header, data []byte) error {
+		dest, err := snapshotEntryPath(header.Name, homeDir)
+		if err != nil {
+			return err
+		}
+		files = append(files, snapshotFile{path: dest, data: data, perm: os.FileMode(header.Mode).Perm()})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read snapshot %s: %w", snapshot.ID, err)
+	}
+
+	safety, err = CreateSnapshot(configFile, "before restoring "+snapshot.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to snapshot the current state: %w", err)
+	}
+
+	for _, file := range files {
+		if !IsDryRun() {
+			if err := os.MkdirAll(filepath.Dir(file.path), 0700); err != nil {
+				return restored, safety, fmt.Errorf("failed to create directory: %w", err)
+			}
+		}
+		if err := writeDataFile(file.path, file.data, file.perm); err != nil {
+			return restored, safety, fmt.Errorf("failed to restore %s: %w", file.path, err)
+		}
+		restored = append(restored, file.path)
+	}
+	return restored, safety, nil
+}
+
+// readSnapshotFiles calls fn with every file of a snapshot archive after its manifest
+func readSnapshotFiles(archivePath string, fn func(header *tar.Header, data []byte) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Name == snapshotManifestName {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unexpected entry %q in snapshot", header.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := fn(header, data); err != nil {
+			return err
+		}
+	}
+}
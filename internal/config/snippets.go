@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Snippet is a remote command that can be run on any host
+type Snippet struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+	TTY     bool   `yaml:"tty,omitempty"`   // Run with ssh -t, for interactive or full-screen commands
+	Pause   bool   `yaml:"pause,omitempty"` // Wait for Enter before returning to sshc
+}
+
+// snippetsFile is the structure of snippets.yaml
+type snippetsFile struct {
+	Snippets []Snippet `yaml:"snippets"`
+}
+
+// snippetsMutex serializes writes of the snippet library
+var snippetsMutex sync.Mutex
+
+// GetSnippetsPath returns the path to the snippet library
+func GetSnippetsPath() (string, error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "snippets.yaml"), nil
+}
+
+// LoadSnippets returns the snippet library, which is empty until a snippet is saved
+func LoadSnippets() ([]Snippet, error) {
+	path, err := GetSnippetsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []Snippet{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets: %w", err)
+	}
+
+	var file snippetsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse snippets: %w", err)
+	}
+	return file.Snippets, nil
+}
+
+// SaveSnippets replaces the snippet library
+func SaveSnippets(snippets []Snippet) error {
+	seen := make(map[string]bool)
+	for _, snippet := range snippets {
+		if err := snippet.Validate(); err != nil {
+			return err
+		}
+		if seen[snippet.Name] {
+			return fmt.Errorf("duplicate snippet name %q", snippet.Name)
+		}
+		seen[snippet.Name] = true
+	}
+
+	snippetsMutex.Lock()
+	defer snippetsMutex.Unlock()
+
+	path, err := GetSnippetsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(&snippetsFile{Snippets: snippets})
+	if err != nil {
+		return fmt.Errorf("failed to marshal snippets: %w", err)
+	}
+	return writeDataFile(path, data, 0600)
+}
+
+// Validate checks that a snippet has a name and a command
+func (s Snippet) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("snippet name is required")
+	}
+	if strings.TrimSpace(s.Command) == "" {
+		return fmt.Errorf("snippet %q has no command", s.Name)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestSnippetsRoundTrip(t *testing.T) {
+	setupAuditTest(t)
+
+	snippets, err := LoadSnippets()
+	if err != nil {
+		t.Fatalf("LoadSnippets() error = %v", err)
+	}
+	if len(snippets) != 0 {
+		t.Fatalf("Expected no snippets before the first save, got %v", snippets)
+	}
+
+	want := []Snippet{
+		{Name: "check disk", Command: "df -h"},
+		{Name: "tail nginx", Command: "sudo tail -f /var/log/nginx/error.log", TTY: true},
+		{Name: "restart app", Command: "sudo systemctl restart app && systemctl status app", Pause: true},
+	}
+	if err := SaveSnippets(want); err != nil {
+		t.Fatalf("SaveSnippets() error = %v", err)
+	}
+	got, err := LoadSnippets()
+	if err != nil {
+		t.Fatalf("LoadSnippets() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("LoadSnippets() = %+v, want %+v", got, want)
+	}
+
+	path, _ := GetSnippetsPath()
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Expected snippets.yaml to be private, got mode %v", info.Mode().Perm())
+	}
+}
+
+func TestSaveSnippetsValidates(t *testing.T) {
+	setupAuditTest(t)
+
+	invalid := [][]Snippet{
+		{{Name: "", Command: "uptime"}},
+		{{Name: "uptime", Command: "  "}},
+		{{Name: "uptime", Command: "uptime"}, {Name: "uptime", Command: "uptime -p"}},
+	}
+	for _, snippets := range invalid {
+		if err := SaveSnippets(snippets); err == nil {
+			t.Errorf("SaveSnippets(%+v): expected an error", snippets)
+		}
+	}
+}
@@ -4,75 +4,117 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/xvertile/sshc/internal/configtext"
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/metrics"
+	"github.com/xvertile/sshc/pkg/sshconfig"
 )
 
 // SSHHost represents an SSH host configuration
 type SSHHost struct {
-	Name          string
-	Hostname      string
-	User          string
-	Port          string
-	Identity      string
-	ProxyJump     string
-	Options       string
-	RemoteCommand string // Command to execute after SSH connection
-	RequestTTY    string // Request TTY (yes, no, force, auto)
-	Tags          []string
-	SourceFile    string // Path to the config file where this host is defined
-
-	// Temporary field to handle multiple aliases during parsing
-	aliasNames []string `json:"-"` // Do not serialize this field
+	Name          string   `json:"name"`
+	Hostname      string   `json:"hostname"`
+	User          string   `json:"user,omitempty"`
+	Port          string   `json:"port,omitempty"`
+	Identity      string   `json:"identity,omitempty"`
+	ProxyJump     string   `json:"proxy_jump,omitempty"`
+	ProxyCommand  string   `json:"proxy_command,omitempty"` // Written unquoted, it contains spaces by design
+	Options       string   `json:"options,omitempty"`
+	RemoteCommand string   `json:"remote_command,omitempty"` // Command to execute after SSH connection
+	RequestTTY    string   `json:"request_tty,omitempty"`    // Request TTY (yes, no, force, auto)
+	Description   string   `json:"description,omitempty"`    // One-line note from a "# Description:" comment
+	Tags          []string `json:"tags,omitempty"`
+	Expires       string   `json:"expires,omitempty"` // Expiry date (YYYY-MM-DD) from a "# Expires:" comment
+	SourceFile    string   `json:"-"`                 // Path to the config file where this host is defined
+	ReadOnly      bool     `json:"-"`                 // SourceFile is marked read-only
+
+	// Host key and canonicalization settings. They are read from the config for
+	// resolving targets; the directives stay in Options and are written from there.
+	HostKeyAlias         string   `json:"-"`
+	CanonicalizeHostname string   `json:"-"` // no (default), yes or always
+	CanonicalDomains     []string `json:"-"`
+	CanonicalizeMaxDots  string   `json:"-"`
+
+	// BlockNames lists every name of the Host line this host was declared on,
+	// when there is more than one
+	BlockNames []string `json:"-"`
+
+	// Line is the line of SourceFile the Host declaration is on, and DirectiveLines
+	// the line of each directive in the block, by lowercase keyword. A directive
+	// given twice keeps its first line, the one ssh uses.
+	Line           int            `json:"-"`
+	DirectiveLines map[string]int `json:"-"`
+
+	// Order numbers the Host blocks in the order ssh reads them, Include files
+	// counted where the Include is, so for a name declared twice the lower wins
+	Order int `json:"-"`
 }
 
-// GetDefaultSSHConfigPath returns the default SSH config path for the current platform
-func GetDefaultSSHConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
+// nativeTagsCheck reports whether tags may be written as native Tag directives
+var nativeTagsCheck func() bool
 
-	switch runtime.GOOS {
-	case "windows":
-		return filepath.Join(homeDir, ".ssh", "config"), nil
-	default:
-		// Linux, macOS, etc.
-		return filepath.Join(homeDir, ".ssh", "config"), nil
-	}
+// SetNativeTagsCheck sets how to tell whether the ssh client understands Tag directives.
+// Without one, or when it returns false, tags are written as a "# Tags:" comment.
+// It must be called before any host is written.
+func SetNativeTagsCheck(check func() bool) {
+	nativeTagsCheck = check
 }
 
-// GetSSHMConfigDir returns the SSHM config directory
-func GetSSHMConfigDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
+// useNativeTags reports whether tags may be written as Tag directives. Tags that
+// ssh would read as more than one word keep the comment form either way.
+func useNativeTags(tags []string) bool {
+	return len(tags) > 0 && nativeTagsCheck != nil && nativeTagsCheck()
+}
+
+// ExpiryDateLayout is the only accepted format for host expiry dates
+const ExpiryDateLayout = "2006-01-02"
+
+// ExpiryDate returns the parsed expiry date of the host, if one is set and valid
+func (h SSHHost) ExpiryDate() (time.Time, bool) {
+	if h.Expires == "" {
+		return time.Time{}, false
+	}
+	date, err := time.ParseInLocation(ExpiryDateLayout, h.Expires, time.Local)
 	if err != nil {
-		return "", err
+		return time.Time{}, false
 	}
+	return date, true
+}
 
-	var configDir string
-	switch runtime.GOOS {
-	case "windows":
-		// Use %APPDATA%/sshc on Windows
-		appData := os.Getenv("APPDATA")
-		if appData != "" {
-			configDir = filepath.Join(appData, "sshc")
-		} else {
-			configDir = filepath.Join(homeDir, ".config", "sshc")
-		}
-	default:
-		// Use XDG Base Directory specification
-		xdgConfigDir := os.Getenv("XDG_CONFIG_HOME")
-		if xdgConfigDir != "" {
-			configDir = filepath.Join(xdgConfigDir, "sshc")
-		} else {
-			configDir = filepath.Join(homeDir, ".config", "sshc")
-		}
-	}
+// IsExpired reports whether the host's expiry date has passed.
+// A host stays valid through the whole day it expires on.
+func (h SSHHost) IsExpired(now time.Time) bool {
+	date, ok := h.ExpiryDate()
+	return ok && !now.Before(date.AddDate(0, 0, 1))
+}
+
+// ExpiresWithin reports whether the host is not yet expired but will expire within d
+func (h SSHHost) ExpiresWithin(d time.Duration, now time.Time) bool {
+	date, ok := h.ExpiryDate()
+	return ok && !h.IsExpired(now) && date.Before(now.Add(d))
+}
 
-	return configDir, nil
+// IsProxied reports whether connections to the host go through a jump host or proxy command
+func (h SSHHost) IsProxied() bool {
+	return h.ProxyJump != "" || h.ProxyCommand != ""
+}
+
+// HasTag reports whether the host has tag, ignoring case
+func (h SSHHost) HasTag(tag string) bool {
+	return slices.ContainsFunc(h.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// GetDefaultSSHConfigPath returns the default SSH config path for the current platform
+func GetDefaultSSHConfigPath() (string, error) {
+	return sshconfig.DefaultPath()
 }
 
 // GetSSHMBackupDir returns the SSHM backup directory
@@ -111,8 +153,14 @@ func ensureSSHDirectory() error {
 // configMutex protects SSH config file operations from race conditions
 var configMutex sync.Mutex
 
-// backupConfig creates a backup of the SSH config file in ~/.config/sshc/backups/
+// backupConfig creates a backup of the SSH config file in ~/.config/sshc/backups/.
+// For a symlink, the file it points to is backed up.
 func backupConfig(configPath string) error {
+	if IsDryRun() {
+		return nil
+	}
+	configPath = resolveConfigPath(configPath)
+
 	// Get backup directory and ensure it exists
 	backupDir, err := GetSSHMBackupDir()
 	if err != nil {
@@ -134,6 +182,7 @@ func backupConfig(configPath string) error {
 	}
 	defer src.Close()
 
+	sshclog.Write(backupPath, "backup")
 	dst, err := os.Create(backupPath)
 	if err != nil {
 		return err
@@ -157,370 +206,147 @@ func ParseSSHConfig() ([]SSHHost, error) {
 	return ParseSSHConfigFile(configPath)
 }
 
-// ParseSSHConfigFile parses a specific SSH config file and returns the list of hosts
+// ParseSSHConfigFile parses a specific SSH config file and returns the list of hosts.
+// The main config is created when it does not exist yet.
 func ParseSSHConfigFile(configPath string) ([]SSHHost, error) {
-	return parseSSHConfigFileWithProcessedFiles(configPath, make(map[string]bool))
+	hosts, _, err := ParseSSHConfigFileWithOptions(configPath, ParseOptions{})
+	return hosts, err
 }
 
-// parseSSHConfigFileWithProcessedFiles parses SSH config with include support
-func parseSSHConfigFileWithProcessedFiles(configPath string, processedFiles map[string]bool) ([]SSHHost, error) {
-	// Resolve absolute path to prevent infinite recursion
-	absPath, err := filepath.Abs(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", configPath, err)
+// logParse writes a summary of a config parse to the debug log
+func logParse(configPath string, hosts []SSHHost, err error) {
+	if !sshclog.Enabled(slog.LevelInfo) {
+		return
 	}
-
-	// Check for circular includes
-	if processedFiles[absPath] {
-		return []SSHHost{}, nil // Skip already processed files silently
-	}
-	processedFiles[absPath] = true
-
-	// Check if the file exists, otherwise create it (and the parent directory if needed)
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Only create the main config file, not included files
-		if absPath == getMainConfigPath() {
-			// Ensure .ssh directory exists with proper permissions
-			if err := ensureSSHDirectory(); err != nil {
-				return nil, fmt.Errorf("failed to create .ssh directory: %w", err)
-			}
-
-			file, err := os.OpenFile(configPath, os.O_CREATE|os.O_WRONLY, 0600)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create SSH config file: %w", err)
-			}
-			file.Close()
-
-			// Set secure permissions on the config file
-			if err := SetSecureFilePermissions(configPath); err != nil {
-				return nil, fmt.Errorf("failed to set secure permissions: %w", err)
-			}
-		}
-
-		// File doesn't exist, return empty host list
-		return []SSHHost{}, nil
-	}
-
-	file, err := os.Open(configPath)
+	total, depth := metrics.ConfigParse.Summary()
+	args := []any{"file", configPath, "hosts", len(hosts), "files", len(metrics.ConfigParse.Files()), "include_depth", depth, "duration", total}
 	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var hosts []SSHHost
-	var currentHost *SSHHost
-	var pendingTags []string
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Ignore empty lines
-		if line == "" {
-			continue
-		}
-
-		// Check for tags comment
-		if strings.HasPrefix(line, "# Tags:") {
-			tagsStr := strings.TrimPrefix(line, "# Tags:")
-			tagsStr = strings.TrimSpace(tagsStr)
-			if tagsStr != "" {
-				// Split tags by comma and trim whitespace
-				for _, tag := range strings.Split(tagsStr, ",") {
-					tag = strings.TrimSpace(tag)
-					if tag != "" {
-						pendingTags = append(pendingTags, tag)
-					}
-				}
-			}
-			continue
-		}
-
-		// Ignore other comments
-		if strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Split line into words
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
-		}
-
-		key := strings.ToLower(parts[0])
-		value := strings.Join(parts[1:], " ")
-
-		switch key {
-		case "include":
-			// Handle Include directive
-			includeHosts, err := processIncludeDirective(value, configPath, processedFiles)
-			if err != nil {
-				// Don't fail the entire parse if include fails, just skip it
-				continue
-			}
-			hosts = append(hosts, includeHosts...)
-		case "host":
-			// New host, save previous one if it exists
-			if currentHost != nil {
-				hosts = append(hosts, *currentHost)
-
-				// Handle aliases: create duplicate hosts for each alias
-				if len(currentHost.aliasNames) > 0 {
-					for _, aliasName := range currentHost.aliasNames {
-						aliasHost := *currentHost // Copy the host
-						aliasHost.Name = aliasName
-						aliasHost.aliasNames = nil // Clear temporary field
-						hosts = append(hosts, aliasHost)
-					}
-				}
-			}
-
-			// Parse multiple host names from the Host line
-			hostNames := strings.Fields(value)
-
-			// Skip hosts with wildcards (*, ?) as they are typically patterns, not actual hosts
-			var validHostNames []string
-			for _, hostName := range hostNames {
-				if !strings.ContainsAny(hostName, "*?") {
-					validHostNames = append(validHostNames, hostName)
-				}
-			}
-
-			if len(validHostNames) == 0 {
-				currentHost = nil
-				pendingTags = nil
-				continue
-			}
-
-			// For multiple hosts, we create the first one normally
-			// and will duplicate it for others after parsing the block
-			currentHost = &SSHHost{
-				Name:       validHostNames[0], // First name as reference
-				Port:       "22",              // Default port
-				Tags:       pendingTags,       // Assign pending tags to this host
-				SourceFile: absPath,           // Track which file this host comes from
-			}
-
-			// Store additional host names for later processing
-			if len(validHostNames) > 1 {
-				currentHost.aliasNames = validHostNames[1:]
-			}
-
-			// Clear pending tags for next host
-			pendingTags = nil
-		case "hostname":
-			if currentHost != nil {
-				currentHost.Hostname = value
-			}
-		case "user":
-			if currentHost != nil {
-				currentHost.User = value
-			}
-		case "port":
-			if currentHost != nil {
-				currentHost.Port = value
-			}
-		case "identityfile":
-			if currentHost != nil {
-				currentHost.Identity = value
-			}
-		case "proxyjump":
-			if currentHost != nil {
-				currentHost.ProxyJump = value
-			}
-		case "remotecommand":
-			if currentHost != nil {
-				currentHost.RemoteCommand = value
-			}
-		case "requesttty":
-			if currentHost != nil {
-				currentHost.RequestTTY = value
-			}
-		default:
-			// Handle other SSH options
-			if currentHost != nil && strings.TrimSpace(line) != "" {
-				// Store options in config format (key value), not command format
-				if currentHost.Options == "" {
-					currentHost.Options = parts[0] + " " + value
-				} else {
-					currentHost.Options += "\n" + parts[0] + " " + value
-				}
-			}
-		}
+		sshclog.Error("config parse failed", append(args, "err", err)...)
+		return
 	}
-
-	// Add the last host if it exists
-	if currentHost != nil {
-		hosts = append(hosts, *currentHost)
-
-		// Handle aliases: create duplicate hosts for each alias
-		if len(currentHost.aliasNames) > 0 {
-			for _, aliasName := range currentHost.aliasNames {
-				aliasHost := *currentHost // Copy the host
-				aliasHost.Name = aliasName
-				aliasHost.aliasNames = nil // Clear temporary field
-				hosts = append(hosts, aliasHost)
-			}
-		}
-		// Clear the temporary field from the original
-		currentHost.aliasNames = nil
-	}
-
-	return hosts, scanner.Err()
+	sshclog.Info("config parsed", args...)
 }
 
-// processIncludeDirective processes an Include directive and returns hosts from included files
-func processIncludeDirective(pattern string, baseConfigPath string, processedFiles map[string]bool) ([]SSHHost, error) {
-	// Expand tilde to home directory
-	if strings.HasPrefix(pattern, "~") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		pattern = filepath.Join(homeDir, pattern[1:])
-	}
-
-	// If pattern is not absolute, make it relative to the base config directory
-	if !filepath.IsAbs(pattern) {
-		baseDir := filepath.Dir(baseConfigPath)
-		pattern = filepath.Join(baseDir, pattern)
-	}
-
-	// Use glob to find matching files
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("failed to glob pattern %s: %w", pattern, err)
-	}
-
-	var allHosts []SSHHost
-	for _, match := range matches {
-		// Skip directories
-		if info, err := os.Stat(match); err == nil && info.IsDir() {
-			continue
-		}
-
-		// Skip backup files created by sshc (*.backup)
-		if strings.HasSuffix(match, ".backup") {
-			continue
-		}
-
-		// Skip markdown files (*.md)
-		if strings.HasSuffix(match, ".md") {
-			continue
-		}
-
-		// Skip common non-SSH config file types
-		if isNonSSHConfigFile(match) {
-			continue
-		}
-
-		// Recursively parse the included file
-		hosts, err := parseSSHConfigFileWithProcessedFiles(match, processedFiles)
-		if err != nil {
-			// Skip files that can't be parsed rather than failing completely
-			continue
-		}
-		allHosts = append(allHosts, hosts...)
-	}
-
-	return allHosts, nil
+// getMainConfigPath returns the main SSH config path for comparison
+func getMainConfigPath() string {
+	configPath, _ := GetDefaultSSHConfigPath()
+	absPath, _ := filepath.Abs(configPath)
+	return absPath
 }
 
-// isNonSSHConfigFile checks if a file should be excluded from SSH config parsing
-func isNonSSHConfigFile(filePath string) bool {
-	fileName := strings.ToLower(filepath.Base(filePath))
-
-	// Skip common documentation files
-	if fileName == "readme" || fileName == "readme.txt" {
-		return true
-	}
-
-	// Skip files with common non-config extensions
-	excludedExtensions := []string{
-		".txt", ".md", ".rst", ".doc", ".docx", ".pdf",
-		".log", ".tmp", ".bak", ".old", ".orig",
-		".json", ".xml", ".yaml", ".yml", ".toml",
-		".sh", ".bash", ".zsh", ".fish", ".ps1", ".bat", ".cmd",
-		".py", ".pl", ".rb", ".js", ".php", ".go", ".c", ".cpp",
-		".jpg", ".jpeg", ".png", ".gif", ".bmp", ".svg",
-		".zip", ".tar", ".gz", ".bz2", ".xz",
-	}
-
-	for _, ext := range excludedExtensions {
-		if strings.HasSuffix(fileName, ext) {
-			return true
-		}
+// createMainConfig creates the main config, and the .ssh directory if needed, when
+// configPath is the main config and does not exist. Nothing is created in a dry run.
+func createMainConfig(configPath string) error {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil || absPath != getMainConfigPath() || IsDryRun() {
+		return nil
 	}
-
-	// Skip hidden files (starting with .)
-	if strings.HasPrefix(fileName, ".") {
-		return true
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		return nil
 	}
 
-	// Additional check: if file contains common non-SSH content indicators
-	// This is a more expensive check, so we do it last
-	if hasNonSSHContent(filePath) {
-		return true
+	// Ensure .ssh directory exists with proper permissions
+	if err := ensureSSHDirectory(); err != nil {
+		return fmt.Errorf("failed to create .ssh directory: %w", err)
 	}
 
-	return false
-}
-
-// hasNonSSHContent performs a quick content check to identify non-SSH files
-func hasNonSSHContent(filePath string) bool {
-	file, err := os.Open(filePath)
+	sshclog.Write(configPath, "create")
+	file, err := os.OpenFile(configPath, os.O_CREATE|os.O_WRONLY, 0600)
 	if err != nil {
-		return false // If we can't read it, don't exclude it
-	}
-	defer file.Close()
-
-	// Read only the first few KB to check content
-	buffer := make([]byte, 2048)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return false
+		return fmt.Errorf("failed to create SSH config file: %w", err)
 	}
-
-	content := strings.ToLower(string(buffer[:n]))
-
-	// Check for common non-SSH file indicators
-	nonSSHIndicators := []string{
-		"<!doctype", "<html>", "<xml>", "<?xml",
-		"#!/bin/", "#!/usr/bin/",
-		"# readme", "# documentation", "# license",
-		"package main", "function ", "class ", "def ",
-		"import ", "require ", "#include",
-		"SELECT ", "INSERT ", "UPDATE ", "DELETE ",
+	err = writeNewConfigFile(file)
+	file.Close()
+	if err != nil {
+		return err
 	}
 
-	for _, indicator := range nonSSHIndicators {
-		if strings.Contains(content, indicator) {
-			return true
-		}
+	// Set secure permissions on the config file
+	if err := SetSecureFilePermissions(configPath); err != nil {
+		return fmt.Errorf("failed to set secure permissions: %w", err)
 	}
-
-	return false
+	return nil
 }
 
-// getMainConfigPath returns the main SSH config path for comparison
-func getMainConfigPath() string {
-	configPath, _ := GetDefaultSSHConfigPath()
-	absPath, _ := filepath.Abs(configPath)
-	return absPath
-}
-
-// formatSSHConfigValue formats a value for SSH config file, adding quotes if necessary
-func formatSSHConfigValue(value string) string {
-	if value == "" {
-		return value
-	}
-
-	// If the value contains spaces, wrap it in quotes
-	if strings.Contains(value, " ") {
-		return `"` + value + `"`
-	}
-
-	return value
+// fromPublic converts a host read by the sshconfig parser. The host key and
+// canonicalization settings are picked out of its options.
+func fromPublic(host sshconfig.Host) SSHHost {
+	converted := SSHHost{
+		Name:           host.Name,
+		Hostname:       host.Hostname,
+		User:           host.User,
+		Port:           host.Port,
+		Identity:       host.IdentityFile,
+		ProxyJump:      host.ProxyJump,
+		ProxyCommand:   host.ProxyCommand,
+		RemoteCommand:  host.RemoteCommand,
+		RequestTTY:     host.RequestTTY,
+		Description:    host.Description,
+		Tags:           host.Tags,
+		Expires:        host.Expires,
+		SourceFile:     host.SourceFile,
+		ReadOnly:       host.ReadOnly,
+		BlockNames:     host.BlockNames,
+		Line:           host.Line,
+		DirectiveLines: host.DirectiveLines,
+		Order:          host.Order,
+	}
+
+	options := make([]string, 0, len(host.Options))
+	for _, option := range host.Options {
+		options = append(options, option.Name+" "+option.Value)
+		converted.setResolutionOption(strings.ToLower(option.Name), strings.Fields(option.Value))
+	}
+	converted.Options = strings.Join(options, "\n")
+	return converted
+}
+
+// toPublic converts a host for the sshconfig writer
+func toPublic(host SSHHost) sshconfig.Host {
+	converted := sshconfig.Host{
+		Name:          host.Name,
+		Hostname:      host.Hostname,
+		User:          host.User,
+		Port:          host.Port,
+		IdentityFile:  host.Identity,
+		ProxyJump:     host.ProxyJump,
+		ProxyCommand:  host.ProxyCommand,
+		RemoteCommand: host.RemoteCommand,
+		RequestTTY:    host.RequestTTY,
+		Description:   host.Description,
+		Tags:          host.Tags,
+		Expires:       host.Expires,
+	}
+	for _, line := range strings.Split(host.Options, "\n") {
+		name, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if name != "" {
+			converted.Options = append(converted.Options, sshconfig.Option{Name: name, Value: strings.TrimSpace(value)})
+		}
+	}
+	return converted
+}
+
+// editor returns the sshconfig editor for a write recorded in the audit log as
+// operation. Files are backed up first, and tags written natively when ssh
+// understands them.
+func editor(operation string, tags []string) sshconfig.Editor {
+	return sshconfig.Editor{
+		NativeTags: useNativeTags(tags),
+		Backup:     backupConfig,
+		Write: func(path, host string, before []byte, after string) error {
+			return writeConfigFile(path, operation, host, before, after)
+		},
+	}
+}
+
+// SanitizeDescription collapses a description onto a single line so it fits in one comment
+func SanitizeDescription(description string) string {
+	return configtext.SingleLine(description)
+}
+
+// formatHostBlock renders a host declaration with its metadata comments as config lines
+func formatHostBlock(names []string, host SSHHost) []string {
+	return editor(AuditUpdate, host.Tags).FormatBlock(names, toPublic(host))
 }
 
 // AddSSHHost adds a new SSH host to the config file
@@ -534,140 +360,119 @@ func AddSSHHost(host SSHHost) error {
 
 // AddSSHHostToFile adds a new SSH host to a specific config file
 func AddSSHHostToFile(host SSHHost, configPath string) error {
+	return addSSHHostToFile(host, configPath, AuditAdd)
+}
+
+// addSSHHostToFile appends a host block, recording it in the audit log as operation
+func addSSHHostToFile(host SSHHost, configPath, operation string) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
+	return editor(operation, host.Tags).Add(configPath, toPublic(host))
+}
 
-	// Create backup before modification if file exists
-	if _, err := os.Stat(configPath); err == nil {
-		if err := backupConfig(configPath); err != nil {
-			return fmt.Errorf("failed to create backup: %w", err)
-		}
-	}
-
-	// Check if host already exists in the specified config file
-	exists, err := HostExistsInFile(host.Name, configPath)
-	if err != nil {
-		return err
-	}
-	if exists {
-		return fmt.Errorf("host '%s' already exists", host.Name)
-	}
-
-	// Open file in append mode
-	file, err := os.OpenFile(configPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Write the configuration
-	_, err = file.WriteString("\n")
-	if err != nil {
-		return err
-	}
-
-	// Write tags if present
-	if len(host.Tags) > 0 {
-		_, err = file.WriteString("# Tags: " + strings.Join(host.Tags, ", ") + "\n")
-		if err != nil {
-			return err
-		}
-	}
-
-	// Write host configuration
-	_, err = file.WriteString(fmt.Sprintf("Host %s\n", host.Name))
-	if err != nil {
-		return err
-	}
-
-	_, err = file.WriteString(fmt.Sprintf("    HostName %s\n", host.Hostname))
-	if err != nil {
-		return err
-	}
-
-	if host.User != "" {
-		_, err = file.WriteString(fmt.Sprintf("    User %s\n", host.User))
-		if err != nil {
-			return err
-		}
-	}
-
-	if host.Port != "" && host.Port != "22" {
-		_, err = file.WriteString(fmt.Sprintf("    Port %s\n", host.Port))
-		if err != nil {
-			return err
-		}
-	}
-
-	if host.Identity != "" {
-		_, err = file.WriteString(fmt.Sprintf("    IdentityFile %s\n", formatSSHConfigValue(host.Identity)))
-		if err != nil {
-			return err
-		}
+// ParseSSHOptionsFromCommand converts SSH command line options to config format
+// Input: "-o Compression=yes -o ProxyCommand='ssh -W %h:%p bastion'"
+// Output: "Compression yes\nProxyCommand ssh -W %h:%p bastion"
+// Quoted values are kept whole, and words following an option without their
+// own -o belong to its value.
+func ParseSSHOptionsFromCommand(options string) string {
+	if options == "" {
+		return ""
 	}
 
-	if host.ProxyJump != "" {
-		_, err = file.WriteString(fmt.Sprintf("    ProxyJump %s\n", host.ProxyJump))
-		if err != nil {
-			return err
+	var result []string
+	words := splitCommandWords(options)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "-o":
+			if i+1 < len(words) {
+				i++
+				result = append(result, commandOptionToConfig(words[i]))
+			}
+		case strings.HasPrefix(word, "-o"):
+			result = append(result, commandOptionToConfig(word[2:]))
+		case len(result) > 0:
+			result[len(result)-1] += " " + word
+		default:
+			result = append(result, commandOptionToConfig(word))
 		}
 	}
 
-	if host.RemoteCommand != "" {
-		_, err = file.WriteString(fmt.Sprintf("    RemoteCommand %s\n", host.RemoteCommand))
-		if err != nil {
-			return err
-		}
-	}
+	return strings.Join(result, "\n")
+}
 
-	if host.RequestTTY != "" {
-		_, err = file.WriteString(fmt.Sprintf("    RequestTTY %s\n", host.RequestTTY))
-		if err != nil {
-			return err
+// NormalizeSSHOptions returns options one per line in config format, converting
+// lines pasted in command line style ("-o Key=value") and dropping blank lines.
+// Lines already in config format are kept verbatim.
+func NormalizeSSHOptions(options string) string {
+	var result []string
+	for _, line := range strings.Split(strings.ReplaceAll(options, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
 		}
-	}
-
-	// Write SSH options
-	if host.Options != "" {
-		// Split options by newlines and write each one
-		options := strings.Split(host.Options, "\n")
-		for _, option := range options {
-			option = strings.TrimSpace(option)
-			if option != "" {
-				_, err = file.WriteString(fmt.Sprintf("    %s\n", option))
-				if err != nil {
-					return err
-				}
+		if strings.HasPrefix(line, "-o") {
+			if converted := ParseSSHOptionsFromCommand(line); converted != "" {
+				result = append(result, converted)
 			}
+			continue
 		}
+		result = append(result, line)
 	}
-
-	return nil
+	return strings.Join(result, "\n")
 }
 
-// ParseSSHOptionsFromCommand converts SSH command line options to config format
-// Input: "-o Compression=yes -o ServerAliveInterval=60"
-// Output: "Compression yes\nServerAliveInterval 60"
-func ParseSSHOptionsFromCommand(options string) string {
-	if options == "" {
-		return ""
-	}
-
-	var result []string
-	parts := strings.Split(options, "-o")
-
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
+// commandOptionToConfig turns "Key=value" into "Key value"
+func commandOptionToConfig(option string) string {
+	option = strings.TrimSpace(option)
+	if i := strings.IndexAny(option, "= \t"); i > 0 {
+		return option[:i] + " " + strings.TrimSpace(option[i+1:])
+	}
+	return option
+}
+
+// splitCommandWords splits a command line into words the way a POSIX shell
+// does, removing quotes and backslash escapes. An unterminated quote runs to
+// the end of the line.
+func splitCommandWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
 		}
-
-		// Replace = with space for SSH config format
-		option := strings.ReplaceAll(part, "=", " ")
-		result = append(result, option)
 	}
-
-	return strings.Join(result, "\n")
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
 }
 
 // FormatSSHOptionsForCommand converts SSH config options to command line format
@@ -722,34 +527,7 @@ func HostExistsInFile(hostName string, configPath string) (bool, error) {
 
 // HostExistsInSpecificFile checks if a host exists in a specific file only (no includes)
 func HostExistsInSpecificFile(hostName string, configPath string) (bool, error) {
-	file, err := os.Open(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Check for Host declaration
-		if strings.HasPrefix(strings.ToLower(line), "host ") {
-			// Extract host names (can be multiple hosts on one line)
-			hostPart := strings.TrimSpace(line[5:]) // Remove "host "
-			hostNames := strings.Fields(hostPart)
-
-			for _, name := range hostNames {
-				if name == hostName {
-					return true, nil
-				}
-			}
-		}
-	}
-
-	return false, scanner.Err()
+	return sshconfig.HasHost(configPath, hostName)
 }
 
 // GetSSHHost retrieves a specific host configuration by name
@@ -798,7 +576,9 @@ func QuickHostExistsInFile(hostName string, configPath string) (bool, error) {
 	return quickHostSearchInFile(hostName, configPath, make(map[string]bool))
 }
 
-// quickHostSearchInFile performs optimized host search with early termination
+// quickHostSearchInFile performs optimized host search with early termination.
+// The file's own Host lines are searched first; its includes are only followed
+// when the host is not declared there.
 func quickHostSearchInFile(hostName string, configPath string, processedFiles map[string]bool) (bool, error) {
 	// Resolve absolute path to prevent infinite recursion
 	absPath, err := filepath.Abs(configPath)
@@ -823,66 +603,62 @@ func quickHostSearchInFile(hostName string, configPath string, processedFiles ma
 	}
 	defer file.Close()
 
+	var includes []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		line := configtext.TrimLine(scanner.Text())
 
 		// Ignore empty lines and comments (except includes)
 		if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "# Tags:")) {
 			continue
 		}
 
-		// Split line into words
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
+		// Split line into a keyword and its value, "Keyword=value" included
+		keyword, value := configtext.SplitDirective(line)
+		if value == "" {
 			continue
 		}
-
-		key := strings.ToLower(parts[0])
-		value := strings.Join(parts[1:], " ")
+		key := strings.ToLower(keyword)
 
 		switch key {
 		case "include":
-			// Handle Include directive - search in included files
-			if found, err := quickSearchInclude(hostName, value, configPath, processedFiles); err == nil && found {
-				return true, nil // Found in included file
-			}
+			// Search included files only once this file is done
+			includes = append(includes, value)
 		case "host":
 			// Parse multiple host names from the Host line
-			hostNames := strings.Fields(value)
+			hostNames := configtext.SplitHostNames(value)
 
 			// Check if our target host is in this Host declaration
 			for _, candidateHostName := range hostNames {
-				// Skip hosts with wildcards (*, ?) as they are typically patterns
-				if !strings.ContainsAny(candidateHostName, "*?") && candidateHostName == hostName {
+				// Skip wildcard and negated names as they are patterns
+				if !configtext.IsPattern(candidateHostName) && candidateHostName == hostName {
 					return true, nil // Found the host!
 				}
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+
+	for _, pattern := range includes {
+		if found, err := quickSearchInclude(hostName, pattern, configPath, processedFiles); err == nil && found {
+			return true, nil // Found in included file
+		}
+	}
 
-	return false, scanner.Err()
+	return false, nil
 }
 
 // quickSearchInclude handles Include directives during quick host search
 func quickSearchInclude(hostName, pattern, baseConfigPath string, processedFiles map[string]bool) (bool, error) {
-	// Expand tilde to home directory
-	if strings.HasPrefix(pattern, "~") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return false, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		pattern = filepath.Join(homeDir, pattern[1:])
-	}
-
-	// If pattern is not absolute, make it relative to the base config directory
-	if !filepath.IsAbs(pattern) {
-		baseDir := filepath.Dir(baseConfigPath)
-		pattern = filepath.Join(baseDir, pattern)
+	pattern, err := sshconfig.ResolveIncludePath(pattern, baseConfigPath)
+	if err != nil {
+		return false, err
 	}
 
 	// Use glob to find matching files
-	matches, err := filepath.Glob(pattern)
+	matches, err := configtext.Glob(pattern)
 	if err != nil {
 		return false, fmt.Errorf("failed to glob pattern %s: %w", pattern, err)
 	}
@@ -893,8 +669,9 @@ func quickSearchInclude(hostName, pattern, baseConfigPath string, processedFiles
 			continue
 		}
 
-		// Skip non-SSH config files (this avoids parsing README, etc.)
-		if isNonSSHConfigFile(match) {
+		// Skip non-SSH config files by name (this avoids parsing README, etc.).
+		// Their content is not sniffed, which would read every match.
+		if configtext.IgnoredName(match) {
 			continue
 		}
 
@@ -918,367 +695,21 @@ func IsPartOfMultiHostDeclaration(hostName string, configPath string) (bool, []s
 	if err != nil {
 		return false, nil, err
 	}
-
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		if strings.HasPrefix(strings.ToLower(line), "host ") {
-			// Extract host names (can be multiple hosts on one line)
-			hostPart := strings.TrimSpace(line[5:]) // Remove "host "
-			hostNames := strings.Fields(hostPart)
-
-			// Check if our target host is in this Host declaration
-			for _, name := range hostNames {
-				if name == hostName {
-					if len(hostNames) > 1 {
-						return true, hostNames, nil
-					}
-					return false, hostNames, nil
-				}
-			}
-		}
-	}
-
-	return false, nil, scanner.Err()
+	hostNames, err := configtext.DeclaringNames(string(content), hostName)
+	return len(hostNames) > 1, hostNames, err
 }
 
 // UpdateSSHHostInFile updates an existing SSH host configuration in a specific file
 func UpdateSSHHostInFile(oldName string, newHost SSHHost, configPath string) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
+	return editor(AuditUpdate, newHost.Tags).Update(configPath, oldName, toPublic(newHost))
+}
 
-	// Create backup before modification
-	if err := backupConfig(configPath); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
-	}
-
-	// Check if this host is part of a multi-host declaration
-	isMultiHost, hostNames, err := IsPartOfMultiHostDeclaration(oldName, configPath)
-	if err != nil {
-		return fmt.Errorf("failed to check multi-host declaration: %w", err)
-	}
-
-	// Read the current config
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	i := 0
-	hostFound := false
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-
-			// Check if this is a Host line that contains our target host
-			if strings.HasPrefix(nextLine, "Host ") {
-				hostPart := strings.TrimSpace(nextLine[5:]) // Remove "Host "
-				foundHostNames := strings.Fields(hostPart)
-
-				// Check if our target host is in this Host declaration
-				targetHostIndex := -1
-				for idx, hostName := range foundHostNames {
-					if hostName == oldName {
-						targetHostIndex = idx
-						break
-					}
-				}
-
-				if targetHostIndex != -1 {
-					hostFound = true
-
-					if isMultiHost && len(hostNames) > 1 {
-						// Strategy: Remove old host from the line, add new host as separate entry
-						// Remove the old host name from the Host line
-						var remainingHosts []string
-						for idx, hostName := range foundHostNames {
-							if idx != targetHostIndex {
-								remainingHosts = append(remainingHosts, hostName)
-							}
-						}
-
-						// Keep the tags comment
-						newLines = append(newLines, lines[i])
-
-						// Update the Host line with remaining hosts
-						if len(remainingHosts) > 0 {
-							newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-							// Copy the existing configuration for remaining hosts
-							i += 2 // Skip tags and original Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								newLines = append(newLines, lines[i])
-								i++
-							}
-						} else {
-							// No remaining hosts, skip the entire block
-							i += 2 // Skip tags and Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								i++
-							}
-						}
-
-						// Add the new host as a separate entry
-						newLines = append(newLines, "")
-						if len(newHost.Tags) > 0 {
-							newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-						}
-						newLines = append(newLines, "Host "+newHost.Name)
-						newLines = append(newLines, "    HostName "+newHost.Hostname)
-						if newHost.User != "" {
-							newLines = append(newLines, "    User "+newHost.User)
-						}
-						if newHost.Port != "" && newHost.Port != "22" {
-							newLines = append(newLines, "    Port "+newHost.Port)
-						}
-						if newHost.Identity != "" {
-							newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-						}
-						if newHost.ProxyJump != "" {
-							newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-						}
-						if newHost.RemoteCommand != "" {
-							newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-						}
-						if newHost.RequestTTY != "" {
-							newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-						}
-						// Write SSH options
-						if newHost.Options != "" {
-							options := strings.Split(newHost.Options, "\n")
-							for _, option := range options {
-								option = strings.TrimSpace(option)
-								if option != "" {
-									newLines = append(newLines, "    "+option)
-								}
-							}
-						}
-						newLines = append(newLines, "")
-
-						continue
-					} else {
-						// Simple case: only one host, replace entire block
-						// Skip until we find the end of this host block (empty line or next Host)
-						i += 2 // Skip tags and Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-
-						// Skip any trailing empty lines after the host block
-						for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-							i++
-						}
-
-						// Insert new configuration at this position
-						// Add empty line only if the previous line is not empty
-						if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-							newLines = append(newLines, "")
-						}
-						if len(newHost.Tags) > 0 {
-							newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-						}
-						newLines = append(newLines, "Host "+newHost.Name)
-						newLines = append(newLines, "    HostName "+newHost.Hostname)
-						if newHost.User != "" {
-							newLines = append(newLines, "    User "+newHost.User)
-						}
-						if newHost.Port != "" && newHost.Port != "22" {
-							newLines = append(newLines, "    Port "+newHost.Port)
-						}
-						if newHost.Identity != "" {
-							newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-						}
-						if newHost.ProxyJump != "" {
-							newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-						}
-						if newHost.RemoteCommand != "" {
-							newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-						}
-						if newHost.RequestTTY != "" {
-							newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-						}
-						// Write SSH options
-						if newHost.Options != "" {
-							options := strings.Split(newHost.Options, "\n")
-							for _, option := range options {
-								option = strings.TrimSpace(option)
-								if option != "" {
-									newLines = append(newLines, "    "+option)
-								}
-							}
-						}
-
-						// Add empty line after the host configuration for separation
-						newLines = append(newLines, "")
-
-						continue
-					}
-				}
-			}
-		}
-
-		// Check for Host line without tags
-		if strings.HasPrefix(line, "Host ") {
-			hostPart := strings.TrimSpace(line[5:]) // Remove "Host "
-			foundHostNames := strings.Fields(hostPart)
-
-			// Check if our target host is in this Host declaration
-			targetHostIndex := -1
-			for idx, hostName := range foundHostNames {
-				if hostName == oldName {
-					targetHostIndex = idx
-					break
-				}
-			}
-
-			if targetHostIndex != -1 {
-				hostFound = true
-
-				if isMultiHost && len(hostNames) > 1 {
-					// Strategy: Remove old host from the line, add new host as separate entry
-					// Remove the old host name from the Host line
-					var remainingHosts []string
-					for idx, hostName := range foundHostNames {
-						if idx != targetHostIndex {
-							remainingHosts = append(remainingHosts, hostName)
-						}
-					}
-
-					// Update the Host line with remaining hosts
-					if len(remainingHosts) > 0 {
-						newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-						// Copy the existing configuration for remaining hosts
-						i++ // Skip original Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							newLines = append(newLines, lines[i])
-							i++
-						}
-					} else {
-						// No remaining hosts, skip the entire block
-						i++ // Skip Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-					}
-
-					// Add the new host as a separate entry
-					newLines = append(newLines, "")
-					if len(newHost.Tags) > 0 {
-						newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-					}
-					newLines = append(newLines, "Host "+newHost.Name)
-					newLines = append(newLines, "    HostName "+newHost.Hostname)
-					if newHost.User != "" {
-						newLines = append(newLines, "    User "+newHost.User)
-					}
-					if newHost.Port != "" && newHost.Port != "22" {
-						newLines = append(newLines, "    Port "+newHost.Port)
-					}
-					if newHost.Identity != "" {
-						newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-					}
-					if newHost.ProxyJump != "" {
-						newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-					}
-					if newHost.RemoteCommand != "" {
-						newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-					}
-					if newHost.RequestTTY != "" {
-						newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-					}
-					// Write SSH options
-					if newHost.Options != "" {
-						options := strings.Split(newHost.Options, "\n")
-						for _, option := range options {
-							option = strings.TrimSpace(option)
-							if option != "" {
-								newLines = append(newLines, "    "+option)
-							}
-						}
-					}
-					newLines = append(newLines, "")
-
-					continue
-				} else {
-					// Simple case: only one host, replace entire block
-					// Skip until we find the end of this host block
-					i++ // Skip Host line
-					for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-						i++
-					}
-
-					// Skip any trailing empty lines after the host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-						i++
-					}
-
-					// Insert new configuration
-					// Add empty line only if the previous line is not empty
-					if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-						newLines = append(newLines, "")
-					}
-					if len(newHost.Tags) > 0 {
-						newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-					}
-					newLines = append(newLines, "Host "+newHost.Name)
-					newLines = append(newLines, "    HostName "+newHost.Hostname)
-					if newHost.User != "" {
-						newLines = append(newLines, "    User "+newHost.User)
-					}
-					if newHost.Port != "" && newHost.Port != "22" {
-						newLines = append(newLines, "    Port "+newHost.Port)
-					}
-					if newHost.Identity != "" {
-						newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(newHost.Identity))
-					}
-					if newHost.ProxyJump != "" {
-						newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-					}
-					if newHost.RemoteCommand != "" {
-						newLines = append(newLines, "    RemoteCommand "+newHost.RemoteCommand)
-					}
-					if newHost.RequestTTY != "" {
-						newLines = append(newLines, "    RequestTTY "+newHost.RequestTTY)
-					}
-					// Write SSH options
-					if newHost.Options != "" {
-						options := strings.Split(newHost.Options, "\n")
-						for _, option := range options {
-							option = strings.TrimSpace(option)
-							if option != "" {
-								newLines = append(newLines, "    "+option)
-							}
-						}
-					}
-
-					// Add empty line after the host configuration for separation
-					newLines = append(newLines, "")
-
-					continue
-				}
-			}
-		}
-
-		// Keep other lines as-is
-		newLines = append(newLines, lines[i])
-		i++
-	}
-
-	if !hostFound {
-		return fmt.Errorf("host '%s' not found", oldName)
-	}
-
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+// updatedHostContent returns content with the block of oldName replaced by newHost.
+// A host sharing its block with others is split out into a block of its own.
+func updatedHostContent(content, oldName string, newHost SSHHost) (string, error) {
+	return editor(AuditUpdate, newHost.Tags).ReplaceHost(content, oldName, toPublic(newHost))
 }
 
 // DeleteSSHHost removes an SSH host configuration from the config file
@@ -1288,194 +719,20 @@ func DeleteSSHHost(hostName string) error {
 
 // DeleteSSHHostFromFile deletes an SSH host from a specific config file
 func DeleteSSHHostFromFile(hostName, configPath string) error {
+	return deleteSSHHostFromFile(hostName, configPath, AuditDelete)
+}
+
+// deleteSSHHostFromFile removes a host, recording it in the audit log as operation
+func deleteSSHHostFromFile(hostName, configPath, operation string) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
+	return editor(operation, nil).Delete(configPath, hostName)
+}
 
-	// Create backup before modification
-	if err := backupConfig(configPath); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
-	}
-
-	// Check if this host is part of a multi-host declaration
-	isMultiHost, hostNames, err := IsPartOfMultiHostDeclaration(hostName, configPath)
-	if err != nil {
-		return fmt.Errorf("failed to check multi-host declaration: %w", err)
-	}
-
-	// Read the current config
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	i := 0
-	hostFound := false
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-
-			// Check if this is a Host line that contains our target host
-			if strings.HasPrefix(nextLine, "Host ") {
-				hostPart := strings.TrimSpace(nextLine[5:]) // Remove "Host "
-				foundHostNames := strings.Fields(hostPart)
-
-				// Check if our target host is in this Host declaration
-				targetHostIndex := -1
-				for idx, host := range foundHostNames {
-					if host == hostName {
-						targetHostIndex = idx
-						break
-					}
-				}
-
-				if targetHostIndex != -1 {
-					hostFound = true
-
-					if isMultiHost && len(hostNames) > 1 {
-						// Remove the target host from the multi-host line
-						var remainingHosts []string
-						for idx, host := range foundHostNames {
-							if idx != targetHostIndex {
-								remainingHosts = append(remainingHosts, host)
-							}
-						}
-
-						// Keep the tags comment
-						newLines = append(newLines, lines[i])
-
-						if len(remainingHosts) > 0 {
-							// Update the Host line with remaining hosts
-							newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-							// Copy the existing configuration for remaining hosts
-							i += 2 // Skip tags and original Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								newLines = append(newLines, lines[i])
-								i++
-							}
-						} else {
-							// No remaining hosts, skip the entire block
-							i += 2 // Skip tags and Host line
-							for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-								i++
-							}
-						}
-
-						// Skip any trailing empty lines after the host block
-						for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-							i++
-						}
-
-						continue
-					} else {
-						// Single host or last host in multi-host block, delete entire block
-						// Skip tags comment and Host line
-						i += 2
-
-						// Skip until we find the end of this host block (empty line or next Host)
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-
-						// Skip any trailing empty lines after the host block
-						for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-							i++
-						}
-
-						continue
-					}
-				}
-			}
-		}
-
-		// Check for Host line without tags
-		if strings.HasPrefix(line, "Host ") {
-			hostPart := strings.TrimSpace(line[5:]) // Remove "Host "
-			foundHostNames := strings.Fields(hostPart)
-
-			// Check if our target host is in this Host declaration
-			targetHostIndex := -1
-			for idx, host := range foundHostNames {
-				if host == hostName {
-					targetHostIndex = idx
-					break
-				}
-			}
-
-			if targetHostIndex != -1 {
-				hostFound = true
-
-				if isMultiHost && len(hostNames) > 1 {
-					// Remove the target host from the multi-host line
-					var remainingHosts []string
-					for idx, host := range foundHostNames {
-						if idx != targetHostIndex {
-							remainingHosts = append(remainingHosts, host)
-						}
-					}
-
-					if len(remainingHosts) > 0 {
-						// Update the Host line with remaining hosts
-						newLines = append(newLines, "Host "+strings.Join(remainingHosts, " "))
-
-						// Copy the existing configuration for remaining hosts
-						i++ // Skip original Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							newLines = append(newLines, lines[i])
-							i++
-						}
-					} else {
-						// No remaining hosts, skip the entire block
-						i++ // Skip Host line
-						for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-							i++
-						}
-					}
-
-					// Skip any trailing empty lines after the host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-						i++
-					}
-
-					continue
-				} else {
-					// Single host, delete entire block
-					// Skip Host line
-					i++
-
-					// Skip until we find the end of this host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-						i++
-					}
-
-					// Skip any trailing empty lines after the host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-						i++
-					}
-
-					continue
-				}
-			}
-		}
-
-		// Keep other lines as-is
-		newLines = append(newLines, lines[i])
-		i++
-	}
-
-	if !hostFound {
-		return fmt.Errorf("host '%s' not found", hostName)
-	}
-
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+// removeHostFromContent removes hostName from config content. A host sharing its
+// Host line with others is only taken off that line; the block stays for the rest.
+func removeHostFromContent(content, hostName string) (string, error) {
+	return sshconfig.Editor{}.RemoveHost(content, hostName)
 }
 
 // FindHostInAllConfigs finds a host in all configuration files and returns the host with its source file
@@ -1500,16 +757,7 @@ func GetAllConfigFiles() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	processedFiles := make(map[string]bool)
-	_, _ = parseSSHConfigFileWithProcessedFiles(configPath, processedFiles)
-
-	files := make([]string, 0, len(processedFiles))
-	for file := range processedFiles {
-		files = append(files, file)
-	}
-
-	return files, nil
+	return sshconfig.IncludedFiles(configPath)
 }
 
 // GetAllConfigFilesFromBase returns all SSH config files starting from a specific base config file
@@ -1518,17 +766,10 @@ func GetAllConfigFilesFromBase(baseConfigPath string) ([]string, error) {
 		// Fallback to default behavior
 		return GetAllConfigFiles()
 	}
+	return sshconfig.IncludedFiles(baseConfigPath)
+}
 
-	processedFiles := make(map[string]bool)
-	_, _ = parseSSHConfigFileWithProcessedFiles(baseConfigPath, processedFiles)
-
-	files := make([]string, 0, len(processedFiles))
-	for file := range processedFiles {
-		files = append(files, file)
-	}
-
-	return files, nil
-} // UpdateSSHHostV2 updates an existing SSH host configuration, searching in all config files
+// UpdateSSHHostV2 updates an existing SSH host configuration, searching in all config files
 func UpdateSSHHostV2(oldName string, newHost SSHHost) error {
 	// Find the host to determine which file it's in
 	existingHost, err := FindHostInAllConfigs(oldName)
@@ -1592,33 +833,7 @@ func GetIncludedConfigFiles() ([]string, error) {
 
 // MoveHostToFile moves an SSH host from its current config file to a target config file
 func MoveHostToFile(hostName string, targetConfigFile string) error {
-	// Find the host in all configs to get its current location and data
-	host, err := FindHostInAllConfigs(hostName)
-	if err != nil {
-		return err
-	}
-
-	// Check if the target file is different from the current source file
-	if host.SourceFile == targetConfigFile {
-		return fmt.Errorf("host '%s' is already in the target config file '%s'", hostName, targetConfigFile)
-	}
-
-	// First, add the host to the target config file
-	err = AddSSHHostToFile(*host, targetConfigFile)
-	if err != nil {
-		return fmt.Errorf("failed to add host to target file: %v", err)
-	}
-
-	// Then, remove the host from its current source file
-	err = DeleteSSHHostFromFile(hostName, host.SourceFile)
-	if err != nil {
-		// If removal fails, we should try to rollback the addition, but for simplicity
-		// we'll just return the error. In a production environment, you might want
-		// to implement a proper rollback mechanism.
-		return fmt.Errorf("failed to remove host from source file: %v", err)
-	}
-
-	return nil
+	return MoveHostsToFile([]string{hostName}, targetConfigFile)
 }
 
 // GetConfigFilesExcludingCurrent returns all config files except the one containing the specified host
@@ -1658,211 +873,11 @@ func GetConfigFilesExcludingCurrent(hostName string, baseConfigFile string) ([]s
 func UpdateMultiHostBlock(originalHosts, newHosts []string, commonProperties SSHHost, configPath string) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
+	return editor(AuditUpdate, commonProperties.Tags).UpdateBlock(configPath, originalHosts, newHosts, toPublic(commonProperties))
+}
 
-	// Create backup before modification
-	if err := backupConfig(configPath); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
-	}
-
-	// Read the current config
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	i := 0
-	blockFound := false
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-
-			// Check if this is a Host line that contains any of our original hosts
-			if strings.HasPrefix(nextLine, "Host ") {
-				hostPart := strings.TrimSpace(nextLine[5:]) // Remove "Host "
-				foundHostNames := strings.Fields(hostPart)
-
-				// Check if any of our original hosts are in this Host declaration
-				hasOriginalHost := false
-				for _, origHost := range originalHosts {
-					for _, foundHost := range foundHostNames {
-						if foundHost == origHost {
-							hasOriginalHost = true
-							break
-						}
-					}
-					if hasOriginalHost {
-						break
-					}
-				}
-
-				if hasOriginalHost {
-					blockFound = true
-
-					// Skip the old block entirely
-					i += 2 // Skip tags and Host line
-					for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-						i++
-					}
-
-					// Skip any trailing empty lines after the host block
-					for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-						i++
-					}
-
-					// Insert new multi-host configuration
-					if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-						newLines = append(newLines, "")
-					}
-
-					// Add tags if present
-					if len(commonProperties.Tags) > 0 {
-						newLines = append(newLines, "# Tags: "+strings.Join(commonProperties.Tags, ", "))
-					}
-
-					// Add Host line with new host names
-					newLines = append(newLines, "Host "+strings.Join(newHosts, " "))
-
-					// Add common properties
-					newLines = append(newLines, "    HostName "+commonProperties.Hostname)
-					if commonProperties.User != "" {
-						newLines = append(newLines, "    User "+commonProperties.User)
-					}
-					if commonProperties.Port != "" && commonProperties.Port != "22" {
-						newLines = append(newLines, "    Port "+commonProperties.Port)
-					}
-					if commonProperties.Identity != "" {
-						newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(commonProperties.Identity))
-					}
-					if commonProperties.ProxyJump != "" {
-						newLines = append(newLines, "    ProxyJump "+commonProperties.ProxyJump)
-					}
-					if commonProperties.RemoteCommand != "" {
-						newLines = append(newLines, "    RemoteCommand "+commonProperties.RemoteCommand)
-					}
-					if commonProperties.RequestTTY != "" {
-						newLines = append(newLines, "    RequestTTY "+commonProperties.RequestTTY)
-					}
-
-					// Write SSH options
-					if commonProperties.Options != "" {
-						options := strings.Split(commonProperties.Options, "\n")
-						for _, option := range options {
-							option = strings.TrimSpace(option)
-							if option != "" {
-								newLines = append(newLines, "    "+option)
-							}
-						}
-					}
-
-					// Add empty line after the block
-					newLines = append(newLines, "")
-
-					continue
-				}
-			}
-		}
-
-		// Check for Host line without tags (same logic)
-		if strings.HasPrefix(line, "Host ") {
-			hostPart := strings.TrimSpace(line[5:]) // Remove "Host "
-			foundHostNames := strings.Fields(hostPart)
-
-			// Check if any of our original hosts are in this Host declaration
-			hasOriginalHost := false
-			for _, origHost := range originalHosts {
-				for _, foundHost := range foundHostNames {
-					if foundHost == origHost {
-						hasOriginalHost = true
-						break
-					}
-				}
-				if hasOriginalHost {
-					break
-				}
-			}
-
-			if hasOriginalHost {
-				blockFound = true
-
-				// Skip the old block entirely
-				i++ // Skip Host line
-				for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-					i++
-				}
-
-				// Skip any trailing empty lines after the host block
-				for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-					i++
-				}
-
-				// Insert new multi-host configuration
-				if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-					newLines = append(newLines, "")
-				}
-
-				// Add tags if present
-				if len(commonProperties.Tags) > 0 {
-					newLines = append(newLines, "# Tags: "+strings.Join(commonProperties.Tags, ", "))
-				}
-
-				// Add Host line with new host names
-				newLines = append(newLines, "Host "+strings.Join(newHosts, " "))
-
-				// Add common properties
-				newLines = append(newLines, "    HostName "+commonProperties.Hostname)
-				if commonProperties.User != "" {
-					newLines = append(newLines, "    User "+commonProperties.User)
-				}
-				if commonProperties.Port != "" && commonProperties.Port != "22" {
-					newLines = append(newLines, "    Port "+commonProperties.Port)
-				}
-				if commonProperties.Identity != "" {
-					newLines = append(newLines, "    IdentityFile "+formatSSHConfigValue(commonProperties.Identity))
-				}
-				if commonProperties.ProxyJump != "" {
-					newLines = append(newLines, "    ProxyJump "+commonProperties.ProxyJump)
-				}
-				if commonProperties.RemoteCommand != "" {
-					newLines = append(newLines, "    RemoteCommand "+commonProperties.RemoteCommand)
-				}
-				if commonProperties.RequestTTY != "" {
-					newLines = append(newLines, "    RequestTTY "+commonProperties.RequestTTY)
-				}
-
-				// Write SSH options
-				if commonProperties.Options != "" {
-					options := strings.Split(commonProperties.Options, "\n")
-					for _, option := range options {
-						option = strings.TrimSpace(option)
-						if option != "" {
-							newLines = append(newLines, "    "+option)
-						}
-					}
-				}
-
-				// Add empty line after the block
-				newLines = append(newLines, "")
-
-				continue
-			}
-		}
-
-		// Keep other lines as-is
-		newLines = append(newLines, lines[i])
-		i++
-	}
-
-	if !blockFound {
-		return fmt.Errorf("multi-host block not found")
-	}
-
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+// updatedMultiHostContent returns content with the block declaring any of originalHosts
+// replaced by a single block for newHosts
+func updatedMultiHostContent(content string, originalHosts, newHosts []string, commonProperties SSHHost) (string, error) {
+	return editor(AuditUpdate, commonProperties.Tags).ReplaceBlock(content, originalHosts, newHosts, toPublic(commonProperties))
 }
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetDefaultSSHConfigPath(t *testing.T) {
@@ -1460,59 +1463,6 @@ func createTempConfigFile(content string) (string, error) {
 	return tempFile.Name(), nil
 }
 
-func TestFormatSSHConfigValue(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "simple path without spaces",
-			input:    "/home/user/.ssh/id_rsa",
-			expected: "/home/user/.ssh/id_rsa",
-		},
-		{
-			name:     "path with spaces",
-			input:    "/home/user/My Documents/ssh key",
-			expected: "\"/home/user/My Documents/ssh key\"",
-		},
-		{
-			name:     "Windows path with spaces",
-			input:    `G:\My Drive\7 - Tech\9 - SSH Keys\Server_WF.opk`,
-			expected: `"G:\My Drive\7 - Tech\9 - SSH Keys\Server_WF.opk"`,
-		},
-		{
-			name:     "path with quotes but no spaces",
-			input:    `/home/user/key"with"quotes`,
-			expected: `/home/user/key"with"quotes`,
-		},
-		{
-			name:     "path with spaces and quotes",
-			input:    `/home/user/key "with" quotes`,
-			expected: `"/home/user/key "with" quotes"`,
-		},
-		{
-			name:     "empty path",
-			input:    "",
-			expected: "",
-		},
-		{
-			name:     "path with single space at end",
-			input:    "/home/user/key ",
-			expected: "\"/home/user/key \"",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := formatSSHConfigValue(tt.input)
-			if result != tt.expected {
-				t.Errorf("formatSSHConfigValue(%q) = %q, want %q", tt.input, result, tt.expected)
-			}
-		})
-	}
-}
-
 func TestAddSSHHostWithSpacesInPath(t *testing.T) {
 	// Create temporary config file
 	configFile, err := createTempConfigFile(`Host existing
@@ -1549,68 +1499,6 @@ func TestAddSSHHostWithSpacesInPath(t *testing.T) {
 	}
 }
 
-func TestIsNonSSHConfigFile(t *testing.T) {
-	tests := []struct {
-		fileName string
-		expected bool
-	}{
-		// Should be excluded
-		{"README", true},
-		{"README.txt", true},
-		{"README.md", true},
-		{"script.sh", true},
-		{"data.json", true},
-		{"notes.txt", true},
-		{".gitignore", true},
-		{"backup.bak", true},
-		{"old.orig", true},
-		{"log.log", true},
-		{"temp.tmp", true},
-		{"archive.zip", true},
-		{"image.jpg", true},
-		{"python.py", true},
-		{"golang.go", true},
-		{"config.yaml", true},
-		{"config.yml", true},
-		{"config.toml", true},
-
-		// Should NOT be excluded (valid SSH config files)
-		{"config", false},
-		{"servers.conf", false},
-		{"production", false},
-		{"staging", false},
-		{"hosts", false},
-		{"ssh_config", false},
-		{"work-servers", false},
-	}
-
-	for _, test := range tests {
-		// Create a temporary file for content testing
-		tempDir := t.TempDir()
-		filePath := filepath.Join(tempDir, test.fileName)
-
-		// Write appropriate content based on expected result
-		var content string
-		if test.expected {
-			// Write non-SSH content for files that should be excluded
-			content = "# This is not an SSH config file\nSome random content"
-		} else {
-			// Write SSH-like content for files that should be included
-			content = "Host example\n    HostName example.com\n    User testuser"
-		}
-
-		err := os.WriteFile(filePath, []byte(content), 0600)
-		if err != nil {
-			t.Fatalf("Failed to create test file %s: %v", test.fileName, err)
-		}
-
-		result := isNonSSHConfigFile(filePath)
-		if result != test.expected {
-			t.Errorf("isNonSSHConfigFile(%q) = %v, want %v", test.fileName, result, test.expected)
-		}
-	}
-}
-
 func TestQuickHostExists(t *testing.T) {
 	// Create temporary directory for test files
 	tempDir := t.TempDir()
@@ -1694,3 +1582,469 @@ Host production-server
 		}
 	}
 }
+
+func TestQuickHostExistsSkipsExcludedNames(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "config.d")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	mainConfig := filepath.Join(tempDir, "config")
+	writeFile(t, mainConfig, "Include config.d/*\n\nHost main-host\n    HostName example.com\n")
+	writeFile(t, filepath.Join(configDir, ".hidden"), "Host hidden-host\n    HostName hidden.example.com\n")
+	writeFile(t, filepath.Join(configDir, "hosts.sh"), "Host script-host\n    HostName script.example.com\n")
+	writeFile(t, filepath.Join(configDir, "servers"), "Include nested/*.conf\n")
+	if err := os.MkdirAll(filepath.Join(configDir, "nested"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(configDir, "nested", "db.conf"), "Host nested-host\n    HostName db.example.com\n")
+
+	for hostName, want := range map[string]bool{
+		"main-host":   true,
+		"nested-host": true,
+		"hidden-host": false,
+		"script-host": false,
+	} {
+		found, err := QuickHostExistsInFile(hostName, mainConfig)
+		if err != nil {
+			t.Fatalf("QuickHostExistsInFile(%q) error = %v", hostName, err)
+		}
+		if found != want {
+			t.Errorf("QuickHostExistsInFile(%q) = %v, want %v", hostName, found, want)
+		}
+	}
+}
+
+// writeIncludingConfig writes a config including n files of 20 hosts each before
+// its own host, main-host
+func writeIncludingConfig(b *testing.B, n int) string {
+	b.Helper()
+	dir := b.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0700); err != nil {
+		b.Fatal(err)
+	}
+	for i := range n {
+		var included strings.Builder
+		for j := range 20 {
+			fmt.Fprintf(&included, "Host node-%02d-%02d\n    HostName 10.0.%d.%d\n    User deploy\n\n", i, j, i, j)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.d", fmt.Sprintf("%02d", i)), []byte(included.String()), 0600); err != nil {
+			b.Fatal(err)
+		}
+	}
+	configFile := filepath.Join(dir, "config")
+	if err := os.WriteFile(configFile, []byte("Include config.d/*\n\nHost main-host\n    HostName example.com\n"), 0600); err != nil {
+		b.Fatal(err)
+	}
+	return configFile
+}
+
+// BenchmarkQuickHostExists looks hosts up in a config with 50 included files. A
+// host of the main file touches none of them, one of the last file reads each once.
+func BenchmarkQuickHostExists(b *testing.B) {
+	configFile := writeIncludingConfig(b, 50)
+	for _, hostName := range []string{"main-host", "node-49-19", "missing"} {
+		b.Run(hostName, func(b *testing.B) {
+			for range b.N {
+				if _, err := QuickHostExistsInFile(hostName, configFile); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestHostExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name         string
+		expires      string
+		wantExpired  bool
+		wantExpiring bool
+	}{
+		{"no date", "", false, false},
+		{"invalid date", "next week", false, false},
+		{"expires today", "2026-03-10", false, true},
+		{"expired yesterday", "2026-03-09", true, false},
+		{"expires within window", "2026-03-20", false, true},
+		{"expires later", "2026-06-01", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := SSHHost{Name: "test", Expires: tt.expires}
+			if got := host.IsExpired(now); got != tt.wantExpired {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.wantExpired)
+			}
+			if got := host.ExpiresWithin(14*24*time.Hour, now); got != tt.wantExpiring {
+				t.Errorf("ExpiresWithin() = %v, want %v", got, tt.wantExpiring)
+			}
+		})
+	}
+}
+
+func TestExpiresCommentRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, "config")
+	configContent := `# Tags: staging
+# Expires: 2026-01-31
+Host old-box
+    HostName old.example.com
+    User admin
+
+Host keeper
+    HostName keeper.example.com
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0600)
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Expires != "2026-01-31" || hosts[1].Expires != "" {
+		t.Fatalf("Unexpected parsed hosts: %+v", hosts)
+	}
+
+	// Update the expiry and make sure the old metadata comments are replaced, not duplicated
+	updated := hosts[0]
+	updated.Expires = "2026-02-28"
+	if err := UpdateSSHHostInFile("old-box", updated, configFile); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Count(string(content), "# Expires:") != 1 || strings.Count(string(content), "# Tags:") != 1 {
+		t.Errorf("Expected a single Tags and Expires comment, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), "# Expires: 2026-02-28") {
+		t.Errorf("Expected updated expiry date, got:\n%s", content)
+	}
+
+	// Deleting the host should remove its metadata comments too
+	if err := DeleteSSHHostFromFile("old-box", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+
+	content, err = os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(content), "# Expires:") || strings.Contains(string(content), "# Tags:") {
+		t.Errorf("Expected metadata comments to be removed with the host, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), "Host keeper") {
+		t.Errorf("Expected keeper host to remain, got:\n%s", content)
+	}
+}
+
+func TestProxyCommandIsWrittenUnquoted(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, "config")
+	configContent := `Host legacy
+    HostName 10.0.0.5
+    ProxyCommand ssh -W %h:%p bastion
+    Compression yes
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("Expected 1 host, got %d", len(hosts))
+	}
+	host := hosts[0]
+	if host.ProxyCommand != "ssh -W %h:%p bastion" {
+		t.Errorf("ProxyCommand = %q, want %q", host.ProxyCommand, "ssh -W %h:%p bastion")
+	}
+	if strings.Contains(host.Options, "ProxyCommand") {
+		t.Errorf("Expected ProxyCommand to be kept out of Options, got %q", host.Options)
+	}
+	if !host.IsProxied() {
+		t.Error("Expected host with ProxyCommand to be proxied")
+	}
+
+	// Rewriting the host must keep the command as a bare value
+	host.User = "admin"
+	if err := UpdateSSHHostInFile("legacy", host, configFile); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if !strings.Contains(string(content), "    ProxyCommand ssh -W %h:%p bastion\n") {
+		t.Errorf("Expected unquoted ProxyCommand line, got:\n%s", content)
+	}
+	if strings.Count(string(content), "ProxyCommand") != 1 {
+		t.Errorf("Expected a single ProxyCommand line, got:\n%s", content)
+	}
+}
+
+func TestDescriptionCommentWithTagsAndAliases(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configFile := filepath.Join(tempDir, "config")
+	configContent := `# Tags: lb, prod
+# Description: primary LB, eu-west
+Host web web-alias
+    HostName web.example.com
+    User deploy
+
+Host plain
+    HostName plain.example.com
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0600)
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("Expected 3 hosts, got %d: %+v", len(hosts), hosts)
+	}
+	for _, host := range hosts[:2] {
+		if host.Description != "primary LB, eu-west" || len(host.Tags) != 2 {
+			t.Errorf("Expected %s to carry the description and tags, got %+v", host.Name, host)
+		}
+	}
+	if hosts[2].Description != "" {
+		t.Errorf("Expected plain to have no description, got %q", hosts[2].Description)
+	}
+
+	// Rewriting the block puts the comments in canonical order, directly above the Host line
+	updated := hosts[0]
+	updated.Description = "primary LB,\n eu-west"
+	if err := UpdateMultiHostBlock([]string{"web", "web-alias"}, []string{"web", "web-alias"}, updated, configFile); err != nil {
+		t.Fatalf("UpdateMultiHostBlock() error = %v", err)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	want := "# Description: primary LB, eu-west\n# Tags: lb, prod\nHost web web-alias\n"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("Expected description and tags comments adjacent and in order, got:\n%s", content)
+	}
+	if strings.Count(string(content), "# Description:") != 1 || strings.Count(string(content), "# Tags:") != 1 {
+		t.Errorf("Expected a single Description and Tags comment, got:\n%s", content)
+	}
+
+	// Saving again without changes must not churn the file
+	if err := UpdateMultiHostBlock([]string{"web", "web-alias"}, []string{"web", "web-alias"}, updated, configFile); err != nil {
+		t.Fatalf("UpdateMultiHostBlock() error = %v", err)
+	}
+	again, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(again) != string(content) {
+		t.Errorf("Expected an unchanged save to leave the file as is, got:\n%s", again)
+	}
+}
+
+func TestRewritesMatchHostKeywordInAnyCase(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	configContent := "HOST upper\n    HostName upper.example.com\n\n" +
+		"host lower1   lower2\n    HostName lower.example.com\n\n" +
+		"\tHost\ttabbed\n\tHostName tabbed.example.com\n\n" +
+		"# Tags: db\nhost\t tagged\n    HostName tagged.example.com\n"
+	writeFile(t, configFile, configContent)
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	if len(hosts) != 5 {
+		t.Fatalf("Expected 5 hosts, got %d", len(hosts))
+	}
+
+	isMulti, names, err := IsPartOfMultiHostDeclaration("lower2", configFile)
+	if err != nil || !isMulti || len(names) != 2 {
+		t.Errorf("IsPartOfMultiHostDeclaration(lower2) = %v, %v, %v", isMulti, names, err)
+	}
+	if found, err := HostExistsInSpecificFile("tabbed", configFile); err != nil || !found {
+		t.Errorf("HostExistsInSpecificFile(tabbed) = %v, %v", found, err)
+	}
+
+	if err := UpdateSSHHostInFile("upper", SSHHost{Name: "upper", Hostname: "upper2.example.com"}, configFile); err != nil {
+		t.Errorf("UpdateSSHHostInFile(upper) error = %v", err)
+	}
+	if err := UpdateSSHHostInFile("tabbed", SSHHost{Name: "tabbed", Hostname: "tabbed2.example.com"}, configFile); err != nil {
+		t.Errorf("UpdateSSHHostInFile(tabbed) error = %v", err)
+	}
+	if err := UpdateMultiHostBlock([]string{"lower1", "lower2"}, []string{"lower1", "lower3"}, SSHHost{Hostname: "lower.example.com"}, configFile); err != nil {
+		t.Errorf("UpdateMultiHostBlock(lower1 lower2) error = %v", err)
+	}
+	if err := DeleteSSHHostFromFile("tagged", configFile); err != nil {
+		t.Errorf("DeleteSSHHostFromFile(tagged) error = %v", err)
+	}
+
+	hosts, err = ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	want := map[string]string{
+		"upper":  "upper2.example.com",
+		"tabbed": "tabbed2.example.com",
+		"lower1": "lower.example.com",
+		"lower3": "lower.example.com",
+	}
+	if len(hosts) != len(want) {
+		content, _ := os.ReadFile(configFile)
+		t.Fatalf("Expected %d hosts after the rewrites, got %d:\n%s", len(want), len(hosts), content)
+	}
+	for _, host := range hosts {
+		if want[host.Name] != host.Hostname {
+			t.Errorf("Expected %s to have HostName %s, got %q", host.Name, want[host.Name], host.Hostname)
+		}
+	}
+	content, _ := os.ReadFile(configFile)
+	if strings.Contains(string(content), "# Tags: db") {
+		t.Errorf("Expected the deleted host's tags comment to be removed, got:\n%s", content)
+	}
+}
+
+func TestHostNamesWithPatternCharacters(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	configContent := "Host web1\n    HostName web1.example.com\n\n" +
+		"# Tags: legacy\nHost web[1]\n    HostName bracket.example.com\n\n" +
+		"Host \"db [old]\" db-new # moved in 2024\n    HostName db.example.com\n\n" +
+		"Host * !web2\n    User admin\n\n" +
+		"Host web2\n    HostName web2.example.com\n"
+	writeFile(t, configFile, configContent)
+
+	names := func() []string {
+		t.Helper()
+		hosts, err := ParseSSHConfigFile(configFile)
+		if err != nil {
+			t.Fatalf("ParseSSHConfigFile() error = %v", err)
+		}
+		var names []string
+		for _, host := range hosts {
+			names = append(names, host.Name)
+		}
+		return names
+	}
+
+	want := []string{"web1", "web[1]", "db [old]", "db-new", "web2"}
+	if got := names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected hosts %v, got %v", want, got)
+	}
+	if found, err := QuickHostExistsInFile("db [old]", configFile); err != nil || !found {
+		t.Errorf("QuickHostExistsInFile(db [old]) = %v, %v", found, err)
+	}
+	if found, err := QuickHostExistsInFile("!web2", configFile); err != nil || found {
+		t.Errorf("QuickHostExistsInFile(!web2) = %v, %v, want a negated pattern not to be a host", found, err)
+	}
+
+	if err := DeleteSSHHostFromFile("web[1]", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile(web[1]) error = %v", err)
+	}
+	if err := DeleteSSHHostFromFile("db-new", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile(db-new) error = %v", err)
+	}
+
+	want = []string{"web1", "db [old]", "web2"}
+	if got := names(); !reflect.DeepEqual(got, want) {
+		content, _ := os.ReadFile(configFile)
+		t.Fatalf("Expected hosts %v after deleting, got %v:\n%s", want, got, content)
+	}
+	content, _ := os.ReadFile(configFile)
+	for _, line := range []string{"HostName web1.example.com", "Host \"db [old]\"", "Host * !web2", "HostName web2.example.com"} {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("Expected %q to survive the deletes, got:\n%s", line, content)
+		}
+	}
+	if strings.Contains(string(content), "legacy") || strings.Contains(string(content), "bracket.example.com") {
+		t.Errorf("Expected the web[1] block and its tags to be removed, got:\n%s", content)
+	}
+}
+
+func TestParseSSHOptionsFromCommandKeepsQuotedValues(t *testing.T) {
+	tests := []struct {
+		options string
+		want    string
+	}{
+		{"-o Compression=yes -o ServerAliveInterval=60", "Compression yes\nServerAliveInterval 60"},
+		{`-o "ProxyCommand=ssh -o StrictHostKeyChecking=no -W %h:%p bastion"`, "ProxyCommand ssh -o StrictHostKeyChecking=no -W %h:%p bastion"},
+		{`-oSetEnv='GREETING="hello world"' -o LogLevel=ERROR`, "SetEnv GREETING=\"hello world\"\nLogLevel ERROR"},
+		{"-o ProxyCommand=nc %h %p", "ProxyCommand nc %h %p"},
+		{"-o ForwardAgent yes", "ForwardAgent yes"},
+	}
+	for _, tt := range tests {
+		if got := ParseSSHOptionsFromCommand(tt.options); got != tt.want {
+			t.Errorf("ParseSSHOptionsFromCommand(%q) = %q, want %q", tt.options, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSSHOptions(t *testing.T) {
+	options := "Compression yes\r\n\n-o ServerAliveInterval=30 -o ConnectTimeout=10\n  LocalCommand echo -o=x  \n"
+	want := "Compression yes\nServerAliveInterval 30\nConnectTimeout 10\nLocalCommand echo -o=x"
+	if got := NormalizeSSHOptions(options); got != want {
+		t.Errorf("NormalizeSSHOptions() = %q, want %q", got, want)
+	}
+}
+
+func TestRewritesHandleBlankLinesAndHostEquals(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	configContent := "Host=web\n    HostName web.example.com\n\n    User deploy\n\n" +
+		"# backups run nightly\nHost = db backup\n    HostName db.example.com\n"
+	writeFile(t, configFile, configContent)
+
+	isMulti, names, err := IsPartOfMultiHostDeclaration("backup", configFile)
+	if err != nil || !isMulti || len(names) != 2 {
+		t.Errorf("IsPartOfMultiHostDeclaration(backup) = %v, %v, %v", isMulti, names, err)
+	}
+	if err := UpdateSSHHostInFile("web", SSHHost{Name: "web", Hostname: "web2.example.com", User: "admin"}, configFile); err != nil {
+		t.Fatalf("UpdateSSHHostInFile(web) error = %v", err)
+	}
+	content, _ := os.ReadFile(configFile)
+	if strings.Contains(string(content), "User deploy") {
+		t.Errorf("Expected the directive after the blank line to be replaced with the block, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), "# backups run nightly\nHost = db backup") {
+		t.Errorf("Expected the comment above the next block to stay with it, got:\n%s", content)
+	}
+
+	if err := DeleteSSHHostFromFile("db", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile(db) error = %v", err)
+	}
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	want := map[string]string{"web": "admin", "backup": ""}
+	if len(hosts) != len(want) {
+		content, _ := os.ReadFile(configFile)
+		t.Fatalf("Expected %d hosts after the rewrites, got %d:\n%s", len(want), len(hosts), content)
+	}
+	for _, host := range hosts {
+		if user, ok := want[host.Name]; !ok || host.User != user {
+			t.Errorf("Unexpected host %s with user %q", host.Name, host.User)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// SymlinkTarget returns the file a config path points to when the path is a
+// symlink, as with a config kept in a dotfiles repository
+func SymlinkTarget(path string) (string, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
+
+// resolveConfigPath returns the file to write or back up in place of path. Writes
+// go to the target of a symlink so that the link itself is never replaced.
+func resolveConfigPath(path string) string {
+	if target, ok := SymlinkTarget(path); ok {
+		return target
+	}
+	return path
+}
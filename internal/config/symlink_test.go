@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupSymlinkedConfig creates a config in a dotfiles directory and a symlink to it
+// the way a dotfiles manager does, returning the link and the target
+func setupSymlinkedConfig(t *testing.T) (link, target string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "xdg"))
+
+	target = filepath.Join(tempDir, "dotfiles", "ssh_config")
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, target, "Host web\n    HostName 10.0.0.1\n\nHost db\n    HostName 10.0.0.2\n")
+	link = filepath.Join(tempDir, ".ssh", "config")
+	if err := os.MkdirAll(filepath.Dir(link), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	return link, target
+}
+
+// assertStillSymlink fails unless link is still a symlink to target
+func assertStillSymlink(t *testing.T, link, target string) {
+	t.Helper()
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("Expected %s to still be a symlink", link)
+	}
+	if got, ok := SymlinkTarget(link); !ok || got != target {
+		t.Errorf("Expected the link to point to %s, got %s", target, got)
+	}
+}
+
+func TestUpdateKeepsSymlink(t *testing.T) {
+	link, target := setupSymlinkedConfig(t)
+
+	if err := UpdateSSHHostInFile("web", SSHHost{Name: "web", Hostname: "10.0.0.9"}, link); err != nil {
+		t.Fatal(err)
+	}
+
+	assertStillSymlink(t, link, target)
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "10.0.0.9") {
+		t.Errorf("Expected the target to be updated, got:\n%s", data)
+	}
+
+	// The backup is of the target, named after it
+	backupDir, err := GetSSHMBackupDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(filepath.Join(backupDir, "ssh_config.backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(backup), "10.0.0.1") {
+		t.Errorf("Expected the backup to hold the previous content, got:\n%s", backup)
+	}
+	if info, err := os.Lstat(filepath.Join(backupDir, "ssh_config.backup")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("Expected the backup to be a regular file, got %v", err)
+	}
+}
+
+func TestDeleteKeepsSymlink(t *testing.T) {
+	link, target := setupSymlinkedConfig(t)
+
+	if err := DeleteSSHHostFromFile("db", link); err != nil {
+		t.Fatal(err)
+	}
+
+	assertStillSymlink(t, link, target)
+	hosts, err := ParseSSHConfigFile(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "web" {
+		t.Errorf("Expected only web to remain, got %+v", hosts)
+	}
+}
+
+func TestSymlinkTarget(t *testing.T) {
+	link, target := setupSymlinkedConfig(t)
+
+	if _, ok := SymlinkTarget(target); ok {
+		t.Error("Expected a regular file not to be reported as a symlink")
+	}
+	if _, ok := SymlinkTarget(filepath.Join(t.TempDir(), "missing")); ok {
+		t.Error("Expected a missing file not to be reported as a symlink")
+	}
+	if got := resolveConfigPath(link); got != target {
+		t.Errorf("Expected %s, got %s", target, got)
+	}
+}
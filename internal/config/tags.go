@@ -0,0 +1,187 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+)
+
+// GetHostsByTag returns the hosts of the default config that have tag, ignoring
+// case. A block with several names is returned once, under its first name.
+func GetHostsByTag(tag string) ([]SSHHost, error) {
+	return GetHostsByTags([]string{tag}, false)
+}
+
+// GetHostsByTags returns the hosts of the default config that have any of tags,
+// or all of them when matchAll is set. A block with several names is returned
+// once, under its first name.
+func GetHostsByTags(tags []string, matchAll bool) ([]SSHHost, error) {
+	hosts, err := ParseSSHConfig()
+	if err != nil {
+		return nil, err
+	}
+	return UniqueHostBlocks(FilterHostsByTags(hosts, tags, matchAll)), nil
+}
+
+// GetHostsByTagsFromFile is GetHostsByTags for a specific config file
+func GetHostsByTagsFromFile(tags []string, matchAll bool, configPath string) ([]SSHHost, error) {
+	hosts, err := ParseSSHConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return UniqueHostBlocks(FilterHostsByTags(hosts, tags, matchAll)), nil
+}
+
+// FilterHostsByTags returns the hosts that have any of tags, or all of them when
+// matchAll is set, keeping their order. Tags are compared ignoring case and blank
+// ones are ignored; without any tag no host matches. Unlike GetHostsByTags it
+// keeps every name of a block, for callers that show or ping each name.
+func FilterHostsByTags(hosts []SSHHost, tags []string, matchAll bool) []SSHHost {
+	var wanted []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			wanted = append(wanted, tag)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	var matched []SSHHost
+	for _, host := range hosts {
+		var ok bool
+		if matchAll {
+			ok = !slices.ContainsFunc(wanted, func(tag string) bool { return !host.HasTag(tag) })
+		} else {
+			ok = slices.ContainsFunc(wanted, host.HasTag)
+		}
+		if ok {
+			matched = append(matched, host)
+		}
+	}
+	return matched
+}
+
+// UniqueHostBlocks drops the aliases of a block, keeping the first name parsed
+// for it. Hosts that were not read from a file are all kept.
+func UniqueHostBlocks(hosts []SSHHost) []SSHHost {
+	type block struct {
+		file string
+		line int
+	}
+	seen := make(map[block]bool)
+	unique := make([]SSHHost, 0, len(hosts))
+	for _, host := range hosts {
+		if host.Line > 0 {
+			key := block{host.SourceFile, host.Line}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		unique = append(unique, host)
+	}
+	return unique
+}
+
+// MigrateTagComments rewrites the "# Tags:" comments of the hosts in a config file as
+// native Tag directives and returns the names of the hosts that changed. Tags that
+// cannot be written as a single Tag value stay in the comment.
+func MigrateTagComments(configPath string) ([]string, error) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	newContent, migrated := migrateTagComments(string(content))
+	if len(migrated) == 0 {
+		return nil, nil
+	}
+
+	if err := backupConfig(configPath); err != nil {
+		return nil, err
+	}
+	if err := writeConfigFile(configPath, AuditTag, strings.Join(migrated, ", "), content, newContent); err != nil {
+		return nil, err
+	}
+	return migrated, nil
+}
+
+// migrateTagComments moves the tags from the "# Tags:" comment above each Host line
+// into Tag directives right below it, skipping tags the block already has
+func migrateTagComments(content string) (string, []string) {
+	lines, format := configtext.Split(content)
+	var migrated []string
+	var result []string
+
+	for i := 0; i < len(lines); i++ {
+		end := configtext.MetadataEnd(lines, i)
+		if end == i || end >= len(lines) || !configtext.IsHostLine(lines[end]) {
+			result = append(result, lines[i])
+			continue
+		}
+
+		// Tags the block already declares natively
+		blockEnd := configtext.BlockEnd(lines, end)
+		var existing []string
+		for _, line := range lines[end+1 : blockEnd] {
+			if line = strings.TrimSpace(line); configtext.IsTagDirective(line) {
+				_, value := configtext.SplitDirective(line)
+				existing = configtext.MergeTags(existing, strings.Fields(value)...)
+			}
+		}
+
+		var native []string
+		hasTagsComment, keptAny := false, false
+		for _, line := range lines[i:end] {
+			trimmed := strings.TrimSpace(line)
+			if !strings.HasPrefix(trimmed, configtext.TagsPrefix) {
+				result = append(result, line)
+				continue
+			}
+			hasTagsComment = true
+
+			var kept []string
+			for _, tag := range strings.Split(strings.TrimPrefix(trimmed, configtext.TagsPrefix), ",") {
+				tag = strings.TrimSpace(tag)
+				switch {
+				case tag == "":
+				case !configtext.IsNativeTag(tag):
+					kept = configtext.MergeTags(kept, tag)
+				case !slices.Contains(existing, tag):
+					native = configtext.MergeTags(native, tag)
+				}
+			}
+			if len(kept) > 0 {
+				result = append(result, configtext.TagsPrefix+" "+strings.Join(kept, ", "))
+				keptAny = true
+			}
+		}
+
+		result = append(result, lines[end])
+		indent := "    "
+		if end+1 < blockEnd {
+			first := lines[end+1]
+			indent = first[:len(first)-len(strings.TrimLeft(first, " \t"))]
+		}
+		for _, tag := range native {
+			result = append(result, indent+"Tag "+tag)
+		}
+
+		// A comment left with the same tags is no change
+		if hasTagsComment && (len(native) > 0 || !keptAny) {
+			names, _ := configtext.HostNames(lines[end])
+			migrated = append(migrated, strings.Join(names, " "))
+		}
+		i = end
+	}
+
+	if len(migrated) == 0 {
+		return content, nil
+	}
+	return format.Join(result), migrated
+}
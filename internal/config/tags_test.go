@@ -0,0 +1,418 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setNativeTags makes host writes use Tag directives for the rest of the test
+func setNativeTags(t *testing.T, native bool) {
+	t.Helper()
+	previous := nativeTagsCheck
+	SetNativeTagsCheck(func() bool { return native })
+	t.Cleanup(func() { nativeTagsCheck = previous })
+}
+
+func TestParseMergesCommentAndNativeTags(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, `# Tags: prod, web, prod
+Host web
+    HostName web.example.com
+    Tag staging
+    tag web
+    Tag eu-west
+
+Host db
+    HostName db.example.com
+    Tag data
+`)
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("Expected 2 hosts, got %d", len(hosts))
+	}
+
+	// The comment comes first, then native tags not already listed
+	if want := []string{"prod", "web", "staging", "eu-west"}; !slices.Equal(hosts[0].Tags, want) {
+		t.Errorf("Tags = %v, want %v", hosts[0].Tags, want)
+	}
+	if strings.Contains(hosts[0].Options, "Tag") {
+		t.Errorf("Expected Tag directives not to end up in Options, got %q", hosts[0].Options)
+	}
+	if want := []string{"data"}; !slices.Equal(hosts[1].Tags, want) {
+		t.Errorf("Tags = %v, want %v", hosts[1].Tags, want)
+	}
+}
+
+func TestMatchBlockEndsHost(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, `Host web
+    HostName web.example.com
+Match tagged prod
+    ForwardAgent no
+`)
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Options != "" {
+		t.Fatalf("Expected the Match block to stay out of the host, got %+v", hosts)
+	}
+
+	// Rewriting the host keeps the Match block that follows it
+	if err := UpdateSSHHostInFile("web", SSHHost{Name: "web", Hostname: "web2.example.com"}, configFile); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(configFile)
+	if !strings.Contains(string(content), "Match tagged prod\n    ForwardAgent no\n") {
+		t.Errorf("Expected the Match block to be kept, got:\n%s", content)
+	}
+}
+
+func TestWriteTagsByClientSupport(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	host := SSHHost{Name: "web", Hostname: "web.example.com", Tags: []string{"prod", "web"}}
+
+	setNativeTags(t, true)
+	configFile := filepath.Join(tempDir, "native")
+	if err := AddSSHHostToFile(host, configFile); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(configFile)
+	if strings.Contains(string(content), "# Tags:") || !strings.Contains(string(content), "    Tag prod\n    Tag web\n") {
+		t.Errorf("Expected native Tag directives, got:\n%s", content)
+	}
+
+	// A tag ssh would split into two words falls back to the comment
+	host.Tags = []string{"prod", "on call"}
+	configFile = filepath.Join(tempDir, "spaces")
+	if err := AddSSHHostToFile(host, configFile); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(configFile)
+	if !strings.Contains(string(content), "# Tags: prod, on call\n") || strings.Contains(string(content), "Tag prod") {
+		t.Errorf("Expected a tags comment, got:\n%s", content)
+	}
+
+	setNativeTags(t, false)
+	host.Tags = []string{"prod"}
+	configFile = filepath.Join(tempDir, "comment")
+	if err := AddSSHHostToFile(host, configFile); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(configFile)
+	if !strings.Contains(string(content), "# Tags: prod\n") {
+		t.Errorf("Expected a tags comment for an older client, got:\n%s", content)
+	}
+}
+
+func TestUpdateUnifiesConflictingTags(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	setNativeTags(t, true)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, `# Tags: prod
+Host web
+    HostName web.example.com
+    Tag staging
+`)
+
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateSSHHostInFile("web", hosts[0], configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(configFile)
+	want := "Host web\n    HostName web.example.com\n    Tag prod\n    Tag staging\n"
+	if string(content) != want {
+		t.Errorf("Expected both tags written once as directives, got:\n%s", content)
+	}
+}
+
+func TestTagsCommentMovedByFormatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "blank line before Host",
+			content: "# Tags: prod, web\n\nHost web\n    HostName web.example.com\n\nHost db\n    HostName db.example.com\n",
+		},
+		{
+			name:    "below Host line",
+			content: "Host web\n    # Tags: prod, web\n    HostName web.example.com\n\nHost db\n    HostName db.example.com\n",
+		},
+		{
+			name:    "dedented below Host line",
+			content: "Host web\n# Tags: prod, web\n    HostName web.example.com\n\nHost db\n    HostName db.example.com\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupAuditTest(t)
+			configFile := filepath.Join(tempDir, "config")
+			writeFile(t, configFile, tt.content)
+
+			hosts, err := ParseSSHConfigFile(configFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hosts) != 2 || !slices.Equal(hosts[0].Tags, []string{"prod", "web"}) || len(hosts[1].Tags) != 0 {
+				t.Fatalf("Expected the tags on web only, got %+v", hosts)
+			}
+			if orphaned := orphanedTagComments(tt.content); len(orphaned) != 0 {
+				t.Errorf("Expected no orphaned tags comment, got lines %v", orphaned)
+			}
+
+			updated := hosts[0]
+			updated.Tags = []string{"prod", "web", "eu"}
+			if err := UpdateSSHHostInFile("web", updated, configFile); err != nil {
+				t.Fatal(err)
+			}
+			content, _ := os.ReadFile(configFile)
+			if strings.Count(string(content), "# Tags:") != 1 || !strings.Contains(string(content), "# Tags: prod, web, eu\nHost web\n") {
+				t.Errorf("Expected a single updated tags comment above Host web, got:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestMigrateTagComments(t *testing.T) {
+	tempDir := setupAuditTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, `# Description: front end
+# Tags: prod, web, on call
+# Expires: 2030-01-01
+Host web web-alias
+	HostName web.example.com
+	Tag web
+
+Host db
+    HostName db.example.com
+
+# Tags: data
+Host cache
+`)
+
+	migrated, err := MigrateTagComments(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"web web-alias", "cache"}; !slices.Equal(migrated, want) {
+		t.Errorf("MigrateTagComments() = %v, want %v", migrated, want)
+	}
+
+	content, _ := os.ReadFile(configFile)
+	want := `# Description: front end
+# Tags: on call
+# Expires: 2030-01-01
+Host web web-alias
+	Tag prod
+	HostName web.example.com
+	Tag web
+
+Host db
+    HostName db.example.com
+
+Host cache
+    Tag data
+`
+	if string(content) != want {
+		t.Errorf("Unexpected migrated config:\n%s", content)
+	}
+
+	// The tags read back the same
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"on call", "prod", "web"}; !slices.Equal(hosts[0].Tags, want) {
+		t.Errorf("Tags after migration = %v, want %v", hosts[0].Tags, want)
+	}
+
+	// Running it again changes nothing
+	if migrated, err := MigrateTagComments(configFile); err != nil || len(migrated) != 0 {
+		t.Errorf("Expected a second migration to do nothing, got %v, %v", migrated, err)
+	}
+}
+
+const taggedConfig = `# Tags: prod, web
+Host web web-alias
+    HostName web.example.com
+
+# Tags: Prod, db
+Host db
+    HostName db.example.com
+
+# Tags: staging, web
+Host web-staging
+    HostName staging.example.com
+
+Host untagged
+    HostName 10.0.0.1
+`
+
+func TestFilterHostsByTags(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	writeFile(t, configFile, taggedConfig)
+	hosts, err := ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := func(hosts []SSHHost) []string {
+		var names []string
+		for _, host := range hosts {
+			names = append(names, host.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		matchAll bool
+		want     []string
+	}{
+		{"one tag, any case", []string{"PROD"}, false, []string{"web", "web-alias", "db"}},
+		{"any of two", []string{"db", "staging"}, false, []string{"db", "web-staging"}},
+		{"all of two", []string{"prod", "web"}, true, []string{"web", "web-alias"}},
+		{"all of two, none has both", []string{"db", "staging"}, true, nil},
+		{"all of one is any of one", []string{"web"}, true, []string{"web", "web-alias", "web-staging"}},
+		{"blank tags are ignored", []string{" ", "db"}, true, []string{"db"}},
+		{"no tags match nothing", nil, false, nil},
+		{"unknown tag", []string{"nope"}, false, nil},
+	}
+	for _, tt := range tests {
+		if got := names(FilterHostsByTags(hosts, tt.tags, tt.matchAll)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: FilterHostsByTags(%q, %v) = %v, want %v", tt.name, tt.tags, tt.matchAll, got, tt.want)
+		}
+	}
+}
+
+func TestGetHostsByTagsDropsAliases(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	writeFile(t, configFile, taggedConfig)
+
+	hosts, err := GetHostsByTagsFromFile([]string{"web"}, false, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+	if want := []string{"web", "web-staging"}; !slices.Equal(names, want) {
+		t.Errorf("GetHostsByTagsFromFile() = %v, want %v, each block once", names, want)
+	}
+
+	// Hosts that were not read from a file have no block to share
+	built := []SSHHost{{Name: "a", Tags: []string{"x"}}, {Name: "b", Tags: []string{"x"}}}
+	if got := UniqueHostBlocks(built); len(got) != 2 {
+		t.Errorf("UniqueHostBlocks() = %v, want both hosts", got)
+	}
+}
+
+func TestGetHostsByTagReadsDefaultConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(home, ".ssh", "config"), taggedConfig)
+
+	hosts, err := GetHostsByTag("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "db" {
+		t.Errorf("GetHostsByTag(db) = %v", hosts)
+	}
+
+	tests := []struct {
+		tags     []string
+		matchAll bool
+		want     []string
+	}{
+		{[]string{"prod", "WEB"}, true, []string{"web"}},
+		{[]string{"prod", "WEB"}, false, []string{"web", "db", "web-staging"}},
+		{[]string{"db", "staging"}, true, nil},
+	}
+	for _, tt := range tests {
+		hosts, err := GetHostsByTags(tt.tags, tt.matchAll)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, host := range hosts {
+			names = append(names, host.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("GetHostsByTags(%q, %v) = %v, want %v", tt.tags, tt.matchAll, names, tt.want)
+		}
+	}
+}
+
+// writeLargeTaggedConfig writes a config of n hosts spread over eight tags
+func writeLargeTaggedConfig(b *testing.B, n int) string {
+	b.Helper()
+	var config strings.Builder
+	for i := range n {
+		fmt.Fprintf(&config, "# Tags: team-%d, zone-%d\nHost node-%04d node-%04d-alias\n    HostName 10.0.%d.%d\n    User deploy\n\n",
+			i%8, i%3, i, i, i/256, i%256)
+	}
+	configFile := filepath.Join(b.TempDir(), "config")
+	if err := os.WriteFile(configFile, []byte(config.String()), 0600); err != nil {
+		b.Fatal(err)
+	}
+	return configFile
+}
+
+// BenchmarkParseSSHConfigFile is the baseline for BenchmarkGetHostsByTags: looking
+// hosts up by tag should cost one parse and nothing more, so it gains from any
+// caching of parsed configs without changes
+func BenchmarkParseSSHConfigFile(b *testing.B) {
+	configFile := writeLargeTaggedConfig(b, 2000)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := ParseSSHConfigFile(configFile); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetHostsByTags(b *testing.B) {
+	configFile := writeLargeTaggedConfig(b, 2000)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := GetHostsByTagsFromFile([]string{"team-3", "zone-1"}, true, configFile); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFilterHostsByTags filters hosts that are already parsed, as the list
+// view does when pinging a tag
+func BenchmarkFilterHostsByTags(b *testing.B) {
+	hosts, err := ParseSSHConfigFile(writeLargeTaggedConfig(b, 2000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for range b.N {
+		FilterHostsByTags(hosts, []string{"TEAM-3", "zone-1"}, false)
+	}
+}
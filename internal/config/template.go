@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// newFileTemplateName is the file in the sshc config directory that config files
+// created by sshc start from
+const newFileTemplateName = "new_file_template.conf"
+
+// GetNewFileTemplatePath returns the path of the template for new config files
+func GetNewFileTemplatePath() (string, error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, newFileTemplateName), nil
+}
+
+// RenderNewFileTemplate fills in the {{filename}} and {{date}} placeholders of a
+// template for the config file at path, and makes sure a non-empty result ends
+// with a newline so a host appended later starts on a line of its own
+func RenderNewFileTemplate(template, path string, now time.Time) string {
+	rendered := strings.NewReplacer(
+		"{{filename}}", filepath.Base(path),
+		"{{date}}", now.Format("2006-01-02"),
+	).Replace(template)
+	if rendered != "" && !strings.HasSuffix(rendered, "\n") {
+		rendered += "\n"
+	}
+	return rendered
+}
+
+// newConfigFileContent returns what a new config file at path starts with: the
+// rendered template, or nothing when there is no template
+func newConfigFileContent(path string) ([]byte, error) {
+	templatePath, err := GetNewFileTemplatePath()
+	if err != nil {
+		return nil, nil
+	}
+	template, err := os.ReadFile(templatePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new file template: %w", err)
+	}
+	return []byte(RenderNewFileTemplate(string(template), path, time.Now())), nil
+}
+
+// writeNewConfigFile fills a config file sshc just created from the template
+func writeNewConfigFile(file *os.File) error {
+	content, err := newConfigFileContent(file.Name())
+	if err != nil {
+		return err
+	}
+	if len(content) == 0 {
+		return nil
+	}
+	if _, err := file.Write(content); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRenderNewFileTemplate(t *testing.T) {
+	now := time.Date(2026, 3, 9, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"placeholders", "# {{filename}}, created {{date}}\nHost *.newco\n", "# newco.conf, created 2026-03-09\nHost *.newco\n"},
+		{"repeated placeholder", "# {{filename}} {{filename}}\n", "# newco.conf newco.conf\n"},
+		{"unknown placeholder kept", "# {{owner}}\n", "# {{owner}}\n"},
+		{"trailing newline added", "# Include guard: keep last", "# Include guard: keep last\n"},
+		{"blank lines kept", "# header\n\n", "# header\n\n"},
+		{"empty template", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderNewFileTemplate(tt.template, "/home/me/.ssh/clients/newco.conf", now); got != tt.want {
+				t.Errorf("RenderNewFileTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeNewFileTemplate puts a new file template in a temporary sshc config directory
+func writeNewFileTemplate(t *testing.T, content string) {
+	t.Helper()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("APPDATA", configHome)
+	path, err := GetNewFileTemplatePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, content)
+}
+
+func TestCreateIncludedConfigFileUsesTemplate(t *testing.T) {
+	writeNewFileTemplate(t, "# Client: {{filename}}\nHost *.newco\n    User deploy")
+	path := filepath.Join(t.TempDir(), "clients", "newco.conf")
+
+	if err := CreateIncludedConfigFile(path); err != nil {
+		t.Fatalf("CreateIncludedConfigFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Client: newco.conf\nHost *.newco\n    User deploy\n"; string(data) != want {
+		t.Errorf("Expected the rendered template, got %q", data)
+	}
+}
+
+func TestCreateIncludedConfigFileWithoutTemplate(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("APPDATA", configHome)
+	path := filepath.Join(t.TempDir(), "new.conf")
+
+	if err := CreateIncludedConfigFile(path); err != nil {
+		t.Fatalf("CreateIncludedConfigFile() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("Expected an empty file, got %v, %v", info, err)
+	}
+}
+
+func TestMainConfigCreatedFromTemplate(t *testing.T) {
+	writeNewFileTemplate(t, "# Managed by sshc\n")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	hosts, err := ParseSSHConfig()
+	if err != nil || len(hosts) != 0 {
+		t.Fatalf("ParseSSHConfig() = %v, %v", hosts, err)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".ssh", "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "# Managed by sshc\n" {
+		t.Errorf("Expected the main config to start from the template, got %q", data)
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+// URLsFor returns the URL templates kept for a host
+func (c *AppConfig) URLsFor(hostName string) []string {
+	if c == nil {
+		return nil
+	}
+	return c.HostURLs[hostName]
+}
+
+// RenameHostURLs moves the URLs of a renamed host to its new name
+func (c *AppConfig) RenameHostURLs(oldName, newName string) bool {
+	urls, ok := c.HostURLs[oldName]
+	if !ok || oldName == newName {
+		return false
+	}
+	delete(c.HostURLs, oldName)
+	c.HostURLs[newName] = urls
+	return true
+}
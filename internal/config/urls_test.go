@@ -0,0 +1,23 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHostURLs(t *testing.T) {
+	c := &AppConfig{HostURLs: map[string][]string{"nas": {"https://{hostname}:5001"}}}
+	if got := c.URLsFor("nas"); !slices.Equal(got, []string{"https://{hostname}:5001"}) {
+		t.Errorf("URLsFor(nas) = %v", got)
+	}
+	if got := (*AppConfig)(nil).URLsFor("nas"); got != nil {
+		t.Errorf("URLsFor on a nil config = %v", got)
+	}
+
+	if !c.RenameHostURLs("nas", "nas-1") || c.URLsFor("nas") != nil || len(c.URLsFor("nas-1")) != 1 {
+		t.Errorf("URLs after the rename = %v", c.HostURLs)
+	}
+	if c.RenameHostURLs("web", "web-1") {
+		t.Error("RenameHostURLs() reported a change for a host without URLs")
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import "time"
+
+// DefaultConnectRateWindow is the window connects are counted in when a limit
+// sets MaxConnects without a window
+const DefaultConnectRateWindow = 10 * time.Minute
+
+// UsageLimit describes how a shared host should be used. MaxUsers is only a note
+// shown before connecting; MaxConnects and Window make sshc ask for confirmation
+// when connecting again would make more than MaxConnects connections to the host
+// within Window.
+type UsageLimit struct {
+	MaxUsers    int `json:"max_users,omitempty"`    // Concurrent users the host is meant for
+	MaxConnects int `json:"max_connects,omitempty"` // 0 leaves the rate unchecked
+	Window      int `json:"window,omitempty"`       // Minutes, 0 uses DefaultConnectRateWindow
+}
+
+// WindowDuration returns the window connects are counted in
+func (l UsageLimit) WindowDuration() time.Duration {
+	if l.Window <= 0 {
+		return DefaultConnectRateWindow
+	}
+	return time.Duration(l.Window) * time.Minute
+}
+
+// UsageLimitFor returns the usage limit of a host: its own settings, with the
+// global ConnectRate filling in those it leaves unset
+func (c *AppConfig) UsageLimitFor(hostName string) UsageLimit {
+	if c == nil {
+		return UsageLimit{}
+	}
+	limit := c.UsageLimits[hostName]
+	if limit.MaxConnects == 0 {
+		limit.MaxConnects = c.ConnectRate.MaxConnects
+	}
+	if limit.Window == 0 {
+		limit.Window = c.ConnectRate.Window
+	}
+	return limit
+}
+
+// RenameUsageLimit moves the usage limit of a renamed host to its new name
+func (c *AppConfig) RenameUsageLimit(oldName, newName string) bool {
+	limit, ok := c.UsageLimits[oldName]
+	if !ok || oldName == newName {
+		return false
+	}
+	delete(c.UsageLimits, oldName)
+	c.UsageLimits[newName] = limit
+	return true
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageLimitForFallsBackToConnectRate(t *testing.T) {
+	c := &AppConfig{
+		ConnectRate: UsageLimit{MaxConnects: 10},
+		UsageLimits: map[string]UsageLimit{
+			"lab-scope": {MaxUsers: 2, MaxConnects: 3, Window: 60},
+			"lab-psu":   {MaxUsers: 4},
+		},
+	}
+
+	if got := c.UsageLimitFor("lab-scope"); got != (UsageLimit{MaxUsers: 2, MaxConnects: 3, Window: 60}) || got.WindowDuration() != time.Hour {
+		t.Errorf("UsageLimitFor(lab-scope) = %+v", got)
+	}
+	got := c.UsageLimitFor("lab-psu")
+	if got != (UsageLimit{MaxUsers: 4, MaxConnects: 10}) || got.WindowDuration() != DefaultConnectRateWindow {
+		t.Errorf("UsageLimitFor(lab-psu) = %+v, want its max users with the global rate", got)
+	}
+	if got := c.UsageLimitFor("web"); got != (UsageLimit{MaxConnects: 10}) {
+		t.Errorf("UsageLimitFor(web) = %+v, want the global rate", got)
+	}
+
+	var unset *AppConfig
+	if got := unset.UsageLimitFor("web"); got.MaxConnects != 0 {
+		t.Errorf("UsageLimitFor() without a config = %+v", got)
+	}
+}
+
+func TestRenameUsageLimit(t *testing.T) {
+	c := &AppConfig{UsageLimits: map[string]UsageLimit{"lab": {MaxUsers: 2}}}
+	if !c.RenameUsageLimit("lab", "lab-1") || c.UsageLimits["lab-1"].MaxUsers != 2 {
+		t.Fatalf("usage limits after rename = %v", c.UsageLimits)
+	}
+	if _, ok := c.UsageLimits["lab"]; ok {
+		t.Error("the old name kept its usage limit")
+	}
+	if c.RenameUsageLimit("web", "web-1") {
+		t.Error("RenameUsageLimit() reported a change for a host without a limit")
+	}
+}
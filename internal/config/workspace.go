@@ -0,0 +1,55 @@
+package config
+
+import "strings"
+
+// Columns of the host list, as named in HiddenColumns and Workspace.Columns
+const (
+	ColumnName        = "name"
+	ColumnHostname    = "hostname"
+	ColumnTags        = "tags"
+	ColumnLastLogin   = "last-login"
+	ColumnDescription = "description"
+)
+
+// ListColumns are the columns of the host list in the order they are shown
+var ListColumns = []string{ColumnName, ColumnHostname, ColumnTags, ColumnLastLogin, ColumnDescription}
+
+// Workspace is a named view of the host list: a search query with the sort mode,
+// block grouping and columns it is looked at with
+type Workspace struct {
+	Name           string   `json:"name"`
+	Query          string   `json:"query,omitempty"`
+	SortMode       string   `json:"sort_mode"` // "name" or "recent"
+	CollapseBlocks bool     `json:"collapse_blocks,omitempty"`
+	Columns        []string `json:"columns,omitempty"` // Visible columns, all of ListColumns when empty
+}
+
+// FindWorkspace returns the workspace with the given name, ignoring case, or nil
+func (c *AppConfig) FindWorkspace(name string) *Workspace {
+	for i := range c.Workspaces {
+		if strings.EqualFold(c.Workspaces[i].Name, name) {
+			return &c.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// SetWorkspace adds a workspace, or replaces the one with the same name in place
+func (c *AppConfig) SetWorkspace(workspace Workspace) {
+	if existing := c.FindWorkspace(workspace.Name); existing != nil {
+		*existing = workspace
+		return
+	}
+	c.Workspaces = append(c.Workspaces, workspace)
+}
+
+// DeleteWorkspace removes the workspace with the given name and reports whether it existed
+func (c *AppConfig) DeleteWorkspace(name string) bool {
+	for i := range c.Workspaces {
+		if strings.EqualFold(c.Workspaces[i].Name, name) {
+			c.Workspaces = append(c.Workspaces[:i], c.Workspaces[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
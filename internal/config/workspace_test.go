@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSetWorkspaceReplacesByName(t *testing.T) {
+	c := &AppConfig{}
+	c.SetWorkspace(Workspace{Name: "oncall", Query: "tag:prod", SortMode: "recent"})
+	c.SetWorkspace(Workspace{Name: "acme", Query: "tag:acme", SortMode: "name"})
+	c.SetWorkspace(Workspace{Name: "OnCall", Query: "tag:prod tag:db", SortMode: "recent"})
+
+	if len(c.Workspaces) != 2 {
+		t.Fatalf("Expected 2 workspaces, got %d", len(c.Workspaces))
+	}
+	if c.Workspaces[0].Name != "OnCall" || c.Workspaces[0].Query != "tag:prod tag:db" {
+		t.Errorf("Expected the first workspace to be replaced in place, got %+v", c.Workspaces[0])
+	}
+	if found := c.FindWorkspace("ACME"); found == nil || found.Query != "tag:acme" {
+		t.Errorf("Expected to find acme ignoring case, got %+v", found)
+	}
+}
+
+func TestDeleteWorkspace(t *testing.T) {
+	c := &AppConfig{Workspaces: []Workspace{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+	if !c.DeleteWorkspace("B") {
+		t.Fatal("Expected b to be deleted")
+	}
+	if c.DeleteWorkspace("b") {
+		t.Error("Expected a second delete to find nothing")
+	}
+	if len(c.Workspaces) != 2 || c.Workspaces[0].Name != "a" || c.Workspaces[1].Name != "c" {
+		t.Errorf("Expected a and c to remain in order, got %+v", c.Workspaces)
+	}
+}
+
+func TestWorkspacesRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+	c := GetDefaultAppConfig()
+	c.SetWorkspace(Workspace{Name: "oncall", Query: "tag:prod", SortMode: "recent", CollapseBlocks: true, Columns: []string{ColumnName, ColumnHostname, ColumnTags}})
+	if err := SaveAppConfig(&c); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadAppConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(loaded.Workspaces)
+	want, _ := json.Marshal(c.Workspaces)
+	if string(got) != string(want) {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
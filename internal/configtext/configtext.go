@@ -0,0 +1,63 @@
+// Package configtext reads and writes the text of ssh config files line by line.
+// It is shared by the public pkg/sshconfig parser and writer and by sshc's own
+// config code, so both read Host lines, metadata comments and line endings alike.
+package configtext
+
+import "strings"
+
+// utf8BOM is the byte order mark some Windows editors put at the start of a file
+const utf8BOM = "\ufeff"
+
+// Format records how a config file encodes its text so rewrites can keep it
+type Format struct {
+	BOM  bool
+	CRLF bool
+}
+
+// Split splits config content into lines without their line endings or BOM, and
+// returns the format needed to join them back into the same style. A file with
+// mixed line endings is rewritten with the one used most.
+func Split(content string) ([]string, Format) {
+	var format Format
+	if strings.HasPrefix(content, utf8BOM) {
+		format.BOM = true
+		content = content[len(utf8BOM):]
+	}
+
+	crlfCount := strings.Count(content, "\r\n")
+	format.CRLF = crlfCount > strings.Count(content, "\n")-crlfCount
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, format
+}
+
+// DetectFormat returns the format of config content
+func DetectFormat(content string) Format {
+	_, format := Split(content)
+	return format
+}
+
+// Newline returns the line ending of the format
+func (f Format) Newline() string {
+	if f.CRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// Join is the inverse of Split
+func (f Format) Join(lines []string) string {
+	content := strings.Join(lines, f.Newline())
+	if f.BOM {
+		content = utf8BOM + content
+	}
+	return content
+}
+
+// TrimLine trims a line read from a config file, including a leading BOM
+func TrimLine(line string) string {
+	return strings.TrimSpace(strings.TrimPrefix(line, utf8BOM))
+}
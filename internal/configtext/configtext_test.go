@@ -0,0 +1,163 @@
+package configtext
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		bom     bool
+		crlf    bool
+	}{
+		{"lf", "Host a\n    HostName a\n", false, false},
+		{"crlf", "Host a\r\n    HostName a\r\n", false, true},
+		{"bom crlf", utf8BOM + "Host a\r\n    HostName a\r\n", true, true},
+		{"bom lf", utf8BOM + "Host a\n", true, false},
+		{"empty", "", false, false},
+	}
+
+	for _, tt := range tests {
+		lines, format := Split(tt.content)
+		if format.BOM != tt.bom || format.CRLF != tt.crlf {
+			t.Errorf("%s: format = %+v, want bom=%v crlf=%v", tt.name, format, tt.bom, tt.crlf)
+		}
+		for _, line := range lines {
+			if strings.ContainsAny(line, "\r") || strings.HasPrefix(line, utf8BOM) {
+				t.Errorf("%s: line %q still carries a line ending or BOM", tt.name, line)
+			}
+		}
+		if got := format.Join(lines); got != tt.content {
+			t.Errorf("%s: Join(Split()) = %q, want %q", tt.name, got, tt.content)
+		}
+	}
+
+	// Mixed endings are normalized to the dominant one
+	_, format := Split("a\r\nb\r\nc\n")
+	if !format.CRLF {
+		t.Error("Expected CRLF to dominate")
+	}
+}
+
+func TestHostNames(t *testing.T) {
+	tests := []struct {
+		line  string
+		names []string
+	}{
+		{"Host web", []string{"web"}},
+		{"host\tweb db", []string{"web", "db"}},
+		{`Host "my server" other # comment`, []string{"my server", "other"}},
+		{"Host=web", []string{"web"}},
+		{"Host = web db", []string{"web", "db"}},
+		{"HostName web", nil},
+		{"HostName=web", nil},
+		{"Host", nil},
+		{"Host=", nil},
+	}
+	for _, tt := range tests {
+		names, ok := HostNames(tt.line)
+		if !slices.Equal(names, tt.names) || ok != (tt.names != nil) {
+			t.Errorf("HostNames(%q) = %q, %v, want %q", tt.line, names, ok, tt.names)
+		}
+	}
+	if line := HostLine([]string{"my server", "web"}); line != `Host "my server" web` {
+		t.Errorf("HostLine() = %s", line)
+	}
+}
+
+func TestBlockEnd(t *testing.T) {
+	lines := []string{
+		"Host web",
+		"    HostName web.example.com",
+		"",
+		"    User deploy",
+		"",
+		"# database servers",
+		"# Tags: db",
+		"Host=db",
+		"    HostName db.example.com",
+		"",
+	}
+	if got := BlockEnd(lines, 0); got != 4 {
+		t.Errorf("BlockEnd(web) = %d, want 4, past the blank line inside the block", got)
+	}
+	if got := BlockEnd(lines, 7); got != 9 {
+		t.Errorf("BlockEnd(db) = %d, want 9", got)
+	}
+}
+
+func TestHasReadOnlyMarker(t *testing.T) {
+	tests := []struct {
+		content  string
+		readOnly bool
+	}{
+		{"# sshm: readonly\nHost a\n", true},
+		{"\ufeff#   SSHM:  ReadOnly\r\nHost a\r\n", true},
+		{"# sshc: readonly\n", true},
+		// Only the comment header at the top counts
+		{"Host a\n    HostName a\n# sshm: readonly\n", false},
+		{"# sshm: readonly please\nHost a\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := HasReadOnlyMarker(tt.content); got != tt.readOnly {
+			t.Errorf("HasReadOnlyMarker(%q) = %v, want %v", tt.content, got, tt.readOnly)
+		}
+	}
+}
+
+func TestQuoteValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "simple path without spaces",
+			input:    "/home/user/.ssh/id_rsa",
+			expected: "/home/user/.ssh/id_rsa",
+		},
+		{
+			name:     "path with spaces",
+			input:    "/home/user/My Documents/ssh key",
+			expected: "\"/home/user/My Documents/ssh key\"",
+		},
+		{
+			name:     "Windows path with spaces",
+			input:    `G:\My Drive\7 - Tech\9 - SSH Keys\Server_WF.opk`,
+			expected: `"G:\My Drive\7 - Tech\9 - SSH Keys\Server_WF.opk"`,
+		},
+		{
+			name:     "path with quotes but no spaces",
+			input:    `/home/user/key"with"quotes`,
+			expected: `/home/user/key"with"quotes`,
+		},
+		{
+			name:     "path with spaces and quotes",
+			input:    `/home/user/key "with" quotes`,
+			expected: `"/home/user/key "with" quotes"`,
+		},
+		{
+			name:     "empty path",
+			input:    "",
+			expected: "",
+		},
+		{
+			name:     "path with single space at end",
+			input:    "/home/user/key ",
+			expected: "\"/home/user/key \"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := QuoteValue(tt.input)
+			if result != tt.expected {
+				t.Errorf("QuoteValue(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package configtext
+
+import (
+	"bufio"
+	"slices"
+	"strings"
+)
+
+// Comments sshc attaches to the Host line that follows them
+const (
+	DescriptionPrefix = "# Description:"
+	TagsPrefix        = "# Tags:"
+	ExpiresPrefix     = "# Expires:"
+)
+
+// readOnlyMarkers are the comments that mark a file read-only when they appear in the
+// comment header at the top of the file, before any directive
+var readOnlyMarkers = []string{"sshm: readonly", "sshc: readonly"}
+
+// SplitDirective splits a config line into its keyword and value. As in ssh, the
+// keyword ends at a space, a tab or an "=", so "Host web", "Host=web" and
+// "Host = web" all give "Host" and "web".
+func SplitDirective(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return line, ""
+	}
+	value := strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return line[:end], value
+}
+
+// HostNames returns the names declared by a Host line. Like ssh, the keyword
+// matches in any case and may be followed by spaces, tabs or an "=".
+func HostNames(line string) ([]string, bool) {
+	keyword, value := SplitDirective(line)
+	if !strings.EqualFold(keyword, "host") {
+		return nil, false
+	}
+	names := SplitHostNames(value)
+	if len(names) == 0 {
+		return nil, false
+	}
+	return names, true
+}
+
+// SplitHostNames splits the value of a Host line into names. As in ssh, double
+// quotes keep a name together and a word starting with # begins a comment.
+func SplitHostNames(value string) []string {
+	var names []string
+	var name strings.Builder
+	inName, quoted := false, false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inName = true
+		case quoted:
+			name.WriteRune(r)
+		case r == ' ' || r == '\t':
+			if inName {
+				names = append(names, name.String())
+				name.Reset()
+				inName = false
+			}
+		case r == '#' && !inName:
+			return names
+		default:
+			name.WriteRune(r)
+			inName = true
+		}
+	}
+	if inName {
+		names = append(names, name.String())
+	}
+	return names
+}
+
+// HostLine formats a Host line, quoting names ssh would otherwise split
+func HostLine(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		if name == "" || strings.ContainsAny(name, " \t#") {
+			name = `"` + name + `"`
+		}
+		quoted[i] = name
+	}
+	return "Host " + strings.Join(quoted, " ")
+}
+
+// DeclaringNames returns the names of the first Host line in content that
+// declares name, or nil when none does
+func DeclaringNames(content, name string) ([]string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if names, ok := HostNames(TrimLine(scanner.Text())); ok && slices.Contains(names, name) {
+			return names, nil
+		}
+	}
+	return nil, scanner.Err()
+}
+
+// IsPattern reports whether a name on a Host line is a wildcard or negated
+// pattern rather than a host. Brackets are not special to ssh, so web[1] is a host.
+func IsPattern(name string) bool {
+	return strings.ContainsAny(name, "*?") || strings.HasPrefix(name, "!")
+}
+
+// IsHostLine reports whether a line starts a Host block
+func IsHostLine(line string) bool {
+	_, ok := HostNames(line)
+	return ok
+}
+
+// IsTagDirective reports whether a trimmed line is a native Tag directive
+func IsTagDirective(line string) bool {
+	keyword, value := SplitDirective(line)
+	return value != "" && strings.EqualFold(keyword, "tag")
+}
+
+// IsBlockStart reports whether a line starts a Host or Match block, either of
+// which ends the block before it
+func IsBlockStart(line string) bool {
+	keyword, value := SplitDirective(line)
+	return IsHostLine(line) || (value != "" && strings.EqualFold(keyword, "match"))
+}
+
+// BlockEnd returns the index of the first line after the block whose Host line is
+// at hostLine. As in ssh, blank lines do not end a block; it runs up to the next
+// Host or Match line, without the blank lines and comments that lead into it,
+// such as the metadata comments of the next host.
+func BlockEnd(lines []string, hostLine int) int {
+	end := hostLine + 1
+	for end < len(lines) && !IsBlockStart(TrimLine(lines[end])) {
+		end++
+	}
+	for end > hostLine+1 {
+		if line := TrimLine(lines[end-1]); line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// IsMetadataComment reports whether a trimmed line is one of the comments
+// sshc keeps directly above a Host line
+func IsMetadataComment(line string) bool {
+	return strings.HasPrefix(line, DescriptionPrefix) ||
+		strings.HasPrefix(line, TagsPrefix) ||
+		strings.HasPrefix(line, ExpiresPrefix)
+}
+
+// MetadataEnd returns the index of the first line after the run of metadata
+// comments starting at index i, or i itself if lines[i] is not a metadata comment.
+// Blank lines between the comments and a Host line belong to the run, so comments a
+// formatter separated from their Host line are still found.
+func MetadataEnd(lines []string, i int) int {
+	end := i
+	for j := i; j < len(lines); j++ {
+		line := strings.TrimSpace(lines[j])
+		switch {
+		case IsMetadataComment(line):
+			end = j + 1
+		case line == "" && end > i:
+		case end > i && IsHostLine(line):
+			return j
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// MergeTags appends tags that are not in existing yet, keeping the order they appear in
+func MergeTags(existing []string, tags ...string) []string {
+	for _, tag := range tags {
+		if tag != "" && !slices.Contains(existing, tag) {
+			existing = append(existing, tag)
+		}
+	}
+	return existing
+}
+
+// IsNativeTag reports whether ssh reads tag as the single value of a Tag directive
+func IsNativeTag(tag string) bool {
+	return tag != "" && !strings.ContainsAny(tag, " \t\"'=#")
+}
+
+// SingleLine collapses text onto a single line so it fits in one comment
+func SingleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// QuoteValue formats a value for a config file, adding quotes if necessary
+func QuoteValue(value string) string {
+	if value == "" {
+		return value
+	}
+
+	// If the value contains spaces, wrap it in quotes
+	if strings.Contains(value, " ") {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+// IsReadOnlyMarker reports whether a trimmed line is a read-only marker comment
+func IsReadOnlyMarker(line string) bool {
+	comment, ok := strings.CutPrefix(line, "#")
+	if !ok {
+		return false
+	}
+	comment = strings.Join(strings.Fields(strings.ToLower(comment)), " ")
+	return slices.Contains(readOnlyMarkers, comment)
+}
+
+// HasReadOnlyMarker reports whether config content starts with a read-only marker.
+// Only the comment header at the top is read.
+func HasReadOnlyMarker(content string) bool {
+	lines, _ := Split(content)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if IsReadOnlyMarker(line) {
+			return true
+		}
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return false
+}
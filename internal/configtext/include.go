@@ -0,0 +1,130 @@
+package configtext
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Glob expands an Include pattern. Like glob(3), which ssh uses, a pattern
+// filepath.Glob rejects, such as an unclosed [, names a file literally.
+//
+// The matches are sorted by their absolute path in byte order, as glob(3) sorts
+// whole paths with strcmp. filepath.Glob only sorts the names within each
+// directory, so "a-b/x" would come after "a/y" although '-' sorts before '/'.
+// Hosts are read in this order whatever the platform or file system, which
+// decides the first of two definitions of a name.
+func Glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if errors.Is(err, filepath.ErrBadPattern) {
+		if _, statErr := os.Stat(pattern); statErr != nil {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sortMatches(matches), nil
+}
+
+// sortMatches makes matches absolute and sorts them in byte order, dropping
+// paths that come up twice. Separators compare as /, so Windows sorts the same.
+func sortMatches(matches []string) []string {
+	for i, match := range matches {
+		if abs, err := filepath.Abs(match); err == nil {
+			matches[i] = abs
+		}
+	}
+	slices.SortFunc(matches, func(a, b string) int {
+		return strings.Compare(filepath.ToSlash(a), filepath.ToSlash(b))
+	})
+	return slices.Compact(matches)
+}
+
+// NotConfig reports whether an included file is skipped because it is not an
+// ssh config, going by its name or, failing that, its first few KB
+func NotConfig(filePath string) bool {
+	if IgnoredName(filePath) {
+		return true
+	}
+
+	// Additional check: if file contains common non-SSH content indicators
+	// This is a more expensive check, so we do it last
+	return hasNonSSHContent(filePath)
+}
+
+// IgnoredName reports whether an included file is skipped by its name alone,
+// without reading it: backups, hidden files, documentation, scripts and the like
+func IgnoredName(filePath string) bool {
+	fileName := strings.ToLower(filepath.Base(filePath))
+
+	// Skip backup files created by sshc
+	if strings.HasSuffix(fileName, ".backup") {
+		return true
+	}
+
+	// Skip common documentation files
+	if fileName == "readme" || fileName == "readme.txt" {
+		return true
+	}
+
+	// Skip files with common non-config extensions
+	excludedExtensions := []string{
+		".txt", ".md", ".rst", ".doc", ".docx", ".pdf",
+		".log", ".tmp", ".bak", ".old", ".orig",
+		".json", ".xml", ".yaml", ".yml", ".toml",
+		".sh", ".bash", ".zsh", ".fish", ".ps1", ".bat", ".cmd",
+		".py", ".pl", ".rb", ".js", ".php", ".go", ".c", ".cpp",
+		".jpg", ".jpeg", ".png", ".gif", ".bmp", ".svg",
+		".zip", ".tar", ".gz", ".bz2", ".xz",
+	}
+
+	for _, ext := range excludedExtensions {
+		if strings.HasSuffix(fileName, ext) {
+			return true
+		}
+	}
+
+	// Skip hidden files (starting with .)
+	return strings.HasPrefix(fileName, ".")
+}
+
+// hasNonSSHContent performs a quick content check to identify non-SSH files
+func hasNonSSHContent(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false // If we can't read it, don't exclude it
+	}
+	defer file.Close()
+
+	// Read only the first few KB to check content
+	buffer := make([]byte, 2048)
+	n, err := file.Read(buffer)
+	if err != nil && err != io.EOF {
+		return false
+	}
+
+	content := strings.ToLower(string(buffer[:n]))
+
+	// Check for common non-SSH file indicators
+	nonSSHIndicators := []string{
+		"<!doctype", "<html>", "<xml>", "<?xml",
+		"#!/bin/", "#!/usr/bin/",
+		"# readme", "# documentation", "# license",
+		"package main", "function ", "class ", "def ",
+		"import ", "require ", "#include",
+		"SELECT ", "INSERT ", "UPDATE ", "DELETE ",
+	}
+
+	for _, indicator := range nonSSHIndicators {
+		if strings.Contains(content, indicator) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package configtext
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestSortIncludeMatches(t *testing.T) {
+	got := sortMatches([]string{"/x/a/y", "/x/a-b/z", "/x/A/w", "/x/a-b/z"})
+	if want := []string{"/x/A/w", "/x/a-b/z", "/x/a/y"}; runtime.GOOS != "windows" && !slices.Equal(got, want) {
+		t.Errorf("sortMatches() = %q, want %q", got, want)
+	}
+}
+
+func TestNotConfig(t *testing.T) {
+	tests := []struct {
+		fileName string
+		expected bool
+	}{
+		// Should be excluded
+		{"README", true},
+		{"README.txt", true},
+		{"README.md", true},
+		{"script.sh", true},
+		{"data.json", true},
+		{"notes.txt", true},
+		{".gitignore", true},
+		{"backup.bak", true},
+		{"old.orig", true},
+		{"log.log", true},
+		{"temp.tmp", true},
+		{"archive.zip", true},
+		{"image.jpg", true},
+		{"python.py", true},
+		{"golang.go", true},
+		{"config.yaml", true},
+		{"config.yml", true},
+		{"config.toml", true},
+
+		// Should NOT be excluded (valid SSH config files)
+		{"config", false},
+		{"servers.conf", false},
+		{"production", false},
+		{"staging", false},
+		{"hosts", false},
+		{"ssh_config", false},
+		{"work-servers", false},
+	}
+
+	for _, test := range tests {
+		// Create a temporary file for content testing
+		tempDir := t.TempDir()
+		filePath := filepath.Join(tempDir, test.fileName)
+
+		// Write appropriate content based on expected result
+		var content string
+		if test.expected {
+			// Write non-SSH content for files that should be excluded
+			content = "# This is not an SSH config file\nSome random content"
+		} else {
+			// Write SSH-like content for files that should be included
+			content = "Host example\n    HostName example.com\n    User testuser"
+		}
+
+		err := os.WriteFile(filePath, []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("Failed to create test file %s: %v", test.fileName, err)
+		}
+
+		result := NotConfig(filePath)
+		if result != test.expected {
+			t.Errorf("NotConfig(%q) = %v, want %v", test.fileName, result, test.expected)
+		}
+	}
+}
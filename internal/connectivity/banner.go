@@ -0,0 +1,97 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os/user"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// BannerTimeout bounds fetching a pre-auth banner, connection included
+const BannerTimeout = 5 * time.Second
+
+// ErrBannerProxied is returned for hosts reached through ProxyJump or ProxyCommand,
+// whose sshd cannot be dialed directly
+var ErrBannerProxied = errors.New("reached through a jump host, so the banner is not fetched")
+
+// FetchBanner connects to the host's sshd and returns the banner it sends before
+// authentication, or "" when it sends none. Nothing is authenticated: the
+// connection is dropped once the server asks for credentials.
+func FetchBanner(ctx context.Context, host config.SSHHost) (string, error) {
+	if host.IsProxied() {
+		return "", ErrBannerProxied
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, BannerTimeout)
+	defer cancel()
+
+	address := resolveAddress(ctx, host)
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	// The handshake has no context of its own; the deadline keeps a silent server from hanging it
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var banner string
+	sshConfig := &ssh.ClientConfig{
+		User:            bannerUser(host),
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // Only the banner is read
+		BannerCallback: func(message string) error {
+			banner += message
+			return nil
+		},
+	}
+
+	sshConn, _, _, err := ssh.NewClientConn(conn, address, sshConfig)
+	if sshConn != nil {
+		sshConn.Close()
+	}
+	if banner != "" {
+		return cleanBanner(banner), nil
+	}
+
+	// Failing to authenticate without credentials is the expected outcome
+	if err != nil && !strings.Contains(err.Error(), "unable to authenticate") {
+		return "", err
+	}
+	return "", nil
+}
+
+// bannerUser returns the user to present, as some servers tailor the banner to it
+func bannerUser(host config.SSHHost) string {
+	if host.User != "" {
+		return host.User
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return "root"
+}
+
+// cleanBanner drops carriage returns and control characters a server could use to
+// redraw the terminal, and trims trailing blank lines
+func cleanBanner(banner string) string {
+	banner = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, banner)
+	return strings.TrimRight(banner, " \t\n")
+}
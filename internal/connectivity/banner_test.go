@@ -0,0 +1,86 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startBannerServer runs an sshd stand-in that sends banner and rejects every login
+func startBannerServer(t *testing.T, banner string) config.SSHHost {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+			return nil, errors.New("denied")
+		},
+	}
+	if banner != "" {
+		serverConfig.BannerCallback = func(ssh.ConnMetadata) string { return banner }
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				ssh.NewServerConn(conn, serverConfig)
+			}()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return config.SSHHost{Name: "test", Hostname: host, Port: port, User: "deploy"}
+}
+
+func TestFetchBanner(t *testing.T) {
+	host := startBannerServer(t, "Authorized use only\r\n\x1b[2JAll access is logged\r\n\r\n")
+
+	banner, err := FetchBanner(context.Background(), host)
+	if err != nil {
+		t.Fatalf("FetchBanner() error = %v", err)
+	}
+	if want := "Authorized use only\n[2JAll access is logged"; banner != want {
+		t.Errorf("FetchBanner() = %q, want %q", banner, want)
+	}
+}
+
+func TestFetchBannerWithoutBanner(t *testing.T) {
+	host := startBannerServer(t, "")
+
+	banner, err := FetchBanner(context.Background(), host)
+	if err != nil || banner != "" {
+		t.Errorf("FetchBanner() = %q, %v; want no banner and no error", banner, err)
+	}
+}
+
+func TestFetchBannerSkipsProxiedHosts(t *testing.T) {
+	host := config.SSHHost{Name: "internal", Hostname: "10.0.0.1", ProxyJump: "bastion"}
+
+	if _, err := FetchBanner(context.Background(), host); !errors.Is(err, ErrBannerProxied) {
+		t.Errorf("FetchBanner() error = %v, want ErrBannerProxied", err)
+	}
+}
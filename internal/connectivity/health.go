@@ -0,0 +1,227 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+// DefaultHealthProbe prints load, root disk use and memory on Linux and macOS
+const DefaultHealthProbe = "uptime; df -P / | tail -n 1; " +
+	"if command -v free >/dev/null 2>&1; then free -m | head -n 2; else sysctl -n hw.memsize; vm_stat; fi"
+
+// HealthProbeTimeout bounds a whole health probe, connection included
+const HealthProbeTimeout = 15 * time.Second
+
+// HostHealth is what a health probe found on a host. Percentages are -1 when the
+// probe output did not include them.
+type HostHealth struct {
+	HostName    string
+	Load        [3]float64 // 1, 5 and 15 minute load averages
+	HasLoad     bool
+	DiskPercent int // Use of the root filesystem
+	MemPercent  int
+	Err         error // Set when the host could not be probed
+}
+
+// ProbeHealth runs the probe command on a host over ssh without prompting for anything
+func ProbeHealth(ctx context.Context, hostName, configFile, command string) HostHealth {
+	if command == "" {
+		command = DefaultHealthProbe
+	}
+	ctx, cancel := context.WithTimeout(ctx, HealthProbeTimeout)
+	defer cancel()
+
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5", "-T"}
+	args = append(args, sshver.NoRemoteCommandArgs()...)
+	if configFile != "" {
+		args = append([]string{"-F", configFile}, args...)
+	}
+	args = append(args, hostName, command)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	health := ParseHealthOutput(stdout.String())
+	health.HostName = hostName
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		health.Err = errors.New("timed out")
+	case err != nil && !health.HasLoad:
+		// ssh exits with 255 for connection failures; partial probe output still counts
+		health.Err = sshFailure(stderr.String(), err)
+	}
+	return health
+}
+
+// sshFailure turns a failed ssh run into an error carrying the first line ssh printed
+func sshFailure(stderr string, err error) error {
+	if sshver.RejectsNoRemoteCommand(stderr) {
+		return sshver.ErrRemoteCommandUnsupported
+	}
+	msg := strings.TrimSpace(stderr)
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = strings.TrimSpace(msg[:i])
+	}
+	if msg == "" {
+		msg = err.Error()
+	}
+	return errors.New(msg)
+}
+
+// ParseHealthOutput extracts load averages, root disk use and memory use from the
+// output of uptime, df -P and either free -m (Linux) or sysctl hw.memsize with
+// vm_stat (macOS). Lines it does not recognize are ignored.
+func ParseHealthOutput(output string) HostHealth {
+	health := HostHealth{DiskPercent: -1, MemPercent: -1}
+
+	var memTotalBytes, pageSize float64
+	availableColumn := -1
+	vmPages := make(map[string]float64)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(line)
+
+		switch {
+		case line == "":
+			continue
+
+		case strings.Contains(line, "load average"):
+			health.Load, health.HasLoad = parseLoadAverages(line)
+
+		case fields[0] == "total" && len(fields) > 1 && fields[1] == "used":
+			// The header of free -m; older versions have no "available" column
+			availableColumn = slices.Index(fields, "available")
+
+		case strings.HasPrefix(line, "Mem:"):
+			health.MemPercent = parseFreeMemLine(fields, availableColumn)
+
+		case strings.HasPrefix(line, "Mach Virtual Memory Statistics"):
+			if _, after, ok := strings.Cut(line, "page size of "); ok && len(strings.Fields(after)) > 0 {
+				pageSize, _ = strconv.ParseFloat(strings.Fields(after)[0], 64)
+			}
+
+		case strings.HasPrefix(line, "Pages "):
+			if name, value, ok := strings.Cut(line, ":"); ok {
+				pages, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "."), 64)
+				if err == nil {
+					vmPages[strings.TrimPrefix(name, "Pages ")] = pages
+				}
+			}
+
+		case len(fields) == 1:
+			// sysctl -n hw.memsize prints the memory size in bytes on a line of its own
+			if size, err := strconv.ParseFloat(fields[0], 64); err == nil {
+				memTotalBytes = size
+			} else if percent, ok := parsePercent(fields[0]); ok && health.DiskPercent < 0 {
+				// df --output=pcent
+				health.DiskPercent = percent
+			}
+
+		case health.DiskPercent < 0 && len(fields) >= 6:
+			// df -P: filesystem, blocks, used, available, capacity, mount point
+			if percent, ok := parsePercent(fields[len(fields)-2]); ok {
+				health.DiskPercent = percent
+			}
+		}
+	}
+
+	if health.MemPercent < 0 && memTotalBytes > 0 && pageSize > 0 {
+		// Memory in use as Activity Monitor counts it: app, wired and compressed pages
+		used := (vmPages["active"] + vmPages["wired down"] + vmPages["occupied by compressor"]) * pageSize
+		health.MemPercent = clampPercent(used / memTotalBytes * 100)
+	}
+	return health
+}
+
+// parseLoadAverages reads "load average: 0.10, 0.20, 0.30" (Linux) or
+// "load averages: 1.50 1.62 1.70" (macOS)
+func parseLoadAverages(line string) ([3]float64, bool) {
+	var load [3]float64
+	i := strings.Index(line, "load average")
+	_, rest, ok := strings.Cut(line[i:], ":")
+	if !ok {
+		return load, false
+	}
+
+	// Linux separates the values with ", ", macOS with spaces. Either may use a
+	// decimal comma depending on the locale.
+	rest = strings.TrimSpace(rest)
+	values := strings.Split(rest, ", ")
+	if len(values) < 3 {
+		values = strings.Fields(rest)
+	}
+	if len(values) < 3 {
+		return load, false
+	}
+	for j := range load {
+		value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(values[j]), ",", "."), 64)
+		if err != nil {
+			return load, false
+		}
+		load[j] = value
+	}
+	return load, true
+}
+
+// parseFreeMemLine computes memory use from the "Mem:" line of free -m. The
+// "available" column, at the given index of the header when present, accounts
+// for reclaimable cache.
+func parseFreeMemLine(fields []string, availableColumn int) int {
+	if len(fields) < 3 {
+		return -1
+	}
+	total, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || total <= 0 {
+		return -1
+	}
+	used, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return -1
+	}
+	// The header has no label for the leading "Mem:" column
+	if availableColumn >= 0 && availableColumn+1 < len(fields) {
+		if available, err := strconv.ParseFloat(fields[availableColumn+1], 64); err == nil {
+			used = total - available
+		}
+	}
+	return clampPercent(used / total * 100)
+}
+
+// parsePercent parses a value such as "42%"
+func parsePercent(s string) (int, bool) {
+	if !strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	value, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// clampPercent rounds a percentage into 0-100
+func clampPercent(value float64) int {
+	return int(min(max(value+0.5, 0), 100))
+}
+
+// FormatLoad renders the load averages of a health result
+func (h HostHealth) FormatLoad() string {
+	if !h.HasLoad {
+		return "?"
+	}
+	return fmt.Sprintf("%.2f %.2f %.2f", h.Load[0], h.Load[1], h.Load[2])
+}
@@ -0,0 +1,212 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/sshver"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Outputs of DefaultHealthProbe captured on real systems
+const (
+	ubuntuHealthOutput = ` 14:02:11 up 41 days,  3:12,  2 users,  load average: 0.42, 0.35, 0.30
+/dev/sda1         40581564 29218996  11346184      73% /
+               total        used        free      shared  buff/cache   available
+Mem:            7951        2113         412          38        5425        5488
+`
+	centos6HealthOutput = ` 09:15:42 up 300 days, 22:01,  1 user,  load average: 2.10, 1.95, 1.80
+/dev/mapper/VolGroup-lv_root  51475068 48901312   1235500      98% /
+             total       used       free     shared    buffers     cached
+Mem:          3832       3700        132          0        210       1900
+`
+	macOSHealthOutput = `14:02  up 6 days,  2:41, 3 users, load averages: 1.50 1.62 1.70
+/dev/disk3s1s1   971350180  20414008 464311736     5%    /
+17179869184
+Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               12110.
+Pages active:                            330442.
+Pages inactive:                          318233.
+Pages speculative:                         9867.
+Pages throttled:                              0.
+Pages wired down:                        156015.
+Pages purgeable:                          12817.
+"Translation faults":                 1195874102.
+Pages copy-on-write:                   55264720.
+Pages occupied by compressor:            213340.
+`
+	// A German locale prints decimal commas
+	localeHealthOutput = ` 14:02:11 up 1 day,  load average: 0,42, 0,35, 0,30
+Use%
+ 12%
+`
+)
+
+func TestParseHealthOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		load   [3]float64
+		disk   int
+		mem    int
+	}{
+		// Used memory excludes reclaimable cache: (7951-5488)/7951
+		{"ubuntu", ubuntuHealthOutput, [3]float64{0.42, 0.35, 0.30}, 73, 31},
+		// Without an available column, used/total
+		{"centos6", centos6HealthOutput, [3]float64{2.10, 1.95, 1.80}, 98, 97},
+		// (330442+156015+213340) pages of 16 KiB out of 16 GiB
+		{"macos", macOSHealthOutput, [3]float64{1.50, 1.62, 1.70}, 5, 67},
+		{"decimal comma and df --output", localeHealthOutput, [3]float64{0.42, 0.35, 0.30}, 12, -1},
+	}
+
+	for _, tt := range tests {
+		health := ParseHealthOutput(tt.output)
+		if !health.HasLoad || health.Load != tt.load {
+			t.Errorf("%s: load = %v (found %v), want %v", tt.name, health.Load, health.HasLoad, tt.load)
+		}
+		if health.DiskPercent != tt.disk {
+			t.Errorf("%s: disk = %d%%, want %d%%", tt.name, health.DiskPercent, tt.disk)
+		}
+		if health.MemPercent != tt.mem {
+			t.Errorf("%s: memory = %d%%, want %d%%", tt.name, health.MemPercent, tt.mem)
+		}
+	}
+}
+
+func TestParseHealthOutputWithoutData(t *testing.T) {
+	health := ParseHealthOutput("Permission denied (publickey).\n")
+	if health.HasLoad || health.DiskPercent != -1 || health.MemPercent != -1 {
+		t.Errorf("Expected nothing parsed, got %+v", health)
+	}
+	if health.FormatLoad() != "?" {
+		t.Errorf("FormatLoad() = %q, want ?", health.FormatLoad())
+	}
+}
+
+// startProbeServer runs an sshd stand-in that lets anyone in, records the
+// commands it is asked to run and answers each with ubuntuHealthOutput
+func startProbeServer(t *testing.T) (port string, commands func() []string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &ssh.ServerConfig{NoClientAuth: true}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	var seen []string
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChannel := range chans {
+					channel, requests, err := newChannel.Accept()
+					if err != nil {
+						continue
+					}
+					go func() {
+						defer channel.Close()
+						for req := range requests {
+							if req.Type != "exec" {
+								req.Reply(req.Type == "env", nil)
+								continue
+							}
+							var payload struct{ Command string }
+							ssh.Unmarshal(req.Payload, &payload)
+							mu.Lock()
+							seen = append(seen, payload.Command)
+							mu.Unlock()
+							req.Reply(true, nil)
+							io.WriteString(channel, ubuntuHealthOutput)
+							channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+							return
+						}
+					}()
+				}
+			}()
+		}
+	}()
+
+	_, port, _ = net.SplitHostPort(listener.Addr().String())
+	return port, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(seen)
+	}
+}
+
+func TestProbeHealthIgnoresRemoteCommand(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh not available")
+	}
+	port, commands := startProbeServer(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// ssh refuses to run a command on a host with a RemoteCommand unless it is overridden
+	configFile := filepath.Join(home, "config")
+	fixture := "Host box\n" +
+		"  HostName 127.0.0.1\n" +
+		"  Port " + port + "\n" +
+		"  User deploy\n" +
+		"  RemoteCommand htop\n" +
+		"  RequestTTY force\n" +
+		"  StrictHostKeyChecking no\n" +
+		"  UserKnownHostsFile /dev/null\n" +
+		"  LogLevel ERROR\n"
+	if err := os.WriteFile(configFile, []byte(fixture), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	health := ProbeHealth(context.Background(), "box", configFile, "")
+	if health.Err != nil {
+		t.Fatalf("ProbeHealth() error = %v", health.Err)
+	}
+	if !health.HasLoad || health.DiskPercent != 73 {
+		t.Errorf("ProbeHealth() = %+v, want the probe output parsed", health)
+	}
+	if got := commands(); !slices.Equal(got, []string{DefaultHealthProbe}) {
+		t.Errorf("server was asked to run %q, want only the probe", got)
+	}
+	if err := VerifyLogin(context.Background(), "box", configFile); err != nil {
+		t.Errorf("VerifyLogin() error = %v", err)
+	}
+}
+
+func TestSSHFailureExplainsOldClients(t *testing.T) {
+	err := sshFailure("command-line line 0: Bad configuration option: remotecommand\n", errors.New("exit status 255"))
+	if !errors.Is(err, sshver.ErrRemoteCommandUnsupported) {
+		t.Errorf("sshFailure() = %v, want ErrRemoteCommandUnsupported", err)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build integration
+
+package connectivity
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshtest"
+)
+
+func TestIntegrationProbeHealthRunsTheRealProbe(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh not available")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	server := sshtest.NewServer(t)
+	key := sshtest.StartAgent(t)
+
+	configFile := filepath.Join(home, "config")
+	if err := os.WriteFile(configFile, []byte(server.Config("box")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The agent key is not authorized yet
+	if err := VerifyLogin(context.Background(), "box", configFile); err == nil {
+		t.Fatal("VerifyLogin() succeeded with a key the server does not know")
+	}
+	server.Authorize(key)
+	if err := VerifyLogin(context.Background(), "box", configFile); err != nil {
+		t.Fatalf("VerifyLogin() error = %v", err)
+	}
+
+	// The probe runs in this machine's shell, so its output is whatever the system prints
+	health := ProbeHealth(context.Background(), "box", configFile, "")
+	if health.Err != nil {
+		t.Fatalf("ProbeHealth() error = %v", health.Err)
+	}
+	if !health.HasLoad || health.DiskPercent < 0 {
+		t.Errorf("ProbeHealth() = %+v, want load and disk usage read from the real probe", health)
+	}
+}
+
+func TestIntegrationFetchBanner(t *testing.T) {
+	server := sshtest.NewServer(t)
+	server.SetBanner("Authorized use only\r\n\n")
+
+	banner, err := FetchBanner(context.Background(), config.SSHHost{Name: "box", Hostname: server.Host, Port: server.Port, User: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if banner != "Authorized use only" {
+		t.Errorf("FetchBanner() = %q", banner)
+	}
+}
@@ -0,0 +1,91 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// BastionDownError is the result of a host that was not probed because a host
+// it is reached through could not be
+type BastionDownError struct {
+	Bastion string
+}
+
+func (e *BastionDownError) Error() string {
+	return "bastion " + e.Bastion + " down"
+}
+
+// JumpScheduler orders probes of hosts by their ProxyJump chains: a host is
+// probed once the bastions it goes through were, and not at all when one of
+// them failed, instead of waiting for its own probe to time out
+type JumpScheduler struct {
+	graph *config.JumpGraph
+	mutex sync.Mutex
+	done  map[string]chan struct{} // Closed once the host's probe finished, for the hosts of the batch
+	errs  map[string]error
+}
+
+// NewJumpScheduler creates a scheduler for probing hostNames. Bastions that are
+// not among them are not waited for.
+func NewJumpScheduler(graph *config.JumpGraph, hostNames []string) *JumpScheduler {
+	s := &JumpScheduler{
+		graph: graph,
+		done:  make(map[string]chan struct{}, len(hostNames)),
+		errs:  make(map[string]error, len(hostNames)),
+	}
+	for _, name := range hostNames {
+		s.done[name] = make(chan struct{})
+	}
+	return s
+}
+
+// Wait blocks until the bastions of hostName were probed, outermost first, and
+// returns a BastionDownError naming the first that failed. Hosts whose chain runs
+// into a cycle do not wait, as their bastions may be waiting for them.
+func (s *JumpScheduler) Wait(ctx context.Context, hostName string) error {
+	if s.graph.InCycle(hostName) {
+		return nil
+	}
+	for _, bastion := range s.graph.Chain(hostName) {
+		done, ok := s.done[bastion]
+		if !ok {
+			continue
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		s.mutex.Lock()
+		err := s.errs[bastion]
+		s.mutex.Unlock()
+		if err == nil {
+			continue
+		}
+		// Name the bastion that is down, not the one left unprobed behind it
+		var down *BastionDownError
+		if errors.As(err, &down) {
+			return down
+		}
+		return &BastionDownError{Bastion: bastion}
+	}
+	return nil
+}
+
+// Done records the result of the probe of hostName, or of skipping it. It must
+// be called once for every host of the batch, or hosts behind it wait until
+// their context ends.
+func (s *JumpScheduler) Done(hostName string, err error) {
+	done, ok := s.done[hostName]
+	if !ok {
+		return
+	}
+	s.mutex.Lock()
+	s.errs[hostName] = err
+	s.mutex.Unlock()
+	close(done)
+}
@@ -0,0 +1,147 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// twoLevelBastionHosts reaches the private hosts through an inner bastion that
+// is itself only reachable through the edge bastion
+var twoLevelBastionHosts = []config.SSHHost{
+	{Name: "db", ProxyJump: "inner"},
+	{Name: "web", ProxyJump: "inner"},
+	{Name: "reports", ProxyJump: "edge,inner"},
+	{Name: "inner", ProxyJump: "admin@edge:2222"},
+	{Name: "edge"},
+	{Name: "public"},
+}
+
+// runScheduled probes every host concurrently through a scheduler, failing the
+// hosts in down, and returns the result of each host and the order the probes ran in
+func runScheduled(t *testing.T, hosts []config.SSHHost, down ...string) (map[string]error, []string) {
+	t.Helper()
+	names := make([]string, len(hosts))
+	for i, host := range hosts {
+		names[i] = host.Name
+	}
+	scheduler := NewJumpScheduler(config.BuildJumpGraph(hosts), names)
+
+	var mutex sync.Mutex
+	var probed []string
+	results := make(map[string]error)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := scheduler.Wait(context.Background(), name)
+			if err == nil {
+				mutex.Lock()
+				probed = append(probed, name)
+				mutex.Unlock()
+				if slices.Contains(down, name) {
+					err = errors.New("timed out")
+				}
+			}
+			scheduler.Done(name, err)
+			mutex.Lock()
+			results[name] = err
+			mutex.Unlock()
+		}()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("probes are still waiting for each other")
+	}
+	return results, probed
+}
+
+func TestJumpSchedulerProbesBastionsFirst(t *testing.T) {
+	results, probed := runScheduled(t, twoLevelBastionHosts)
+
+	for name, err := range results {
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	position := func(name string) int { return slices.Index(probed, name) }
+	for _, leaf := range []string{"db", "web", "reports"} {
+		if position(leaf) < position("inner") {
+			t.Errorf("%s was probed before inner: %v", leaf, probed)
+		}
+	}
+	if position("inner") < position("edge") {
+		t.Errorf("inner was probed before edge: %v", probed)
+	}
+}
+
+func TestJumpSchedulerSkipsHostsBehindDownBastion(t *testing.T) {
+	tests := []struct {
+		down    string
+		bastion string   // Reported for the hosts behind it
+		behind  []string // Hosts not probed
+	}{
+		{"edge", "edge", []string{"inner", "db", "web", "reports"}},
+		{"inner", "inner", []string{"db", "web", "reports"}},
+	}
+	for _, tt := range tests {
+		results, probed := runScheduled(t, twoLevelBastionHosts, tt.down)
+
+		for _, name := range tt.behind {
+			var down *BastionDownError
+			if !errors.As(results[name], &down) || down.Bastion != tt.bastion {
+				t.Errorf("%s down: %s got %v, want bastion %s down", tt.down, name, results[name], tt.bastion)
+			}
+			if slices.Contains(probed, name) {
+				t.Errorf("%s down: %s was probed", tt.down, name)
+			}
+		}
+		if results["public"] != nil {
+			t.Errorf("%s down: public got %v", tt.down, results["public"])
+		}
+		if err := results[tt.down]; err == nil || errors.As(err, new(*BastionDownError)) {
+			t.Errorf("%s down: its own result is %v, want its probe error", tt.down, err)
+		}
+	}
+}
+
+func TestJumpSchedulerCycle(t *testing.T) {
+	hosts := []config.SSHHost{
+		{Name: "a", ProxyJump: "b"},
+		{Name: "b", ProxyJump: "a"},
+		{Name: "behind", ProxyJump: "a"},
+		{Name: "self", ProxyJump: "self"},
+	}
+	// ssh fails on these by itself; they must only not wait for each other
+	_, probed := runScheduled(t, hosts, "a", "b", "self")
+	if len(probed) != len(hosts) {
+		t.Errorf("probed %v, want every host of a cycle probed on its own", probed)
+	}
+}
+
+func TestJumpSchedulerWaitCancelled(t *testing.T) {
+	graph := config.BuildJumpGraph(twoLevelBastionHosts)
+	scheduler := NewJumpScheduler(graph, []string{"edge", "inner"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := scheduler.Wait(ctx, "inner"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want the context error", err)
+	}
+	// Bastions outside the batch are not waited for
+	if err := NewJumpScheduler(graph, []string{"db"}).Wait(context.Background(), "db"); err != nil {
+		t.Errorf("Wait() = %v without bastions to wait for", err)
+	}
+}
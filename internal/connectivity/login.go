@@ -0,0 +1,41 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+// LoginTimeout bounds a login check, connection included
+const LoginTimeout = 20 * time.Second
+
+// VerifyLogin connects to a host with BatchMode, so it fails instead of asking for a
+// password, a passphrase or trust in an unknown host key, and runs nothing but true
+func VerifyLogin(ctx context.Context, hostName, configFile string) error {
+	ctx, cancel := context.WithTimeout(ctx, LoginTimeout)
+	defer cancel()
+
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10", "-T"}
+	args = append(args, sshver.NoRemoteCommandArgs()...)
+	if configFile != "" {
+		args = append([]string{"-F", configFile}, args...)
+	}
+	args = append(args, hostName, "true")
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return errors.New("timed out")
+	case err != nil:
+		return sshFailure(stderr.String(), err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+// OSProbe prints the system name and kernel release, the uptime and, on Linux, the
+// distribution. Only procps has uptime -p; BSD, macOS and busybox print the classic line.
+const OSProbe = "uname -sr; uptime -p 2>/dev/null || uptime; grep -s '^ID=' /etc/os-release"
+
+// OSProbeTimeout bounds an OS probe, connection included
+const OSProbeTimeout = 10 * time.Second
+
+// DefaultOSInfoTTL is how long probed OS info is used before the host is probed again
+const DefaultOSInfoTTL = 24 * time.Hour
+
+// osCacheFile is the name of the OS info cache in the cache directory
+const osCacheFile = "os_info.json"
+
+// OSInfo is what an OS probe found on a host
+type OSInfo struct {
+	Family    string        `json:"family"` // Distribution ID such as "ubuntu", or "macos", "freebsd"
+	System    string        `json:"system"` // As printed by uname -s
+	Kernel    string        `json:"kernel"` // As printed by uname -r
+	Uptime    time.Duration `json:"uptime,omitempty"`
+	HasUptime bool          `json:"has_uptime,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// ProbeOSInfo runs OSProbe on a host over ssh without prompting for anything
+func ProbeOSInfo(ctx context.Context, hostName, configFile string) (OSInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, OSProbeTimeout)
+	defer cancel()
+
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5", "-T"}
+	args = append(args, sshver.NoRemoteCommandArgs()...)
+	if configFile != "" {
+		args = append([]string{"-F", configFile}, args...)
+	}
+	args = append(args, hostName, OSProbe)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// grep exits with 1 without an os-release, so the output decides
+	err := cmd.Run()
+	info, ok := ParseOSInfo(stdout.String())
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return OSInfo{}, errors.New("timed out")
+	case !ok && err != nil:
+		return OSInfo{}, sshFailure(stderr.String(), err)
+	case !ok:
+		return OSInfo{}, errors.New("unrecognized uname output")
+	}
+	info.CheckedAt = time.Now()
+	return info, nil
+}
+
+// ParseOSInfo reads the output of OSProbe: the uname -sr line first, then an uptime
+// line from procps uptime -p or the classic uptime of Linux, BSD, macOS and
+// busybox, then the ID= line of /etc/os-release. It reports false when the
+// uname line is missing.
+func ParseOSInfo(output string) (OSInfo, bool) {
+	var info OSInfo
+	var distro string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "ID="):
+			distro = strings.ToLower(strings.Trim(strings.TrimPrefix(line, "ID="), `"'`))
+		case info.System == "":
+			fields := strings.Fields(line)
+			info.System = fields[0]
+			if len(fields) > 1 {
+				info.Kernel = fields[1]
+			}
+		case !info.HasUptime:
+			info.Uptime, info.HasUptime = ParseUptime(line)
+		}
+	}
+	if info.System == "" {
+		return OSInfo{}, false
+	}
+
+	info.Family = distro
+	if info.Family == "" {
+		info.Family = strings.ToLower(info.System)
+		if info.Family == "darwin" {
+			info.Family = "macos"
+		}
+	}
+	return info, true
+}
+
+// uptimeUnits maps the units uptime prints to their length. Prefixes are matched,
+// so "min", "mins" and "minutes" all count.
+var uptimeUnits = []struct {
+	prefix string
+	unit   time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"week", 7 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"hr", time.Hour},
+	{"min", time.Minute},
+	{"sec", time.Second},
+}
+
+// ParseUptime reads how long a system has been up from a line of uptime output:
+// "up 2 weeks, 3 days, 4 hours" from uptime -p, or the classic
+// "10:15:01 up 3 days,  4:05,  2 users,  load average: ..." with its variants
+// ("up 45 min", "up  4:05", macOS "up 2 days, 3 hrs", busybox without users).
+func ParseUptime(line string) (time.Duration, bool) {
+	i := strings.Index(line, "up ")
+	if i < 0 || (i > 0 && line[i-1] != ' ') {
+		return 0, false
+	}
+
+	var uptime time.Duration
+	parsed := false
+	for _, part := range strings.Split(line[i+len("up "):], ",") {
+		part = strings.TrimSpace(part)
+		if d, ok := parseClock(part); ok {
+			uptime += d
+			parsed = true
+			continue
+		}
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			break
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			break
+		}
+		unit := time.Duration(0)
+		for _, u := range uptimeUnits {
+			if strings.HasPrefix(fields[1], u.prefix) {
+				unit = u.unit
+				break
+			}
+		}
+		if unit == 0 {
+			// "2 users" ends the uptime
+			break
+		}
+		uptime += time.Duration(n) * unit
+		parsed = true
+	}
+	return uptime, parsed
+}
+
+// parseClock reads the "H:MM" hours and minutes of a classic uptime line
+func parseClock(s string) (time.Duration, bool) {
+	hours, minutes, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, false
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 {
+		return 0, false
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, true
+}
+
+// OSCache keeps the OS info of each host in the cache directory
+type OSCache struct {
+	path  string
+	hosts map[string]OSInfo
+}
+
+// LoadOSCache reads the OS info cache. A missing or unreadable cache starts empty;
+// the cache can always be probed again.
+func LoadOSCache() (*OSCache, error) {
+	path, err := config.CacheFilePath(osCacheFile)
+	if err != nil {
+		return &OSCache{hosts: make(map[string]OSInfo)}, err
+	}
+	return loadOSCache(path), nil
+}
+
+func loadOSCache(path string) *OSCache {
+	cache := &OSCache{path: path, hosts: make(map[string]OSInfo)}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &cache.hosts); err != nil || cache.hosts == nil {
+			cache.hosts = make(map[string]OSInfo)
+		}
+	}
+	return cache
+}
+
+// Get returns the OS info of a host, however old
+func (c *OSCache) Get(hostName string) (OSInfo, bool) {
+	info, ok := c.hosts[hostName]
+	return info, ok
+}
+
+// Stale reports whether a host has no OS info or only info older than ttl at now
+func (c *OSCache) Stale(hostName string, now time.Time, ttl time.Duration) bool {
+	info, ok := c.hosts[hostName]
+	return !ok || now.Sub(info.CheckedAt) >= ttl
+}
+
+// Put stores the OS info of a host
+func (c *OSCache) Put(hostName string, info OSInfo) {
+	c.hosts[hostName] = info
+}
+
+// Save writes the cache. A cache loaded without a path is kept in memory only.
+func (c *OSCache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.hosts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
@@ -0,0 +1,116 @@
+package connectivity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseOSInfo(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name   string
+		output string
+		want   OSInfo
+	}{
+		{
+			name:   "ubuntu with procps",
+			output: "Linux 5.15.0-91-generic\nup 3 weeks, 2 days, 4 hours, 5 minutes\nID=ubuntu\n",
+			want:   OSInfo{Family: "ubuntu", System: "Linux", Kernel: "5.15.0-91-generic", Uptime: 23*day + 4*time.Hour + 5*time.Minute, HasUptime: true},
+		},
+		{
+			name:   "alpine with busybox",
+			output: "Linux 6.6.7-0-lts\n 10:15:01 up 12 days,  4:05,  load average: 0.00, 0.01, 0.05\nID=alpine\n",
+			want:   OSInfo{Family: "alpine", System: "Linux", Kernel: "6.6.7-0-lts", Uptime: 12*day + 4*time.Hour + 5*time.Minute, HasUptime: true},
+		},
+		{
+			name:   "busybox in its first hour",
+			output: "Linux 5.10.0\n 10:15:01 up 12 min,  load average: 0.00, 0.01, 0.05\nID=\"alpine\"\n",
+			want:   OSInfo{Family: "alpine", System: "Linux", Kernel: "5.10.0", Uptime: 12 * time.Minute, HasUptime: true},
+		},
+		{
+			name:   "rhel with quoted id",
+			output: "Linux 4.18.0-513.el8.x86_64\nup 1 year, 2 weeks\nID=\"rhel\"\n",
+			want:   OSInfo{Family: "rhel", System: "Linux", Kernel: "4.18.0-513.el8.x86_64", Uptime: 379 * day, HasUptime: true},
+		},
+		{
+			name:   "macOS",
+			output: "Darwin 23.1.0\n14:02  up 6 days,  2:41, 3 users, load averages: 1.50 1.62 1.70\n",
+			want:   OSInfo{Family: "macos", System: "Darwin", Kernel: "23.1.0", Uptime: 6*day + 2*time.Hour + 41*time.Minute, HasUptime: true},
+		},
+		{
+			name:   "macOS on whole hours",
+			output: "Darwin 22.6.0\n9:41  up 2 days, 3 hrs, 1 user, load averages: 2.01 1.80 1.75\n",
+			want:   OSInfo{Family: "macos", System: "Darwin", Kernel: "22.6.0", Uptime: 2*day + 3*time.Hour, HasUptime: true},
+		},
+		{
+			name:   "freebsd right after boot",
+			output: "FreeBSD 14.0-RELEASE\n 9:41AM  up 23 secs, 1 user, load averages: 0.31, 0.08, 0.03\n",
+			want:   OSInfo{Family: "freebsd", System: "FreeBSD", Kernel: "14.0-RELEASE", Uptime: 23 * time.Second, HasUptime: true},
+		},
+		{
+			name:   "classic linux under a day",
+			output: "Linux 3.10.0-1160.el7.x86_64\n 09:15:42 up  4:05,  1 user,  load average: 2.10, 1.95, 1.80\nID=\"centos\"\n",
+			want:   OSInfo{Family: "centos", System: "Linux", Kernel: "3.10.0-1160.el7.x86_64", Uptime: 4*time.Hour + 5*time.Minute, HasUptime: true},
+		},
+		{
+			name:   "classic linux with days and minutes",
+			output: "Linux 5.4.0\n 09:15:42 up 1 day, 45 min,  2 users,  load average: 0.10, 0.05, 0.01\n",
+			want:   OSInfo{Family: "linux", System: "Linux", Kernel: "5.4.0", Uptime: day + 45*time.Minute, HasUptime: true},
+		},
+		{
+			name:   "uptime missing",
+			output: "OpenBSD 7.4\n",
+			want:   OSInfo{Family: "openbsd", System: "OpenBSD", Kernel: "7.4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseOSInfo(tt.output)
+			if !ok {
+				t.Fatalf("ParseOSInfo() did not recognize the output")
+			}
+			if got != tt.want {
+				t.Errorf("ParseOSInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := ParseOSInfo("\n"); ok {
+		t.Error("ParseOSInfo() recognized empty output")
+	}
+}
+
+func TestParseUptimeRejectsOtherLines(t *testing.T) {
+	for _, line := range []string{"", "load average: 0.00", "setup 3 days", "up", "up soon"} {
+		if d, ok := ParseUptime(line); ok {
+			t.Errorf("ParseUptime(%q) = %v, want no uptime", line, d)
+		}
+	}
+}
+
+func TestOSCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), osCacheFile)
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	cache := loadOSCache(path)
+	if !cache.Stale("web", now, DefaultOSInfoTTL) {
+		t.Error("A host never probed is not stale")
+	}
+	cache.Put("web", OSInfo{Family: "ubuntu", System: "Linux", CheckedAt: now.Add(-time.Hour)})
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := loadOSCache(path)
+	if info, ok := reloaded.Get("web"); !ok || info.Family != "ubuntu" || !info.CheckedAt.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("Get() after reload = %+v, %v", info, ok)
+	}
+	if reloaded.Stale("web", now, DefaultOSInfoTTL) {
+		t.Error("An hour old entry is stale with a day's TTL")
+	}
+	if !reloaded.Stale("web", now, time.Hour) {
+		t.Error("An hour old entry is fresh with an hour's TTL")
+	}
+}
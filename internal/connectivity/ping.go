@@ -43,19 +43,80 @@ type HostPingResult struct {
 	Duration time.Duration
 }
 
+// DefaultMaxConcurrentPings is how many hosts are probed at once unless configured otherwise
+const DefaultMaxConcurrentPings = 16
+
 // PingManager manages SSH connectivity checks for multiple hosts
 type PingManager struct {
 	results map[string]*HostPingResult
 	mutex   sync.RWMutex
 	timeout time.Duration
+	slots   chan struct{} // Probes wait here once the concurrency limit is reached
+
+	// probe checks a single address; replaced in tests
+	probe func(ctx context.Context, host config.SSHHost) (PingStatus, error)
 }
 
 // NewPingManager creates a new ping manager with the specified timeout
 func NewPingManager(timeout time.Duration) *PingManager {
-	return &PingManager{
+	pm := &PingManager{
 		results: make(map[string]*HostPingResult),
 		timeout: timeout,
+		slots:   make(chan struct{}, DefaultMaxConcurrentPings),
+	}
+	pm.probe = pm.dial
+	return pm
+}
+
+// SetMaxConcurrent limits how many probes run at once; n <= 0 restores the default.
+// It must be called before any pings start.
+func (pm *PingManager) SetMaxConcurrent(n int) {
+	if n <= 0 {
+		n = DefaultMaxConcurrentPings
 	}
+	pm.slots = make(chan struct{}, n)
+}
+
+// PingTarget returns the address a host is probed at, so aliases of one server share a probe
+func PingTarget(host config.SSHHost) string {
+	return net.JoinHostPort(strings.ToLower(host.CanonicalTarget()), pingPort(host))
+}
+
+// pingPort returns the port of a host, defaulting to 22
+func pingPort(host config.SSHHost) string {
+	port := host.Port
+	if port == "" {
+		port = "22"
+	}
+	return port
+}
+
+// resolveAddress returns the address to dial for a host. With hostname canonicalization,
+// the first candidate name that resolves is used, like ssh does.
+func resolveAddress(ctx context.Context, host config.SSHHost) string {
+	names := host.CanonicalHostnames()
+	for _, name := range names[:len(names)-1] {
+		if _, err := net.DefaultResolver.LookupHost(ctx, name); err == nil {
+			return net.JoinHostPort(name, pingPort(host))
+		}
+	}
+	return net.JoinHostPort(names[len(names)-1], pingPort(host))
+}
+
+// GroupByTarget groups hosts by their ping target, in order of first appearance
+func GroupByTarget(hosts []config.SSHHost) [][]config.SSHHost {
+	var groups [][]config.SSHHost
+	index := make(map[string]int)
+	for _, host := range hosts {
+		target := PingTarget(host)
+		if i, ok := index[target]; ok {
+			groups[i] = append(groups[i], host)
+			continue
+		}
+		index[target] = len(groups)
+		groups = append(groups, []config.SSHHost{host})
+	}
+	return groups
 }
 
 // GetStatus returns the current status for a host
@@ -93,38 +154,67 @@ func (pm *PingManager) updateStatus(hostName string, status PingStatus, err erro
 
 // PingHost performs an SSH connectivity check for a single host
 func (pm *PingManager) PingHost(ctx context.Context, host config.SSHHost) *HostPingResult {
-	start := time.Now()
-
 	// Mark as connecting
 	pm.updateStatus(host.Name, StatusConnecting, nil, 0)
 
-	// Determine the actual hostname and port
-	hostname := host.Hostname
-	if hostname == "" {
-		hostname = host.Name
+	// Wait for a free slot
+	select {
+	case pm.slots <- struct{}{}:
+		defer func() { <-pm.slots }()
+	case <-ctx.Done():
+		pm.updateStatus(host.Name, StatusOffline, ctx.Err(), 0)
+		return &HostPingResult{HostName: host.Name, Status: StatusOffline, Error: ctx.Err()}
 	}
 
-	port := host.Port
-	if port == "" {
-		port = "22"
+	start := time.Now()
+	status, err := pm.probe(ctx, host)
+	duration := time.Since(start)
+
+	pm.updateStatus(host.Name, status, err, duration)
+	return &HostPingResult{
+		HostName: host.Name,
+		Status:   status,
+		Error:    err,
+		Duration: duration,
 	}
+}
+
+// PingGroup probes hosts sharing a ping target once and records the result for all of them
+func (pm *PingManager) PingGroup(ctx context.Context, hosts []config.SSHHost) []*HostPingResult {
+	if len(hosts) == 0 {
+		return nil
+	}
+	for _, host := range hosts[1:] {
+		pm.updateStatus(host.Name, StatusConnecting, nil, 0)
+	}
+
+	first := pm.PingHost(ctx, hosts[0])
+	results := []*HostPingResult{first}
+	for _, host := range hosts[1:] {
+		pm.updateStatus(host.Name, first.Status, first.Error, first.Duration)
+		results = append(results, &HostPingResult{
+			HostName: host.Name,
+			Status:   first.Status,
+			Error:    first.Error,
+			Duration: first.Duration,
+		})
+	}
+	return results
+}
 
+// dial checks whether the host accepts TCP connections and speaks SSH
+func (pm *PingManager) dial(ctx context.Context, host config.SSHHost) (PingStatus, error) {
 	// Create context with timeout
 	pingCtx, cancel := context.WithTimeout(ctx, pm.timeout)
 	defer cancel()
 
+	address := resolveAddress(pingCtx, host)
+
 	// Try to establish a TCP connection first (faster than SSH handshake)
 	dialer := &net.Dialer{}
-	conn, err := dialer.DialContext(pingCtx, "tcp", net.JoinHostPort(hostname, port))
+	conn, err := dialer.DialContext(pingCtx, "tcp", address)
 	if err != nil {
-		duration := time.Since(start)
-		pm.updateStatus(host.Name, StatusOffline, err, duration)
-		return &HostPingResult{
-			HostName: host.Name,
-			Status:   StatusOffline,
-			Error:    err,
-			Duration: duration,
-		}
+		return StatusOffline, err
 	}
 	defer conn.Close()
 
@@ -136,46 +226,37 @@ func (pm *PingManager) PingHost(ctx context.Context, host config.SSHHost) *HostP
 	}
 
 	// We don't need to authenticate, just check if SSH is responding
-	sshConn, _, _, err := ssh.NewClientConn(conn, net.JoinHostPort(hostname, port), sshConfig)
+	sshConn, _, _, err := ssh.NewClientConn(conn, address, sshConfig)
 	if sshConn != nil {
 		sshConn.Close()
 	}
 
-	duration := time.Since(start)
-
 	// Even if SSH handshake fails, if we got a TCP connection, consider it online
 	// This handles cases where authentication fails but the host is reachable
-	status := StatusOnline
 	if err != nil && isConnectionError(err) {
-		status = StatusOffline
-	}
-
-	pm.updateStatus(host.Name, status, err, duration)
-	return &HostPingResult{
-		HostName: host.Name,
-		Status:   status,
-		Error:    err,
-		Duration: duration,
+		return StatusOffline, err
 	}
+	return StatusOnline, err
 }
 
-// PingAllHosts pings all hosts concurrently and returns a channel of results
+// PingAllHosts pings all hosts concurrently, once per ping target, and returns a channel of results
 func (pm *PingManager) PingAllHosts(ctx context.Context, hosts []config.SSHHost) <-chan *HostPingResult {
 	resultChan := make(chan *HostPingResult, len(hosts))
 
 	var wg sync.WaitGroup
 
-	for _, host := range hosts {
+	for _, group := range GroupByTarget(hosts) {
 		wg.Add(1)
-		go func(h config.SSHHost) {
+		go func(g []config.SSHHost) {
 			defer wg.Done()
-			result := pm.PingHost(ctx, h)
-			select {
-			case resultChan <- result:
-			case <-ctx.Done():
-				return
+			for _, result := range pm.PingGroup(ctx, g) {
+				select {
+				case resultChan <- result:
+				case <-ctx.Done():
+					return
+				}
 			}
-		}(host)
+		}(group)
 	}
 
 	// Close the channel when all goroutines are done
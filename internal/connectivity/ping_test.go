@@ -2,6 +2,8 @@ package connectivity
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -141,4 +143,111 @@ func TestPingHost_Basic(t *testing.T) {
 	if status == StatusUnknown {
 		t.Error("Expected status to be set after ping attempt")
 	}
-}
\ No newline at end of file
+}
+
+func TestPingAllHostsProbesEachTargetOnce(t *testing.T) {
+	pm := NewPingManager(1 * time.Second)
+
+	var mu sync.Mutex
+	probed := make(map[string]int)
+	pm.probe = func(ctx context.Context, host config.SSHHost) (PingStatus, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		probed[PingTarget(host)]++
+		if host.Hostname == "10.0.0.3" {
+			return StatusOffline, nil
+		}
+		return StatusOnline, nil
+	}
+
+	hosts := []config.SSHHost{
+		{Name: "web", Hostname: "10.0.0.1"},
+		{Name: "web-alias", Hostname: "10.0.0.1", Port: "22"},
+		{Name: "web-admin", Hostname: "10.0.0.1", User: "admin"},
+		{Name: "web-2", Hostname: "10.0.0.1"},
+		{Name: "db", Hostname: "10.0.0.2"},
+		{Name: "db-ro", Hostname: "10.0.0.2"},
+		{Name: "db-rw", Hostname: "10.0.0.2"},
+		{Name: "old", Hostname: "10.0.0.3"},
+		{Name: "old-2", Hostname: "10.0.0.3"},
+		{Name: "old-3", Hostname: "10.0.0.3"},
+	}
+
+	var results int
+	for range pm.PingAllHosts(context.Background(), hosts) {
+		results++
+	}
+
+	if len(probed) != 3 {
+		t.Errorf("Expected 3 targets to be probed, got %v", probed)
+	}
+	for target, n := range probed {
+		if n != 1 {
+			t.Errorf("Expected %s to be probed once, got %d", target, n)
+		}
+	}
+	if results != len(hosts) {
+		t.Errorf("Expected a result for each of the %d hosts, got %d", len(hosts), results)
+	}
+	if got := pm.GetStatus("old-3"); got != StatusOffline {
+		t.Errorf("Expected old-3 to share the offline result, got %v", got)
+	}
+	if got := pm.GetStatus("db-rw"); got != StatusOnline {
+		t.Errorf("Expected db-rw to share the online result, got %v", got)
+	}
+}
+
+func TestPingHostRespectsConcurrencyLimit(t *testing.T) {
+	pm := NewPingManager(1 * time.Second)
+	pm.SetMaxConcurrent(2)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	pm.probe = func(ctx context.Context, host config.SSHHost) (PingStatus, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return StatusOnline, nil
+	}
+
+	var hosts []config.SSHHost
+	for i := range 8 {
+		hosts = append(hosts, config.SSHHost{Name: fmt.Sprintf("h%d", i), Hostname: fmt.Sprintf("10.0.1.%d", i)})
+	}
+	for range pm.PingAllHosts(context.Background(), hosts) {
+	}
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 probes at once, got %d", peak)
+	}
+	for _, host := range hosts {
+		if got := pm.GetStatus(host.Name); got != StatusOnline {
+			t.Errorf("Expected %s to be pinged after waiting, got %v", host.Name, got)
+		}
+	}
+}
+
+func TestPingTargetUsesCanonicalName(t *testing.T) {
+	db := config.SSHHost{
+		Name:                 "db",
+		Hostname:             "db1",
+		CanonicalizeHostname: "yes",
+		CanonicalDomains:     []string{"internal.example.com"},
+	}
+	if got := PingTarget(db); got != "db1.internal.example.com:22" {
+		t.Errorf("PingTarget() = %q, want the canonical name", got)
+	}
+
+	// A host spelled out in full shares the probe
+	full := config.SSHHost{Name: "db-full", Hostname: "db1.internal.example.com"}
+	if groups := GroupByTarget([]config.SSHHost{db, full}); len(groups) != 1 {
+		t.Errorf("Expected one ping group, got %d", len(groups))
+	}
+}
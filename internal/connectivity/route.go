@@ -0,0 +1,150 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+// BastionTag marks the hosts FindRoute tries as jump hosts
+const BastionTag = "bastion"
+
+// DefaultRouteConcurrency is how many routes FindRoute probes at once
+const DefaultRouteConcurrency = 4
+
+// routeProbeTimeout bounds the probe of one route
+const routeProbeTimeout = 8 * time.Second
+
+// ErrNoRoute is returned by FindRoute when no route reached the host
+var ErrNoRoute = errors.New("not reachable directly or through any bastion")
+
+// RouteTarget is a host being added, which is not in the config yet
+type RouteTarget struct {
+	Hostname string
+	User     string
+	Port     string
+}
+
+// RouteProbe checks whether the target answers through bastion, or directly when
+// bastion is ""
+type RouteProbe func(ctx context.Context, bastion string) error
+
+// FindRoute probes the target directly and through each of bastions, at most
+// concurrency at a time, starting with the direct route and then the bastions in
+// order. Of the routes that work, the first in that order wins, however fast the
+// others answered: it is returned as the bastion to jump through, "" meaning
+// direct, and the probes of the routes after it are canceled. FindRoute returns
+// only after every probe it started has returned.
+func FindRoute(ctx context.Context, bastions []string, probe RouteProbe, concurrency int) (string, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultRouteConcurrency
+	}
+
+	routes := append([]string{""}, bastions...)
+	cancels := make([]context.CancelFunc, len(routes))
+	best := len(routes) // Index of the first route that worked so far
+	var mu sync.Mutex
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, route := range routes {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		mu.Lock()
+		if ctx.Err() != nil || best < i {
+			mu.Unlock()
+			break
+		}
+		routeCtx, cancel := context.WithCancel(ctx)
+		cancels[i] = cancel
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(i int, route string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer cancel()
+			if probe(routeCtx, route) != nil || routeCtx.Err() != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if i < best {
+				best = i
+				for _, later := range cancels[i+1:] {
+					if later != nil {
+						later()
+					}
+				}
+			}
+		}(i, route)
+	}
+	wg.Wait()
+
+	if best < len(routes) {
+		return routes[best], nil
+	}
+	if err := ctx.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return "", err
+	}
+	return "", ErrNoRoute
+}
+
+// SSHRouteProbe returns a RouteProbe that runs ssh in BatchMode against target,
+// with -J for a bastion. The bastion is read from configFile like any host.
+func SSHRouteProbe(target RouteTarget, configFile string) RouteProbe {
+	return func(ctx context.Context, bastion string) error {
+		ctx, cancel := context.WithTimeout(ctx, routeProbeTimeout)
+		defer cancel()
+
+		args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5", "-T"}
+		args = append(args, sshver.NoRemoteCommandArgs()...)
+		if configFile != "" {
+			args = append([]string{"-F", configFile}, args...)
+		}
+		if bastion != "" {
+			args = append(args, "-J", bastion)
+		}
+		if target.Port != "" {
+			args = append(args, "-p", target.Port)
+		}
+		if target.User != "" {
+			args = append(args, "-l", target.User)
+		}
+		args = append(args, "--", target.Hostname, "true")
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "ssh", args...)
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case serverAnswered(stderr.String()) && !(bastion != "" && jumpFailed(stderr.String())):
+			return nil
+		}
+		return sshFailure(stderr.String(), err)
+	}
+}
+
+// jumpFailed reports whether ssh's errors show the bastion itself refused the
+// login or could not reach the target, which also prints "Permission denied"
+func jumpFailed(stderr string) bool {
+	for _, marker := range []string{"UNKNOWN port 65535", "stdio forwarding failed", "channel 0: open failed"} {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
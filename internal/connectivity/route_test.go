@@ -0,0 +1,128 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubRoutes answers each route after a delay, successfully when its error is nil,
+// and records which probes ran and were canceled
+type stubRoutes struct {
+	delays map[string]time.Duration
+	errs   map[string]error
+
+	mu       sync.Mutex
+	started  []string
+	canceled []string
+	running  atomic.Int32
+	peak     atomic.Int32
+}
+
+func (s *stubRoutes) probe(ctx context.Context, bastion string) error {
+	s.mu.Lock()
+	s.started = append(s.started, bastion)
+	s.mu.Unlock()
+	if n := s.running.Add(1); n > s.peak.Load() {
+		s.peak.Store(n)
+	}
+	defer s.running.Add(-1)
+
+	select {
+	case <-time.After(s.delays[bastion]):
+		return s.errs[bastion]
+	case <-ctx.Done():
+		s.mu.Lock()
+		s.canceled = append(s.canceled, bastion)
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func TestFindRouteFirstRouteInOrderWins(t *testing.T) {
+	fail := errors.New("Permission denied")
+	stub := &stubRoutes{
+		delays: map[string]time.Duration{"": time.Millisecond, "slow": 20 * time.Millisecond, "broken": time.Millisecond, "fast": time.Millisecond, "hung": time.Hour},
+		errs:   map[string]error{"": fail, "broken": fail},
+	}
+	route, err := FindRoute(context.Background(), []string{"slow", "broken", "fast", "hung"}, stub.probe, 5)
+	if err != nil || route != "slow" {
+		t.Fatalf("FindRoute() = %q, %v, want slow, which comes before fast", route, err)
+	}
+	if stub.running.Load() != 0 {
+		t.Error("Expected every probe to have returned")
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if len(stub.canceled) != 1 || stub.canceled[0] != "hung" {
+		t.Errorf("canceled = %q, want only the hung probe after a working route", stub.canceled)
+	}
+}
+
+func TestFindRoutePrefersDirectWhenItAnswersFirst(t *testing.T) {
+	stub := &stubRoutes{delays: map[string]time.Duration{"": time.Millisecond, "bastion": time.Hour}}
+	route, err := FindRoute(context.Background(), []string{"bastion"}, stub.probe, 2)
+	if err != nil || route != "" {
+		t.Errorf("FindRoute() = %q, %v, want the direct route", route, err)
+	}
+}
+
+func TestFindRouteBoundsConcurrencyInOrder(t *testing.T) {
+	fail := errors.New("timed out")
+	stub := &stubRoutes{
+		delays: map[string]time.Duration{"": 2 * time.Millisecond, "a": 2 * time.Millisecond, "b": 2 * time.Millisecond, "c": 2 * time.Millisecond},
+		errs:   map[string]error{"": fail, "a": fail, "b": fail, "c": fail},
+	}
+	_, err := FindRoute(context.Background(), []string{"a", "b", "c"}, stub.probe, 1)
+	if !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("FindRoute() error = %v, want ErrNoRoute", err)
+	}
+	if stub.peak.Load() != 1 {
+		t.Errorf("Peak concurrency = %d, want 1", stub.peak.Load())
+	}
+	if got := stub.started; len(got) != 4 || got[0] != "" || got[1] != "a" || got[3] != "c" {
+		t.Errorf("started = %q, want direct first and then the bastions in order", got)
+	}
+}
+
+func TestFindRouteCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stub := &stubRoutes{delays: map[string]time.Duration{"": time.Hour, "a": time.Hour, "b": time.Hour}}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := FindRoute(ctx, []string{"a", "b"}, stub.probe, 2)
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, ErrNoRoute) {
+			t.Errorf("FindRoute() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("FindRoute() did not return after cancel")
+	}
+	if stub.running.Load() != 0 {
+		t.Error("Expected every probe to have returned")
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if len(stub.started) != 2 {
+		t.Errorf("started = %q, want only the two probes that fit before canceling", stub.started)
+	}
+}
+
+func TestJumpFailed(t *testing.T) {
+	if !jumpFailed("Permission denied (publickey).\r\nConnection closed by UNKNOWN port 65535") {
+		t.Error("Expected a refused bastion login to count as a failed jump")
+	}
+	if jumpFailed("deploy@10.0.0.5: Permission denied (publickey).") {
+		t.Error("Expected a refused target login to count as reached")
+	}
+}
@@ -0,0 +1,164 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+// DefaultWaitTimeout is how long WaitUntil keeps probing a host by default
+const DefaultWaitTimeout = 10 * time.Minute
+
+// Backoff sets the delays between the attempts of WaitUntil, doubling from
+// Initial up to Max
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// DefaultBackoff is used by WaitUntil when no delays are given
+var DefaultBackoff = Backoff{Initial: time.Second, Max: 15 * time.Second}
+
+// waitProbeTimeout bounds one attempt of a host probe
+const waitProbeTimeout = 10 * time.Second
+
+// ErrWaitTimeout is returned when a host did not become ready before the deadline
+var ErrWaitTimeout = errors.New("host did not become reachable in time")
+
+// WaitProgress reports a failed attempt of WaitUntil
+type WaitProgress struct {
+	Attempt int
+	Elapsed time.Duration
+	Err     error         // Why the attempt failed
+	Next    time.Duration // Delay before the next attempt
+}
+
+// WaitUntil runs probe with exponential backoff until it succeeds, ctx is done or
+// timeout has passed, and reports each failed attempt to progress. A zero backoff
+// uses DefaultBackoff. The probe gets a context that is canceled with ctx, so
+// canceling returns promptly. It returns nil once the probe succeeded,
+// ErrWaitTimeout or ctx.Err().
+func WaitUntil(ctx context.Context, probe func(context.Context) error, timeout time.Duration, backoff Backoff, progress func(WaitProgress)) error {
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+	if backoff.Initial <= 0 {
+		backoff = DefaultBackoff
+	}
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := backoff.Initial
+	for attempt := 1; ; attempt++ {
+		err := probe(waitCtx)
+		if err == nil {
+			return nil
+		}
+		if waitCtx.Err() != nil {
+			return waitError(ctx)
+		}
+
+		next := min(delay, timeout-time.Since(start))
+		if progress != nil {
+			progress(WaitProgress{Attempt: attempt, Elapsed: time.Since(start), Err: err, Next: next})
+		}
+
+		timer := time.NewTimer(next)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			return waitError(ctx)
+		case <-timer.C:
+		}
+		delay = min(delay*2, max(backoff.Max, backoff.Initial))
+	}
+}
+
+// waitError tells a cancellation of ctx from the wait running out of time
+func waitError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrWaitTimeout
+}
+
+// HostProbe returns a probe for WaitUntil that succeeds once the host's sshd
+// answers: its port must accept TCP connections, then an ssh BatchMode login must
+// get as far as the server. Hosts behind a jump host skip the TCP check.
+func HostProbe(host config.SSHHost, configFile string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, waitProbeTimeout)
+		defer cancel()
+
+		if !host.IsProxied() {
+			dialer := &net.Dialer{}
+			conn, err := dialer.DialContext(ctx, "tcp", resolveAddress(ctx, host))
+			if err != nil {
+				return err
+			}
+			conn.Close()
+		}
+		return sshAnswers(ctx, host.Name, configFile)
+	}
+}
+
+// sshAnswers runs ssh in BatchMode against the host. A refused login still means
+// sshd is up, and so does a changed host key, common on a freshly built machine.
+func sshAnswers(ctx context.Context, hostName, configFile string) error {
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=5", "-T"}
+	args = append(args, sshver.NoRemoteCommandArgs()...)
+	if configFile != "" {
+		args = append([]string{"-F", configFile}, args...)
+	}
+	args = append(args, hostName, "true")
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case serverAnswered(stderr.String()):
+		return nil
+	}
+	return sshFailure(stderr.String(), err)
+}
+
+// serverAnswered reports whether ssh's errors show it reached the server
+func serverAnswered(stderr string) bool {
+	for _, marker := range []string{"Permission denied", "Host key verification failed", "REMOTE HOST IDENTIFICATION HAS CHANGED"} {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientFailed reports whether ssh's errors show that it failed itself, when it
+// could not connect or lost the connection, and not that a remote command exited
+// with 255, which ssh passes on as its own exit status
+func ClientFailed(stderr string) bool {
+	for _, marker := range []string{
+		"ssh: connect to host", "Could not resolve hostname", "kex_exchange_identification",
+		"Connection timed out", "Connection refused", "Connection closed by", "Connection reset by",
+		"closed by remote host", "Timeout, server", "client_loop:", "No route to host",
+		"Network is unreachable", "Broken pipe",
+	} {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
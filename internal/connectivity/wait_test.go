@@ -0,0 +1,131 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// shortBackoff makes WaitUntil back off in milliseconds for the tests
+var shortBackoff = Backoff{Initial: time.Millisecond, Max: 4 * time.Millisecond}
+
+func TestWaitUntilBacksOff(t *testing.T) {
+	attempts := 0
+	probe := func(ctx context.Context) error {
+		attempts++
+		if attempts < 5 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	var reports []WaitProgress
+	err := WaitUntil(context.Background(), probe, time.Minute, shortBackoff, func(p WaitProgress) { reports = append(reports, p) })
+	if err != nil {
+		t.Fatalf("WaitUntil() error = %v", err)
+	}
+	if attempts != 5 || len(reports) != 4 {
+		t.Fatalf("Got %d attempts and %d reports, want 5 and 4", attempts, len(reports))
+	}
+
+	wantDelays := []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond}
+	for i, report := range reports {
+		if report.Attempt != i+1 || report.Next != wantDelays[i] || report.Err == nil {
+			t.Errorf("Report %d = %+v, want attempt %d and next %v", i, report, i+1, wantDelays[i])
+		}
+	}
+}
+
+func TestWaitUntilTimesOut(t *testing.T) {
+	probe := func(ctx context.Context) error { return errors.New("no route to host") }
+	err := WaitUntil(context.Background(), probe, 20*time.Millisecond, shortBackoff, nil)
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("WaitUntil() error = %v, want ErrWaitTimeout", err)
+	}
+}
+
+func TestWaitUntilCancelsBlockedProbe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	probing := make(chan struct{})
+	probeDone := make(chan struct{})
+	probe := func(ctx context.Context) error {
+		defer close(probeDone)
+		close(probing)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- WaitUntil(ctx, probe, time.Hour, Backoff{}, nil) }()
+	<-probing
+	cancel()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("WaitUntil() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitUntil() did not return after cancel")
+	}
+	select {
+	case <-probeDone:
+	default:
+		t.Error("Expected the probe to have returned")
+	}
+}
+
+func TestWaitUntilCancelsBackoff(t *testing.T) {
+	// The real delays: cancel has to cut the wait for the next attempt short
+	ctx, cancel := context.WithCancel(context.Background())
+	probe := func(ctx context.Context) error { return errors.New("connection refused") }
+	progress := func(WaitProgress) { cancel() }
+
+	start := time.Now()
+	err := WaitUntil(ctx, probe, time.Hour, Backoff{}, progress)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WaitUntil() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("WaitUntil() took %v after cancel", elapsed)
+	}
+}
+
+func TestServerAnswered(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{"deploy@10.0.0.5: Permission denied (publickey).", true},
+		{"Host key verification failed.", true},
+		{"@    WARNING: REMOTE HOST IDENTIFICATION HAS CHANGED!     @", true},
+		{"ssh: connect to host 10.0.0.5 port 22: Connection refused", false},
+		{"kex_exchange_identification: read: Connection reset by peer", false},
+	}
+	for _, tt := range tests {
+		if got := serverAnswered(tt.stderr); got != tt.want {
+			t.Errorf("serverAnswered(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestClientFailed(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{"ssh: connect to host 10.0.0.5 port 22: Connection timed out", true},
+		{"ssh: Could not resolve hostname web: Name or service not known", true},
+		{"Timeout, server 10.0.0.5 not responding.", true},
+		{"client_loop: send disconnect: Broken pipe", true},
+		{"Connection to 10.0.0.5 closed by remote host.", true},
+		{"", false},
+		{"Connection to 10.0.0.5 closed.", false},
+		{"make: *** [deploy] Error 255", false},
+	}
+	for _, tt := range tests {
+		if got := ClientFailed(tt.stderr); got != tt.want {
+			t.Errorf("ClientFailed(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package contactsheet renders hosts as a printable reference in Markdown or HTML.
+// Output depends only on the hosts given, so it can be committed without diff noise.
+package contactsheet
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// Ways to group hosts
+const (
+	GroupByTag  = "tag"
+	GroupByFile = "file"
+)
+
+// untaggedGroup collects hosts without tags when grouping by tag
+const untaggedGroup = "Untagged"
+
+// maxOptionLength is how much of the first option is shown before it is shortened
+const maxOptionLength = 40
+
+// Options select and group the hosts on the sheet
+type Options struct {
+	Tag     string // Only list hosts with this tag
+	GroupBy string // GroupByTag or GroupByFile
+	Home    string // Shown as ~ in source file paths
+}
+
+// Colors are the theme colors inlined in the HTML variant
+type Colors struct {
+	Background string
+	Foreground string
+	Primary    string
+	Accent     string
+	Muted      string
+}
+
+// Group is a titled set of hosts, sorted by name
+type Group struct {
+	Title string
+	Rows  []Row
+}
+
+// Row is one host as shown on the sheet
+type Row struct {
+	Name        string
+	Hostname    string
+	User        string
+	Port        string
+	Jump        string
+	Tags        string
+	Options     string
+	Description string
+}
+
+// columns are the table headers, in the order of the Row fields
+var columns = []string{"Name", "Hostname", "User", "Port", "Jump", "Tags", "Options", "Description"}
+
+func (r Row) cells() []string {
+	return []string{r.Name, r.Hostname, r.User, r.Port, r.Jump, r.Tags, r.Options, r.Description}
+}
+
+// Groups selects the hosts matching opts and groups them. Groups are sorted by
+// title with untagged hosts last, and a host with several tags is listed under each.
+func Groups(hosts []config.SSHHost, opts Options) []Group {
+	byTitle := make(map[string][]Row)
+	for _, host := range hosts {
+		if opts.Tag != "" && !host.HasTag(opts.Tag) {
+			continue
+		}
+		row := newRow(host)
+
+		if opts.GroupBy == GroupByFile {
+			title := displayPath(host.SourceFile, opts.Home)
+			byTitle[title] = append(byTitle[title], row)
+			continue
+		}
+
+		if len(host.Tags) == 0 {
+			byTitle[untaggedGroup] = append(byTitle[untaggedGroup], row)
+			continue
+		}
+		for _, tag := range host.Tags {
+			// Filtering by a tag shows that tag's group only
+			if opts.Tag != "" && !strings.EqualFold(tag, opts.Tag) {
+				continue
+			}
+			byTitle[tag] = append(byTitle[tag], row)
+		}
+	}
+
+	groups := make([]Group, 0, len(byTitle))
+	for title, rows := range byTitle {
+		sort.Slice(rows, func(i, j int) bool { return lessRow(rows[i], rows[j]) })
+		groups = append(groups, Group{Title: title, Rows: rows})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].Title, groups[j].Title
+		if (a == untaggedGroup) != (b == untaggedGroup) {
+			return b == untaggedGroup
+		}
+		return lessFold(a, b)
+	})
+	return groups
+}
+
+// Markdown renders the groups as a Markdown document with one table per group
+func Markdown(groups []Group) string {
+	var b strings.Builder
+	b.WriteString("# SSH hosts\n")
+	for _, group := range groups {
+		fmt.Fprintf(&b, "\n## %s\n\n", escapeMarkdown(group.Title))
+		b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+		for _, row := range group.Rows {
+			cells := row.cells()
+			for i, cell := range cells {
+				cells[i] = escapeMarkdown(cell)
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	return b.String()
+}
+
+var htmlTemplate = template.Must(template.New("sheet").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SSH hosts</title>
+<style>
+body { background: {{.Colors.Background}}; color: {{.Colors.Foreground}}; font-family: ui-monospace, Menlo, Consolas, monospace; margin: 2em; }
+h1, h2 { color: {{.Colors.Primary}}; }
+table { border-collapse: collapse; margin-bottom: 2em; width: 100%; }
+th { color: {{.Colors.Accent}}; text-align: left; border-bottom: 2px solid {{.Colors.Primary}}; }
+th, td { padding: 0.3em 0.8em; vertical-align: top; }
+td { border-bottom: 1px solid {{.Colors.Muted}}; }
+@media print { body { background: #FFFFFF; color: #000000; } }
+</style>
+</head>
+<body>
+<h1>SSH hosts</h1>
+{{- range .Groups}}
+<h2>{{.Title}}</h2>
+<table>
+<tr>{{range $.Columns}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlGroup struct {
+	Title string
+	Rows  []htmlRow
+}
+
+type htmlRow struct {
+	Cells []string
+}
+
+// HTML renders the groups as a standalone page styled with colors
+func HTML(groups []Group, colors Colors) (string, error) {
+	data := struct {
+		Colors  Colors
+		Columns []string
+		Groups  []htmlGroup
+	}{Colors: colors, Columns: columns}
+	for _, group := range groups {
+		g := htmlGroup{Title: group.Title}
+		for _, row := range group.Rows {
+			g.Rows = append(g.Rows, htmlRow{Cells: row.cells()})
+		}
+		data.Groups = append(data.Groups, g)
+	}
+
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func newRow(host config.SSHHost) Row {
+	return Row{
+		Name:        host.Name,
+		Hostname:    host.Hostname,
+		User:        host.User,
+		Port:        host.Port,
+		Jump:        host.ProxyJump,
+		Tags:        strings.Join(host.Tags, ", "),
+		Options:     summarizeOptions(host.Options),
+		Description: host.Description,
+	}
+}
+
+// summarizeOptions shows the first option and counts the others, so a host with a
+// long list of options does not stretch the table
+func summarizeOptions(options string) string {
+	var lines []string
+	for _, line := range strings.Split(options, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	first := lines[0]
+	if runes := []rune(first); len(runes) > maxOptionLength {
+		first = string(runes[:maxOptionLength-1]) + "…"
+	}
+	if len(lines) > 1 {
+		first += fmt.Sprintf(" (+%d more)", len(lines)-1)
+	}
+	return first
+}
+
+// displayPath shows path relative to home as ~/..., which keeps the sheet the same
+// for everyone sharing it
+func displayPath(path, home string) string {
+	if path == "" {
+		return "(unknown file)"
+	}
+	if home != "" {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(filepath.Join("~", rel))
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// lessFold orders strings case-insensitively, falling back to the exact strings so
+// the order never depends on the input order
+func lessFold(a, b string) bool {
+	if la, lb := strings.ToLower(a), strings.ToLower(b); la != lb {
+		return la < lb
+	}
+	return a < b
+}
+
+func lessRow(a, b Row) bool {
+	if a.Name != b.Name {
+		return lessFold(a.Name, b.Name)
+	}
+	// Hosts of the same name from different files
+	return strings.Join(a.cells(), "\x00") < strings.Join(b.cells(), "\x00")
+}
+
+// escapeMarkdown keeps a value inside its table cell
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,69 @@
+package contactsheet
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares output with testdata/name
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Output does not match %s:\n%s", path, got)
+	}
+}
+
+func testHosts() []config.SSHHost {
+	return []config.SSHHost{
+		{Name: "web2", Hostname: "web2.example.com", User: "deploy", Port: "22", Tags: []string{"prod", "web"}, SourceFile: "/home/me/.ssh/config", Description: "Second front end"},
+		{Name: "bastion", Hostname: "203.0.113.10", User: "ops", Port: "2222", Tags: []string{"prod"}, SourceFile: "/home/me/.ssh/config"},
+		{Name: "db", Hostname: "db.internal", User: "postgres", Port: "22", ProxyJump: "bastion", Tags: []string{"prod"}, SourceFile: "/home/me/.ssh/conf.d/db",
+			Options: "LocalForward 5432 localhost:5432\nServerAliveInterval 30\nServerAliveCountMax 5", Description: "Primary | replica"},
+		{Name: "Web1", Hostname: "web1.example.com", User: "deploy", Port: "22", Tags: []string{"web", "prod"}, SourceFile: "/home/me/.ssh/config"},
+		{Name: "scratch", Hostname: "10.0.0.5", Port: "22", SourceFile: "/etc/ssh/ssh_config.d/lab",
+			Options: "SetEnv GREETING=hello-from-a-very-long-option-value"},
+	}
+}
+
+func TestMarkdownGroupedByTag(t *testing.T) {
+	hosts := testHosts()
+	got := Markdown(Groups(hosts, Options{GroupBy: GroupByTag, Home: "/home/me"}))
+	assertGolden(t, "by_tag.md", got)
+
+	// Input order does not change the output
+	slices.Reverse(hosts)
+	if again := Markdown(Groups(hosts, Options{GroupBy: GroupByTag, Home: "/home/me"})); again != got {
+		t.Errorf("Expected the same sheet for reordered hosts, got:\n%s", again)
+	}
+}
+
+func TestMarkdownGroupedByFileWithTag(t *testing.T) {
+	got := Markdown(Groups(testHosts(), Options{Tag: "PROD", GroupBy: GroupByFile, Home: "/home/me"}))
+	assertGolden(t, "by_file_prod.md", got)
+}
+
+func TestHTML(t *testing.T) {
+	colors := Colors{Background: "#0F172A", Foreground: "#F8FAFC", Primary: "#3B82F6", Accent: "#06B6D4", Muted: "#64748B"}
+	got, err := HTML(Groups(testHosts(), Options{Tag: "web", GroupBy: GroupByTag, Home: "/home/me"}), colors)
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	assertGolden(t, "web.html", got)
+}
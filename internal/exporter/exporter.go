@@ -0,0 +1,195 @@
+// Package exporter probes hosts on an interval and serves their reachability as
+// Prometheus metrics, for watching machines without deploying real monitoring
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+)
+
+// DefaultAddr is the address "sshc exporter" listens on by default
+const DefaultAddr = ":9108"
+
+// MetricsPath is the endpoint Prometheus scrapes
+const MetricsPath = "/metrics"
+
+// Pinger probes hosts, such as a connectivity.PingManager
+type Pinger interface {
+	PingAllHosts(ctx context.Context, hosts []config.SSHHost) <-chan *connectivity.HostPingResult
+}
+
+// sample is the outcome of the latest probe of a host
+type sample struct {
+	up      bool
+	latency time.Duration
+	probed  time.Time
+}
+
+// Registry holds the latest sample of every probed host
+type Registry struct {
+	mutex   sync.RWMutex
+	samples map[string]sample
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{samples: make(map[string]sample)}
+}
+
+// Record stores the result of probing a host at the given time
+func (r *Registry) Record(result *connectivity.HostPingResult, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.samples[result.HostName] = sample{
+		up:      result.Status == connectivity.StatusOnline,
+		latency: result.Duration,
+		probed:  at,
+	}
+}
+
+// Retain drops the samples of hosts not in names, such as hosts removed from the
+// config or untagged since the last probe
+func (r *Registry) Retain(names []string) {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for name := range r.samples {
+		if !keep[name] {
+			delete(r.samples, name)
+		}
+	}
+}
+
+// WriteTo writes the metrics in the Prometheus text format, hosts sorted by name.
+// Latency is only reported for hosts that are up.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.RLock()
+	names := make([]string, 0, len(r.samples))
+	for name := range r.samples {
+		names = append(names, name)
+	}
+	samples := make(map[string]sample, len(r.samples))
+	for name, s := range r.samples {
+		samples[name] = s
+	}
+	r.mutex.RUnlock()
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# HELP sshc_host_up Whether the host accepted a TCP connection and spoke SSH at the last probe.\n")
+	b.WriteString("# TYPE sshc_host_up gauge\n")
+	for _, name := range names {
+		up := 0
+		if samples[name].up {
+			up = 1
+		}
+		fmt.Fprintf(&b, "sshc_host_up{host=\"%s\"} %d\n", escapeLabel(name), up)
+	}
+	b.WriteString("# HELP sshc_host_latency_seconds How long the last successful probe of the host took.\n")
+	b.WriteString("# TYPE sshc_host_latency_seconds gauge\n")
+	for _, name := range names {
+		if s := samples[name]; s.up {
+			fmt.Fprintf(&b, "sshc_host_latency_seconds{host=\"%s\"} %g\n", escapeLabel(name), s.latency.Seconds())
+		}
+	}
+	b.WriteString("# HELP sshc_last_probe_timestamp When the host was last probed, in seconds since the epoch.\n")
+	b.WriteString("# TYPE sshc_last_probe_timestamp gauge\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "sshc_last_probe_timestamp{host=\"%s\"} %d\n", escapeLabel(name), samples[name].probed.Unix())
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// escapeLabel escapes a label value for the Prometheus text format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// Handler serves the metrics of registry at MetricsPath
+func Handler(registry *Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(MetricsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		registry.WriteTo(w)
+	})
+	return mux
+}
+
+// Prober probes the hosts returned by load that carry tag, or all of them when
+// tag is empty, and records the results in a registry
+type Prober struct {
+	Pinger   Pinger
+	Load     func() ([]config.SSHHost, error)
+	Tag      string
+	Registry *Registry
+
+	now func() time.Time // Replaced in tests
+}
+
+// ProbeOnce reads the hosts again and probes them all, returning how many were
+// probed. Results arriving after ctx is done are not recorded, since those
+// probes were cut short rather than failed.
+func (p *Prober) ProbeOnce(ctx context.Context) (int, error) {
+	hosts, err := p.Load()
+	if err != nil {
+		return 0, err
+	}
+	var selected []config.SSHHost
+	names := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if p.Tag == "" || host.HasTag(p.Tag) {
+			selected = append(selected, host)
+			names = append(names, host.Name)
+		}
+	}
+	p.Registry.Retain(names)
+
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	for result := range p.Pinger.PingAllHosts(ctx, selected) {
+		if ctx.Err() != nil {
+			break
+		}
+		p.Registry.Record(result, now())
+	}
+	return len(selected), ctx.Err()
+}
+
+// Run probes the hosts right away and then every interval until ctx is done.
+// A round that fails to read the config is reported to onError and retried at
+// the next interval.
+func (p *Prober) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.ProbeOnce(ctx); err != nil && ctx.Err() == nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+)
+
+// stubPinger reports the configured status of each host and counts the rounds
+type stubPinger struct {
+	mutex  sync.Mutex
+	status map[string]connectivity.PingStatus
+	rounds int
+}
+
+func (s *stubPinger) PingAllHosts(ctx context.Context, hosts []config.SSHHost) <-chan *connectivity.HostPingResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rounds++
+	results := make(chan *connectivity.HostPingResult, len(hosts))
+	for _, host := range hosts {
+		results <- &connectivity.HostPingResult{HostName: host.Name, Status: s.status[host.Name], Duration: 250 * time.Millisecond}
+	}
+	close(results)
+	return results
+}
+
+func (s *stubPinger) roundCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.rounds
+}
+
+func labHosts() ([]config.SSHHost, error) {
+	return []config.SSHHost{
+		{Name: "lab-1", Tags: []string{"lab"}},
+		{Name: `lab "2"`, Tags: []string{"Lab"}},
+		{Name: "prod", Tags: []string{"prod"}},
+	}, nil
+}
+
+func TestProbeOnceServesMetrics(t *testing.T) {
+	pinger := &stubPinger{status: map[string]connectivity.PingStatus{
+		"lab-1":   connectivity.StatusOnline,
+		`lab "2"`: connectivity.StatusOffline,
+	}}
+	registry := NewRegistry()
+	probed := time.Unix(1700000000, 0)
+	prober := &Prober{Pinger: pinger, Load: labHosts, Tag: "lab", Registry: registry, now: func() time.Time { return probed }}
+
+	if n, err := prober.ProbeOnce(context.Background()); err != nil || n != 2 {
+		t.Fatalf("ProbeOnce() = %d, %v, want the 2 lab hosts", n, err)
+	}
+
+	server := httptest.NewServer(Handler(registry))
+	defer server.Close()
+	resp, err := http.Get(server.URL + MetricsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	metrics := string(body)
+
+	for _, want := range []string{
+		"# TYPE sshc_host_up gauge",
+		`sshc_host_up{host="lab \"2\""} 0`,
+		`sshc_host_up{host="lab-1"} 1`,
+		`sshc_host_latency_seconds{host="lab-1"} 0.25`,
+		`sshc_last_probe_timestamp{host="lab-1"} 1700000000`,
+		`sshc_last_probe_timestamp{host="lab \"2\""} 1700000000`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics lack %q:\n%s", want, metrics)
+		}
+	}
+	for _, unwanted := range []string{`host="prod"`, `sshc_host_latency_seconds{host="lab \"2\""}`} {
+		if strings.Contains(metrics, unwanted) {
+			t.Errorf("metrics contain %q:\n%s", unwanted, metrics)
+		}
+	}
+}
+
+func TestProbeOnceDropsHostsNoLongerSelected(t *testing.T) {
+	registry := NewRegistry()
+	registry.Record(&connectivity.HostPingResult{HostName: "removed", Status: connectivity.StatusOnline}, time.Now())
+	prober := &Prober{Pinger: &stubPinger{}, Load: labHosts, Registry: registry}
+
+	if _, err := prober.ProbeOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	registry.WriteTo(&b)
+	if strings.Contains(b.String(), "removed") || !strings.Contains(b.String(), `host="prod"`) {
+		t.Errorf("metrics = %s, want every current host and none of the removed one", b.String())
+	}
+}
+
+func TestRunProbesUntilCancelled(t *testing.T) {
+	pinger := &stubPinger{}
+	loadErr := errors.New("config is gone")
+	var failures int
+	var mutex sync.Mutex
+	load := func() ([]config.SSHHost, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if failures == 0 {
+			failures++
+			return nil, loadErr
+		}
+		return labHosts()
+	}
+	prober := &Prober{Pinger: pinger, Load: load, Registry: NewRegistry()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var reported error
+	go func() {
+		prober.Run(ctx, 5*time.Millisecond, func(err error) { reported = err })
+		close(done)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for pinger.roundCount() < 2 {
+		select {
+		case <-deadline:
+			t.Fatal("Run() did not keep probing after a failed round")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return after cancelling")
+	}
+	if !errors.Is(reported, loadErr) {
+		t.Errorf("reported error = %v, want the failed load", reported)
+	}
+}
+
+func TestHandlerRejectsOtherMethods(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(NewRegistry()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, MetricsPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/xvertile/sshc/internal/config"
+	sshclog "github.com/xvertile/sshc/internal/log"
 )
 
 // ConnectionHistory represents the history of SSH connections
@@ -29,7 +30,36 @@ type TransferHistoryEntry struct {
 	Direction  string    `json:"direction"` // "upload" or "download"
 	LocalPath  string    `json:"local_path"`
 	RemotePath string    `json:"remote_path"`
+	Recursive  bool      `json:"recursive,omitempty"`
 	Timestamp  time.Time `json:"timestamp"`
+	Outcome    string    `json:"outcome,omitempty"` // TransferSucceeded, TransferFailed, or "" if unknown
+	Error      string    `json:"error,omitempty"`
+}
+
+// Outcomes of a recorded transfer
+const (
+	TransferSucceeded = "success"
+	TransferFailed    = "failed"
+)
+
+// TransferRecord is a transfer together with the host it went to or came from
+type TransferRecord struct {
+	HostName string
+	TransferHistoryEntry
+}
+
+// SnippetHistoryEntry records a snippet run on a host
+type SnippetHistoryEntry struct {
+	Snippet   string    `json:"snippet"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AcceptedKey records the key a server accepted during a verbose connect
+type AcceptedKey struct {
+	Path        string    `json:"path"`
+	Type        string    `json:"type,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
 }
 
 // ConnectionInfo stores information about a specific connection
@@ -37,30 +67,37 @@ type ConnectionInfo struct {
 	HostName        string                 `json:"host_name"`
 	LastConnect     time.Time              `json:"last_connect"`
 	ConnectCount    int                    `json:"connect_count"`
+	RecentConnects  []time.Time            `json:"recent_connects,omitempty"` // Oldest first, the last maxRecentConnects
 	PortForwarding  *PortForwardConfig     `json:"port_forwarding,omitempty"`
 	TransferHistory []TransferHistoryEntry `json:"transfer_history,omitempty"`
+	SnippetHistory  []SnippetHistoryEntry  `json:"snippet_history,omitempty"`
+	AcceptedKey     *AcceptedKey           `json:"accepted_key,omitempty"`
+	JumpOverride    string                 `json:"jump_override,omitempty"`   // Jump host last chosen at connect time
+	LastRemoteDir   string                 `json:"last_remote_dir,omitempty"` // Remote directory last picked in a transfer
+	LastLocalDir    string                 `json:"last_local_dir,omitempty"`  // Local directory last picked in a transfer
+
+	// Attempts where ssh itself failed (exit status 255), such as timeouts, rather
+	// than a remote command exiting with 255
+	FailedConnects int       `json:"failed_connects,omitempty"`
+	LastFailure    time.Time `json:"last_failure,omitempty"`
 }
 
 // HistoryManager manages the connection history
 type HistoryManager struct {
-	historyPath string
-	history     *ConnectionHistory
+	historyPath   string
+	history       *ConnectionHistory
+	journal       bool   // Append connections to a journal instead of rewriting the file
+	recoveredFrom string // Where a corrupt history file was moved on load
 }
 
 // NewHistoryManager creates a new history manager
 func NewHistoryManager() (*HistoryManager, error) {
-	configDir, err := config.GetSSHMConfigDir()
+	// History lives in the state directory, moved there from the config directory once
+	historyPath, err := config.StateFilePath("sshc_history.json")
 	if err != nil {
 		return nil, err
 	}
 
-	// Ensure config dir exists
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return nil, err
-	}
-
-	historyPath := filepath.Join(configDir, "sshc_history.json")
-
 	// Migration: check if old history file exists and migrate it
 	if err := migrateOldHistoryFile(historyPath); err != nil {
 		// Don't fail if migration fails, just log it
@@ -72,19 +109,15 @@ func NewHistoryManager() (*HistoryManager, error) {
 		history:     &ConnectionHistory{Connections: make(map[string]ConnectionInfo)},
 	}
 
-	// Load existing history if it exists
-	err = hm.loadHistory()
-	if err != nil {
-		// If file doesn't exist, that's okay - we'll create it when needed
-		if !os.IsNotExist(err) {
-			return nil, err
-		}
+	// Load existing history if it exists; it is created when needed
+	if err := hm.loadHistory(); err != nil {
+		return nil, err
 	}
 
 	return hm, nil
 }
 
-// migrateOldHistoryFile migrates the old history file from ~/.ssh to ~/.config/sshc
+// migrateOldHistoryFile migrates the old history file from ~/.ssh to the state directory
 // TODO: Remove this migration logic in v2.0.0 (introduced in v1.6.0)
 func migrateOldHistoryFile(newHistoryPath string) error {
 	// Check if new file already exists, skip migration
@@ -111,6 +144,7 @@ func migrateOldHistoryFile(newHistoryPath string) error {
 	}
 
 	// Write to new location
+	sshclog.Write(newHistoryPath, "migrate history")
 	if err := os.WriteFile(newHistoryPath, data, 0644); err != nil {
 		return err
 	}
@@ -124,51 +158,50 @@ func migrateOldHistoryFile(newHistoryPath string) error {
 	return nil
 }
 
-// loadHistory loads the connection history from the JSON file
+// loadHistory loads the connection history from the JSON file and its journal.
+// A missing file leaves the history empty.
 func (hm *HistoryManager) loadHistory() error {
-	data, err := os.ReadFile(hm.historyPath)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(data, hm.history)
+	return hm.withLock(hm.reload)
 }
 
-// saveHistory saves the connection history to the JSON file
+// saveHistory replaces the JSON file with the history, which then holds everything
+// in the journal too. The caller holds the lock and has reloaded the history.
 func (hm *HistoryManager) saveHistory() error {
-	// Ensure the directory exists
-	dir := filepath.Dir(hm.historyPath)
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return err
-	}
-
 	data, err := json.MarshalIndent(hm.history, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(hm.historyPath, data, 0600)
+	sshclog.Write(hm.historyPath, "history")
+	if err := writeFileAtomic(hm.historyPath, data); err != nil {
+		return err
+	}
+	if err := os.Remove(hm.journalPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 // RecordConnection records a new connection for the specified host
 func (hm *HistoryManager) RecordConnection(hostName string) error {
-	now := time.Now()
+	return hm.record(journalEntry{Op: journalConnect, Host: hostName, Time: time.Now()})
+}
 
-	if conn, exists := hm.history.Connections[hostName]; exists {
-		// Update existing connection
-		conn.LastConnect = now
-		conn.ConnectCount++
-		hm.history.Connections[hostName] = conn
-	} else {
-		// Create new connection record
-		hm.history.Connections[hostName] = ConnectionInfo{
-			HostName:     hostName,
-			LastConnect:  now,
-			ConnectCount: 1,
+// RecordConnectFailure counts a connection attempt to hostName that ssh gave up on
+func (hm *HistoryManager) RecordConnectFailure(hostName string) error {
+	return hm.record(journalEntry{Op: journalFailure, Host: hostName, Time: time.Now()})
+}
+
+// GetConnectFailures returns the number of failed connection attempts of every
+// host that has any, keyed by host name
+func (hm *HistoryManager) GetConnectFailures() map[string]int {
+	failures := make(map[string]int)
+	for name, conn := range hm.history.Connections {
+		if conn.FailedConnects > 0 {
+			failures[name] = conn.FailedConnects
 		}
 	}
-
-	return hm.saveHistory()
+	return failures
 }
 
 // GetLastConnectionTime returns the last connection time for a host
@@ -179,6 +212,21 @@ func (hm *HistoryManager) GetLastConnectionTime(hostName string) (time.Time, boo
 	return time.Time{}, false
 }
 
+// GetRecentConnects returns when the last connections to a host were made, oldest
+// first. At most maxRecentConnects are kept.
+func (hm *HistoryManager) GetRecentConnects(hostName string) []time.Time {
+	if conn, exists := hm.history.Connections[hostName]; exists {
+		return conn.RecentConnects
+	}
+	return nil
+}
+
+// CountSince returns how many of times, sorted oldest first, are after since
+func CountSince(times []time.Time, since time.Time) int {
+	i := sort.Search(len(times), func(i int) bool { return times[i].After(since) })
+	return len(times) - i
+}
+
 // GetConnectionCount returns the total number of connections for a host
 func (hm *HistoryManager) GetConnectionCount(hostName string) int {
 	if conn, exists := hm.history.Connections[hostName]; exists {
@@ -254,13 +302,14 @@ func (hm *HistoryManager) CleanupOldEntries(currentHosts []config.SSHHost) error
 	}
 
 	// Remove entries for hosts that no longer exist
-	for hostName := range hm.history.Connections {
-		if !currentHostNames[hostName] {
-			delete(hm.history.Connections, hostName)
+	return hm.update(func(h *ConnectionHistory) bool {
+		for hostName := range h.Connections {
+			if !currentHostNames[hostName] {
+				delete(h.Connections, hostName)
+			}
 		}
-	}
-
-	return hm.saveHistory()
+		return true
+	})
 }
 
 // GetAllConnectionsInfo returns all connection information sorted by last connection time
@@ -289,23 +338,24 @@ func (hm *HistoryManager) RecordPortForwarding(hostName, forwardType, localPort,
 		BindAddress: bindAddress,
 	}
 
-	if conn, exists := hm.history.Connections[hostName]; exists {
-		// Update existing connection
-		conn.LastConnect = now
-		conn.ConnectCount++
-		conn.PortForwarding = portForwardConfig
-		hm.history.Connections[hostName] = conn
-	} else {
-		// Create new connection record
-		hm.history.Connections[hostName] = ConnectionInfo{
-			HostName:       hostName,
-			LastConnect:    now,
-			ConnectCount:   1,
-			PortForwarding: portForwardConfig,
+	return hm.update(func(h *ConnectionHistory) bool {
+		if conn, exists := h.Connections[hostName]; exists {
+			// Update existing connection
+			conn.LastConnect = now
+			conn.ConnectCount++
+			conn.PortForwarding = portForwardConfig
+			h.Connections[hostName] = conn
+		} else {
+			// Create new connection record
+			h.Connections[hostName] = ConnectionInfo{
+				HostName:       hostName,
+				LastConnect:    now,
+				ConnectCount:   1,
+				PortForwarding: portForwardConfig,
+			}
 		}
-	}
-
-	return hm.saveHistory()
+		return true
+	})
 }
 
 // GetPortForwardingConfig retrieves the last used port forwarding configuration for a host
@@ -318,34 +368,216 @@ func (hm *HistoryManager) GetPortForwardingConfig(hostName string) *PortForwardC
 
 // RecordTransfer saves a file transfer record for a host
 func (hm *HistoryManager) RecordTransfer(hostName, direction, localPath, remotePath string) error {
-	now := time.Now()
-
-	entry := TransferHistoryEntry{
+	return hm.RecordTransferEntry(hostName, TransferHistoryEntry{
 		Direction:  direction,
 		LocalPath:  localPath,
 		RemotePath: remotePath,
-		Timestamp:  now,
+	})
+}
+
+// RecordTransferEntry saves a file transfer record for a host, keeping the last 10.
+// A zero Timestamp is set to the current time.
+func (hm *HistoryManager) RecordTransferEntry(hostName string, entry TransferHistoryEntry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
 	}
+	now := entry.Timestamp
+
+	return hm.update(func(h *ConnectionHistory) bool {
+		if conn, exists := h.Connections[hostName]; exists {
+			// Add to existing history, keep last 10 entries
+			conn.TransferHistory = append([]TransferHistoryEntry{entry}, conn.TransferHistory...)
+			if len(conn.TransferHistory) > 10 {
+				conn.TransferHistory = conn.TransferHistory[:10]
+			}
+			conn.LastConnect = now
+			h.Connections[hostName] = conn
+		} else {
+			// Create new connection record
+			h.Connections[hostName] = ConnectionInfo{
+				HostName:        hostName,
+				LastConnect:     now,
+				ConnectCount:    0,
+				TransferHistory: []TransferHistoryEntry{entry},
+			}
+		}
+		return true
+	})
+}
 
-	if conn, exists := hm.history.Connections[hostName]; exists {
-		// Add to existing history, keep last 10 entries
-		conn.TransferHistory = append([]TransferHistoryEntry{entry}, conn.TransferHistory...)
-		if len(conn.TransferHistory) > 10 {
-			conn.TransferHistory = conn.TransferHistory[:10]
+// RecordSnippet saves that a snippet ran on a host, keeping the last 10 runs
+func (hm *HistoryManager) RecordSnippet(hostName, snippetName string) error {
+	now := time.Now()
+	entry := SnippetHistoryEntry{Snippet: snippetName, Timestamp: now}
+
+	return hm.update(func(h *ConnectionHistory) bool {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			conn = ConnectionInfo{HostName: hostName}
+		}
+		conn.SnippetHistory = append([]SnippetHistoryEntry{entry}, conn.SnippetHistory...)
+		if len(conn.SnippetHistory) > 10 {
+			conn.SnippetHistory = conn.SnippetHistory[:10]
 		}
 		conn.LastConnect = now
-		hm.history.Connections[hostName] = conn
-	} else {
-		// Create new connection record
-		hm.history.Connections[hostName] = ConnectionInfo{
-			HostName:        hostName,
-			LastConnect:     now,
-			ConnectCount:    0,
-			TransferHistory: []TransferHistoryEntry{entry},
+		h.Connections[hostName] = conn
+		return true
+	})
+}
+
+// GetSnippetHistory retrieves the snippets run on a host, most recent first
+func (hm *HistoryManager) GetSnippetHistory(hostName string) []SnippetHistoryEntry {
+	if conn, exists := hm.history.Connections[hostName]; exists {
+		return conn.SnippetHistory
+	}
+	return nil
+}
+
+// RecordAcceptedKey saves the key the host last accepted
+func (hm *HistoryManager) RecordAcceptedKey(hostName string, key AcceptedKey) error {
+	if key.Timestamp.IsZero() {
+		key.Timestamp = time.Now()
+	}
+
+	return hm.update(func(h *ConnectionHistory) bool {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			conn = ConnectionInfo{HostName: hostName, LastConnect: key.Timestamp}
+		}
+		conn.AcceptedKey = &key
+		h.Connections[hostName] = conn
+		return true
+	})
+}
+
+// GetAcceptedKey retrieves the key the host last accepted, or nil if none was recorded
+func (hm *HistoryManager) GetAcceptedKey(hostName string) *AcceptedKey {
+	if conn, exists := hm.history.Connections[hostName]; exists {
+		return conn.AcceptedKey
+	}
+	return nil
+}
+
+// RecordJumpOverride stores the jump host last used instead of the host's
+// ProxyJump, or clears it when jump is empty
+func (hm *HistoryManager) RecordJumpOverride(hostName, jump string) error {
+	return hm.update(func(h *ConnectionHistory) bool {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			if jump == "" {
+				return false
+			}
+			conn = ConnectionInfo{HostName: hostName, LastConnect: time.Now()}
+		}
+		conn.JumpOverride = jump
+		h.Connections[hostName] = conn
+		return true
+	})
+}
+
+// GetJumpOverride retrieves the jump host last used for the host at connect
+// time, or "" if none was recorded
+func (hm *HistoryManager) GetJumpOverride(hostName string) string {
+	if conn, exists := hm.history.Connections[hostName]; exists {
+		return conn.JumpOverride
+	}
+	return ""
+}
+
+// RecordLastDirs stores the remote and local directories last picked for a
+// transfer with the host. An empty directory keeps the one recorded before.
+func (hm *HistoryManager) RecordLastDirs(hostName, remoteDir, localDir string) error {
+	if remoteDir == "" && localDir == "" {
+		return nil
+	}
+	return hm.update(func(h *ConnectionHistory) bool {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			conn = ConnectionInfo{HostName: hostName}
+		}
+		if remoteDir != "" {
+			conn.LastRemoteDir = remoteDir
+		}
+		if localDir != "" {
+			conn.LastLocalDir = localDir
 		}
+		h.Connections[hostName] = conn
+		return true
+	})
+}
+
+// GetLastDirs retrieves the remote and local directories last picked for a
+// transfer with the host, each "" if none was recorded
+func (hm *HistoryManager) GetLastDirs(hostName string) (remoteDir, localDir string) {
+	if conn, exists := hm.history.Connections[hostName]; exists {
+		return conn.LastRemoteDir, conn.LastLocalDir
 	}
+	return "", ""
+}
 
-	return hm.saveHistory()
+// RecordTransferOutcome stores how the transfer recorded at timestamp ended
+func (hm *HistoryManager) RecordTransferOutcome(hostName string, timestamp time.Time, transferErr error) error {
+	return hm.update(func(h *ConnectionHistory) bool {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			return false
+		}
+		for i := range conn.TransferHistory {
+			entry := &conn.TransferHistory[i]
+			if !entry.Timestamp.Equal(timestamp) {
+				continue
+			}
+			entry.Outcome, entry.Error = TransferSucceeded, ""
+			if transferErr != nil {
+				entry.Outcome, entry.Error = TransferFailed, transferErr.Error()
+			}
+			return true
+		}
+		return false
+	})
+}
+
+// GetTransfers returns the recent transfers of a host, or of every host when
+// hostName is empty, most recent first. A limit of 0 or less returns them all.
+func (hm *HistoryManager) GetTransfers(hostName string, limit int) []TransferRecord {
+	var records []TransferRecord
+	for name, conn := range hm.history.Connections {
+		if hostName != "" && name != hostName {
+			continue
+		}
+		for _, entry := range conn.TransferHistory {
+			records = append(records, TransferRecord{HostName: name, TransferHistoryEntry: entry})
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].Timestamp.Equal(records[j].Timestamp) {
+			return records[i].Timestamp.After(records[j].Timestamp)
+		}
+		return records[i].HostName < records[j].HostName
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
+
+// DeleteTransfer removes the transfer recorded at timestamp from a host's history
+func (hm *HistoryManager) DeleteTransfer(hostName string, timestamp time.Time) error {
+	return hm.update(func(h *ConnectionHistory) bool {
+		conn, exists := h.Connections[hostName]
+		if !exists {
+			return false
+		}
+		for i, entry := range conn.TransferHistory {
+			if entry.Timestamp.Equal(timestamp) {
+				conn.TransferHistory = append(conn.TransferHistory[:i:i], conn.TransferHistory[i+1:]...)
+				h.Connections[hostName] = conn
+				return true
+			}
+		}
+		return false
+	})
 }
 
 // GetTransferHistory retrieves the transfer history for a host
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -22,6 +23,21 @@ func createTestHistoryManager(t *testing.T) *HistoryManager {
 }
 
 func TestNewHistoryManager(t *testing.T) {
+	configHome, stateHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("APPDATA", configHome)
+	t.Setenv("XDG_STATE_HOME", stateHome)
+	t.Setenv("LOCALAPPDATA", stateHome)
+
+	// History written to the config directory by an older version
+	oldPath := filepath.Join(configHome, "sshc", "sshc_history.json")
+	if err := os.MkdirAll(filepath.Dir(oldPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(oldPath, []byte(`{"connections":{"web":{"host_name":"web","connect_count":4}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
 	hm, err := NewHistoryManager()
 	if err != nil {
 		t.Fatalf("NewHistoryManager() error = %v", err)
@@ -29,8 +45,14 @@ func TestNewHistoryManager(t *testing.T) {
 	if hm == nil {
 		t.Fatal("NewHistoryManager() returned nil")
 	}
-	if hm.historyPath == "" {
-		t.Error("Expected historyPath to be set")
+	if want := filepath.Join(stateHome, "sshc", "sshc_history.json"); hm.historyPath != want {
+		t.Errorf("Expected historyPath %s, got %s", want, hm.historyPath)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the old history file to be moved, stat error = %v", err)
+	}
+	if count := hm.GetConnectionCount("web"); count != 4 {
+		t.Errorf("Expected the migrated history to be loaded, got connection count %d", count)
 	}
 }
 
@@ -162,3 +184,244 @@ func TestMigrateOldHistoryFile_NewFileExists(t *testing.T) {
 		t.Error("New file was modified when it shouldn't have been")
 	}
 }
+
+func TestHistoryManager_RecordSnippet(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	for i := 0; i < 12; i++ {
+		if err := hm.RecordSnippet("web", "check disk"); err != nil {
+			t.Fatalf("RecordSnippet() error = %v", err)
+		}
+	}
+	if err := hm.RecordSnippet("web", "tail nginx"); err != nil {
+		t.Fatalf("RecordSnippet() error = %v", err)
+	}
+
+	runs := hm.GetSnippetHistory("web")
+	if len(runs) != 10 {
+		t.Fatalf("Expected the last 10 runs to be kept, got %d", len(runs))
+	}
+	if runs[0].Snippet != "tail nginx" {
+		t.Errorf("Expected the latest run first, got %q", runs[0].Snippet)
+	}
+	// A snippet run is not a new interactive connection
+	if hm.GetConnectionCount("web") != 0 {
+		t.Errorf("Expected snippet runs not to count as connections")
+	}
+	if len(hm.GetSnippetHistory("db")) != 0 {
+		t.Error("Expected no snippet history for another host")
+	}
+}
+
+func TestHistoryManager_RecordAcceptedKey(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	key := AcceptedKey{Path: "/home/alice/.ssh/id_ed25519", Type: "ED25519", Fingerprint: "SHA256:abc"}
+	if err := hm.RecordAcceptedKey("web", key); err != nil {
+		t.Fatalf("RecordAcceptedKey() error = %v", err)
+	}
+
+	got := hm.GetAcceptedKey("web")
+	if got == nil || got.Path != key.Path || got.Timestamp.IsZero() {
+		t.Fatalf("GetAcceptedKey() = %+v", got)
+	}
+	if hm.GetConnectionCount("web") != 1 {
+		t.Error("Expected recording the key to keep the connection count")
+	}
+	if hm.GetAcceptedKey("db") != nil {
+		t.Error("Expected no accepted key for another host")
+	}
+}
+
+func TestHistoryManager_RecordJumpOverride(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordJumpOverride("web", "bastion-2"); err != nil {
+		t.Fatalf("RecordJumpOverride() error = %v", err)
+	}
+	if got := hm.GetJumpOverride("web"); got != "bastion-2" {
+		t.Errorf("GetJumpOverride() = %q, want bastion-2", got)
+	}
+	if hm.GetConnectionCount("web") != 1 {
+		t.Error("Expected recording the override to keep the connection count")
+	}
+
+	if err := hm.RecordJumpOverride("web", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := hm.GetJumpOverride("web"); got != "" {
+		t.Errorf("GetJumpOverride() after clearing = %q", got)
+	}
+	if err := hm.RecordJumpOverride("db", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(hm.GetAllConnectionsInfo()) != 1 {
+		t.Error("Expected clearing an unknown host to record nothing")
+	}
+}
+
+func TestHistoryManager_RecordLastDirs(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	if err := hm.RecordLastDirs("web", "/var/www/releases", "/home/me/builds"); err != nil {
+		t.Fatalf("RecordLastDirs() error = %v", err)
+	}
+	// Picking only one side keeps the other
+	if err := hm.RecordLastDirs("web", "/var/www/releases/42", ""); err != nil {
+		t.Fatal(err)
+	}
+	if remote, local := hm.GetLastDirs("web"); remote != "/var/www/releases/42" || local != "/home/me/builds" {
+		t.Errorf("GetLastDirs() = %q, %q", remote, local)
+	}
+	if remote, local := hm.GetLastDirs("db"); remote != "" || local != "" {
+		t.Errorf("GetLastDirs() of a host without history = %q, %q", remote, local)
+	}
+	if hm.GetConnectionCount("web") != 0 {
+		t.Error("Expected recording directories not to count as a connection")
+	}
+
+	reloaded := &HistoryManager{historyPath: hm.historyPath, history: &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if remote, local := reloaded.GetLastDirs("web"); remote != "/var/www/releases/42" || local != "/home/me/builds" {
+		t.Errorf("GetLastDirs() after reload = %q, %q", remote, local)
+	}
+}
+
+func TestHistoryManager_GetTransfers(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	record := func(host, direction, local string, minutes int) TransferHistoryEntry {
+		entry := TransferHistoryEntry{Direction: direction, LocalPath: local, RemotePath: "/tmp/" + local, Timestamp: base.Add(time.Duration(minutes) * time.Minute)}
+		if err := hm.RecordTransferEntry(host, entry); err != nil {
+			t.Fatalf("RecordTransferEntry() error = %v", err)
+		}
+		return entry
+	}
+	record("web", "upload", "a.txt", 1)
+	failed := record("db", "download", "dump.sql", 2)
+	last := record("web", "upload", "b.txt", 3)
+
+	if err := hm.RecordTransferOutcome("db", failed.Timestamp, errors.New("permission denied")); err != nil {
+		t.Fatalf("RecordTransferOutcome() error = %v", err)
+	}
+	if err := hm.RecordTransferOutcome("web", last.Timestamp, nil); err != nil {
+		t.Fatalf("RecordTransferOutcome() error = %v", err)
+	}
+
+	all := hm.GetTransfers("", 0)
+	if len(all) != 3 || all[0].LocalPath != "b.txt" || all[1].HostName != "db" || all[2].LocalPath != "a.txt" {
+		t.Fatalf("GetTransfers() = %+v, want the three transfers newest first", all)
+	}
+	if all[0].Outcome != TransferSucceeded || all[1].Outcome != TransferFailed || all[1].Error != "permission denied" || all[2].Outcome != "" {
+		t.Errorf("Unexpected outcomes: %+v", all)
+	}
+	if got := hm.GetTransfers("web", 1); len(got) != 1 || got[0].LocalPath != "b.txt" {
+		t.Errorf("GetTransfers(web, 1) = %+v, want b.txt only", got)
+	}
+
+	if err := hm.DeleteTransfer("web", last.Timestamp); err != nil {
+		t.Fatalf("DeleteTransfer() error = %v", err)
+	}
+	if got := hm.GetTransfers("web", 0); len(got) != 1 || got[0].LocalPath != "a.txt" {
+		t.Errorf("GetTransfers(web) after delete = %+v, want a.txt only", got)
+	}
+
+	// The outcome survives a reload
+	reloaded := &HistoryManager{historyPath: hm.historyPath, history: &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.GetTransfers("db", 0); len(got) != 1 || got[0].Outcome != TransferFailed {
+		t.Errorf("GetTransfers(db) after reload = %+v", got)
+	}
+}
+
+func TestHistoryManager_LoadsTransfersWithoutOutcome(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	old := `{"connections":{"web":{"host_name":"web","transfer_history":[{"direction":"upload","local_path":"a.txt","remote_path":"/tmp/a.txt","timestamp":"2025-01-02T03:04:05Z"}]}}}`
+	if err := os.WriteFile(hm.historyPath, []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.loadHistory(); err != nil {
+		t.Fatalf("loadHistory() error = %v", err)
+	}
+
+	got := hm.GetTransfers("", 10)
+	if len(got) != 1 || got[0].HostName != "web" || got[0].Outcome != "" || got[0].Recursive {
+		t.Fatalf("GetTransfers() = %+v", got)
+	}
+}
+
+func TestHistoryManager_RecordConnectFailure(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := hm.RecordConnectFailure("web"); err != nil {
+			t.Fatalf("RecordConnectFailure() error = %v", err)
+		}
+	}
+	hm.RecordConnectFailure("db")
+
+	failures := hm.GetConnectFailures()
+	if len(failures) != 2 || failures["web"] != 2 || failures["db"] != 1 {
+		t.Errorf("GetConnectFailures() = %v, want web: 2 and db: 1", failures)
+	}
+	if count := hm.GetConnectionCount("web"); count != 1 {
+		t.Errorf("Expected failures not to count as connections, got %d", count)
+	}
+}
+
+func TestHistoryManager_RecentConnects(t *testing.T) {
+	hm := createTestHistoryManager(t)
+
+	for i := 0; i < maxRecentConnects+5; i++ {
+		if err := hm.RecordConnection("lab"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recent := hm.GetRecentConnects("lab")
+	if len(recent) != maxRecentConnects {
+		t.Fatalf("Expected %d recent connects to be kept, got %d", maxRecentConnects, len(recent))
+	}
+	if last, _ := hm.GetLastConnectionTime("lab"); !recent[len(recent)-1].Equal(last) {
+		t.Errorf("Expected the newest connect last, got %v and last connection %v", recent[len(recent)-1], last)
+	}
+	if count := hm.GetConnectionCount("lab"); count != maxRecentConnects+5 {
+		t.Errorf("Expected the count to include trimmed connects, got %d", count)
+	}
+}
+
+func TestCountSince(t *testing.T) {
+	base := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	times := []time.Time{base, base.Add(time.Minute), base.Add(5 * time.Minute), base.Add(9 * time.Minute)}
+
+	tests := []struct {
+		since time.Time
+		want  int
+	}{
+		{base.Add(-time.Second), 4},
+		{base, 3}, // A connect exactly at since is outside the window
+		{base.Add(4 * time.Minute), 2},
+		{base.Add(9 * time.Minute), 0},
+	}
+	for _, tt := range tests {
+		if got := CountSince(times, tt.since); got != tt.want {
+			t.Errorf("CountSince(%v) = %d, want %d", tt.since, got, tt.want)
+		}
+	}
+	if got := CountSince(nil, base); got != 0 {
+		t.Errorf("CountSince(nil) = %d, want 0", got)
+	}
+}
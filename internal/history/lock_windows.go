@@ -0,0 +1,36 @@
+//go:build windows
+
+package history
+
+import (
+	"os"
+	"time"
+)
+
+// staleLockAge is how old a lock file must be before it is taken to be left
+// behind by a process that died while holding it
+const staleLockAge = 30 * time.Second
+
+// lockFile creates path exclusively, waiting up to lockTimeout for another process
+// to remove it
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errLockTimeout
+		}
+		time.Sleep(lockRetry)
+	}
+}
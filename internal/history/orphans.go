@@ -0,0 +1,118 @@
+package history
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// OrphanReport lists the records kept for hosts that no longer exist
+type OrphanReport struct {
+	History []string // Hosts with connection history
+	Colors  []string // Hosts with a color label
+	Pins    []string // Hosts with pinned remote paths
+}
+
+// Count returns how many records the report lists
+func (r OrphanReport) Count() int {
+	return len(r.History) + len(r.Colors) + len(r.Pins)
+}
+
+// OrphanedHosts returns the hosts with history that are not in known, without
+// removing them
+func (hm *HistoryManager) OrphanedHosts(known []string) []string {
+	existing := make(map[string]bool, len(known))
+	for _, name := range known {
+		existing[name] = true
+	}
+	var orphaned []string
+	for name := range hm.history.Connections {
+		if !existing[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+// PruneHosts removes the history of hosts that are not in known and returns
+// their names. The history file is only written when something was removed.
+func (hm *HistoryManager) PruneHosts(known []string) ([]string, error) {
+	var orphaned []string
+	err := hm.update(func(h *ConnectionHistory) bool {
+		orphaned = hm.OrphanedHosts(known)
+		for _, name := range orphaned {
+			delete(h.Connections, name)
+		}
+		return len(orphaned) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orphaned, nil
+}
+
+// FindOrphans reports the history entries and color labels of hosts not in
+// known. Either of hm and appConfig may be nil.
+func FindOrphans(hm *HistoryManager, appConfig *config.AppConfig, known []string) OrphanReport {
+	var report OrphanReport
+	if hm != nil {
+		report.History = hm.OrphanedHosts(known)
+	}
+	if appConfig != nil {
+		report.Colors = appConfig.OrphanedHostColors(known)
+		report.Pins = appConfig.OrphanedPinnedPaths(known)
+	}
+	return report
+}
+
+// CleanupDeleted removes the history entries, color labels and pinned paths of
+// hosts that were just deleted, unless one of the configs still has them in known.
+// The records of every other host are kept, whether a config lists it or not.
+func CleanupDeleted(hm *HistoryManager, appConfig *config.AppConfig, deleted, known []string) (OrphanReport, error) {
+	remove := make(map[string]bool, len(deleted))
+	for _, name := range deleted {
+		if !slices.Contains(known, name) {
+			remove[name] = true
+		}
+	}
+	if len(remove) == 0 {
+		return OrphanReport{}, nil
+	}
+
+	// Without known names every record is reported, so keep all but the deleted
+	recorded := FindOrphans(hm, appConfig, nil)
+	var keep []string
+	for _, names := range [][]string{recorded.History, recorded.Colors, recorded.Pins} {
+		for _, name := range names {
+			if !remove[name] {
+				keep = append(keep, name)
+			}
+		}
+	}
+	return CleanupOrphans(hm, appConfig, keep)
+}
+
+// CleanupOrphans removes the history entries, color labels and pinned paths of
+// hosts not in known, saving the app config when it changed, and reports what it removed
+func CleanupOrphans(hm *HistoryManager, appConfig *config.AppConfig, known []string) (OrphanReport, error) {
+	var report OrphanReport
+	if hm != nil {
+		pruned, err := hm.PruneHosts(known)
+		if err != nil {
+			return report, err
+		}
+		report.History = pruned
+	}
+	if appConfig != nil {
+		report.Colors = appConfig.PruneHostColors(known)
+		report.Pins = appConfig.PrunePinnedPaths(known)
+		if len(report.Colors) > 0 || len(report.Pins) > 0 {
+			if err := config.SaveAppConfig(appConfig); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func TestCleanupOrphans(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	hm := createTestHistoryManager(t)
+	for _, name := range []string{"web", "web-alias", "gone"} {
+		if err := hm.RecordConnection(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hm.RecordLastDirs("gone", "/var/www", "/tmp"); err != nil {
+		t.Fatal(err)
+	}
+	appConfig := &config.AppConfig{
+		HostColors:  map[string]string{"web": "accent-1", "db": "accent-2"},
+		PinnedPaths: map[string][]config.PinnedPath{"gone": {{Path: "/var/www"}}, "db": {{Path: "/var/lib"}}},
+	}
+	known := []string{"web-alias", "db"}
+
+	report := FindOrphans(hm, appConfig, known)
+	if !slices.Equal(report.History, []string{"gone", "web"}) || !slices.Equal(report.Colors, []string{"web"}) || !slices.Equal(report.Pins, []string{"gone"}) || report.Count() != 4 {
+		t.Fatalf("FindOrphans() = %+v", report)
+	}
+	if hm.GetConnectionCount("gone") != 1 {
+		t.Fatal("Expected FindOrphans to change nothing")
+	}
+
+	cleaned, err := CleanupOrphans(hm, appConfig, known)
+	if err != nil || cleaned.Count() != 4 {
+		t.Fatalf("CleanupOrphans() = %+v, %v", cleaned, err)
+	}
+	if hm.GetConnectionCount("gone") != 0 || hm.GetConnectionCount("web-alias") != 1 {
+		t.Error("Expected only the history of missing hosts to be removed")
+	}
+	if remote, local := hm.GetLastDirs("gone"); remote != "" || local != "" {
+		t.Errorf("Expected the last directories of a missing host to be removed, got %q, %q", remote, local)
+	}
+	if _, ok := appConfig.HostColors["db"]; !ok || len(appConfig.HostColors) != 1 {
+		t.Errorf("HostColors = %v, want only db", appConfig.HostColors)
+	}
+	if _, ok := appConfig.PinnedPaths["db"]; !ok || len(appConfig.PinnedPaths) != 1 {
+		t.Errorf("PinnedPaths = %v, want only db", appConfig.PinnedPaths)
+	}
+
+	if again, _ := CleanupOrphans(hm, appConfig, known); again.Count() != 0 {
+		t.Errorf("Second CleanupOrphans() = %+v, want nothing left", again)
+	}
+}
+
+func TestCleanupDeletedKeepsOtherHosts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+
+	hm := createTestHistoryManager(t)
+	// "adhoc" was reached with sshc <host> and "lab" is in another config: neither is known
+	for _, name := range []string{"web", "shared", "adhoc", "lab"} {
+		if err := hm.RecordConnection(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appConfig := &config.AppConfig{
+		HostColors:  map[string]string{"web": "accent-1", "lab": "accent-2"},
+		PinnedPaths: map[string][]config.PinnedPath{"web": {{Path: "/var/www"}}, "adhoc": {{Path: "/tmp"}}},
+	}
+
+	// "shared" was deleted here but another config still defines it
+	cleaned, err := CleanupDeleted(hm, appConfig, []string{"web", "shared"}, []string{"shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cleaned.History, []string{"web"}) || !slices.Equal(cleaned.Colors, []string{"web"}) || !slices.Equal(cleaned.Pins, []string{"web"}) {
+		t.Errorf("CleanupDeleted() = %+v, want only web", cleaned)
+	}
+	for _, name := range []string{"shared", "adhoc", "lab"} {
+		if hm.GetConnectionCount(name) != 1 {
+			t.Errorf("Expected the history of %s to be kept", name)
+		}
+	}
+	if len(appConfig.HostColors) != 1 || len(appConfig.PinnedPaths) != 1 {
+		t.Errorf("HostColors = %v, PinnedPaths = %v, want lab and adhoc kept", appConfig.HostColors, appConfig.PinnedPaths)
+	}
+
+	if again, _ := CleanupDeleted(hm, appConfig, []string{"shared"}, []string{"shared"}); again.Count() != 0 {
+		t.Errorf("CleanupDeleted() of a known host = %+v, want nothing", again)
+	}
+}
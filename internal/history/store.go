@@ -0,0 +1,265 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+const (
+	// lockTimeout is how long a write waits for another sshc to finish with the history
+	lockTimeout = 5 * time.Second
+	lockRetry   = 20 * time.Millisecond
+
+	// journalCompactSize is the journal size past which it is folded into the history file
+	journalCompactSize = 64 << 10
+
+	// maxRecentConnects is how many connection times are kept per host for the
+	// connect rate warning
+	maxRecentConnects = 50
+)
+
+var errLockTimeout = errors.New("timed out waiting for another sshc to finish writing the history")
+
+// Operations recorded in the journal
+const (
+	journalConnect = "connect"
+	journalFailure = "failure"
+)
+
+// journalEntry is a connection appended to the journal instead of being written
+// into the history file
+type journalEntry struct {
+	Op   string    `json:"op"` // journalConnect or journalFailure
+	Host string    `json:"host"`
+	Time time.Time `json:"time"`
+}
+
+// apply adds the connection to h
+func (e journalEntry) apply(h *ConnectionHistory) {
+	conn, exists := h.Connections[e.Host]
+	if !exists {
+		conn = ConnectionInfo{HostName: e.Host}
+	}
+	switch e.Op {
+	case journalConnect:
+		conn.LastConnect = e.Time
+		conn.ConnectCount++
+		conn.RecentConnects = append(conn.RecentConnects, e.Time)
+		if len(conn.RecentConnects) > maxRecentConnects {
+			conn.RecentConnects = conn.RecentConnects[len(conn.RecentConnects)-maxRecentConnects:]
+		}
+	case journalFailure:
+		conn.FailedConnects++
+		conn.LastFailure = e.Time
+	}
+	h.Connections[e.Host] = conn
+}
+
+// SetJournal makes connections get appended to a journal next to the history file
+// rather than rewriting the whole file each time. The journal is folded into the
+// file once it grows past journalCompactSize or on the next other change.
+func (hm *HistoryManager) SetJournal(enabled bool) {
+	hm.journal = enabled
+}
+
+// RecoveredFrom returns where a history file that was not valid JSON was moved
+// when it was loaded, or "" if it was fine
+func (hm *HistoryManager) RecoveredFrom() string {
+	return hm.recoveredFrom
+}
+
+func (hm *HistoryManager) journalPath() string { return hm.historyPath + ".journal" }
+func (hm *HistoryManager) lockPath() string    { return hm.historyPath + ".lock" }
+
+// withLock runs fn while holding the lock that keeps sshc processes from writing
+// the history at the same time
+func (hm *HistoryManager) withLock(fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(hm.historyPath), 0700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(hm.lockPath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// update reads what other sshc processes wrote, applies change and writes the
+// result, all under the lock. Nothing is written when change returns false.
+func (hm *HistoryManager) update(change func(h *ConnectionHistory) bool) error {
+	return hm.withLock(func() error {
+		if err := hm.reload(); err != nil {
+			return err
+		}
+		if !change(hm.history) {
+			return nil
+		}
+		return hm.saveHistory()
+	})
+}
+
+// record adds a connection or failed connection. With the journal on it is
+// appended to the journal instead of rewriting the history file.
+func (hm *HistoryManager) record(entry journalEntry) error {
+	if !hm.journal {
+		return hm.update(func(h *ConnectionHistory) bool {
+			entry.apply(h)
+			return true
+		})
+	}
+	return hm.withLock(func() error {
+		entry.apply(hm.history)
+		size, err := hm.appendJournal(entry)
+		if err != nil || size < journalCompactSize {
+			return err
+		}
+		if err := hm.reload(); err != nil {
+			return err
+		}
+		return hm.saveHistory()
+	})
+}
+
+// reload replaces the history in memory with the file and journal on disk. The
+// history in memory is kept when there is neither, or when the file was corrupt.
+func (hm *HistoryManager) reload() error {
+	history, found, err := hm.readHistory()
+	if err != nil {
+		return err
+	}
+	if found {
+		hm.history = history
+	}
+	return nil
+}
+
+// readHistory reads the history file and replays the journal over it. A file that
+// is not valid JSON is moved aside, and reading goes on as if it did not exist.
+func (hm *HistoryManager) readHistory() (*ConnectionHistory, bool, error) {
+	history := &ConnectionHistory{}
+	found := false
+	data, err := os.ReadFile(hm.historyPath)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, history); err != nil {
+			if err := hm.quarantine(err); err != nil {
+				return nil, false, err
+			}
+			history = &ConnectionHistory{}
+		} else {
+			found = true
+		}
+	case !os.IsNotExist(err):
+		return nil, false, err
+	}
+	if history.Connections == nil {
+		history.Connections = make(map[string]ConnectionInfo)
+	}
+
+	replayed, err := hm.replayJournal(history)
+	if err != nil {
+		return nil, false, err
+	}
+	return history, found || replayed > 0, nil
+}
+
+// quarantine moves a history file that could not be parsed out of the way, keeping
+// it for a look by hand while a fresh history is started
+func (hm *HistoryManager) quarantine(parseErr error) error {
+	corruptPath := fmt.Sprintf("%s.corrupt-%s", hm.historyPath, time.Now().Format("20060102-150405"))
+	sshclog.Warn("history corrupt", "path", hm.historyPath, "moved_to", corruptPath, "err", parseErr)
+	if err := os.Rename(hm.historyPath, corruptPath); err != nil {
+		return fmt.Errorf("history file %s is corrupt (%v) and could not be moved aside: %w", hm.historyPath, parseErr, err)
+	}
+	hm.recoveredFrom = corruptPath
+	return nil
+}
+
+// replayJournal applies the journal to history and returns how many entries it
+// had. A line cut short by a crash is skipped.
+func (hm *HistoryManager) replayJournal(history *ConnectionHistory) (int, error) {
+	data, err := os.ReadFile(hm.journalPath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Host == "" {
+			sshclog.Warn("history journal line skipped", "path", hm.journalPath(), "line", replayed+1)
+			continue
+		}
+		entry.apply(history)
+		replayed++
+	}
+	return replayed, scanner.Err()
+}
+
+// appendJournal adds entry to the journal and returns the journal's new size
+func (hm *HistoryManager) appendJournal(entry journalEntry) (int64, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	sshclog.Write(hm.journalPath(), "history journal")
+	f, err := os.OpenFile(hm.journalPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return 0, err
+	}
+	info, err := f.Stat()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over
+// path, so that a reader sees either the old content or the new, never a mix
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
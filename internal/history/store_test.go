@@ -0,0 +1,190 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoadHistory_RecoversFromCorruptFile(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	corrupt := `{"connections":{"web":{"host_name":"web"}}{"connections":`
+	if err := os.WriteFile(hm.historyPath, []byte(corrupt), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hm.loadHistory(); err != nil {
+		t.Fatalf("loadHistory() error = %v, want the corrupt file recovered", err)
+	}
+	moved := hm.RecoveredFrom()
+	if moved == "" || !strings.HasPrefix(filepath.Base(moved), filepath.Base(hm.historyPath)+".corrupt-") {
+		t.Fatalf("RecoveredFrom() = %q, want the path the corrupt file was moved to", moved)
+	}
+	if data, err := os.ReadFile(moved); err != nil || string(data) != corrupt {
+		t.Errorf("corrupt file moved to %s = %q, %v; want its content kept", moved, data, err)
+	}
+	if _, err := os.Stat(hm.historyPath); !os.IsNotExist(err) {
+		t.Errorf("history file still exists after recovery, stat error = %v", err)
+	}
+	if len(hm.history.Connections) != 0 {
+		t.Errorf("history after recovery = %v, want it empty", hm.history.Connections)
+	}
+
+	// A fresh history is written on the next connection
+	if err := hm.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := &HistoryManager{historyPath: hm.historyPath, history: &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.RecoveredFrom() != "" || reloaded.GetConnectionCount("web") != 1 {
+		t.Errorf("reloaded history: recovered from %q, web count %d", reloaded.RecoveredFrom(), reloaded.GetConnectionCount("web"))
+	}
+}
+
+func TestHistoryManager_ConcurrentWritesKeepEveryRecord(t *testing.T) {
+	for _, journal := range []bool{false, true} {
+		t.Run(fmt.Sprintf("journal=%v", journal), func(t *testing.T) {
+			historyPath := filepath.Join(t.TempDir(), "sshc_history.json")
+			const writers, connects = 8, 10
+
+			// Each writer stands for a separate sshc process with its own view of the file
+			var wg sync.WaitGroup
+			errs := make(chan error, 2*writers*connects)
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					hm := &HistoryManager{historyPath: historyPath, history: &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}}
+					hm.SetJournal(journal)
+					for i := 0; i < connects; i++ {
+						errs <- hm.RecordConnection("shared")
+						errs <- hm.RecordConnection(fmt.Sprintf("host-%d", w))
+					}
+				}(w)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatalf("RecordConnection() error = %v", err)
+				}
+			}
+
+			hm := &HistoryManager{historyPath: historyPath, history: &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}}
+			if err := hm.loadHistory(); err != nil {
+				t.Fatal(err)
+			}
+			if hm.RecoveredFrom() != "" {
+				t.Fatalf("history was corrupted by concurrent writes, moved to %s", hm.RecoveredFrom())
+			}
+			if got := hm.GetConnectionCount("shared"); got != writers*connects {
+				t.Errorf("shared connection count = %d, want %d", got, writers*connects)
+			}
+			for w := 0; w < writers; w++ {
+				if got := hm.GetConnectionCount(fmt.Sprintf("host-%d", w)); got != connects {
+					t.Errorf("host-%d connection count = %d, want %d", w, got, connects)
+				}
+			}
+
+			entries, err := os.ReadDir(filepath.Dir(historyPath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if strings.Contains(entry.Name(), ".tmp-") {
+					t.Errorf("temporary file %s left behind", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryManager_WriteKeepsOtherProcessChanges(t *testing.T) {
+	first := createTestHistoryManager(t)
+	second := &HistoryManager{historyPath: first.historyPath, history: &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}}
+
+	if err := first.RecordConnection("web"); err != nil {
+		t.Fatal(err)
+	}
+	// second loaded nothing before first wrote, and must not drop web when it writes
+	if err := second.RecordSnippet("db", "uptime"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.loadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if first.GetConnectionCount("web") != 1 || len(first.GetSnippetHistory("db")) != 1 {
+		t.Errorf("history after both wrote = %+v", first.history.Connections)
+	}
+}
+
+func TestHistoryManager_Journal(t *testing.T) {
+	hm := createTestHistoryManager(t)
+	if err := hm.RecordSnippet("web", "uptime"); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(hm.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hm.SetJournal(true)
+	for i := 0; i < 3; i++ {
+		if err := hm.RecordConnection("web"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hm.RecordConnectFailure("db"); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.ReadFile(hm.historyPath); string(after) != string(before) {
+		t.Error("connections with the journal on rewrote the history file")
+	}
+
+	// A line cut short by a crash is skipped
+	f, err := os.OpenFile(hm.journalPath(), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"connect","ho`)
+	f.Close()
+
+	reloaded := &HistoryManager{historyPath: hm.historyPath, history: &ConnectionHistory{Connections: make(map[string]ConnectionInfo)}}
+	if err := reloaded.loadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.GetConnectionCount("web"); got != 3 {
+		t.Errorf("web connection count from the journal = %d, want 3", got)
+	}
+	if got := reloaded.GetConnectFailures()["db"]; got != 1 {
+		t.Errorf("db failures from the journal = %d, want 1", got)
+	}
+	if len(reloaded.GetSnippetHistory("web")) != 1 {
+		t.Error("snippet history in the file was lost when the journal was replayed")
+	}
+
+	// Any other change folds the journal into the file
+	if err := reloaded.RecordLastDirs("web", "/srv", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(hm.journalPath()); !os.IsNotExist(err) {
+		t.Errorf("journal still exists after a full write, stat error = %v", err)
+	}
+	var onDisk ConnectionHistory
+	data, err := os.ReadFile(hm.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &onDisk); err != nil {
+		t.Fatal(err)
+	}
+	if onDisk.Connections["web"].ConnectCount != 3 || onDisk.Connections["db"].FailedConnects != 1 {
+		t.Errorf("history file after folding the journal = %+v", onDisk.Connections)
+	}
+}
@@ -0,0 +1,227 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DefaultAnnotationTimeout bounds the annotation command when no timeout is configured
+const DefaultAnnotationTimeout = 10 * time.Second
+
+// AnnotationVersion is the version of the JSON written to the annotation command
+const AnnotationVersion = 1
+
+// MaxAnnotationLength is how many characters of an annotation are shown
+const MaxAnnotationLength = 40
+
+// maxAnnotationOutput caps how much the annotation command may print
+const maxAnnotationOutput = 1 << 20
+
+// AnnotationHost is what the annotation command is told about a host
+type AnnotationHost struct {
+	Name     string   `json:"name"`
+	Hostname string   `json:"hostname"`
+	User     string   `json:"user,omitempty"`
+	Port     string   `json:"port,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// AnnotationRequest is written to the annotation command's stdin
+type AnnotationRequest struct {
+	Version int              `json:"version"`
+	Hosts   []AnnotationHost `json:"hosts"`
+}
+
+// Annotation is a short note the annotation command returns for a host, with an
+// optional color slot such as "accent-2"
+type Annotation struct {
+	Text  string `json:"text"`
+	Color string `json:"color,omitempty"`
+}
+
+// UnmarshalJSON accepts either {"text": ..., "color": ...} or a plain string
+func (a *Annotation) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*a = Annotation{Text: text}
+		return nil
+	}
+	var object struct {
+		Text  *string `json:"text"`
+		Color string  `json:"color"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return errors.New("annotation must be a string or an object with a text")
+	}
+	if object.Text == nil {
+		return errors.New("annotation object has no text")
+	}
+	*a = Annotation{Text: *object.Text, Color: object.Color}
+	return nil
+}
+
+// Annotator runs the command that annotates the host list
+type Annotator struct {
+	Command string
+	Shell   bool // Run the command with sh -c, like the connect hooks
+	Timeout time.Duration
+}
+
+// AnnotatorFromAppConfig returns the annotation command set in the app config
+func AnnotatorFromAppConfig(c *config.AppConfig) Annotator {
+	if c == nil {
+		return Annotator{}
+	}
+	a := Annotator{
+		Command: strings.TrimSpace(c.AnnotationCommand),
+		Shell:   c.HookShell,
+		Timeout: DefaultAnnotationTimeout,
+	}
+	if c.AnnotationTimeout > 0 {
+		a.Timeout = time.Duration(c.AnnotationTimeout) * time.Second
+	}
+	return a
+}
+
+// Enabled reports whether an annotation command is configured
+func (a Annotator) Enabled() bool {
+	return a.Command != ""
+}
+
+// Run sends hosts to the annotation command as an AnnotationRequest and returns
+// the annotations it prints, by host name. A command that times out, exits with
+// an error or prints anything but the expected JSON returns an error, and no
+// annotations at all.
+func (a Annotator) Run(ctx context.Context, hosts []config.SSHHost) (map[string]Annotation, error) {
+	if !a.Enabled() {
+		return nil, nil
+	}
+	timeout := a.Timeout
+	if timeout <= 0 {
+		timeout = DefaultAnnotationTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request := AnnotationRequest{Version: AnnotationVersion, Hosts: make([]AnnotationHost, 0, len(hosts))}
+	for _, host := range hosts {
+		request.Hosts = append(request.Hosts, AnnotationHost{
+			Name:     host.Name,
+			Hostname: host.Hostname,
+			User:     host.User,
+			Port:     host.Port,
+			Tags:     host.Tags,
+		})
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := Hooks{Shell: a.Shell}.command(ctx, a.Command, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Stop a command that prints too much instead of waiting for its timeout
+	stdout := limitedBuffer{limit: maxAnnotationOutput, exceeded: cancel}
+	var stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait for children that keep the output open after a timeout
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if stdout.full {
+		return nil, fmt.Errorf("annotation command printed more than %d bytes", maxAnnotationOutput)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("annotation command timed out after %s", timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("annotation command failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("annotation command failed: %w", err)
+	}
+
+	names := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		names[host.Name] = true
+	}
+	return ParseAnnotations(stdout.buf.Bytes(), names)
+}
+
+// ParseAnnotations reads the output of the annotation command: a JSON object
+// mapping host names to an annotation. Hosts not in names are dropped, and so are
+// empty annotations; the text is cleaned of control characters and shortened to
+// MaxAnnotationLength.
+func ParseAnnotations(output []byte, names map[string]bool) (map[string]Annotation, error) {
+	var raw map[string]Annotation
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("annotation command printed invalid JSON: %w", err)
+	}
+	if raw == nil {
+		return nil, errors.New("annotation command printed null instead of an object")
+	}
+	if decoder.More() {
+		return nil, errors.New("annotation command printed more than one JSON value")
+	}
+
+	annotations := make(map[string]Annotation, len(raw))
+	for name, annotation := range raw {
+		if !names[name] {
+			continue
+		}
+		text := cleanAnnotation(annotation.Text)
+		if text == "" {
+			continue
+		}
+		annotations[name] = Annotation{Text: text, Color: strings.ToLower(strings.TrimSpace(annotation.Color))}
+	}
+	return annotations, nil
+}
+
+// cleanAnnotation keeps an annotation to one short line of plain text
+func cleanAnnotation(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, ansi.Strip(text))
+	text = strings.Join(strings.Fields(text), " ")
+	return ansi.Truncate(text, MaxAnnotationLength, "…")
+}
+
+// limitedBuffer collects output up to limit bytes. A write past it fails and
+// calls exceeded. The buffer is not embedded, so io.Copy can't bypass Write
+// through its ReadFrom.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	full     bool
+	exceeded func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		if !b.full {
+			b.full = true
+			b.exceeded()
+		}
+		return 0, fmt.Errorf("output is larger than %d bytes", b.limit)
+	}
+	return b.buf.Write(p)
+}
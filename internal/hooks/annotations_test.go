@@ -0,0 +1,158 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+var annotatedHosts = []config.SSHHost{
+	{Name: "web", Hostname: "web.example.com", User: "deploy", Port: "22", Tags: []string{"prod"}},
+	{Name: "db", Hostname: "10.0.0.5"},
+}
+
+func TestParseAnnotations(t *testing.T) {
+	names := map[string]bool{"web": true, "db": true, "cache": true}
+	output := `{
+		"web": {"text": "team-payments / prod", "color": " Accent-2 "},
+		"db": "owner: dba",
+		"cache": {"text": "   "},
+		"unknown": "not in the list"
+	}`
+	annotations, err := ParseAnnotations([]byte(output), names)
+	if err != nil {
+		t.Fatalf("ParseAnnotations() error = %v", err)
+	}
+	if got := annotations["web"]; got.Text != "team-payments / prod" || got.Color != "accent-2" {
+		t.Errorf("web = %+v", got)
+	}
+	if got := annotations["db"]; got.Text != "owner: dba" || got.Color != "" {
+		t.Errorf("db = %+v, want a plain string accepted", got)
+	}
+	if _, ok := annotations["cache"]; ok {
+		t.Error("Expected an empty annotation to be dropped")
+	}
+	if _, ok := annotations["unknown"]; ok {
+		t.Error("Expected an annotation of an unknown host to be dropped")
+	}
+}
+
+func TestParseAnnotationsCleansText(t *testing.T) {
+	names := map[string]bool{"web": true, "db": true}
+	output, _ := json.Marshal(map[string]string{
+		"web": "line one\nline\ttwo \x1b[31mred\x1b[0m\x07",
+		"db":  strings.Repeat("x", 100),
+	})
+	annotations, err := ParseAnnotations(output, names)
+	if err != nil {
+		t.Fatalf("ParseAnnotations() error = %v", err)
+	}
+	if got := annotations["web"].Text; got != "line one line two red" {
+		t.Errorf("web = %q, want one line of plain text", got)
+	}
+	if got := annotations["db"].Text; len([]rune(got)) != MaxAnnotationLength || !strings.HasSuffix(got, "…") {
+		t.Errorf("db = %q, want it shortened to %d characters", got, MaxAnnotationLength)
+	}
+}
+
+func TestParseAnnotationsRejectsMalformedOutput(t *testing.T) {
+	names := map[string]bool{"web": true}
+	for _, output := range []string{
+		``,
+		`not json`,
+		`null`,
+		`["web"]`,
+		`{"web": 42}`,
+		`{"web": {"color": "accent-1"}}`,
+		`{"web": {"text": 42}}`,
+		`{"web": "ok"} {"web": "again"}`,
+		`{"web": "ok"`,
+	} {
+		if annotations, err := ParseAnnotations([]byte(output), names); err == nil {
+			t.Errorf("ParseAnnotations(%q) = %v, want an error", output, annotations)
+		}
+	}
+}
+
+func TestAnnotatorSendsHostsAsJSON(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	input := filepath.Join(t.TempDir(), "input.json")
+	a := Annotator{Command: `sh -c 'cat > "$0"; echo "{\"web\": \"prod\"}"' ` + input}
+
+	annotations, err := a.Run(context.Background(), annotatedHosts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if annotations["web"].Text != "prod" || len(annotations) != 1 {
+		t.Errorf("annotations = %+v", annotations)
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request AnnotationRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("stdin is not a request: %v: %s", err, data)
+	}
+	if request.Version != AnnotationVersion || len(request.Hosts) != 2 {
+		t.Fatalf("request = %+v", request)
+	}
+	if web := request.Hosts[0]; web.Name != "web" || web.Hostname != "web.example.com" || web.User != "deploy" || web.Tags[0] != "prod" {
+		t.Errorf("web = %+v", web)
+	}
+}
+
+func TestAnnotatorFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	tests := []struct {
+		name      string
+		annotator Annotator
+		want      string
+	}{
+		{"non-zero exit", Annotator{Command: `sh -c "echo 'CMDB is down' >&2; exit 2"`}, "CMDB is down"},
+		{"timeout", Annotator{Command: "sleep 5", Timeout: 50 * time.Millisecond}, "timed out"},
+		{"malformed", Annotator{Command: "echo not json"}, "invalid JSON"},
+		{"too much output", Annotator{Command: "yes"}, "more than"},
+		{"missing command", Annotator{Command: "sshc-no-such-annotator"}, "annotation command failed"},
+		{"bad quoting", Annotator{Command: `echo "unterminated`}, "unterminated"},
+	}
+	for _, tt := range tests {
+		annotations, err := tt.annotator.Run(context.Background(), annotatedHosts)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+		if annotations != nil {
+			t.Errorf("%s: annotations = %v, want none", tt.name, annotations)
+		}
+	}
+}
+
+func TestAnnotatorFromAppConfig(t *testing.T) {
+	if AnnotatorFromAppConfig(nil).Enabled() {
+		t.Error("Expected no annotator without an app config")
+	}
+	a := AnnotatorFromAppConfig(&config.AppConfig{AnnotationCommand: " cmdb-notes ", AnnotationTimeout: 3, HookShell: true})
+	if a.Command != "cmdb-notes" || a.Timeout != 3*time.Second || !a.Shell {
+		t.Errorf("annotator = %+v", a)
+	}
+	if got := AnnotatorFromAppConfig(&config.AppConfig{AnnotationCommand: "x"}).Timeout; got != DefaultAnnotationTimeout {
+		t.Errorf("default timeout = %s", got)
+	}
+
+	// Without a command nothing runs
+	if annotations, err := (Annotator{}).Run(context.Background(), annotatedHosts); annotations != nil || err != nil {
+		t.Errorf("Run() without a command = %v, %v", annotations, err)
+	}
+}
@@ -0,0 +1,252 @@
+// Package hooks runs the user's pre- and post-connect commands and the command that annotates hosts
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/shellquote"
+)
+
+// DefaultTimeout bounds how long a hook may run when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// Target describes the connection a hook runs for
+type Target struct {
+	Host     string // Name of the host in the config, or what was typed for ad-hoc connections
+	Hostname string
+	User     string
+}
+
+// Hooks holds the configured connect hooks
+type Hooks struct {
+	PreConnect  string
+	PostConnect string
+	Shell       bool // Run hooks with sh -c instead of as a plain argument list
+	Timeout     time.Duration
+}
+
+// FromAppConfig returns the hooks set in the app config
+func FromAppConfig(c *config.AppConfig) Hooks {
+	if c == nil {
+		return Hooks{}
+	}
+	h := Hooks{
+		PreConnect:  strings.TrimSpace(c.PreConnectHook),
+		PostConnect: strings.TrimSpace(c.PostConnectHook),
+		Shell:       c.HookShell,
+		Timeout:     DefaultTimeout,
+	}
+	if c.HookTimeout > 0 {
+		h.Timeout = time.Duration(c.HookTimeout) * time.Second
+	}
+	return h
+}
+
+// Enabled reports whether any hook is configured
+func (h Hooks) Enabled() bool {
+	return h.PreConnect != "" || h.PostConnect != ""
+}
+
+// RunPre runs the pre-connect hook, sending its output to out. The connection must
+// not go ahead when it returns an error, which includes what the hook wrote to stderr.
+func (h Hooks) RunPre(target Target, out io.Writer) error {
+	if h.PreConnect == "" {
+		return nil
+	}
+	if err := h.run(h.PreConnect, target.vars(), out); err != nil {
+		return fmt.Errorf("pre-connect hook failed: %w", err)
+	}
+	return nil
+}
+
+// RunPost runs the post-connect hook with the exit code of ssh, sending its output to out
+func (h Hooks) RunPost(target Target, exitCode int, out io.Writer) error {
+	if h.PostConnect == "" {
+		return nil
+	}
+	vars := target.vars()
+	vars["exit_code"] = strconv.Itoa(exitCode)
+	if err := h.run(h.PostConnect, vars, out); err != nil {
+		return fmt.Errorf("post-connect hook failed: %w", err)
+	}
+	return nil
+}
+
+// vars returns the placeholder values for the target
+func (t Target) vars() map[string]string {
+	hostname := t.Hostname
+	if hostname == "" {
+		hostname = t.Host
+	}
+	return map[string]string{
+		"host":     t.Host,
+		"hostname": hostname,
+		"user":     t.User,
+	}
+}
+
+// run executes a hook with a timeout, returning its stderr as part of any error
+func (h Hooks) run(template string, vars map[string]string, out io.Writer) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd, err := h.command(ctx, template, vars)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// command builds the hook command. Without Shell, the template is split into words
+// first and each placeholder becomes part of a single argument, so values can never
+// add arguments or run shell syntax. With Shell, values are quoted for sh.
+func (h Hooks) command(ctx context.Context, template string, vars map[string]string) (*exec.Cmd, error) {
+	if h.Shell {
+		quoted := make(map[string]string, len(vars))
+		for name, value := range vars {
+			quoted[name] = shellquote.Quote(value)
+		}
+		return exec.CommandContext(ctx, "sh", "-c", expand(template, quoted)), nil
+	}
+
+	words, err := splitWords(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, errors.New("empty hook command")
+	}
+	for i, word := range words {
+		words[i] = expand(word, vars)
+	}
+	return exec.CommandContext(ctx, words[0], words[1:]...), nil
+}
+
+// expand replaces {name} placeholders in one pass, so substituted values are never
+// expanded again. Unknown placeholders are left as they are.
+func expand(s string, vars map[string]string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		if value, ok := vars[s[start+1:end]]; ok {
+			b.WriteString(s[:start])
+			b.WriteString(value)
+			s = s[end+1:]
+		} else {
+			b.WriteString(s[:start+1])
+			s = s[start+1:]
+		}
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// splitWords splits a command line into words the way a shell does for simple
+// commands: whitespace separates words, quotes group them and backslash escapes
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]):
+				i++
+				word.WriteRune(runes[i])
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in hook command", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// SSHErrorExitCode is the exit code of ssh when it failed itself, for example
+// because the host could not be reached, rather than the remote command
+const SSHErrorExitCode = 255
+
+// ExitCode returns the exit code of a finished command: 0 on success, the
+// process's code when it exited with one, and -1 otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,130 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"vpn-check --quiet", []string{"vpn-check", "--quiet"}},
+		{"  log  'two words'\t\"and {host}\" ", []string{"log", "two words", "and {host}"}},
+		{`a\ b "c\"d" 'e\f'`, []string{"a b", `c"d`, `e\f`}},
+		{`""`, []string{""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitWords(tt.input)
+		if err != nil {
+			t.Errorf("splitWords(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := splitWords("echo 'unterminated"); err == nil {
+		t.Error("Expected an error for an unterminated quote")
+	}
+}
+
+func TestExpandIsSinglePass(t *testing.T) {
+	vars := map[string]string{"host": "{user}", "user": "root"}
+	if got := expand("{host}@{user} {other} {", vars); got != "{user}@root {other} {" {
+		t.Errorf("expand() = %q", got)
+	}
+}
+
+func TestPlaceholdersCannotInjectArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	marker := filepath.Join(t.TempDir(), "injected")
+	target := Target{Host: "web; touch " + marker, User: "$(id)"}
+
+	var out bytes.Buffer
+	h := Hooks{PreConnect: "printf '%s|%s\\n' {host} {user}"}
+	if err := h.RunPre(target, &out); err != nil {
+		t.Fatalf("RunPre() error = %v", err)
+	}
+	if got := out.String(); got != target.Host+"|$(id)\n" {
+		t.Errorf("Expected placeholders as single literal arguments, got %q", got)
+	}
+
+	// With hook_shell the values are quoted for sh
+	out.Reset()
+	h = Hooks{PreConnect: "echo {host} {user}", Shell: true}
+	if err := h.RunPre(target, &out); err != nil {
+		t.Fatalf("RunPre() error = %v", err)
+	}
+	if got := out.String(); got != target.Host+" $(id)\n" {
+		t.Errorf("Expected quoted shell values, got %q", got)
+	}
+
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("A placeholder value was run as a command")
+	}
+}
+
+func TestPreHookFailureIncludesStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	h := Hooks{PreConnect: `sh -c "echo 'VPN is down' >&2; exit 3"`}
+	err := h.RunPre(Target{Host: "web"}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("Expected a failing pre-connect hook to return an error")
+	}
+	if !strings.Contains(err.Error(), "VPN is down") || ExitCode(err) != 3 {
+		t.Errorf("Expected the hook's stderr and exit code in the error, got %v", err)
+	}
+
+	h = Hooks{PreConnect: "sleep 5", Timeout: 50 * time.Millisecond}
+	if err := h.RunPre(Target{Host: "web"}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestPostHookGetsExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	var out bytes.Buffer
+	h := Hooks{PostConnect: "echo {host} {hostname} {exit_code}"}
+	if err := h.RunPost(Target{Host: "web"}, 255, &out); err != nil {
+		t.Fatalf("RunPost() error = %v", err)
+	}
+	// Without a HostName the host name stands in for it
+	if got := out.String(); got != "web web 255\n" {
+		t.Errorf("RunPost() output = %q", got)
+	}
+
+	if err := (Hooks{}).RunPost(Target{}, 0, &out); err != nil {
+		t.Errorf("Expected no hook to be a no-op, got %v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if ExitCode(nil) != 0 {
+		t.Error("Expected 0 for success")
+	}
+	if runtime.GOOS != "windows" {
+		if code := ExitCode(exec.Command("sh", "-c", "exit 7").Run()); code != 7 {
+			t.Errorf("ExitCode() = %d, want 7", code)
+		}
+	}
+	if ExitCode(os.ErrNotExist) != -1 {
+		t.Error("Expected -1 when the command did not exit")
+	}
+}
@@ -0,0 +1,257 @@
+// Package keys inspects private key files without needing their passphrase
+package keys
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// opensshMagic starts the body of an "OPENSSH PRIVATE KEY" block
+const opensshMagic = "openssh-key-v1\x00"
+
+// ExpandPath resolves ~ and surrounding quotes in an IdentityFile value
+func ExpandPath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), `"`)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	return path
+}
+
+// IsEncrypted reports whether the private key file at path is protected by a passphrase
+func IsEncrypted(path string) (bool, error) {
+	data, err := os.ReadFile(ExpandPath(path))
+	if err != nil {
+		return false, err
+	}
+	return isEncryptedKey(data)
+}
+
+// isEncryptedKey checks the header of a PEM or OpenSSH private key
+func isEncryptedKey(data []byte) (bool, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false, errors.New("not a PEM encoded private key")
+	}
+
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		key, err := parseOpenSSHKey(block.Bytes)
+		if err != nil {
+			return false, err
+		}
+		return key.cipher != "none", nil
+	case "ENCRYPTED PRIVATE KEY":
+		// PKCS#8 wraps the whole key in encryption
+		return true, nil
+	default:
+		// Traditional PEM keys mark encryption with a Proc-Type header
+		return strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED"), nil
+	}
+}
+
+// opensshKey is the unencrypted prefix of an OpenSSH private key
+type opensshKey struct {
+	cipher    string
+	publicKey []byte
+}
+
+// parseOpenSSHKey reads the cipher and first public key of an OpenSSH key body,
+// both of which are stored in the clear
+func parseOpenSSHKey(body []byte) (opensshKey, error) {
+	var key opensshKey
+	if !bytes.HasPrefix(body, []byte(opensshMagic)) {
+		return key, errors.New("invalid OpenSSH private key")
+	}
+	rest := body[len(opensshMagic):]
+
+	var fields [3][]byte // cipher name, KDF name, KDF options
+	for i := range fields {
+		var ok bool
+		if fields[i], rest, ok = readString(rest); !ok {
+			return key, errors.New("truncated OpenSSH private key")
+		}
+	}
+	key.cipher = string(fields[0])
+
+	if len(rest) < 4 || binary.BigEndian.Uint32(rest) < 1 {
+		return key, errors.New("OpenSSH private key holds no keys")
+	}
+	publicKey, _, ok := readString(rest[4:])
+	if !ok {
+		return key, errors.New("truncated OpenSSH private key")
+	}
+	key.publicKey = publicKey
+	return key, nil
+}
+
+// readString reads an SSH wire format string: a 32-bit length and that many bytes
+func readString(data []byte) ([]byte, []byte, bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(len(data)-4) < uint64(n) {
+		return nil, nil, false
+	}
+	return data[4 : 4+n], data[4+n:], true
+}
+
+// publicKeyBlob returns the wire format public key of the private key at path, read
+// from the key itself when it is in OpenSSH format and otherwise from path.pub
+func publicKeyBlob(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(data); block != nil && block.Type == "OPENSSH PRIVATE KEY" {
+		if key, err := parseOpenSSHKey(block.Bytes); err == nil {
+			return key.publicKey, nil
+		}
+	}
+
+	pub, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		return nil, err
+	}
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return publicKey.Marshal(), nil
+}
+
+// InAgent reports whether the key at path is loaded in the running ssh-agent.
+// It is false when there is no agent or the public key cannot be determined.
+func InAgent(path string) bool {
+	blob, err := publicKeyBlob(ExpandPath(path))
+	if err != nil {
+		return false
+	}
+
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return false
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	loaded, err := agent.NewClient(conn).List()
+	if err != nil {
+		return false
+	}
+	for _, key := range loaded {
+		if bytes.Equal(key.Blob, blob) {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsPassphrase reports whether connecting with the key at path will prompt for
+// its passphrase, because it is encrypted and not loaded in the agent
+func NeedsPassphrase(path string) bool {
+	if path == "" {
+		return false
+	}
+	encrypted, err := IsEncrypted(path)
+	return err == nil && encrypted && !InAgent(path)
+}
+
+// addArgs returns the ssh-add arguments that load path, storing the passphrase
+// in the keychain on macOS
+func addArgs(goos, path string) []string {
+	if goos == "darwin" {
+		return []string{"--apple-use-keychain", path}
+	}
+	return []string{path}
+}
+
+// AddCommand returns the ssh-add command that loads the key at path into the agent
+func AddCommand(path string) *exec.Cmd {
+	return exec.Command("ssh-add", addArgs(runtime.GOOS, ExpandPath(path))...)
+}
+
+// AddCommandLine returns AddCommand as text to show to the user
+func AddCommandLine(path string) string {
+	return fmt.Sprintf("ssh-add %s", strings.Join(addArgs(runtime.GOOS, path), " "))
+}
+
+// maxPublicKeySize bounds what ReadPublicKey reads; public keys are a single line
+const maxPublicKeySize = 16 * 1024
+
+// PublicKeyPath returns the public key file belonging to an IdentityFile value
+func PublicKeyPath(identity string) string {
+	path := ExpandPath(identity)
+	if strings.HasSuffix(path, ".pub") {
+		return path
+	}
+	return path + ".pub"
+}
+
+// ReadPublicKey reads a public key file in authorized_keys format and returns its
+// line. It refuses anything that could be a private key: paths not ending in .pub,
+// .pub symlinks to other files, and files that do not parse as a public key.
+func ReadPublicKey(path string) (string, error) {
+	path = ExpandPath(path)
+	if !strings.HasSuffix(path, ".pub") {
+		return "", fmt.Errorf("%s: only .pub files are read as public keys", path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(resolved, ".pub") {
+		return "", fmt.Errorf("%s: links to %s, which is not a .pub file", path, resolved)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxPublicKeySize {
+		return "", fmt.Errorf("%s: not a public key file", path)
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return "", err
+	}
+	line, err := ParsePublicKey(string(data))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return line, nil
+}
+
+// ParsePublicKey checks that data is a single public key in authorized_keys
+// format and returns it trimmed. Private keys are rejected.
+func ParsePublicKey(data string) (string, error) {
+	if strings.Contains(data, "PRIVATE KEY") {
+		return "", errors.New("contains a private key")
+	}
+	line := strings.TrimSpace(data)
+	if strings.Contains(line, "\n") {
+		return "", errors.New("not a single public key")
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line)); err != nil {
+		return "", fmt.Errorf("not a public key: %w", err)
+	}
+	return line, nil
+}
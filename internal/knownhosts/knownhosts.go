@@ -0,0 +1,184 @@
+// Package knownhosts scans host keys with ssh-keyscan and records them in known_hosts
+package knownhosts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+)
+
+// ScanTimeout bounds a whole ssh-keyscan run
+const ScanTimeout = 15 * time.Second
+
+// Status is how a host's scanned keys compare to known_hosts
+type Status int
+
+const (
+	// Unknown means known_hosts has no key for the host yet
+	Unknown Status = iota
+	// Known means one of the scanned keys is already trusted
+	Known
+	// Changed means known_hosts has keys for the host but none of them match
+	Changed
+)
+
+// HostKey is a public key offered by a host
+type HostKey struct {
+	Type        string // Key algorithm, such as ssh-ed25519
+	Fingerprint string // SHA256 fingerprint as printed by ssh
+	Line        string // known_hosts line for the key
+	key         ssh.PublicKey
+}
+
+// DefaultPath returns the user's known_hosts file
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh", "known_hosts"), nil
+}
+
+// Scan fetches the host keys of address:port with ssh-keyscan. The returned lines
+// store the keys under name, which is what ssh looks up for the host (see
+// config.SSHHost.KnownHostsName).
+func Scan(ctx context.Context, address, port, name string) ([]HostKey, error) {
+	if _, err := exec.LookPath("ssh-keyscan"); err != nil {
+		return nil, errors.New("ssh-keyscan is not installed")
+	}
+	ctx, cancel := context.WithTimeout(ctx, ScanTimeout)
+	defer cancel()
+
+	args := []string{"-T", "5"}
+	if port != "" && port != "22" {
+		args = append(args, "-p", port)
+	}
+	args = append(args, address)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh-keyscan", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	keys := parseScan(stdout.String(), name)
+	if len(keys) > 0 {
+		return keys, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s did not answer in time", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ssh-keyscan failed: %v", err)
+	}
+	return nil, fmt.Errorf("%s did not offer any host key", address)
+}
+
+// parseScan reads ssh-keyscan output, skipping comments and keys it cannot parse
+func parseScan(output, name string) []HostKey {
+	var keys []HostKey
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fields[1] + " " + fields[2]))
+		if err != nil {
+			continue
+		}
+		keys = append(keys, HostKey{
+			Type:        key.Type(),
+			Fingerprint: ssh.FingerprintSHA256(key),
+			Line:        name + " " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+			key:         key,
+		})
+	}
+	return keys
+}
+
+// Check compares scanned keys with the ones path has for name. A missing file means
+// no key is known yet.
+func Check(path, name string, keys []HostKey) (Status, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Unknown, nil
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return Unknown, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	address := lookupAddress(name)
+	remote := &net.TCPAddr{IP: net.IPv4zero}
+	status := Unknown
+	for _, key := range keys {
+		err := callback(address, remote, key.key)
+		var keyErr *knownhosts.KeyError
+		switch {
+		case err == nil:
+			return Known, nil
+		case errors.As(err, &keyErr):
+			if len(keyErr.Want) > 0 {
+				status = Changed
+			}
+		default:
+			return Unknown, err
+		}
+	}
+	return status, nil
+}
+
+// lookupAddress turns a known_hosts name such as "[db]:2222" into the host:port form
+// the knownhosts callback expects
+func lookupAddress(name string) string {
+	if strings.HasPrefix(name, "[") {
+		if host, port, err := net.SplitHostPort(name); err == nil {
+			return net.JoinHostPort(host, port)
+		}
+	}
+	return net.JoinHostPort(name, "22")
+}
+
+// Add appends the keys to path, creating the file and its directory when needed
+func Add(path string, keys []HostKey) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var b strings.Builder
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	for _, key := range keys {
+		b.WriteString(key.Line + "\n")
+	}
+
+	// Written in one call so a failure cannot leave some of the keys behind
+	sshclog.Write(path, "append known hosts")
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,78 @@
+package knownhosts
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// scanLine returns an ssh-keyscan output line for a new random key
+func scanLine(t *testing.T, host string) string {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host + " " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
+
+func TestParseScan(t *testing.T) {
+	output := "# db.example.com:2222 SSH-2.0-OpenSSH_9.6\n" +
+		scanLine(t, "[db.example.com]:2222") + "\n" +
+		"[db.example.com]:2222 ssh-rsa not-base64\n"
+
+	keys := parseScan(output, "[db]:2222")
+	if len(keys) != 1 {
+		t.Fatalf("Expected 1 key, got %+v", keys)
+	}
+	if keys[0].Type != "ssh-ed25519" || !strings.HasPrefix(keys[0].Fingerprint, "SHA256:") {
+		t.Errorf("Unexpected key %+v", keys[0])
+	}
+	if !strings.HasPrefix(keys[0].Line, "[db]:2222 ssh-ed25519 ") {
+		t.Errorf("Expected the key stored under the host's known_hosts name, got %q", keys[0].Line)
+	}
+}
+
+func TestCheckAndAdd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ssh", "known_hosts")
+	keys := parseScan(scanLine(t, "web.example.com"), "web")
+	other := parseScan(scanLine(t, "web.example.com"), "web")
+
+	if status, err := Check(path, "web", keys); err != nil || status != Unknown {
+		t.Fatalf("Check() on a missing file = %v, %v", status, err)
+	}
+
+	// A file without a trailing newline must not get the new line glued to its last one
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(parseScan(scanLine(t, "db"), "db")[0].Line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(path, keys); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 2 || lines[1] != keys[0].Line {
+		t.Errorf("Unexpected known_hosts content:\n%s", content)
+	}
+
+	if status, err := Check(path, "web", keys); err != nil || status != Known {
+		t.Errorf("Check() after Add = %v, %v, want Known", status, err)
+	}
+	if status, err := Check(path, "web", other); err != nil || status != Changed {
+		t.Errorf("Check() with a different key = %v, %v, want Changed", status, err)
+	}
+	if status, err := Check(path, "[web]:2222", other); err != nil || status != Unknown {
+		t.Errorf("Check() on another port = %v, %v, want Unknown", status, err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package metrics collects the timings shown in the debug overlay. The per-message
+// and per-render collectors only run while Enabled, so they cost one atomic load
+// when the overlay is closed. Config parses are rare and always recorded, so they
+// can be shown as soon as the overlay opens.
+package metrics
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Window is how many of the latest samples a Rolling keeps
+const Window = 64
+
+var enabled atomic.Bool
+
+// Enabled reports whether the per-message collectors should record
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// SetEnabled turns the per-message collectors on or off
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+// The collectors the debug overlay shows
+var (
+	ConfigParse  ParseLog // Duration of each config file of the last parse
+	TableRebuild Rolling  // Rebuilds of the host table
+	MessageLoop  Interval // Time between the messages of the TUI
+	Cache        Ratio    // Cache lookups
+)
+
+// Rolling keeps the last Window samples of a duration
+type Rolling struct {
+	mu      sync.Mutex
+	samples [Window]time.Duration
+	next    int // Index the next sample is written to
+	count   int // Samples seen since the last Reset
+}
+
+// RollingStats summarizes the samples a Rolling keeps
+type RollingStats struct {
+	Last  time.Duration
+	Avg   time.Duration // Average of the samples in the window
+	Max   time.Duration // Largest sample in the window
+	Count int           // Samples seen in total
+}
+
+// Add records a sample
+func (r *Rolling) Add(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % Window
+	r.count++
+}
+
+// Since records the time elapsed since start, for use with defer
+func (r *Rolling) Since(start time.Time) {
+	r.Add(time.Since(start))
+}
+
+// Stats returns the last sample and the average and maximum of the window
+func (r *Rolling) Stats() RollingStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := RollingStats{Count: r.count}
+	n := min(r.count, Window)
+	if n == 0 {
+		return stats
+	}
+	stats.Last = r.samples[(r.next+Window-1)%Window]
+	var total time.Duration
+	for _, sample := range r.samples[:n] {
+		total += sample
+		stats.Max = max(stats.Max, sample)
+	}
+	stats.Avg = total / time.Duration(n)
+	return stats
+}
+
+// Reset drops every sample
+func (r *Rolling) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next, r.count = 0, 0
+}
+
+// Interval measures the time between successive events
+type Interval struct {
+	Rolling
+	lastMu sync.Mutex
+	last   time.Time
+}
+
+// Tick records the time since the previous tick. The first tick only starts
+// the measurement.
+func (i *Interval) Tick(now time.Time) {
+	i.lastMu.Lock()
+	last := i.last
+	i.last = now
+	i.lastMu.Unlock()
+	if !last.IsZero() {
+		i.Add(now.Sub(last))
+	}
+}
+
+// Reset drops every sample and forgets the last tick, so a pause while
+// disabled is not counted
+func (i *Interval) Reset() {
+	i.lastMu.Lock()
+	i.last = time.Time{}
+	i.lastMu.Unlock()
+	i.Rolling.Reset()
+}
+
+// Ratio counts hits and misses
+type Ratio struct {
+	hits, misses atomic.Int64
+}
+
+// Hit counts a hit
+func (r *Ratio) Hit() { r.hits.Add(1) }
+
+// Miss counts a miss
+func (r *Ratio) Miss() { r.misses.Add(1) }
+
+// Counts returns the hits and misses so far
+func (r *Ratio) Counts() (hits, misses int64) {
+	return r.hits.Load(), r.misses.Load()
+}
+
+// Rate returns the share of hits, or false before the first lookup
+func (r *Ratio) Rate() (float64, bool) {
+	hits, misses := r.Counts()
+	if hits+misses == 0 {
+		return 0, false
+	}
+	return float64(hits) / float64(hits+misses), true
+}
+
+// FileParse is the parse of one config file
+type FileParse struct {
+	Path     string
+	Depth    int           // Include depth, 0 for the main config
+	Duration time.Duration // Including the files it includes
+}
+
+// ParseLog keeps the files of the last config parse
+type ParseLog struct {
+	mu    sync.Mutex
+	files []FileParse
+}
+
+// Reset starts a new parse
+func (p *ParseLog) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files = nil
+}
+
+// Record adds the parse of a file, replacing an earlier parse of the same file
+// since the last Reset
+func (p *ParseLog) Record(path string, depth int, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	parse := FileParse{Path: path, Depth: depth, Duration: d}
+	if i := slices.IndexFunc(p.files, func(f FileParse) bool { return f.Path == path }); i >= 0 {
+		p.files[i] = parse
+		return
+	}
+	p.files = append(p.files, parse)
+}
+
+// Files returns the files of the last parse, slowest first
+func (p *ParseLog) Files() []FileParse {
+	p.mu.Lock()
+	files := slices.Clone(p.files)
+	p.mu.Unlock()
+	slices.SortStableFunc(files, func(a, b FileParse) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	return files
+}
+
+// Summary returns the duration of the whole parse and the deepest Include
+func (p *ParseLog) Summary() (total time.Duration, depth int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, file := range p.files {
+		if file.Depth == 0 {
+			total += file.Duration
+		}
+		depth = max(depth, file.Depth)
+	}
+	return total, depth
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRollingStats(t *testing.T) {
+	var r Rolling
+	if stats := r.Stats(); stats != (RollingStats{}) {
+		t.Errorf("Stats() without samples = %+v", stats)
+	}
+
+	for _, ms := range []int{4, 10, 1} {
+		r.Add(time.Duration(ms) * time.Millisecond)
+	}
+	want := RollingStats{Last: time.Millisecond, Avg: 5 * time.Millisecond, Max: 10 * time.Millisecond, Count: 3}
+	if stats := r.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestRollingDropsSamplesOutsideWindow(t *testing.T) {
+	var r Rolling
+	// A slow sample followed by a full window of fast ones no longer counts
+	r.Add(time.Second)
+	for i := 1; i <= Window; i++ {
+		r.Add(time.Duration(i) * time.Millisecond)
+	}
+	stats := r.Stats()
+	if stats.Max != Window*time.Millisecond {
+		t.Errorf("Max = %v, want the largest sample in the window", stats.Max)
+	}
+	if want := (Window + 1) * time.Millisecond / 2; stats.Avg != want {
+		t.Errorf("Avg = %v, want %v", stats.Avg, want)
+	}
+	if stats.Last != Window*time.Millisecond || stats.Count != Window+1 {
+		t.Errorf("Last = %v, Count = %d", stats.Last, stats.Count)
+	}
+
+	r.Reset()
+	if stats := r.Stats(); stats.Count != 0 || stats.Max != 0 {
+		t.Errorf("Stats() after Reset = %+v", stats)
+	}
+}
+
+func TestIntervalMeasuresBetweenTicks(t *testing.T) {
+	var i Interval
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	i.Tick(start)
+	if stats := i.Stats(); stats.Count != 0 {
+		t.Errorf("Expected the first tick to record nothing, got %+v", stats)
+	}
+	i.Tick(start.Add(20 * time.Millisecond))
+	i.Tick(start.Add(120 * time.Millisecond))
+	want := RollingStats{Last: 100 * time.Millisecond, Avg: 60 * time.Millisecond, Max: 100 * time.Millisecond, Count: 2}
+	if stats := i.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+
+	// A pause between Reset and the next tick is not a gap between messages
+	i.Reset()
+	i.Tick(start.Add(time.Hour))
+	if stats := i.Stats(); stats.Count != 0 {
+		t.Errorf("Expected Reset to forget the last tick, got %+v", stats)
+	}
+}
+
+func TestRatio(t *testing.T) {
+	var r Ratio
+	if _, ok := r.Rate(); ok {
+		t.Error("Expected no rate before the first lookup")
+	}
+	r.Hit()
+	r.Hit()
+	r.Hit()
+	r.Miss()
+	if rate, ok := r.Rate(); !ok || rate != 0.75 {
+		t.Errorf("Rate() = %v, %v; want 0.75", rate, ok)
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	var p ParseLog
+	p.Record("/home/me/.ssh/config.d/work", 1, 2*time.Millisecond)
+	p.Record("/home/me/.ssh/config.d/deep", 2, time.Millisecond)
+	p.Record("/home/me/.ssh/config", 0, 5*time.Millisecond)
+	// Reading the same file again replaces its entry
+	p.Record("/home/me/.ssh/config.d/deep", 2, 3*time.Millisecond)
+
+	total, depth := p.Summary()
+	if total != 5*time.Millisecond || depth != 2 {
+		t.Errorf("Summary() = %v, %d; want the main config's duration and depth 2", total, depth)
+	}
+	var order []string
+	for _, file := range p.Files() {
+		order = append(order, file.Path)
+	}
+	want := []string{"/home/me/.ssh/config", "/home/me/.ssh/config.d/deep", "/home/me/.ssh/config.d/work"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("Files() = %q, want slowest first %q", order, want)
+	}
+
+	p.Reset()
+	if files := p.Files(); len(files) != 0 {
+		t.Errorf("Files() after Reset = %v", files)
+	}
+}
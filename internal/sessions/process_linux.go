@@ -0,0 +1,36 @@
+package sessions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// listProcesses reads the arguments of every process from /proc, which keeps
+// arguments containing spaces whole
+func listProcesses(ctx context.Context) ([]Process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var processes []Process
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return processes, ctx.Err()
+		}
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Processes may exit while they are read
+		data, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+		if args := ParseCmdline(data); len(args) > 0 {
+			processes = append(processes, Process{PID: pid, Args: args})
+		}
+	}
+	return processes, nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux && !windows
+
+package sessions
+
+import (
+	"context"
+	"os/exec"
+)
+
+// listProcesses lists every process with ps
+func listProcesses(ctx context.Context) ([]Process, error) {
+	output, err := exec.CommandContext(ctx, "ps", "axww", "-o", "pid=,args=").Output()
+	if err != nil {
+		return nil, err
+	}
+	return ParsePS(string(output)), nil
+}
@@ -0,0 +1,16 @@
+package sessions
+
+import (
+	"context"
+	"os/exec"
+)
+
+// listProcesses lists the ssh processes with wmic. It is missing from recent
+// Windows releases, in which case only control sockets are found.
+func listProcesses(ctx context.Context) ([]Process, error) {
+	output, err := exec.CommandContext(ctx, "wmic", "process", "where", "name='ssh.exe'", "get", "CommandLine,ProcessId", "/format:csv").Output()
+	if err != nil {
+		return nil, err
+	}
+	return ParseWMIC(string(output)), nil
+}
@@ -0,0 +1,424 @@
+// Package sessions finds the ssh sessions open to each host on this machine:
+// ssh processes connected to it, and ControlMaster sockets shared with them
+package sessions
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// Process is a running process and its arguments, the program first
+type Process struct {
+	PID  int
+	Args []string
+}
+
+// Target is where an ssh command line connects to. Host is the destination as
+// given, an alias or an address; User and Port are empty unless given.
+type Target struct {
+	User string
+	Host string
+	Port string
+}
+
+// Count is what is open to a host
+type Count struct {
+	Processes int  // ssh processes connected to it
+	Socket    bool // Its ControlPath socket exists, so a master connection may be shared
+}
+
+// Active reports whether anything is open to the host
+func (c Count) Active() bool {
+	return c.Processes > 0 || c.Socket
+}
+
+// Host is a host to count sessions for, with the settings ssh resolves for it
+type Host struct {
+	config.SSHHost
+	ControlPath string // As configured, tokens unexpanded; "" or "none" for no socket
+}
+
+// optionsWithArgument are the ssh flags that take an argument
+const optionsWithArgument = "bcDeEFIiJlLmOopQRSwWB"
+
+// ParseArgs reads the destination of an ssh command line. It follows ssh's own
+// parsing: flags may be grouped and their argument attached or separate, options
+// may follow the destination, and the first argument after it starts the remote
+// command. ok is false for other programs and for ssh invocations that do not
+// connect anywhere, such as ssh -V, ssh -G or ssh -O check.
+func ParseArgs(args []string) (target Target, ok bool) {
+	if len(args) == 0 || !isSSH(args[0]) {
+		return Target{}, false
+	}
+
+	var host string
+	terminated := false
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if terminated || arg == "-" || !strings.HasPrefix(arg, "-") {
+			if host != "" {
+				break // Start of the remote command
+			}
+			host = arg
+			if terminated {
+				break
+			}
+			continue
+		}
+		if arg == "--" {
+			terminated = true
+			continue
+		}
+
+		for j := 1; j < len(arg); j++ {
+			flag := arg[j]
+			if !strings.ContainsRune(optionsWithArgument, rune(flag)) {
+				switch flag {
+				case 'G', 'V':
+					return Target{}, false
+				}
+				continue
+			}
+			value := arg[j+1:]
+			if value == "" {
+				if i+1 >= len(args) {
+					return Target{}, false
+				}
+				i++
+				value = args[i]
+			}
+			switch flag {
+			case 'l':
+				target.User = value
+			case 'p':
+				target.Port = value
+			case 'o':
+				applyOption(&target, value)
+			case 'O', 'Q':
+				return Target{}, false
+			}
+			break
+		}
+	}
+	if host == "" {
+		return Target{}, false
+	}
+
+	user, hostPart, port := splitDestination(host)
+	if user != "" && target.User == "" {
+		target.User = user
+	}
+	if port != "" && target.Port == "" {
+		target.Port = port
+	}
+	target.Host = hostPart
+	return target, target.Host != ""
+}
+
+// isSSH reports whether program is the ssh client, given as a name or a path
+func isSSH(program string) bool {
+	name := program
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToLower(strings.Trim(name, `"`))
+	return name == "ssh" || name == "ssh.exe"
+}
+
+// applyOption takes the user and port from an -o option
+func applyOption(target *Target, option string) {
+	keyword, value := option, ""
+	if i := strings.IndexAny(option, " \t="); i >= 0 {
+		keyword = option[:i]
+		value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(option[i:]), "="))
+	}
+	switch strings.ToLower(keyword) {
+	case "user":
+		if target.User == "" {
+			target.User = value
+		}
+	case "port":
+		if target.Port == "" {
+			target.Port = value
+		}
+	}
+}
+
+// splitDestination splits [user@]host or ssh://[user@]host[:port]
+func splitDestination(destination string) (user, host, port string) {
+	rest, isURI := strings.CutPrefix(destination, "ssh://")
+	if i := strings.LastIndexByte(rest, '@'); i >= 0 {
+		user, rest = rest[:i], rest[i+1:]
+	}
+	if !isURI {
+		return user, rest, ""
+	}
+	rest = strings.TrimSuffix(rest, "/")
+	if h, p, err := net.SplitHostPort(rest); err == nil {
+		return user, h, p
+	}
+	return user, strings.Trim(rest, "[]"), ""
+}
+
+// ParsePS reads the output of ps -o pid=,args=. ps joins the arguments with
+// spaces, so an argument that contains spaces comes out split in several.
+func ParsePS(output string) []Process {
+	var processes []Process
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue // Header line
+		}
+		processes = append(processes, Process{PID: pid, Args: fields[1:]})
+	}
+	return processes
+}
+
+// ParseCmdline reads /proc/<pid>/cmdline, where the arguments are separated by
+// NUL bytes
+func ParseCmdline(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+}
+
+// ParseWMIC reads the output of wmic process get CommandLine,ProcessId /format:csv.
+// wmic does not quote the fields, so the command line is taken as everything
+// between the node name and the process ID, commas included.
+func ParseWMIC(output string) []Process {
+	var processes []Process
+	header := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !header {
+			header = strings.HasSuffix(line, ",CommandLine,ProcessId")
+			continue
+		}
+		first, last := strings.IndexByte(line, ','), strings.LastIndexByte(line, ',')
+		if first < 0 || last <= first {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(line[last+1:]))
+		if err != nil {
+			continue
+		}
+		if args := SplitCommandLine(line[first+1 : last]); len(args) > 0 {
+			processes = append(processes, Process{PID: pid, Args: args})
+		}
+	}
+	return processes
+}
+
+// SplitCommandLine splits a Windows command line into arguments the way the C
+// runtime does: double quotes group an argument, backslashes are literal except
+// before a quote, where each pair stands for one backslash and an odd one out
+// escapes the quote, and "" inside quotes stands for one quote.
+func SplitCommandLine(commandLine string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, started := false, false
+	for i := 0; i < len(commandLine); i++ {
+		c := commandLine[i]
+		switch {
+		case c == '\\':
+			backslashes := 1
+			for i+backslashes < len(commandLine) && commandLine[i+backslashes] == '\\' {
+				backslashes++
+			}
+			i += backslashes - 1
+			started = true
+			if i+1 >= len(commandLine) || commandLine[i+1] != '"' {
+				current.WriteString(strings.Repeat("\\", backslashes))
+				continue
+			}
+			current.WriteString(strings.Repeat("\\", backslashes/2))
+			if backslashes%2 == 1 {
+				current.WriteByte('"')
+				i++
+			}
+		case c == '"':
+			started = true
+			if inQuotes && i+1 < len(commandLine) && commandLine[i+1] == '"' {
+				current.WriteByte('"')
+				i++
+				continue
+			}
+			inQuotes = !inQuotes
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteByte(c)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// Matches reports whether a target connects to host: it names the host's alias,
+// or its address on the same port, and with the same user where both give one
+func (t Target) Matches(host config.SSHHost) bool {
+	if strings.EqualFold(t.Host, host.Name) {
+		return true
+	}
+	if host.Hostname == "" || !strings.EqualFold(t.Host, host.Hostname) {
+		return false
+	}
+	if t.User != "" && host.User != "" && t.User != host.User {
+		return false
+	}
+	return defaultPort(t.Port) == defaultPort(host.Port)
+}
+
+// defaultPort returns port, or ssh's default port when it is not given
+func defaultPort(port string) string {
+	if port == "" {
+		return "22"
+	}
+	return port
+}
+
+// CountProcesses counts the ssh processes connected to each host by name
+func CountProcesses(processes []Process, hosts []config.SSHHost) map[string]int {
+	counts := make(map[string]int)
+	for _, process := range processes {
+		target, ok := ParseArgs(process.Args)
+		if !ok {
+			continue
+		}
+		for _, host := range hosts {
+			if target.Matches(host) {
+				counts[host.Name]++
+			}
+		}
+	}
+	return counts
+}
+
+// Local is the local side of a ControlPath: the user and machine sshc runs as
+type Local struct {
+	User     string
+	UID      string
+	Home     string
+	Hostname string
+}
+
+// CurrentLocal returns the user and machine sshc runs as
+func CurrentLocal() Local {
+	local := Local{UID: strconv.Itoa(os.Getuid())}
+	if u, err := user.Current(); err == nil {
+		local.User = u.Username
+	}
+	local.Home, _ = os.UserHomeDir()
+	local.Hostname, _ = os.Hostname()
+	return local
+}
+
+// ExpandControlPath returns the socket paths a ControlPath can stand for with
+// host: two when it uses %C, which newer versions of ssh hash with the jump host
+// and older ones without, one otherwise.
+func ExpandControlPath(controlPath string, host config.SSHHost, local Local) []string {
+	if controlPath == "" || strings.EqualFold(controlPath, "none") {
+		return nil
+	}
+
+	hostname := host.Hostname
+	if hostname == "" {
+		hostname = host.Name
+	}
+	port := defaultPort(host.Port)
+	remoteUser := host.User
+	if remoteUser == "" {
+		remoteUser = local.User
+	}
+	shortHost, _, _ := strings.Cut(local.Hostname, ".")
+
+	var paths []string
+	for _, withJump := range []bool{true, false} {
+		hash := local.Hostname + hostname + port + remoteUser
+		if withJump {
+			hash += host.ProxyJump
+		}
+		sum := sha1.Sum([]byte(hash))
+		replacer := strings.NewReplacer(
+			"%%", "%",
+			"%C", hex.EncodeToString(sum[:]),
+			"%d", local.Home,
+			"%h", hostname,
+			"%i", local.UID,
+			"%j", host.ProxyJump,
+			"%L", shortHost,
+			"%l", local.Hostname,
+			"%n", host.Name,
+			"%p", port,
+			"%r", remoteUser,
+			"%u", local.User,
+		)
+		path := replacer.Replace(controlPath)
+		if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/' || rest[0] == '\\') {
+			path = filepath.Join(local.Home, rest)
+		}
+		if len(paths) == 0 || paths[0] != path {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// socketExists reports whether any of paths is a socket
+func socketExists(paths []string) bool {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan counts the sessions open to each host. A failure to list the processes
+// is returned along with the sockets that were found.
+func Scan(ctx context.Context, hosts []Host) (map[string]Count, error) {
+	sshHosts := make([]config.SSHHost, len(hosts))
+	for i, host := range hosts {
+		sshHosts[i] = host.SSHHost
+	}
+
+	counts := make(map[string]Count)
+	local := CurrentLocal()
+	for _, host := range hosts {
+		if socketExists(ExpandControlPath(host.ControlPath, host.SSHHost, local)) {
+			counts[host.Name] = Count{Socket: true}
+		}
+	}
+
+	processes, err := listProcesses(ctx)
+	for name, n := range CountProcesses(processes, sshHosts) {
+		count := counts[name]
+		count.Processes = n
+		counts[name] = count
+	}
+	return counts, err
+}
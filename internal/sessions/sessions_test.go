@@ -0,0 +1,195 @@
+package sessions
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want Target
+		ok   bool
+	}{
+		{[]string{"ssh", "app"}, Target{Host: "app"}, true},
+		{[]string{"/usr/bin/ssh", "deploy@app", "uptime"}, Target{User: "deploy", Host: "app"}, true},
+		{[]string{"ssh", "-p", "2222", "-l", "root", "10.0.0.5"}, Target{User: "root", Host: "10.0.0.5", Port: "2222"}, true},
+		{[]string{"ssh", "-vvtp2222", "10.0.0.5"}, Target{Host: "10.0.0.5", Port: "2222"}, true},
+		{[]string{"ssh", "-oUser=ci", "-o", "Port 2200", "app"}, Target{User: "ci", Host: "app", Port: "2200"}, true},
+		{[]string{"ssh", "app", "-p", "2222", "ls", "-la"}, Target{Host: "app", Port: "2222"}, true}, // Options may follow the destination
+		{[]string{"ssh", "app", "tail", "-p", "x"}, Target{Host: "app"}, true},                       // ... but not the command
+		{[]string{"ssh", "-s", "--", "deploy@10.0.0.5", "sftp"}, Target{User: "deploy", Host: "10.0.0.5"}, true},
+		{[]string{"ssh", "-J", "bastion", "db"}, Target{Host: "db"}, true}, // Not the jump host
+		{[]string{"ssh", "ssh://root@[2001:db8::1]:2200"}, Target{User: "root", Host: "2001:db8::1", Port: "2200"}, true},
+		{[]string{"ssh", "ssh://app"}, Target{Host: "app"}, true},
+		{[]string{"ssh", "-l", "a@b", "user@x@app"}, Target{User: "a@b", Host: "app"}, true}, // -l wins over the destination
+		{[]string{`C:\Windows\System32\OpenSSH\ssh.exe`, "app"}, Target{Host: "app"}, true},
+		{[]string{"SSH.EXE", "app"}, Target{Host: "app"}, true},
+		{[]string{"ssh", "-O", "check", "app"}, Target{}, false},
+		{[]string{"ssh", "-G", "app"}, Target{}, false},
+		{[]string{"ssh", "-V"}, Target{}, false},
+		{[]string{"ssh", "-Q", "cipher"}, Target{}, false},
+		{[]string{"ssh", "-p"}, Target{}, false}, // Argument missing
+		{[]string{"ssh"}, Target{}, false},
+		{[]string{"ssh:", "/home/me/.ssh/cm-app", "[mux]"}, Target{}, false}, // A master's process title
+		{[]string{"sshd", "-D"}, Target{}, false},
+		{[]string{"sshc", "app"}, Target{}, false},
+		{[]string{"ssh-agent", "-l"}, Target{}, false},
+		{nil, Target{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseArgs(tt.args)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseArgs(%q) = %+v, %v; want %+v, %v", tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+var testHosts = []config.SSHHost{
+	{Name: "app", Hostname: "app.example.com"},
+	{Name: "db", Hostname: "db.internal", User: "deploy"},
+	{Name: "bastion", Hostname: "203.0.113.10"},
+	{Name: "build", Hostname: "10.0.0.5", Port: "2222"},
+	{Name: "files", Hostname: "10.0.0.5", User: "deploy"},
+	{Name: "legacy", Hostname: "legacy.example.com"},
+	{Name: "v6", Hostname: "2001:db8::1", Port: "2200"},
+	{Name: "idle", Hostname: "idle.example.com"},
+}
+
+func TestCountProcessesFromPS(t *testing.T) {
+	processes := ParsePS(readFixture(t, "ps.txt"))
+	if len(processes) != 17 {
+		t.Fatalf("ParsePS() read %d processes, want 17", len(processes))
+	}
+	if want := (Process{PID: 1206, Args: []string{"/usr/bin/ssh", "-vvt", "-p2222", "10.0.0.5", "tmux", "attach"}}); !reflect.DeepEqual(processes[6], want) {
+		t.Errorf("process = %+v, want %+v", processes[6], want)
+	}
+
+	got := CountProcesses(processes, testHosts)
+	want := map[string]int{
+		"app":     2, // ssh app and the -fNL forward; not -O check, sshc or grep
+		"db":      1, // Through bastion with -J
+		"bastion": 1, // The -W proxy of that connection
+		"build":   1,
+		"files":   1, // The sftp session on port 22
+		// ps splits "-o ProxyCommand ssh -W %h:%p bastion", so legacy is missed
+		"v6": 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CountProcesses() = %v, want %v", got, want)
+	}
+}
+
+func TestCountProcessesFromCmdline(t *testing.T) {
+	args := ParseCmdline([]byte("ssh\x00-o\x00ProxyCommand ssh -W %h:%p bastion\x00legacy\x00"))
+	if want := []string{"ssh", "-o", "ProxyCommand ssh -W %h:%p bastion", "legacy"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("ParseCmdline() = %q, want %q", args, want)
+	}
+	if got := CountProcesses([]Process{{PID: 1, Args: args}}, testHosts); !reflect.DeepEqual(got, map[string]int{"legacy": 1}) {
+		t.Errorf("CountProcesses() = %v, want legacy once", got)
+	}
+	if ParseCmdline(nil) != nil {
+		t.Error("ParseCmdline() of a kernel thread returned arguments")
+	}
+}
+
+func TestCountProcessesFromWMIC(t *testing.T) {
+	processes := ParseWMIC(readFixture(t, "wmic.csv"))
+	want := []Process{
+		{PID: 4120, Args: []string{`C:\Windows\System32\OpenSSH\ssh.exe`, "app"}},
+		{PID: 4188, Args: []string{`C:\Windows\System32\OpenSSH\ssh.exe`, "-i", `C:\Users\Me\.ssh\id key`, "deploy@db.internal", `echo "a,b"`}},
+		{PID: 4200, Args: []string{"ssh.exe", "-V"}},
+	}
+	if !reflect.DeepEqual(processes, want) {
+		t.Fatalf("ParseWMIC() = %q, want %q", processes, want)
+	}
+	if got := CountProcesses(processes, testHosts); !reflect.DeepEqual(got, map[string]int{"app": 1, "db": 1}) {
+		t.Errorf("CountProcesses() = %v, want app and db once", got)
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := map[string][]string{
+		`ssh app`:                    {"ssh", "app"},
+		`  ssh   "a b"  c `:          {"ssh", "a b", "c"},
+		`ssh ""`:                     {"ssh", ""},
+		`ssh "say ""hi"""`:           {"ssh", `say "hi"`},
+		`ssh \"quoted\"`:             {"ssh", `"quoted"`},
+		`ssh "C:\dir\\" next`:        {"ssh", `C:\dir\`, "next"},
+		`C:\Program Files\ssh.exe x`: {`C:\Program`, `Files\ssh.exe`, "x"},
+	}
+	for commandLine, want := range tests {
+		if got := SplitCommandLine(commandLine); !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitCommandLine(%s) = %q, want %q", commandLine, got, want)
+		}
+	}
+}
+
+func TestExpandControlPath(t *testing.T) {
+	local := Local{User: "me", UID: "1000", Home: "/home/me", Hostname: "laptop.lan"}
+	host := config.SSHHost{Name: "db", Hostname: "db.internal", User: "deploy", ProxyJump: "bastion"}
+
+	got := ExpandControlPath("~/.ssh/cm-%r@%h:%p-%n-%L-%u-%i-%%", host, local)
+	if want := []string{"/home/me/.ssh/cm-deploy@db.internal:22-db-laptop-me-1000-%"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandControlPath() = %q, want %q", got, want)
+	}
+
+	// ssh -G db prints the hash of laptop.landb.internal22deploy(bastion)
+	got = ExpandControlPath("%d/.ssh/cm-%C", host, local)
+	want := []string{
+		"/home/me/.ssh/cm-e53f705fdf93ca9bddc0613d485870710752cc2c",
+		"/home/me/.ssh/cm-6a3a19c10748b302e1eddc0f22b3db85958e3a59",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandControlPath(%%C) = %q, want %q", got, want)
+	}
+
+	for _, none := range []string{"", "none", "NONE"} {
+		if got := ExpandControlPath(none, host, local); got != nil {
+			t.Errorf("ExpandControlPath(%q) = %q, want nothing", none, got)
+		}
+	}
+}
+
+func TestScanFindsControlSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Unix socket paths are limited to about 100 bytes, too short for t.TempDir()
+	listener, err := net.Listen("unix", filepath.Join(dir, "cm-app"))
+	if err != nil {
+		t.Skipf("unix sockets are not available: %v", err)
+	}
+	defer listener.Close()
+	if err := os.WriteFile(filepath.Join(dir, "cm-db"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := []Host{
+		{SSHHost: config.SSHHost{Name: "app"}, ControlPath: filepath.Join(dir, "cm-%n")},
+		{SSHHost: config.SSHHost{Name: "db"}, ControlPath: filepath.Join(dir, "cm-%n")}, // Not a socket
+		{SSHHost: config.SSHHost{Name: "web"}, ControlPath: "none"},
+	}
+	counts, _ := Scan(context.Background(), hosts)
+	if !counts["app"].Socket || counts["db"].Socket || counts["web"].Socket {
+		t.Errorf("Scan() = %+v, want only the socket of app", counts)
+	}
+}
@@ -0,0 +1,201 @@
+// Package share serves a read-only list of hosts over HTTP, for a colleague to
+// browse in their own sshc, and fetches such a list
+package share
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/validation"
+)
+
+// DefaultAddr is the address "sshc serve" listens on by default
+const DefaultAddr = ":7843"
+
+// HostsPath is the endpoint that lists the shared hosts
+const HostsPath = "/hosts"
+
+// formatVersion is bumped when the response layout changes incompatibly
+const formatVersion = 1
+
+// maxResponseSize bounds what Fetch reads from a peer
+const maxResponseSize = 4 << 20
+
+// fetchTimeout bounds a whole request made by Fetch
+const fetchTimeout = 10 * time.Second
+
+// ErrUnauthorized is returned by Fetch when the peer rejects the token
+var ErrUnauthorized = errors.New("the peer rejected the token")
+
+// Host is what is shared of a host: enough to find and reach it, but no keys,
+// options, jump hosts or commands
+type Host struct {
+	Name        string   `json:"name"`
+	Hostname    string   `json:"hostname"`
+	User        string   `json:"user,omitempty"`
+	Port        string   `json:"port,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Response is the JSON document served at HostsPath
+type Response struct {
+	Version int    `json:"version"`
+	Hosts   []Host `json:"hosts"`
+}
+
+// NewHandler serves the hosts returned by load at HostsPath to requests carrying
+// "Authorization: Bearer <token>". Hosts are loaded for every request, so edits
+// show up without restarting.
+func NewHandler(load func() ([]config.SSHHost, error), token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HostsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorized(r, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sshc"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		hosts, err := load()
+		if err != nil {
+			http.Error(w, "could not read the SSH config", http.StatusInternalServerError)
+			return
+		}
+		response := Response{Version: formatVersion, Hosts: make([]Host, 0, len(hosts))}
+		for _, host := range hosts {
+			response.Hosts = append(response.Hosts, sharedHost(host))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(response)
+	})
+	return mux
+}
+
+// authorized checks the bearer token of a request in constant time
+func authorized(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// sharedHost strips a host down to what is shared. Without a HostName, ssh
+// connects to the alias itself, so that is shared as the hostname.
+func sharedHost(host config.SSHHost) Host {
+	hostname := host.Hostname
+	if hostname == "" {
+		hostname = host.Name
+	}
+	return Host{
+		Name:        host.Name,
+		Hostname:    hostname,
+		User:        host.User,
+		Port:        host.Port,
+		Tags:        host.Tags,
+		Description: host.Description,
+	}
+}
+
+// NewServer returns an HTTP server for handler with timeouts, so a slow or
+// stalled client cannot hold a connection open
+func NewServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+}
+
+// Fetch reads the hosts shared at baseURL, such as "http://peer:7843". Hosts whose
+// details could be mistaken for ssh options or are otherwise unsafe to pass to
+// ssh are left out, and their names are returned separately.
+func Fetch(ctx context.Context, client *http.Client, baseURL, token string) ([]config.SSHHost, []string, error) {
+	if client == nil {
+		client = &http.Client{Timeout: fetchTimeout}
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	url := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(url, HostsPath) {
+		url += HostsPath
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, nil, ErrUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, fmt.Errorf("peer answered %s", resp.Status)
+	}
+
+	var response Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&response); err != nil {
+		return nil, nil, fmt.Errorf("failed to read the host list: %w", err)
+	}
+	if response.Version > formatVersion {
+		return nil, nil, fmt.Errorf("host list version %d is newer than supported version %d", response.Version, formatVersion)
+	}
+
+	var hosts []config.SSHHost
+	var skipped []string
+	for _, host := range response.Hosts {
+		if !safeHost(host) {
+			skipped = append(skipped, host.Name)
+			continue
+		}
+		hosts = append(hosts, config.SSHHost{
+			Name:        host.Name,
+			Hostname:    host.Hostname,
+			User:        host.User,
+			Port:        host.Port,
+			Tags:        host.Tags,
+			Description: host.Description,
+		})
+	}
+	return hosts, skipped, nil
+}
+
+// safeHost reports whether a shared host can be connected to without its fields
+// being read as ssh options. The details come from another machine, so they are
+// checked as strictly as typed ones.
+func safeHost(host Host) bool {
+	if host.Name == "" || strings.ContainsAny(host.Name, " \t\r\n") || strings.HasPrefix(host.Name, "-") {
+		return false
+	}
+	if !validation.ValidateHostname(host.Hostname) && !validation.ValidateIP(host.Hostname) {
+		return false
+	}
+	if strings.HasPrefix(host.User, "-") || strings.ContainsAny(host.User, " \t\r\n@") {
+		return false
+	}
+	return validation.ValidatePort(host.Port)
+}
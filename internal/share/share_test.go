@@ -0,0 +1,157 @@
+package share
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func testHosts() ([]config.SSHHost, error) {
+	return []config.SSHHost{
+		{
+			Name: "web", Hostname: "10.0.0.1", User: "deploy", Port: "2222", Tags: []string{"prod"},
+			Identity: "~/.ssh/id_prod", Options: "ForwardAgent yes", ProxyJump: "bastion", RemoteCommand: "htop",
+		},
+		{Name: "db"},
+	}, nil
+}
+
+func TestHandlerServesSharedFieldsOnly(t *testing.T) {
+	server := httptest.NewServer(NewHandler(testHosts, "s3cret"))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+HostsPath, nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Status = %s, want 200", resp.Status)
+	}
+
+	var raw map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(raw)
+	for _, secret := range []string{"id_prod", "ForwardAgent", "bastion", "htop"} {
+		if strings.Contains(string(body), secret) {
+			t.Errorf("Response leaks %q: %s", secret, body)
+		}
+	}
+	if !strings.Contains(string(body), `"hostname":"db"`) {
+		t.Errorf("Expected a host without HostName to share its alias as hostname: %s", body)
+	}
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	handler := NewHandler(testHosts, "s3cret")
+	tests := []struct {
+		name   string
+		method string
+		auth   string
+		want   int
+	}{
+		{"no token", http.MethodGet, "", http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, "Bearer nope", http.StatusUnauthorized},
+		{"token without scheme", http.MethodGet, "s3cret", http.StatusUnauthorized},
+		{"write attempt", http.MethodPost, "Bearer s3cret", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, HostsPath, nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+
+	// An empty token on the server never authorizes anyone
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, HostsPath, nil)
+	req.Header.Set("Authorization", "Bearer ")
+	NewHandler(testHosts, "").ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Empty server token: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(NewHandler(testHosts, "s3cret"))
+	defer server.Close()
+
+	hosts, skipped, err := Fetch(context.Background(), nil, server.URL+"/", "s3cret")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(skipped) != 0 || len(hosts) != 2 {
+		t.Fatalf("Fetch() = %+v, skipped %v", hosts, skipped)
+	}
+	if web := hosts[0]; web.Name != "web" || web.User != "deploy" || web.Port != "2222" || !slices.Equal(web.Tags, []string{"prod"}) || web.Identity != "" {
+		t.Errorf("web = %+v", web)
+	}
+
+	// Without a scheme, http is assumed
+	if _, _, err := Fetch(context.Background(), nil, strings.TrimPrefix(server.URL, "http://"), "s3cret"); err != nil {
+		t.Errorf("Fetch() without scheme error = %v", err)
+	}
+	if _, _, err := Fetch(context.Background(), nil, server.URL, "wrong"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Fetch() with a wrong token error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestFetchSkipsUnsafeHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":1,"hosts":[
+			{"name":"ok","hostname":"ok.example.com","user":"me"},
+			{"name":"inject","hostname":"-oProxyCommand=touch /tmp/x"},
+			{"name":"user-option","hostname":"10.0.0.1","user":"-oProxyCommand=x"},
+			{"name":"bad port","hostname":"10.0.0.1"},
+			{"name":"port","hostname":"10.0.0.1","port":"22; rm"}
+		]}`))
+	}))
+	defer server.Close()
+
+	hosts, skipped, err := Fetch(context.Background(), nil, server.URL, "t")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != "ok" {
+		t.Errorf("hosts = %+v, want only ok", hosts)
+	}
+	if want := []string{"inject", "user-option", "bad port", "port"}; !slices.Equal(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+}
+
+func TestFetchRejectsBadResponses(t *testing.T) {
+	for _, body := range []string{`not json`, `{"version":99,"hosts":[]}`} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		if _, _, err := Fetch(context.Background(), nil, server.URL, "t"); err == nil {
+			t.Errorf("Fetch() of %q succeeded, want an error", body)
+		}
+		server.Close()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	if _, _, err := Fetch(context.Background(), nil, server.URL, "t"); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Fetch() error = %v, want the status", err)
+	}
+}
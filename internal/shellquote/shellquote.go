@@ -0,0 +1,41 @@
+// Package shellquote quotes words for a POSIX shell, such as the one that runs
+// the command of an ssh session or reads the paths scp hands to the remote side
+package shellquote
+
+import (
+	"regexp"
+	"strings"
+)
+
+// safeWord matches words the shell reads as they are
+var safeWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// Quote returns s as a single shell word in single quotes, in which the shell
+// expands nothing
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Word returns s as a single shell word, quoted only when the shell would
+// otherwise split or expand it
+func Word(s string) string {
+	if safeWord.MatchString(s) {
+		return s
+	}
+	return Quote(s)
+}
+
+// Path returns a remote path as a single shell word like Word, leaving a leading
+// ~ or ~/ unquoted so the shell still expands it to the home directory
+func Path(path string) string {
+	switch {
+	case path == "~":
+		return path
+	case strings.HasPrefix(path, "~/"):
+		if rest := path[2:]; rest != "" {
+			return "~/" + Word(rest)
+		}
+		return path
+	}
+	return Word(path)
+}
@@ -0,0 +1,117 @@
+package shellquote
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// nastyWords are read literally only when quoted right
+var nastyWords = []string{
+	"",
+	"plain",
+	"with space",
+	"  leading and trailing  ",
+	"tab\there",
+	"new\nline",
+	"it's",
+	"''",
+	`"double"`,
+	`back\slash`,
+	`trailing\`,
+	"$HOME",
+	"${HOME}",
+	"$(touch pwned)",
+	"`touch pwned`",
+	"; touch pwned",
+	"a && touch pwned",
+	"a | touch pwned",
+	"a > pwned",
+	"semi;colon | pipe & amp",
+	"(parens)",
+	"{a,b}",
+	"*",
+	"[ab]?",
+	"!bang",
+	"#hash",
+	"-rf",
+	"~",
+	"~/notes",
+	"a=b",
+	"photo été 2024.jpg",
+	"日本語のファイル",
+	"emoji 🚀.txt",
+	"\x01control",
+}
+
+// echo runs word through sh and returns what the shell made of it
+func echo(t *testing.T, word string) string {
+	t.Helper()
+	dir := t.TempDir()
+	cmd := exec.Command("sh", "-c", "printf %s "+word)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "HOME=/home/remote")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sh failed for %s: %v", word, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Errorf("%s ran a command", word)
+	}
+	return string(out)
+}
+
+func TestQuoteRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	for _, word := range nastyWords {
+		if got := echo(t, Quote(word)); got != word {
+			t.Errorf("Quote(%q) came back as %q", word, got)
+		}
+		if got := echo(t, Word(word)); got != word {
+			t.Errorf("Word(%q) came back as %q", word, got)
+		}
+	}
+}
+
+func TestWordLeavesSafeWordsBare(t *testing.T) {
+	for _, word := range []string{"web", "/srv/logs/", "user@host:2222", "a-b_c.d", "50%", "a=b,c+d"} {
+		if got := Word(word); got != word {
+			t.Errorf("Word(%q) = %q, want it unquoted", word, got)
+		}
+	}
+	for word, want := range map[string]string{
+		"":         "''",
+		"a b":      "'a b'",
+		"it's":     `'it'\''s'`,
+		"~/x":      "'~/x'",
+		"été":      "'été'",
+		"$HOME/.x": "'$HOME/.x'",
+	} {
+		if got := Word(word); got != want {
+			t.Errorf("Word(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestPathKeepsHomeExpandable(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	tests := map[string]string{
+		"~":                       "/home/remote",
+		"~/":                      "/home/remote/",
+		"~/my notes/it's (1).txt": "/home/remote/my notes/it's (1).txt",
+		"~/$(touch pwned)":        "/home/remote/$(touch pwned)",
+		"/srv/a b & c":            "/srv/a b & c",
+		"~user's":                 "~user's",
+		"dir/~/x":                 "dir/~/x",
+	}
+	for path, want := range tests {
+		if got := echo(t, Path(path)); got != want {
+			t.Errorf("Path(%q) = %s came back as %q, want %q", path, Path(path), got, want)
+		}
+	}
+}
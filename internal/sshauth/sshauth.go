@@ -0,0 +1,99 @@
+// Package sshauth reads which keys were offered and accepted from ssh -v debug output
+package sshauth
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// Key is a public key ssh offered to a server
+type Key struct {
+	Path        string // Key file, or the key comment for agent keys without a file
+	Type        string // Key type as printed by ssh, such as ED25519
+	Fingerprint string // SHA256 fingerprint
+}
+
+// Result is what the debug output says about authentication
+type Result struct {
+	Offered  []Key
+	Accepted *Key     // Key the server accepted, nil when none was
+	Method   string   // Method that authenticated, such as publickey or password; "" when unknown
+	Messages []string // Lines other than debug output, which ssh prints without -v
+}
+
+// ParseFile parses a debug log written with ssh -E
+func ParseFile(path string) (Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads the authentication lines of ssh -v output. Other lines are ignored.
+func Parse(r io.Reader) (Result, error) {
+	var result Result
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if _, rest, ok := strings.Cut(line, ": "); ok && strings.HasPrefix(line, "debug") {
+			line = rest
+		} else if line != "" && !strings.HasPrefix(line, "OpenSSH_") && !strings.HasPrefix(line, "Authenticated to ") {
+			result.Messages = append(result.Messages, line)
+		}
+
+		switch {
+		case strings.HasPrefix(line, "Offering public key: "):
+			if key, ok := parseKey(strings.TrimPrefix(line, "Offering public key: ")); ok {
+				result.Offered = append(result.Offered, key)
+			}
+		case strings.HasPrefix(line, "Server accepts key: "):
+			if key, ok := parseKey(strings.TrimPrefix(line, "Server accepts key: ")); ok {
+				result.Accepted = &key
+			} else if len(result.Offered) > 0 {
+				// Older clients print the algorithm instead of the key; it is the last one offered
+				key := result.Offered[len(result.Offered)-1]
+				result.Accepted = &key
+			}
+		case strings.HasPrefix(line, "Authentication succeeded ("):
+			// OpenSSH 8.x: Authentication succeeded (publickey).
+			result.Method = strings.TrimSuffix(strings.TrimPrefix(line, "Authentication succeeded ("), ").")
+		case strings.HasPrefix(line, "Authenticated to "):
+			// OpenSSH 9.x: Authenticated to host ([10.0.0.1]:22) using "publickey".
+			if _, method, ok := strings.Cut(line, " using "); ok {
+				result.Method = strings.Trim(method, `".`)
+			}
+		}
+	}
+
+	if result.Method != "" && result.Method != "publickey" {
+		// The accepted key did not finish the login, another method did
+		result.Accepted = nil
+	}
+	return result, scanner.Err()
+}
+
+// parseKey reads the key of an "Offering public key" line, which is
+// "<path> <type> <fingerprint> [flags]" since OpenSSH 8.0 and
+// "<type> <fingerprint> <path>" before
+func parseKey(s string) (Key, bool) {
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		if !strings.HasPrefix(field, "SHA256:") && !strings.HasPrefix(field, "MD5:") || i == 0 {
+			continue
+		}
+		if i == 1 {
+			return Key{Type: fields[0], Fingerprint: field, Path: strings.Join(fields[2:], " ")}, true
+		}
+		return Key{Path: strings.Join(fields[:i-1], " "), Type: fields[i-1], Fingerprint: field}, true
+	}
+	return Key{}, false
+}
+
+// String formats the key the way ssh prints it
+func (k Key) String() string {
+	return strings.TrimSpace(k.Path + " (" + k.Type + " " + k.Fingerprint + ")")
+}
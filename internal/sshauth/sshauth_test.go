@@ -0,0 +1,83 @@
+package sshauth
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		file     string
+		offered  int
+		method   string
+		accepted Key
+	}{
+		{
+			file:     "openssh-8.2.log",
+			offered:  2,
+			method:   "publickey",
+			accepted: Key{Path: "/home/alice/.ssh/id_ed25519", Type: "ED25519", Fingerprint: "SHA256:Jm3kT9uV1wX2yZ3aB4cD5eF6gH7iJ8kL9mN0oP1qR2s"},
+		},
+		{
+			file:     "openssh-9.6.log",
+			offered:  2,
+			method:   "publickey",
+			accepted: Key{Path: "/Users/alice/.ssh/work key", Type: "ED25519", Fingerprint: "SHA256:Zz9yY8xX7wW6vV5uU4tT3sS2rR1qQ0pP9oO8nN7mM6l"},
+		},
+		{
+			file:    "openssh-9.6-password.log",
+			offered: 1,
+			method:  "password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			result, err := ParseFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+			if len(result.Offered) != tt.offered || result.Method != tt.method {
+				t.Errorf("Got %d offered keys and method %q, want %d and %q", len(result.Offered), result.Method, tt.offered, tt.method)
+			}
+			switch {
+			case tt.accepted == Key{} && result.Accepted != nil:
+				t.Errorf("Expected no accepted key, got %+v", *result.Accepted)
+			case tt.accepted != Key{} && (result.Accepted == nil || *result.Accepted != tt.accepted):
+				t.Errorf("Accepted = %+v, want %+v", result.Accepted, tt.accepted)
+			}
+		})
+	}
+}
+
+func TestParseOldKeyFormat(t *testing.T) {
+	log := "debug1: Offering public key: RSA SHA256:p1Zq8X3nZ0a2Yv1h8Pq9m4bT7cW5dE6fG7hI8jK9lM0 /home/alice/.ssh/id_rsa\n" +
+		"debug1: Server accepts key: pkalg rsa-sha2-512 blen 279\n" +
+		"debug1: Authentication succeeded (publickey).\n"
+
+	result, err := Parse(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Accepted == nil || result.Accepted.Path != "/home/alice/.ssh/id_rsa" || result.Accepted.Type != "RSA" {
+		t.Errorf("Expected the last offered key to be accepted, got %+v", result.Accepted)
+	}
+	if got := result.Accepted.String(); got != "/home/alice/.ssh/id_rsa (RSA SHA256:p1Zq8X3nZ0a2Yv1h8Pq9m4bT7cW5dE6fG7hI8jK9lM0)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestParseKeepsMessages(t *testing.T) {
+	log := "OpenSSH_9.6p1, LibreSSL 3.3.6\n" +
+		"debug1: Connecting to gone.example.com port 22.\n" +
+		"ssh: Could not resolve hostname gone.example.com: nodename nor servname provided, or not known\n"
+
+	result, err := Parse(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Messages) != 1 || !strings.HasPrefix(result.Messages[0], "ssh: Could not resolve hostname") {
+		t.Errorf("Expected only the error message kept, got %q", result.Messages)
+	}
+}
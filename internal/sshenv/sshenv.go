@@ -0,0 +1,192 @@
+// Package sshenv decides how the environment overrides of a host reach its
+// sessions: through the environment of the local ssh process, or through the
+// SetEnv and SendEnv options
+package sshenv
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+// Mechanism is how a variable reaches the remote session
+type Mechanism int
+
+const (
+	// Local sets the variable in the environment of the ssh process. ssh sends
+	// TERM with its terminal request, so that is all TERM needs.
+	Local Mechanism = iota
+	// SetEnv sends the variable with its value with -o SetEnv (OpenSSH 7.8)
+	SetEnv
+	// SendEnv sets the variable locally and sends it with -o SendEnv, for
+	// clients without SetEnv
+	SendEnv
+)
+
+func (m Mechanism) String() string {
+	switch m {
+	case SetEnv:
+		return "SetEnv"
+	case SendEnv:
+		return "SendEnv"
+	default:
+		return "local env"
+	}
+}
+
+// Override is a variable of a host and how it is passed
+type Override struct {
+	Name  string
+	Value string
+	Via   Mechanism
+}
+
+// Plan picks a mechanism for each variable, sorted by name. TERM always goes in
+// the local environment; the others use SetEnv when the ssh client is known to
+// support it, and SendEnv otherwise. The server only takes the variables its
+// AcceptEnv allows either way.
+func Plan(env map[string]string, v sshver.Version, known bool) []Override {
+	plan := make([]Override, 0, len(env))
+	for name, value := range env {
+		o := Override{Name: name, Value: value, Via: SendEnv}
+		switch {
+		case name == "TERM":
+			o.Via = Local
+		case known && v.SupportsSetEnv():
+			o.Via = SetEnv
+		}
+		plan = append(plan, o)
+	}
+	sort.Slice(plan, func(i, j int) bool { return plan[i].Name < plan[j].Name })
+	return plan
+}
+
+// MergeSetEnv adds the variables of configured, the value of a SetEnv in the ssh
+// config, to a plan that sends others with SetEnv. ssh uses the first SetEnv it
+// obtains, and the one on the command line would drop the configured variables.
+// Variables in both keep the value of the plan.
+func MergeSetEnv(plan []Override, configured string) []Override {
+	if !slices.ContainsFunc(plan, func(o Override) bool { return o.Via == SetEnv }) {
+		return plan
+	}
+	merged := slices.Clone(plan)
+	for _, kv := range splitSetEnv(configured) {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" || slices.ContainsFunc(merged, func(o Override) bool { return o.Name == name }) {
+			continue
+		}
+		merged = append(merged, Override{Name: name, Value: value, Via: SetEnv})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// MergeConfigSetEnv merges the SetEnv the ssh config at configPath, or the default
+// one when it is "", gives hostName into plan with MergeSetEnv. The config is only
+// read when plan sends variables with SetEnv, and one that cannot be read adds none.
+func MergeConfigSetEnv(plan []Override, hostName, configPath string) []Override {
+	if !slices.ContainsFunc(plan, func(o Override) bool { return o.Via == SetEnv }) {
+		return plan
+	}
+	if configPath == "" {
+		configPath, _ = config.GetDefaultSSHConfigPath()
+	}
+	settings, err := config.ResolveSettings([]string{hostName}, []string{"SetEnv"}, configPath)
+	if err != nil {
+		return plan
+	}
+	return MergeSetEnv(plan, settings[hostName]["setenv"])
+}
+
+// splitSetEnv splits a SetEnv value into its NAME=value words, the way ssh does:
+// double quotes keep spaces in a word and are removed
+func splitSetEnv(value string) []string {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted, inWord = !quoted, true
+		case (r == ' ' || r == '\t') && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			inWord = false
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// Args returns the ssh options a plan needs, such as -o "SetEnv=LANG=C LC_ALL=C".
+// A value with spaces, which only a merged SetEnv has, is quoted.
+func Args(plan []Override) []string {
+	var setEnv, sendEnv []string
+	for _, o := range plan {
+		switch o.Via {
+		case SetEnv:
+			kv := o.Name + "=" + o.Value
+			if strings.ContainsAny(kv, " \t") {
+				kv = `"` + kv + `"`
+			}
+			setEnv = append(setEnv, kv)
+		case SendEnv:
+			sendEnv = append(sendEnv, o.Name)
+		}
+	}
+	var args []string
+	if len(setEnv) > 0 {
+		args = append(args, "-o", "SetEnv="+strings.Join(setEnv, " "))
+	}
+	if len(sendEnv) > 0 {
+		args = append(args, "-o", "SendEnv="+strings.Join(sendEnv, " "))
+	}
+	return args
+}
+
+// Environ returns base with the variables a plan sets locally replaced or added,
+// or nil when it sets none, so the ssh process keeps inheriting the environment
+func Environ(plan []Override, base []string) []string {
+	local := make(map[string]string)
+	for _, o := range plan {
+		if o.Via != SetEnv {
+			local[o.Name] = o.Value
+		}
+	}
+	if len(local) == 0 {
+		return nil
+	}
+
+	env := make([]string, 0, len(base)+len(local))
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, overridden := local[name]; !overridden {
+			env = append(env, kv)
+		}
+	}
+	for _, o := range plan {
+		if _, ok := local[o.Name]; ok {
+			env = append(env, o.Name+"="+o.Value)
+		}
+	}
+	return env
+}
+
+// Describe lists a plan for display, such as "TERM=vt100 (local env), LANG=C (SetEnv)"
+func Describe(plan []Override) string {
+	parts := make([]string, len(plan))
+	for i, o := range plan {
+		parts[i] = o.Name + "=" + o.Value + " (" + o.Via.String() + ")"
+	}
+	return strings.Join(parts, ", ")
+}
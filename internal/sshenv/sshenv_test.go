@@ -0,0 +1,96 @@
+package sshenv
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+func mustParse(t *testing.T, output string) sshver.Version {
+	t.Helper()
+	v, err := sshver.Parse(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestPlanPicksMechanism(t *testing.T) {
+	env := map[string]string{"TERM": "vt100", "LANG": "C.UTF-8", "LC_ALL": "C"}
+	tests := []struct {
+		name    string
+		version sshver.Version
+		known   bool
+		want    []Override
+	}{
+		{"SetEnv supported", mustParse(t, "OpenSSH_9.6p1"), true, []Override{
+			{"LANG", "C.UTF-8", SetEnv}, {"LC_ALL", "C", SetEnv}, {"TERM", "vt100", Local},
+		}},
+		{"first with SetEnv", mustParse(t, "OpenSSH_7.8p1"), true, []Override{
+			{"LANG", "C.UTF-8", SetEnv}, {"LC_ALL", "C", SetEnv}, {"TERM", "vt100", Local},
+		}},
+		{"too old for SetEnv", mustParse(t, "OpenSSH_7.4p1"), true, []Override{
+			{"LANG", "C.UTF-8", SendEnv}, {"LC_ALL", "C", SendEnv}, {"TERM", "vt100", Local},
+		}},
+		{"version unknown", sshver.Version{}, false, []Override{
+			{"LANG", "C.UTF-8", SendEnv}, {"LC_ALL", "C", SendEnv}, {"TERM", "vt100", Local},
+		}},
+	}
+	for _, tt := range tests {
+		if got := Plan(env, tt.version, tt.known); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Plan() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	plan := []Override{{"LANG", "C", SetEnv}, {"LC_ALL", "C", SetEnv}, {"LC_TIME", "en_GB", SendEnv}, {"TERM", "vt100", Local}}
+	want := []string{"-o", "SetEnv=LANG=C LC_ALL=C", "-o", "SendEnv=LC_TIME"}
+	if got := Args(plan); !slices.Equal(got, want) {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+	if got := Args([]Override{{"TERM", "vt100", Local}}); len(got) != 0 {
+		t.Errorf("Args(TERM only) = %q, want none", got)
+	}
+}
+
+func TestMergeSetEnv(t *testing.T) {
+	plan := []Override{{"LANG", "C", SetEnv}, {"TERM", "vt100", Local}}
+	got := MergeSetEnv(plan, `LANG=en_US.UTF-8 "GREETING=hello world" EDITOR=vi`)
+	want := []Override{{"EDITOR", "vi", SetEnv}, {"GREETING", "hello world", SetEnv}, {"LANG", "C", SetEnv}, {"TERM", "vt100", Local}}
+	if !slices.Equal(got, want) {
+		t.Errorf("MergeSetEnv() = %v, want %v", got, want)
+	}
+	if args := Args(got); !slices.Equal(args, []string{"-o", `SetEnv=EDITOR=vi "GREETING=hello world" LANG=C`}) {
+		t.Errorf("Args() = %q", args)
+	}
+
+	// Without a SetEnv on the command line the configured one applies as it is
+	local := []Override{{"TERM", "vt100", Local}}
+	if got := MergeSetEnv(local, "EDITOR=vi"); !slices.Equal(got, local) {
+		t.Errorf("MergeSetEnv(TERM only) = %v, want the plan unchanged", got)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	base := []string{"HOME=/home/alice", "TERM=xterm-256color", "LANG=en_US.UTF-8"}
+	plan := []Override{{"LANG", "C", SendEnv}, {"LC_ALL", "C", SetEnv}, {"TERM", "vt100", Local}}
+	want := []string{"HOME=/home/alice", "LANG=C", "TERM=vt100"}
+	if got := Environ(plan, base); !slices.Equal(got, want) {
+		t.Errorf("Environ() = %q, want %q", got, want)
+	}
+
+	// Variables sent with SetEnv leave the local environment alone
+	if got := Environ([]Override{{"LANG", "C", SetEnv}}, base); got != nil {
+		t.Errorf("Environ(SetEnv only) = %q, want nil", got)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	plan := []Override{{"LANG", "C", SetEnv}, {"LC_TIME", "en_GB", SendEnv}, {"TERM", "vt100", Local}}
+	want := "LANG=C (SetEnv), LC_TIME=en_GB (SendEnv), TERM=vt100 (local env)"
+	if got := Describe(plan); got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,346 @@
+// Package sshtest runs an sshd stand-in in the test process, for integration
+// tests that drive the real ssh, scp and ssh-copy-id against it. The "remote"
+// side is the local machine: commands run with sh in the server's home
+// directory, and the sftp subsystem serves the local file system.
+package sshtest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Server is a running sshd stand-in. It lets in the keys given to Authorize and
+// those in the authorized_keys file of its home directory.
+type Server struct {
+	Host string
+	Port string
+	Home string // Home directory of the remote user, where commands run
+
+	mutex    sync.Mutex
+	keys     []ssh.PublicKey
+	banner   string
+	commands []string
+}
+
+// NewServer starts a server on a loopback port with a fresh home directory,
+// stopped when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &Server{Home: t.TempDir()}
+	s.Host, s.Port, _ = net.SplitHostPort(listener.Addr().String())
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: s.checkKey,
+		BannerCallback: func(ssh.ConnMetadata) string {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			return s.banner
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, config)
+		}
+	}()
+	return s
+}
+
+// Authorize lets key log in
+func (s *Server) Authorize(key ssh.PublicKey) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys = append(s.keys, key)
+}
+
+// SetBanner sets the banner sent to clients before they authenticate
+func (s *Server) SetBanner(banner string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.banner = banner
+}
+
+// Commands returns the commands clients asked to run so far
+func (s *Server) Commands() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.commands)
+}
+
+// Config returns an ssh config entry for the server under alias, with host key
+// checking off as its key changes with every run
+func (s *Server) Config(alias string) string {
+	return "Host " + alias + "\n" +
+		"  HostName " + s.Host + "\n" +
+		"  Port " + s.Port + "\n" +
+		"  User tester\n" +
+		"  StrictHostKeyChecking no\n" +
+		"  UserKnownHostsFile /dev/null\n" +
+		"  LogLevel ERROR\n"
+}
+
+// checkKey accepts the authorized keys, reading authorized_keys anew each time
+// so keys installed by the client count right away
+func (s *Server) checkKey(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	s.mutex.Lock()
+	keys := slices.Clone(s.keys)
+	s.mutex.Unlock()
+
+	if data, err := os.ReadFile(filepath.Join(s.Home, ".ssh", "authorized_keys")); err == nil {
+		for len(data) > 0 {
+			authorized, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+			if err != nil {
+				break
+			}
+			keys = append(keys, authorized)
+			data = rest
+		}
+	}
+
+	for _, authorized := range keys {
+		if bytes.Equal(authorized.Marshal(), key.Marshal()) {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %s", ssh.FingerprintSHA256(key))
+}
+
+func (s *Server) serve(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go s.session(channel, requests)
+		case "direct-tcpip":
+			go forward(newChannel)
+		default:
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions and forwarding are served")
+		}
+	}
+}
+
+// forward connects a direct-tcpip channel, which ssh -J opens on a jump host, to
+// the address it asks for
+func forward(newChannel ssh.NewChannel) {
+	var target struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer conn.Close()
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+	go ssh.DiscardRequests(requests)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(conn, channel)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(channel, conn)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// session serves one session channel: a command or the sftp subsystem. Terminals
+// and shells are refused, as nothing here is interactive.
+func (s *Server) session(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		var payload struct{ Value string }
+		switch req.Type {
+		case "exec":
+			ssh.Unmarshal(req.Payload, &payload)
+			s.mutex.Lock()
+			s.commands = append(s.commands, payload.Value)
+			s.mutex.Unlock()
+			req.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+			status := s.run(channel, payload.Value)
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+			return
+		case "subsystem":
+			ssh.Unmarshal(req.Payload, &payload)
+			if payload.Value != "sftp" {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+			serveSFTP(channel, s.Home)
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// run runs command with sh in the home directory and returns its exit status
+func (s *Server) run(channel ssh.Channel, command string) uint32 {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = s.Home
+	cmd.Env = []string{"HOME=" + s.Home, "PATH=" + os.Getenv("PATH"), "USER=tester", "LC_ALL=C"}
+	cmd.Stdout = channel
+	cmd.Stderr = channel.Stderr()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return 255
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(channel.Stderr(), err)
+		return 127
+	}
+	// Not waited for: the client need not close its side before the command ends
+	go func() {
+		io.Copy(stdin, channel)
+		stdin.Close()
+	}()
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return uint32(exitErr.ExitCode())
+	default:
+		return 255
+	}
+}
+
+// GenerateKey creates an ed25519 key pair in OpenSSH format, the private key at
+// path and the public one next to it with .pub added
+func GenerateKey(t testing.TB, path string) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "sshtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	public := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))) + " sshtest\n"
+	if err := os.WriteFile(path+".pub", []byte(public), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// StartAgent serves an ssh agent holding a fresh key and points SSH_AUTH_SOCK at
+// it for the rest of the test. The key is returned so a server can authorize it.
+func StartAgent(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key, Comment: "sshtest agent"}); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Socket paths are limited to about a hundred bytes, which test directories can exceed
+	dir, err := os.MkdirTemp("", "sshtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	t.Setenv("SSH_AUTH_SOCK", socket)
+	return signer.PublicKey()
+}
@@ -0,0 +1,524 @@
+package sshtest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SFTP version 3 packet types, as spoken by OpenSSH
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpOpen     = 3
+	sftpClose    = 4
+	sftpRead     = 5
+	sftpWrite    = 6
+	sftpLstat    = 7
+	sftpFstat    = 8
+	sftpSetstat  = 9
+	sftpFsetstat = 10
+	sftpOpendir  = 11
+	sftpReaddir  = 12
+	sftpRemove   = 13
+	sftpMkdir    = 14
+	sftpRmdir    = 15
+	sftpRealpath = 16
+	sftpStat     = 17
+	sftpRename   = 18
+	sftpReadlink = 19
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpData     = 103
+	sftpName     = 104
+	sftpAttrs    = 105
+)
+
+// Status codes
+const (
+	statusOK          = 0
+	statusEOF         = 1
+	statusNoSuchFile  = 2
+	statusPermission  = 3
+	statusFailure     = 4
+	statusBadMessage  = 5
+	statusUnsupported = 8
+)
+
+// Attribute flags
+const (
+	attrSize        = 0x1
+	attrUIDGID      = 0x2
+	attrPermissions = 0x4
+	attrTimes       = 0x8
+	attrExtended    = 0x80000000
+)
+
+// Open flags
+const (
+	openRead      = 0x1
+	openWrite     = 0x2
+	openAppend    = 0x4
+	openCreate    = 0x8
+	openTruncate  = 0x10
+	openExclusive = 0x20
+)
+
+const (
+	maxSFTPPacket      = 256 * 1024
+	defaultCreateMode  = 0644
+	defaultMkdirMode   = 0755
+	longnameTimeFormat = "Jan _2 15:04"
+)
+
+var errBadMessage = errors.New("malformed sftp packet")
+
+// sftpHandleState is an open file or directory
+type sftpHandleState struct {
+	file    *os.File
+	append  bool          // Writes go to the end, wherever the client says
+	entries []fs.DirEntry // Of a directory, nil once they were sent
+	dir     string
+}
+
+// sftpServer serves the sftp subsystem from the local file system, resolving
+// relative paths against home. It knows what scp and sftp need to copy and
+// list files, not the OpenSSH extensions.
+type sftpServer struct {
+	rw      io.ReadWriter
+	home    string
+	handles map[string]*sftpHandleState
+	next    int
+}
+
+func serveSFTP(rw io.ReadWriter, home string) {
+	s := &sftpServer{rw: rw, home: home, handles: make(map[string]*sftpHandleState)}
+	defer func() {
+		for _, h := range s.handles {
+			if h.file != nil {
+				h.file.Close()
+			}
+		}
+	}()
+	for {
+		packet, err := s.readPacket()
+		if err != nil {
+			return
+		}
+		if err := s.handle(packet); err != nil {
+			return
+		}
+	}
+}
+
+func (s *sftpServer) readPacket() ([]byte, error) {
+	var length uint32
+	if err := binary.Read(s.rw, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if length == 0 || length > maxSFTPPacket {
+		return nil, errBadMessage
+	}
+	packet := make([]byte, length)
+	_, err := io.ReadFull(s.rw, packet)
+	return packet, err
+}
+
+func (s *sftpServer) send(packetType byte, fields ...[]byte) error {
+	body := []byte{packetType}
+	for _, field := range fields {
+		body = append(body, field...)
+	}
+	_, err := s.rw.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(body))), body...))
+	return err
+}
+
+func (s *sftpServer) status(id uint32, code uint32, message string) error {
+	return s.send(sftpStatus, u32(id), u32(code), str(message), str(""))
+}
+
+// errorStatus answers a failed request with the status closest to err
+func (s *sftpServer) errorStatus(id uint32, err error) error {
+	switch {
+	case err == nil:
+		return s.status(id, statusOK, "")
+	case errors.Is(err, fs.ErrNotExist):
+		return s.status(id, statusNoSuchFile, err.Error())
+	case errors.Is(err, fs.ErrPermission):
+		return s.status(id, statusPermission, err.Error())
+	default:
+		return s.status(id, statusFailure, err.Error())
+	}
+}
+
+func (s *sftpServer) handle(packet []byte) error {
+	r := &sftpReader{data: packet[1:]}
+	if packet[0] == sftpInit {
+		return s.send(sftpVersion, u32(3))
+	}
+	id := r.uint32()
+
+	switch packet[0] {
+	case sftpRealpath:
+		path := s.resolve(r.string())
+		if r.err != nil {
+			break
+		}
+		return s.send(sftpName, u32(id), u32(1), str(path), str(path), u32(0))
+
+	case sftpStat, sftpLstat:
+		path := s.resolve(r.string())
+		if r.err != nil {
+			break
+		}
+		stat := os.Stat
+		if packet[0] == sftpLstat {
+			stat = os.Lstat
+		}
+		info, err := stat(path)
+		if err != nil {
+			return s.errorStatus(id, err)
+		}
+		return s.send(sftpAttrs, u32(id), encodeAttrs(info))
+
+	case sftpFstat:
+		h := s.handles[r.string()]
+		if r.err != nil {
+			break
+		}
+		if h == nil || h.file == nil {
+			return s.status(id, statusFailure, "invalid handle")
+		}
+		info, err := h.file.Stat()
+		if err != nil {
+			return s.errorStatus(id, err)
+		}
+		return s.send(sftpAttrs, u32(id), encodeAttrs(info))
+
+	case sftpOpen:
+		path := s.resolve(r.string())
+		pflags := r.uint32()
+		attrs := r.attrs()
+		if r.err != nil {
+			break
+		}
+		flags := 0
+		switch {
+		case pflags&openRead != 0 && pflags&openWrite != 0:
+			flags = os.O_RDWR
+		case pflags&openWrite != 0:
+			flags = os.O_WRONLY
+		}
+		if pflags&openCreate != 0 {
+			flags |= os.O_CREATE
+		}
+		if pflags&openTruncate != 0 {
+			flags |= os.O_TRUNC
+		}
+		if pflags&openExclusive != 0 {
+			flags |= os.O_EXCL
+		}
+		mode := fs.FileMode(defaultCreateMode)
+		if attrs.flags&attrPermissions != 0 {
+			mode = fs.FileMode(attrs.permissions & 0777)
+		}
+		file, err := os.OpenFile(path, flags, mode)
+		if err != nil {
+			return s.errorStatus(id, err)
+		}
+		return s.send(sftpHandle, u32(id), str(s.add(&sftpHandleState{file: file, append: pflags&openAppend != 0})))
+
+	case sftpOpendir:
+		path := s.resolve(r.string())
+		if r.err != nil {
+			break
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return s.errorStatus(id, err)
+		}
+		if entries == nil {
+			entries = []fs.DirEntry{}
+		}
+		return s.send(sftpHandle, u32(id), str(s.add(&sftpHandleState{entries: entries, dir: path})))
+
+	case sftpReaddir:
+		h := s.handles[r.string()]
+		if r.err != nil {
+			break
+		}
+		if h == nil || h.entries == nil {
+			return s.status(id, statusEOF, "")
+		}
+		entries := h.entries
+		h.entries = nil
+		var names [][]byte
+		count := 0
+		for _, entry := range entries {
+			info, err := os.Lstat(filepath.Join(h.dir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			names = append(names, str(entry.Name()), str(longname(info)), encodeAttrs(info))
+			count++
+		}
+		if count == 0 {
+			return s.status(id, statusEOF, "")
+		}
+		return s.send(sftpName, append([][]byte{u32(id), u32(uint32(count))}, names...)...)
+
+	case sftpRead:
+		h := s.handles[r.string()]
+		offset := r.uint64()
+		length := r.uint32()
+		if r.err != nil {
+			break
+		}
+		if h == nil || h.file == nil {
+			return s.status(id, statusFailure, "invalid handle")
+		}
+		buf := make([]byte, min(length, maxSFTPPacket/2))
+		n, err := h.file.ReadAt(buf, int64(offset))
+		if n == 0 {
+			if err == nil || errors.Is(err, io.EOF) {
+				return s.status(id, statusEOF, "")
+			}
+			return s.errorStatus(id, err)
+		}
+		return s.send(sftpData, u32(id), str(string(buf[:n])))
+
+	case sftpWrite:
+		h := s.handles[r.string()]
+		offset := r.uint64()
+		data := r.string()
+		if r.err != nil {
+			break
+		}
+		if h == nil || h.file == nil {
+			return s.status(id, statusFailure, "invalid handle")
+		}
+		if h.append {
+			if _, err := h.file.Seek(0, io.SeekEnd); err != nil {
+				return s.errorStatus(id, err)
+			}
+			_, err := h.file.Write([]byte(data))
+			return s.errorStatus(id, err)
+		}
+		_, err := h.file.WriteAt([]byte(data), int64(offset))
+		return s.errorStatus(id, err)
+
+	case sftpClose:
+		handle := r.string()
+		if r.err != nil {
+			break
+		}
+		h := s.handles[handle]
+		delete(s.handles, handle)
+		if h != nil && h.file != nil {
+			return s.errorStatus(id, h.file.Close())
+		}
+		return s.status(id, statusOK, "")
+
+	case sftpSetstat:
+		path := s.resolve(r.string())
+		attrs := r.attrs()
+		if r.err != nil {
+			break
+		}
+		return s.errorStatus(id, attrs.apply(path))
+
+	case sftpFsetstat:
+		h := s.handles[r.string()]
+		attrs := r.attrs()
+		if r.err != nil {
+			break
+		}
+		if h == nil || h.file == nil {
+			return s.status(id, statusFailure, "invalid handle")
+		}
+		return s.errorStatus(id, attrs.apply(h.file.Name()))
+
+	case sftpMkdir:
+		path := s.resolve(r.string())
+		attrs := r.attrs()
+		if r.err != nil {
+			break
+		}
+		mode := fs.FileMode(defaultMkdirMode)
+		if attrs.flags&attrPermissions != 0 {
+			mode = fs.FileMode(attrs.permissions & 0777)
+		}
+		return s.errorStatus(id, os.Mkdir(path, mode))
+
+	case sftpRemove, sftpRmdir:
+		path := s.resolve(r.string())
+		if r.err != nil {
+			break
+		}
+		return s.errorStatus(id, os.Remove(path))
+
+	case sftpRename:
+		from := s.resolve(r.string())
+		to := s.resolve(r.string())
+		if r.err != nil {
+			break
+		}
+		return s.errorStatus(id, os.Rename(from, to))
+
+	case sftpReadlink:
+		path := s.resolve(r.string())
+		if r.err != nil {
+			break
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return s.errorStatus(id, err)
+		}
+		return s.send(sftpName, u32(id), u32(1), str(target), str(target), u32(0))
+
+	default:
+		return s.status(id, statusUnsupported, fmt.Sprintf("request %d not supported", packet[0]))
+	}
+	return s.status(id, statusBadMessage, errBadMessage.Error())
+}
+
+// resolve makes path absolute, relative paths being taken from the home directory
+func (s *sftpServer) resolve(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.home, path)
+	}
+	return filepath.Clean(path)
+}
+
+func (s *sftpServer) add(h *sftpHandleState) string {
+	s.next++
+	handle := strconv.Itoa(s.next)
+	s.handles[handle] = h
+	return handle
+}
+
+// fileAttrs are the file attributes of a request
+type fileAttrs struct {
+	flags       uint32
+	size        uint64
+	permissions uint32
+	atime       uint32
+	mtime       uint32
+}
+
+func (a fileAttrs) apply(path string) error {
+	if a.flags&attrSize != 0 {
+		if err := os.Truncate(path, int64(a.size)); err != nil {
+			return err
+		}
+	}
+	if a.flags&attrPermissions != 0 {
+		if err := os.Chmod(path, fs.FileMode(a.permissions&0777)); err != nil {
+			return err
+		}
+	}
+	if a.flags&attrTimes != 0 {
+		return os.Chtimes(path, time.Unix(int64(a.atime), 0), time.Unix(int64(a.mtime), 0))
+	}
+	return nil
+}
+
+// encodeAttrs encodes the size, permissions and times of info, with the file type
+// in the permissions as sftp clients expect
+func encodeAttrs(info fs.FileInfo) []byte {
+	mode := uint32(info.Mode().Perm())
+	switch {
+	case info.IsDir():
+		mode |= 0040000
+	case info.Mode()&fs.ModeSymlink != 0:
+		mode |= 0120000
+	case info.Mode().IsRegular():
+		mode |= 0100000
+	}
+	mtime := uint32(info.ModTime().Unix())
+	b := u32(attrSize | attrPermissions | attrTimes)
+	b = binary.BigEndian.AppendUint64(b, uint64(info.Size()))
+	b = binary.BigEndian.AppendUint32(b, mode)
+	b = binary.BigEndian.AppendUint32(b, mtime)
+	return binary.BigEndian.AppendUint32(b, mtime)
+}
+
+// longname formats an entry like ls -l, which sftp shows for long listings
+func longname(info fs.FileInfo) string {
+	return fmt.Sprintf("%s 1 tester tester %8d %s %s",
+		info.Mode().String(), info.Size(), info.ModTime().Format(longnameTimeFormat), info.Name())
+}
+
+func u32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func str(s string) []byte {
+	return append(u32(uint32(len(s))), s...)
+}
+
+// sftpReader decodes the fields of a packet, remembering the first error so a
+// request is checked once after all its fields were read
+type sftpReader struct {
+	data []byte
+	err  error
+}
+
+func (r *sftpReader) take(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		r.err = errBadMessage
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *sftpReader) uint32() uint32 {
+	if b := r.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *sftpReader) uint64() uint64 {
+	if b := r.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *sftpReader) string() string {
+	return string(r.take(int(r.uint32())))
+}
+
+func (r *sftpReader) attrs() fileAttrs {
+	a := fileAttrs{flags: r.uint32()}
+	if a.flags&attrSize != 0 {
+		a.size = r.uint64()
+	}
+	if a.flags&attrUIDGID != 0 {
+		r.uint32()
+		r.uint32()
+	}
+	if a.flags&attrPermissions != 0 {
+		a.permissions = r.uint32()
+	}
+	if a.flags&attrTimes != 0 {
+		a.atime = r.uint32()
+		a.mtime = r.uint32()
+	}
+	if a.flags&attrExtended != 0 {
+		for n := r.uint32(); n > 0 && r.err == nil; n-- {
+			r.string()
+			r.string()
+		}
+	}
+	return a
+}
@@ -0,0 +1,167 @@
+// Package sshver detects the version of the local ssh client and what it supports
+package sshver
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is an OpenSSH client version as reported by ssh -V
+type Version struct {
+	Major    int
+	Minor    int
+	Portable int    // Portable release number, the 1 in "9.6p1"; 0 for OpenBSD's own builds
+	Windows  bool   // Microsoft's Win32-OpenSSH port
+	Crypto   string // Crypto library it was built with, e.g. "OpenSSL 3.0.13" or "LibreSSL 3.3.6"
+	Raw      string // First line of ssh -V
+}
+
+// versionPattern matches the version in "OpenSSH_9.6p1 Ubuntu-3ubuntu13, OpenSSL 3.0.13 30 Jan 2024"
+var versionPattern = regexp.MustCompile(`OpenSSH_(for_Windows_)?(\d+)\.(\d+)(?:p(\d+))?`)
+
+// cryptoPattern matches the crypto library and its version
+var cryptoPattern = regexp.MustCompile(`\b(OpenSSL|LibreSSL|BoringSSL)[ _]([0-9][0-9A-Za-z.\-]*)`)
+
+// Parse reads the version from the output of ssh -V
+func Parse(output string) (Version, error) {
+	raw := strings.TrimSpace(output)
+	if i := strings.IndexByte(raw, '\n'); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	match := versionPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return Version{Raw: raw}, fmt.Errorf("not an OpenSSH version: %q", raw)
+	}
+
+	v := Version{Windows: match[1] != "", Raw: raw}
+	v.Major, _ = strconv.Atoi(match[2])
+	v.Minor, _ = strconv.Atoi(match[3])
+	if match[4] != "" {
+		v.Portable, _ = strconv.Atoi(match[4])
+	}
+	if crypto := cryptoPattern.FindStringSubmatch(raw); crypto != nil {
+		v.Crypto = crypto[1] + " " + crypto[2]
+	}
+	return v, nil
+}
+
+// String renders the version for display, e.g. "OpenSSH 9.6p1 (OpenSSL 3.0.13)"
+func (v Version) String() string {
+	s := fmt.Sprintf("OpenSSH %d.%d", v.Major, v.Minor)
+	if v.Portable > 0 {
+		s += fmt.Sprintf("p%d", v.Portable)
+	}
+	var details []string
+	if v.Windows {
+		details = append(details, "Windows")
+	}
+	if v.Crypto != "" {
+		details = append(details, v.Crypto)
+	}
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+	return s
+}
+
+// AtLeast reports whether the version is major.minor or newer
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// SupportsProxyJump reports whether ProxyJump and ssh -J are available (OpenSSH 7.3)
+func (v Version) SupportsProxyJump() bool {
+	return v.AtLeast(7, 3)
+}
+
+// SupportsInclude reports whether ssh_config understands Include (OpenSSH 7.3)
+func (v Version) SupportsInclude() bool {
+	return v.AtLeast(7, 3)
+}
+
+// SupportsNativeTags reports whether ssh_config understands Tag and Match tagged (OpenSSH 9.4)
+func (v Version) SupportsNativeTags() bool {
+	return v.AtLeast(9, 4)
+}
+
+// SupportsRemoteCommand reports whether ssh_config understands RemoteCommand, and so
+// whether it can be overridden with RemoteCommand=none (OpenSSH 7.6)
+func (v Version) SupportsRemoteCommand() bool {
+	return v.AtLeast(7, 6)
+}
+
+// SupportsSetEnv reports whether ssh_config understands SetEnv, which sends
+// variables with given values to the server (OpenSSH 7.8)
+func (v Version) SupportsSetEnv() bool {
+	return v.AtLeast(7, 8)
+}
+
+// SCPUsesSFTP reports whether scp transfers over the SFTP protocol by default,
+// which takes remote paths literally instead of passing them to the remote shell
+// (OpenSSH 9.0)
+func (v Version) SCPUsesSFTP() bool {
+	return v.AtLeast(9, 0)
+}
+
+// NoRemoteCommandArgs returns the options that stop a host's RemoteCommand and
+// RequestTTY from applying to a command sshc runs itself, such as a probe or a
+// transfer. ssh refuses to run a command or subsystem on a host with a
+// RemoteCommand otherwise. An ssh older than OpenSSH 7.6 would reject them and
+// cannot read a RemoteCommand anyway, so it gets none; when the version is
+// unknown they are passed.
+func NoRemoteCommandArgs() []string {
+	if v, err := detect(); err == nil && !v.SupportsRemoteCommand() {
+		return nil
+	}
+	return []string{"-o", "RemoteCommand=none", "-o", "RequestTTY=no"}
+}
+
+// ErrNotInstalled is returned when there is no ssh client on the PATH
+var ErrNotInstalled = errors.New("ssh client not found")
+
+// ErrRemoteCommandUnsupported is returned when ssh rejected NoRemoteCommandArgs
+var ErrRemoteCommandUnsupported = errors.New("the installed ssh does not support RemoteCommand=none, OpenSSH 7.6 or newer is needed")
+
+// rejectedOption matches ssh's complaint about an option it does not know
+var rejectedOption = regexp.MustCompile(`(?i)bad configuration option: (remotecommand|requesttty)`)
+
+// RejectsNoRemoteCommand reports whether ssh's errors show it did not understand
+// NoRemoteCommandArgs
+func RejectsNoRemoteCommand(stderr string) bool {
+	return rejectedOption.MatchString(stderr)
+}
+
+// detect is the version NoRemoteCommandArgs checks, replaced in tests
+var detect = Detect
+
+var (
+	detectOnce    sync.Once
+	detectVersion Version
+	detectErr     error
+)
+
+// Detect runs ssh -V the first time it is called and returns the same result afterwards
+func Detect() (Version, error) {
+	detectOnce.Do(func() {
+		detectVersion, detectErr = run()
+	})
+	return detectVersion, detectErr
+}
+
+// run asks the ssh client for its version, which it prints to stderr
+func run() (Version, error) {
+	path, err := exec.LookPath("ssh")
+	if err != nil {
+		return Version{}, ErrNotInstalled
+	}
+	output, err := exec.Command(path, "-V").CombinedOutput()
+	if err != nil && len(output) == 0 {
+		return Version{}, fmt.Errorf("ssh -V failed: %w", err)
+	}
+	return Parse(string(output))
+}
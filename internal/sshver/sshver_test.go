@@ -0,0 +1,145 @@
+package sshver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		output   string
+		major    int
+		minor    int
+		portable int
+		windows  bool
+		crypto   string
+	}{
+		{"OpenSSH_7.2p2 Ubuntu-4ubuntu2.10, OpenSSL 1.0.2g  1 Mar 2016\n", 7, 2, 2, false, "OpenSSL 1.0.2g"},
+		{"OpenSSH_7.4p1, OpenSSL 1.0.2k-fips  26 Jan 2017", 7, 4, 1, false, "OpenSSL 1.0.2k-fips"},
+		{"OpenSSH_8.1p1, LibreSSL 2.7.3", 8, 1, 1, false, "LibreSSL 2.7.3"},
+		{"OpenSSH_8.9p1 Ubuntu-3ubuntu0.10, OpenSSL 3.0.2 15 Mar 2022", 8, 9, 1, false, "OpenSSL 3.0.2"},
+		{"OpenSSH_9.2p1 Debian-2+deb12u3, OpenSSL 3.0.11 19 Sep 2023", 9, 2, 1, false, "OpenSSL 3.0.11"},
+		{"OpenSSH_9.6, LibreSSL 3.9.0", 9, 6, 0, false, "LibreSSL 3.9.0"},
+		{"OpenSSH_9.8p1, LibreSSL 3.3.6", 9, 8, 1, false, "LibreSSL 3.3.6"},
+		{"OpenSSH_for_Windows_8.1p1, LibreSSL 3.0.2", 8, 1, 1, true, "LibreSSL 3.0.2"},
+		{"OpenSSH_for_Windows_9.5p1, LibreSSL 3.8.2\r\n", 9, 5, 1, true, "LibreSSL 3.8.2"},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.output)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.output, err)
+			continue
+		}
+		if v.Major != tt.major || v.Minor != tt.minor || v.Portable != tt.portable || v.Windows != tt.windows || v.Crypto != tt.crypto {
+			t.Errorf("Parse(%q) = %+v", tt.output, v)
+		}
+	}
+
+	for _, output := range []string{"", "usage: ssh [-46AaCfGgKkMNnqsTtVvXxYy]", "Sun_SSH_1.1.8, SSH protocols 1.5/2.0"} {
+		if _, err := Parse(output); err == nil {
+			t.Errorf("Parse(%q): expected an error", output)
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	tests := []struct {
+		output                                  string
+		jump, include, tags, rcmd, sftp, setenv bool
+	}{
+		{"OpenSSH_7.2p2", false, false, false, false, false, false},
+		{"OpenSSH_7.3p1", true, true, false, false, false, false},
+		{"OpenSSH_7.6p1", true, true, false, true, false, false},
+		{"OpenSSH_7.8p1", true, true, false, true, false, true},
+		{"OpenSSH_8.9p1", true, true, false, true, false, true},
+		{"OpenSSH_9.0p1", true, true, false, true, true, true},
+		{"OpenSSH_9.3p2", true, true, false, true, true, true},
+		{"OpenSSH_9.4p1", true, true, true, true, true, true},
+		{"OpenSSH_10.0p2", true, true, true, true, true, true},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.SupportsProxyJump() != tt.jump || v.SupportsInclude() != tt.include || v.SupportsNativeTags() != tt.tags {
+			t.Errorf("%s: ProxyJump=%v Include=%v Tags=%v", tt.output, v.SupportsProxyJump(), v.SupportsInclude(), v.SupportsNativeTags())
+		}
+		if v.SupportsRemoteCommand() != tt.rcmd || v.SCPUsesSFTP() != tt.sftp || v.SupportsSetEnv() != tt.setenv {
+			t.Errorf("%s: RemoteCommand=%v SCPUsesSFTP=%v SetEnv=%v", tt.output, v.SupportsRemoteCommand(), v.SCPUsesSFTP(), v.SupportsSetEnv())
+		}
+	}
+}
+
+func TestNoRemoteCommandArgs(t *testing.T) {
+	defer func(orig func() (Version, error)) { detect = orig }(detect)
+
+	tests := []struct {
+		output string
+		err    error
+		want   int
+	}{
+		{"OpenSSH_7.4p1", nil, 0},
+		{"OpenSSH_7.6p1", nil, 4},
+		{"", ErrNotInstalled, 4},
+	}
+	for _, tt := range tests {
+		detect = func() (Version, error) {
+			v, _ := Parse(tt.output)
+			return v, tt.err
+		}
+		if got := NoRemoteCommandArgs(); len(got) != tt.want {
+			t.Errorf("%q: NoRemoteCommandArgs() = %q, want %d arguments", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestRejectsNoRemoteCommand(t *testing.T) {
+	if !RejectsNoRemoteCommand("command-line line 0: Bad configuration option: remotecommand\n") {
+		t.Error("Expected an unknown RemoteCommand to be recognized")
+	}
+	if RejectsNoRemoteCommand("ssh: connect to host web port 22: Connection refused") {
+		t.Error("Expected a connection failure not to be taken for a rejected option")
+	}
+}
+
+func TestString(t *testing.T) {
+	v, _ := Parse("OpenSSH_for_Windows_9.5p1, LibreSSL 3.8.2")
+	if got := v.String(); got != "OpenSSH 9.5p1 (Windows, LibreSSL 3.8.2)" {
+		t.Errorf("String() = %q", got)
+	}
+	v, _ = Parse("OpenSSH_9.6")
+	if got := v.String(); got != "OpenSSH 9.6" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestRunWithoutSSH(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if _, err := run(); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Expected ErrNotInstalled, got %v", err)
+	}
+}
+
+func TestRunReadsStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as ssh")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'OpenSSH_9.7p1, OpenSSL 3.2.1 30 Jan 2024' >&2\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	v, err := run()
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if v.Major != 9 || v.Minor != 7 {
+		t.Errorf("run() = %+v", v)
+	}
+}
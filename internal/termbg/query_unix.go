@@ -0,0 +1,57 @@
+//go:build !windows
+
+package termbg
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// query asks the terminal for its background color and returns its answer. The
+// OSC 11 query is followed by a device attributes query, which every terminal
+// answers, so one that ignores OSC 11 is known not to as soon as it replies.
+func query(timeout time.Duration) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	// Without a deadline the read could block for good, so give up before
+	// anything is sent when the terminal cannot have one
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+	state, err := term.MakeRaw(tty.Fd())
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(tty.Fd(), state)
+
+	if _, err := tty.WriteString("\x1b]11;?\x1b\\\x1b[c"); err != nil {
+		return "", err
+	}
+
+	var reply strings.Builder
+	buf := make([]byte, 256)
+	for {
+		n, err := tty.Read(buf)
+		reply.Write(buf[:n])
+		if deviceAttributesReceived(reply.String()) {
+			return reply.String(), nil
+		}
+		if err != nil {
+			return reply.String(), err
+		}
+	}
+}
+
+// deviceAttributesReceived reports whether reply holds the answer to the device
+// attributes query, "\x1b[?...c", which comes after any answer to OSC 11
+func deviceAttributesReceived(reply string) bool {
+	start := strings.Index(reply, "\x1b[?")
+	return start >= 0 && strings.Contains(reply[start:], "c")
+}
@@ -0,0 +1,12 @@
+package termbg
+
+import (
+	"errors"
+	"time"
+)
+
+// query is not supported on Windows, where the console cannot be read without
+// taking the input away from the program
+func query(timeout time.Duration) (string, error) {
+	return "", errors.New("querying the terminal is not supported on Windows")
+}
@@ -0,0 +1,143 @@
+// Package termbg finds out whether the terminal has a light or a dark background
+package termbg
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Background is the background of the terminal, as far as it could be told
+type Background int
+
+const (
+	Unknown Background = iota
+	Dark
+	Light
+)
+
+func (b Background) String() string {
+	switch b {
+	case Dark:
+		return "dark"
+	case Light:
+		return "light"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultTimeout is how long Detect waits for the terminal to answer
+const DefaultTimeout = 150 * time.Millisecond
+
+// errNoReply is returned when the terminal did not report its background color
+var errNoReply = errors.New("terminal did not report its background color")
+
+// Detect asks the terminal for its background color with OSC 11, waiting at
+// most timeout for the answer, and falls back to the COLORFGBG variable when the
+// terminal cannot be asked or does not answer
+func Detect(timeout time.Duration) (Background, error) {
+	var err error
+	if os.Getenv("TERM") != "dumb" {
+		var reply string
+		// The answer may be complete even when the wait for the rest timed out
+		reply, err = query(timeout)
+		if bg := ParseOSC11(reply); bg != Unknown {
+			return bg, nil
+		}
+		if err == nil {
+			err = errNoReply
+		}
+	}
+	if bg := ParseCOLORFGBG(os.Getenv("COLORFGBG")); bg != Unknown {
+		return bg, nil
+	}
+	return Unknown, err
+}
+
+// ParseOSC11 reads the background color from a terminal's answer to OSC 11,
+// such as "\x1b]11;rgb:ffff/ffff/ffff\x1b\\". The answer may end with BEL or ST,
+// come with other replies around it, and give each channel with 1 to 4 hex
+// digits, as rgb: or rgba:, or as #rrggbb.
+func ParseOSC11(reply string) Background {
+	start := strings.Index(reply, "\x1b]11;")
+	if start < 0 {
+		// Some terminals answer with the 8-bit OSC
+		if start = strings.Index(reply, "\x9d11;"); start < 0 {
+			return Unknown
+		}
+		reply = reply[start+len("\x9d11;"):]
+	} else {
+		reply = reply[start+len("\x1b]11;"):]
+	}
+	if end := strings.IndexAny(reply, "\a\x1b\x9c"); end >= 0 {
+		reply = reply[:end]
+	}
+
+	red, green, blue, ok := parseColor(reply)
+	if !ok {
+		return Unknown
+	}
+	return fromLuminance(red, green, blue)
+}
+
+// parseColor reads an X11 color spec into channels between 0 and 1
+func parseColor(spec string) (red, green, blue float64, ok bool) {
+	spec = strings.TrimSpace(spec)
+	var channels []string
+	switch {
+	case strings.HasPrefix(spec, "rgb:"):
+		channels = strings.Split(spec[len("rgb:"):], "/")
+	case strings.HasPrefix(spec, "rgba:"):
+		channels = strings.Split(spec[len("rgba:"):], "/")
+		if len(channels) == 4 {
+			channels = channels[:3] // Alpha does not change how text reads
+		}
+	case strings.HasPrefix(spec, "#") && len(spec) == 7:
+		channels = []string{spec[1:3], spec[3:5], spec[5:7]}
+	}
+	if len(channels) != 3 {
+		return 0, 0, 0, false
+	}
+
+	values := make([]float64, 3)
+	for i, channel := range channels {
+		if len(channel) == 0 || len(channel) > 4 {
+			return 0, 0, 0, false
+		}
+		n, err := strconv.ParseUint(channel, 16, 16)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		values[i] = float64(n) / float64(uint64(1)<<(4*len(channel))-1)
+	}
+	return values[0], values[1], values[2], true
+}
+
+// fromLuminance tells light from dark by the perceived brightness of a color
+func fromLuminance(red, green, blue float64) Background {
+	if 0.2126*red+0.7152*green+0.0722*blue > 0.5 {
+		return Light
+	}
+	return Dark
+}
+
+// ParseCOLORFGBG reads the background from the COLORFGBG variable some terminals
+// set, "foreground;background" or "foreground;other;background" as ANSI color
+// numbers. Like vim, backgrounds 0 to 6 and 8 count as dark and the rest as light.
+func ParseCOLORFGBG(value string) Background {
+	fields := strings.Split(value, ";")
+	if len(fields) < 2 {
+		return Unknown
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(fields[len(fields)-1]))
+	if err != nil || n < 0 || n > 15 {
+		return Unknown
+	}
+	if n <= 6 || n == 8 {
+		return Dark
+	}
+	return Light
+}
@@ -0,0 +1,74 @@
+package termbg
+
+import "testing"
+
+func TestParseOSC11(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  Background
+	}{
+		{"xterm white", "\x1b]11;rgb:ffff/ffff/ffff\x1b\\", Light},
+		{"xterm default black", "\x1b]11;rgb:0000/0000/0000\x1b\\", Dark},
+		{"BEL terminated", "\x1b]11;rgb:1e1e/1e1e/2e2e\a", Dark},
+		{"with device attributes", "\x1b]11;rgb:fdfd/f6f6/e3e3\x1b\\\x1b[?62;22;52c", Light},
+		{"after other input", "\x1b[?1;2c\x1b]11;rgb:2828/2a2a/3636\x1b\\", Dark},
+		{"two digits", "\x1b]11;rgb:ee/e8/d5\a", Light},
+		{"one digit", "\x1b]11;rgb:f/f/f\a", Light},
+		{"three digits", "\x1b]11;rgb:202/202/202\a", Dark},
+		{"urxvt rgba", "\x1b]11;rgba:0000/0000/0000/e665\x1b\\", Dark},
+		{"hash", "\x1b]11;#eff1f5\x1b\\", Light},
+		{"8-bit", "\x9d11;rgb:ffff/ffff/ffff\x9c", Light},
+		{"mid gray", "\x1b]11;rgb:7f7f/7f7f/7f7f\a", Dark},
+		{"saturated yellow", "\x1b]11;rgb:ffff/ffff/0000\a", Light},
+		{"only device attributes", "\x1b[?62;22c", Unknown},
+		{"empty", "", Unknown},
+		{"cut off", "\x1b]11;rgb:ffff/ff", Unknown},
+		{"not hex", "\x1b]11;rgb:zzzz/ffff/ffff\a", Unknown},
+		{"too many digits", "\x1b]11;rgb:fffff/ffff/ffff\a", Unknown},
+		{"named color", "\x1b]11;white\a", Unknown},
+		{"other OSC", "\x1b]10;rgb:ffff/ffff/ffff\a", Unknown},
+	}
+	for _, tt := range tests {
+		if got := ParseOSC11(tt.reply); got != tt.want {
+			t.Errorf("%s: ParseOSC11(%q) = %v, want %v", tt.name, tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestParseCOLORFGBG(t *testing.T) {
+	tests := map[string]Background{
+		"15;0":         Dark,
+		"0;15":         Light,
+		"12;8":         Dark,
+		"0;7":          Light,
+		"15;default;0": Dark,
+		"0;default;15": Light,
+		"15;default":   Unknown,
+		"":             Unknown,
+		"7":            Unknown,
+		"0;16":         Unknown,
+	}
+	for value, want := range tests {
+		if got := ParseCOLORFGBG(value); got != want {
+			t.Errorf("ParseCOLORFGBG(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestDetectFallsBackToCOLORFGBG(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	t.Setenv("COLORFGBG", "0;15")
+	if bg, err := Detect(DefaultTimeout); bg != Light || err != nil {
+		t.Errorf("Detect() = %v, %v; want light from COLORFGBG", bg, err)
+	}
+}
+
+func TestDeviceAttributesReceived(t *testing.T) {
+	if deviceAttributesReceived("\x1b]11;rgb:ffff/ffff/ffff\x1b\\") {
+		t.Error("the OSC 11 answer was taken for device attributes")
+	}
+	if !deviceAttributesReceived("\x1b]11;rgb:ffff/ffff/ffff\x1b\\\x1b[?62;22c") {
+		t.Error("device attributes were missed")
+	}
+}
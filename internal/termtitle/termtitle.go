@@ -0,0 +1,102 @@
+// Package termtitle sets the terminal window title while a connection is open
+package termtitle
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// DefaultTemplate is the title used unless the app config sets another
+const DefaultTemplate = "{name} ({hostname})"
+
+// Off disables the window title when used as the template
+const Off = "off"
+
+// Template returns the title template from the app config, or "" when titles are disabled
+func Template(c *config.AppConfig) string {
+	if c == nil || c.WindowTitle == "" {
+		return DefaultTemplate
+	}
+	if strings.EqualFold(strings.TrimSpace(c.WindowTitle), Off) {
+		return ""
+	}
+	return c.WindowTitle
+}
+
+// Format fills the {name}, {hostname} and {user} placeholders of a template. Without a
+// HostName the host name stands in for it.
+func Format(template, name, hostname, user string) string {
+	if hostname == "" {
+		hostname = name
+	}
+	r := strings.NewReplacer("{name}", name, "{hostname}", hostname, "{user}", user)
+	return r.Replace(template)
+}
+
+// Set saves the current title on the terminal's title stack and replaces it. Nothing
+// is written when w is not a terminal or title is empty.
+func Set(w io.Writer, title string) {
+	if title == "" || !isTerminal(w) {
+		return
+	}
+	io.WriteString(w, setSequence(title, inTmux()))
+}
+
+// Restore brings back the title saved by Set
+func Restore(w io.Writer) {
+	if !isTerminal(w) {
+		return
+	}
+	io.WriteString(w, restoreSequence(inTmux()))
+}
+
+// setSequence pushes the current title (XTWINOPS 22) and sets the icon name and
+// window title (OSC 0)
+func setSequence(title string, tmux bool) string {
+	return wrap("\x1b[22;0t", tmux) + wrap("\x1b]0;"+sanitize(title)+"\x07", tmux)
+}
+
+// restoreSequence clears the title, which terminals without a title stack treat as
+// going back to their default, then pops the saved one (XTWINOPS 23)
+func restoreSequence(tmux bool) string {
+	return wrap("\x1b]0;\x07", tmux) + wrap("\x1b[23;0t", tmux)
+}
+
+// wrap passes a sequence through tmux to the outer terminal with a DCS tmux; envelope,
+// in which every ESC of the sequence is doubled
+func wrap(seq string, tmux bool) string {
+	if !tmux {
+		return seq
+	}
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// sanitize drops control characters and invalid UTF-8, so a host name cannot end the
+// sequence early
+func sanitize(title string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, title)
+}
+
+// inTmux reports whether sshc runs inside tmux
+func inTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
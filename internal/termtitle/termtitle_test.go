@@ -0,0 +1,67 @@
+package termtitle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func TestSequences(t *testing.T) {
+	if got := setSequence("web (10.0.0.5)", false); got != "\x1b[22;0t\x1b]0;web (10.0.0.5)\x07" {
+		t.Errorf("setSequence() = %q", got)
+	}
+	if got := restoreSequence(false); got != "\x1b]0;\x07\x1b[23;0t" {
+		t.Errorf("restoreSequence() = %q", got)
+	}
+
+	// Inside tmux each sequence gets its own passthrough with ESC doubled
+	want := "\x1bPtmux;\x1b\x1b[22;0t\x1b\\" + "\x1bPtmux;\x1b\x1b]0;web\x07\x1b\\"
+	if got := setSequence("web", true); got != want {
+		t.Errorf("setSequence(tmux) = %q, want %q", got, want)
+	}
+	want = "\x1bPtmux;\x1b\x1b]0;\x07\x1b\\" + "\x1bPtmux;\x1b\x1b[23;0t\x1b\\"
+	if got := restoreSequence(true); got != want {
+		t.Errorf("restoreSequence(tmux) = %q, want %q", got, want)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	if got := setSequence("evil\x07\x1b]0;owned\x9c", false); got != "\x1b[22;0t\x1b]0;evil]0;owned\x07" {
+		t.Errorf("Expected control characters to be dropped, got %q", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format(DefaultTemplate, "web", "10.0.0.5", "deploy"); got != "web (10.0.0.5)" {
+		t.Errorf("Format() = %q", got)
+	}
+	if got := Format("{user}@{hostname}", "web", "", "deploy"); got != "deploy@web" {
+		t.Errorf("Format() without hostname = %q", got)
+	}
+	// Values are not expanded again
+	if got := Format("{name}", "{user}", "", "root"); got != "{user}" {
+		t.Errorf("Format() = %q", got)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	if Template(nil) != DefaultTemplate || Template(&config.AppConfig{}) != DefaultTemplate {
+		t.Error("Expected the default template without a configured one")
+	}
+	if got := Template(&config.AppConfig{WindowTitle: "ssh: {name}"}); got != "ssh: {name}" {
+		t.Errorf("Template() = %q", got)
+	}
+	if got := Template(&config.AppConfig{WindowTitle: "OFF"}); got != "" {
+		t.Errorf("Expected off to disable titles, got %q", got)
+	}
+}
+
+func TestSetSkipsNonTerminals(t *testing.T) {
+	var out bytes.Buffer
+	Set(&out, "web")
+	Restore(&out)
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written to a non-terminal, got %q", out.String())
+	}
+}
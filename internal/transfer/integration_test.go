@@ -0,0 +1,152 @@
+//go:build integration
+
+package transfer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/sshtest"
+)
+
+// startFixture runs an sshd stand-in reachable as "fixture" through the returned
+// config file, logging in with a key from an agent as the real ssh would
+func startFixture(t *testing.T) (*sshtest.Server, string) {
+	t.Helper()
+	for _, program := range []string{"ssh", "scp"} {
+		if _, err := exec.LookPath(program); err != nil {
+			t.Skip(program + " not available")
+		}
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	server := sshtest.NewServer(t)
+	server.Authorize(sshtest.StartAgent(t))
+	configFile := filepath.Join(home, "config")
+	if err := os.WriteFile(configFile, []byte(server.Config("fixture")+server.Config("mirror")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return server, configFile
+}
+
+func run(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%q failed: %v\n%s", cmd.Args, err, out)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestIntegrationSCPUploadAndDownload(t *testing.T) {
+	server, configFile := startFixture(t)
+	local := t.TempDir()
+	source := filepath.Join(local, nastyName)
+	if err := os.WriteFile(source, []byte("payload\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(server.Home, "drop box"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := TransferRequest{Host: "fixture", Direction: Upload, LocalPath: source, RemotePath: "drop box/", ConfigFile: configFile}
+	run(t, upload.BuildSCPCommand())
+	uploaded := filepath.Join(server.Home, "drop box", nastyName)
+	if got := readFile(t, uploaded); got != "payload\n" {
+		t.Errorf("uploaded file holds %q", got)
+	}
+
+	back := filepath.Join(local, "back.txt")
+	download := TransferRequest{Host: "fixture", Direction: Download, RemotePath: uploaded, LocalPath: back, ConfigFile: configFile}
+	run(t, download.BuildSCPCommand())
+	if got := readFile(t, back); got != "payload\n" {
+		t.Errorf("downloaded file holds %q", got)
+	}
+}
+
+func TestIntegrationSCPRecursiveUpload(t *testing.T) {
+	server, configFile := startFixture(t)
+	tree := filepath.Join(t.TempDir(), "site")
+	if err := os.MkdirAll(filepath.Join(tree, "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tree, "assets", "app.css"), []byte("body{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := ParseTransferArgs(tree, "fixture:"+server.Home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.ConfigFile = configFile
+	run(t, req.BuildSCPCommand())
+	if got := readFile(t, filepath.Join(server.Home, "site", "assets", "app.css")); got != "body{}" {
+		t.Errorf("uploaded tree holds %q", got)
+	}
+}
+
+func TestIntegrationRemoteCopy(t *testing.T) {
+	server, configFile := startFixture(t)
+	if err := os.WriteFile(filepath.Join(server.Home, "app.log"), []byte("started\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := RemoteCopyRequest{SourceHost: "fixture", SourcePath: "app.log", DestHost: "mirror", DestPath: "copy.log", ConfigFile: configFile}
+	run(t, req.BuildSCPCommand())
+	if got := readFile(t, filepath.Join(server.Home, "copy.log")); got != "started\n" {
+		t.Errorf("copied file holds %q", got)
+	}
+}
+
+func TestIntegrationListDirectory(t *testing.T) {
+	server, configFile := startFixture(t)
+	dir := filepath.Join(server.Home, "logs")
+	if err := os.MkdirAll(filepath.Join(dir, "archive"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "my app.log"), []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := NewSFTPSession("fixture", configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	home, err := session.GetHomeDirectory()
+	if err != nil || home != server.Home {
+		t.Fatalf("GetHomeDirectory() = %q, %v, want %q", home, err, server.Home)
+	}
+
+	files, err := session.ListDirectory("~/logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	if want := []string{"..", "archive", "my app.log"}; !slices.Equal(names, want) {
+		t.Fatalf("ListDirectory() names = %q, want %q", names, want)
+	}
+	if !files[1].IsDir || files[2].IsDir || files[2].Size != 5 || files[2].Path != filepath.Join(dir, "my app.log") {
+		t.Errorf("ListDirectory() entries = %+v", files)
+	}
+
+	info, err := session.Stat(filepath.Join(dir, "my app.log"))
+	if err != nil || info.Size != 5 || info.IsDir {
+		t.Errorf("Stat() = %+v, %v", info, err)
+	}
+}
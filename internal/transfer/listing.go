@@ -0,0 +1,157 @@
+package transfer
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/shellquote"
+)
+
+// listDirectoryCommand builds the remote command listing path with ls -la. GNU ls
+// prints modification times as Unix seconds; BSD and macOS ls reject
+// --time-style and fall back to -T, which prints them in full, and anything else
+// to the default format. The options are tried on / so only one ls lists path:
+// GNU ls exits 1 after listing what it could, which must not run the next. The
+// command fails when path is not a readable directory.
+func listDirectoryCommand(dir string) string {
+	quoted := shellquote.Quote(dir)
+	return "test -d " + quoted + " && test -r " + quoted + " || exit 2; " +
+		"if ls -d --time-style=+%s / >/dev/null 2>&1; then ls -la --time-style=+%s " + quoted + "; " +
+		"elif ls -dT / >/dev/null 2>&1; then ls -laT " + quoted + "; " +
+		"else ls -la " + quoted + "; fi 2>/dev/null; test $? -le 1"
+}
+
+// ParseListing turns the output of listDirectoryCommand for dir into files, in
+// the order ls printed them. The "total" line, "." and "..", and lines that are
+// not file entries are left out. now is used to place the times of the default
+// format, which leave out the year, and its location to read times ls printed
+// in local time.
+func ParseListing(output, dir string, now time.Time) []RemoteFile {
+	var files []RemoteFile
+	for _, line := range strings.Split(output, "\n") {
+		file, ok := parseListingLine(strings.TrimRight(line, "\r"), now)
+		if !ok || file.Name == "." || file.Name == ".." {
+			continue
+		}
+		file.Path = path.Join(dir, file.Name)
+		files = append(files, file)
+	}
+	return files
+}
+
+// parseListingLine parses a line of ls -l output in any of the formats of
+// listDirectoryCommand:
+//
+//	-rw-r--r--  1 deploy deploy 1048576 1760600000 app.log           (GNU, --time-style=+%s)
+//	-rw-r--r--  1 alice  staff     2048 Oct 16 09:12:45 2026 app.log (BSD, -T)
+//	-rw-r--r--  1 root   root      1234 Oct 16 09:12 app.log         (default)
+//
+// Everything after the time is the name, spaces included; symlinks end in
+// " -> target".
+func parseListingLine(line string, now time.Time) (RemoteFile, bool) {
+	fields, rest := cutFields(line, 5)
+	if len(fields) < 5 || !isFileMode(fields[0]) {
+		return RemoteFile{}, false
+	}
+	file := RemoteFile{Mode: fields[0]}
+
+	// Devices show "major, minor" where other files show their size
+	if strings.HasSuffix(fields[4], ",") {
+		var minor []string
+		if minor, rest = cutFields(rest, 1); len(minor) < 1 {
+			return RemoteFile{}, false
+		}
+	} else {
+		size, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return RemoteFile{}, false
+		}
+		file.Size = size
+	}
+
+	modTime, rest, ok := cutTime(rest, now)
+	if !ok || rest == "" {
+		return RemoteFile{}, false
+	}
+	file.ModTime = modTime
+
+	switch file.Mode[0] {
+	case 'd':
+		file.IsDir = true
+	case 'l':
+		file.IsLink = true
+		if name, target, found := strings.Cut(rest, " -> "); found {
+			rest, file.LinkTarget = name, target
+		}
+	}
+	file.Name = rest
+	return file, true
+}
+
+// cutTime reads the modification time at the start of s in any of the formats of
+// parseListingLine, and returns the rest of s
+func cutTime(s string, now time.Time) (time.Time, string, bool) {
+	first, rest := cutFields(s, 1)
+	if len(first) < 1 {
+		return time.Time{}, "", false
+	}
+	if seconds, err := strconv.ParseInt(first[0], 10, 64); err == nil {
+		return time.Unix(seconds, 0).In(now.Location()), rest, true
+	}
+
+	fields, rest := cutFields(rest, 2)
+	if len(fields) < 2 {
+		return time.Time{}, "", false
+	}
+	month, day, clock := first[0], fields[0], fields[1]
+	switch strings.Count(clock, ":") {
+	case 2: // BSD -T: Oct 16 09:12:45 2026
+		year, after := cutFields(rest, 1)
+		if len(year) < 1 {
+			return time.Time{}, "", false
+		}
+		t, err := time.ParseInLocation("Jan 2 15:04:05 2006", month+" "+day+" "+clock+" "+year[0], now.Location())
+		return t, after, err == nil
+	case 1: // Default format within six months: Oct 16 09:12, of this year or the last
+		t, err := time.ParseInLocation("Jan 2 15:04 2006", month+" "+day+" "+clock+" "+strconv.Itoa(now.Year()), now.Location())
+		if err == nil && t.After(now.Add(24*time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t, rest, err == nil
+	default: // Default format otherwise: Mar 3 2025
+		t, err := time.ParseInLocation("Jan 2 2006", month+" "+day+" "+clock, now.Location())
+		return t, rest, err == nil
+	}
+}
+
+// cutFields splits the first n whitespace separated fields off s and returns
+// them with the rest of s, which keeps its inner whitespace. There are fewer
+// fields when s runs out.
+func cutFields(s string, n int) ([]string, string) {
+	fields := make([]string, 0, n)
+	for len(fields) < n {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			break
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		fields = append(fields, s[:end])
+		s = s[end:]
+	}
+	// A single separator ends the last field; a name may start with spaces
+	if len(s) > 0 && (s[0] == ' ' || s[0] == '\t') {
+		s = s[1:]
+	}
+	return fields, s
+}
+
+// isFileMode reports whether s looks like the mode column of ls -l, such as
+// "drwxr-xr-x", "-rw-r--r--@" or "lrwxrwxrwx."
+func isFileMode(s string) bool {
+	return len(s) >= 10 && strings.ContainsRune("-dlcbps", rune(s[0]))
+}
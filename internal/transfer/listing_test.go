@@ -0,0 +1,155 @@
+package transfer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listingNow is when the fixtures were listed, for the default format's dates
+var listingNow = time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+
+func readListing(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseListingGNU(t *testing.T) {
+	files := ParseListing(readListing(t, "ls-gnu.txt"), "/home/deploy", listingNow)
+	unix := func(seconds int64) time.Time { return time.Unix(seconds, 0).UTC() }
+
+	want := []RemoteFile{
+		{Name: ".bash_history", Path: "/home/deploy/.bash_history", Size: 2514, ModTime: unix(1760590000), Mode: "-rw-------"},
+		{Name: ".bash_logout", Path: "/home/deploy/.bash_logout", Size: 220, ModTime: unix(1750000000), Mode: "-rw-r--r--"},
+		{Name: "app.log", Path: "/home/deploy/app.log", Size: 1048576, ModTime: unix(1760599000), Mode: "-rw-r--r--"},
+		{Name: "app  log (old).txt", Path: "/home/deploy/app  log (old).txt", Size: 52311, ModTime: unix(1760000000), Mode: "-rw-r--r--"},
+		{Name: "current", Path: "/home/deploy/current", Size: 15, ModTime: unix(1760500000), Mode: "lrwxrwxrwx", IsLink: true, LinkTarget: "releases/v2.1.0"},
+		{Name: "my link", Path: "/home/deploy/my link", Size: 22, ModTime: unix(1760500000), Mode: "lrwxrwxrwx", IsLink: true, LinkTarget: "/srv/shared data"},
+		{Name: "null", Path: "/home/deploy/null", ModTime: unix(1750000000), Mode: "crw-rw-rw-"},
+		{Name: "release notes", Path: "/home/deploy/release notes", IsDir: true, Size: 4096, ModTime: unix(1760400000), Mode: "drwxr-xr-x"},
+		{Name: "releases", Path: "/home/deploy/releases", IsDir: true, Size: 4096, ModTime: unix(1760500000), Mode: "drwxr-xr-x"},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ParseListing() =\n%+v\nwant\n%+v", files, want)
+	}
+}
+
+func TestParseListingBSD(t *testing.T) {
+	files := ParseListing(readListing(t, "ls-bsd.txt"), "/Users/alice/work", listingNow)
+
+	want := []RemoteFile{
+		{Name: ".DS_Store", Path: "/Users/alice/work/.DS_Store", Size: 6148, ModTime: time.Date(2026, 10, 15, 18, 3, 10, 0, time.UTC), Mode: "-rw-r--r--@"},
+		{Name: "app.log", Path: "/Users/alice/work/app.log", Size: 1048576, ModTime: time.Date(2026, 10, 16, 9, 12, 45, 0, time.UTC), Mode: "-rw-r--r--"},
+		{Name: "name with  spaces.log", Path: "/Users/alice/work/name with  spaces.log", Size: 2048, ModTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Mode: "-rw-r--r--"},
+		{Name: "latest", Path: "/Users/alice/work/latest", Size: 7, ModTime: time.Date(2026, 10, 16, 9, 12, 45, 0, time.UTC), Mode: "lrwxr-xr-x", IsLink: true, LinkTarget: "app.log"},
+		{Name: "logs", Path: "/Users/alice/work/logs", IsDir: true, Size: 96, ModTime: time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC), Mode: "drwxr-xr-x"},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ParseListing() =\n%+v\nwant\n%+v", files, want)
+	}
+}
+
+func TestParseListingDefaultFormat(t *testing.T) {
+	files := ParseListing(readListing(t, "ls-busybox.txt"), "/var/log", listingNow)
+
+	want := map[string]time.Time{
+		"messages":   time.Date(2026, 10, 16, 9, 12, 0, 0, time.UTC),
+		"messages.0": time.Date(2025, 12, 30, 23, 59, 0, 0, time.UTC), // Would be in the future this year
+		"old log":    time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+	}
+	if len(files) != len(want) {
+		t.Fatalf("ParseListing() = %+v, want %d files", files, len(want))
+	}
+	for _, file := range files {
+		if !file.ModTime.Equal(want[file.Name]) {
+			t.Errorf("%q modified %v, want %v", file.Name, file.ModTime, want[file.Name])
+		}
+	}
+}
+
+func TestParseListingSkipsOtherLines(t *testing.T) {
+	output := "total 8\nls: cannot access 'x': No such file or directory\n\n-rw-r--r-- 1 a b notasize 1760000000 f\n-rw-r--r-- 1 a b 12 1760000000\n"
+	if files := ParseListing(output, "/", listingNow); len(files) != 0 {
+		t.Errorf("ParseListing() = %+v, want nothing", files)
+	}
+}
+
+func TestListDirectoryCommandListsLocally(t *testing.T) {
+	if _, err := exec.LookPath("ls"); err != nil {
+		t.Skip("ls not available")
+	}
+
+	dir := t.TempDir()
+	name := "two  spaces.log"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(dir, name), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("sh", "-c", listDirectoryCommand(dir)).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := ParseListing(string(out), dir, time.Now())
+	if len(files) != 1 || files[0].Name != name || files[0].Size != 5 {
+		t.Fatalf("ParseListing() = %+v, want %q of 5 bytes", files, name)
+	}
+	// The default format only has minutes
+	if diff := files[0].ModTime.Sub(modTime); diff < -time.Minute || diff > time.Minute {
+		t.Errorf("ModTime = %v, want %v", files[0].ModTime, modTime)
+	}
+	if strings.TrimSpace(files[0].Mode) == "" {
+		t.Error("Mode is empty")
+	}
+}
+
+func TestListDirectoryCommandListsPartialListingOnce(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// An ls that takes every option but cannot read every entry of the directory
+	bin := t.TempDir()
+	fake := "#!/bin/sh\nfor last; do :; done\n[ \"$last\" = / ] && exit 0\n" +
+		"echo '-rw-r--r-- 1 deploy deploy 5 1760600000 app.log'\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(bin, "ls"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	out, err := exec.Command("sh", "-c", listDirectoryCommand(t.TempDir())).Output()
+	if err != nil {
+		t.Fatalf("listing failed: %v", err)
+	}
+	if files := ParseListing(string(out), "/srv", time.Now()); len(files) != 1 {
+		t.Errorf("ParseListing() = %+v, want app.log once", files)
+	}
+}
+
+func TestListDirectoryCommandFailsForMissingDirectory(t *testing.T) {
+	if _, err := exec.LookPath("ls"); err != nil {
+		t.Skip("ls not available")
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing"), file} {
+		if out, err := exec.Command("sh", "-c", listDirectoryCommand(path)).Output(); err == nil {
+			t.Errorf("listing %s succeeded with %q, want an error", path, out)
+		}
+	}
+}
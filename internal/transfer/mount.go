@@ -0,0 +1,174 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+// MountRunner runs a command and returns its combined output
+type MountRunner func(name string, args ...string) ([]byte, error)
+
+// Mounter mounts hosts with sshfs and keeps track of the mounts it made
+type Mounter struct {
+	run      MountRunner
+	lookPath func(file string) (string, error)
+	goos     string
+
+	mu     sync.Mutex
+	mounts map[string]SSHFSMount // By host name
+}
+
+// NewMounter creates a Mounter that runs sshfs and the unmount tools directly
+func NewMounter() *Mounter {
+	return newMounter(func(name string, args ...string) ([]byte, error) {
+		cmd := exec.Command(name, args...)
+		sshclog.Exec(cmd.Args)
+		return cmd.CombinedOutput()
+	}, exec.LookPath, runtime.GOOS)
+}
+
+func newMounter(run MountRunner, lookPath func(string) (string, error), goos string) *Mounter {
+	return &Mounter{
+		run:      run,
+		lookPath: lookPath,
+		goos:     goos,
+		mounts:   make(map[string]SSHFSMount),
+	}
+}
+
+// Check returns an error explaining why mounting cannot work here, or nil
+func (m *Mounter) Check() error {
+	switch m.goos {
+	case "linux", "darwin", "freebsd":
+	default:
+		return fmt.Errorf("sshfs mounts are not supported on %s", m.goos)
+	}
+	if _, err := m.lookPath("sshfs"); err != nil {
+		return fmt.Errorf("sshfs is not installed. %s", GetSSHFSInstallInstructions())
+	}
+	return nil
+}
+
+// DefaultMountPoint returns ~/mnt/<host>
+func DefaultMountPoint(host string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "mnt", host), nil
+}
+
+// Mount mounts remotePath of host at mountPoint, creating the directory first.
+// An empty remotePath mounts the remote home directory.
+func (m *Mounter) Mount(host, remotePath, mountPoint, configFile string) error {
+	if err := m.Check(); err != nil {
+		return err
+	}
+	if _, mounted := m.Mounted(host); mounted {
+		return fmt.Errorf("%s is already mounted", host)
+	}
+
+	if err := os.MkdirAll(mountPoint, 0700); err != nil {
+		return fmt.Errorf("failed to create mount point: %w", err)
+	}
+	if output, err := m.run("sshfs", mountArgs(m.goos, host, remotePath, mountPoint, configFile)...); err != nil {
+		if sshver.RejectsNoRemoteCommand(string(output)) {
+			return sshver.ErrRemoteCommandUnsupported
+		}
+		return commandError("sshfs", output, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mounts[host] = SSHFSMount{Host: host, RemotePath: remotePath, MountPoint: mountPoint, ConfigFile: configFile}
+	return nil
+}
+
+// Unmount unmounts a host mounted with Mount. The mount point directory is kept.
+func (m *Mounter) Unmount(host string) error {
+	mount, ok := m.Mounted(host)
+	if !ok {
+		return fmt.Errorf("%s is not mounted", host)
+	}
+
+	name, args := unmountCommand(m.goos, mount.MountPoint, m.lookPath)
+	if output, err := m.run(name, args...); err != nil {
+		return commandError(name, output, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.mounts, host)
+	return nil
+}
+
+// Mounted returns the mount of host, if it is mounted
+func (m *Mounter) Mounted(host string) (SSHFSMount, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mount, ok := m.mounts[host]
+	return mount, ok
+}
+
+// mountArgs builds the sshfs arguments. The host is addressed by its alias and the
+// config is passed with -F, so ssh applies the host's settings, except for a
+// RemoteCommand that would stop ssh from starting the sftp subsystem.
+func mountArgs(goos, host, remotePath, mountPoint, configFile string) []string {
+	args := []string{host + ":" + remotePath, mountPoint}
+	if configFile != "" {
+		args = append(args, "-F", configFile)
+	}
+	args = append(args,
+		"-o", "reconnect",
+		"-o", "ServerAliveInterval=15",
+		"-o", "follow_symlinks",
+		"-o", "ssh_command=ssh "+strings.Join(sshver.NoRemoteCommandArgs(), " "),
+	)
+	if goos == "darwin" {
+		// Finder shows the volume name, which macOS limits to 27 characters
+		volName := host + ":" + remotePath
+		if len(volName) > 27 {
+			volName = host
+		}
+		args = append(args, "-o", "volname="+volName)
+	}
+	return args
+}
+
+// unmountCommand returns the command that unmounts a FUSE mount on goos
+func unmountCommand(goos, mountPoint string, lookPath func(string) (string, error)) (string, []string) {
+	if goos == "linux" {
+		return fusermount(lookPath), []string{"-u", mountPoint}
+	}
+	return "umount", []string{mountPoint}
+}
+
+// fusermount returns fusermount3, which FUSE 3 installs without fusermount, or
+// fusermount when fusermount3 is not on the PATH
+func fusermount(lookPath func(string) (string, error)) string {
+	if _, err := lookPath("fusermount3"); err == nil {
+		return "fusermount3"
+	}
+	return "fusermount"
+}
+
+// commandError adds the command's own explanation to err
+func commandError(name string, output []byte, err error) error {
+	if message := strings.TrimSpace(string(output)); message != "" {
+		return fmt.Errorf("%s failed: %s", name, message)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return err
+}
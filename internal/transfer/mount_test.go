@@ -0,0 +1,133 @@
+package transfer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubRunner records the commands a Mounter runs instead of running them
+type stubRunner struct {
+	calls  [][]string
+	output string
+	err    error
+}
+
+func (r *stubRunner) run(name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	return []byte(r.output), r.err
+}
+
+func foundSSHFS(string) (string, error) { return "/usr/bin/sshfs", nil }
+
+func TestMounterMountAndUnmount(t *testing.T) {
+	tests := []struct {
+		goos       string
+		wantMount  []string
+		wantUmount []string
+	}{
+		{
+			goos:       "linux",
+			wantMount:  []string{"sshfs", "web:/var/www", "MNT", "-F", "/home/me/.ssh/config", "-o", "reconnect", "-o", "ServerAliveInterval=15", "-o", "follow_symlinks", "-o", "ssh_command=ssh -o RemoteCommand=none -o RequestTTY=no"},
+			wantUmount: []string{"fusermount3", "-u", "MNT"},
+		},
+		{
+			goos:       "darwin",
+			wantMount:  []string{"sshfs", "web:/var/www", "MNT", "-F", "/home/me/.ssh/config", "-o", "reconnect", "-o", "ServerAliveInterval=15", "-o", "follow_symlinks", "-o", "ssh_command=ssh -o RemoteCommand=none -o RequestTTY=no", "-o", "volname=web:/var/www"},
+			wantUmount: []string{"umount", "MNT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			mountPoint := filepath.Join(t.TempDir(), "mnt", "web")
+			runner := &stubRunner{}
+			m := newMounter(runner.run, foundSSHFS, tt.goos)
+
+			if err := m.Mount("web", "/var/www", mountPoint, "/home/me/.ssh/config"); err != nil {
+				t.Fatalf("Mount() error = %v", err)
+			}
+			if info, err := os.Stat(mountPoint); err != nil || !info.IsDir() {
+				t.Errorf("Expected the mount point to be created, stat error = %v", err)
+			}
+			if mount, ok := m.Mounted("web"); !ok || mount.MountPoint != mountPoint {
+				t.Errorf("Expected web to be tracked as mounted at %s, got %+v", mountPoint, mount)
+			}
+			if err := m.Mount("web", "/", mountPoint, ""); err == nil {
+				t.Error("Expected mounting web twice to fail")
+			}
+
+			if err := m.Unmount("web"); err != nil {
+				t.Fatalf("Unmount() error = %v", err)
+			}
+			if _, ok := m.Mounted("web"); ok {
+				t.Error("Expected web to be unmounted")
+			}
+
+			if len(runner.calls) != 2 {
+				t.Fatalf("Expected a mount and an unmount command, got %q", runner.calls)
+			}
+			for i, want := range [][]string{tt.wantMount, tt.wantUmount} {
+				want = slices.Clone(want)
+				for j := range want {
+					want[j] = strings.ReplaceAll(want[j], "MNT", mountPoint)
+				}
+				if !slices.Equal(runner.calls[i], want) {
+					t.Errorf("Command %d = %q, want %q", i, runner.calls[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmountFallsBackToFusermount(t *testing.T) {
+	withoutFUSE3 := func(file string) (string, error) {
+		if file == "fusermount3" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+	if name, args := unmountCommand("linux", "/mnt/web", withoutFUSE3); name != "fusermount" || !slices.Equal(args, []string{"-u", "/mnt/web"}) {
+		t.Errorf("unmountCommand() = %s %q, want fusermount -u /mnt/web", name, args)
+	}
+	if name, _ := unmountCommand("linux", "/mnt/web", foundSSHFS); name != "fusermount3" {
+		t.Errorf("unmountCommand() = %s, want fusermount3 when it is installed", name)
+	}
+}
+
+func TestMounterFailedMountIsNotTracked(t *testing.T) {
+	runner := &stubRunner{output: "read: Connection reset by peer\n", err: errors.New("exit status 1")}
+	m := newMounter(runner.run, foundSSHFS, "linux")
+
+	err := m.Mount("web", "", filepath.Join(t.TempDir(), "web"), "")
+	if err == nil || !strings.Contains(err.Error(), "Connection reset by peer") {
+		t.Errorf("Expected the sshfs output in the error, got %v", err)
+	}
+	if _, ok := m.Mounted("web"); ok {
+		t.Error("Expected a failed mount not to be tracked")
+	}
+	if err := m.Unmount("web"); err == nil {
+		t.Error("Expected unmounting a host that is not mounted to fail")
+	}
+}
+
+func TestMounterCheck(t *testing.T) {
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+	runner := &stubRunner{}
+
+	if err := newMounter(runner.run, missing, "linux").Check(); err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("Expected missing sshfs to be reported, got %v", err)
+	}
+	if err := newMounter(runner.run, foundSSHFS, "windows").Check(); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected windows to be unsupported, got %v", err)
+	}
+	if err := newMounter(runner.run, foundSSHFS, "linux").Mount("web", "", filepath.Join(t.TempDir(), "web"), ""); err != nil {
+		t.Errorf("Mount() error = %v", err)
+	}
+	if err := newMounter(runner.run, missing, "linux").Mount("web", "", t.TempDir(), ""); err == nil {
+		t.Error("Expected Mount to fail without sshfs")
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/xvertile/sshc/internal/shellquote"
+	"github.com/xvertile/sshc/internal/sshver"
 )
 
 // Direction represents the transfer direction
@@ -107,14 +110,17 @@ func (r *TransferRequest) BuildSCPCommand() *exec.Cmd {
 		args = append(args, "-F", r.ConfigFile)
 	}
 
+	// scp runs the sftp subsystem, which ssh refuses next to a RemoteCommand
+	args = append(args, sshver.NoRemoteCommandArgs()...)
+
 	// Build source and destination based on direction
 	var source, dest string
 	if r.Direction == Upload {
-		source = r.LocalPath
-		dest = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
+		source = localSCPArg(r.LocalPath)
+		dest = remoteSCPArg(r.Host, r.RemotePath, false)
 	} else {
-		source = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-		dest = r.LocalPath
+		source = remoteSCPArg(r.Host, r.RemotePath, true)
+		dest = localSCPArg(r.LocalPath)
 	}
 
 	args = append(args, source, dest)
@@ -122,6 +128,75 @@ func (r *TransferRequest) BuildSCPCommand() *exec.Cmd {
 	return exec.Command("scp", args...)
 }
 
+// RemoteCopyRequest copies a file between two remote hosts through the local machine
+type RemoteCopyRequest struct {
+	SourceHost string // SSH host name of the source
+	SourcePath string // Path of the file on the source host
+	DestHost   string // SSH host name of the destination
+	DestPath   string // Destination file or directory on the destination host
+	Recursive  bool   // Copy directories recursively
+	ConfigFile string // Optional SSH config file path, used for both hosts
+}
+
+// BuildSCPCommand builds an "scp -3" command, which routes the data through this machine
+// so the two hosts never need to reach each other
+func (r *RemoteCopyRequest) BuildSCPCommand() *exec.Cmd {
+	args := []string{"-3"}
+
+	if r.Recursive {
+		args = append(args, "-r")
+	}
+	if r.ConfigFile != "" {
+		args = append(args, "-F", r.ConfigFile)
+	}
+	args = append(args, sshver.NoRemoteCommandArgs()...)
+
+	args = append(args,
+		remoteSCPArg(r.SourceHost, r.SourcePath, true),
+		remoteSCPArg(r.DestHost, r.DestPath, false),
+	)
+
+	return exec.Command("scp", args...)
+}
+
+// scpUsesSFTP reports whether scp speaks the SFTP protocol, which takes remote
+// paths literally, rather than the legacy protocol, which hands them to the remote
+// shell. scp comes with the ssh client, whose version tells. A variable so tests
+// can choose.
+var scpUsesSFTP = func() bool {
+	v, err := sshver.Detect()
+	return err == nil && v.SCPUsesSFTP()
+}
+
+// remoteSCPArg builds the host:path argument of scp for a remote path taken
+// literally. Over SFTP a source is matched as a glob, so its glob characters are
+// escaped, and a destination is used as it is. The legacy protocol passes both
+// through the remote shell, so they are quoted for it with ~/ left to expand.
+func remoteSCPArg(host, path string, source bool) string {
+	switch {
+	case path == "":
+		// The remote home directory
+	case !scpUsesSFTP():
+		path = shellquote.Path(path)
+	case source:
+		path = escapeGlob(path)
+	}
+	return host + ":" + path
+}
+
+// localSCPArg passes a local path to scp as it is, except that a relative path
+// scp would take for an option or for host:path gets a leading ./
+func localSCPArg(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	colon := strings.IndexByte(path, ':')
+	if strings.HasPrefix(path, "-") || (colon >= 0 && !strings.Contains(path[:colon], "/")) {
+		return "./" + path
+	}
+	return path
+}
+
 // Execute runs the transfer and returns the result
 func (r *TransferRequest) Execute() *TransferResult {
 	cmd := r.BuildSCPCommand()
@@ -147,31 +222,7 @@ func (r *TransferRequest) Execute() *TransferResult {
 // ExecuteWithProgress runs the transfer with progress callback
 // This uses scp's built-in progress indicator
 func (r *TransferRequest) ExecuteWithProgress() *TransferResult {
-	args := []string{}
-
-	// Add recursive flag if needed
-	if r.Recursive {
-		args = append(args, "-r")
-	}
-
-	// Add config file if specified
-	if r.ConfigFile != "" {
-		args = append(args, "-F", r.ConfigFile)
-	}
-
-	// Build source and destination based on direction
-	var source, dest string
-	if r.Direction == Upload {
-		source = r.LocalPath
-		dest = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-	} else {
-		source = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-		dest = r.LocalPath
-	}
-
-	args = append(args, source, dest)
-
-	cmd := exec.Command("scp", args...)
+	cmd := r.BuildSCPCommand()
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -198,31 +249,7 @@ type RunningTransfer struct {
 
 // StartTransfer starts a transfer and returns a RunningTransfer that can be cancelled
 func (r *TransferRequest) StartTransfer() *RunningTransfer {
-	args := []string{}
-
-	// Add recursive flag if needed
-	if r.Recursive {
-		args = append(args, "-r")
-	}
-
-	// Add config file if specified
-	if r.ConfigFile != "" {
-		args = append(args, "-F", r.ConfigFile)
-	}
-
-	// Build source and destination based on direction
-	var source, dest string
-	if r.Direction == Upload {
-		source = r.LocalPath
-		dest = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-	} else {
-		source = fmt.Sprintf("%s:%s", r.Host, r.RemotePath)
-		dest = r.LocalPath
-	}
-
-	args = append(args, source, dest)
-
-	cmd := exec.Command("scp", args...)
+	cmd := r.BuildSCPCommand()
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
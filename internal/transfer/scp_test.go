@@ -0,0 +1,147 @@
+package transfer
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRemoteCopyBuildSCPCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		req  RemoteCopyRequest
+		want []string
+	}{
+		{
+			name: "file into directory",
+			req:  RemoteCopyRequest{SourceHost: "web", SourcePath: "/var/log/app.log", DestHost: "backup", DestPath: "/srv/logs/"},
+			want: []string{"scp", "-3", "-o", "RemoteCommand=none", "-o", "RequestTTY=no", "web:/var/log/app.log", "backup:/srv/logs/"},
+		},
+		{
+			name: "config file and recursive",
+			req: RemoteCopyRequest{
+				SourceHost: "web", SourcePath: "/etc/nginx", DestHost: "backup", DestPath: "/srv/",
+				Recursive: true, ConfigFile: "/home/me/.ssh/work",
+			},
+			want: []string{"scp", "-3", "-r", "-F", "/home/me/.ssh/work", "-o", "RemoteCommand=none", "-o", "RequestTTY=no", "web:/etc/nginx", "backup:/srv/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.req.BuildSCPCommand()
+			if !slices.Equal(cmd.Args, tt.want) {
+				t.Errorf("BuildSCPCommand() args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSCPCommandOverridesRemoteCommand(t *testing.T) {
+	req := TransferRequest{Host: "web", Direction: Download, RemotePath: "/var/log/app.log", LocalPath: "./app.log", ConfigFile: "/home/me/.ssh/work"}
+	want := []string{"scp", "-F", "/home/me/.ssh/work", "-o", "RemoteCommand=none", "-o", "RequestTTY=no", "web:/var/log/app.log", "./app.log"}
+	if cmd := req.BuildSCPCommand(); !slices.Equal(cmd.Args, want) {
+		t.Errorf("BuildSCPCommand() args = %q, want %q", cmd.Args, want)
+	}
+}
+
+// useSCPProtocol makes BuildSCPCommand quote for the SFTP or the legacy protocol
+func useSCPProtocol(t *testing.T, sftp bool) {
+	t.Helper()
+	saved := scpUsesSFTP
+	scpUsesSFTP = func() bool { return sftp }
+	t.Cleanup(func() { scpUsesSFTP = saved })
+}
+
+// nastyName is a file name the remote shell would split and expand
+const nastyName = "it's a (test) & $HOME; `id` [1]*.txt"
+
+func TestBuildSCPCommandQuotesForRemoteShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	useSCPProtocol(t, false)
+
+	// What the remote shell makes of the path part of host:path
+	remoteShell := func(arg string) string {
+		t.Helper()
+		host, path, _ := strings.Cut(arg, ":")
+		if host != "web" {
+			t.Fatalf("host of %q = %q", arg, host)
+		}
+		dir := t.TempDir()
+		cmd := exec.Command("sh", "-c", "printf %s "+path)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "HOME=/home/web")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("sh failed for %s: %v", path, err)
+		}
+		return string(out)
+	}
+
+	local := "./my file & co " + nastyName
+	download := TransferRequest{Host: "web", Direction: Download, RemotePath: "/srv/" + nastyName, LocalPath: local}
+	args := download.BuildSCPCommand().Args
+	if got := args[len(args)-1]; got != local {
+		t.Errorf("local path = %q, want it passed untouched", got)
+	}
+	if got := remoteShell(args[len(args)-2]); got != "/srv/"+nastyName {
+		t.Errorf("remote shell reads %q, want the file name literally", got)
+	}
+
+	upload := TransferRequest{Host: "web", Direction: Upload, LocalPath: local, RemotePath: "~/uploads/été 2024/"}
+	args = upload.BuildSCPCommand().Args
+	if got := remoteShell(args[len(args)-1]); got != "/home/web/uploads/été 2024/" {
+		t.Errorf("remote shell reads %q, want the home directory expanded", got)
+	}
+
+	copyReq := RemoteCopyRequest{SourceHost: "web", SourcePath: "/srv/" + nastyName, DestHost: "web", DestPath: "/tmp/" + nastyName}
+	args = copyReq.BuildSCPCommand().Args
+	if remoteShell(args[len(args)-2]) != "/srv/"+nastyName || remoteShell(args[len(args)-1]) != "/tmp/"+nastyName {
+		t.Errorf("remote copy args %q are not read literally", args)
+	}
+}
+
+func TestBuildSCPCommandOverSFTP(t *testing.T) {
+	useSCPProtocol(t, true)
+
+	download := TransferRequest{Host: "web", Direction: Download, RemotePath: "/srv/" + nastyName, LocalPath: "./" + nastyName}
+	args := download.BuildSCPCommand().Args
+	// The source is a glob, the destination a plain path
+	want := []string{`web:/srv/it's a (test) & $HOME; ` + "`id`" + ` \[1\]\*.txt`, "./" + nastyName}
+	if got := args[len(args)-2:]; !slices.Equal(got, want) {
+		t.Errorf("download args = %q, want %q", got, want)
+	}
+
+	upload := TransferRequest{Host: "web", Direction: Upload, LocalPath: "/tmp/" + nastyName, RemotePath: "/srv/" + nastyName}
+	args = upload.BuildSCPCommand().Args
+	if want := []string{"/tmp/" + nastyName, "web:/srv/" + nastyName}; !slices.Equal(args[len(args)-2:], want) {
+		t.Errorf("upload args = %q, want %q", args[len(args)-2:], want)
+	}
+
+	home := TransferRequest{Host: "web", Direction: Upload, LocalPath: "/tmp/x"}
+	if args := home.BuildSCPCommand().Args; args[len(args)-1] != "web:" {
+		t.Errorf("upload to the home directory = %q, want web:", args[len(args)-1])
+	}
+}
+
+func TestLocalSCPArg(t *testing.T) {
+	tests := map[string]string{
+		"report.txt":       "report.txt",
+		"my file & co.txt": "my file & co.txt",
+		"-rf":              "./-rf",
+		"a:b.txt":          "./a:b.txt",
+		"dir/a:b.txt":      "dir/a:b.txt",
+		"/tmp/-x:y":        "/tmp/-x:y",
+		"./host:path":      "./host:path",
+		"日本語:ファイル.txt":     "./日本語:ファイル.txt",
+	}
+	for path, want := range tests {
+		if got := localSCPArg(path); got != want {
+			t.Errorf("localSCPArg(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
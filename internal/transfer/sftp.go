@@ -9,6 +9,10 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/shellquote"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
@@ -16,11 +20,14 @@ import (
 
 // RemoteFile represents a file on the remote server
 type RemoteFile struct {
-	Name    string
-	Path    string
-	IsDir   bool
-	Size    int64
-	ModTime string
+	Name       string
+	Path       string
+	IsDir      bool
+	Size       int64
+	ModTime    time.Time
+	Mode       string // Permissions as ls prints them, such as "-rw-r--r--"
+	IsLink     bool
+	LinkTarget string
 }
 
 // SFTPSession manages an SFTP connection for browsing
@@ -101,6 +108,7 @@ func resolveSSHHost(host, configFile string) (hostname, port, user string) {
 	}
 
 	cmd := exec.Command("ssh", args...)
+	sshclog.Exec(cmd.Args)
 	output, err := cmd.Output()
 	if err != nil {
 		return
@@ -152,8 +160,7 @@ func (s *SFTPSession) ListDirectory(path string) ([]RemoteFile, error) {
 	}
 
 	// List directory with details
-	cmd := fmt.Sprintf("ls -la %q 2>/dev/null | tail -n +2", path)
-	output, err := session.Output(cmd)
+	output, err := session.Output(listDirectoryCommand(path))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list directory: %w", err)
 	}
@@ -169,51 +176,18 @@ func (s *SFTPSession) ListDirectory(path string) ([]RemoteFile, error) {
 		})
 	}
 
-	for _, line := range strings.Split(string(output), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Parse ls -la output
-		// drwxr-xr-x  2 user group  4096 Jan  1 12:00 dirname
-		fields := strings.Fields(line)
-		if len(fields) < 9 {
-			continue
-		}
-
-		permissions := fields[0]
-		size := int64(0)
-		fmt.Sscanf(fields[4], "%d", &size)
-		name := strings.Join(fields[8:], " ")
-
-		// Skip . and .. entries from ls output
-		if name == "." || name == ".." {
-			continue
-		}
-
-		isDir := strings.HasPrefix(permissions, "d")
-		isLink := strings.HasPrefix(permissions, "l")
-
-		// Handle symlinks
-		if isLink {
-			// Check if link points to directory
+	for _, file := range ParseListing(string(output), path, time.Now()) {
+		// Check whether links point to directories
+		if file.IsLink {
 			checkSession, err := s.client.NewSession()
 			if err == nil {
-				linkPath := filepath.Join(path, name)
-				checkCmd := fmt.Sprintf("test -d %q && echo dir", linkPath)
+				checkCmd := fmt.Sprintf("test -d %s && echo dir", shellquote.Quote(file.Path))
 				checkOutput, _ := checkSession.Output(checkCmd)
 				checkSession.Close()
-				isDir = strings.TrimSpace(string(checkOutput)) == "dir"
+				file.IsDir = strings.TrimSpace(string(checkOutput)) == "dir"
 			}
 		}
-
-		files = append(files, RemoteFile{
-			Name:  name,
-			Path:  filepath.Join(path, name),
-			IsDir: isDir,
-			Size:  size,
-		})
+		files = append(files, file)
 	}
 
 	// Sort: directories first, then by name
@@ -266,7 +240,7 @@ func (s *SFTPSession) ReadFile(path string, w io.Writer) error {
 	defer session.Close()
 
 	session.Stdout = w
-	return session.Run(fmt.Sprintf("cat %q", path))
+	return session.Run("cat " + shellquote.Quote(path))
 }
 
 // Stat returns file info for a remote path
@@ -277,7 +251,7 @@ func (s *SFTPSession) Stat(path string) (*RemoteFile, error) {
 	}
 	defer session.Close()
 
-	cmd := fmt.Sprintf("ls -ld %q 2>/dev/null", path)
+	cmd := fmt.Sprintf("ls -ld %s 2>/dev/null", shellquote.Quote(path))
 	output, err := session.Output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("path does not exist: %s", path)
@@ -352,10 +326,10 @@ func (s *SFTPSession) Search(pattern, startDir string, limit int) ([]RemoteFile,
 
 	if hasFd {
 		// fd is super fast and has nice defaults
-		cmd = fmt.Sprintf("fd -H -I --max-results %d %q %q 2>/dev/null", limit, pattern, startDir)
+		cmd = fmt.Sprintf("fd -H -I --max-results %d %s %s 2>/dev/null", limit, shellquote.Quote(pattern), shellquote.Quote(startDir))
 	} else {
 		// Fall back to find with iname for case-insensitive matching
-		cmd = fmt.Sprintf("find %q -iname '*%s*' 2>/dev/null | head -n %d", startDir, pattern, limit)
+		cmd = fmt.Sprintf("find %s -iname %s 2>/dev/null | head -n %d", shellquote.Quote(startDir), shellquote.Quote(findNamePattern(pattern)), limit)
 	}
 
 	output, err := session.Output(cmd)
@@ -377,7 +351,7 @@ func (s *SFTPSession) Search(pattern, startDir string, limit int) ([]RemoteFile,
 			continue
 		}
 
-		infoCmd := fmt.Sprintf("ls -ld %q 2>/dev/null", line)
+		infoCmd := fmt.Sprintf("ls -ld %s 2>/dev/null", shellquote.Quote(line))
 		infoOutput, err := infoSession.Output(infoCmd)
 		infoSession.Close()
 
@@ -407,11 +381,49 @@ func (s *SFTPSession) Search(pattern, startDir string, limit int) ([]RemoteFile,
 	return files, nil
 }
 
+// SearchMaxDepth is how many directory levels below the start directory QuickSearch looks
+const SearchMaxDepth = 4
+
+// SearchLimit is the default cap on the number of QuickSearch results
+const SearchLimit = 200
+
+// findNamePattern builds the -iname pattern matching names containing query,
+// with glob characters in the query matched literally
+func findNamePattern(query string) string {
+	return "*" + escapeGlob(query) + "*"
+}
+
+// escapeGlob escapes the glob characters of s with backslashes, so a glob
+// matches it literally
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// buildSearchCommand builds the remote find command listing up to limit matches of
+// query below startDir as "d path" or "f path" lines. The portable form avoids
+// GNU-only timeout and -printf for BSD/macOS hosts.
+func buildSearchCommand(startDir, query string, limit int, portable bool) string {
+	find := fmt.Sprintf("find %s -maxdepth %d -iname %s",
+		shellquote.Quote(startDir), SearchMaxDepth, shellquote.Quote(findNamePattern(query)))
+	if portable {
+		return fmt.Sprintf(`%s 2>/dev/null | head -n %d | while IFS= read -r f; do if [ -d "$f" ]; then echo "d $f"; else echo "f $f"; fi; done`, find, limit)
+	}
+	// timeout 3s kills slow searches on large trees
+	return fmt.Sprintf(`timeout 3s %s -printf '%%y %%p\n' 2>/dev/null | head -n %d`, find, limit)
+}
+
 // QuickSearch does a faster search without fetching file details
 // Uses timeout and depth limit to avoid slow searches
 func (s *SFTPSession) QuickSearch(pattern, startDir string, limit int) ([]RemoteFile, error) {
 	if limit <= 0 {
-		limit = 30
+		limit = SearchLimit
 	}
 
 	session, err := s.client.NewSession()
@@ -433,27 +445,14 @@ func (s *SFTPSession) QuickSearch(pattern, startDir string, limit int) ([]Remote
 		}
 	}
 
-	// Sanitize pattern to prevent command injection
-	pattern = strings.ReplaceAll(pattern, "'", "")
-	pattern = strings.ReplaceAll(pattern, "\"", "")
-	pattern = strings.ReplaceAll(pattern, ";", "")
-	pattern = strings.ReplaceAll(pattern, "|", "")
-	pattern = strings.ReplaceAll(pattern, "&", "")
-	pattern = strings.ReplaceAll(pattern, "$", "")
-	pattern = strings.ReplaceAll(pattern, "`", "")
-
-	// Use find with depth limit and timeout for faster results
-	// -maxdepth 5 limits how deep we search
-	// timeout 3s kills the search after 3 seconds
-	cmd := fmt.Sprintf("timeout 3s find %q -maxdepth 5 -iname '*%s*' -printf '%%y %%p\\n' 2>/dev/null | head -n %d", startDir, pattern, limit)
-
-	output, err := session.Output(cmd)
+	output, err := session.Output(buildSearchCommand(startDir, pattern, limit, false))
 	if err != nil {
 		// Try simpler find without -printf and timeout (BSD/macOS compatibility)
-		session2, _ := s.client.NewSession()
-		// macOS uses gtimeout (from coreutils) or we skip timeout
-		cmd = fmt.Sprintf("find %q -maxdepth 5 -iname '*%s*' 2>/dev/null | head -n %d | while read f; do if [ -d \"$f\" ]; then echo \"d $f\"; else echo \"f $f\"; fi; done", startDir, pattern, limit)
-		output, err = session2.Output(cmd)
+		session2, err := s.client.NewSession()
+		if err != nil {
+			return []RemoteFile{}, nil
+		}
+		output, err = session2.Output(buildSearchCommand(startDir, pattern, limit, true))
 		session2.Close()
 		if err != nil {
 			return []RemoteFile{}, nil
@@ -0,0 +1,278 @@
+package transfer
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var nastyInputs = []string{
+	"'; rm -rf /tmp/x; echo '",
+	"$(touch pwned)",
+	"`touch pwned`",
+	"a\"b",
+	"back\\slash",
+	"new\nline",
+	"semi;colon | pipe & amp",
+	"*",
+	"[ab]?",
+}
+
+func TestListDirectoryCommandQuotesPath(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	for _, input := range nastyInputs {
+		root := t.TempDir()
+		dir := filepath.Join(root, input)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "marker"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command("sh", "-c", listDirectoryCommand(dir))
+		cmd.Dir = root
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", input, err)
+		}
+		if !strings.Contains(string(out), "marker") {
+			t.Errorf("listing %q = %q, want the directory's contents", input, out)
+		}
+		if _, err := os.Stat(filepath.Join(root, "pwned")); err == nil {
+			t.Errorf("listing %q ran a command", input)
+		}
+	}
+}
+
+func TestBuildSearchCommandMatchesLiterally(t *testing.T) {
+	if _, err := exec.LookPath("find"); err != nil {
+		t.Skip("find not available")
+	}
+
+	root := t.TempDir()
+	// The start directory itself has a quote in its name
+	dir := filepath.Join(root, "it's here")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"report-2024.txt", "star*name", "plain", "sub/deep-report.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := func(query string) []string {
+		cmd := exec.Command("sh", "-c", buildSearchCommand(dir, query, SearchLimit, true))
+		cmd.Dir = root
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("search %q failed: %v", query, err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "pwned")); err == nil {
+			t.Fatalf("search %q ran a command", query)
+		}
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if line != "" {
+				lines = append(lines, strings.TrimPrefix(line, "f "+dir+"/"))
+			}
+		}
+		return lines
+	}
+
+	if got := search("REPORT"); len(got) != 2 {
+		t.Errorf("search REPORT = %q, want both reports", got)
+	}
+	if got := search("*"); len(got) != 1 || got[0] != "star*name" {
+		t.Errorf("search * = %q, want only star*name", got)
+	}
+	for _, query := range nastyInputs {
+		if query == "*" {
+			continue
+		}
+		if got := search(query); len(got) != 0 {
+			t.Errorf("search %q = %q, want no matches", query, got)
+		}
+	}
+}
+
+func TestBuildSearchCommandLimits(t *testing.T) {
+	cmd := buildSearchCommand("/srv", "log", 200, false)
+	for _, want := range []string{"-maxdepth 4", "head -n 200", "-iname '*log*'", "find '/srv'"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command %q does not contain %q", cmd, want)
+		}
+	}
+}
+
+// startExecServer runs an sshd stand-in that accepts any key, records the
+// commands it is asked to run and answers ls with a listing of /srv
+func startExecServer(t *testing.T) (port string, commands func() []string) {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	var seen []string
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChannel := range chans {
+					channel, requests, err := newChannel.Accept()
+					if err != nil {
+						continue
+					}
+					go func() {
+						defer channel.Close()
+						for req := range requests {
+							if req.Type != "exec" {
+								req.Reply(false, nil)
+								continue
+							}
+							var payload struct{ Command string }
+							ssh.Unmarshal(req.Payload, &payload)
+							mu.Lock()
+							seen = append(seen, payload.Command)
+							mu.Unlock()
+							req.Reply(true, nil)
+
+							status := uint32(0)
+							if strings.Contains(payload.Command, "ls -la") {
+								io.WriteString(channel, "drwxr-xr-x 3 deploy deploy 4096 Jan  1 12:00 .\n"+
+									"drwxr-xr-x 9 root root 4096 Jan  1 12:00 ..\n"+
+									"-rw-r--r-- 1 deploy deploy 1234 Jan  1 12:00 app.log\n"+
+									"drwxr-xr-x 2 deploy deploy 4096 Jan  1 12:00 releases\n")
+							} else {
+								status = 127
+							}
+							channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+							return
+						}
+					}()
+				}
+			}()
+		}
+	}()
+
+	_, port, _ = net.SplitHostPort(listener.Addr().String())
+	return port, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(seen)
+	}
+}
+
+// startAgent serves an agent holding a new key and points SSH_AUTH_SOCK at it
+func startAgent(t *testing.T) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+}
+
+func TestListDirectoryIgnoresRemoteCommand(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh not available to resolve the host")
+	}
+	port, commands := startExecServer(t)
+	startAgent(t)
+
+	// A host that starts htop on login must still be browsable
+	configFile := filepath.Join(t.TempDir(), "config")
+	fixture := "Host box\n" +
+		"  HostName 127.0.0.1\n" +
+		"  Port " + port + "\n" +
+		"  User deploy\n" +
+		"  RemoteCommand htop\n" +
+		"  RequestTTY force\n"
+	if err := os.WriteFile(configFile, []byte(fixture), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := NewSFTPSession("box", configFile)
+	if err != nil {
+		t.Fatalf("NewSFTPSession() error = %v", err)
+	}
+	defer session.Close()
+
+	files, err := session.ListDirectory("/srv")
+	if err != nil {
+		t.Fatalf("ListDirectory() error = %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	if want := []string{"..", "releases", "app.log"}; !slices.Equal(names, want) {
+		t.Errorf("ListDirectory() = %q, want %q", names, want)
+	}
+	for _, command := range commands() {
+		if command != listDirectoryCommand("/srv") {
+			t.Errorf("Expected only the listing to run, the server was asked for %q", command)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/xvertile/sshc/internal/sshver"
 )
 
 // SSHFSMount represents a mounted SSHFS filesystem
@@ -66,7 +68,9 @@ func (m *SSHFSMount) Mount() error {
 
 	// Add SSH config file if specified
 	if m.ConfigFile != "" {
-		args = append(args, "-o", fmt.Sprintf("ssh_command=ssh -F %s", m.ConfigFile))
+		args = append(args, "-o", fmt.Sprintf("ssh_command=ssh -F %s %s", m.ConfigFile, strings.Join(sshver.NoRemoteCommandArgs(), " ")))
+	} else {
+		args = append(args, "-o", "ssh_command=ssh "+strings.Join(sshver.NoRemoteCommandArgs(), " "))
 	}
 
 	// Add useful options
@@ -109,7 +113,7 @@ func (m *SSHFSMount) Unmount() error {
 		// On macOS, use diskutil or umount
 		cmd = exec.Command("diskutil", "unmount", "force", m.MountPoint)
 	case "linux":
-		cmd = exec.Command("fusermount", "-u", m.MountPoint)
+		cmd = exec.Command(fusermount(exec.LookPath), "-u", m.MountPoint)
 	default:
 		cmd = exec.Command("umount", m.MountPoint)
 	}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
@@ -23,8 +25,24 @@ type addFormModel struct {
 	width      int
 	height     int
 	configFile string
+
+	// Name derived from the Hostname field, shown while Name is empty
+	nameSuggestion string
+	suggestedFor   string // Hostname the suggestion was derived from
+
+	uriErr string // Error shown for an ssh:// URI in the Hostname field
+
+	// Search for a bastion that reaches the hostname (Ctrl+B)
+	routeCancel  context.CancelFunc
+	routeSeq     int
+	routeFor     string // Hostname the latest search was started for
+	routeBastion string // Bastion found for routeFor, until accepted
+	routeStatus  string
 }
 
+// defaultNamePlaceholder is shown in the Name field when there is no suggestion
+const defaultNamePlaceholder = "my-server"
+
 const (
 	addNameInput = iota
 	addHostnameInput
@@ -32,13 +50,16 @@ const (
 	addPortInput
 	addIdentityInput
 	addProxyJumpInput
+	addDescriptionInput
 	addTagsInput
+	addExpiresInput
 )
 
 // Messages for communication with parent model
 type addFormSubmitMsg struct {
 	hostname string
 	err      error
+	form     *addFormModel // Form the host was typed in, shown again if saving fails after it closed
 }
 
 type addFormCancelMsg struct{}
@@ -66,11 +87,11 @@ func NewAddForm(hostname string, styles Styles, width, height int, configFile st
 		}
 	}
 
-	inputs := make([]textinput.Model, 7)
+	inputs := make([]textinput.Model, 9)
 
 	// Name input
 	inputs[addNameInput] = textinput.New()
-	inputs[addNameInput].Placeholder = "my-server"
+	inputs[addNameInput].Placeholder = defaultNamePlaceholder
 	inputs[addNameInput].Focus()
 	inputs[addNameInput].CharLimit = 50
 	inputs[addNameInput].Width = 40
@@ -112,12 +133,24 @@ func NewAddForm(hostname string, styles Styles, width, height int, configFile st
 	inputs[addProxyJumpInput].CharLimit = 200
 	inputs[addProxyJumpInput].Width = 40
 
+	// Description input
+	inputs[addDescriptionInput] = textinput.New()
+	inputs[addDescriptionInput].Placeholder = "primary LB, eu-west"
+	inputs[addDescriptionInput].CharLimit = 200
+	inputs[addDescriptionInput].Width = 40
+
 	// Tags input
 	inputs[addTagsInput] = textinput.New()
 	inputs[addTagsInput].Placeholder = "web, production"
 	inputs[addTagsInput].CharLimit = 200
 	inputs[addTagsInput].Width = 40
 
+	// Expires input
+	inputs[addExpiresInput] = textinput.New()
+	inputs[addExpiresInput].Placeholder = "YYYY-MM-DD (optional)"
+	inputs[addExpiresInput].CharLimit = 10
+	inputs[addExpiresInput].Width = 40
+
 	return &addFormModel{
 		inputs:     inputs,
 		focused:    addNameInput,
@@ -145,14 +178,28 @@ func (m *addFormModel) Update(msg tea.Msg) (*addFormModel, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "esc":
+			m.cancelRouteSearch()
 			return m, func() tea.Msg { return addFormCancelMsg{} }
 
+		case "ctrl+b":
+			m.leaveHostnameField()
+			if m.acceptRouteSuggestion() {
+				return m, nil
+			}
+			return m, m.startRouteSearch()
+
 		case "ctrl+s":
+			if err := m.splitHostnameURI(); err != nil {
+				m.err = err.Error()
+				m.uriErr = m.err
+				return m, nil
+			}
 			return m, m.submitForm()
 
 		case "tab", "down", "enter":
+			m.leaveHostnameField()
 			// Move to next field
-			if msg.String() == "enter" && m.focused == addTagsInput {
+			if msg.String() == "enter" && m.focused == addExpiresInput {
 				// Submit on enter at last field
 				return m, m.submitForm()
 			}
@@ -162,7 +209,16 @@ func (m *addFormModel) Update(msg tea.Msg) (*addFormModel, tea.Cmd) {
 			}
 			return m, m.updateFocus()
 
+		case "right":
+			// Take the suggested name, from the Name field or the end of the Hostname field
+			hostnameInput := m.inputs[addHostnameInput]
+			atHostnameEnd := m.focused == addHostnameInput && hostnameInput.Position() == len([]rune(hostnameInput.Value()))
+			if (m.focused == addNameInput || atHostnameEnd) && m.acceptNameSuggestion() {
+				return m, nil
+			}
+
 		case "shift+tab", "up":
+			m.leaveHostnameField()
 			// Move to previous field
 			m.focused--
 			if m.focused < 0 {
@@ -173,18 +229,32 @@ func (m *addFormModel) Update(msg tea.Msg) (*addFormModel, tea.Cmd) {
 
 	case addFormSubmitMsg:
 		if msg.err != nil {
-			m.err = msg.err.Error()
+			m.err = saveErrorText(msg.err)
 		} else {
 			m.success = true
 			m.err = ""
 		}
 		return m, nil
+
+	case nameSuggestionMsg:
+		m.applyNameSuggestion(msg)
+		return m, nil
+
+	case routeSuggestionMsg:
+		m.applyRouteSuggestion(msg)
+		return m, nil
 	}
 
 	// Update focused input
 	var cmd tea.Cmd
 	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
 	cmds = append(cmds, cmd)
+	if m.focused == addHostnameInput {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && (keyMsg.Paste || len(keyMsg.Runes) > 1) {
+			m.leaveHostnameField()
+		}
+		cmds = append(cmds, m.refreshNameSuggestion())
+	}
 
 	return m, tea.Batch(cmds...)
 }
@@ -226,7 +296,9 @@ func (m *addFormModel) View() string {
 		{addPortInput, "Port", false},
 		{addIdentityInput, "Identity File", false},
 		{addProxyJumpInput, "ProxyJump", false},
+		{addDescriptionInput, "Description", false},
 		{addTagsInput, "Tags", false},
+		{addExpiresInput, "Expires", false},
 	}
 
 	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Width(14)
@@ -252,6 +324,20 @@ func (m *addFormModel) View() string {
 
 		// Input
 		b.WriteString(m.inputs[field.index].View())
+		if field.index == addNameInput && m.nameSuggestionShown() {
+			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Render("  → to use"))
+		}
+		b.WriteString("\n")
+		if field.index == addProxyJumpInput {
+			if matches := renderHostMatches(m.inputs[field.index]); matches != "" {
+				b.WriteString(strings.Repeat(" ", 17) + matches + "\n")
+			}
+		}
+	}
+
+	if m.routeStatus != "" {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Render(m.routeStatus))
 		b.WriteString("\n")
 	}
 
@@ -265,7 +351,7 @@ func (m *addFormModel) View() string {
 	// Help
 	b.WriteString("\n\n")
 	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
-	b.WriteString(helpStyle.Render("↑/↓: navigate • Enter: next/submit • Ctrl+S: save • Esc: cancel"))
+	b.WriteString(helpStyle.Render(hintLine(m.hintState(), nil)))
 
 	content := b.String()
 
@@ -290,61 +376,114 @@ func (m *addFormModel) View() string {
 	)
 }
 
+// splitHostnameURI spreads an ssh:// URI in the Hostname field over the Hostname,
+// User and Port fields. Parts the URI leaves out keep their field's value.
+func (m *addFormModel) splitHostnameURI() error {
+	value := strings.TrimSpace(m.inputs[addHostnameInput].Value())
+	if !validation.IsURI(value) {
+		return nil
+	}
+	uri, err := validation.ParseSSHURI(value)
+	if err != nil {
+		return err
+	}
+
+	m.inputs[addHostnameInput].SetValue(uri.Hostname)
+	if uri.User != "" {
+		m.inputs[addUserInput].SetValue(uri.User)
+	}
+	if uri.Port != "" {
+		m.inputs[addPortInput].SetValue(uri.Port)
+	}
+	return nil
+}
+
+// leaveHostnameField splits a URI in the Hostname field once it is pasted or the
+// field is left, so typing one is not split halfway
+func (m *addFormModel) leaveHostnameField() {
+	if m.focused != addHostnameInput {
+		return
+	}
+	if err := m.splitHostnameURI(); err != nil {
+		m.err = err.Error()
+		m.uriErr = m.err
+	} else if m.err != "" && m.err == m.uriErr {
+		m.err = ""
+	}
+}
+
 func (m *addFormModel) submitForm() tea.Cmd {
 	return func() tea.Msg {
-		// Get values
-		name := strings.TrimSpace(m.inputs[addNameInput].Value())
-		hostname := strings.TrimSpace(m.inputs[addHostnameInput].Value())
-		user := strings.TrimSpace(m.inputs[addUserInput].Value())
-		port := strings.TrimSpace(m.inputs[addPortInput].Value())
-		identity := strings.TrimSpace(m.inputs[addIdentityInput].Value())
-		proxyJump := strings.TrimSpace(m.inputs[addProxyJumpInput].Value())
-
-		// Set defaults
-		if user == "" {
-			user = m.inputs[addUserInput].Placeholder
-		}
-		if port == "" {
-			port = "22"
-		}
+		msg := m.saveHost()
+		msg.form = m
+		return msg
+	}
+}
 
-		// Validate required fields
-		if err := validation.ValidateHost(name, hostname, port, identity); err != nil {
-			return addFormSubmitMsg{err: err}
-		}
+// saveHost validates the form and adds its host to the config
+func (m *addFormModel) saveHost() addFormSubmitMsg {
+	// Get values
+	name := strings.TrimSpace(m.inputs[addNameInput].Value())
+	hostname := strings.TrimSpace(m.inputs[addHostnameInput].Value())
+	user := strings.TrimSpace(m.inputs[addUserInput].Value())
+	port := strings.TrimSpace(m.inputs[addPortInput].Value())
+	identity := strings.TrimSpace(m.inputs[addIdentityInput].Value())
+	proxyJump := strings.TrimSpace(m.inputs[addProxyJumpInput].Value())
+	description := config.SanitizeDescription(m.inputs[addDescriptionInput].Value())
+	expires := strings.TrimSpace(m.inputs[addExpiresInput].Value())
+
+	// Set defaults
+	if user == "" {
+		user = m.inputs[addUserInput].Placeholder
+	}
+	if port == "" {
+		port = "22"
+	}
+
+	// Validate required fields
+	if err := validation.ValidateHost(name, hostname, port, identity); err != nil {
+		return addFormSubmitMsg{err: err}
+	}
+	if err := validation.ValidateNewHostName(name); err != nil {
+		return addFormSubmitMsg{err: err}
+	}
+	if !validation.ValidateExpiryDate(expires) {
+		return addFormSubmitMsg{err: fmt.Errorf("invalid expiry date %q: use YYYY-MM-DD", expires)}
+	}
 
-		// Parse tags
-		tagsStr := strings.TrimSpace(m.inputs[addTagsInput].Value())
-		var tags []string
-		if tagsStr != "" {
-			for _, tag := range strings.Split(tagsStr, ",") {
-				tag = strings.TrimSpace(tag)
-				if tag != "" {
-					tags = append(tags, tag)
-				}
+	// Parse tags
+	tagsStr := strings.TrimSpace(m.inputs[addTagsInput].Value())
+	var tags []string
+	if tagsStr != "" {
+		for _, tag := range strings.Split(tagsStr, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag != "" {
+				tags = append(tags, tag)
 			}
 		}
+	}
 
-		// Create host configuration
-		host := config.SSHHost{
-			Name:      name,
-			Hostname:  hostname,
-			User:      user,
-			Port:      port,
-			Identity:  identity,
-			ProxyJump: proxyJump,
-			Tags:      tags,
-		}
+	// Create host configuration
+	host := config.SSHHost{
+		Name:        name,
+		Hostname:    hostname,
+		User:        user,
+		Port:        port,
+		Identity:    identity,
+		ProxyJump:   proxyJump,
+		Description: description,
+		Tags:        tags,
+		Expires:     expires,
+	}
 
-		// Add to config
-		var err error
-		if m.configFile != "" {
-			err = config.AddSSHHostToFile(host, m.configFile)
-		} else {
-			err = config.AddSSHHost(host)
-		}
-		return addFormSubmitMsg{hostname: name, err: err}
+	// Add to config
+	var err error
+	if m.configFile != "" {
+		err = config.AddSSHHostToFile(host, m.configFile)
+	} else {
+		err = config.AddSSHHost(host)
 	}
+	return addFormSubmitMsg{hostname: name, err: err}
 }
 
 // Standalone wrapper for add form
@@ -356,9 +495,10 @@ func (m standaloneAddForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case addFormSubmitMsg:
 		if msg.err != nil {
-			m.addFormModel.err = msg.err.Error()
+			m.addFormModel.err = saveErrorText(msg.err)
 		} else {
 			m.addFormModel.success = true
+			m.addFormModel.cancelRouteSearch()
 			return m, tea.Quit
 		}
 		return m, nil
@@ -373,11 +513,40 @@ func (m standaloneAddForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // RunAddForm provides backward compatibility for standalone add form
 func RunAddForm(hostname string, configFile string) error {
+	_, err := RunAddFormForHost(config.SSHHost{Name: hostname}, configFile)
+	return err
+}
+
+// RunAddFormForHost runs the standalone add form pre-filled with the name, hostname,
+// user and port of host. It returns the name of the saved host, or "" if cancelled.
+func RunAddFormForHost(host config.SSHHost, configFile string) (string, error) {
 	styles := NewStyles(80)
-	addForm := NewAddForm(hostname, styles, 80, 24, configFile)
+	addForm := NewAddForm(host.Name, styles, 80, 24, configFile)
+	addForm.prefill(host)
 	m := standaloneAddForm{addForm}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err := p.Run()
-	return err
+	final, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+	form := final.(standaloneAddForm).addFormModel
+	if !form.success {
+		return "", nil
+	}
+	return strings.TrimSpace(form.inputs[addNameInput].Value()), nil
+}
+
+// prefill sets the connection fields that are known for a new host
+func (m *addFormModel) prefill(host config.SSHHost) {
+	if host.Hostname != "" {
+		m.inputs[addHostnameInput].SetValue(host.Hostname)
+		m.refreshNameSuggestion()
+	}
+	if host.User != "" {
+		m.inputs[addUserInput].SetValue(host.User)
+	}
+	if host.Port != "" {
+		m.inputs[addPortInput].SetValue(host.Port)
+	}
 }
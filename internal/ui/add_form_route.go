@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Route search hooks, replaced in tests
+var (
+	findRoute     = connectivity.FindRoute
+	newRouteProbe = connectivity.SSHRouteProbe
+)
+
+// routeSuggestionMsg carries the outcome of a route search of the add form
+type routeSuggestionMsg struct {
+	seq      int
+	hostname string
+	bastion  string // Bastion that reached the host, "" for direct
+	bastions int    // How many bastions were tried
+	err      error
+}
+
+// bastionHosts returns the names of the hosts tagged as bastions, in config order
+func bastionHosts(hosts []config.SSHHost) []string {
+	var names []string
+	for _, host := range hosts {
+		for _, tag := range host.Tags {
+			if strings.EqualFold(tag, connectivity.BastionTag) {
+				names = append(names, host.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// startRouteSearch probes the typed hostname directly and through every bastion,
+// canceling a search still running for an earlier hostname
+func (m *addFormModel) startRouteSearch() tea.Cmd {
+	m.cancelRouteSearch()
+	hostname := strings.TrimSpace(m.inputs[addHostnameInput].Value())
+	if hostname == "" {
+		m.routeStatus = "Enter a hostname to find a route to"
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.routeCancel = cancel
+	m.routeSeq++
+	m.routeFor = hostname
+	m.routeBastion = ""
+	m.routeStatus = "Looking for a route to " + hostname + "..."
+
+	seq, configFile := m.routeSeq, m.configFile
+	target := connectivity.RouteTarget{
+		Hostname: hostname,
+		User:     strings.TrimSpace(m.inputs[addUserInput].Value()),
+		Port:     strings.TrimSpace(m.inputs[addPortInput].Value()),
+	}
+	return func() tea.Msg {
+		var hosts []config.SSHHost
+		var err error
+		if configFile != "" {
+			hosts, err = config.ParseSSHConfigFile(configFile)
+		} else {
+			hosts, err = config.ParseSSHConfig()
+		}
+		if err != nil {
+			return routeSuggestionMsg{seq: seq, hostname: hostname, err: err}
+		}
+		bastions := bastionHosts(hosts)
+		bastion, err := findRoute(ctx, bastions, newRouteProbe(target, configFile), connectivity.DefaultRouteConcurrency)
+		return routeSuggestionMsg{seq: seq, hostname: hostname, bastion: bastion, bastions: len(bastions), err: err}
+	}
+}
+
+// cancelRouteSearch stops a running route search, if any
+func (m *addFormModel) cancelRouteSearch() {
+	if m.routeCancel != nil {
+		m.routeCancel()
+		m.routeCancel = nil
+	}
+}
+
+// applyRouteSuggestion takes the result of the latest route search
+func (m *addFormModel) applyRouteSuggestion(msg routeSuggestionMsg) {
+	if msg.seq != m.routeSeq {
+		return
+	}
+	m.cancelRouteSearch()
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.routeStatus = ""
+	case errors.Is(msg.err, connectivity.ErrNoRoute) && msg.bastions == 0:
+		m.routeStatus = fmt.Sprintf("%s is not reachable directly, and no host is tagged %q", msg.hostname, connectivity.BastionTag)
+	case msg.err != nil:
+		m.routeStatus = fmt.Sprintf("No route to %s: %v", msg.hostname, msg.err)
+	case msg.bastion == "":
+		m.routeStatus = msg.hostname + " is reachable directly, no ProxyJump needed"
+	default:
+		m.routeBastion = msg.bastion
+		m.routeStatus = fmt.Sprintf("%s is reachable through %s • Ctrl+B: use it as ProxyJump", msg.hostname, msg.bastion)
+	}
+}
+
+// acceptRouteSuggestion puts the suggested bastion in the ProxyJump field when it
+// was found for the current hostname, and reports whether it did
+func (m *addFormModel) acceptRouteSuggestion() bool {
+	if m.routeBastion == "" || m.routeFor != strings.TrimSpace(m.inputs[addHostnameInput].Value()) {
+		return false
+	}
+	m.inputs[addProxyJumpInput].SetValue(m.routeBastion)
+	m.routeBastion = ""
+	m.routeStatus = ""
+	return true
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// nameSuggestionDelay lets typing settle before a reverse lookup is started
+const nameSuggestionDelay = 300 * time.Millisecond
+
+// reverseLookupTimeout bounds the reverse DNS lookup of a typed IP
+const reverseLookupTimeout = time.Second
+
+// lookupAddr resolves an IP to its names; tests replace it
+var lookupAddr = net.DefaultResolver.LookupAddr
+
+// nameSuggestionMsg carries the reverse DNS name suggested for an IP
+type nameSuggestionMsg struct {
+	hostname string // The Hostname field value the lookup was started for
+	name     string
+}
+
+// suggestHostName derives a host name from a hostname: the domain is dropped and
+// what remains is lowercased with dots replaced by dashes, so db.eu.example.com
+// becomes db-eu. IPs become ip-1-2-3-4.
+func suggestHostName(hostname string) string {
+	hostname = strings.TrimSuffix(strings.TrimSpace(hostname), ".")
+	if hostname == "" {
+		return ""
+	}
+	if ip := net.ParseIP(hostname); ip != nil {
+		return ipHostName(ip)
+	}
+
+	labels := strings.Split(strings.ToLower(hostname), ".")
+	switch {
+	case len(labels) > 2:
+		labels = labels[:len(labels)-2]
+	case len(labels) == 2:
+		labels = labels[:1]
+	}
+	return strings.Join(labels, "-")
+}
+
+// ipHostName names a host after its address
+func ipHostName(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return "ip-" + strings.ReplaceAll(v4.String(), ".", "-")
+	}
+	return "ip-" + strings.Trim(strings.ReplaceAll(ip.String(), ":", "-"), "-")
+}
+
+// reverseLookupCmd looks up the name of ip once typing has settled. An IP without
+// a name, or a lookup that times out, yields no message.
+func reverseLookupCmd(ip string) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(nameSuggestionDelay)
+		ctx, cancel := context.WithTimeout(context.Background(), reverseLookupTimeout)
+		defer cancel()
+		names, err := lookupAddr(ctx, ip)
+		if err != nil || len(names) == 0 {
+			return nil
+		}
+		return nameSuggestionMsg{hostname: ip, name: suggestHostName(names[0])}
+	}
+}
+
+// refreshNameSuggestion updates the suggested name after the Hostname field
+// changed, and starts a reverse lookup when it holds an IP
+func (m *addFormModel) refreshNameSuggestion() tea.Cmd {
+	hostname := strings.TrimSpace(m.inputs[addHostnameInput].Value())
+	if hostname == m.suggestedFor {
+		return nil
+	}
+	m.suggestedFor = hostname
+	m.setNameSuggestion(suggestHostName(hostname))
+	if net.ParseIP(hostname) != nil && m.inputs[addNameInput].Value() == "" {
+		return reverseLookupCmd(hostname)
+	}
+	return nil
+}
+
+// applyNameSuggestion takes a reverse lookup result, unless the Hostname field
+// changed since it was started
+func (m *addFormModel) applyNameSuggestion(msg nameSuggestionMsg) {
+	if msg.hostname != strings.TrimSpace(m.inputs[addHostnameInput].Value()) || msg.name == "" {
+		return
+	}
+	m.setNameSuggestion(msg.name)
+}
+
+// setNameSuggestion shows name as ghost text in the Name field
+func (m *addFormModel) setNameSuggestion(name string) {
+	m.nameSuggestion = name
+	if name == "" {
+		m.inputs[addNameInput].Placeholder = defaultNamePlaceholder
+		return
+	}
+	m.inputs[addNameInput].Placeholder = name
+}
+
+// acceptNameSuggestion fills the Name field with the suggestion when it is still
+// empty, and reports whether it did
+func (m *addFormModel) acceptNameSuggestion() bool {
+	if m.nameSuggestion == "" || m.inputs[addNameInput].Value() != "" {
+		return false
+	}
+	m.inputs[addNameInput].SetValue(m.nameSuggestion)
+	m.setNameSuggestion("")
+	return true
+}
+
+// nameSuggestionShown reports whether the Name field shows a suggestion
+func (m *addFormModel) nameSuggestionShown() bool {
+	return m.nameSuggestion != "" && m.inputs[addNameInput].Value() == ""
+}
@@ -0,0 +1,315 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSuggestHostName(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"", ""},
+		{"web01", "web01"},
+		{"Example.com", "example"},
+		{"web01.example.com", "web01"},
+		{"db.eu.example.com.", "db-eu"},
+		{"10.0.0.12", "ip-10-0-0-12"},
+		{"2001:db8::1", "ip-2001-db8--1"},
+	}
+	for _, tt := range tests {
+		if got := suggestHostName(tt.hostname); got != tt.want {
+			t.Errorf("suggestHostName(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
+
+// typeAddFormField types text into the focused field of the add form
+func typeAddFormField(m *addFormModel, text string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, r := range text {
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return cmd
+}
+
+func newTestAddForm(t *testing.T) *addFormModel {
+	t.Helper()
+	m := NewAddForm("", NewStyles(120), 120, 60, "")
+	m.focused = addHostnameInput
+	m.updateFocus()
+	return m
+}
+
+func TestAddFormAcceptsSuggestedName(t *testing.T) {
+	m := newTestAddForm(t)
+	typeAddFormField(m, "web01.example.com")
+
+	if got := m.inputs[addNameInput].Placeholder; got != "web01" {
+		t.Fatalf("Name placeholder = %q, want web01", got)
+	}
+	if m.inputs[addNameInput].Value() != "" {
+		t.Fatal("Expected the suggestion not to fill the Name field by itself")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := m.inputs[addNameInput].Value(); got != "web01" {
+		t.Errorf("Name after → = %q, want web01", got)
+	}
+}
+
+func TestAddFormKeepsTypedName(t *testing.T) {
+	m := newTestAddForm(t)
+	m.inputs[addNameInput].SetValue("mine")
+	typeAddFormField(m, "web01.example.com")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := m.inputs[addNameInput].Value(); got != "mine" {
+		t.Errorf("Name = %q, want the typed name kept", got)
+	}
+}
+
+func TestAddFormReverseLookup(t *testing.T) {
+	defer func(original func(context.Context, string) ([]string, error)) { lookupAddr = original }(lookupAddr)
+	lookupAddr = func(ctx context.Context, addr string) ([]string, error) {
+		if addr != "10.0.0.12" {
+			t.Errorf("Looked up %q, want 10.0.0.12", addr)
+		}
+		return []string{"build-03.lab.example.org."}, nil
+	}
+
+	m := newTestAddForm(t)
+	cmd := typeAddFormField(m, "10.0.0.12")
+	if got := m.inputs[addNameInput].Placeholder; got != "ip-10-0-0-12" {
+		t.Fatalf("Name placeholder before the lookup = %q, want ip-10-0-0-12", got)
+	}
+
+	msg := reverseLookupCmd("10.0.0.12")()
+	suggestion, ok := msg.(nameSuggestionMsg)
+	if !ok {
+		t.Fatalf("reverseLookupCmd() = %#v, want a nameSuggestionMsg", msg)
+	}
+	if suggestion.name != "build-03-lab" {
+		t.Errorf("Suggested name = %q, want build-03-lab", suggestion.name)
+	}
+	if cmd == nil {
+		t.Error("Expected typing an IP to start a lookup")
+	}
+
+	m.Update(suggestion)
+	if got := m.inputs[addNameInput].Placeholder; got != "build-03-lab" {
+		t.Errorf("Name placeholder after the lookup = %q, want build-03-lab", got)
+	}
+}
+
+func TestAddFormIgnoresLateLookup(t *testing.T) {
+	m := newTestAddForm(t)
+	typeAddFormField(m, "10.0.0.12")
+
+	// A name typed before the lookup returns is never replaced
+	m.inputs[addNameInput].SetValue("mine")
+	m.Update(nameSuggestionMsg{hostname: "10.0.0.12", name: "build-03-lab"})
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := m.inputs[addNameInput].Value(); got != "mine" {
+		t.Errorf("Name = %q, want the typed name kept", got)
+	}
+
+	// A lookup for a hostname that has since changed is dropped
+	m.inputs[addNameInput].SetValue("")
+	typeAddFormField(m, "4")
+	m.Update(nameSuggestionMsg{hostname: "10.0.0.12", name: "build-03-lab"})
+	if got := m.inputs[addNameInput].Placeholder; got != "ip-10-0-0-124" {
+		t.Errorf("Name placeholder = %q, want ip-10-0-0-124", got)
+	}
+}
+
+func TestReverseLookupFailureYieldsNothing(t *testing.T) {
+	defer func(original func(context.Context, string) ([]string, error)) { lookupAddr = original }(lookupAddr)
+	lookupAddr = func(ctx context.Context, addr string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+	if msg := reverseLookupCmd("10.0.0.12")(); msg != nil {
+		t.Errorf("reverseLookupCmd() = %#v, want nil", msg)
+	}
+}
+
+func TestAddFormSplitsPastedURI(t *testing.T) {
+	m := newTestAddForm(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ssh://deploy@[2001:db8::1]:2222"), Paste: true})
+
+	if got := m.inputs[addHostnameInput].Value(); got != "2001:db8::1" {
+		t.Errorf("Hostname = %q, want 2001:db8::1", got)
+	}
+	if got := m.inputs[addUserInput].Value(); got != "deploy" {
+		t.Errorf("User = %q, want deploy", got)
+	}
+	if got := m.inputs[addPortInput].Value(); got != "2222" {
+		t.Errorf("Port = %q, want 2222", got)
+	}
+}
+
+func TestAddFormSplitsTypedURIOnLeavingField(t *testing.T) {
+	m := newTestAddForm(t)
+	typeAddFormField(m, "ssh://web.example.com")
+	if got := m.inputs[addHostnameInput].Value(); got != "ssh://web.example.com" {
+		t.Fatalf("Expected a URI being typed to be left alone, got %q", got)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.inputs[addHostnameInput].Value(); got != "web.example.com" {
+		t.Errorf("Hostname = %q, want web.example.com", got)
+	}
+	if m.inputs[addUserInput].Value() != "" || m.inputs[addPortInput].Value() != "" {
+		t.Error("Expected User and Port to stay empty for a URI without them")
+	}
+}
+
+func TestAddFormRejectsOtherSchemes(t *testing.T) {
+	m := newTestAddForm(t)
+	typeAddFormField(m, "https://example.com")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd != nil {
+		t.Fatal("Expected the form not to be submitted")
+	}
+	if m.err == "" || !strings.Contains(m.err, "only ssh:// URIs") {
+		t.Errorf("err = %q, want the unsupported scheme explained", m.err)
+	}
+
+	// Fixing the hostname clears the error
+	m.inputs[addHostnameInput].SetValue("example.com")
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.err != "" {
+		t.Errorf("err = %q, want it cleared", m.err)
+	}
+}
+
+// stubFindRoute makes the add form's route search report bastion without probing,
+// and records the bastions it was given
+func stubFindRoute(t *testing.T, bastion string, err error) *[]string {
+	t.Helper()
+	var tried []string
+	original := findRoute
+	findRoute = func(ctx context.Context, bastions []string, probe connectivity.RouteProbe, concurrency int) (string, error) {
+		tried = bastions
+		return bastion, err
+	}
+	t.Cleanup(func() { findRoute = original })
+	return &tried
+}
+
+func TestAddFormSuggestsBastion(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configFile, []byte("# Tags: bastion\nHost jump-eu\n    HostName 1.2.3.4\n\nHost web\n    HostName 5.6.7.8\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tried := stubFindRoute(t, "jump-eu", nil)
+
+	m := newTestAddForm(t)
+	m.configFile = configFile
+	typeAddFormField(m, "10.20.0.5")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if cmd == nil || m.routeCancel == nil {
+		t.Fatal("Expected Ctrl+B to start a route search")
+	}
+	m.Update(cmd())
+	if len(*tried) != 1 || (*tried)[0] != "jump-eu" {
+		t.Errorf("Bastions tried = %q, want the host tagged bastion", *tried)
+	}
+	if !strings.Contains(m.routeStatus, "jump-eu") || m.routeCancel != nil {
+		t.Errorf("routeStatus = %q, want the found bastion and the search finished", m.routeStatus)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if got := m.inputs[addProxyJumpInput].Value(); got != "jump-eu" {
+		t.Errorf("ProxyJump = %q, want the suggestion accepted", got)
+	}
+}
+
+func TestAddFormRouteSearchStaleAndCanceled(t *testing.T) {
+	stubFindRoute(t, "", connectivity.ErrNoRoute)
+
+	m := newTestAddForm(t)
+	m.configFile = filepath.Join(t.TempDir(), "config")
+	os.WriteFile(m.configFile, nil, 0600)
+	typeAddFormField(m, "10.20.0.5")
+
+	_, first := m.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	_, second := m.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	stale := first().(routeSuggestionMsg)
+	stale.bastion, stale.err = "old", nil
+	m.Update(stale)
+	if m.routeBastion != "" {
+		t.Error("Expected the result of a replaced search to be ignored")
+	}
+	m.Update(second())
+	if !strings.Contains(m.routeStatus, "no host is tagged") {
+		t.Errorf("routeStatus = %q, want the missing bastions explained", m.routeStatus)
+	}
+
+	m.startRouteSearch()
+	cancel := m.routeCancel
+	ctxCanceled := false
+	m.routeCancel = func() { ctxCanceled = true; cancel() }
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !ctxCanceled {
+		t.Error("Expected closing the form to cancel the running search")
+	}
+}
+
+func TestAddFormReopensWhenSaveFails(t *testing.T) {
+	// A directory cannot be written as a config file
+	form := NewAddForm("", NewStyles(120), 120, 60, t.TempDir())
+	form.inputs[addNameInput].SetValue("nfs-box")
+	form.inputs[addHostnameInput].SetValue("10.1.2.3")
+	form.inputs[addUserInput].SetValue("deploy")
+
+	m := createTestModel()
+	m.addForm = form
+	m.viewMode = ViewAdd
+	msg := form.submitForm()()
+
+	// The form was closed while the host was being saved
+	updated, _ := m.Update(addFormCancelMsg{})
+	m = updated.(Model)
+	if m.addForm != nil {
+		t.Fatal("Expected cancel to close the form")
+	}
+
+	updated, _ = m.Update(msg)
+	m = updated.(Model)
+	if m.viewMode != ViewAdd || m.addForm == nil {
+		t.Fatalf("viewMode = %v, want the add form back after the failed save", m.viewMode)
+	}
+	if m.addForm.err == "" {
+		t.Error("Expected the save error in the form")
+	}
+	for field, want := range map[int]string{addNameInput: "nfs-box", addHostnameInput: "10.1.2.3", addUserInput: "deploy"} {
+		if got := m.addForm.inputs[field].Value(); got != want {
+			t.Errorf("field %d = %q, want %q kept", field, got, want)
+		}
+	}
+}
+
+func TestSaveErrorTextOffersRetry(t *testing.T) {
+	stale := &os.PathError{Op: "open", Path: "/nfs/home/.ssh/config", Err: syscall.ESTALE}
+	if config.IsTransientWriteError(stale) && !strings.Contains(saveErrorText(stale), "ctrl+s tries again") {
+		t.Errorf("saveErrorText(ESTALE) = %q, want a hint to try again", saveErrorText(stale))
+	}
+	denied := &os.PathError{Op: "open", Path: "/home/.ssh/config", Err: syscall.EACCES}
+	if got := saveErrorText(denied); got != denied.Error() {
+		t.Errorf("saveErrorText(EACCES) = %q, want the error alone", got)
+	}
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/hooks"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// annotationSeparator sits between a host's tags and its annotation
+const annotationSeparator = " · "
+
+// annotationsMsg carries the result of running the annotation command
+type annotationsMsg struct {
+	annotations map[string]hooks.Annotation
+	err         error
+}
+
+// loadAnnotations runs the configured annotation command on the host list in the
+// background, or returns nil when there is none
+func (m *Model) loadAnnotations() tea.Cmd {
+	annotator := hooks.AnnotatorFromAppConfig(m.appConfig)
+	if !annotator.Enabled() || m.browsing() {
+		return nil
+	}
+	hosts := append([]config.SSHHost(nil), m.hosts...)
+	return func() tea.Msg {
+		annotations, err := annotator.Run(context.Background(), hosts)
+		return annotationsMsg{annotations: annotations, err: err}
+	}
+}
+
+// applyAnnotations shows the annotations of msg, or drops them all and warns once
+// when the command failed
+func (m *Model) applyAnnotations(msg annotationsMsg) tea.Cmd {
+	m.annotations = msg.annotations
+	if msg.err != nil {
+		m.annotations = nil
+	}
+	m.rowCache = nil
+	m.updateTableColumns()
+	m.updateTableRows()
+	if msg.err == nil {
+		return nil
+	}
+	m.errorMessage = fmt.Sprintf("No annotations: %v", msg.err)
+	m.showingError = true
+	m.report(severityWarning, sourceAnnotations, msg.err.Error())
+	return func() tea.Msg {
+		time.Sleep(3 * time.Second)
+		return errorMsg("clear")
+	}
+}
+
+// joinAnnotation appends an annotation to the tags of a row
+func joinAnnotation(tags, annotation string) string {
+	if tags == "" {
+		return annotation
+	}
+	return tags + annotationSeparator + annotation
+}
+
+// tagsCell renders the tags column of a row, coloring the annotation with its slot
+func (m *Model) tagsCell(cells *rowCacheEntry) string {
+	color := GetCurrentTheme().LabelColor(cells.annotation.Color)
+	if cells.annotation.Text == "" || color == "" {
+		return cells.tagsStr
+	}
+	// The table would cut the styled text in the middle of its escape codes
+	if columns := m.table.Columns(); len(columns) > 2 && lipgloss.Width(cells.tagsStr) > columns[2].Width {
+		return cells.tagsStr
+	}
+	tags := cells.tagsStr[:len(cells.tagsStr)-len(cells.annotation.Text)]
+	return tags + lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(cells.annotation.Text)
+}
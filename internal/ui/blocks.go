@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Indicators of the row standing for a multi-host block
+const (
+	collapsedBlockIndicator = "▸"
+	expandedBlockIndicator  = "▾"
+)
+
+// blockMemberIndent shifts the members of an expanded block under its row
+const blockMemberIndent = "  "
+
+// blockKey identifies the multi-host block a host was declared in, or returns ""
+// for a host declared on its own
+func blockKey(host *config.SSHHost) string {
+	if host == nil || len(host.BlockNames) < 2 {
+		return ""
+	}
+	return host.SourceFile + "\x00" + strings.Join(host.BlockNames, " ")
+}
+
+// blockLabel names a block after its first and last host
+func blockLabel(names []string) string {
+	return fmt.Sprintf("%s…%s (%d hosts)", names[0], names[len(names)-1], len(names))
+}
+
+// blockEntries replaces the members of each multi-host block with a single row,
+// followed by the members when the block is expanded. The row takes the place of
+// the first member in entries. While searching every block is expanded, so a
+// matching member is never hidden.
+func (m *Model) blockEntries(entries []HostEntry) []HostEntry {
+	members := make(map[string][]HostEntry)
+	for _, entry := range entries {
+		if key := blockKey(entry.SSHHost); key != "" {
+			members[key] = append(members[key], entry)
+		}
+	}
+	if len(members) == 0 {
+		return entries
+	}
+
+	searching := m.searchInput.Value() != ""
+	result := make([]HostEntry, 0, len(entries))
+	shown := make(map[string]bool)
+	for _, entry := range entries {
+		key := blockKey(entry.SSHHost)
+		if key == "" || len(members[key]) < 2 {
+			result = append(result, entry)
+			continue
+		}
+		if shown[key] {
+			continue
+		}
+		shown[key] = true
+
+		expanded := searching || m.expandedBlocks[key]
+		result = append(result, HostEntry{
+			Name:         blockLabel(entry.SSHHost.BlockNames),
+			Tags:         entry.Tags,
+			Hostname:     entry.Hostname,
+			Block:        key,
+			BlockMembers: members[key],
+			Expanded:     expanded,
+		})
+		if expanded {
+			for _, member := range members[key] {
+				member.Block = key
+				result = append(result, member)
+			}
+		}
+	}
+	return result
+}
+
+// buildEntryRow formats the row of any entry, block rows and members included
+func (m *Model) buildEntryRow(entry HostEntry) table.Row {
+	if entry.BlockMembers != nil {
+		return m.buildBlockRow(entry)
+	}
+	row := m.buildRow(entry)
+	if entry.Block != "" {
+		row[0] = blockMemberIndent + row[0]
+	}
+	return row
+}
+
+// buildBlockRow formats the row standing for a multi-host block. Its Last Login
+// is the most recent login to any member.
+func (m *Model) buildBlockRow(entry HostEntry) table.Row {
+	indicator := collapsedBlockIndicator
+	if entry.Expanded {
+		indicator = expandedBlockIndicator
+	}
+
+	var lastLogin time.Time
+	hasLogin := false
+	for _, member := range entry.BlockMembers {
+		cells := m.cachedCells(member.Name, member.Tags)
+		if cells.hasLogin && (!hasLogin || cells.lastLogin.After(lastLogin)) {
+			lastLogin, hasLogin = cells.lastLogin, true
+		}
+	}
+	var lastLoginStr string
+	if hasLogin {
+		lastLoginStr = m.formatLastLogin(lastLogin)
+	}
+
+	first := entry.BlockMembers[0]
+	var description string
+	if first.SSHHost != nil {
+		description = first.SSHHost.Description
+	}
+
+	return table.Row{
+		indicator + " " + entry.Name,
+		entry.Hostname,
+		m.cachedCells(first.Name, first.Tags).tagsStr,
+		lastLoginStr,
+		description,
+	}
+}
+
+// selectedEntry returns the entry under the cursor, or nil when the list is empty
+func (m *Model) selectedEntry() *HostEntry {
+	entries := m.displayEntries()
+	index := m.selectedIndex()
+	if index < 0 || index >= len(entries) {
+		return nil
+	}
+	return &entries[index]
+}
+
+// hostActions are the list actions that need a single host, so they cannot run
+// on the row of a collapsed block
+var hostActions = map[string]bool{
+	config.ActionInfo:        true,
+	config.ActionEdit:        true,
+	config.ActionDelete:      true,
+	config.ActionMove:        true,
+	config.ActionTransfer:    true,
+	config.ActionForward:     true,
+	config.ActionKeyUpload:   true,
+	config.ActionTagFilter:   true,
+	config.ActionDualBrowser: true,
+	config.ActionSnippets:    true,
+	config.ActionVerboseSSH:  true,
+	config.ActionMount:       true,
+}
+
+// handleBlockKey handles keys on the rows of multi-host blocks: right expands a
+// block, left collapses it, and Enter or a host action on the block's row opens
+// it so a member can be chosen. handled is false for keys it leaves alone.
+func (m Model) handleBlockKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	entry := m.selectedEntry()
+	if entry == nil || entry.Block == "" {
+		return m, nil, false
+	}
+	key := msg.String()
+	isBlockRow := entry.BlockMembers != nil
+	kb := m.keyBindings()
+
+	switch {
+	case key == "right" && isBlockRow:
+		m.setBlockExpanded(entry.Block, true, false)
+		return m, nil, true
+
+	case key == "left" && m.expandedBlocks[entry.Block]:
+		m.setBlockExpanded(entry.Block, false, false)
+		return m, nil, true
+
+	case isBlockRow && (key == "enter" || hostActions[kb.ActionForKey(key)]):
+		// Open the block on its first member and ask for one
+		m.setBlockExpanded(entry.Block, true, true)
+		m.errorMessage = fmt.Sprintf("%s: select a host and try again", entry.Name)
+		m.showingError = true
+		return m, func() tea.Msg {
+			time.Sleep(2 * time.Second)
+			return errorMsg("clear")
+		}, true
+	}
+	return m, nil, false
+}
+
+// setBlockExpanded expands or collapses a block and puts the cursor on the
+// block's row, or on its first member when onMember is set
+func (m *Model) setBlockExpanded(key string, expanded, onMember bool) {
+	if m.expandedBlocks == nil {
+		m.expandedBlocks = make(map[string]bool)
+	}
+	if expanded {
+		m.expandedBlocks[key] = true
+	} else {
+		delete(m.expandedBlocks, key)
+	}
+
+	cursor := m.selectedIndex()
+	for i, entry := range m.displayEntries() {
+		if entry.Block == key && entry.BlockMembers != nil {
+			cursor = i
+			if onMember && entry.Expanded {
+				cursor++
+			}
+			break
+		}
+	}
+	m.materializeRows(cursor)
+}
+
+// toggleCollapseBlocks switches collapse mode, keeping the cursor on the same host
+// or on the block that now stands for it
+func (m *Model) toggleCollapseBlocks() {
+	var selectedName, selectedBlock string
+	if entry := m.selectedEntry(); entry != nil {
+		selectedName, selectedBlock = entry.Name, entry.Block
+		if entry.BlockMembers != nil {
+			selectedName = entry.BlockMembers[0].Name
+		}
+		if selectedBlock == "" {
+			selectedBlock = blockKey(entry.SSHHost)
+		}
+	}
+
+	m.collapseBlocks = !m.collapseBlocks
+	if m.appConfig != nil {
+		m.appConfig.CollapseBlocks = m.collapseBlocks
+		config.SaveAppConfig(m.appConfig)
+	}
+
+	cursor := 0
+	for i, entry := range m.displayEntries() {
+		if entry.BlockMembers == nil && entry.Name == selectedName {
+			cursor = i
+			break
+		}
+		if selectedBlock != "" && entry.Block == selectedBlock && entry.BlockMembers != nil && !entry.Expanded {
+			cursor = i
+			break
+		}
+	}
+	m.updateTableHeight()
+	m.updateTableColumns()
+	m.materializeRows(cursor)
+}
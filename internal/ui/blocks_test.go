@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// createBlockTestModel lists the blocks "db1 db2" and "node01 node02 node03" and a host of its own
+func createBlockTestModel() Model {
+	block := func(names ...string) []config.SSHHost {
+		hosts := make([]config.SSHHost, len(names))
+		for i, name := range names {
+			hosts[i] = config.SSHHost{Name: name, Hostname: "%h.internal", SourceFile: "/ssh/config", BlockNames: names}
+		}
+		return hosts
+	}
+	hosts := append(block("db1", "db2"), block("node01", "node02", "node03")...)
+	hosts = append(hosts, config.SSHHost{Name: "web", Hostname: "web.example.com", SourceFile: "/ssh/config"})
+	m := createTestModel(withHosts(hosts), withSize(120, 40), withCollapsedBlocks())
+	m.table.Focus()
+	return m
+}
+
+// rowNames returns the first cell of every listed row without styling
+func rowNames(m Model) []string {
+	var names []string
+	for _, row := range m.table.Rows() {
+		names = append(names, strings.TrimSpace(ansi.Strip(row[0])))
+	}
+	return names
+}
+
+func TestCollapsedBlocksExpand(t *testing.T) {
+	m := createBlockTestModel()
+
+	want := []string{"▸ db1…db2 (2 hosts)", "▸ node01…node03 (3 hosts)", "○ web"}
+	if got := rowNames(m); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Expected collapsed blocks %q, got %q", want, got)
+	}
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	want = []string{"▸ db1…db2 (2 hosts)", "▾ node01…node03 (3 hosts)", "○ node01", "○ node02", "○ node03", "○ web"}
+	if got := rowNames(m); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Expected the node block expanded, got %q", got)
+	}
+	if entry := m.selectedEntry(); entry == nil || entry.BlockMembers == nil {
+		t.Errorf("Expected the cursor to stay on the block's row, got %+v", entry)
+	}
+
+	// Enter on a collapsed block opens it on its first member instead of connecting
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if entry := m.selectedEntry(); entry == nil || entry.Name != "db1" {
+		t.Errorf("Expected the cursor on db1, got %+v", entry)
+	}
+	if !m.showingError || !strings.Contains(m.errorMessage, "select a host") {
+		t.Errorf("Expected a prompt to choose a host, got %q", m.errorMessage)
+	}
+
+	// Left on a member folds its block again
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyLeft})
+	if got := rowNames(m)[0]; got != "▸ db1…db2 (2 hosts)" {
+		t.Errorf("Expected the db block collapsed, got %q", got)
+	}
+}
+
+func TestBlockExpansionSurvivesFiltering(t *testing.T) {
+	m := createBlockTestModel()
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRight})
+
+	// Searching for a member opens its block
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = typeFilterKeystroke(m, 'd')
+	m = typeFilterKeystroke(m, 'b')
+	want := []string{"▾ db1…db2 (2 hosts)", "○ db1", "○ db2"}
+	if got := rowNames(m); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Expected the matching block expanded, got %q", got)
+	}
+
+	// Clearing the search keeps the block opened by hand and folds the other one
+	for range 2 {
+		m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+		newModel, _ := m.Update(listSearchDebounceMsg{seq: m.searchSeq})
+		m = newModel.(Model)
+	}
+	want = []string{"▸ db1…db2 (2 hosts)", "▾ node01…node03 (3 hosts)", "○ node01", "○ node02", "○ node03", "○ web"}
+	if got := rowNames(m); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected the node block to stay expanded, got %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// browseActions are the list actions available when browsing a peer's hosts. The
+// rest edit the local config or read host details that were not shared.
+var browseActions = map[string]bool{
+	config.ActionHelp:          true,
+	config.ActionSearch:        true,
+	config.ActionSortCycle:     true,
+	config.ActionSortName:      true,
+	config.ActionSortRecent:    true,
+	config.ActionTagFilter:     true,
+	config.ActionTheme:         true,
+	config.ActionTimeFormat:    true,
+	config.ActionCollapse:      true,
+	config.ActionColumns:       true,
+	config.ActionPing:          true,
+	config.ActionVerboseSSH:    true,
+	config.ActionMessages:      true,
+	config.ActionJumpConnect:   true,
+	config.ActionSaveWorkspace: true,
+	config.ActionWorkspaces:    true,
+}
+
+// browsing reports whether the list shows hosts fetched from a peer
+func (m Model) browsing() bool {
+	return m.browseSource != ""
+}
+
+// blockedInBrowseMode shows a notice and returns true when action is not available
+// while browsing a peer's hosts
+func (m *Model) blockedInBrowseMode(action string) (tea.Cmd, bool) {
+	if !m.browsing() || action == "" || browseActions[action] {
+		return nil, false
+	}
+	m.errorMessage = fmt.Sprintf("Read-only: these hosts are shared by %s", m.browseSource)
+	m.showingError = true
+	return func() tea.Msg {
+		time.Sleep(2 * time.Second)
+		return errorMsg("clear")
+	}, true
+}
+
+// sshDestination returns the ssh arguments that reach hostName. Shared hosts are
+// not in the local config, so they are reached by address, user and port.
+func (m *Model) sshDestination(hostName string) []string {
+	if !m.browsing() {
+		return []string{hostName}
+	}
+	for _, host := range m.hosts {
+		if host.Name != hostName {
+			continue
+		}
+		var args []string
+		if host.Port != "" {
+			args = append(args, "-p", host.Port)
+		}
+		if host.User != "" {
+			args = append(args, "-l", host.User)
+		}
+		return append(args, host.Hostname)
+	}
+	return []string{hostName}
+}
+
+// RunBrowseMode shows hosts shared by a peer at source in a read-only list.
+// Connections use the local ssh and the local keys.
+func RunBrowseMode(hosts []config.SSHHost, source, currentVersion string) error {
+	if os.Getenv("SSHC_DEBUG") != "" {
+		if logPath, err := config.StateFilePath("debug.log"); err == nil {
+			if f, err := tea.LogToFile(logPath, "sshc"); err == nil {
+				defer f.Close()
+			}
+		}
+	}
+
+	m := newModel(hosts, "", currentVersion, strings.TrimSuffix(source, "/"))
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("error running TUI: %w", err)
+	}
+	return nil
+}
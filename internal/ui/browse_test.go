@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshver"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBrowseModeIsReadOnly(t *testing.T) {
+	m := createLargeTestModel(3)
+	m.browseSource = "http://peer:7843"
+
+	for _, key := range []string{"e", "d", "a"} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		got := updated.(Model)
+		if got.viewMode != ViewList || !got.showingError {
+			t.Errorf("Key %q: viewMode = %v, showingError = %v, want a read-only notice in the list", key, got.viewMode, got.showingError)
+		}
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if got := updated.(Model); got.viewMode != ViewHelp {
+		t.Errorf("Help: viewMode = %v, want ViewHelp", got.viewMode)
+	}
+}
+
+func TestBrowseModeConnectsByAddress(t *testing.T) {
+	m := createLargeTestModel(1)
+	m.hosts[0].Port = "2222"
+
+	if got := m.sshDestination("node-0000"); !slices.Equal(got, []string{"node-0000"}) {
+		t.Errorf("sshDestination() = %v, want the alias outside browse mode", got)
+	}
+
+	m.browseSource = "http://peer:7843"
+	want := []string{"-p", "2222", "-l", "deploy", "10.0.0.0"}
+	if got := m.sshDestination("node-0000"); !slices.Equal(got, want) {
+		t.Errorf("sshDestination() = %v, want %v", got, want)
+	}
+}
+
+func TestBrowseModeSkipsLocalHooksAndSetEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	m := createLargeTestModel(1)
+	m.configFile = filepath.Join(t.TempDir(), "config")
+	fixture := "Host node-0000\n    SetEnv EDITOR=vi\n"
+	if err := os.WriteFile(m.configFile, []byte(fixture), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m.appConfig = &config.AppConfig{
+		PreConnectHook:  "echo pre {host}",
+		PostConnectHook: "echo post {host}",
+		HostEnv:         map[string]map[string]string{"node-0000": {"LANG": "C"}},
+	}
+	m.sshVersion, _ = sshver.Parse("OpenSSH_9.6p1")
+	m.sshVersionKnown = true
+
+	// run connects with a command that does nothing and returns what the hooks printed
+	run := func(cmd *hintedCommand) string {
+		var out bytes.Buffer
+		cmd.Cmd = exec.Command("true")
+		cmd.Stdout = &out
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	cmd := m.connectCommand("node-0000", []string{"node-0000"})
+	if !strings.Contains(strings.Join(cmd.Args, " "), "EDITOR=vi") {
+		t.Errorf("args = %q, want the configured SetEnv outside browse mode", cmd.Args)
+	}
+	if got := run(cmd); got != "pre node-0000\npost node-0000\n" {
+		t.Errorf("hooks printed %q outside browse mode, want both hooks", got)
+	}
+
+	m.browseSource = "http://peer:7843"
+	cmd = m.connectCommand("node-0000", m.sshDestination("node-0000"))
+	if strings.Contains(strings.Join(cmd.Args, " "), "EDITOR=vi") {
+		t.Errorf("args = %q, want no SetEnv from the local config for a browsed host", cmd.Args)
+	}
+	if got := run(cmd); got != "" {
+		t.Errorf("hooks printed %q for a browsed host, want none to run", got)
+	}
+}
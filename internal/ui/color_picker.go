@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type colorPickerModel struct {
+	hostName      string
+	current       string
+	selectedIndex int
+	styles        Styles
+	width         int
+	height        int
+}
+
+// Messages for communication with parent model
+type colorPickerSubmitMsg struct {
+	hostName string
+	slot     string // Empty removes the label
+}
+
+type colorPickerCancelMsg struct{}
+
+// colorPickerSlots returns the picker entries: no label, then the label slots
+func colorPickerSlots() []string {
+	return append([]string{""}, ColorLabels...)
+}
+
+// NewColorPicker creates a picker for the color label of a host
+func NewColorPicker(hostName, current string, styles Styles, width, height int) *colorPickerModel {
+	selectedIndex := 0
+	for i, slot := range colorPickerSlots() {
+		if slot == current {
+			selectedIndex = i
+			break
+		}
+	}
+
+	return &colorPickerModel{
+		hostName:      hostName,
+		current:       current,
+		selectedIndex: selectedIndex,
+		styles:        styles,
+		width:         width,
+		height:        height,
+	}
+}
+
+func (m *colorPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *colorPickerModel) Update(msg tea.Msg) (*colorPickerModel, tea.Cmd) {
+	slots := colorPickerSlots()
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch key := msg.String(); key {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return colorPickerCancelMsg{} }
+
+		case "enter":
+			return m, m.submit(slots[m.selectedIndex])
+
+		case "up", "k":
+			m.selectedIndex--
+			if m.selectedIndex < 0 {
+				m.selectedIndex = len(slots) - 1
+			}
+			return m, nil
+
+		case "down", "j":
+			m.selectedIndex++
+			if m.selectedIndex >= len(slots) {
+				m.selectedIndex = 0
+			}
+			return m, nil
+
+		case "0", "x":
+			return m, m.submit("")
+
+		default:
+			// Digits pick a color directly
+			if index, ok := tagDigitIndex(key); ok && index < len(ColorLabels) {
+				return m, m.submit(ColorLabels[index])
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// submit returns a command applying slot to the host
+func (m *colorPickerModel) submit(slot string) tea.Cmd {
+	hostName := m.hostName
+	return func() tea.Msg { return colorPickerSubmitMsg{hostName: hostName, slot: slot} }
+}
+
+func (m *colorPickerModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	var items []string
+	for i, slot := range colorPickerSlots() {
+		label := "0. No color"
+		color := theme.Foreground
+		if slot != "" {
+			label = fmt.Sprintf("%d. ● %s", i, m.hostName)
+			color = theme.LabelColor(slot)
+		}
+		if slot == m.current {
+			label += " (current)"
+		}
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Padding(0, 2)
+		if i == m.selectedIndex {
+			style = style.Background(lipgloss.Color(theme.SelectionBg)).Bold(true)
+		}
+		items = append(items, style.Render(label))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Color label for "+m.hostName),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		helpStyle.Render("1-5/Enter: apply • 0: remove • Esc: cancel"),
+	)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content),
+	)
+}
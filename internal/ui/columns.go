@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// optionalColumns are the columns of the host list that can be hidden
+var optionalColumns = []string{config.ColumnTags, config.ColumnLastLogin, config.ColumnDescription}
+
+// columnTitles label the optional columns in the picker
+var columnTitles = map[string]string{
+	config.ColumnTags:        "Tags",
+	config.ColumnLastLogin:   "Last Login",
+	config.ColumnDescription: "Description",
+}
+
+// columnPickerModel shows or hides the optional columns of the host list
+type columnPickerModel struct {
+	hidden        []string
+	selectedIndex int
+	styles        Styles
+	width         int
+	height        int
+}
+
+// Messages for communication with parent model
+type columnsChangedMsg struct {
+	hidden []string
+}
+
+type columnPickerCloseMsg struct{}
+
+// NewColumnPicker creates the picker of the optional columns, hidden ones unchecked
+func NewColumnPicker(hidden []string, styles Styles, width, height int) *columnPickerModel {
+	return &columnPickerModel{
+		hidden: slices.Clone(hidden),
+		styles: styles,
+		width:  width,
+		height: height,
+	}
+}
+
+func (m *columnPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *columnPickerModel) Update(msg tea.Msg) (*columnPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch key := msg.String(); key {
+		case "ctrl+c", "esc", "q", "enter":
+			return m, func() tea.Msg { return columnPickerCloseMsg{} }
+
+		case "up", "k":
+			m.selectedIndex--
+			if m.selectedIndex < 0 {
+				m.selectedIndex = len(optionalColumns) - 1
+			}
+
+		case "down", "j":
+			m.selectedIndex++
+			if m.selectedIndex >= len(optionalColumns) {
+				m.selectedIndex = 0
+			}
+
+		case " ":
+			return m, m.toggle(m.selectedIndex)
+
+		default:
+			// Digits toggle a column directly
+			if index, ok := tagDigitIndex(key); ok {
+				return m, m.toggle(index)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// toggle shows or hides the column at index and returns a command applying it to the list
+func (m *columnPickerModel) toggle(index int) tea.Cmd {
+	if index < 0 || index >= len(optionalColumns) {
+		return nil
+	}
+	m.selectedIndex = index
+	column := optionalColumns[index]
+	if i := slices.Index(m.hidden, column); i >= 0 {
+		m.hidden = slices.Delete(m.hidden, i, i+1)
+	} else {
+		m.hidden = append(m.hidden, column)
+	}
+	hidden := slices.Clone(m.hidden)
+	return func() tea.Msg { return columnsChangedMsg{hidden: hidden} }
+}
+
+func (m *columnPickerModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	var items []string
+	for i, column := range optionalColumns {
+		check := "[x]"
+		if slices.Contains(m.hidden, column) {
+			check = "[ ]"
+		}
+		label := fmt.Sprintf("%d. %s %s", i+1, check, columnTitles[column])
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground)).Padding(0, 2)
+		if i == m.selectedIndex {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 2)
+		}
+		items = append(items, style.Render(label))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Columns"),
+		"",
+		helpStyle.Render("Name and Hostname are always shown"),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		helpStyle.Render("1-3/Space: show or hide • Esc: close"),
+	)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content),
+	)
+}
+
+// columnVisible reports whether column is shown in the host list
+func (m *Model) columnVisible(column string) bool {
+	return !slices.Contains(m.hiddenColumns, column)
+}
+
+// visibleColumns returns the columns shown in the host list, in display order
+func (m *Model) visibleColumns() []string {
+	var columns []string
+	for _, column := range config.ListColumns {
+		if m.columnVisible(column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// hideableColumns returns the optional columns among columns, in display order.
+// Names of other columns, such as ones edited into the config by hand, are dropped.
+func hideableColumns(columns []string) []string {
+	var hideable []string
+	for _, column := range optionalColumns {
+		if slices.Contains(columns, column) {
+			hideable = append(hideable, column)
+		}
+	}
+	return hideable
+}
+
+// setHiddenColumns hides the optional columns in hidden and shows the others
+func (m *Model) setHiddenColumns(hidden []string) {
+	m.hiddenColumns = hideableColumns(hidden)
+	m.updateTableColumns()
+	m.updateTableRows()
+}
+
+// saveHiddenColumns saves the hidden columns to the application configuration
+func (m *Model) saveHiddenColumns() {
+	if m.appConfig == nil {
+		return
+	}
+
+	m.appConfig.HiddenColumns = slices.Clone(m.hiddenColumns)
+	config.SaveAppConfig(m.appConfig)
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sparklineWidth is the number of buckets the connect timeline is drawn with
+const sparklineWidth = 20
+
+// sparkLevels are the bar heights of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// connectRateConfirmModel asks before connecting to a host that was connected to
+// more often than its usage limit allows
+type connectRateConfirmModel struct {
+	hostName string
+	limit    config.UsageLimit
+	connects []time.Time // Within the window, oldest first
+	now      time.Time
+	key      tea.KeyMsg // Key that connects, handled again once confirmed
+	styles   Styles
+	width    int
+	height   int
+}
+
+// Messages for communication with parent model
+type connectRateConfirmMsg struct {
+	hostName string
+	key      tea.KeyMsg
+}
+
+type connectRateCancelMsg struct{}
+
+// NewConnectRateConfirm creates the confirmation for connecting to a host with key
+func NewConnectRateConfirm(hostName string, limit config.UsageLimit, connects []time.Time, now time.Time, key tea.KeyMsg, styles Styles, width, height int) *connectRateConfirmModel {
+	return &connectRateConfirmModel{
+		hostName: hostName,
+		limit:    limit,
+		connects: connects,
+		now:      now,
+		key:      key,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+}
+
+func (m *connectRateConfirmModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *connectRateConfirmModel) Update(msg tea.Msg) (*connectRateConfirmModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "n":
+			return m, func() tea.Msg { return connectRateCancelMsg{} }
+
+		case "enter", "y":
+			hostName, key := m.hostName, m.key
+			return m, func() tea.Msg { return connectRateConfirmMsg{hostName: hostName, key: key} }
+		}
+	}
+	return m, nil
+}
+
+func (m *connectRateConfirmModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning)).Bold(true)
+	sparkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Warning)).
+		Padding(1, 3)
+
+	window := m.limit.WindowDuration()
+	axis := "-" + formatRateWindow(window)
+	lines := []string{
+		titleStyle.Render(fmt.Sprintf("%d connections to %s in the last %s", len(m.connects), m.hostName, formatRateWindow(window))),
+		"",
+		mutedStyle.Render(fmt.Sprintf("The limit is %d; a reconnect loop may be hammering the host.", m.limit.MaxConnects)),
+		"",
+		sparkStyle.Render(sparkline(m.connects, m.now, window, sparklineWidth)),
+		mutedStyle.Render(axis + strings.Repeat(" ", max(sparklineWidth-len(axis)-3, 1)) + "now"),
+	}
+	if m.limit.MaxUsers > 0 {
+		lines = append(lines, "", fmt.Sprintf("Shared host: max %d concurrent users", m.limit.MaxUsers))
+	}
+	lines = append(lines, "", mutedStyle.Render("Enter/y: connect anyway • Esc/n: cancel"))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// formatRateWindow writes a window as minutes, or hours when it is a whole number of them
+func formatRateWindow(window time.Duration) string {
+	if window >= time.Hour && window%time.Hour == 0 {
+		return fmt.Sprintf("%dh", window/time.Hour)
+	}
+	return fmt.Sprintf("%dm", window/time.Minute)
+}
+
+// sparkline draws how times, oldest first, spread over the window ending at now
+// as width bars. Empty buckets are blank and the fullest gets the highest bar.
+func sparkline(times []time.Time, now time.Time, window time.Duration, width int) string {
+	if width <= 0 || window <= 0 {
+		return ""
+	}
+	buckets := make([]int, width)
+	start := now.Add(-window)
+	peak := 0
+	for _, t := range times {
+		if !t.After(start) || t.After(now) {
+			continue
+		}
+		i := int(t.Sub(start) * time.Duration(width) / window)
+		i = min(i, width-1)
+		buckets[i]++
+		peak = max(peak, buckets[i])
+	}
+
+	var b strings.Builder
+	for _, count := range buckets {
+		if count == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparkLevels[(count*len(sparkLevels)-1)/peak])
+	}
+	return b.String()
+}
+
+// connectsWithin returns the connections to a host made in the window ending at now
+func connectsWithin(times []time.Time, now time.Time, window time.Duration) []time.Time {
+	return times[len(times)-history.CountSince(times, now.Add(-window)):]
+}
+
+// blockedByConnectRate asks for confirmation before connecting to a host when
+// this connection would go over its connect rate, and reports whether it did.
+// key is handled again once confirmed, and then goes through.
+func (m *Model) blockedByConnectRate(hostName string, key tea.KeyMsg) bool {
+	if m.connectRateConfirmed == hostName {
+		m.connectRateConfirmed = ""
+		return false
+	}
+	limit := m.appConfig.UsageLimitFor(hostName)
+	if limit.MaxConnects <= 0 || m.historyManager == nil {
+		return false
+	}
+	now := clock()
+	connects := connectsWithin(m.historyManager.GetRecentConnects(hostName), now, limit.WindowDuration())
+	if len(connects) < limit.MaxConnects {
+		return false
+	}
+	m.connectRateConfirm = NewConnectRateConfirm(hostName, limit, connects, now, key, m.styles, m.width, m.height)
+	m.viewMode = ViewConnectRateConfirm
+	m.table.Blur()
+	return true
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSparkline(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) time.Time { return now.Add(-ago) }
+
+	tests := []struct {
+		name  string
+		times []time.Time
+		want  string
+	}{
+		{"empty", nil, "          "},
+		{"one connect per bucket", []time.Time{at(9*time.Minute + 30*time.Second), at(30 * time.Second)}, "█        █"},
+		{"burst", []time.Time{at(5 * time.Minute), at(30 * time.Second), at(20 * time.Second), at(10 * time.Second), at(0)}, "     ▂   █"},
+		{"half of the peak", []time.Time{at(3*time.Minute + 30*time.Second), at(30 * time.Second), at(20 * time.Second)}, "      ▄  █"},
+		{"outside the window", []time.Time{at(10 * time.Minute), at(11 * time.Minute), now.Add(time.Second)}, "          "},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.times, now, 10*time.Minute, 10); got != tt.want {
+			t.Errorf("%s: sparkline() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := sparkline(nil, now, 10*time.Minute, 0); got != "" {
+		t.Errorf("sparkline() without width = %q", got)
+	}
+}
+
+func TestConnectsWithin(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	times := []time.Time{now.Add(-time.Hour), now.Add(-9 * time.Minute), now.Add(-time.Minute)}
+	if got := connectsWithin(times, now, 10*time.Minute); len(got) != 2 || !got[0].Equal(times[1]) {
+		t.Errorf("connectsWithin() = %v, want the last two", got)
+	}
+}
+
+func TestConnectRateConfirmation(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m.historyManager = newTestHistoryManager(t)
+	m.appConfig.UsageLimits = map[string]config.UsageLimit{"node-0000": {MaxUsers: 3, MaxConnects: 2}}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	m.historyManager.RecordConnection("node-0000")
+	if m.blockedByConnectRate("node-0000", enter) {
+		t.Fatal("Expected the second connect to go through")
+	}
+	m.historyManager.RecordConnection("node-0000")
+
+	m, _ = press(t, m, enter)
+	if m.viewMode != ViewConnectRateConfirm {
+		t.Fatalf("Expected the connect rate confirmation, got view %v", m.viewMode)
+	}
+	if got := len(m.connectRateConfirm.connects); got != 2 {
+		t.Errorf("Expected the timeline to hold 2 connects, got %d", got)
+	}
+
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := msg.(connectRateCancelMsg); !ok {
+		t.Fatalf("Expected Esc to cancel, got %T", msg)
+	}
+	m, _ = press(t, m, msg)
+	if m.viewMode != ViewList {
+		t.Fatalf("Expected the list after cancelling, got view %v", m.viewMode)
+	}
+
+	m, _ = press(t, m, enter)
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if _, ok := msg.(connectRateConfirmMsg); !ok {
+		t.Fatalf("Expected y to confirm, got %T", msg)
+	}
+	// The connect command is not run, it would start ssh
+	updated, cmd := m.Update(msg)
+	m = updated.(Model)
+	if cmd == nil || m.viewMode == ViewConnectRateConfirm || m.connectRateConfirmed != "" {
+		t.Errorf("Expected the confirmed key to connect, got view %v and %q", m.viewMode, m.connectRateConfirmed)
+	}
+
+	// Another host keeps the global rate, which is off by default
+	if m.blockedByConnectRate("node-0001", enter) {
+		t.Error("Expected a host without a limit not to be checked")
+	}
+}
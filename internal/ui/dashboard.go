@@ -0,0 +1,282 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+)
+
+const (
+	// dashboardMaxHosts caps how many of the filtered hosts the dashboard probes
+	dashboardMaxHosts = 30
+	// dashboardConcurrency is how many ssh probes run at once
+	dashboardConcurrency = 8
+)
+
+type dashboardModel struct {
+	hosts      []string
+	skipped    int // Filtered hosts left out by dashboardMaxHosts
+	results    map[string]connectivity.HostHealth
+	graph      *config.JumpGraph // ProxyJump chains, to probe bastions before the hosts behind them
+	command    string
+	configFile string
+	generation int // Results of an earlier refresh are dropped
+	cancel     context.CancelFunc
+	styles     Styles
+	width      int
+	height     int
+}
+
+// Messages for communication with parent model
+type dashboardResultMsg struct {
+	generation int
+	health     connectivity.HostHealth
+}
+
+type dashboardCloseMsg struct{}
+
+// NewDashboard creates a health dashboard for the given hosts. The bastions they
+// are reached through according to graph are probed too, ahead of them. command
+// replaces the default probe when it is not empty.
+func NewDashboard(hostNames []string, graph *config.JumpGraph, command, configFile string, styles Styles, width, height int) *dashboardModel {
+	skipped := 0
+	if len(hostNames) > dashboardMaxHosts {
+		skipped = len(hostNames) - dashboardMaxHosts
+		hostNames = hostNames[:dashboardMaxHosts]
+	}
+	if graph == nil {
+		graph = config.BuildJumpGraph(nil)
+	}
+	return &dashboardModel{
+		hosts:      graph.WithBastions(hostNames),
+		skipped:    skipped,
+		results:    make(map[string]connectivity.HostHealth),
+		graph:      graph,
+		command:    command,
+		configFile: configFile,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+}
+
+// Init starts probing every host
+func (m *dashboardModel) Init() tea.Cmd {
+	return m.probeAll()
+}
+
+// probeAll probes the hosts in parallel. Each probe waits for a free slot, so slow
+// or unreachable hosts only hold up the ones queued behind them. A host behind a
+// bastion waits for the bastion's probe before taking a slot, and is reported as
+// behind a down bastion without being probed when that failed.
+func (m *dashboardModel) probeAll() tea.Cmd {
+	m.stop()
+	m.generation++
+	m.results = make(map[string]connectivity.HostHealth)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	slots := make(chan struct{}, dashboardConcurrency)
+	scheduler := connectivity.NewJumpScheduler(m.graph, m.hosts)
+
+	cmds := make([]tea.Cmd, 0, len(m.hosts))
+	for _, hostName := range m.hosts {
+		generation, command, configFile := m.generation, m.command, m.configFile
+		cmds = append(cmds, func() tea.Msg {
+			if err := scheduler.Wait(ctx, hostName); err != nil {
+				scheduler.Done(hostName, err)
+				if ctx.Err() != nil {
+					return nil
+				}
+				health := connectivity.HostHealth{HostName: hostName, DiskPercent: -1, MemPercent: -1, Err: err}
+				return dashboardResultMsg{generation: generation, health: health}
+			}
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				scheduler.Done(hostName, ctx.Err())
+				return nil
+			}
+			health := connectivity.ProbeHealth(ctx, hostName, configFile, command)
+			scheduler.Done(hostName, health.Err)
+			return dashboardResultMsg{generation: generation, health: health}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// stop cancels the probes still running
+func (m *dashboardModel) stop() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+}
+
+func (m *dashboardModel) Update(msg tea.Msg) (*dashboardModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case dashboardResultMsg:
+		if msg.generation == m.generation {
+			m.results[msg.health.HostName] = msg.health
+			if len(m.results) == len(m.hosts) {
+				m.stop()
+			}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			m.stop()
+			return m, func() tea.Msg { return dashboardCloseMsg{} }
+
+		case "r":
+			return m, m.probeAll()
+		}
+	}
+
+	return m, nil
+}
+
+// percentStyle colors a usage percentage by how close it is to full
+func percentStyle(percent int) lipgloss.Style {
+	theme := GetCurrentTheme()
+	switch {
+	case percent >= 90:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error)).Bold(true)
+	case percent >= 80:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning))
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground))
+	}
+}
+
+// formatPercent renders a usage percentage, or "?" when the probe did not report it
+func formatPercent(percent int) string {
+	if percent < 0 {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(GetCurrentTheme().Muted)).Render("?")
+	}
+	return percentStyle(percent).Render(fmt.Sprintf("%d%%", percent))
+}
+
+func (m *dashboardModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted)).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Error))
+
+	okStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Success))
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Warning))
+
+	nameWidth := len("Host")
+	for _, hostName := range m.hosts {
+		nameWidth = max(nameWidth, lipgloss.Width(hostName))
+	}
+	nameWidth = min(nameWidth, 24)
+
+	// Status takes whatever the border, padding and other columns leave
+	statusWidth := max(m.width-nameWidth-16-8-8-4-10, 12)
+
+	cell := func(s string, width int) string {
+		s = ansi.Truncate(s, width, "…")
+		return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0)) + " "
+	}
+
+	lines := []string{
+		headerStyle.Render(cell("Host", nameWidth) + cell("Load", 16) + cell("Disk /", 8) + cell("Memory", 8) + "Status"),
+	}
+
+	done := 0
+	for _, hostName := range m.hosts {
+		health, ok := m.results[hostName]
+		if !ok {
+			lines = append(lines, cell(hostName, nameWidth)+mutedStyle.Render(cell("…", 16)+cell("", 8)+cell("", 8)+"probing…"))
+			continue
+		}
+		done++
+
+		// The chain goes after the status, which is cut first when space runs out
+		var via string
+		if chain := m.graph.Chain(hostName); len(chain) > 0 {
+			via = " via " + strings.Join(chain, " → ")
+		}
+
+		var down *connectivity.BastionDownError
+		if errors.As(health.Err, &down) {
+			status := "bastion down: " + down.Bastion
+			lines = append(lines, cell(hostName, nameWidth)+mutedStyle.Render(cell("-", 16)+cell("-", 8)+cell("-", 8))+
+				warningStyle.Render(status)+mutedStyle.Render(ansi.Truncate(via, max(statusWidth-len(status), 0), "…")))
+			continue
+		}
+
+		if health.Err != nil {
+			status := ansi.Truncate("unreachable: "+health.Err.Error(), statusWidth, "…")
+			lines = append(lines, cell(hostName, nameWidth)+mutedStyle.Render(cell("-", 16)+cell("-", 8)+cell("-", 8))+
+				errorStyle.Render(status)+mutedStyle.Render(ansi.Truncate(via, max(statusWidth-lipgloss.Width(status), 0), "…")))
+			continue
+		}
+
+		lines = append(lines, cell(hostName, nameWidth)+
+			cell(health.FormatLoad(), 16)+
+			cell(formatPercent(health.DiskPercent), 8)+
+			cell(formatPercent(health.MemPercent), 8)+
+			okStyle.Render("ok")+mutedStyle.Render(ansi.Truncate(via, max(statusWidth-2, 0), "…")))
+	}
+
+	title := fmt.Sprintf("Host health (%d/%d)", done, len(m.hosts))
+	var notes []string
+	if m.skipped > 0 {
+		notes = append(notes, mutedStyle.Render(fmt.Sprintf("%d more hosts not shown; filter the list to narrow it down", m.skipped)))
+	}
+	if len(m.hosts) == 0 {
+		notes = append(notes, mutedStyle.Render("No SSH hosts to probe"))
+	}
+
+	parts := []string{titleStyle.Render(title), "", lipgloss.JoinVertical(lipgloss.Left, lines...)}
+	if len(notes) > 0 {
+		parts = append(parts, "")
+		parts = append(parts, notes...)
+	}
+	parts = append(parts, "", mutedStyle.Render("r: refresh • Esc: close"))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, parts...)),
+	)
+}
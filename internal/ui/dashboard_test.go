@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+)
+
+func TestDashboardShowsBastionChain(t *testing.T) {
+	graph := config.BuildJumpGraph([]config.SSHHost{
+		{Name: "edge"},
+		{Name: "inner", ProxyJump: "edge"},
+		{Name: "db", ProxyJump: "inner"},
+	})
+	m := NewDashboard([]string{"db"}, graph, "", "", NewStyles(160), 160, 40)
+	if want := []string{"edge", "inner", "db"}; !slices.Equal(m.hosts, want) {
+		t.Fatalf("dashboard hosts = %v, want the bastions of db first: %v", m.hosts, want)
+	}
+
+	m.results["edge"] = connectivity.HostHealth{HostName: "edge", DiskPercent: -1, MemPercent: -1, Err: errors.New("timed out")}
+	for _, name := range []string{"inner", "db"} {
+		m.results[name] = connectivity.HostHealth{HostName: name, DiskPercent: -1, MemPercent: -1, Err: &connectivity.BastionDownError{Bastion: "edge"}}
+	}
+	view := m.View()
+	for _, want := range []string{"unreachable: timed out", "bastion down: edge via edge → inner"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("dashboard does not show %q:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/metrics"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// debugOverlayFiles is how many of the slowest config files the overlay lists
+const debugOverlayFiles = 5
+
+// debugOverlayModel shows the timings collected by the metrics package in a box
+// drawn over the top right corner of any view. Collecting runs while it is open.
+type debugOverlayModel struct {
+	styles Styles
+}
+
+// newDebugOverlay opens the overlay and starts collecting
+func newDebugOverlay(styles Styles) *debugOverlayModel {
+	metrics.MessageLoop.Reset()
+	metrics.TableRebuild.Reset()
+	metrics.SetEnabled(true)
+	return &debugOverlayModel{styles: styles}
+}
+
+// close stops collecting
+func (d *debugOverlayModel) close() {
+	metrics.SetEnabled(false)
+}
+
+// toggleDebugOverlay opens or closes the debug overlay
+func (m *Model) toggleDebugOverlay() {
+	if m.debugOverlay != nil {
+		m.debugOverlay.close()
+		m.debugOverlay = nil
+		return
+	}
+	m.debugOverlay = newDebugOverlay(m.styles)
+}
+
+// View renders the overlay box for a config of hostCount hosts
+func (d *debugOverlayModel) View(hostCount int) string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	labelStyle := lipgloss.NewStyle().Width(15)
+
+	row := func(label, value string) string {
+		return labelStyle.Render(label) + value
+	}
+
+	total, depth := metrics.ConfigParse.Summary()
+	files := metrics.ConfigParse.Files()
+	lines := []string{
+		titleStyle.Render("Debug") + mutedStyle.Render("  F12: close"),
+		"",
+		row("Hosts", fmt.Sprintf("%d", hostCount)),
+	}
+	if len(files) == 0 {
+		lines = append(lines, row("Config parse", "not measured yet, reload to measure"))
+	} else {
+		lines = append(lines, row("Config parse", fmt.Sprintf("%s, %d file(s), include depth %d", formatTiming(total), len(files), depth)))
+	}
+	for i, file := range files {
+		if i == debugOverlayFiles {
+			lines = append(lines, mutedStyle.Render(fmt.Sprintf("  (+%d more)", len(files)-i)))
+			break
+		}
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("  %8s  %s", formatTiming(file.Duration), abbreviateHome(file.Path))))
+	}
+
+	cache := "n/a"
+	if rate, ok := metrics.Cache.Rate(); ok {
+		hits, misses := metrics.Cache.Counts()
+		cache = fmt.Sprintf("%.0f%% (%d/%d)", rate*100, hits, hits+misses)
+	}
+	lines = append(lines,
+		row("Cache hits", cache),
+		row("Table rebuild", formatRolling(metrics.TableRebuild.Stats())),
+		row("Message loop", formatRolling(metrics.MessageLoop.Stats())),
+	)
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// formatRolling renders the last, average and maximum of a rolling window
+func formatRolling(stats metrics.RollingStats) string {
+	if stats.Count == 0 {
+		return "no samples yet"
+	}
+	return fmt.Sprintf("last %s  avg %s  max %s", formatTiming(stats.Last), formatTiming(stats.Avg), formatTiming(stats.Max))
+}
+
+// formatTiming renders a duration with a precision that suits it
+func formatTiming(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	case d < time.Second:
+		return fmt.Sprintf("%.1fms", float64(d.Microseconds())/1000)
+	default:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	}
+}
+
+// placeTopRight draws box over the top right corner of view, which is width
+// columns wide
+func placeTopRight(view, box string, width int) string {
+	viewLines := strings.Split(view, "\n")
+	boxLines := strings.Split(box, "\n")
+	boxWidth := lipgloss.Width(box)
+	left := max(width-boxWidth, 0)
+
+	for i, boxLine := range boxLines {
+		if i >= len(viewLines) {
+			viewLines = append(viewLines, "")
+		}
+		line := viewLines[i]
+		if pad := left - ansi.StringWidth(line); pad > 0 {
+			line += strings.Repeat(" ", pad)
+		}
+		viewLines[i] = ansi.Truncate(line, left, "") + ansi.ResetStyle + boxLine + ansi.ResetStyle + ansi.TruncateLeft(line, left+boxWidth, "")
+	}
+	return strings.Join(viewLines, "\n")
+}
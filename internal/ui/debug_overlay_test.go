@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/metrics"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// otherMsg is a message no view handles
+type otherMsg struct{}
+
+func TestDebugOverlayToggles(t *testing.T) {
+	m := createLargeTestModel(42)
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+	plainView := m.View()
+
+	press := func() {
+		t.Helper()
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyF12})
+		m = updated.(Model)
+	}
+
+	press()
+	if m.debugOverlay == nil || !metrics.Enabled() {
+		t.Fatal("Expected F12 to open the overlay and start collecting")
+	}
+	// Messages and table rebuilds are measured while it is open
+	m.updateTableRows()
+	for range 3 {
+		updated, _ := m.Update(otherMsg{})
+		m = updated.(Model)
+	}
+	if stats := metrics.TableRebuild.Stats(); stats.Count == 0 {
+		t.Error("Expected the table rebuild to be measured")
+	}
+	if stats := metrics.MessageLoop.Stats(); stats.Count != 2 {
+		t.Errorf("Expected the 2 gaps between 3 messages to be measured, got %d", stats.Count)
+	}
+
+	view := m.View()
+	if got, want := strings.Count(view, "\n"), strings.Count(plainView, "\n"); got != want {
+		t.Errorf("Expected the overlay to keep the view at %d lines, got %d", want+1, got+1)
+	}
+	plain := ansi.Strip(view)
+	for _, want := range []string{"Debug", "Hosts          42", "Message loop", "Search"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("Expected %q in the view:\n%s", want, plain)
+		}
+	}
+	for i, line := range strings.Split(view, "\n") {
+		if width := ansi.StringWidth(line); width > m.width {
+			t.Errorf("Line %d is %d columns wide, wider than the terminal", i, width)
+		}
+	}
+
+	press()
+	if m.debugOverlay != nil || metrics.Enabled() {
+		t.Error("Expected F12 to close the overlay and stop collecting")
+	}
+	if strings.Contains(ansi.Strip(m.View()), "Message loop") {
+		t.Error("Expected the overlay to be gone")
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// renderDiff colors the lines of a unified diff with the current theme, truncating
+// each line to width. Added lines use the success color and removed lines the error color.
+func renderDiff(diff string, width int) []string {
+	if diff == "" {
+		return nil
+	}
+	theme := GetCurrentTheme()
+
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Bold(true)
+	hunkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Accent))
+	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Success))
+	removeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error))
+	contextStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground))
+
+	lines := strings.Split(diff, "\n")
+	rendered := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if width > 0 {
+			line = ansi.Truncate(line, width, "…")
+		}
+
+		var style lipgloss.Style
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			style = headerStyle
+		case strings.HasPrefix(line, "@@"):
+			style = hunkStyle
+		case strings.HasPrefix(line, "+"):
+			style = addStyle
+		case strings.HasPrefix(line, "-"):
+			style = removeStyle
+		default:
+			style = contextStyle
+		}
+		rendered = append(rendered, style.Render(line))
+	}
+	return rendered
+}
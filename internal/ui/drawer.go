@@ -0,0 +1,360 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// drawerSeverity tells warnings from errors in the message drawer
+type drawerSeverity int
+
+const (
+	severityWarning drawerSeverity = iota
+	severityError
+)
+
+func (s drawerSeverity) String() string {
+	if s == severityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Sources of the messages collected in the drawer
+const (
+	sourceConfig      = "config"
+	sourcePing        = "ping"
+	sourceWrite       = "write"
+	sourceAnnotations = "annotations"
+	sourceMount       = "mount"
+	sourceSnippet     = "snippet"
+	sourceExec        = "exec"
+	sourceHistory     = "history"
+	sourcePermissions = "permissions"
+	sourceKeys        = "keys"
+)
+
+// drawerLines is how many lines of messages the open drawer shows
+const drawerLines = 8
+
+// drawerEntry is a warning or error kept in the drawer until it is dismissed
+type drawerEntry struct {
+	severity drawerSeverity
+	time     time.Time
+	source   string
+	text     string
+	count    int // How many times the same message was reported
+
+	// Blocks declaring the same hosts, which Enter opens the merge view for
+	conflict *config.BlockConflict
+
+	// File ssh refuses for its permissions, which f fixes along with the others
+	permission *config.PermissionProblem
+}
+
+// messageDrawer collects the warnings and errors of the session below the host
+// list. It only takes the keyboard while open.
+type messageDrawer struct {
+	entries  []drawerEntry
+	selected int
+	open     bool
+	viewport viewport.Model
+
+	// Permission problems outside the .ssh directory wait for y after a fix
+	confirmOutside bool
+}
+
+// add records a message, counting a repeat of one still in the drawer instead of
+// listing it again
+func (d *messageDrawer) add(severity drawerSeverity, source, text string) {
+	for i := range d.entries {
+		entry := &d.entries[i]
+		if entry.severity == severity && entry.source == source && entry.text == text {
+			entry.time = time.Now()
+			entry.count++
+			return
+		}
+	}
+	d.entries = append(d.entries, drawerEntry{severity: severity, time: time.Now(), source: source, text: text, count: 1})
+}
+
+// addConflict records a warning about two blocks declaring the same hosts
+func (d *messageDrawer) addConflict(conflict config.BlockConflict) {
+	text := conflict.String()
+	d.add(severityWarning, sourceConfig, text)
+	for i := range d.entries {
+		if d.entries[i].source == sourceConfig && d.entries[i].text == text {
+			d.entries[i].conflict = &conflict
+		}
+	}
+}
+
+// selectedConflict returns the conflict of the selected message, or nil
+func (d *messageDrawer) selectedConflict() *config.BlockConflict {
+	if d.selected >= len(d.entries) {
+		return nil
+	}
+	return d.entries[d.selected].conflict
+}
+
+// dismiss removes the selected message
+func (d *messageDrawer) dismiss() {
+	if d.selected >= len(d.entries) {
+		return
+	}
+	d.entries = append(d.entries[:d.selected:d.selected], d.entries[d.selected+1:]...)
+	d.selected = min(d.selected, max(len(d.entries)-1, 0))
+}
+
+// removeSource removes every message of source, e.g. the lint results of a
+// config that was read again
+func (d *messageDrawer) removeSource(source string) {
+	kept := d.entries[:0:0]
+	for _, entry := range d.entries {
+		if entry.source != source {
+			kept = append(kept, entry)
+		}
+	}
+	d.entries = kept
+	d.selected = min(d.selected, max(len(d.entries)-1, 0))
+}
+
+// hasErrors reports whether any message is an error rather than a warning
+func (d *messageDrawer) hasErrors() bool {
+	for _, entry := range d.entries {
+		if entry.severity == severityError {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh renders the messages into the viewport, scrolled to show the selected one
+func (d *messageDrawer) refresh(width int) {
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.SelectionFg)).
+		Background(lipgloss.Color(theme.SelectionBg))
+
+	d.viewport.Width = width
+	d.viewport.Height = drawerLines
+	if len(d.entries) == 0 {
+		d.viewport.SetContent(mutedStyle.Render("No warnings or errors"))
+		d.viewport.GotoTop()
+		return
+	}
+
+	var lines []string
+	selectedTop, selectedBottom := 0, 0
+	for i, entry := range d.entries {
+		severityStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning))
+		if entry.severity == severityError {
+			severityStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error))
+		}
+		prefix := fmt.Sprintf("%s %-7s %s: ", entry.time.Format("15:04:05"), entry.severity, entry.source)
+		text := entry.text
+		if entry.count > 1 {
+			text = fmt.Sprintf("%s (%dx)", text, entry.count)
+		}
+
+		// Wrap long and multi-line messages under their first line
+		indent := strings.Repeat(" ", ansi.StringWidth(prefix))
+		wrapped := strings.Split(ansi.Wrap(text, max(width-len(indent), 10), " "), "\n")
+		if i == d.selected {
+			selectedTop = len(lines)
+		}
+		for j, line := range wrapped {
+			var rendered string
+			if j == 0 {
+				rendered = prefix + line
+			} else {
+				rendered = indent + line
+			}
+			if i == d.selected {
+				rendered = selectedStyle.Render(rendered + strings.Repeat(" ", max(width-ansi.StringWidth(rendered), 0)))
+			} else if j == 0 {
+				rendered = mutedStyle.Render(entry.time.Format("15:04:05")+" ") +
+					severityStyle.Render(fmt.Sprintf("%-7s", entry.severity)) +
+					mutedStyle.Render(" "+entry.source+": ") + line
+			}
+			lines = append(lines, rendered)
+		}
+		if i == d.selected {
+			selectedBottom = len(lines) - 1
+		}
+	}
+	d.viewport.SetContent(strings.Join(lines, "\n"))
+
+	// Keep the whole selected message in view
+	if selectedTop < d.viewport.YOffset {
+		d.viewport.SetYOffset(selectedTop)
+	} else if selectedBottom >= d.viewport.YOffset+drawerLines {
+		d.viewport.SetYOffset(min(selectedTop, selectedBottom-drawerLines+1))
+	}
+}
+
+// report adds a warning or error to the drawer. It does not replace the toast,
+// which still shows the latest message for a moment.
+func (m *Model) report(severity drawerSeverity, source, text string) {
+	m.drawer.add(severity, source, text)
+	if m.drawer.open {
+		m.drawer.refresh(m.drawerWidth())
+	}
+}
+
+// reportLint replaces the lint results of the config in the drawer with those of hosts
+func (m *Model) reportLint(hosts []config.SSHHost) {
+	m.drawer.removeSource(sourceConfig)
+	if m.browsing() {
+		return
+	}
+	warnings := config.LintHosts(hosts)
+	var files []string
+	if m.configFile != "" {
+		files, _ = config.GetAllConfigFilesFromBase(m.configFile)
+	} else {
+		files, _ = config.GetAllConfigFiles()
+	}
+	warnings = append(warnings, config.LintConfigFiles(files)...)
+	for _, warning := range warnings {
+		m.drawer.add(severityWarning, sourceConfig, warning.String())
+	}
+	for _, conflict := range config.FindBlockConflicts(hosts) {
+		m.drawer.addConflict(conflict)
+	}
+	if m.drawer.open {
+		m.drawer.refresh(m.drawerWidth())
+	}
+}
+
+// toggleDrawer opens or closes the drawer, making room for it below the table
+func (m *Model) toggleDrawer() {
+	m.drawer.open = !m.drawer.open
+	if m.drawer.open {
+		m.drawer.selected = max(len(m.drawer.entries)-1, 0)
+		m.drawer.refresh(m.drawerWidth())
+		m.table.Blur()
+	} else if !m.searchMode {
+		m.table.Focus()
+	}
+	m.updateTableHeight()
+}
+
+// drawerWidth is the width of the messages inside the drawer's border
+func (m *Model) drawerWidth() int {
+	return max(m.getTableWidth()-4, 20)
+}
+
+// drawerHeight is how many lines the open drawer takes, border and help included
+func (m *Model) drawerHeight() int {
+	if !m.drawer.open {
+		return 0
+	}
+	return drawerLines + 3
+}
+
+// handleDrawerKeys handles keys while the drawer is open, leaving the table alone
+func (m Model) handleDrawerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if m.drawer.confirmOutside {
+		m.drawer.confirmOutside = false
+		if key == "y" {
+			cmd := m.fixPermissions(true)
+			m.drawer.refresh(m.drawerWidth())
+			return m, cmd
+		}
+	}
+	switch key {
+	case "esc", "q":
+		m.toggleDrawer()
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.drawer.selected > 0 {
+			m.drawer.selected--
+		}
+	case "down", "j":
+		if m.drawer.selected < len(m.drawer.entries)-1 {
+			m.drawer.selected++
+		}
+	case "home", "g":
+		m.drawer.selected = 0
+	case "end", "G":
+		m.drawer.selected = max(len(m.drawer.entries)-1, 0)
+	case "d", "x", "delete", "backspace":
+		m.drawer.dismiss()
+	case "enter":
+		if conflict := m.drawer.selectedConflict(); conflict != nil {
+			m.openMergeView(*conflict)
+			return m, nil
+		}
+	case "f":
+		if len(m.drawer.permissionProblems()) > 0 {
+			cmd := m.fixPermissions(false)
+			m.drawer.refresh(m.drawerWidth())
+			return m, cmd
+		}
+	default:
+		if kb := m.keyBindings(); kb.ActionForKey(key) == config.ActionMessages {
+			m.toggleDrawer()
+			return m, nil
+		}
+	}
+	m.drawer.refresh(m.drawerWidth())
+	return m, nil
+}
+
+// renderDrawer renders the open drawer
+func (m Model) renderDrawer() string {
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	borderColor := theme.Warning
+	if m.drawer.hasErrors() {
+		borderColor = theme.Error
+	}
+
+	help := fmt.Sprintf("%d messages • ↑/↓: select • d: dismiss • Esc: close", len(m.drawer.entries))
+	if m.drawer.selectedConflict() != nil {
+		help = fmt.Sprintf("%d messages • ↑/↓: select • Enter: merge • d: dismiss • Esc: close", len(m.drawer.entries))
+	} else if problems := m.drawer.permissionProblems(); m.drawer.confirmOutside {
+		help = fmt.Sprintf("Fix %d files outside ~/.ssh too? y: fix • any other key: leave them", len(problems))
+	} else if len(problems) > 0 {
+		help = fmt.Sprintf("%d messages • ↑/↓: select • f: fix permissions • d: dismiss • Esc: close", len(m.drawer.entries))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left,
+		m.drawer.viewport.View(),
+		mutedStyle.Render(ansi.Truncate(help, m.drawerWidth(), "…")),
+	)
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(borderColor)).
+		Padding(0, 1).
+		Width(m.drawerWidth() + 2).
+		Render(body)
+}
+
+// drawerBadge is the count of messages shown in the status bar while the drawer
+// is closed, or "" when there are none
+func (m Model) drawerBadge() string {
+	if m.drawer.open || len(m.drawer.entries) == 0 {
+		return ""
+	}
+	theme := GetCurrentTheme()
+	color := theme.Warning
+	if m.drawer.hasErrors() {
+		color = theme.Error
+	}
+	kb := m.keyBindings()
+	badge := fmt.Sprintf("[%s %d]", kb.KeyForAction(config.ActionMessages), len(m.drawer.entries))
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true).Render(badge)
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDrawerCollectsMessagesUntilDismissed(t *testing.T) {
+	m := createLargeTestModel(10)
+
+	for range 2 {
+		updated, _ := m.Update(pingResultMsg(&connectivity.HostPingResult{
+			HostName: "node-0003",
+			Status:   connectivity.StatusOffline,
+			Error:    errors.New("connection refused"),
+		}))
+		m = updated.(Model)
+	}
+	updated, _ := m.Update(moveFormSubmitMsg{err: errors.New("permission denied")})
+	m = updated.(Model)
+
+	if len(m.drawer.entries) != 2 || m.drawer.entries[0].count != 2 {
+		t.Fatalf("entries = %+v, want the repeated ping failure counted once", m.drawer.entries)
+	}
+	if entry := m.drawer.entries[1]; entry.severity != severityError || entry.source != sourceWrite {
+		t.Errorf("move failure = %+v", entry)
+	}
+	if status := ansi.Strip(m.renderStatusBar()); !strings.Contains(status, "[! 2]") {
+		t.Errorf("status bar = %q, want the message count", status)
+	}
+
+	// While open the drawer takes the keys, and the table keeps its cursor
+	m.table.SetCursor(5)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'!'}})
+	m = updated.(Model)
+	if !m.drawer.open {
+		t.Fatal("Expected ! to open the drawer")
+	}
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "ping: node-0003: connection refused") || !strings.Contains(view, "(2x)") || !strings.Contains(view, "Move failed: permission denied") {
+		t.Errorf("drawer does not show the messages:\n%s", view)
+	}
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyUp}, {Type: tea.KeyRunes, Runes: []rune{'d'}}} {
+		updated, _ = m.Update(key)
+		m = updated.(Model)
+	}
+	if m.table.Cursor() != 5 {
+		t.Errorf("table cursor = %d, want it left alone while the drawer is open", m.table.Cursor())
+	}
+	if len(m.drawer.entries) != 1 || m.drawer.entries[0].source != sourceWrite {
+		t.Errorf("entries after dismissing the first = %+v", m.drawer.entries)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.drawer.open {
+		t.Error("Expected Esc to close the drawer")
+	}
+	if status := ansi.Strip(m.renderStatusBar()); !strings.Contains(status, "[! 1]") {
+		t.Errorf("status bar = %q after dismissing one", status)
+	}
+}
+
+func TestDrawerShowsLintOfReloadedConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	configFile := filepath.Join(tempDir, "config")
+	write := func(content string) {
+		if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Host web\n    HostName 10.0.0.1\n    ProxyJump bastion\n    ProxyCommand nc %h %p\n")
+
+	m := createLargeTestModel(0)
+	m.configFile = configFile
+	if _, err := m.reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.drawer.entries) != 1 || m.drawer.entries[0].source != sourceConfig || !strings.Contains(m.drawer.entries[0].text, "web") {
+		t.Fatalf("entries = %+v, want the proxy conflict of web", m.drawer.entries)
+	}
+
+	// Fixing the config drops the warning on the next reload
+	write("Host web\n    HostName 10.0.0.1\n    ProxyJump bastion\n")
+	if _, err := m.reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.drawer.entries) != 0 {
+		t.Errorf("entries after fixing the config = %+v", m.drawer.entries)
+	}
+}
+
+func TestDrawerFixesPermissionsAskingOutsideSSHDir(t *testing.T) {
+	if !config.PermissionChecksSupported {
+		t.Skip("permission bits are not checked on this platform")
+	}
+	home := t.TempDir()
+	sshDir := filepath.Join(home, ".ssh")
+	inside, outside := filepath.Join(sshDir, "config"), filepath.Join(home, "dotfiles.conf")
+	for _, path := range []string{inside, outside} {
+		os.MkdirAll(filepath.Dir(path), 0700)
+		os.WriteFile(path, []byte("Host x\n"), 0600)
+		os.Chmod(path, 0664)
+	}
+
+	m := createLargeTestModel(3)
+	for _, problem := range config.CheckPermissions(sshDir, []string{inside, outside}, nil) {
+		m.drawer.addPermission(problem)
+	}
+	m.toggleDrawer()
+	if help := ansi.Strip(m.renderDrawer()); !strings.Contains(help, "f: fix permissions") {
+		t.Errorf("drawer help = %q, want the fix key", help)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = updated.(Model)
+	if info, _ := os.Stat(inside); info.Mode().Perm() != 0600 {
+		t.Errorf("config in .ssh has mode %o, want 600", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(outside); info.Mode().Perm() != 0664 {
+		t.Errorf("file outside .ssh has mode %o, want it left until confirmed", info.Mode().Perm())
+	}
+	if !m.drawer.confirmOutside || len(m.drawer.permissionProblems()) != 1 {
+		t.Fatalf("confirmOutside = %v, problems = %v, want to be asked about the file outside", m.drawer.confirmOutside, m.drawer.permissionProblems())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(Model)
+	if info, _ := os.Stat(outside); info.Mode().Perm() != 0600 {
+		t.Errorf("file outside .ssh has mode %o after confirming, want 600", info.Mode().Perm())
+	}
+	if len(m.drawer.entries) != 0 {
+		t.Errorf("entries after fixing = %+v, want none", m.drawer.entries)
+	}
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dryRunModel shows the changes a dry run kept from being written
+type dryRunModel struct {
+	changes []config.ConfigChange
+	offset  int
+	styles  Styles
+	width   int
+	height  int
+}
+
+type dryRunCloseMsg struct{}
+
+// NewDryRun creates the view of changes that were not written
+func NewDryRun(changes []config.ConfigChange, styles Styles, width, height int) *dryRunModel {
+	return &dryRunModel{
+		changes: changes,
+		styles:  styles,
+		width:   width,
+		height:  height,
+	}
+}
+
+// add appends changes made while the view is already open
+func (m *dryRunModel) add(changes []config.ConfigChange) {
+	m.changes = append(m.changes, changes...)
+}
+
+// lines renders the diffs of all changes
+func (m *dryRunModel) lines() []string {
+	var lines []string
+	for _, change := range m.changes {
+		if !change.Changed() {
+			continue
+		}
+		lines = append(lines, renderDiff(strings.TrimRight(change.Diff(), "\n"), m.width-4)...)
+	}
+	return lines
+}
+
+// pageSize returns how many diff lines fit on screen
+func (m *dryRunModel) pageSize() int {
+	// Title, note, blank lines, help and padding
+	return max(m.height-9, 3)
+}
+
+func (m *dryRunModel) Update(msg tea.Msg) (*dryRunModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "enter", "q", "ctrl+c":
+			return m, func() tea.Msg { return dryRunCloseMsg{} }
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+			}
+		case "down", "j":
+			m.offset++
+		case "pgup":
+			m.offset = max(m.offset-m.pageSize(), 0)
+		case "pgdown", " ":
+			m.offset += m.pageSize()
+		}
+	}
+	return m, nil
+}
+
+func (m *dryRunModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Warning))
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	lines := m.lines()
+	pageSize := m.pageSize()
+	m.offset = min(m.offset, max(len(lines)-pageSize, 0))
+	end := min(m.offset+pageSize, len(lines))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("DRY RUN"))
+	b.WriteString("\n\n")
+	if len(lines) == 0 {
+		b.WriteString(infoStyle.Render("The change would leave every file as it is."))
+	} else {
+		b.WriteString(infoStyle.Render("Nothing was written. The change would have been:"))
+		b.WriteString("\n\n")
+		b.WriteString(strings.Join(lines[m.offset:end], "\n"))
+	}
+	b.WriteString("\n\n")
+
+	help := "Enter/Esc: close"
+	if len(lines) > pageSize {
+		help = fmt.Sprintf("lines %d-%d of %d • ↑/↓: scroll • ", m.offset+1, end, len(lines)) + help
+	}
+	b.WriteString(infoStyle.Render(help))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(b.String())
+}
+
+// showDryRunChanges opens the dry-run view for changes that were just kept from being
+// written, or adds them to it when it is already open
+func (m Model) showDryRunChanges() Model {
+	changes := config.TakeDryRunChanges()
+	if len(changes) == 0 {
+		return m
+	}
+	if m.viewMode == ViewDryRun && m.dryRunView != nil {
+		m.dryRunView.add(changes)
+		return m
+	}
+	m.dryRunReturn = m.viewMode
+	m.dryRunView = NewDryRun(changes, m.styles, m.width, m.height)
+	m.viewMode = ViewDryRun
+	return m
+}
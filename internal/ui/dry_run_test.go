@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDryRunShowsDiffInsteadOfWriting(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "config")
+	original := "Host web\n    HostName web.example.com\n\nHost db\n    HostName db.example.com\n"
+	if err := os.WriteFile(configFile, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config.SetDryRun(true)
+	defer config.SetDryRun(false)
+
+	m := createLargeTestModel(3)
+	m.dryRun = true
+	m.table.Focus()
+
+	// The delete runs in a command; its result message reaches Update afterwards
+	if err := config.DeleteSSHHostFromFile("db", configFile); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+	newModel, _ := m.Update(sshConnectionResultMsg{})
+	m = newModel.(Model)
+
+	if m.viewMode != ViewDryRun {
+		t.Fatalf("Expected the dry-run view, got view mode %d", m.viewMode)
+	}
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "DRY RUN") || !strings.Contains(view, "-Host db") {
+		t.Errorf("Expected the view to show the diff of the delete, got:\n%s", view)
+	}
+	if content, _ := os.ReadFile(configFile); string(content) != original {
+		t.Errorf("Expected the config to be unchanged, got:\n%s", content)
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+	if m.viewMode != ViewList || m.dryRunView != nil {
+		t.Errorf("Expected Enter to return to the list, got view mode %d", m.viewMode)
+	}
+}
@@ -0,0 +1,375 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xvertile/sshc/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dualBrowserMinWidth is the narrowest terminal that shows both panes side by side.
+// Narrower terminals show one pane at a time.
+const dualBrowserMinWidth = 110
+
+// dualBrowserModel shows two remote browsers and copies files between their hosts
+type dualBrowserModel struct {
+	hosts      [2]string
+	candidates []string // Hosts offered as the second host
+	configFile string
+
+	// Picking the second host
+	picking    bool
+	pickCursor int
+	pickFilter string
+
+	panes  [2]*remoteBrowserModel
+	focus  int
+	status string
+	err    string
+
+	styles Styles
+	width  int
+	height int
+}
+
+// Messages for communication with parent model
+type dualBrowserCloseMsg struct{}
+
+// dualPaneMsg carries a message produced by one of the panes back to that pane
+type dualPaneMsg struct {
+	pane int
+	msg  tea.Msg
+}
+
+// dualCopyDoneMsg is sent when scp -3 exits
+type dualCopyDoneMsg struct {
+	description string
+	err         error
+}
+
+// NewDualBrowser creates a dual remote browser for host, starting with a picker for the second host
+func NewDualBrowser(host string, candidates []string, configFile string, styles Styles, width, height int) *dualBrowserModel {
+	var others []string
+	for _, candidate := range candidates {
+		if candidate != host {
+			others = append(others, candidate)
+		}
+	}
+
+	return &dualBrowserModel{
+		hosts:      [2]string{host},
+		candidates: others,
+		configFile: configFile,
+		picking:    true,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+}
+
+func (m *dualBrowserModel) Init() tea.Cmd {
+	return nil
+}
+
+// sideBySide reports whether both panes fit next to each other
+func (m *dualBrowserModel) sideBySide() bool {
+	return m.width >= dualBrowserMinWidth
+}
+
+// paneWidth returns the width of a pane's panel, or 0 to size it to its content
+func (m *dualBrowserModel) paneWidth() int {
+	if !m.sideBySide() {
+		return 0
+	}
+	// Each panel adds a 2 column border around its width, plus one column between them
+	return (m.width-1)/2 - 2
+}
+
+// filteredCandidates returns the candidate hosts matching the picker filter
+func (m *dualBrowserModel) filteredCandidates() []string {
+	if m.pickFilter == "" {
+		return m.candidates
+	}
+	var filtered []string
+	for _, candidate := range m.candidates {
+		if strings.Contains(strings.ToLower(candidate), strings.ToLower(m.pickFilter)) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+// wrapPaneCmd tags the message produced by cmd with the pane it belongs to
+func wrapPaneCmd(pane int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return dualPaneMsg{pane: pane, msg: cmd()}
+	}
+}
+
+// openPanes creates both browsers once the second host is known
+func (m *dualBrowserModel) openPanes(second string) tea.Cmd {
+	m.hosts[1] = second
+	m.picking = false
+	m.focus = 0
+
+	var cmds []tea.Cmd
+	for i, host := range m.hosts {
+		m.panes[i] = NewRemoteBrowser(host, "~", m.configFile, BrowseFiles, m.styles, m.width, m.height)
+		cmds = append(cmds, wrapPaneCmd(i, m.panes[i].Init()))
+	}
+	return tea.Batch(cmds...)
+}
+
+// close closes the SFTP sessions of both panes
+func (m *dualBrowserModel) close() tea.Cmd {
+	for _, pane := range m.panes {
+		if pane != nil && pane.session != nil {
+			pane.session.Close()
+			pane.session = nil
+		}
+	}
+	return func() tea.Msg { return dualBrowserCloseMsg{} }
+}
+
+// copyRequest builds the request copying path from a pane to the directory open in the other pane
+func (m *dualBrowserModel) copyRequest(from int, filePath string) *transfer.RemoteCopyRequest {
+	to := 1 - from
+	return &transfer.RemoteCopyRequest{
+		SourceHost: m.hosts[from],
+		SourcePath: filePath,
+		DestHost:   m.hosts[to],
+		DestPath:   strings.TrimSuffix(m.panes[to].currentDir, "/") + "/",
+		ConfigFile: m.configFile,
+	}
+}
+
+// startCopy hands the terminal to scp -3, which shows its own progress meter
+func (m *dualBrowserModel) startCopy(from int, filePath string) tea.Cmd {
+	req := m.copyRequest(from, filePath)
+	description := fmt.Sprintf("%s:%s → %s:%s", req.SourceHost, req.SourcePath, req.DestHost, req.DestPath)
+
+	cmd := &hintedCommand{Cmd: req.BuildSCPCommand(), hint: "Copying " + description}
+	return execHinted(cmd, func(err error) tea.Msg {
+		return dualCopyDoneMsg{description: description, err: err}
+	})
+}
+
+// reloadPanes reconnects both panes and lists their current directories again
+func (m *dualBrowserModel) reloadPanes() tea.Cmd {
+	var cmds []tea.Cmd
+	for i, pane := range m.panes {
+		if pane.session != nil {
+			pane.session.Close()
+			pane.session = nil
+		}
+		pane.loading = true
+		cmds = append(cmds, wrapPaneCmd(i, pane.loadDirectory(pane.currentDir)))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *dualBrowserModel) Update(msg tea.Msg) (*dualBrowserModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		for _, pane := range m.panes {
+			if pane != nil {
+				pane.width = m.width
+				pane.height = m.height
+				pane.styles = m.styles
+			}
+		}
+		return m, nil
+
+	case dualPaneMsg:
+		pane := m.panes[msg.pane]
+		if pane == nil {
+			return m, nil
+		}
+
+		if result, ok := msg.msg.(remoteBrowserResultMsg); ok {
+			// The browser closes its session when it finishes
+			pane.session = nil
+			if !result.selected {
+				return m, m.close()
+			}
+			return m, m.startCopy(msg.pane, result.path)
+		}
+
+		newPane, cmd := pane.Update(msg.msg)
+		m.panes[msg.pane] = newPane
+		return m, wrapPaneCmd(msg.pane, cmd)
+
+	case dualCopyDoneMsg:
+		if msg.err != nil {
+			m.err = fmt.Sprintf("Copy failed: %v", msg.err)
+			m.status = ""
+		} else {
+			m.status = "Copied " + msg.description
+			m.err = ""
+		}
+		return m, m.reloadPanes()
+
+	case tea.KeyMsg:
+		if m.picking {
+			return m.updatePicker(msg)
+		}
+
+		if msg.String() == "tab" {
+			m.focus = 1 - m.focus
+			return m, nil
+		}
+
+		newPane, cmd := m.panes[m.focus].Update(msg)
+		m.panes[m.focus] = newPane
+		return m, wrapPaneCmd(m.focus, cmd)
+	}
+
+	return m, nil
+}
+
+// updatePicker handles keys while choosing the second host
+func (m *dualBrowserModel) updatePicker(msg tea.KeyMsg) (*dualBrowserModel, tea.Cmd) {
+	filtered := m.filteredCandidates()
+
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		return m, m.close()
+
+	case "enter":
+		if m.pickCursor < len(filtered) {
+			return m, m.openPanes(filtered[m.pickCursor])
+		}
+		return m, nil
+
+	case "up", "ctrl+p":
+		if m.pickCursor > 0 {
+			m.pickCursor--
+		}
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.pickCursor < len(filtered)-1 {
+			m.pickCursor++
+		}
+		return m, nil
+
+	case "backspace":
+		if m.pickFilter != "" {
+			_, size := utf8.DecodeLastRuneInString(m.pickFilter)
+			m.pickFilter = m.pickFilter[:len(m.pickFilter)-size]
+			m.pickCursor = 0
+		}
+		return m, nil
+
+	default:
+		if msg.Type == tea.KeyRunes {
+			m.pickFilter += string(msg.Runes)
+			m.pickCursor = 0
+		}
+		return m, nil
+	}
+}
+
+func (m *dualBrowserModel) View() string {
+	if m.picking {
+		return m.pickerView()
+	}
+
+	theme := GetCurrentTheme()
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	var panels string
+	if m.sideBySide() {
+		rendered := make([]string, len(m.panes))
+		for i, pane := range m.panes {
+			border := theme.Muted
+			if i == m.focus {
+				border = theme.Primary
+			}
+			rendered[i] = pane.renderPanel(border, m.paneWidth())
+		}
+		panels = lipgloss.JoinHorizontal(lipgloss.Top, rendered[0], " ", rendered[1])
+	} else {
+		// Too narrow for two panes: show the focused one and name the other
+		other := 1 - m.focus
+		panels = lipgloss.JoinVertical(lipgloss.Left,
+			m.panes[m.focus].renderPanel(theme.Primary, 0),
+			helpStyle.Render(fmt.Sprintf("Other pane: %s:%s", m.hosts[other], m.panes[other].currentDir)),
+		)
+	}
+
+	var statusLine string
+	switch {
+	case m.err != "":
+		statusLine = m.styles.Error.Render(m.err)
+	case m.status != "":
+		statusLine = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Success)).Render(m.status)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		m.styles.Header.Render(fmt.Sprintf("Copy between %s and %s", m.hosts[0], m.hosts[1])),
+		"",
+		panels,
+		statusLine,
+		helpStyle.Render(fmt.Sprintf("Tab: switch pane • Enter on a file: copy to %s • Esc: close", m.hosts[1-m.focus])),
+	)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+// pickerView renders the list of hosts to pair with the first one
+func (m *dualBrowserModel) pickerView() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	filtered := m.filteredCandidates()
+	visible := max(m.height-14, 5)
+	start := 0
+	if m.pickCursor >= visible {
+		start = m.pickCursor - visible + 1
+	}
+	end := min(start+visible, len(filtered))
+
+	var items []string
+	for i := start; i < end; i++ {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground)).Padding(0, 2)
+		if i == m.pickCursor {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 2)
+		}
+		items = append(items, style.Render(filtered[i]))
+	}
+	if len(items) == 0 {
+		items = append(items, helpStyle.Render("  No matching hosts"))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Copy between "+m.hosts[0]+" and..."),
+		"",
+		"Filter: "+m.pickFilter+"_",
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		helpStyle.Render("Type to filter • ↑/↓: select • Enter: open both • Esc: cancel"),
+	)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, containerStyle.Render(content))
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDualBrowserPicksSecondHost(t *testing.T) {
+	m := NewDualBrowser("web", []string{"web", "db", "backup"}, "/tmp/ssh_config", NewStyles(120), 120, 40)
+	if slices.Contains(m.candidates, "web") {
+		t.Fatalf("Expected the first host to be excluded from the candidates, got %v", m.candidates)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("back")})
+	if got := m.filteredCandidates(); len(got) != 1 || got[0] != "backup" {
+		t.Fatalf("Expected the filter to leave backup, got %v", got)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.picking || cmd == nil {
+		t.Fatal("Expected enter to open both panes")
+	}
+	if m.panes[0].host != "web" || m.panes[1].host != "backup" {
+		t.Errorf("Unexpected pane hosts %q and %q", m.panes[0].host, m.panes[1].host)
+	}
+
+	// Tab moves focus without reaching the browsers
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.focus != 1 {
+		t.Errorf("Expected tab to focus the second pane, got %d", m.focus)
+	}
+}
+
+func TestDualBrowserPickFilterBackspaceRemovesARune(t *testing.T) {
+	m := NewDualBrowser("web", []string{"web", "dépôt", "db"}, "/tmp/ssh_config", NewStyles(120), 120, 40)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("dépô")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if m.pickFilter != "dép" {
+		t.Errorf("Expected backspace to remove the last character, got %q", m.pickFilter)
+	}
+	if got := m.filteredCandidates(); len(got) != 1 || got[0] != "dépôt" {
+		t.Errorf("Expected the filter to still match dépôt, got %v", got)
+	}
+}
+
+func TestDualBrowserCopyRequest(t *testing.T) {
+	m := NewDualBrowser("web", []string{"backup"}, "/tmp/ssh_config", NewStyles(120), 120, 40)
+	m.openPanes("backup")
+	m.panes[0].currentDir = "/var/log"
+	m.panes[1].currentDir = "/srv/logs/"
+
+	req := m.copyRequest(0, "/var/log/app.log")
+	cmd := req.BuildSCPCommand()
+	want := []string{"scp", "-3", "-F", "/tmp/ssh_config", "-o", "RemoteCommand=none", "-o", "RequestTTY=no", "web:/var/log/app.log", "backup:/srv/logs/"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("Expected %q, got %q", want, cmd.Args)
+	}
+
+	req = m.copyRequest(1, "/srv/logs/old.log")
+	if req.SourceHost != "backup" || req.DestHost != "web" || req.DestPath != "/var/log/" {
+		t.Errorf("Unexpected reverse copy request %+v", req)
+	}
+}
+
+func TestDualBrowserCollapsesWhenNarrow(t *testing.T) {
+	m := NewDualBrowser("web", []string{"backup"}, "", NewStyles(120), 120, 40)
+	m.openPanes("backup")
+	m.panes[0].loading = false
+	m.panes[1].loading = false
+
+	if !m.sideBySide() || !strings.Contains(m.View(), "Remote Browser: backup") {
+		t.Error("Expected both panes side by side on a wide terminal")
+	}
+
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	view := m.View()
+	if m.sideBySide() || strings.Contains(view, "Remote Browser: backup") {
+		t.Error("Expected only the focused pane on a narrow terminal")
+	}
+	if !strings.Contains(view, "Other pane: backup:") {
+		t.Error("Expected the narrow layout to name the other pane")
+	}
+}
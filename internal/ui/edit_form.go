@@ -2,11 +2,13 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/validation"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -20,14 +22,29 @@ const (
 type editFormSubmitMsg struct {
 	hostname string
 	err      error
+	form     *editFormModel // Form of the change, shown again if writing it fails after it closed
 }
 
 type editFormCancelMsg struct{}
 
+// editFormPreviewMsg carries the computed change awaiting confirmation
+type editFormPreviewMsg struct {
+	hostname string
+	change   *config.ConfigChange
+}
+
+// editOptionsField is the property index of the SSH Options, which are edited in
+// a text area rather than in m.inputs
+const editOptionsField = 5
+
+// editOptionsHeight is how many lines of SSH Options are shown at once
+const editOptionsHeight = 4
+
 type editFormModel struct {
 	hostInputs       []textinput.Model // Support for multiple hosts
 	inputs           []textinput.Model
-	focusArea        int // 0=hosts, 1=properties
+	options          textarea.Model // SSH Options, one per line in config format
+	focusArea        int            // 0=hosts, 1=properties
 	focused          int
 	currentTab       int // 0=General, 1=Advanced (only applies when focusArea == focusAreaProperties)
 	err              string
@@ -39,6 +56,19 @@ type editFormModel struct {
 	actualConfigFile string          // Actual config file to use (either configFile or host.SourceFile)
 	width            int
 	height           int
+
+	// RemoteCommand programs that need a TTY, defaults to config.DefaultInteractiveCommands
+	interactiveCommands []string
+
+	// Change shown for confirmation before it is written
+	preview         *config.ConfigChange
+	previewHostname string
+	previewOffset   int
+
+	// Settings the host gets from wildcard blocks, shown greyed out below the form
+	inherited       []config.EffectiveSetting
+	showInherited   bool
+	inheritedCursor int
 }
 
 // NewEditForm creates a new edit form model that supports both single and multi-host editing
@@ -92,7 +122,7 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 		}
 	}
 
-	inputs := make([]textinput.Model, 9) // Increased from 8 to 9 for RequestTTY
+	inputs := make([]textinput.Model, 13)
 
 	// Hostname input
 	inputs[0] = textinput.New()
@@ -129,14 +159,19 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 	inputs[4].Width = 30
 	inputs[4].SetValue(host.ProxyJump)
 
-	// Options input
+	// Options are edited in the text area below, this input stays unused
 	inputs[5] = textinput.New()
-	inputs[5].Placeholder = "-o StrictHostKeyChecking=no"
-	inputs[5].CharLimit = 200
-	inputs[5].Width = 50
-	if host.Options != "" {
-		inputs[5].SetValue(config.FormatSSHOptionsForCommand(host.Options))
-	}
+
+	// Options text area, the lines are written to the config verbatim
+	options := textarea.New()
+	options.Placeholder = "StrictHostKeyChecking no"
+	options.ShowLineNumbers = false
+	options.Prompt = ""
+	options.CharLimit = 0
+	options.SetWidth(70)
+	options.SetHeight(editOptionsHeight)
+	options.SetValue(host.Options)
+	options.Blur()
 
 	// Tags input
 	inputs[6] = textinput.New()
@@ -161,9 +196,37 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 	inputs[8].Width = 30
 	inputs[8].SetValue(host.RequestTTY)
 
-	return &editFormModel{
+	// Expires input
+	inputs[9] = textinput.New()
+	inputs[9].Placeholder = "YYYY-MM-DD (optional)"
+	inputs[9].CharLimit = 10
+	inputs[9].Width = 30
+	inputs[9].SetValue(host.Expires)
+
+	// ProxyCommand input
+	inputs[10] = textinput.New()
+	inputs[10].Placeholder = "ssh -W %h:%p bastion"
+	inputs[10].CharLimit = 300
+	inputs[10].Width = 70
+	inputs[10].SetValue(host.ProxyCommand)
+
+	// Description input
+	inputs[11] = textinput.New()
+	inputs[11].Placeholder = "primary LB, eu-west"
+	inputs[11].CharLimit = 200
+	inputs[11].Width = 50
+	inputs[11].SetValue(host.Description)
+
+	// Environment input, filled in from the app config by the caller
+	inputs[12] = textinput.New()
+	inputs[12].Placeholder = "TERM=vt100 LANG=C.UTF-8"
+	inputs[12].CharLimit = 300
+	inputs[12].Width = 50
+
+	m := &editFormModel{
 		hostInputs:       hostInputs,
 		inputs:           inputs,
+		options:          options,
 		focusArea:        focusAreaHosts, // Start with hosts focused for multi-host editing
 		focused:          0,
 		currentTab:       0, // Start on General tab
@@ -175,7 +238,9 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 		styles:           styles,
 		width:            width,
 		height:           height,
-	}, nil
+	}
+	m.loadInheritedSettings()
+	return m, nil
 }
 
 func (m *editFormModel) Init() tea.Cmd {
@@ -235,12 +300,15 @@ func (m *editFormModel) updateFocus() tea.Cmd {
 	for i := range m.inputs {
 		m.inputs[i].Blur()
 	}
+	m.options.Blur()
 
 	// Focus the appropriate input
 	if m.focusArea == focusAreaHosts {
 		if m.focused < len(m.hostInputs) {
 			m.hostInputs[m.focused].Focus()
 		}
+	} else if m.focused == editOptionsField {
+		return m.options.Focus()
 	} else {
 		if m.focused < len(m.inputs) {
 			m.inputs[m.focused].Focus()
@@ -254,19 +322,19 @@ func (m *editFormModel) updateFocus() tea.Cmd {
 func (m *editFormModel) getPropertiesForCurrentTab() []int {
 	switch m.currentTab {
 	case 0: // General
-		return []int{0, 1, 2, 3, 4, 6} // hostname, user, port, identity, proxyjump, tags
+		return []int{0, 1, 2, 3, 4, 11, 6, 9} // hostname, user, port, identity, proxyjump, description, tags, expires
 	case 1: // Advanced
-		return []int{5, 7, 8} // options, remotecommand, requesttty
+		return []int{5, 10, 7, 8, 12} // options, proxycommand, remotecommand, requesttty, environment
 	default:
-		return []int{0, 1, 2, 3, 4, 6}
+		return []int{0, 1, 2, 3, 4, 11, 6, 9}
 	}
 }
 
 // getFirstPropertyForTab returns the first property index for a given tab
 func (m *editFormModel) getFirstPropertyForTab(tab int) int {
-	properties := []int{0, 1, 2, 3, 4, 6} // General tab
+	properties := []int{0, 1, 2, 3, 4, 11, 6, 9} // General tab
 	if tab == 1 {
-		properties = []int{5, 7, 8} // Advanced tab
+		properties = []int{5, 10, 7, 8, 12} // Advanced tab
 	}
 	if len(properties) > 0 {
 		return properties[0]
@@ -364,14 +432,13 @@ func (m *editFormModel) getMinimumHeight() int {
 	// Tabs: 1 line + 2 newlines = 3
 	tabLines := 3
 	// Fields in current tab
-	var fieldsCount int
-	if m.currentTab == 0 {
-		fieldsCount = 6 // 6 fields in general tab
-	} else {
-		fieldsCount = 3 // 3 fields in advanced tab
-	}
+	fieldsCount := len(m.getPropertiesForCurrentTab())
 	// Each field: reduced from 4 to 3 lines per field
 	fieldsLines := fieldsCount * 3
+	if m.currentTab == 1 {
+		// The SSH Options text area is taller than an input, and has the presets below it
+		fieldsLines += editOptionsHeight - 1 + 2
+	}
 	// Help text: 3 lines
 	helpLines := 3
 	// Error message space when needed: 2 lines
@@ -380,7 +447,7 @@ func (m *editFormModel) getMinimumHeight() int {
 		errorLines = 2
 	}
 
-	return titleLines + configLines + hostSectionLines + hostLines + propertiesSectionLines + tabLines + fieldsLines + helpLines + errorLines + 1 // +1 minimal safety margin
+	return titleLines + configLines + hostSectionLines + hostLines + propertiesSectionLines + tabLines + fieldsLines + m.inheritedLines() + helpLines + errorLines + 1 // +1 minimal safety margin
 }
 
 // isHeightSufficient checks if the current terminal height is sufficient
@@ -427,6 +494,13 @@ func (m *editFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 	case tea.KeyMsg:
+		if m.preview != nil {
+			return m, m.updatePreview(msg)
+		}
+		if m.showInherited {
+			return m, m.updateInherited(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			m.err = ""
@@ -455,8 +529,40 @@ func (m *editFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.updateFocus()
 
 		case "tab", "shift+tab", "enter", "up", "down":
+			if m.optionsFocused() && m.optionsTakeKey(msg.String()) {
+				break
+			}
 			return m, m.handleEditNavigation(msg.String())
 
+		case "ctrl+o":
+			// Show the settings inherited from wildcard blocks
+			if len(m.inherited) > 0 {
+				m.showInherited = true
+				m.inheritedCursor = 0
+				for i := range m.inputs {
+					m.inputs[i].Blur()
+				}
+				m.options.Blur()
+				for i := range m.hostInputs {
+					m.hostInputs[i].Blur()
+				}
+			}
+			return m, nil
+
+		case "ctrl+r":
+			// Cycle the keepalive and timeout preset written into the SSH Options
+			if m.currentTab == 1 {
+				m.cycleRobustnessPreset()
+			}
+			return m, nil
+
+		case "ctrl+l":
+			// Switch the algorithms written into the SSH Options between the defaults and legacy
+			if m.currentTab == 1 {
+				m.cycleAlgorithmPreset()
+			}
+			return m, nil
+
 		case "ctrl+a":
 			// Add a new host input
 			return m, m.addHostInput()
@@ -468,9 +574,17 @@ func (m *editFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case editFormPreviewMsg:
+		m.err = ""
+		m.preview = msg.change
+		m.previewHostname = msg.hostname
+		m.previewOffset = 0
+		return m, nil
+
 	case editFormSubmitMsg:
 		if msg.err != nil {
-			m.err = msg.err.Error()
+			m.err = saveErrorText(msg.err)
+			m.preview = nil
 		} else {
 			// Success: let the wrapper handle this
 			// In TUI mode, this will be handled by the parent
@@ -493,10 +607,57 @@ func (m *editFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	cmds = append(cmds, propCmd...)
 
+	if m.optionsFocused() {
+		var cmd tea.Cmd
+		m.options, cmd = m.options.Update(msg)
+		cmds = append(cmds, cmd)
+		// Options pasted from a command line are converted to config lines
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.Paste {
+			m.convertPastedOptions()
+		}
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+// optionsFocused reports whether the SSH Options text area has the keyboard
+func (m *editFormModel) optionsFocused() bool {
+	return m.focusArea == focusAreaProperties && m.focused == editOptionsField
+}
+
+// optionsTakeKey reports whether the SSH Options text area handles a navigation
+// key itself: Enter starts a new line, and the arrows move between its lines,
+// leaving the field only from its first or last line
+func (m *editFormModel) optionsTakeKey(key string) bool {
+	switch key {
+	case "enter":
+		return true
+	case "up":
+		return m.options.Line() > 0
+	case "down":
+		return m.options.Line() < m.options.LineCount()-1
+	}
+	return false
+}
+
+// convertPastedOptions rewrites "-o Key=value" lines of the SSH Options to
+// "Key value" lines, leaving the cursor at the end
+func (m *editFormModel) convertPastedOptions() {
+	value := m.options.Value()
+	for _, line := range strings.Split(value, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "-o") {
+			m.options.SetValue(config.NormalizeSSHOptions(value))
+			m.options.CursorEnd()
+			return
+		}
+	}
+}
+
 func (m *editFormModel) View() string {
+	if m.preview != nil {
+		return m.renderPreview()
+	}
+
 	// Check if terminal height is sufficient
 	if !m.isHeightSufficient() {
 		return m.renderHeightWarning()
@@ -513,7 +674,7 @@ func (m *editFormModel) View() string {
 	// Config file info
 	if m.host != nil && m.host.SourceFile != "" {
 		infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
-		b.WriteString(infoStyle.Render("Config: " + formatConfigFile(m.host.SourceFile)))
+		b.WriteString(infoStyle.Render("Config: " + formatConfigFileTarget(m.host.SourceFile)))
 		b.WriteString("\n\n")
 	}
 
@@ -554,6 +715,11 @@ func (m *editFormModel) View() string {
 		b.WriteString(m.renderEditAdvancedTab())
 	}
 
+	if inherited := m.renderInherited(); inherited != "" {
+		b.WriteString(inherited)
+		b.WriteString("\n")
+	}
+
 	// Error message
 	if m.err != "" {
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
@@ -570,7 +736,11 @@ func (m *editFormModel) View() string {
 		b.WriteString(helpStyle.Render("↑/↓: navigate • Ctrl+J/K: tabs • Ctrl+A: add host"))
 	}
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Ctrl+S: save • Esc: cancel"))
+	if m.showInherited {
+		b.WriteString(helpStyle.Render("↑/↓: select • Enter: copy into this host • Ctrl+O: hide • Ctrl+S: save"))
+	} else {
+		b.WriteString(helpStyle.Render("Ctrl+S: save • Esc: cancel"))
+	}
 
 	content := b.String()
 
@@ -633,7 +803,9 @@ func (m *editFormModel) renderEditGeneralTab() string {
 		{2, "Port", false},
 		{3, "Identity File", false},
 		{4, "ProxyJump", false},
+		{11, "Description", false},
 		{6, "Tags", false},
+		{9, "Expires", false},
 	}
 
 	for _, field := range fields {
@@ -652,6 +824,11 @@ func (m *editFormModel) renderEditGeneralTab() string {
 		}
 		b.WriteString(m.inputs[field.index].View())
 		b.WriteString("\n")
+		if field.index == 4 {
+			if matches := renderHostMatches(m.inputs[field.index]); matches != "" {
+				b.WriteString(strings.Repeat(" ", 19) + matches + "\n")
+			}
+		}
 	}
 
 	return b.String()
@@ -670,26 +847,77 @@ func (m *editFormModel) renderEditAdvancedTab() string {
 		label string
 	}{
 		{5, "SSH Options"},
+		{10, "ProxyCommand"},
 		{7, "Remote Command"},
 		{8, "Request TTY"},
+		{12, "Environment"},
 	}
 
 	for _, field := range fields {
+		var label string
 		if m.focusArea == focusAreaProperties && m.focused == field.index {
-			b.WriteString(focusedLabelStyle.Render(field.label))
-			b.WriteString(" ")
-			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Render("> "))
+			label = focusedLabelStyle.Render(field.label) + " " +
+				lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Render("> ")
 		} else {
-			b.WriteString(labelStyle.Render(field.label))
-			b.WriteString("   ")
+			label = labelStyle.Render(field.label) + "   "
+		}
+		if field.index == editOptionsField {
+			// The text area's lines line up under its first one
+			b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, label, m.options.View()))
+		} else {
+			b.WriteString(label)
+			b.WriteString(m.inputs[field.index].View())
 		}
-		b.WriteString(m.inputs[field.index].View())
 		b.WriteString("\n")
+
+		if field.index == editOptionsField {
+			b.WriteString(m.renderRobustness())
+			b.WriteString(m.renderAlgorithms())
+		}
+
+		// An interactive RemoteCommand without a TTY exits as soon as it starts
+		if field.index == 8 {
+			if hint := m.requestTTYHint(); hint != "" {
+				warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning))
+				b.WriteString(strings.Repeat(" ", 19))
+				b.WriteString(warningStyle.Render(hint))
+				b.WriteString("\n")
+			}
+		}
+
+		// ProxyCommand is run by the shell, remind what the ssh tokens expand to
+		if field.index == 10 && m.focusArea == focusAreaProperties && m.focused == field.index {
+			hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Italic(true)
+			b.WriteString(strings.Repeat(" ", 19))
+			b.WriteString(hintStyle.Render("%h = target host, %p = target port, %r = remote user"))
+			b.WriteString("\n")
+		}
+
+		if field.index == 12 && m.focusArea == focusAreaProperties && m.focused == field.index {
+			hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Italic(true)
+			b.WriteString(strings.Repeat(" ", 19))
+			b.WriteString(hintStyle.Render("TERM is set locally, others are sent with SetEnv or SendEnv"))
+			b.WriteString("\n")
+		}
 	}
 
 	return b.String()
 }
 
+// requestTTYHint suggests RequestTTY yes when the RemoteCommand looks interactive.
+// Only the edit form needs it: the add form has no RemoteCommand or RequestTTY field.
+func (m *editFormModel) requestTTYHint() string {
+	interactive := m.interactiveCommands
+	if interactive == nil {
+		interactive = config.DefaultInteractiveCommands
+	}
+	remoteCommand := m.inputs[7].Value()
+	if !validation.RemoteCommandNeedsTTY(remoteCommand, m.inputs[8].Value(), interactive) {
+		return ""
+	}
+	return fmt.Sprintf("%s needs a terminal: set Request TTY to yes", validation.InteractiveRemoteCommand(remoteCommand, interactive))
+}
+
 // Standalone wrapper for edit form
 type standaloneEditForm struct {
 	*editFormModel
@@ -699,10 +927,14 @@ func (m standaloneEditForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case editFormSubmitMsg:
 		if msg.err != nil {
-			m.editFormModel.err = msg.err.Error()
+			m.editFormModel.err = saveErrorText(msg.err)
+			m.editFormModel.preview = nil
 			return m, nil
 		} else {
-			// Success: quit the program
+			// Success: save the environment overrides and quit the program
+			if appConfig, err := config.LoadAppConfig(); err == nil && m.editFormModel.saveHostEnv(appConfig) {
+				config.SaveAppConfig(appConfig)
+			}
 			return m, tea.Quit
 		}
 	case editFormCancelMsg:
@@ -721,6 +953,9 @@ func RunEditForm(hostName string, configFile string) error {
 	if err != nil {
 		return err
 	}
+	if appConfig, err := config.LoadAppConfig(); err == nil {
+		editForm.inputs[12].SetValue(config.FormatHostEnv(appConfig.HostEnvFor(hostName)))
+	}
 
 	m := standaloneEditForm{editForm}
 	p := tea.NewProgram(m, tea.WithAltScreen())
@@ -730,15 +965,7 @@ func RunEditForm(hostName string, configFile string) error {
 
 func (m *editFormModel) submitEditForm() tea.Cmd {
 	return func() tea.Msg {
-		// Collect host names
-		var hostNames []string
-		for _, input := range m.hostInputs {
-			name := strings.TrimSpace(input.Value())
-			if name != "" {
-				hostNames = append(hostNames, name)
-			}
-		}
-
+		hostNames := m.hostNames()
 		if len(hostNames) == 0 {
 			return editFormSubmitMsg{err: fmt.Errorf("at least one host name is required")}
 		}
@@ -749,9 +976,13 @@ func (m *editFormModel) submitEditForm() tea.Cmd {
 		port := strings.TrimSpace(m.inputs[2].Value())          // portInput
 		identity := strings.TrimSpace(m.inputs[3].Value())      // identityInput
 		proxyJump := strings.TrimSpace(m.inputs[4].Value())     // proxyJumpInput
-		options := strings.TrimSpace(m.inputs[5].Value())       // optionsInput
+		proxyCommand := strings.TrimSpace(m.inputs[10].Value()) // proxyCommandInput
 		remoteCommand := strings.TrimSpace(m.inputs[7].Value()) // remoteCommandInput
 		requestTTY := strings.TrimSpace(m.inputs[8].Value())    // requestTTYInput
+		expires := strings.TrimSpace(m.inputs[9].Value())       // expiresInput
+
+		// Options are one per line; any still in command line format are converted
+		options := config.NormalizeSSHOptions(m.options.Value())
 
 		// Set defaults
 		if port == "" {
@@ -768,8 +999,29 @@ func (m *editFormModel) submitEditForm() tea.Cmd {
 			if err := validation.ValidateHost(hostName, hostname, port, identity); err != nil {
 				return editFormSubmitMsg{err: err}
 			}
+			// A renamed or added name must not be a pattern; existing names are kept as they are
+			if !slices.Contains(m.originalHosts, hostName) {
+				if err := validation.ValidateNewHostName(hostName); err != nil {
+					return editFormSubmitMsg{err: err}
+				}
+			}
 		}
 
+		if err := validation.ValidateSSHOptions(options); err != nil {
+			return editFormSubmitMsg{err: err}
+		}
+
+		if !validation.ValidateExpiryDate(expires) {
+			return editFormSubmitMsg{err: fmt.Errorf("invalid expiry date %q: use YYYY-MM-DD", expires)}
+		}
+
+		if _, err := config.ParseHostEnv(m.inputs[12].Value()); err != nil {
+			return editFormSubmitMsg{err: err}
+		}
+
+		// The description is stored in a single comment line
+		description := config.SanitizeDescription(m.inputs[11].Value()) // descriptionInput
+
 		// Parse tags
 		tagsStr := strings.TrimSpace(m.inputs[6].Value()) // tagsInput
 		var tags []string
@@ -789,26 +1041,155 @@ func (m *editFormModel) submitEditForm() tea.Cmd {
 			Port:          port,
 			Identity:      identity,
 			ProxyJump:     proxyJump,
+			ProxyCommand:  proxyCommand,
 			Options:       options,
 			RemoteCommand: remoteCommand,
 			RequestTTY:    requestTTY,
+			Description:   description,
 			Tags:          tags,
+			Expires:       expires,
 		}
 
+		// Compute the change so it can be reviewed before anything is written
+		var change *config.ConfigChange
 		var err error
 		if len(hostNames) == 1 && len(m.originalHosts) == 1 {
 			// Single host editing
 			commonHost.Name = hostNames[0]
-			if m.actualConfigFile != "" {
-				err = config.UpdateSSHHostInFile(m.originalName, commonHost, m.actualConfigFile)
-			} else {
-				err = config.UpdateSSHHost(m.originalName, commonHost)
+			configFile := m.actualConfigFile
+			if configFile == "" {
+				existingHost, findErr := config.FindHostInAllConfigs(m.originalName)
+				if findErr != nil {
+					return editFormSubmitMsg{err: findErr}
+				}
+				configFile = existingHost.SourceFile
 			}
+			change, err = config.PreviewSSHHostUpdate(m.originalName, commonHost, configFile)
 		} else {
 			// Multi-host editing or conversion from single to multi
-			err = config.UpdateMultiHostBlock(m.originalHosts, hostNames, commonHost, m.actualConfigFile)
+			change, err = config.PreviewMultiHostBlockUpdate(m.originalHosts, hostNames, commonHost, m.actualConfigFile)
 		}
 
-		return editFormSubmitMsg{hostname: hostNames[0], err: err}
+		if err != nil {
+			return editFormSubmitMsg{err: err}
+		}
+		if !change.Changed() {
+			// Nothing to write
+			return editFormSubmitMsg{hostname: hostNames[0]}
+		}
+		return editFormPreviewMsg{hostname: hostNames[0], change: change}
 	}
 }
+
+// hostNames returns the host names entered in the form
+func (m *editFormModel) hostNames() []string {
+	var hostNames []string
+	for _, input := range m.hostInputs {
+		name := strings.TrimSpace(input.Value())
+		if name != "" {
+			hostNames = append(hostNames, name)
+		}
+	}
+	return hostNames
+}
+
+// saveHostEnv stores the environment overrides of the form for each of its hosts
+// in the app config, and drops those of hosts renamed or removed from the block.
+// It reports whether the app config changed.
+func (m *editFormModel) saveHostEnv(c *config.AppConfig) bool {
+	env, err := config.ParseHostEnv(m.inputs[12].Value())
+	if err != nil {
+		return false
+	}
+	hostNames := m.hostNames()
+	changed := false
+	for _, name := range m.originalHosts {
+		if !slices.Contains(hostNames, name) && c.SetHostEnv(name, nil) {
+			changed = true
+		}
+	}
+	for _, name := range hostNames {
+		if c.SetHostEnv(name, env) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// updatePreview handles keys while the change is shown for confirmation
+func (m *editFormModel) updatePreview(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		change := m.preview
+		hostname := m.previewHostname
+		return func() tea.Msg {
+			return editFormSubmitMsg{hostname: hostname, err: config.ApplyConfigChange(change), form: m}
+		}
+
+	case "e", "esc", "n":
+		// Back to the form with the edits intact
+		m.preview = nil
+
+	case "ctrl+c":
+		m.preview = nil
+		return func() tea.Msg { return editFormCancelMsg{} }
+
+	case "up", "k":
+		if m.previewOffset > 0 {
+			m.previewOffset--
+		}
+
+	case "down", "j":
+		m.previewOffset++
+
+	case "pgup":
+		m.previewOffset = max(m.previewOffset-m.previewPageSize(), 0)
+
+	case "pgdown", " ":
+		m.previewOffset += m.previewPageSize()
+	}
+	if m.preview != nil {
+		m.previewOffset = min(m.previewOffset, m.maxPreviewOffset())
+	}
+	return nil
+}
+
+// maxPreviewOffset returns the last scroll position that still fills the page
+func (m *editFormModel) maxPreviewOffset() int {
+	return max(len(renderDiff(m.preview.Diff(), m.width-4))-m.previewPageSize(), 0)
+}
+
+// previewPageSize returns how many diff lines fit on screen
+func (m *editFormModel) previewPageSize() int {
+	// Title, file, blank lines, help and border padding
+	return max(m.height-10, 3)
+}
+
+// renderPreview shows the pending change as a colored diff
+func (m *editFormModel) renderPreview() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Primary))
+	infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	lines := renderDiff(m.preview.Diff(), m.width-4)
+	pageSize := m.previewPageSize()
+	// The window may have shrunk since the last scroll
+	offset := min(m.previewOffset, max(len(lines)-pageSize, 0))
+	end := min(offset+pageSize, len(lines))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("REVIEW CHANGES"))
+	b.WriteString("\n\n")
+	b.WriteString(infoStyle.Render("Config: " + formatConfigFile(m.preview.Path)))
+	b.WriteString("\n\n")
+	b.WriteString(strings.Join(lines[offset:end], "\n"))
+	b.WriteString("\n\n")
+
+	help := "y: apply • e: back to form • Esc: back"
+	if len(lines) > pageSize {
+		help = fmt.Sprintf("lines %d-%d of %d • ↑/↓: scroll • ", offset+1, end, len(lines)) + help
+	}
+	b.WriteString(infoStyle.Render(help))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(b.String())
+}
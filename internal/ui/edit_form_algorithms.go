@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// algorithmPreset returns the algorithm preset the SSH Options field matches
+func (m *editFormModel) algorithmPreset() string {
+	return config.DetectAlgorithmPreset(config.NormalizeSSHOptions(m.options.Value()))
+}
+
+// cycleAlgorithmPreset switches the SSH Options field to the next algorithm
+// preset. Custom values count as Default, so they are replaced by Legacy first.
+func (m *editFormModel) cycleAlgorithmPreset() {
+	index := max(slices.Index(config.AlgorithmPresets, m.algorithmPreset()), 0)
+	next := config.AlgorithmPresets[(index+1)%len(config.AlgorithmPresets)]
+
+	options := config.NormalizeSSHOptions(m.options.Value())
+	m.options.SetValue(config.ApplyAlgorithmPreset(options, next))
+	m.options.CursorEnd()
+}
+
+// renderAlgorithms renders the algorithm preset below the robustness preset, with
+// the directives set and a warning when legacy ones are on without the legacy tag
+func (m *editFormModel) renderAlgorithms() string {
+	theme := GetCurrentTheme()
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Width(16)
+	valueStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Primary))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	preset := m.algorithmPreset()
+	values := "OpenSSH defaults"
+	if preset != config.AlgorithmsDefault {
+		values = config.DescribeAlgorithmSettings(config.ParseAlgorithmSettings(config.NormalizeSSHOptions(m.options.Value())))
+	}
+
+	return labelStyle.Render("Algorithms") + "   " + valueStyle.Render(preset) + "  " +
+		mutedStyle.Render(values+" • Ctrl+L: Default/Legacy") + "\n"
+}
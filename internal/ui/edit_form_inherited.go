@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inheritedInputs maps directives with their own field to the input index
+var inheritedInputs = map[string]int{
+	"hostname":      0,
+	"user":          1,
+	"port":          2,
+	"identityfile":  3,
+	"proxyjump":     4,
+	"remotecommand": 7,
+	"requesttty":    8,
+	"proxycommand":  10,
+}
+
+// loadInheritedSettings looks up what the host gets from wildcard blocks. It is
+// only informational, so a config that cannot be resolved leaves the list empty.
+func (m *editFormModel) loadInheritedSettings() {
+	configPath := m.configFile
+	if configPath == "" {
+		var err error
+		if configPath, err = config.GetDefaultSSHConfigPath(); err != nil {
+			return
+		}
+	}
+	effective, err := config.ResolveEffectiveHost(m.originalName, configPath)
+	if err != nil {
+		return
+	}
+	m.inherited = effective.Inherited()
+}
+
+// updateInherited handles keys while the inherited settings are expanded
+func (m *editFormModel) updateInherited(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+o", "esc":
+		m.showInherited = false
+		return m.updateFocus()
+	case "ctrl+c":
+		return func() tea.Msg { return editFormCancelMsg{} }
+	case "ctrl+s":
+		return m.submitEditForm()
+	case "up", "k":
+		if m.inheritedCursor > 0 {
+			m.inheritedCursor--
+		}
+	case "down", "j":
+		if m.inheritedCursor < len(m.inherited)-1 {
+			m.inheritedCursor++
+		}
+	case "enter", "c":
+		m.copyInherited(m.inheritedCursor)
+	}
+	return nil
+}
+
+// copyInherited puts the inherited setting at index into the form as an explicit
+// value of the host, and drops it from the list
+func (m *editFormModel) copyInherited(index int) {
+	if index < 0 || index >= len(m.inherited) {
+		return
+	}
+	setting := m.inherited[index]
+
+	if input, ok := inheritedInputs[strings.ToLower(setting.Keyword)]; ok {
+		m.inputs[input].SetValue(setting.Value)
+	} else {
+		option := setting.Keyword + " " + setting.Value
+		if current := strings.TrimSpace(m.options.Value()); current != "" {
+			option = current + "\n" + option
+		}
+		m.options.SetValue(option)
+	}
+
+	m.inherited = append(m.inherited[:index], m.inherited[index+1:]...)
+	if m.inheritedCursor >= len(m.inherited) {
+		m.inheritedCursor = max(len(m.inherited)-1, 0)
+	}
+	if len(m.inherited) == 0 {
+		m.showInherited = false
+	}
+}
+
+// inheritedLines returns how many lines the inherited section takes up
+func (m *editFormModel) inheritedLines() int {
+	if len(m.inherited) == 0 {
+		return 0
+	}
+	if !m.showInherited {
+		return 2
+	}
+	return len(m.inherited) + 2
+}
+
+// renderInherited renders the inherited settings, collapsed to a single line
+// unless they were expanded with Ctrl+O
+func (m *editFormModel) renderInherited() string {
+	if len(m.inherited) == 0 {
+		return ""
+	}
+	theme := GetCurrentTheme()
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Accent))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	var b strings.Builder
+	title := fmt.Sprintf("Inherited settings (%d)", len(m.inherited))
+	if !m.showInherited {
+		b.WriteString(sectionStyle.Render("▸ " + title))
+		b.WriteString(mutedStyle.Render("  Ctrl+O: show"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(sectionStyle.Render("▾ " + title))
+	b.WriteString("\n")
+	for i, setting := range m.inherited {
+		line := fmt.Sprintf("%s %s  from Host %s", setting.Keyword, setting.Value, setting.Pattern)
+		if setting.Overrides {
+			line += " (wins over this host's value)"
+		}
+		prefix := "  "
+		if i == m.inheritedCursor {
+			prefix = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Render("> ")
+		}
+		b.WriteString(prefix + mutedStyle.Render(line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
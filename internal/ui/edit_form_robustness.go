@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// robustnessPreset returns the connection preset the SSH Options field matches
+func (m *editFormModel) robustnessPreset() string {
+	return config.DetectRobustnessPreset(config.NormalizeSSHOptions(m.options.Value()))
+}
+
+// cycleRobustnessPreset switches the SSH Options field to the next preset. Custom
+// values count as Off, so they are replaced by Normal first.
+func (m *editFormModel) cycleRobustnessPreset() {
+	index := max(slices.Index(config.RobustnessPresets, m.robustnessPreset()), 0)
+	next := config.RobustnessPresets[(index+1)%len(config.RobustnessPresets)]
+
+	options := config.NormalizeSSHOptions(m.options.Value())
+	m.options.SetValue(config.ApplyRobustnessPreset(options, next))
+	m.options.CursorEnd()
+}
+
+// renderRobustness renders the preset selector below the SSH Options field, with
+// the values in effect: the preset's, the host's own custom ones, or those it
+// inherits from wildcard blocks
+func (m *editFormModel) renderRobustness() string {
+	theme := GetCurrentTheme()
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Width(16)
+	valueStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(theme.Primary))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	preset := m.robustnessPreset()
+	var values string
+	switch preset {
+	case config.RobustnessOff:
+		var inherited []string
+		for _, setting := range m.inherited {
+			if config.IsRobustnessKeyword(setting.Keyword) {
+				inherited = append(inherited, setting.Keyword+" "+setting.Value)
+			}
+		}
+		if len(inherited) > 0 {
+			values = "inherited: " + strings.Join(inherited, ", ")
+		} else {
+			values = "ssh defaults"
+		}
+	case config.RobustnessCustom:
+		settings := config.RobustnessSettings(config.NormalizeSSHOptions(m.options.Value()))
+		var parts []string
+		for _, keyword := range config.RobustnessKeywords {
+			if value, ok := settings[keyword]; ok {
+				parts = append(parts, keyword+" "+value)
+			}
+		}
+		values = strings.Join(parts, ", ")
+	default:
+		values = config.DescribeRobustnessPreset(preset)
+	}
+
+	return labelStyle.Render("Robustness") + "   " + valueStyle.Render(preset) + "  " +
+		mutedStyle.Render(values+" • Ctrl+R: Off/Normal/Aggressive") + "\n"
+}
@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestEditFormCopiesInheritedSetting(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host web\n    HostName web.example.com\n\nHost *\n    User admin\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewEditForm("web", NewStyles(120), 120, 60, configFile)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+	if len(m.inherited) != 1 || m.inherited[0].Keyword != "User" || m.inherited[0].Value != "admin" {
+		t.Fatalf("Expected User admin to be inherited, got %+v", m.inherited)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Inherited settings (1)") || strings.Contains(view, "User admin") {
+		t.Errorf("Expected the inherited settings collapsed, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "User admin  from Host *") {
+		t.Errorf("Expected the expanded section to list User, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.inputs[1].Value(); got != "admin" {
+		t.Errorf("Expected the user field to be filled in, got %q", got)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m.Update(cmd())
+	if m.preview == nil {
+		t.Fatal("Expected a preview of the change")
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if msg, ok := cmd().(editFormSubmitMsg); !ok || msg.err != nil {
+		t.Fatalf("Expected the change to be applied, got %+v", msg)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := strings.SplitN(string(content), "Host *", 2)[0]
+	if !strings.Contains(block, "    User admin\n") {
+		t.Errorf("Expected an explicit User line in the web block, got:\n%s", content)
+	}
+}
+
+func TestEditFormCyclesRobustnessPreset(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host web\n    HostName web.example.com\n    Compression yes\n    ServerAliveInterval 60\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewEditForm("web", NewStyles(120), 120, 60, configFile)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlJ})
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Custom  ServerAliveInterval 60") {
+		t.Errorf("Expected the host's own values as a custom preset, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got := m.options.Value(); got != "Compression yes\nServerAliveInterval 30\nServerAliveCountMax 3\nConnectTimeout 10" {
+		t.Errorf("SSH Options after Ctrl+R = %q, want the Normal preset", got)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got := m.robustnessPreset(); got != "Aggressive" {
+		t.Errorf("Preset after a second Ctrl+R = %s, want Aggressive", got)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got := m.options.Value(); got != "Compression yes" {
+		t.Errorf("SSH Options after switching Off = %q", got)
+	}
+}
+
+func TestEditFormKeepsQuotedOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	// Long values with quotes, = and -o used to be mangled on the way back
+	original := "Host web\n    HostName web.example.com\n" +
+		"    ProxyCommand ssh -o StrictHostKeyChecking=no -W \"%h:%p\" 'jump host'\n" +
+		"    LocalCommand printf 'connected to %n, user=%r\\n' >> \"/tmp/ssh log\"\n" +
+		"    SetEnv GREETING=\"hello world\" LANG=C.UTF-8\n"
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewEditForm("web", NewStyles(120), 120, 60, configFile)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+
+	// Change the user so there is something to write
+	m.inputs[1].SetValue("deploy")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m.Update(cmd())
+	if m.preview == nil {
+		t.Fatalf("Expected a preview of the change, got error %q", m.err)
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if msg, ok := cmd().(editFormSubmitMsg); !ok || msg.err != nil {
+		t.Fatalf("Expected the change to be applied, got %+v", msg)
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(original), "\n")[2:] {
+		if !strings.Contains(string(content), line+"\n") {
+			t.Errorf("Expected %q to be written verbatim, got:\n%s", line, content)
+		}
+	}
+}
+
+func TestEditFormConvertsPastedCommandLineOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host web\n    HostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewEditForm("web", NewStyles(120), 120, 60, configFile)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+	m.focusArea = focusAreaProperties
+	m.currentTab = 1
+	m.focused = editOptionsField
+	m.updateFocus()
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(`-o Compression=yes -o "ProxyCommand=ssh -W %h:%p bastion"`), Paste: true})
+	if got, want := m.options.Value(), "Compression yes\nProxyCommand ssh -W %h:%p bastion"; got != want {
+		t.Errorf("SSH Options after paste = %q, want %q", got, want)
+	}
+
+	// Enter starts a new option and up moves back within the field
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ForwardAgent")})
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if m.focused != editOptionsField || m.options.Line() != 1 {
+		t.Errorf("focused = %d, line = %d, want the second line of the SSH Options", m.focused, m.options.Line())
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if msg, ok := cmd().(editFormSubmitMsg); !ok || msg.err == nil || !strings.Contains(msg.err.Error(), "ForwardAgent has no value") {
+		t.Errorf("Expected the option without a value to be rejected, got %+v", msg)
+	}
+}
+
+func TestEditFormTogglesLegacyAlgorithms(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host switch\n    HostName 10.0.0.2\n    KexAlgorithms +diffie-hellman-group1-sha1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewEditForm("switch", NewStyles(120), 120, 60, configFile)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlJ})
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Custom  KexAlgorithms +diffie-hellman-group1-sha1") {
+		t.Errorf("Expected the host's own algorithms as custom, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if got := m.algorithmPreset(); got != "Legacy" {
+		t.Errorf("Preset after Ctrl+L = %s, want Legacy; options %q", got, m.options.Value())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if got := m.options.Value(); got != "" {
+		t.Errorf("SSH Options after switching to Default = %q", got)
+	}
+}
+
+func TestEditFormSavesHostEnv(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host router\n    HostName 10.0.0.254\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewEditForm("router", NewStyles(120), 120, 60, configFile)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+
+	m.inputs[12].SetValue("TERM=vt100 LANG")
+	if msg, ok := m.submitEditForm()().(editFormSubmitMsg); !ok || msg.err == nil {
+		t.Fatalf("Expected an invalid override to be rejected, got %+v", msg)
+	}
+
+	// Only the environment changed, so there is nothing to write to the SSH config
+	m.inputs[12].SetValue("TERM=vt100, LANG=C")
+	if msg, ok := m.submitEditForm()().(editFormSubmitMsg); !ok || msg.err != nil {
+		t.Fatalf("Expected the form to submit, got %+v", msg)
+	}
+
+	appConfig := &config.AppConfig{HostEnv: map[string]map[string]string{"old": {"TERM": "vt220"}}}
+	m.hostInputs[0].SetValue("core-router")
+	m.originalHosts = []string{"router", "old"}
+	if !m.saveHostEnv(appConfig) {
+		t.Fatal("Expected the app config to change")
+	}
+	want := map[string]map[string]string{"core-router": {"TERM": "vt100", "LANG": "C"}}
+	if !reflect.DeepEqual(appConfig.HostEnv, want) {
+		t.Errorf("HostEnv = %v, want %v", appConfig.HostEnv, want)
+	}
+}
+
+func TestEditFromListShowsPreviewAndApplies(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host web\n    HostName 10.0.0.1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := createLargeTestModel(0)
+	m.configFile = configFile
+	m.appConfig = &config.AppConfig{}
+	editForm, err := NewEditForm("web", m.styles, m.width, m.height, configFile)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+	editForm.inputs[0].SetValue("10.0.0.2")
+	m.editForm = editForm
+	m.viewMode = ViewEdit
+
+	// Every message goes through the main model, as in the running TUI
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.editForm == nil || m.editForm.preview == nil {
+		t.Fatal("Expected the change to be shown for review")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "REVIEW CHANGES") {
+		t.Errorf("Expected the review screen, got:\n%s", view)
+	}
+
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.viewMode != ViewList || m.editForm != nil {
+		t.Errorf("Expected the list after saving, got view %v", m.viewMode)
+	}
+	if content, _ := os.ReadFile(configFile); !strings.Contains(string(content), "HostName 10.0.0.2") {
+		t.Errorf("Expected the new hostname to be written, got:\n%s", content)
+	}
+}
+
+func TestEditPreviewScrollStopsAtEnd(t *testing.T) {
+	after := strings.Repeat("Host a\n    HostName 10.0.0.1\n", 15)
+	m := &editFormModel{height: 20, width: 80, styles: NewStyles(80), preview: &config.ConfigChange{Path: "config", After: after}}
+	for range 40 {
+		m.updatePreview(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if m.previewOffset == 0 || m.previewOffset != m.maxPreviewOffset() {
+		t.Errorf("previewOffset = %d, want the last page at %d", m.previewOffset, m.maxPreviewOffset())
+	}
+	m.View()
+	if m.previewOffset != m.maxPreviewOffset() {
+		t.Error("Rendering changed the scroll position")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/xvertile/sshc/internal/config"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -17,6 +18,15 @@ type fileSelectorModel struct {
 	width        int
 	height       int
 	title        string
+
+	// Creating a new file for an Include directive to pick up
+	allowCreate     bool
+	baseConfig      string   // Relative names resolve against this file's directory
+	includePatterns []string // Absolute Include patterns from the config
+	creating        bool
+	nameInput       textinput.Model
+	createErr       string
+	warned          bool // The Include warning for the current name has been shown
 }
 
 type fileSelectorMsg struct {
@@ -52,8 +62,43 @@ func NewFileSelectorFromBase(title string, styles Styles, width, height int, bas
 	return newFileSelectorFromFiles(title, styles, width, height, files)
 }
 
-// newFileSelectorFromFiles creates a file selector from a list of files
+// NewIncludeTargetSelector creates a file selector for adding a host, with an entry
+// for creating a new file matched by one of the config's Include directives
+func NewIncludeTargetSelector(title string, styles Styles, width, height int, baseConfigFile string) (*fileSelectorModel, error) {
+	if baseConfigFile == "" {
+		var err error
+		baseConfigFile, err = config.GetDefaultSSHConfigPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := NewFileSelectorFromBase(title, styles, width, height, baseConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := config.GetIncludePatterns(baseConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	nameInput := textinput.New()
+	nameInput.Placeholder = "conf.d/servers.conf"
+	nameInput.CharLimit = 256
+	nameInput.Width = 50
+
+	m.allowCreate = true
+	m.baseConfig = baseConfigFile
+	m.includePatterns = patterns
+	m.nameInput = nameInput
+	return m, nil
+}
+
+// newFileSelectorFromFiles creates a file selector from a list of files. Files marked
+// read-only are left out since every selector picks a file to write to.
 func newFileSelectorFromFiles(title string, styles Styles, width, height int, files []string) (*fileSelectorModel, error) {
+	files = config.WritableConfigFiles(files)
 
 	// Convert absolute paths to more user-friendly names
 	var displayNames []string
@@ -103,6 +148,10 @@ func (m *fileSelectorModel) Update(msg tea.Msg) (*fileSelectorModel, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.creating {
+			return m.updateCreate(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			return m, func() tea.Msg {
@@ -110,6 +159,13 @@ func (m *fileSelectorModel) Update(msg tea.Msg) (*fileSelectorModel, tea.Cmd) {
 			}
 
 		case "enter":
+			if m.allowCreate && m.selected == len(m.files) {
+				m.creating = true
+				m.createErr = ""
+				m.warned = false
+				m.nameInput.SetValue("")
+				return m, m.nameInput.Focus()
+			}
 			selectedFile := ""
 			if m.selected < len(m.files) {
 				selectedFile = m.files[m.selected]
@@ -124,7 +180,7 @@ func (m *fileSelectorModel) Update(msg tea.Msg) (*fileSelectorModel, tea.Cmd) {
 			}
 
 		case "down", "j":
-			if m.selected < len(m.files)-1 {
+			if m.selected < m.entryCount()-1 {
 				m.selected++
 			}
 		}
@@ -133,13 +189,69 @@ func (m *fileSelectorModel) Update(msg tea.Msg) (*fileSelectorModel, tea.Cmd) {
 	return m, nil
 }
 
+// entryCount returns the number of selectable entries, including "create new file"
+func (m *fileSelectorModel) entryCount() int {
+	if m.allowCreate {
+		return len(m.files) + 1
+	}
+	return len(m.files)
+}
+
+// updateCreate handles keys while typing the name of a new config file
+func (m *fileSelectorModel) updateCreate(msg tea.KeyMsg) (*fileSelectorModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.creating = false
+		m.nameInput.Blur()
+		return m, nil
+
+	case "enter":
+		name := strings.TrimSpace(m.nameInput.Value())
+		if name == "" {
+			m.createErr = "Enter a file name"
+			return m, nil
+		}
+		path, err := config.ResolveIncludePath(name, m.baseConfig)
+		if err != nil {
+			m.createErr = err.Error()
+			return m, nil
+		}
+
+		// Creating a file ssh won't read is allowed, but only after a warning
+		if err := config.CheckIncludeTarget(path, m.includePatterns); err != nil && !m.warned {
+			m.createErr = err.Error() + ". Press Enter again to create it anyway."
+			m.warned = true
+			return m, nil
+		}
+
+		if err := config.CreateIncludedConfigFile(path); err != nil {
+			m.createErr = err.Error()
+			m.warned = false
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			return fileSelectorMsg{selectedFile: path}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.nameInput, cmd = m.nameInput.Update(msg)
+	m.createErr = ""
+	m.warned = false
+	return m, cmd
+}
+
 func (m *fileSelectorModel) View() string {
 	var b strings.Builder
 
 	b.WriteString(m.styles.FormTitle.Render(m.title))
 	b.WriteString("\n\n")
 
-	if len(m.files) == 0 {
+	if m.creating {
+		return m.createView()
+	}
+
+	if len(m.files) == 0 && !m.allowCreate {
 		b.WriteString(m.styles.Error.Render("No SSH config files found."))
 		b.WriteString("\n\n")
 		b.WriteString(m.styles.FormHelp.Render("Esc: cancel"))
@@ -155,8 +267,52 @@ func (m *fileSelectorModel) View() string {
 		b.WriteString("\n")
 	}
 
+	if m.allowCreate {
+		if m.selected == len(m.files) {
+			b.WriteString(m.styles.Selected.Render("▶ + Create new file…"))
+		} else {
+			b.WriteString("  + Create new file…")
+		}
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
 	b.WriteString(m.styles.FormHelp.Render("↑/↓: navigate • Enter: select • Esc: cancel"))
 
 	return b.String()
 }
+
+// createView renders the prompt for a new config file name
+func (m *fileSelectorModel) createView() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render("Create new config file"))
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.FocusedLabel.Render("File name"))
+	b.WriteString("\n")
+	b.WriteString(m.nameInput.View())
+	b.WriteString("\n\n")
+
+	if len(m.includePatterns) > 0 {
+		b.WriteString(m.styles.HelpText.Render("Included by this config:"))
+		b.WriteString("\n")
+		for _, pattern := range m.includePatterns {
+			b.WriteString(m.styles.HelpText.Render("  " + pattern))
+			b.WriteString("\n")
+		}
+	} else {
+		b.WriteString(m.styles.HelpText.Render("This config has no Include directives."))
+		b.WriteString("\n")
+	}
+
+	if m.createErr != "" {
+		b.WriteString("\n")
+		b.WriteString(m.styles.Error.Render(m.createErr))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.FormHelp.Render("Relative names start in " + filepath.Dir(m.baseConfig) + " • Enter: create • Esc: back"))
+
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFileSelectorCreatesIncludeTarget(t *testing.T) {
+	tempDir := t.TempDir()
+	mainConfig := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(mainConfig, []byte("Include conf.d/*.conf\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewIncludeTargetSelector("Select", NewStyles(100), 100, 40, mainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.entryCount() != len(m.files)+1 {
+		t.Fatal("Expected a create entry after the existing files")
+	}
+
+	m.selected = len(m.files)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.creating {
+		t.Fatal("Expected enter on the create entry to prompt for a name")
+	}
+
+	// A name no Include matches is only created after a second enter
+	m.nameInput.SetValue("stray.conf")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.createErr == "" {
+		t.Fatal("Expected a warning for a file ssh would not read")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "stray.conf")); err == nil {
+		t.Fatal("Expected the file not to be created before confirming")
+	}
+
+	m.nameInput.SetValue("conf.d/web.conf")
+	m.warned = false
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("Expected the matching file to be created, got %q", m.createErr)
+	}
+	want := filepath.Join(tempDir, "conf.d", "web.conf")
+	if msg, ok := cmd().(fileSelectorMsg); !ok || msg.selectedFile != want {
+		t.Errorf("Expected the add form to target %s, got %#v", want, msg)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("Expected %s to exist: %v", want, err)
+	}
+}
@@ -1,25 +1,33 @@
 package ui
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type helpModel struct {
-	styles Styles
-	width  int
-	height int
+	styles      Styles
+	width       int
+	height      int
+	keyBindings config.KeyBindings
+	sshVersion  string // Detected ssh client, shown at the bottom
 }
 
 // helpCloseMsg is sent when the help window is closed
 type helpCloseMsg struct{}
 
 // NewHelpForm creates a new help form model
-func NewHelpForm(styles Styles, width, height int) *helpModel {
+func NewHelpForm(styles Styles, width, height int, keyBindings config.KeyBindings) *helpModel {
 	return &helpModel{
-		styles: styles,
-		width:  width,
-		height: height,
+		styles:      styles,
+		width:       width,
+		height:      height,
+		keyBindings: keyBindings,
 	}
 }
 
@@ -30,9 +38,13 @@ func (m *helpModel) Init() tea.Cmd {
 func (m *helpModel) Update(msg tea.Msg) (*helpModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc", "q", "h", "enter", "ctrl+c":
+		switch key := msg.String(); key {
+		case "esc", "q", "enter", "ctrl+c":
 			return m, func() tea.Msg { return helpCloseMsg{} }
+		default:
+			if key == m.keyBindings.KeyForAction(config.ActionHelp) {
+				return m, func() tea.Msg { return helpCloseMsg{} }
+			}
 		}
 	}
 	return m, nil
@@ -46,74 +58,76 @@ func (m *helpModel) View() string {
 	leftColumn := lipgloss.JoinVertical(lipgloss.Left,
 		m.styles.FocusedLabel.Render("Navigation & Connection"),
 		"",
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("↑/k ↓/j "),
+			m.styles.HelpText.Render("move; gg/G first/last, ctrl+u/ctrl+d half page")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("⏎  "),
 			m.styles.HelpText.Render("connect to selected host")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("i  "),
-			m.styles.HelpText.Render("show host information")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("/  "),
-			m.styles.HelpText.Render("search hosts")),
+		m.renderKeyLine(config.ActionVerboseSSH, "connect and show which key was accepted"),
+		m.renderKeyLine(config.ActionJumpConnect, "connect through another jump host"),
+		m.renderKeyLine(config.ActionInfo, "show host information"),
+		m.renderKeyLine(config.ActionSearch, "search hosts"),
+		m.renderKeyLine(config.ActionTagFilter, "filter by a tag of selected host"),
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("Tab "),
 			m.styles.HelpText.Render("switch focus")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render(padHelpKey("ctrl+/")),
+			m.styles.HelpText.Render("search proxy, identity and options too")),
 		"",
 		m.styles.FocusedLabel.Render("Host Management"),
 		"",
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("a  "),
-			m.styles.HelpText.Render("add new host")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("e  "),
-			m.styles.HelpText.Render("edit selected host")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("m  "),
-			m.styles.HelpText.Render("move host to another config")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("d  "),
-			m.styles.HelpText.Render("delete selected host")),
+		m.renderKeyLine(config.ActionAdd, "add new host"),
+		m.renderKeyLine(config.ActionEdit, "edit selected host"),
+		m.renderKeyLine(config.ActionMark, "mark host, for moving several at once"),
+		m.renderKeyLine(config.ActionMove, "move host (or marked hosts) to another config"),
+		m.renderKeyLine(config.ActionDelete, "delete selected host"),
+		m.renderKeyLine(config.ActionDeleteExpired, "delete all expired hosts"),
+		m.renderKeyLine(config.ActionKeyUpload, "upload SSH key to host"),
+		m.renderKeyLine(config.ActionOnboard, "onboard: trust key, upload, test login"),
 	)
 
 	rightColumn := lipgloss.JoinVertical(lipgloss.Left,
 		m.styles.FocusedLabel.Render("Advanced Features"),
 		"",
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("p  "),
-			m.styles.HelpText.Render("ping all hosts")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("f  "),
-			m.styles.HelpText.Render("setup port forwarding")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("t  "),
-			m.styles.HelpText.Render("quick file transfer (upload/download)")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("s  "),
-			m.styles.HelpText.Render("cycle sort modes")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("n  "),
-			m.styles.HelpText.Render("sort by name")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("r  "),
-			m.styles.HelpText.Render("sort by recent connection")),
+		m.renderKeyLine(config.ActionPing, "ping all hosts, count open sessions"),
+		m.renderKeyLine(config.ActionForward, "setup port forwarding"),
+		m.renderKeyLine(config.ActionTransfer, "quick file transfer (upload/download)"),
+		m.renderKeyLine(config.ActionDualBrowser, "copy files between two hosts"),
+		m.renderKeyLine(config.ActionDashboard, "load, disk and memory of listed hosts"),
+		m.renderKeyLine(config.ActionOSInfo, "read the OS and uptime of listed hosts"),
+		m.renderKeyLine(config.ActionOpenURL, "open a web page of the host"),
+		m.renderKeyLine(config.ActionSnippets, "run a saved snippet on the host"),
+		m.renderKeyLine(config.ActionWait, "wait until the host answers, then connect"),
+		m.renderKeyLine(config.ActionHistory, "recent transfers, to run one again"),
+		m.renderKeyLine(config.ActionMount, "mount the host with sshfs (again to unmount)"),
+		m.renderKeyLine(config.ActionReload, "reload the SSH config from disk"),
+		m.renderKeyLine(config.ActionVerify, "compare the host with ssh -G, adopt its values"),
+		m.renderKeyLine(config.ActionMessages, "warnings and errors of this session"),
+		m.renderKeyLine(config.ActionSortCycle, "cycle sort modes"),
+		m.renderKeyLine(config.ActionSortName, "sort by name"),
+		m.renderKeyLine(config.ActionSortRecent, "sort by recent connection"),
+		m.renderKeyLine(config.ActionTimeFormat, "toggle relative/absolute times"),
+		m.renderKeyLine(config.ActionCollapse, "collapse multi-host blocks (→/← to open/close)"),
+		m.renderKeyLine(config.ActionColumns, "show or hide columns"),
+		m.renderKeyLine(config.ActionSaveWorkspace, "save search, sorting and columns as a workspace"),
+		m.renderKeyLine(config.ActionWorkspaces, "switch or delete workspaces"),
 		"",
 		m.styles.FocusedLabel.Render("System"),
 		"",
+		m.renderKeyLine(config.ActionTheme, "change theme/colors"),
+		m.renderKeyLine(config.ActionK8sAdd, "add kubernetes host"),
+		m.renderKeyLine(config.ActionHelp, "show this help"),
 		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("c  "),
-			m.styles.HelpText.Render("change theme/colors")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("K  "),
-			m.styles.HelpText.Render("add kubernetes host")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("h  "),
-			m.styles.HelpText.Render("show this help")),
-		lipgloss.JoinHorizontal(lipgloss.Left,
-			m.styles.FocusedLabel.Render("q  "),
+			m.styles.FocusedLabel.Render(padHelpKey(strings.Join(m.keyBindings.QuitKeys, "/"))),
 			m.styles.HelpText.Render("quit application")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("ESC "),
 			m.styles.HelpText.Render("exit current view")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("F12 "),
+			m.styles.HelpText.Render("debug overlay: parse and render timings")),
 	)
 
 	// Join the two columns side by side
@@ -129,7 +143,8 @@ func (m *helpModel) View() string {
 		"",
 		columns,
 		"",
-		m.styles.HelpText.Render("Press ESC, h, q or Enter to close"),
+		m.styles.HelpText.Render("ssh client: "+m.sshVersion),
+		m.styles.HelpText.Render(fmt.Sprintf("Press ESC, %s, q or Enter to close", m.keyBindings.KeyForAction(config.ActionHelp))),
 	)
 
 	// Center the help window
@@ -141,3 +156,15 @@ func (m *helpModel) View() string {
 		m.styles.FormContainer.Render(content),
 	)
 }
+
+// renderKeyLine renders the configured key for an action next to its description
+func (m *helpModel) renderKeyLine(action, description string) string {
+	return lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.FocusedLabel.Render(padHelpKey(m.keyBindings.KeyForAction(action))),
+		m.styles.HelpText.Render(description))
+}
+
+// padHelpKey pads a key label so descriptions line up in the help columns
+func padHelpKey(key string) string {
+	return fmt.Sprintf("%-3s", key)
+}
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// hintState names what has the focus, which decides the keys the hint line offers
+type hintState string
+
+const (
+	hintEmptyList   hintState = "empty-list"   // No row to act on
+	hintHost        hintState = "host"         // A host row is selected
+	hintBlock       hintState = "block"        // The row of a collapsed multi-host block
+	hintSearch      hintState = "search"       // Typing in the search bar
+	hintDelete      hintState = "delete"       // The delete confirmation
+	hintAddField    hintState = "add-field"    // A field of the add form
+	hintAddProxy    hintState = "add-proxy"    // The ProxyJump field of the add form
+	hintForwardType hintState = "forward-type" // The type field of the port forward form
+	hintForward     hintState = "forward"      // Another field of the port forward form
+)
+
+// keyHint is one entry of a hint line. Its key is the one bound to action when
+// action is set, so customized bindings show, and the fixed key otherwise.
+type keyHint struct {
+	action string
+	key    string
+	label  string
+}
+
+// hintLines holds every hint line, so the wording lives in one place. The host
+// line leads with the five most used actions.
+var hintLines = map[hintState][]keyHint{
+	hintEmptyList: {
+		{action: config.ActionAdd, label: "add"},
+		{action: config.ActionSearch, label: "search"},
+		{action: config.ActionTheme, label: "themes"},
+		{action: config.ActionWorkspaces, label: "workspaces"},
+		{action: config.ActionHelp, label: "help"},
+	},
+	hintHost: {
+		{key: "Enter", label: "connect"},
+		{action: config.ActionEdit, label: "edit"},
+		{action: config.ActionInfo, label: "info"},
+		{action: config.ActionTransfer, label: "transfer"},
+		{action: config.ActionForward, label: "forward"},
+		{action: config.ActionHelp, label: "help"},
+	},
+	hintBlock: {
+		{key: "→", label: "expand"},
+		{key: "Enter", label: "pick a host"},
+		{action: config.ActionCollapse, label: "expand all"},
+		{action: config.ActionHelp, label: "help"},
+	},
+	hintSearch: {
+		{key: "tag:NAME"},
+		{key: "user:NAME"},
+		{key: "host:ADDR"},
+		{key: "expired:true"},
+		{key: "Enter", label: "validate"},
+		{key: "ctrl+/", label: "deep"},
+		{key: "Esc", label: "exit"},
+	},
+	hintDelete: {
+		{key: "Enter", label: "confirm"},
+		{key: "Esc", label: "cancel"},
+	},
+	hintAddField: {
+		{key: "↑/↓", label: "navigate"},
+		{key: "Enter", label: "next/submit"},
+		{key: "Ctrl+S", label: "save"},
+		{key: "Esc", label: "cancel"},
+	},
+	hintAddProxy: {
+		{key: "↑/↓", label: "navigate"},
+		{key: "Ctrl+B", label: "find bastion"},
+		{key: "Ctrl+S", label: "save"},
+		{key: "Esc", label: "cancel"},
+	},
+	hintForwardType: {
+		{key: "←/→", label: "change type"},
+		{key: "↓", label: "next field"},
+		{key: "Enter", label: "connect"},
+		{key: "Esc", label: "cancel"},
+	},
+	hintForward: {
+		{key: "↑/↓", label: "navigate"},
+		{key: "Enter", label: "connect"},
+		{key: "Esc", label: "cancel"},
+	},
+}
+
+// hintLine renders the hint line of state as "key: label" entries. Actions
+// without a bound key are left out. kb may be nil for lines with fixed keys only.
+func hintLine(state hintState, kb *config.KeyBindings) string {
+	var parts []string
+	for _, hint := range hintLines[state] {
+		key := hint.key
+		if hint.action != "" {
+			if kb == nil {
+				continue
+			}
+			key = kb.KeyForAction(hint.action)
+		}
+		if key == "" {
+			continue
+		}
+		if hint.label == "" {
+			parts = append(parts, key)
+			continue
+		}
+		parts = append(parts, key+": "+hint.label)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// hintState returns what has the focus in the current view
+func (m Model) hintState() hintState {
+	switch m.viewMode {
+	case ViewAdd:
+		if m.addForm != nil {
+			return m.addForm.hintState()
+		}
+	case ViewPortForward:
+		if m.portForwardForm != nil {
+			return m.portForwardForm.hintState()
+		}
+	}
+
+	switch {
+	case m.deleteMode:
+		return hintDelete
+	case m.searchMode:
+		return hintSearch
+	}
+	entry := m.selectedEntry()
+	switch {
+	case entry == nil:
+		return hintEmptyList
+	case entry.BlockMembers != nil:
+		return hintBlock
+	}
+	return hintHost
+}
+
+// contextHint returns the hint line for what has the focus, with the keys as bound
+func (m Model) contextHint() string {
+	kb := m.keyBindings()
+	return hintLine(m.hintState(), &kb)
+}
+
+// hintState returns what has the focus in the add form: the ProxyJump field,
+// where Ctrl+B finds a bastion, or any other field
+func (m *addFormModel) hintState() hintState {
+	if m.focused == addProxyJumpInput {
+		return hintAddProxy
+	}
+	return hintAddField
+}
+
+// hintState returns what has the focus in the port forward form: the forward
+// type, changed with ←/→, or one of the port and address fields
+func (m *portForwardModel) hintState() hintState {
+	if m.focused == pfTypeInput {
+		return hintForwardType
+	}
+	return hintForward
+}
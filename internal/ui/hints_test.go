@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestContextHintSnapshots(t *testing.T) {
+	var lines []string
+	snapshot := func(name string, m Model) {
+		t.Helper()
+		lines = append(lines, name+": "+m.contextHint())
+	}
+	press := func(m Model, msg tea.KeyMsg) Model {
+		t.Helper()
+		newModel, _ := m.Update(msg)
+		return newModel.(Model)
+	}
+
+	m := createTestModel()
+	m.table.Focus()
+	snapshot("host", m)
+
+	search := press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if !search.searchMode {
+		t.Fatal("Expected / to focus the search bar")
+	}
+	snapshot("search", search)
+
+	remove := press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if !remove.deleteMode {
+		t.Fatal("Expected d to ask for confirmation")
+	}
+	snapshot("delete", remove)
+
+	forward := m
+	forward.viewMode = ViewPortForward
+	forward.portForwardForm = NewPortForwardForm("server1", m.styles, m.width, m.height, "", nil)
+	snapshot("forward type field", forward)
+	forward.portForwardForm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	snapshot("forward port field", forward)
+
+	add := m
+	add.viewMode = ViewAdd
+	add.addForm = NewAddForm("", m.styles, m.width, m.height, "")
+	snapshot("add name field", add)
+	add.addForm.focused = addProxyJumpInput
+	snapshot("add proxy field", add)
+
+	empty := press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "no-such-host" {
+		empty = press(empty, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	empty = press(empty, tea.KeyMsg{Type: tea.KeyEnter})
+	snapshot("no match", empty)
+
+	assertGolden(t, "hints.golden", strings.Join(lines, "\n")+"\n")
+}
+
+func TestContextHintShowsCustomizedKeys(t *testing.T) {
+	m := createTestModel()
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.KeyBindings.Actions[config.ActionEdit] = "E"
+	appConfig.KeyBindings.Actions[config.ActionTransfer] = "ctrl+t"
+	m.appConfig = &appConfig
+
+	hint := m.contextHint()
+	if !strings.Contains(hint, "E: edit") || !strings.Contains(hint, "ctrl+t: transfer") {
+		t.Errorf("contextHint() = %q, want the customized keys", hint)
+	}
+	if strings.Contains(hint, "e: edit") {
+		t.Errorf("contextHint() = %q still shows the default edit key", hint)
+	}
+}
@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// historyTransferLimit is how many recent transfers the history view lists
+const historyTransferLimit = 50
+
+// historyViewModel lists recent transfers so one can be pruned or run again
+type historyViewModel struct {
+	historyManager *history.HistoryManager
+	configFile     string
+	records        []history.TransferRecord
+	selectedIndex  int
+	confirmRerun   bool
+	status         string
+	err            string
+
+	styles Styles
+	width  int
+	height int
+}
+
+type historyViewCloseMsg struct{}
+
+// transferRerunMsg asks the model to run a recorded transfer again
+type transferRerunMsg struct {
+	request *transfer.TransferRequest
+}
+
+// transferRerunDoneMsg is sent when a re-run transfer exited
+type transferRerunDoneMsg struct {
+	hostName string
+	err      error
+}
+
+// NewHistoryView creates the history view of the recent transfers of every host
+func NewHistoryView(historyManager *history.HistoryManager, configFile string, styles Styles, width, height int) *historyViewModel {
+	m := &historyViewModel{
+		historyManager: historyManager,
+		configFile:     configFile,
+		styles:         styles,
+		width:          width,
+		height:         height,
+	}
+	m.reload()
+	return m
+}
+
+// reload reads the transfers again, keeping the selection in range
+func (m *historyViewModel) reload() {
+	m.records = m.historyManager.GetTransfers("", historyTransferLimit)
+	if m.selectedIndex >= len(m.records) {
+		m.selectedIndex = max(len(m.records)-1, 0)
+	}
+}
+
+func (m *historyViewModel) Update(msg tea.Msg) (*historyViewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case transferRerunDoneMsg:
+		m.reload()
+		if msg.err != nil {
+			m.status, m.err = "", fmt.Sprintf("Transfer with %s failed: %v", msg.hostName, msg.err)
+		} else {
+			m.status, m.err = fmt.Sprintf("Transfer with %s complete", msg.hostName), ""
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.confirmRerun {
+			return m.updateConfirmRerun(msg)
+		}
+		return m.updateList(msg)
+	}
+	return m, nil
+}
+
+// updateList handles keys while browsing the transfers
+func (m *historyViewModel) updateList(msg tea.KeyMsg) (*historyViewModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc", "q":
+		return m, func() tea.Msg { return historyViewCloseMsg{} }
+
+	case "up", "k":
+		if m.selectedIndex > 0 {
+			m.selectedIndex--
+		}
+
+	case "down", "j":
+		if m.selectedIndex < len(m.records)-1 {
+			m.selectedIndex++
+		}
+
+	case "enter":
+		if len(m.records) > 0 {
+			m.confirmRerun = true
+			m.status, m.err = "", ""
+		}
+
+	case "d":
+		if len(m.records) > 0 {
+			record := m.records[m.selectedIndex]
+			if err := m.historyManager.DeleteTransfer(record.HostName, record.Timestamp); err != nil {
+				m.err = err.Error()
+			} else {
+				m.status, m.err = "Removed from history", ""
+			}
+			m.reload()
+		}
+	}
+	return m, nil
+}
+
+// updateConfirmRerun asks before running the selected transfer again
+func (m *historyViewModel) updateConfirmRerun(msg tea.KeyMsg) (*historyViewModel, tea.Cmd) {
+	m.confirmRerun = false
+	switch msg.String() {
+	case "y", "Y":
+		request := transferRequestFromRecord(m.records[m.selectedIndex], m.configFile)
+		return m, func() tea.Msg { return transferRerunMsg{request: request} }
+	}
+	return m, nil
+}
+
+// transferRequestFromRecord rebuilds the request of a recorded transfer. Uploads
+// of a directory are recursive even when recorded by a version that did not
+// store the flag.
+func transferRequestFromRecord(record history.TransferRecord, configFile string) *transfer.TransferRequest {
+	request := &transfer.TransferRequest{
+		Host:       record.HostName,
+		Direction:  transfer.Upload,
+		LocalPath:  record.LocalPath,
+		RemotePath: record.RemotePath,
+		Recursive:  record.Recursive,
+		ConfigFile: configFile,
+	}
+	if record.Direction == "download" {
+		request.Direction = transfer.Download
+	} else if info, err := os.Stat(record.LocalPath); err == nil && info.IsDir() {
+		request.Recursive = true
+	}
+	return request
+}
+
+// transferHistoryEntry describes a transfer request for the history
+func transferHistoryEntry(request *transfer.TransferRequest) history.TransferHistoryEntry {
+	direction := "upload"
+	if request.Direction == transfer.Download {
+		direction = "download"
+	}
+	return history.TransferHistoryEntry{
+		Direction:  direction,
+		LocalPath:  request.LocalPath,
+		RemotePath: request.RemotePath,
+		Recursive:  request.Recursive,
+		Timestamp:  time.Now(),
+	}
+}
+
+func (m *historyViewModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	tabStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.SelectionFg)).
+		Background(lipgloss.Color(theme.SelectionBg)).
+		Padding(0, 1)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error))
+	successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Success))
+
+	body := []string{titleStyle.Render("History"), "", tabStyle.Render("Transfers"), ""}
+	body = append(body, m.transferLines()...)
+
+	switch {
+	case m.err != "":
+		body = append(body, "", errorStyle.Render(m.err))
+	case m.status != "":
+		body = append(body, "", successStyle.Render(m.status))
+	}
+
+	help := "Enter: run again • d: remove • Esc: close"
+	if m.confirmRerun {
+		command := transferRequestFromRecord(m.records[m.selectedIndex], m.configFile).BuildSCPCommand()
+		help = fmt.Sprintf("Run %s? (y/N)", strings.Join(command.Args, " "))
+	}
+	body = append(body, "", helpStyle.Render(ansi.Truncate(help, max(m.width-10, 20), "…")))
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		container.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+	)
+}
+
+// transferLines renders the transfers around the selection
+func (m *historyViewModel) transferLines() []string {
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	if len(m.records) == 0 {
+		return []string{mutedStyle.Render("No transfers yet")}
+	}
+
+	// Leave room for the border, title, tab, status and help lines
+	visible := max(m.height-16, 3)
+	start := 0
+	if m.selectedIndex >= visible {
+		start = m.selectedIndex - visible + 1
+	}
+	end := min(start+visible, len(m.records))
+	pathWidth := max(m.width-60, 20)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		record := m.records[i]
+		arrow := "↑"
+		paths := record.LocalPath + " → " + record.HostName + ":" + record.RemotePath
+		if record.Direction == "download" {
+			arrow = "↓"
+			paths = record.HostName + ":" + record.RemotePath + " → " + record.LocalPath
+		}
+		line := fmt.Sprintf("%s %s  %s %s",
+			record.Timestamp.Local().Format("2006-01-02 15:04"),
+			transferOutcomeIcon(record.Outcome),
+			arrow,
+			ansi.Truncate(paths, pathWidth, "…"),
+		)
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground)).Padding(0, 1)
+		if i == m.selectedIndex {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 1)
+		}
+		lines = append(lines, style.Render(line))
+		if i == m.selectedIndex && record.Error != "" {
+			lines = append(lines, mutedStyle.Render("    "+ansi.Truncate(record.Error, pathWidth, "…")))
+		}
+	}
+	return lines
+}
+
+// transferOutcomeIcon marks how a recorded transfer ended
+func transferOutcomeIcon(outcome string) string {
+	switch outcome {
+	case history.TransferSucceeded:
+		return "✓"
+	case history.TransferFailed:
+		return "✗"
+	default:
+		return "·"
+	}
+}
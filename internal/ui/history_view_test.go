@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func newTestHistoryManager(t *testing.T) *history.HistoryManager {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+
+	hm, err := history.NewHistoryManager()
+	if err != nil {
+		t.Fatalf("NewHistoryManager() error = %v", err)
+	}
+	return hm
+}
+
+func TestHistoryViewRerunsTransfer(t *testing.T) {
+	hm := newTestHistoryManager(t)
+	localDir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	if err := hm.RecordTransferEntry("web", history.TransferHistoryEntry{Direction: "upload", LocalPath: localDir, RemotePath: "/srv/site", Timestamp: base}); err != nil {
+		t.Fatal(err)
+	}
+	if err := hm.RecordTransferEntry("db", history.TransferHistoryEntry{Direction: "download", LocalPath: "/tmp/dumps", RemotePath: "/var/backups", Recursive: true, Timestamp: base.Add(time.Minute), Outcome: history.TransferFailed, Error: "permission denied"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewHistoryView(hm, "/home/me/.ssh/config", NewStyles(160), 160, 40)
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "✗  ↓ db:/var/backups → /tmp/dumps") || !strings.Contains(view, "permission denied") {
+		t.Errorf("Expected the failed download first with its error, got:\n%s", view)
+	}
+
+	// The upload of a directory was recorded without the recursive flag
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Run scp -r -F /home/me/.ssh/config -o RemoteCommand=none -o RequestTTY=no "+localDir+" web:/srv/site? (y/N)") {
+		t.Errorf("Expected the scp command to confirm, got:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Fatal("Expected confirming to run the transfer")
+	}
+	rerun, ok := cmd().(transferRerunMsg)
+	if !ok {
+		t.Fatalf("Expected a transferRerunMsg")
+	}
+	want := transfer.TransferRequest{Host: "web", Direction: transfer.Upload, LocalPath: localDir, RemotePath: "/srv/site", Recursive: true, ConfigFile: "/home/me/.ssh/config"}
+	if *rerun.request != want {
+		t.Errorf("Re-run request = %+v, want %+v", *rerun.request, want)
+	}
+}
+
+func TestHistoryViewPrunesTransfer(t *testing.T) {
+	hm := newTestHistoryManager(t)
+	if err := hm.RecordTransfer("web", "upload", "/tmp/a.txt", "/tmp/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewHistoryView(hm, "", NewStyles(120), 120, 40)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if cmd != nil || m.confirmRerun {
+		t.Fatal("Expected n outside a confirmation to do nothing")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if len(m.records) != 0 || len(hm.GetTransfers("web", 0)) != 0 {
+		t.Errorf("Expected the transfer to be pruned, got %+v", m.records)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "No transfers yet") {
+		t.Errorf("Expected an empty history, got:\n%s", view)
+	}
+}
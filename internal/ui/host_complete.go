@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/validation"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hostMatchesShown is how many completions are listed below a host input
+const hostMatchesShown = 5
+
+// jumpHostCandidates returns the names a jump host input completes: the hosts
+// tagged as bastions first, then every other host, leaving out exclude and
+// wildcard patterns
+func jumpHostCandidates(hosts []config.SSHHost, exclude string) []string {
+	var names []string
+	add := func(name string) {
+		if name != exclude && !validation.IsHostPattern(name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range bastionHosts(hosts) {
+		add(name)
+	}
+	for _, host := range hosts {
+		add(host.Name)
+	}
+	return names
+}
+
+// completeHostNames makes input complete the names of hosts, bastions first.
+// Whatever is typed is kept when nothing matches. Completions are cycled with
+// ctrl+n/ctrl+p and accepted with → or Tab, as forms keep up, down and Tab for
+// moving between fields.
+func completeHostNames(input *textinput.Model, hosts []config.SSHHost, exclude string) {
+	input.ShowSuggestions = true
+	input.SetSuggestions(jumpHostCandidates(hosts, exclude))
+	input.KeyMap.AcceptSuggestion = key.NewBinding(key.WithKeys("tab", "right"))
+	input.KeyMap.NextSuggestion = key.NewBinding(key.WithKeys("ctrl+n"))
+	input.KeyMap.PrevSuggestion = key.NewBinding(key.WithKeys("ctrl+p"))
+}
+
+// renderHostMatches lists the completions of a host input, the current one
+// highlighted, or returns "" while there are none
+func renderHostMatches(input textinput.Model) string {
+	if !input.Focused() {
+		return ""
+	}
+	matches := input.MatchedSuggestions()
+	if len(matches) == 0 || (len(matches) == 1 && strings.EqualFold(matches[0], input.Value())) {
+		return ""
+	}
+
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	currentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+
+	// Keep the current completion in the window shown
+	current := input.CurrentSuggestionIndex()
+	start := max(0, min(current-hostMatchesShown+1, len(matches)-hostMatchesShown))
+	end := min(start+hostMatchesShown, len(matches))
+
+	var parts []string
+	for i := start; i < end; i++ {
+		if i == current {
+			parts = append(parts, currentStyle.Render(matches[i]))
+		} else {
+			parts = append(parts, mutedStyle.Render(matches[i]))
+		}
+	}
+	line := strings.Join(parts, mutedStyle.Render(" • "))
+	if more := len(matches) - end + start; more > 0 {
+		line += mutedStyle.Render(fmt.Sprintf(" (+%d)", more))
+	}
+	return line
+}
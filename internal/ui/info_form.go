@@ -1,9 +1,15 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/keys"
+	"github.com/xvertile/sshc/internal/sshenv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -16,6 +22,38 @@ type infoFormModel struct {
 	height     int
 	configFile string
 	hostName   string
+	lastLogin  time.Time
+	hasLogin   bool
+
+	// Key the server accepted on the last verbose connect
+	acceptedKey *history.AcceptedKey
+
+	// Pre-auth banner of the server, or a note on why it was not fetched
+	banner       string
+	bannerNote   string
+	bannerOffset int
+
+	// Passphrase state of the host's IdentityFile
+	identityEncrypted bool
+	identityInAgent   bool
+
+	// Effective settings with the file and line defining them, shown with b
+	blame     []config.EffectiveSetting
+	showBlame bool
+
+	// Maintenance of the host, if it is under maintenance
+	maintenance   config.Maintenance
+	inMaintenance bool
+
+	// Usage limit of the host, with the global connect rate filled in
+	usageLimit config.UsageLimit
+
+	// OS and uptime last probed on the host
+	osInfo    connectivity.OSInfo
+	hasOSInfo bool
+
+	// Environment overrides of the host and how each reaches the session
+	envPlan []sshenv.Override
 }
 
 // Messages for communication with parent model
@@ -25,6 +63,24 @@ type infoFormEditMsg struct {
 
 type infoFormCancelMsg struct{}
 
+type infoFormTagMsg struct {
+	tag string
+}
+
+type infoFormColorMsg struct {
+	hostName string
+}
+
+// infoFormMaintenanceMsg asks to start or end the maintenance of the host
+type infoFormMaintenanceMsg struct {
+	hostName string
+}
+
+// infoFormAddKeyMsg asks to load an identity file into the agent with ssh-add
+type infoFormAddKeyMsg struct {
+	path string
+}
+
 // NewInfoForm creates a new info form model for displaying host details in read-only mode
 func NewInfoForm(hostName string, styles Styles, width, height int, configFile string) (*infoFormModel, error) {
 	// Get the existing host configuration
@@ -41,14 +97,33 @@ func NewInfoForm(hostName string, styles Styles, width, height int, configFile s
 		return nil, err
 	}
 
-	return &infoFormModel{
+	m := &infoFormModel{
 		host:       host,
 		hostName:   hostName,
 		configFile: configFile,
 		styles:     styles,
 		width:      width,
 		height:     height,
-	}, nil
+	}
+	m.checkIdentity()
+	return m, nil
+}
+
+// checkIdentity looks up whether the identity file needs a passphrase and is loaded in the agent
+func (m *infoFormModel) checkIdentity() {
+	m.identityEncrypted, m.identityInAgent = false, false
+	if m.host.Identity == "" {
+		return
+	}
+	if encrypted, err := keys.IsEncrypted(m.host.Identity); err == nil && encrypted {
+		m.identityEncrypted = true
+		m.identityInAgent = keys.InAgent(m.host.Identity)
+	}
+}
+
+// canAddKey reports whether ssh-add would save a passphrase prompt when connecting
+func (m *infoFormModel) canAddKey() bool {
+	return m.identityEncrypted && !m.identityInAgent
 }
 
 func (m *infoFormModel) Init() tea.Cmd {
@@ -71,6 +146,41 @@ func (m *infoFormModel) Update(msg tea.Msg) (*infoFormModel, tea.Cmd) {
 		case "e", "enter":
 			// Switch to edit mode
 			return m, func() tea.Msg { return infoFormEditMsg{hostName: m.hostName} }
+
+		case "c":
+			return m, func() tea.Msg { return infoFormColorMsg{hostName: m.hostName} }
+
+		case "m":
+			return m, func() tea.Msg { return infoFormMaintenanceMsg{hostName: m.hostName} }
+
+		case "b":
+			m.showBlame = !m.showBlame
+			if m.showBlame && m.blame == nil {
+				m.loadBlame()
+			}
+
+		case "up", "k":
+			if m.bannerOffset > 0 {
+				m.bannerOffset--
+			}
+
+		case "down", "j":
+			if m.bannerOffset < len(m.bannerLines())-bannerHeight {
+				m.bannerOffset++
+			}
+
+		case "a":
+			if m.canAddKey() {
+				path := m.host.Identity
+				return m, func() tea.Msg { return infoFormAddKeyMsg{path: path} }
+			}
+
+		default:
+			// Digits select a tag chip to filter the host list by
+			if index, ok := tagDigitIndex(msg.String()); ok && index < len(m.host.Tags) {
+				tag := m.host.Tags[index]
+				return m, func() tea.Msg { return infoFormTagMsg{tag: tag} }
+			}
 		}
 	}
 
@@ -91,14 +201,26 @@ func (m *infoFormModel) View() string {
 		value string
 	}{
 		{"Host Name", m.host.Name},
-		{"Config File", formatConfigFile(m.host.SourceFile)},
+		{"Description", formatOptionalValue(m.host.Description)},
+		{"Config File", formatConfigFileTarget(m.host.SourceFile)},
 		{"Hostname/IP", m.host.Hostname},
+		{"Canonical Name", formatCanonicalNames(*m.host)},
+		{"Host Key Alias", formatOptionalValue(m.host.HostKeyAlias)},
+		{"Route", formatRoute(*m.host)},
 		{"User", formatOptionalValue(m.host.User)},
 		{"Port", formatOptionalValue(m.host.Port)},
-		{"Identity File", formatOptionalValue(m.host.Identity)},
+		{"Identity File", m.formatIdentity()},
+		{"Accepted Key", m.formatAcceptedKey()},
 		{"ProxyJump", formatOptionalValue(m.host.ProxyJump)},
+		{"ProxyCommand", formatOptionalValue(m.host.ProxyCommand)},
 		{"SSH Options", formatSSHOptions(m.host.Options)},
-		{"Tags", formatTags(m.host.Tags)},
+		{"Environment", formatOptionalValue(sshenv.Describe(m.envPlan))},
+		{"Tags", formatTagChips(m.host.Tags)},
+		{"Expires", formatExpiry(*m.host)},
+		{"Maintenance", m.formatMaintenance()},
+		{"Usage Limit", formatUsageLimit(m.usageLimit)},
+		{"OS", m.formatOSInfo()},
+		{"Last Login", m.formatLastLogin()},
 	}
 
 	// Render each section
@@ -129,6 +251,10 @@ func (m *infoFormModel) View() string {
 		b.WriteString("\n")
 	}
 
+	b.WriteString(m.renderBanner())
+	if m.showBlame {
+		b.WriteString(m.renderBlame())
+	}
 	b.WriteString("\n")
 
 	// Action instructions
@@ -148,6 +274,50 @@ func (m *infoFormModel) View() string {
 	b.WriteString(helpStyle.Render(" - Switch to edit mode"))
 	b.WriteString("\n")
 
+	b.WriteString("  ")
+	b.WriteString(actionStyle.Render("c"))
+	b.WriteString(helpStyle.Render(" - Set color label"))
+	b.WriteString("\n")
+
+	b.WriteString("  ")
+	b.WriteString(actionStyle.Render("m"))
+	if m.inMaintenance {
+		b.WriteString(helpStyle.Render(" - End maintenance"))
+	} else {
+		b.WriteString(helpStyle.Render(" - Start maintenance, for the host or one of its tags"))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("  ")
+	b.WriteString(actionStyle.Render("b"))
+	if m.showBlame {
+		b.WriteString(helpStyle.Render(" - Hide where settings are defined"))
+	} else {
+		b.WriteString(helpStyle.Render(" - Show where settings are defined"))
+	}
+	b.WriteString("\n")
+
+	if m.canAddKey() {
+		b.WriteString("  ")
+		b.WriteString(actionStyle.Render("a"))
+		b.WriteString(helpStyle.Render(" - Add key to agent (" + keys.AddCommandLine(m.host.Identity) + ")"))
+		b.WriteString("\n")
+	}
+
+	if len(m.bannerLines()) > bannerHeight {
+		b.WriteString("  ")
+		b.WriteString(actionStyle.Render("↑/↓"))
+		b.WriteString(helpStyle.Render(" - Scroll banner"))
+		b.WriteString("\n")
+	}
+
+	if len(m.host.Tags) > 0 {
+		b.WriteString("  ")
+		b.WriteString(actionStyle.Render(fmt.Sprintf("1-%d", min(len(m.host.Tags), 9))))
+		b.WriteString(helpStyle.Render(" - Filter host list by tag"))
+		b.WriteString("\n")
+	}
+
 	b.WriteString("  ")
 	b.WriteString(actionStyle.Render("q/Esc"))
 	b.WriteString(helpStyle.Render(" - Return to host list"))
@@ -180,6 +350,17 @@ func formatOptionalValue(value string) string {
 	return value
 }
 
+// formatIdentity marks a passphrase-protected identity file and whether the agent holds it
+func (m *infoFormModel) formatIdentity() string {
+	if !m.identityEncrypted {
+		return formatOptionalValue(m.host.Identity)
+	}
+	if m.identityInAgent {
+		return m.host.Identity + " 🔒 (loaded in agent)"
+	}
+	return m.host.Identity + " 🔒 (passphrase required, not in agent)"
+}
+
 func formatSSHOptions(options string) string {
 	if options == "" {
 		return "Not set"
@@ -187,11 +368,119 @@ func formatSSHOptions(options string) string {
 	return options
 }
 
-func formatTags(tags []string) string {
+// formatCanonicalNames lists the names ssh tries after hostname canonicalization, in order
+func formatCanonicalNames(host config.SSHHost) string {
+	names := host.CanonicalHostnames()
+	if len(names) == 1 {
+		return "Not set"
+	}
+	return strings.Join(names, ", then ")
+}
+
+func formatRoute(host config.SSHHost) string {
+	switch {
+	case host.ProxyJump != "" && host.ProxyCommand != "":
+		return "via proxy (ProxyJump and ProxyCommand both set, ssh uses the first)"
+	case host.ProxyJump != "":
+		return "via proxy (jump host " + host.ProxyJump + ")"
+	case host.ProxyCommand != "":
+		return "via proxy (ProxyCommand)"
+	}
+	return "direct"
+}
+
+// formatLastLogin shows the last connection both relative and as local and UTC timestamps
+func (m *infoFormModel) formatLastLogin() string {
+	if !m.hasLogin {
+		return "Never"
+	}
+	return formatLoginDetails(m.lastLogin, time.Now(), time.Local)
+}
+
+// formatAcceptedKey shows the key recorded by the last verbose connect
+func (m *infoFormModel) formatAcceptedKey() string {
+	if m.acceptedKey == nil {
+		return "Not set"
+	}
+	key := m.acceptedKey
+	value := key.Path
+	if key.Type != "" || key.Fingerprint != "" {
+		value += " (" + strings.TrimSpace(key.Type+" "+key.Fingerprint) + ")"
+	}
+	return value + ", " + formatTimeAgo(key.Timestamp)
+}
+
+func formatExpiry(host config.SSHHost) string {
+	if host.Expires == "" {
+		return "Not set"
+	}
+	if host.IsExpired(time.Now()) {
+		return host.Expires + " (expired)"
+	}
+	return host.Expires
+}
+
+// formatTagChips numbers the tags so they can be picked with digit keys
+func formatTagChips(tags []string) string {
 	if len(tags) == 0 {
 		return "Not set"
 	}
-	return strings.Join(tags, ", ")
+	chips := make([]string, len(tags))
+	for i, tag := range tags {
+		if i < 9 {
+			chips[i] = fmt.Sprintf("[%d] %s", i+1, tag)
+		} else {
+			chips[i] = tag
+		}
+	}
+	return strings.Join(chips, "  ")
+}
+
+// bannerHeight is how many banner lines are shown before it scrolls
+const bannerHeight = 8
+
+// setBanner shows the result of fetching the pre-auth banner. Hosts without one,
+// and hosts that could not be reached, get no banner section.
+func (m *infoFormModel) setBanner(banner string, err error) {
+	m.banner, m.bannerNote, m.bannerOffset = banner, "", 0
+	if errors.Is(err, connectivity.ErrBannerProxied) {
+		m.bannerNote = "Not fetched: the host is reached through a jump host"
+	}
+}
+
+func (m *infoFormModel) bannerLines() []string {
+	if m.banner == "" {
+		return nil
+	}
+	return strings.Split(m.banner, "\n")
+}
+
+// renderBanner renders the visible part of the banner below the host details
+func (m *infoFormModel) renderBanner() string {
+	lines := m.bannerLines()
+	if len(lines) == 0 && m.bannerNote == "" {
+		return ""
+	}
+
+	labelStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Width(15).
+		AlignHorizontal(lipgloss.Right)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+
+	var value string
+	if len(lines) == 0 {
+		value = mutedStyle.Render(m.bannerNote)
+	} else {
+		end := min(m.bannerOffset+bannerHeight, len(lines))
+		value = strings.Join(lines[m.bannerOffset:end], "\n")
+		if len(lines) > bannerHeight {
+			value += "\n" + mutedStyle.Render(fmt.Sprintf("lines %d-%d of %d", m.bannerOffset+1, end, len(lines)))
+		}
+	}
+
+	return "\n" + lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render("Banner:"), " ", value) + "\n"
 }
 
 // Standalone wrapper for info form (for testing or standalone use)
@@ -226,3 +515,34 @@ func RunInfoForm(hostName string, configFile string) error {
 	_, err = p.Run()
 	return err
 }
+
+// formatOSInfo describes the OS last probed on the host
+func (m *infoFormModel) formatOSInfo() string {
+	if !m.hasOSInfo {
+		return "Not set"
+	}
+	return formatOSInfo(m.osInfo, time.Now())
+}
+
+// formatUsageLimit describes the usage limit of the host
+func formatUsageLimit(limit config.UsageLimit) string {
+	var parts []string
+	if limit.MaxUsers > 0 {
+		parts = append(parts, fmt.Sprintf("max %d concurrent users", limit.MaxUsers))
+	}
+	if limit.MaxConnects > 0 {
+		parts = append(parts, fmt.Sprintf("warn over %d connects in %s", limit.MaxConnects, formatRateWindow(limit.WindowDuration())))
+	}
+	if len(parts) == 0 {
+		return "Not set"
+	}
+	return strings.Join(parts, " • ")
+}
+
+// formatMaintenance describes the maintenance of the host
+func (m *infoFormModel) formatMaintenance() string {
+	if !m.inMaintenance {
+		return "Not set"
+	}
+	return maintenanceIndicator + " " + formatMaintenanceEnd(m.maintenance.Until)
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// loadBlame resolves where each setting of the host is defined. It is only
+// informational, so a config that cannot be resolved leaves the section empty.
+func (m *infoFormModel) loadBlame() {
+	configPath := m.configFile
+	if configPath == "" {
+		var err error
+		if configPath, err = config.GetDefaultSSHConfigPath(); err != nil {
+			return
+		}
+	}
+	effective, err := config.ResolveEffectiveHost(m.hostName, configPath)
+	if err != nil {
+		return
+	}
+	m.blame = effective.Settings
+}
+
+// renderBlame lists every effective setting of the host with the file and line it
+// comes from, like git blame. Inherited settings name the Host pattern that sets them.
+func (m *infoFormModel) renderBlame() string {
+	labelStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Width(15).
+		AlignHorizontal(lipgloss.Right)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+
+	if len(m.blame) == 0 {
+		return "\n" + lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render("Blame:"), " ", mutedStyle.Render("No settings found")) + "\n"
+	}
+
+	keywordWidth, valueWidth := 0, 0
+	for _, setting := range m.blame {
+		keywordWidth = max(keywordWidth, len(setting.Keyword))
+		valueWidth = max(valueWidth, lipgloss.Width(setting.Value))
+	}
+	valueWidth = min(valueWidth, 40)
+
+	var lines []string
+	for _, setting := range m.blame {
+		value := ansi.Truncate(setting.Value, valueWidth, "…")
+		source := fmt.Sprintf("%s:%d", abbreviateHome(setting.SourceFile), setting.Line)
+		if setting.Inherited {
+			source += " (Host " + setting.Pattern + ")"
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s  %s", keywordWidth, setting.Keyword, value+strings.Repeat(" ", valueWidth-ansi.StringWidth(value)), mutedStyle.Render(source)))
+	}
+
+	return "\n" + lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render("Blame:"), " ", strings.Join(lines, "\n")) + "\n"
+}
+
+// abbreviateHome shortens a path under the home directory to start with ~
+func abbreviateHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel) {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
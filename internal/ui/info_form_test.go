@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func newTestInfoForm(host config.SSHHost) *infoFormModel {
+	return &infoFormModel{host: &host, hostName: host.Name, styles: NewStyles(120), width: 120, height: 60}
+}
+
+func TestInfoFormBanner(t *testing.T) {
+	m := newTestInfoForm(config.SSHHost{Name: "web", Hostname: "web.example.com"})
+	if view := ansi.Strip(m.View()); strings.Contains(view, "Banner:") {
+		t.Errorf("Expected no banner section before one is fetched, got:\n%s", view)
+	}
+
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, fmt.Sprintf("notice line %d", i))
+	}
+	m.setBanner(strings.Join(lines, "\n"), nil)
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "notice line 1") || strings.Contains(view, "notice line 9") {
+		t.Errorf("Expected the first %d banner lines, got:\n%s", bannerHeight, view)
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	view = ansi.Strip(m.View())
+	if !strings.Contains(view, "notice line 12") || !strings.Contains(view, "lines 5-12 of 12") {
+		t.Errorf("Expected scrolling to stop at the last line, got:\n%s", view)
+	}
+}
+
+func TestInfoFormBannerNoteForProxiedHost(t *testing.T) {
+	m := newTestInfoForm(config.SSHHost{Name: "db", Hostname: "10.0.0.5", ProxyJump: "bastion"})
+	m.setBanner("", connectivity.ErrBannerProxied)
+
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "reached through a jump host") {
+		t.Errorf("Expected a note explaining the missing banner, got:\n%s", view)
+	}
+}
+
+func TestInfoFormBlame(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config")
+	content := "Host *\n    User admin\n\nHost web\n    HostName web.example.com\n"
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newTestInfoForm(config.SSHHost{Name: "web", Hostname: "web.example.com"})
+	m.configFile = configFile
+	if view := ansi.Strip(m.View()); strings.Contains(view, "Blame:") {
+		t.Errorf("Expected no blame section before b is pressed, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "config:2 (Host *)") {
+		t.Errorf("Expected User to point at the wildcard block, got:\n%s", view)
+	}
+	if !strings.Contains(view, "config:5") {
+		t.Errorf("Expected HostName to point at its line, got:\n%s", view)
+	}
+}
@@ -0,0 +1,134 @@
+//go:build integration
+
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshtest"
+)
+
+// startFixture runs an sshd stand-in and returns a model whose config has it as
+// "fixture", plus "inner" reached through it with ProxyJump. The client logs in
+// with a key from an agent.
+func startFixture(t *testing.T, extra string) (*sshtest.Server, *Model) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh not available")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.Mkdir(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	server := sshtest.NewServer(t)
+	server.Authorize(sshtest.StartAgent(t))
+	configFile := filepath.Join(home, "config")
+	content := server.Config("fixture") + extra + server.Config("inner") + "  ProxyJump fixture\n"
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := config.ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, &Model{configFile: configFile, hosts: hosts}
+}
+
+func TestIntegrationConnectCommand(t *testing.T) {
+	server, m := startFixture(t, "  RemoteCommand echo connected to $HOME\n  RequestTTY no\n")
+
+	cmd := m.connectCommand("fixture", m.sshDestination("fixture"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%q failed: %v\n%s", cmd.Args, err, out)
+	}
+	if got, want := strings.TrimSpace(string(out)), "connected to "+server.Home; got != want {
+		t.Errorf("session printed %q, want %q", got, want)
+	}
+	if cmd.hookTarget.Hostname != server.Host {
+		t.Errorf("hook target hostname = %q, want %q", cmd.hookTarget.Hostname, server.Host)
+	}
+}
+
+func TestIntegrationConnectThroughJumpHost(t *testing.T) {
+	server, m := startFixture(t, "")
+
+	cmd := m.connectCommand("inner", jumpArgs("fixture", append(m.sshDestination("inner"), "echo", "inside")))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%q failed: %v\n%s", cmd.Args, err, out)
+	}
+	if strings.TrimSpace(string(out)) != "inside" {
+		t.Errorf("session printed %q", out)
+	}
+	if commands := server.Commands(); !slices.Equal(commands, []string{"echo inside"}) {
+		t.Errorf("commands run = %q, want only the one on the inner host", commands)
+	}
+}
+
+func TestIntegrationCopyID(t *testing.T) {
+	if _, err := exec.LookPath("ssh-copy-id"); err != nil {
+		t.Skip("ssh-copy-id not available")
+	}
+	server, m := startFixture(t, "")
+	keyPath := filepath.Join(t.TempDir(), "id_deploy")
+	sshtest.GenerateKey(t, keyPath)
+
+	cmd := copyIDCommand(m.configFile, keyPath, "fixture")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%q failed: %v\n%s", cmd.Args, err, out)
+	}
+
+	// The agent key is left out, so only the installed key can log in
+	login := exec.Command("ssh", "-F", m.configFile, "-i", keyPath, "-o", "IdentitiesOnly=yes", "-o", "IdentityAgent=none", "fixture", "true")
+	if out, err := login.CombinedOutput(); err != nil {
+		t.Fatalf("login with the copied key failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(readRemote(t, server, ".ssh/authorized_keys"), "sshtest") {
+		t.Error("authorized_keys does not hold the key")
+	}
+}
+
+func TestIntegrationAuthorizePastedKey(t *testing.T) {
+	server, m := startFixture(t, "")
+	keyPath := filepath.Join(t.TempDir(), "id_pasted")
+	sshtest.GenerateKey(t, keyPath)
+	public, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A comment the remote shell would otherwise run
+	key := strings.TrimSpace(string(public)) + " it's $(touch pwned)"
+
+	cmd := authorizeKeySSHCommand(m.configFile, "fixture", key)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%q failed: %v\n%s", cmd.Args, err, out)
+	}
+	if got := readRemote(t, server, ".ssh/authorized_keys"); got != key+"\n" {
+		t.Errorf("authorized_keys = %q, want %q", got, key+"\n")
+	}
+	if _, err := os.Stat(filepath.Join(server.Home, "pwned")); err == nil {
+		t.Error("the key comment was run by the remote shell")
+	}
+	info, err := os.Stat(filepath.Join(server.Home, ".ssh", "authorized_keys"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("authorized_keys mode = %v, %v, want 0600", info, err)
+	}
+}
+
+func readRemote(t *testing.T, server *sshtest.Server, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(server.Home, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// jumpPromptModel asks for the jump host to connect through this time, in place
+// of the one in the host's config
+type jumpPromptModel struct {
+	hostName   string
+	configured string // ProxyJump of the host, "" when it has none
+	input      textinput.Model
+	styles     Styles
+	width      int
+	height     int
+}
+
+// Messages for communication with parent model
+type jumpPromptSubmitMsg struct {
+	hostName string
+	jump     string // "" connects as configured
+}
+
+type jumpPromptCancelMsg struct{}
+
+// NewJumpPrompt creates the jump host prompt for host, filled in with the jump
+// host last chosen for it
+func NewJumpPrompt(host config.SSHHost, hosts []config.SSHHost, last string, styles Styles, width, height int) *jumpPromptModel {
+	input := textinput.New()
+	input.Placeholder = "bastion or user@host:port"
+	input.CharLimit = 200
+	input.Width = 40
+	completeHostNames(&input, hosts, host.Name)
+	input.SetValue(last)
+	input.CursorEnd()
+	input.Focus()
+
+	return &jumpPromptModel{
+		hostName:   host.Name,
+		configured: host.ProxyJump,
+		input:      input,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+}
+
+func (m *jumpPromptModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *jumpPromptModel) Update(msg tea.Msg) (*jumpPromptModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, func() tea.Msg { return jumpPromptCancelMsg{} }
+
+		case "enter":
+			hostName, jump := m.hostName, strings.TrimSpace(m.input.Value())
+			return m, func() tea.Msg { return jumpPromptSubmitMsg{hostName: hostName, jump: jump} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *jumpPromptModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	configured := "No ProxyJump configured"
+	if m.configured != "" {
+		configured = "Configured: ProxyJump " + m.configured + ", replaced for this connection"
+	}
+
+	lines := []string{
+		titleStyle.Render("Connect to " + m.hostName + " through"),
+		"",
+		mutedStyle.Render(configured),
+		"",
+		"Jump host: " + m.input.View(),
+	}
+	if matches := renderHostMatches(m.input); matches != "" {
+		lines = append(lines, "           "+matches)
+	}
+	lines = append(lines,
+		"",
+		mutedStyle.Render("Enter: connect (empty: as configured) • →/Tab: complete • ctrl+n/p: next/previous • Esc: cancel"),
+	)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
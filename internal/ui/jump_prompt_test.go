@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestJumpOverrideTakesPrecedenceOverProxyJump(t *testing.T) {
+	m := createLargeTestModel(0)
+	m.configFile = "/tmp/sshc-test-config"
+	m.hosts = []config.SSHHost{{Name: "web", Hostname: "10.0.0.1", ProxyJump: "bastion-1"}}
+
+	// Without an override ssh reads the configured ProxyJump itself
+	cmd := m.connectCommand("web", jumpArgs("", m.sshDestination("web")))
+	if want := []string{"ssh", "-F", "/tmp/sshc-test-config", "web"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+
+	// -J is read before the config, and must come before the destination
+	cmd = m.connectCommand("web", jumpArgs("admin@bastion-2:2222", m.sshDestination("web")))
+	if want := []string{"ssh", "-F", "/tmp/sshc-test-config", "-J", "admin@bastion-2:2222", "web"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+
+	// Browsing passes the destination on the command line, the override still goes first
+	m.browseSource = "peer"
+	if got, want := jumpArgs("bastion-2", m.sshDestination("web")), []string{"-J", "bastion-2", "10.0.0.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("browse args = %q, want %q", got, want)
+	}
+}
+
+func TestJumpHostCandidatesListBastionsFirst(t *testing.T) {
+	hosts := []config.SSHHost{
+		{Name: "web"},
+		{Name: "bastion-eu", Tags: []string{"Bastion"}},
+		{Name: "db"},
+		{Name: "*.internal"},
+		{Name: "bastion-us", Tags: []string{"bastion"}},
+	}
+	got := jumpHostCandidates(hosts, "web")
+	if want := []string{"bastion-eu", "bastion-us", "db"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jumpHostCandidates() = %q, want %q", got, want)
+	}
+}
+
+func TestJumpPromptRemembersOverride(t *testing.T) {
+	m := createLargeTestModel(0)
+	m.historyManager = newTestHistoryManager(t)
+	m.hosts = []config.SSHHost{
+		{Name: "web", Hostname: "10.0.0.1", ProxyJump: "bastion-1"},
+		{Name: "bastion-1", Tags: []string{"bastion"}},
+		{Name: "bastion-2", Tags: []string{"bastion"}},
+	}
+	m.filteredHosts = m.hosts
+	m.rebuildEntries()
+	m.updateTableRows()
+	m.table.SetCursor(0)
+
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		updated, cmd := m.Update(msg)
+		m = updated.(Model)
+		if cmd == nil {
+			return
+		}
+		// Deliver the prompt's own messages, leave starting ssh alone
+		switch msg := cmd().(type) {
+		case jumpPromptSubmitMsg, jumpPromptCancelMsg:
+			updated, _ = m.Update(msg)
+			m = updated.(Model)
+		}
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'J'}})
+	if m.viewMode != ViewJumpPrompt {
+		t.Fatalf("viewMode = %v, want the jump prompt", m.viewMode)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Configured: ProxyJump bastion-1") {
+		t.Errorf("prompt does not show the configured jump host:\n%s", view)
+	}
+
+	// Typing completes bastions, → takes the completion
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bastion")})
+	press(tea.KeyMsg{Type: tea.KeyCtrlN})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if got := m.jumpPrompt.input.Value(); got != "bastion-2" {
+		t.Fatalf("input = %q, want the second bastion", got)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != ViewList || m.connectionHost != "web" || m.connectionJump != "bastion-2" {
+		t.Fatalf("viewMode = %v, connection = %s via %q", m.viewMode, m.connectionHost, m.connectionJump)
+	}
+	if got := m.historyManager.GetJumpOverride("web"); got != "bastion-2" {
+		t.Errorf("recorded override = %q", got)
+	}
+
+	// The next time the override is offered as the default
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'J'}})
+	if got := m.jumpPrompt.input.Value(); got != "bastion-2" {
+		t.Errorf("default = %q, want the last override", got)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.viewMode != ViewList || m.jumpPrompt != nil {
+		t.Error("Expected Esc to close the prompt")
+	}
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// listKeyRoute is where handleListViewKeys sends a key of the table
+type listKeyRoute int
+
+const (
+	routeOther    listKeyRoute = iota // Enter, Esc, Tab and the like, handled by name
+	routePrefix                       // Completes or cancels a pending movement prefix
+	routeMovement                     // Moves the cursor
+	routeAction                       // Triggers the action bound to the key
+)
+
+// gotoTopPrefix is the key that, pressed twice, jumps to the first host
+const gotoTopPrefix = "g"
+
+// listTableKeyMap moves the table's cursor with config.MovementKeys only, so the
+// letters the table binds by default stay free for actions
+func listTableKeyMap() table.KeyMap {
+	return table.KeyMap{
+		LineUp:       key.NewBinding(key.WithKeys("up", "k")),
+		LineDown:     key.NewBinding(key.WithKeys("down", "j")),
+		PageUp:       key.NewBinding(key.WithKeys("pgup")),
+		PageDown:     key.NewBinding(key.WithKeys("pgdown")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d")),
+		GotoTop:      key.NewBinding(key.WithKeys("home")),
+		GotoBottom:   key.NewBinding(key.WithKeys("end", "G")),
+	}
+}
+
+// routeListKey returns where a key pressed in the table goes, and the action it
+// triggers for routeAction. While a movement prefix is pending no action fires,
+// whatever the key.
+func (m Model) routeListKey(key string) (listKeyRoute, string) {
+	if m.pendingPrefix != "" {
+		return routePrefix, ""
+	}
+	if config.IsMovementKey(key) {
+		return routeMovement, ""
+	}
+	// Bindings name the space bar "space"
+	if key == " " {
+		key = "space"
+	}
+	kb := m.keyBindings()
+	if action := kb.ActionForKey(key); action != "" {
+		return routeAction, action
+	}
+	return routeOther, ""
+}
+
+// handleMovementKey moves the cursor of the table. The first g of gg only starts
+// the prefix.
+func (m Model) handleMovementKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == gotoTopPrefix {
+		m.pendingPrefix = gotoTopPrefix
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	m.syncRowWindow(msg)
+	return m, cmd
+}
+
+// handlePrefixKey completes a pending movement prefix, or drops it along with
+// the key when the key does not complete it
+func (m Model) handlePrefixKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prefix := m.pendingPrefix
+	m.pendingPrefix = ""
+	if prefix == gotoTopPrefix && msg.String() == gotoTopPrefix {
+		return m.handleMovementKey(tea.KeyMsg{Type: tea.KeyHome})
+	}
+	return m, nil
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEveryActionKeyIsReachable(t *testing.T) {
+	m := createLargeTestModel(5)
+	for action, key := range config.GetDefaultActionKeys() {
+		if key == "space" {
+			key = " "
+		}
+		if route, got := m.routeListKey(key); route != routeAction || got != action {
+			t.Errorf("key %q routes to %v %q, want action %q", key, route, got, action)
+		}
+	}
+	for _, key := range config.MovementKeys {
+		if route, _ := m.routeListKey(key); route != routeMovement {
+			t.Errorf("movement key %q routes to %v", key, route)
+		}
+	}
+}
+
+func TestVimMovementInList(t *testing.T) {
+	m := createLargeTestModel(300)
+	m.table.Focus()
+	j := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}
+	k := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")}
+	g := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")}
+	steps := []struct {
+		key  tea.KeyMsg
+		want int
+	}{
+		{j, 1},
+		{j, 2},
+		{k, 1},
+		{tea.KeyMsg{Type: tea.KeyCtrlD}, 1 + m.table.Height()/2},
+		{tea.KeyMsg{Type: tea.KeyCtrlU}, 1},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")}, 299},
+		{g, 299}, // Only starts gg
+		{g, 0},
+	}
+	for i, step := range steps {
+		m, _ = press(t, m, step.key)
+		if got := m.selectedIndex(); got != step.want {
+			t.Fatalf("step %d (%s): selected index = %d, want %d", i, step.key, got, step.want)
+		}
+	}
+}
+
+func TestNoActionFiresWhilePrefixPending(t *testing.T) {
+	m := createLargeTestModel(10)
+	m.table.Focus()
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+
+	// g followed by the delete key drops both instead of asking to delete
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if m.deleteMode || m.pendingPrefix != "" {
+		t.Fatalf("deleteMode = %v, pendingPrefix = %q after g d, want neither", m.deleteMode, m.pendingPrefix)
+	}
+	if got := m.selectedIndex(); got != 1 {
+		t.Errorf("selected index = %d after g d, want the cursor left alone", got)
+	}
+
+	// Without the prefix the key works again
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if !m.deleteMode {
+		t.Error("Expected d to ask to delete once the prefix is gone")
+	}
+}
+
+func TestKeyUploadNoLongerOnMovementKey(t *testing.T) {
+	m := createLargeTestModel(10)
+	m.table.Focus()
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	if m.viewMode != ViewList || m.selectedIndex() != 0 {
+		t.Errorf("k: viewMode = %v, selected = %d, want to move up in the list", m.viewMode, m.selectedIndex())
+	}
+
+	if m, _ := press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")}); m.viewMode != ViewSSHKeyUpload {
+		t.Errorf("U: viewMode = %v, want the key upload form", m.viewMode)
+	}
+	if m, _ := press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")}); m.viewMode != ViewK8sAdd {
+		t.Errorf("K: viewMode = %v, want the k8s add form", m.viewMode)
+	}
+}
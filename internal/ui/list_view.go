@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderListView renders the main list interface
+func (m Model) renderListView() string {
+	// Build the interface components
+	components := []string{}
+
+	// Add the ASCII title
+	components = append(components, m.styles.Header.Render(asciiTitle))
+
+	// Add update notification if available (between title and search)
+	if m.updateInfo != nil && m.updateInfo.Available {
+		updateText := fmt.Sprintf("Update available: %s -> %s",
+			m.updateInfo.CurrentVer,
+			m.updateInfo.LatestVer)
+
+		updateStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10")). // Green color
+			Bold(true).
+			Align(lipgloss.Center) // Center the notification
+
+		components = append(components, updateStyle.Render(updateText))
+	}
+
+	// Add error message if there's one to show
+	if m.showingError && m.errorMessage != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")). // Red color
+			Background(lipgloss.Color("1")). // Dark red background
+			Bold(true).
+			Padding(0, 1).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("9")).
+			Align(lipgloss.Center)
+
+		components = append(components, errorStyle.Render("[!] "+m.errorMessage))
+	}
+
+	// Add the search bar with the appropriate style based on focus
+	searchPrompt := "Search (/ to focus): "
+	if m.searchMode {
+		components = append(components, m.styles.SearchFocused.Render(searchPrompt+m.searchInput.View()))
+	} else {
+		components = append(components, m.styles.SearchUnfocused.Render(searchPrompt+m.searchInput.View()))
+	}
+
+	// Add the table with the appropriate style based on focus
+	if m.compactLayout() {
+		// Narrow terminals get a plain list without borders
+		components = append(components, m.renderCompactList())
+	} else if m.searchMode || m.drawer.open {
+		// The table is not focused, use the unfocused style
+		components = append(components, m.styles.TableUnfocused.Render(m.table.View()))
+	} else {
+		// The table is focused, use the focused style with the primary color
+		components = append(components, m.styles.TableFocused.Render(m.table.View()))
+	}
+
+	// The message drawer sits between the table and the help text
+	if m.drawer.open {
+		components = append(components, m.renderDrawer())
+	}
+
+	components = append(components, m.renderStatusBar())
+
+	// Join all components vertically with center alignment
+	mainView := lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(
+			lipgloss.Center,
+			components...,
+		),
+	)
+
+	// If in delete mode, overlay the confirmation dialog
+	if m.deleteMode {
+		// Combine the main view with the confirmation dialog overlay
+		confirmation := m.renderDeleteConfirmation()
+
+		// Center the confirmation dialog on the screen
+		centeredConfirmation := lipgloss.Place(
+			m.width,
+			m.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			confirmation,
+		)
+
+		return centeredConfirmation
+	}
+
+	return mainView
+}
+
+// renderStatusBar renders the line below the table: the mode indicators, the
+// message count and the key hints, constrained to the table width
+func (m Model) renderStatusBar() string {
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	// Calculate table width from current columns
+	tableWidth := m.getTableWidth()
+
+	kb := m.keyBindings()
+	var helpParts []string
+	if badge := m.drawerBadge(); badge != "" {
+		helpParts = append(helpParts, badge, mutedStyle.Render(" "))
+	}
+	if m.dryRun {
+		dryRunStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning)).Bold(true)
+		helpParts = append(helpParts, dryRunStyle.Render("[dry run]"), mutedStyle.Render(" "))
+	}
+	if m.browsing() {
+		browseStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+		helpParts = append(helpParts, browseStyle.Render("[browsing "+m.browseSource+", read-only]"), mutedStyle.Render(" "))
+	}
+	if m.configStale {
+		staleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning))
+		helpParts = append(helpParts, staleStyle.Render(fmt.Sprintf("[config changed on disk, %s: reload]", kb.KeyForAction(config.ActionReload))), mutedStyle.Render(" "))
+	}
+	if label := m.workspaceLabel(); label != "" {
+		workspaceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+		helpParts = append(helpParts, workspaceStyle.Render("[workspace: "+label+"]"), mutedStyle.Render(" "))
+	}
+	if m.deepSearch {
+		deepStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+		helpParts = append(helpParts, deepStyle.Render("[deep search]"), mutedStyle.Render(" "))
+	}
+	if m.tagFilterActive() {
+		tagStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+		helpParts = append(helpParts, tagStyle.Render("[tag: "+m.tagFilter+"]"), mutedStyle.Render(" Esc: clear • "))
+	}
+	helpParts = append(helpParts, mutedStyle.Render(m.contextHint()))
+	if !m.searchMode {
+		if len(kb.QuitKeys) > 0 {
+			helpParts = append(helpParts, mutedStyle.Render(fmt.Sprintf(" • %s: quit", kb.QuitKeys[0])))
+		}
+	} else {
+		helpParts = append(helpParts, mutedStyle.Render(" • ctrl+s: search focus "))
+		if m.appConfig != nil && m.appConfig.StartInSearchMode {
+			onStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+			helpParts = append(helpParts, onStyle.Render("[on]"))
+		} else {
+			helpParts = append(helpParts, mutedStyle.Render("[off]"))
+		}
+	}
+
+	// Constrain help text to table width using lipgloss
+	helpStyle := lipgloss.NewStyle().Width(tableWidth).Align(lipgloss.Center)
+	return helpStyle.Render(strings.Join(helpParts, ""))
+}
@@ -0,0 +1,382 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maintenanceIndicator marks hosts under maintenance in the list
+const maintenanceIndicator = "🔧"
+
+// clock returns the current time. Maintenance is checked against it, so tests
+// can move past a maintenance end.
+var clock = time.Now
+
+// maintenanceTarget is what the maintenance prompt applies to: one host, or every
+// host with a tag
+type maintenanceTarget struct {
+	label  string   // Host name, or the tag as "#tag"
+	hosts  []string // Hosts the target covers
+	active bool     // Every host is under maintenance
+	until  time.Time
+}
+
+// maintenancePromptModel starts or ends the maintenance of a host, or of every
+// host sharing one of its tags
+type maintenancePromptModel struct {
+	targets  []maintenanceTarget // The host first, then one per tag
+	selected int
+	input    textinput.Model // When a started maintenance ends
+	err      string
+	styles   Styles
+	width    int
+	height   int
+}
+
+// maintenanceConfirmModel asks for the host name to be typed before connecting
+// to a host under maintenance
+type maintenanceConfirmModel struct {
+	hostName string
+	until    time.Time
+	key      tea.KeyMsg // Key that connects, handled again once confirmed
+	input    textinput.Model
+	err      string
+	styles   Styles
+	width    int
+	height   int
+}
+
+// Messages for communication with parent model
+type maintenanceToggleMsg struct {
+	label string
+	hosts []string
+	start bool
+	until time.Time
+}
+
+type maintenancePromptCancelMsg struct{}
+
+type maintenanceConfirmMsg struct {
+	hostName string
+	key      tea.KeyMsg
+}
+
+type maintenanceConfirmCancelMsg struct{}
+
+// NewMaintenancePrompt creates the prompt for the given targets, the first selected
+func NewMaintenancePrompt(targets []maintenanceTarget, styles Styles, width, height int) *maintenancePromptModel {
+	input := textinput.New()
+	input.Placeholder = "2h, 3d or YYYY-MM-DD HH:MM, empty for no end"
+	input.CharLimit = 30
+	input.Width = 45
+	input.Focus()
+
+	return &maintenancePromptModel{
+		targets: targets,
+		input:   input,
+		styles:  styles,
+		width:   width,
+		height:  height,
+	}
+}
+
+func (m *maintenancePromptModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *maintenancePromptModel) Update(msg tea.Msg) (*maintenancePromptModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, func() tea.Msg { return maintenancePromptCancelMsg{} }
+
+		case "tab":
+			m.selected = (m.selected + 1) % len(m.targets)
+			m.err = ""
+			return m, nil
+
+		case "shift+tab":
+			m.selected = (m.selected + len(m.targets) - 1) % len(m.targets)
+			m.err = ""
+			return m, nil
+
+		case "enter":
+			target := m.targets[m.selected]
+			if target.active {
+				return m, func() tea.Msg {
+					return maintenanceToggleMsg{label: target.label, hosts: target.hosts}
+				}
+			}
+			until, err := config.ParseMaintenanceUntil(m.input.Value(), clock())
+			if err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return maintenanceToggleMsg{label: target.label, hosts: target.hosts, start: true, until: until}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *maintenancePromptModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	labels := make([]string, len(m.targets))
+	for i, target := range m.targets {
+		label := target.label
+		if strings.HasPrefix(label, "#") {
+			label = fmt.Sprintf("%s (%d)", label, len(target.hosts))
+		}
+		if i == m.selected {
+			labels[i] = selectedStyle.Render("[" + label + "]")
+		} else {
+			labels[i] = mutedStyle.Render(" " + label + " ")
+		}
+	}
+
+	target := m.targets[m.selected]
+	lines := []string{
+		titleStyle.Render(maintenanceIndicator + " Maintenance"),
+		"",
+		"For: " + strings.Join(labels, " "),
+		"",
+	}
+	help := "Enter: start • Esc: cancel"
+	if target.active {
+		lines = append(lines, "Under maintenance "+formatMaintenanceEnd(target.until))
+		help = "Enter: end maintenance • Esc: cancel"
+	} else {
+		lines = append(lines, "Until: "+m.input.View())
+	}
+	if len(m.targets) > 1 {
+		help = "Tab: host or tag • " + help
+	}
+	if m.err != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error)).Render(m.err))
+	}
+	lines = append(lines, "", mutedStyle.Render(help))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// NewMaintenanceConfirm creates the confirmation for connecting to a host under
+// maintenance with key
+func NewMaintenanceConfirm(hostName string, until time.Time, key tea.KeyMsg, styles Styles, width, height int) *maintenanceConfirmModel {
+	input := textinput.New()
+	input.Placeholder = hostName
+	input.CharLimit = 100
+	input.Width = 30
+	input.Focus()
+
+	return &maintenanceConfirmModel{
+		hostName: hostName,
+		until:    until,
+		key:      key,
+		input:    input,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+}
+
+func (m *maintenanceConfirmModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *maintenanceConfirmModel) Update(msg tea.Msg) (*maintenanceConfirmModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, func() tea.Msg { return maintenanceConfirmCancelMsg{} }
+
+		case "enter":
+			if strings.TrimSpace(m.input.Value()) != m.hostName {
+				m.err = fmt.Sprintf("Type %s to connect anyway", m.hostName)
+				return m, nil
+			}
+			hostName, key := m.hostName, m.key
+			return m, func() tea.Msg { return maintenanceConfirmMsg{hostName: hostName, key: key} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *maintenanceConfirmModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning)).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Warning)).
+		Padding(1, 3)
+
+	lines := []string{
+		titleStyle.Render(maintenanceIndicator + " " + m.hostName + " is under maintenance"),
+		"",
+		mutedStyle.Render("Maintenance " + formatMaintenanceEnd(m.until)),
+		"",
+		"Type the host name to connect anyway: " + m.input.View(),
+	}
+	if m.err != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error)).Render(m.err))
+	}
+	lines = append(lines, "", mutedStyle.Render("Enter: connect • Esc: cancel"))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// formatMaintenanceEnd describes when a maintenance ends
+func formatMaintenanceEnd(until time.Time) string {
+	if until.IsZero() {
+		return "until it is ended by hand"
+	}
+	return "until " + until.Format("2006-01-02 15:04")
+}
+
+// inMaintenance returns the maintenance of a host, if it holds now
+func (m *Model) inMaintenance(hostName string) (config.Maintenance, bool) {
+	return m.appConfig.InMaintenance(hostName, clock())
+}
+
+// maintenanceTargets returns the host and each of its tags for the maintenance prompt
+func (m *Model) maintenanceTargets(host config.SSHHost) []maintenanceTarget {
+	targets := []maintenanceTarget{m.maintenanceTarget(host.Name, []string{host.Name})}
+	for _, tag := range host.Tags {
+		var hosts []string
+		for _, h := range m.hosts {
+			if slices.ContainsFunc(h.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+				hosts = append(hosts, h.Name)
+			}
+		}
+		targets = append(targets, m.maintenanceTarget("#"+tag, hosts))
+	}
+	return targets
+}
+
+// maintenanceTarget reports whether every host is under maintenance, and until when
+func (m *Model) maintenanceTarget(label string, hosts []string) maintenanceTarget {
+	target := maintenanceTarget{label: label, hosts: hosts, active: len(hosts) > 0}
+	for _, name := range hosts {
+		maintenance, ok := m.inMaintenance(name)
+		if !ok {
+			target.active = false
+			break
+		}
+		target.until = maintenance.Until
+	}
+	return target
+}
+
+// openMaintenancePrompt shows the prompt to start or end the maintenance of a host
+func (m *Model) openMaintenancePrompt(hostName string) tea.Cmd {
+	for _, host := range m.hosts {
+		if host.Name == hostName {
+			m.maintenancePrompt = NewMaintenancePrompt(m.maintenanceTargets(host), m.styles, m.width, m.height)
+			m.viewMode = ViewMaintenancePrompt
+			m.table.Blur()
+			return textinput.Blink
+		}
+	}
+	return nil
+}
+
+// toggleMaintenance starts or ends the maintenance of the hosts of msg and saves it
+func (m *Model) toggleMaintenance(msg maintenanceToggleMsg) tea.Cmd {
+	if m.appConfig == nil {
+		return nil
+	}
+	if msg.start {
+		m.appConfig.SetMaintenance(msg.hosts, msg.until)
+		m.errorMessage = fmt.Sprintf("%s under maintenance %s", msg.label, formatMaintenanceEnd(msg.until))
+	} else {
+		m.appConfig.ClearMaintenance(msg.hosts)
+		m.errorMessage = fmt.Sprintf("Maintenance of %s ended", msg.label)
+	}
+	if err := config.SaveAppConfig(m.appConfig); err != nil {
+		m.errorMessage = fmt.Sprintf("Could not save maintenance: %v", err)
+		m.report(severityError, sourceWrite, m.errorMessage)
+	}
+	m.showingError = true
+	m.updateTableRows()
+	return func() tea.Msg {
+		time.Sleep(2 * time.Second)
+		return errorMsg("clear")
+	}
+}
+
+// blockedByMaintenance asks for the host name to be typed before connecting to a
+// host under maintenance, and reports whether it did. key is handled again once
+// the name was typed, and then goes through.
+func (m *Model) blockedByMaintenance(hostName string, key tea.KeyMsg) bool {
+	if m.maintenanceConfirmed == hostName {
+		m.maintenanceConfirmed = ""
+		return false
+	}
+	maintenance, ok := m.inMaintenance(hostName)
+	if !ok {
+		return false
+	}
+	m.maintenanceConfirm = NewMaintenanceConfirm(hostName, maintenance.Until, key, m.styles, m.width, m.height)
+	m.viewMode = ViewMaintenanceConfirm
+	m.table.Blur()
+	return true
+}
+
+// expireMaintenance ends the maintenance whose end has passed. It runs when the
+// list is loaded and reloaded; until then such hosts already show as available.
+func (m *Model) expireMaintenance() {
+	if m.appConfig == nil || m.browsing() {
+		return
+	}
+	if expired := m.appConfig.ExpireMaintenance(clock()); len(expired) > 0 {
+		debugLogf("Maintenance ended for: %s", strings.Join(expired, ", "))
+		config.SaveAppConfig(m.appConfig)
+	}
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// setTestClock makes now the current time for maintenance until the test ends
+func setTestClock(t *testing.T, now time.Time) {
+	t.Helper()
+	clock = func() time.Time { return now }
+	t.Cleanup(func() { clock = time.Now })
+}
+
+func TestMaintenanceRowEndsLazily(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	setTestClock(t, now)
+	m := createWorkspaceTestModel(t)
+	m.appConfig.SetMaintenance([]string{"node-0000"}, now.Add(time.Hour))
+	m.updateTableRows()
+
+	cell := m.table.Rows()[0][0]
+	if !strings.Contains(cell, maintenanceIndicator) {
+		t.Fatalf("name cell = %q, want the maintenance indicator", cell)
+	}
+	if entry := m.selectedEntry(); entry == nil || entry.Name != "node-0000" {
+		t.Errorf("selectedEntry() = %+v, want node-0000", entry)
+	}
+
+	setTestClock(t, now.Add(time.Hour+time.Second))
+	m.rowCache = nil
+	m.updateTableRows()
+	if cell := m.table.Rows()[0][0]; strings.Contains(cell, maintenanceIndicator) {
+		t.Errorf("name cell = %q after the maintenance ended", cell)
+	}
+
+	m.expireMaintenance()
+	if len(m.appConfig.Maintenance) != 0 {
+		t.Errorf("maintenance after expiry = %v", m.appConfig.Maintenance)
+	}
+}
+
+func TestConnectToHostUnderMaintenance(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m.appConfig.SetMaintenance([]string{"node-0000"}, time.Time{})
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != ViewMaintenanceConfirm {
+		t.Fatalf("Expected the maintenance confirmation, got view %v", m.viewMode)
+	}
+
+	m = typeText(t, m, "node-0001")
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if msg != nil || m.maintenanceConfirm.err == "" {
+		t.Fatalf("Expected a wrong name to be refused, got %T", msg)
+	}
+
+	m.maintenanceConfirm.input.SetValue("")
+	m = typeText(t, m, "node-0000")
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if _, ok := msg.(maintenanceConfirmMsg); !ok {
+		t.Fatalf("Expected the connection to be confirmed, got %T", msg)
+	}
+
+	// The connect command is not run, it would start ssh
+	updated, cmd := m.Update(msg)
+	m = updated.(Model)
+	if cmd == nil || m.viewMode == ViewMaintenanceConfirm || m.maintenanceConfirmed != "" {
+		t.Errorf("Expected the confirmed key to connect, got view %v and %q", m.viewMode, m.maintenanceConfirmed)
+	}
+
+	// The next connection asks again
+	if !m.blockedByMaintenance("node-0000", tea.KeyMsg{Type: tea.KeyEnter}) {
+		t.Error("Expected the next connection to need confirming again")
+	}
+}
+
+func TestMaintenanceForTag(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+
+	m, _ = press(t, m, infoFormMaintenanceMsg{hostName: "node-0000"})
+	if m.viewMode != ViewMaintenancePrompt {
+		t.Fatalf("Expected the maintenance prompt, got view %v", m.viewMode)
+	}
+	// node-0000, #cloud, #zone-0
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = typeText(t, m, "2h")
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	toggle, ok := msg.(maintenanceToggleMsg)
+	if !ok || toggle.label != "#zone-0" {
+		t.Fatalf("Expected maintenance for #zone-0, got %#v", msg)
+	}
+	updated, _ := m.Update(toggle)
+	m = updated.(Model)
+
+	var names []string
+	for name := range m.appConfig.Maintenance {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if want := []string{"node-0000", "node-0008"}; !slices.Equal(names, want) {
+		t.Errorf("hosts under maintenance = %v, want %v", names, want)
+	}
+
+	// The tag is now ended as a whole
+	targets := m.maintenanceTargets(m.hosts[8])
+	if !targets[0].active || !targets[2].active || targets[1].active {
+		t.Errorf("targets = %+v, want node-0008 and #zone-0 active", targets)
+	}
+
+	loaded, err := config.LoadAppConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.Maintenance["node-0008"]; !ok {
+		t.Error("Expected the maintenance to be saved")
+	}
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// markedIndicator follows the names of hosts marked for a bulk move
+const markedIndicator = "✔"
+
+// toggleMark marks the selected host, or unmarks it when it already is
+func (m *Model) toggleMark() tea.Cmd {
+	entry := m.selectedEntry()
+	if entry == nil {
+		return nil
+	}
+	if entry.IsK8s {
+		m.errorMessage = "Kubernetes hosts cannot be marked"
+		m.showingError = true
+		return func() tea.Msg {
+			time.Sleep(2 * time.Second)
+			return errorMsg("clear")
+		}
+	}
+
+	hostName := entry.Name
+	if m.marked[hostName] {
+		delete(m.marked, hostName)
+	} else {
+		if m.marked == nil {
+			m.marked = make(map[string]bool)
+		}
+		m.marked[hostName] = true
+	}
+	m.updateTableRows()
+	return nil
+}
+
+// markedHostNames returns the marked hosts still in the config, in config order
+func (m *Model) markedHostNames() []string {
+	var names []string
+	for _, host := range m.hosts {
+		if m.marked[host.Name] {
+			names = append(names, host.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// resolveBlockConflict writes a merge; tests replace it
+var resolveBlockConflict = config.ResolveBlockConflict
+
+// mergeViewModel shows two blocks declaring the same hosts side by side, lets the
+// side each differing directive is taken from be picked, and writes the merge
+type mergeViewModel struct {
+	conflict   config.BlockConflict
+	directives []config.MergeDirective
+	choices    []config.MergeSide
+	differing  []int // Indexes of the directives the blocks disagree on
+	cursor     int   // Into differing
+	target     config.MergeSide
+	commentOut bool // Comment the other block out instead of removing it
+	writing    bool
+	err        string
+
+	styles Styles
+	width  int
+	height int
+}
+
+// mergeViewDoneMsg reports the outcome of writing a merge
+type mergeViewDoneMsg struct {
+	names  []string
+	target string
+	err    error
+}
+
+type mergeViewCancelMsg struct{}
+
+// NewMergeView opens the merge of a conflict, with each directive taken from
+// the block ssh takes it from today and the merge going to the first block's file
+func NewMergeView(conflict config.BlockConflict, styles Styles, width, height int) *mergeViewModel {
+	directives := config.DiffBlocks(conflict.First, conflict.Second)
+	m := &mergeViewModel{
+		conflict:   conflict,
+		directives: directives,
+		choices:    config.DefaultMergeChoices(directives),
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+	for i, d := range directives {
+		if d.Differs() {
+			m.differing = append(m.differing, i)
+		}
+	}
+	return m
+}
+
+// block returns the block of a side
+func (m *mergeViewModel) block(side config.MergeSide) config.SSHHost {
+	if side == config.MergeSecond {
+		return m.conflict.Second
+	}
+	return m.conflict.First
+}
+
+// choose takes the selected directive from side
+func (m *mergeViewModel) choose(side config.MergeSide) {
+	if len(m.differing) > 0 {
+		m.choices[m.differing[m.cursor]] = side
+	}
+}
+
+func (m *mergeViewModel) Update(msg tea.Msg) (*mergeViewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case mergeViewDoneMsg:
+		// Only failures come back here, the parent closes the view otherwise
+		m.writing = false
+		m.err = fmt.Sprintf("Could not merge: %v", msg.err)
+
+	case tea.KeyMsg:
+		if m.writing {
+			return m, nil
+		}
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return mergeViewCancelMsg{} }
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.differing)-1 {
+				m.cursor++
+			}
+		case "left", "h", "1":
+			m.choose(config.MergeFirst)
+		case "right", "l", "2":
+			m.choose(config.MergeSecond)
+		case " ":
+			if len(m.differing) > 0 {
+				m.choose(1 - m.choices[m.differing[m.cursor]])
+			}
+		case "t":
+			m.target = 1 - m.target
+		case "c":
+			m.commentOut = !m.commentOut
+		case "enter":
+			m.writing, m.err = true, ""
+			return m, m.write()
+		}
+	}
+	return m, nil
+}
+
+// write merges the blocks with the chosen directives into the target file
+func (m *mergeViewModel) write() tea.Cmd {
+	conflict, commentOut := m.conflict, m.commentOut
+	merged := config.MergeBlocks(conflict, m.directives, m.choices)
+	target := m.block(m.target).SourceFile
+	return func() tea.Msg {
+		err := resolveBlockConflict(conflict, merged, target, commentOut)
+		return mergeViewDoneMsg{names: config.MergedNames(conflict), target: target, err: err}
+	}
+}
+
+func (m *mergeViewModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error))
+
+	body := []string{titleStyle.Render("Merge " + strings.Join(m.conflict.Names, ", ")), ""}
+	body = append(body, m.directiveLines()...)
+
+	other := "removed"
+	if m.commentOut {
+		other = "commented out"
+	}
+	body = append(body, "",
+		fmt.Sprintf("Write to:    %s", abbreviateHome(m.block(m.target).SourceFile)),
+		fmt.Sprintf("Other block: %s", other))
+
+	switch {
+	case m.writing:
+		body = append(body, "", helpStyle.Render("Writing..."))
+	case m.err != "":
+		body = append(body, "", errorStyle.Render(m.err))
+	}
+
+	help := "←/→: pick side • t: target file • c: comment out/remove other • Enter: write • Esc: cancel"
+	body = append(body, "", helpStyle.Render(ansi.Truncate(help, max(m.width-10, 20), "…")))
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		container.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+	)
+}
+
+// directiveLines renders the directives as a table of keyword and the value of each
+// block. Differing directives are highlighted, with a dot on the side that wins.
+func (m *mergeViewModel) directiveLines() []string {
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true).Padding(0, 1)
+
+	valueWidth := max((m.width-30)/2, 14)
+	keywordWidth := len("Directive")
+	for _, d := range m.directives {
+		keywordWidth = max(keywordWidth, len(d.Key))
+	}
+	cell := func(value string, chosen, differs bool) string {
+		mark := "  "
+		if differs && chosen {
+			mark = "● "
+		}
+		if value == "" {
+			value = "(not set)"
+		}
+		value = ansi.Truncate(mark+strings.ReplaceAll(value, "\n", " | "), valueWidth, "…")
+		return value + strings.Repeat(" ", max(valueWidth-ansi.StringWidth(value), 0))
+	}
+	source := func(side config.MergeSide) string {
+		block := m.block(side)
+		return fmt.Sprintf("  %s:%d", filepath.Base(block.SourceFile), block.Line)
+	}
+
+	lines := []string{headerStyle.Render(fmt.Sprintf("%-*s  %s  %s", keywordWidth, "Directive",
+		cell(source(config.MergeFirst), false, false), cell(source(config.MergeSecond), false, false)))}
+	if len(m.differing) == 0 {
+		lines = append(lines, mutedStyle.Render("  Both blocks set the same values"))
+	}
+
+	// Leave room for the border, title, header, target, status and help lines
+	visible := max(m.height-16, 3)
+	start := 0
+	if len(m.differing) > 0 && m.differing[m.cursor] >= visible {
+		start = m.differing[m.cursor] - visible + 1
+	}
+	end := min(start+visible, len(m.directives))
+	for i := start; i < end; i++ {
+		d := m.directives[i]
+		differs := d.Differs()
+		line := fmt.Sprintf("%-*s  %s  %s", keywordWidth, d.Key,
+			cell(d.First, m.choices[i] == config.MergeFirst, differs),
+			cell(d.Second, m.choices[i] == config.MergeSecond, differs))
+
+		style := mutedStyle.Padding(0, 1)
+		switch {
+		case len(m.differing) > 0 && i == m.differing[m.cursor]:
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 1)
+		case differs:
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning)).Padding(0, 1)
+		}
+		lines = append(lines, style.Render(line))
+	}
+	return lines
+}
+
+// openMergeView opens the merge of two blocks declaring the same hosts
+func (m *Model) openMergeView(conflict config.BlockConflict) {
+	if m.drawer.open {
+		m.toggleDrawer()
+	}
+	m.mergeView = NewMergeView(conflict, m.styles, m.width, m.height)
+	m.viewMode = ViewMergeBlocks
+	m.table.Blur()
+}
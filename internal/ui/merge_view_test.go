@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// setupMergeTest writes a config whose include declares web1 and web2 again, and
+// returns a model reloaded from it with the paths of both files
+func setupMergeTest(t *testing.T) (Model, string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	for _, env := range []string{"XDG_CONFIG_HOME", "APPDATA", "XDG_STATE_HOME", "LOCALAPPDATA"} {
+		t.Setenv(env, tempDir)
+	}
+	basePath := filepath.Join(tempDir, "base.conf")
+	overridePath := filepath.Join(tempDir, "override.conf")
+	base := "Host web1 web2\n    HostName web.example.com\n    User deploy\n\nInclude " + overridePath + "\n"
+	override := "Host web2 web1\n    HostName web.override.com\n    Port 2222\n\nHost other\n    HostName other.example.com\n"
+	for path, content := range map[string]string{basePath: base, overridePath: override} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := createLargeTestModel(0)
+	m.configFile = basePath
+	if _, err := m.reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	return m, basePath, overridePath
+}
+
+func TestDrawerOpensMergeOfConflictingBlocks(t *testing.T) {
+	m, basePath, overridePath := setupMergeTest(t)
+	if len(m.drawer.entries) != 1 || m.drawer.entries[0].conflict == nil {
+		t.Fatalf("entries = %+v, want the conflict of web1 and web2", m.drawer.entries)
+	}
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'!'}})
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Enter: merge") {
+		t.Errorf("drawer help does not offer the merge:\n%s", view)
+	}
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != ViewMergeBlocks || m.mergeView == nil || m.drawer.open {
+		t.Fatalf("viewMode = %v, drawer open = %v, want the merge view", m.viewMode, m.drawer.open)
+	}
+
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"Merge web1, web2", "base.conf:1", "override.conf:1", "● web.example.com", "● 2222", "Write to:"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("merge view lacks %q:\n%s", want, view)
+		}
+	}
+
+	// Take the override's HostName, write to the override and comment the base out
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRight},
+		{Type: tea.KeyRunes, Runes: []rune{'t'}},
+		{Type: tea.KeyRunes, Runes: []rune{'c'}},
+	} {
+		m, _ = press(t, m, key)
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Enter did not write the merge")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	if m.viewMode != ViewList || m.mergeView != nil {
+		t.Errorf("viewMode = %v after the merge, want the list", m.viewMode)
+	}
+	if !strings.Contains(m.errorMessage, "Merged web1, web2 into") {
+		t.Errorf("toast = %q", m.errorMessage)
+	}
+	if len(m.drawer.entries) != 0 {
+		t.Errorf("entries after the merge = %+v, want the conflict gone", m.drawer.entries)
+	}
+
+	override, _ := os.ReadFile(overridePath)
+	if !strings.HasPrefix(string(override), "Host web1 web2\n    HostName web.override.com\n    User deploy\n    Port 2222\n") {
+		t.Errorf("override.conf =\n%s", override)
+	}
+	base, _ := os.ReadFile(basePath)
+	if !strings.HasPrefix(string(base), "# Merged into "+overridePath+"\n# Host web1 web2\n") {
+		t.Errorf("base.conf =\n%s", base)
+	}
+}
+
+func TestMergeViewKeepsChoicesWhenWriteFails(t *testing.T) {
+	m, _, _ := setupMergeTest(t)
+	previous := resolveBlockConflict
+	resolveBlockConflict = func(config.BlockConflict, config.SSHHost, string, bool) error {
+		return errors.New("permission denied")
+	}
+	t.Cleanup(func() { resolveBlockConflict = previous })
+
+	m.openMergeView(*m.drawer.entries[0].conflict)
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	if m.viewMode != ViewMergeBlocks || m.mergeView == nil {
+		t.Fatalf("viewMode = %v, want the merge view kept open", m.viewMode)
+	}
+	if m.mergeView.choices[m.mergeView.differing[0]] != config.MergeSecond {
+		t.Error("the choice made before the failure was lost")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Could not merge: permission denied") {
+		t.Errorf("merge view does not show the error:\n%s", view)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/connectivity"
 	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/hooks"
+	"github.com/xvertile/sshc/internal/sessions"
+	"github.com/xvertile/sshc/internal/sshver"
+	"github.com/xvertile/sshc/internal/transfer"
 	"github.com/xvertile/sshc/internal/version"
 
 	"github.com/charmbracelet/bubbles/table"
@@ -50,6 +54,26 @@ const (
 	ViewTheme
 	ViewConnectionError
 	ViewSSHKeyUpload
+	ViewTagPicker
+	ViewDualBrowser
+	ViewColorPicker
+	ViewDashboard
+	ViewSnippetPicker
+	ViewOnboard
+	ViewDryRun
+	ViewMount
+	ViewHistory
+	ViewWait
+	ViewVerify
+	ViewJumpPrompt
+	ViewWorkspacePrompt
+	ViewWorkspacePicker
+	ViewColumnPicker
+	ViewMaintenancePrompt
+	ViewMaintenanceConfirm
+	ViewConnectRateConfirm
+	ViewMergeBlocks
+	ViewOpenURL
 )
 
 // PortForwardType defines the type of port forwarding
@@ -82,22 +106,38 @@ type HostEntry struct {
 	K8sHost  *config.K8sHost
 	Tags     []string
 	Hostname string // For display: SSH hostname or K8s namespace/pod
+
+	// Multi-host blocks while they are collapsed: Block identifies the block of a
+	// host, and BlockMembers is set on the row standing for the whole block
+	Block        string
+	BlockMembers []HostEntry
+	Expanded     bool
 }
 
 // Model represents the state of the user interface
 type Model struct {
-	table          table.Model
-	searchInput    textinput.Model
-	hosts          []config.SSHHost
-	filteredHosts  []config.SSHHost
-	searchMode     bool
-	deleteMode     bool
-	deleteHost     string
-	deleteHostIsK8s bool // Track if delete target is a k8s host
-	historyManager *history.HistoryManager
-	pingManager    *connectivity.PingManager
-	sortMode       SortMode
-	configFile     string // Path to the SSH config file
+	table           table.Model
+	searchInput     textinput.Model
+	hosts           []config.SSHHost
+	filteredHosts   []config.SSHHost
+	searchMode      bool
+	pendingPrefix   string // Movement prefix waiting for its second key, such as the first g of gg
+	deleteMode      bool
+	deleteHost      string
+	deleteHostIsK8s bool            // Track if delete target is a k8s host
+	deleteExpired   []string        // Expired hosts queued for bulk deletion
+	marked          map[string]bool // Hosts marked for a bulk move
+	historyManager  *history.HistoryManager
+	pingManager     *connectivity.PingManager
+	banners         map[string]bannerMsg        // Pre-auth banners fetched this session, by host name
+	mounter         *transfer.Mounter           // sshfs mounts made this session
+	annotations     map[string]hooks.Annotation // Notes from the annotation command, by host name
+	drawer          messageDrawer               // Warnings and errors of the session, toggled below the list
+	debugOverlay    *debugOverlayModel          // Timings drawn over every view, toggled with F12
+	sortMode        SortMode
+	absoluteTimes   bool   // Show Last Login as local timestamps instead of "X ago"
+	configFile      string // Path to the SSH config file
+	dryRun          bool   // Show changes to config files instead of writing them
 
 	// Kubernetes hosts
 	k8sHosts         []config.K8sHost
@@ -107,29 +147,104 @@ type Model struct {
 	allEntries      []HostEntry
 	filteredEntries []HostEntry
 
+	// Virtualized table rendering
+	rowCache       map[string]*rowCacheEntry // Formatted cell values keyed by host name
+	rowBuffers     [2][]table.Row            // Row slices reused across rebuilds
+	rowBufferIndex int                       // Buffer currently held by the table
+	tableHeight    int                       // Height last given to the table
+	rowOffset      int                       // Index of the first materialized row
+	searchSeq      int                       // Sequence number of the latest debounced search
+
+	// Modification times of the config files when they were last read, and
+	// whether a later check found them changed on disk
+	configSnapshot configSnapshot
+	configStale    bool
+
+	// Where the hosts were fetched from when browsing a peer's list, or ""
+	browseSource string
+
+	// Ping results arriving before the next refresh tick are shown together
+	pingRefreshPending bool
+	onRowsRebuilt      func() // Called whenever rows are materialized, for tests
+
+	// Multi-host blocks shown as one row, and the ones expanded into their members
+	collapseBlocks bool
+	expandedBlocks map[string]bool
+
+	// Optional columns left out of the host list
+	hiddenColumns []string
+
+	// Tag applied as the search filter by the quick tag filter
+	tagFilter string
+
+	// Name of the workspace last saved or switched to
+	activeWorkspace string
+
+	// Free-text search also matches proxy, identity, remote command and options
+	deepSearch bool
+
+	// Host under maintenance whose name was just typed to connect to it
+	maintenanceConfirmed string
+
+	// Host whose connect rate warning was just confirmed
+	connectRateConfirmed string
+
+	// OS and uptime probed on each host, and the probes still running
+	osCache       *connectivity.OSCache
+	osInfoPending int
+
+	// ssh sessions open to each host, found when the hosts were last pinged
+	openSessions map[string]sessions.Count
+
 	// Application configuration
-	appConfig      *config.AppConfig
+	appConfig *config.AppConfig
 
 	// Version update information
 	updateInfo     *version.UpdateInfo
 	currentVersion string
 
+	// Local ssh client, detected in the background at startup
+	sshVersion      sshver.Version
+	sshVersionErr   error
+	sshVersionKnown bool
+
 	// View management
-	viewMode          ViewMode
-	addForm           *addFormModel
-	editForm          *editFormModel
-	moveForm          *moveFormModel
-	infoForm          *infoFormModel
-	portForwardForm   *portForwardModel
-	transferForm      *transferFormModel
-	quickTransferForm *quickTransferModel
-	remoteBrowserForm *remoteBrowserModel
-	helpForm          *helpModel
-	fileSelectorForm  *fileSelectorModel
-	k8sAddForm        *k8sAddFormModel
-	k8sEditForm       *k8sEditFormModel
-	themePicker       *themePickerModel
-	sshKeyUploadForm  *sshKeyUploadModel
+	viewMode           ViewMode
+	addForm            *addFormModel
+	editForm           *editFormModel
+	moveForm           *moveFormModel
+	infoForm           *infoFormModel
+	portForwardForm    *portForwardModel
+	transferForm       *transferFormModel
+	quickTransferForm  *quickTransferModel
+	remoteBrowserForm  *remoteBrowserModel
+	helpForm           *helpModel
+	fileSelectorForm   *fileSelectorModel
+	k8sAddForm         *k8sAddFormModel
+	k8sEditForm        *k8sEditFormModel
+	themePicker        *themePickerModel
+	sshKeyUploadForm   *sshKeyUploadModel
+	tagPicker          *tagPickerModel
+	dualBrowser        *dualBrowserModel
+	colorPicker        *colorPickerModel
+	dashboard          *dashboardModel
+	snippetPicker      *snippetPickerModel
+	onboard            *onboardModel
+	mountForm          *mountFormModel
+	historyView        *historyViewModel
+	waitView           *waitViewModel
+	verifyView         *verifyViewModel
+	jumpPrompt         *jumpPromptModel
+	workspacePrompt    *workspacePromptModel
+	workspacePicker    *workspacePickerModel
+	columnPicker       *columnPickerModel
+	maintenancePrompt  *maintenancePromptModel
+	maintenanceConfirm *maintenanceConfirmModel
+	connectRateConfirm *connectRateConfirmModel
+	mergeView          *mergeViewModel
+	openURLForm        *openURLModel
+	dryRunView         *dryRunModel
+	dryRunReturn       ViewMode // View to go back to when the dry-run view closes
 
 	// Terminal size and styles
 	width  int
@@ -144,6 +259,7 @@ type Model struct {
 	// Connection retry state
 	connectionHost  string // Host being connected to
 	connectionIsK8s bool   // Whether it's a k8s host
+	connectionJump  string // Jump host chosen at connect time instead of the host's ProxyJump
 	connectionError string // Last connection error
 }
 
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/xvertile/sshc/internal/transfer"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// mountFormModel asks where to mount a host with sshfs
+type mountFormModel struct {
+	hostName   string
+	remotePath textinput.Model
+	mountPoint textinput.Model
+	focus      int // 0=remote path, 1=mount point
+	err        string
+
+	styles Styles
+	width  int
+	height int
+}
+
+// mountFormSubmitMsg asks the model to mount a host
+type mountFormSubmitMsg struct {
+	hostName   string
+	remotePath string
+	mountPoint string
+}
+
+type mountFormCancelMsg struct{}
+
+// mountResultMsg is sent when a mount or unmount finished
+type mountResultMsg struct {
+	hostName   string
+	mountPoint string
+	unmount    bool
+	err        error
+}
+
+// NewMountForm creates the form for mounting a host, with ~/mnt/<host> as mount point
+func NewMountForm(hostName string, styles Styles, width, height int) *mountFormModel {
+	remotePath := textinput.New()
+	remotePath.Placeholder = "home directory"
+	remotePath.CharLimit = 300
+	remotePath.Width = 50
+	remotePath.Focus()
+
+	mountPoint := textinput.New()
+	mountPoint.Placeholder = "~/mnt/" + hostName
+	mountPoint.CharLimit = 300
+	mountPoint.Width = 50
+	if path, err := transfer.DefaultMountPoint(hostName); err == nil {
+		mountPoint.SetValue(path)
+	}
+
+	return &mountFormModel{
+		hostName:   hostName,
+		remotePath: remotePath,
+		mountPoint: mountPoint,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+}
+
+func (m *mountFormModel) Update(msg tea.Msg) (*mountFormModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, func() tea.Msg { return mountFormCancelMsg{} }
+
+		case "tab", "shift+tab", "up", "down":
+			m.focus = 1 - m.focus
+			m.remotePath.Blur()
+			m.mountPoint.Blur()
+			if m.focus == 0 {
+				return m, m.remotePath.Focus()
+			}
+			return m, m.mountPoint.Focus()
+
+		case "enter":
+			if m.focus == 0 {
+				m.focus = 1
+				m.remotePath.Blur()
+				return m, m.mountPoint.Focus()
+			}
+			return m, m.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	if m.focus == 0 {
+		m.remotePath, cmd = m.remotePath.Update(msg)
+	} else {
+		m.mountPoint, cmd = m.mountPoint.Update(msg)
+	}
+	return m, cmd
+}
+
+// submit checks the mount point and asks for the mount
+func (m *mountFormModel) submit() tea.Cmd {
+	mountPoint := strings.TrimSpace(m.mountPoint.Value())
+	if mountPoint == "" {
+		m.err = "A mount point is required"
+		return nil
+	}
+	if expanded, err := transfer.ExpandPath(mountPoint); err == nil {
+		mountPoint = expanded
+	}
+
+	msg := mountFormSubmitMsg{
+		hostName:   m.hostName,
+		remotePath: strings.TrimSpace(m.remotePath.Value()),
+		mountPoint: mountPoint,
+	}
+	return func() tea.Msg { return msg }
+}
+
+func (m *mountFormModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Width(14)
+	focusedLabelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true).Width(14)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error))
+
+	field := func(label string, input textinput.Model, focused bool) string {
+		style := labelStyle
+		if focused {
+			style = focusedLabelStyle
+		}
+		return style.Render(label) + input.View()
+	}
+
+	body := []string{
+		titleStyle.Render("Mount " + m.hostName + " with sshfs"),
+		"",
+		field("Remote path", m.remotePath, m.focus == 0),
+		field("Mount point", m.mountPoint, m.focus == 1),
+	}
+	if m.err != "" {
+		body = append(body, "", errorStyle.Render(m.err))
+	}
+	body = append(body, "", helpStyle.Render("Tab: next field • Enter: mount • Esc: cancel"))
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		container.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+	)
+}
+
+// mountCmd mounts a host in the background
+func mountCmd(mounter *transfer.Mounter, hostName, remotePath, mountPoint, configFile string) tea.Cmd {
+	return func() tea.Msg {
+		err := mounter.Mount(hostName, remotePath, mountPoint, configFile)
+		return mountResultMsg{hostName: hostName, mountPoint: mountPoint, err: err}
+	}
+}
+
+// unmountCmd unmounts a host in the background
+func unmountCmd(mounter *transfer.Mounter, hostName string) tea.Cmd {
+	mount, _ := mounter.Mounted(hostName)
+	return func() tea.Msg {
+		err := mounter.Unmount(hostName)
+		return mountResultMsg{hostName: hostName, mountPoint: mount.MountPoint, unmount: true, err: err}
+	}
+}
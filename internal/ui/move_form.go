@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/xvertile/sshc/internal/config"
 
@@ -11,22 +13,32 @@ import (
 type moveFormModel struct {
 	fileSelector *fileSelectorModel
 	hostName     string
+	hostNames    []string // Every host to move, hostName included
 	configFile   string
 	width        int
 	height       int
 	styles       Styles
 	state        moveFormState
+
+	// Hazards of the move to targetFile, shown before it goes ahead
+	targetFile string
+	findings   []config.MoveFinding
 }
 
 type moveFormState int
 
 const (
 	moveFormSelectingFile moveFormState = iota
+	moveFormReviewing
 	moveFormProcessing
 )
 
+// analyzeMove finds what breaks with a move; tests replace it
+var analyzeMove = config.AnalyzeMove
+
 type moveFormSubmitMsg struct {
 	hostName   string
+	hostNames  []string
 	targetFile string
 	err        error
 }
@@ -35,19 +47,46 @@ type moveFormCancelMsg struct{}
 
 // NewMoveForm creates a new move form for moving a host to another config file
 func NewMoveForm(hostName string, styles Styles, width, height int, configFile string) (*moveFormModel, error) {
-	// Get all config files except the one containing the current host
-	files, err := config.GetConfigFilesExcludingCurrent(hostName, configFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config files: %v", err)
+	return NewMoveFormForHosts([]string{hostName}, styles, width, height, configFile)
+}
+
+// NewMoveFormForHosts creates a move form for moving several hosts at once. Only
+// files holding none of the hosts are offered as the destination.
+func NewMoveFormForHosts(hostNames []string, styles Styles, width, height int, configFile string) (*moveFormModel, error) {
+	if len(hostNames) == 0 {
+		return nil, fmt.Errorf("no host to move")
 	}
 
+	// Get all config files except the ones containing the hosts
+	var files []string
+	for i, hostName := range hostNames {
+		candidates, err := config.GetConfigFilesExcludingCurrent(hostName, configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get config files: %v", err)
+		}
+		if i == 0 {
+			files = candidates
+		} else {
+			files = slices.DeleteFunc(files, func(file string) bool { return !slices.Contains(candidates, file) })
+		}
+	}
+	files = config.WritableConfigFiles(files)
+
 	if len(files) == 0 {
+		if len(hostNames) > 1 {
+			return nil, fmt.Errorf("no config file is free of all %d marked hosts to move them to", len(hostNames))
+		}
 		return nil, fmt.Errorf("no includes found in SSH config file - move operation requires multiple config files")
 	}
 
+	title := fmt.Sprintf("Select destination config file for host '%s':", hostNames[0])
+	if len(hostNames) > 1 {
+		title = fmt.Sprintf("Select destination config file for %d hosts:", len(hostNames))
+	}
+
 	// Create a custom file selector for move operation
 	fileSelector, err := newFileSelectorFromFiles(
-		fmt.Sprintf("Select destination config file for host '%s':", hostName),
+		title,
 		styles,
 		width,
 		height,
@@ -59,7 +98,8 @@ func NewMoveForm(hostName string, styles Styles, width, height int, configFile s
 
 	return &moveFormModel{
 		fileSelector: fileSelector,
-		hostName:     hostName,
+		hostName:     hostNames[0],
+		hostNames:    hostNames,
 		configFile:   configFile,
 		width:        width,
 		height:       height,
@@ -92,8 +132,15 @@ func (m *moveFormModel) Update(msg tea.Msg) (*moveFormModel, tea.Cmd) {
 			case "enter":
 				if m.fileSelector != nil && len(m.fileSelector.files) > 0 {
 					selectedFile := m.fileSelector.files[m.fileSelector.selected]
+					// An analysis that fails leaves the move to report the error
+					if findings, err := analyzeMove(m.hostNames, selectedFile); err == nil && len(findings) > 0 {
+						m.targetFile = selectedFile
+						m.findings = findings
+						m.state = moveFormReviewing
+						return m, nil
+					}
 					m.state = moveFormProcessing
-					return m, m.submitMove(selectedFile)
+					return m, m.submitMove(selectedFile, config.MoveOptions{})
 				}
 			case "esc", "q":
 				return m, func() tea.Msg { return moveFormCancelMsg{} }
@@ -105,6 +152,23 @@ func (m *moveFormModel) Update(msg tea.Msg) (*moveFormModel, tea.Cmd) {
 					return m, cmd
 				}
 			}
+		case moveFormReviewing:
+			switch msg.String() {
+			case "enter":
+				if !m.blocked() {
+					m.state = moveFormProcessing
+					return m, m.submitMove(m.targetFile, config.MoveOptions{})
+				}
+			case "a":
+				if !m.blocked() && m.hasRelativePaths() {
+					m.state = moveFormProcessing
+					return m, m.submitMove(m.targetFile, config.MoveOptions{AbsolutePaths: true})
+				}
+			case "esc", "q":
+				// Back to picking another file
+				m.state = moveFormSelectingFile
+				m.findings = nil
+			}
 		case moveFormProcessing:
 			// Dans cet état, on attend le résultat de l'opération
 			// Le résultat sera géré par le modèle principal
@@ -126,7 +190,14 @@ func (m *moveFormModel) View() string {
 		}
 		return "Loading..."
 
+	case moveFormReviewing:
+		return m.reviewView()
+
 	case moveFormProcessing:
+		if len(m.hostNames) > 1 {
+			return m.styles.FormTitle.Render("Moving hosts...") + "\n\n" +
+				m.styles.HelpText.Render(fmt.Sprintf("Moving %d hosts to selected config file...", len(m.hostNames)))
+		}
 		return m.styles.FormTitle.Render("Moving host...") + "\n\n" +
 			m.styles.HelpText.Render(fmt.Sprintf("Moving host '%s' to selected config file...", m.hostName))
 
@@ -135,11 +206,45 @@ func (m *moveFormModel) View() string {
 	}
 }
 
-func (m *moveFormModel) submitMove(targetFile string) tea.Cmd {
+// blocked reports whether a finding stops the move
+func (m *moveFormModel) blocked() bool {
+	return slices.ContainsFunc(m.findings, config.MoveFinding.Blocking)
+}
+
+// hasRelativePaths reports whether a finding is a path that can be made absolute
+func (m *moveFormModel) hasRelativePaths() bool {
+	return slices.ContainsFunc(m.findings, func(f config.MoveFinding) bool { return f.Kind == config.MoveRelativePath })
+}
+
+// reviewView lists the hazards of the move with what can be done about them
+func (m *moveFormModel) reviewView() string {
+	var b strings.Builder
+	b.WriteString(m.styles.FormTitle.Render("Moving to "+abbreviateHome(m.targetFile)) + "\n\n")
+	for _, finding := range m.findings {
+		line := "• " + finding.String()
+		if finding.Blocking() {
+			b.WriteString(m.styles.Error.Render(line) + "\n")
+		} else {
+			b.WriteString(line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	switch {
+	case m.blocked():
+		b.WriteString(m.styles.Error.Render("Define the jump hosts in a file the target sees, or pick another file.") + "\n\n")
+		b.WriteString(m.styles.HelpText.Render("Esc: pick another file"))
+	default:
+		b.WriteString(m.styles.HelpText.Render("a: make the paths absolute and move • Enter: move as is • Esc: pick another file"))
+	}
+	return b.String()
+}
+
+func (m *moveFormModel) submitMove(targetFile string, opts config.MoveOptions) tea.Cmd {
 	return func() tea.Msg {
-		err := config.MoveHostToFile(m.hostName, targetFile)
+		err := config.MoveHostsToFileWithOptions(m.hostNames, targetFile, opts)
 		return moveFormSubmitMsg{
 			hostName:   m.hostName,
+			hostNames:  m.hostNames,
 			targetFile: targetFile,
 			err:        err,
 		}
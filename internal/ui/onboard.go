@@ -0,0 +1,345 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+	"github.com/xvertile/sshc/internal/knownhosts"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Onboarding steps, in the order they run
+const (
+	onboardStepHostKey = iota
+	onboardStepUploadKey
+	onboardStepLogin
+	onboardStepCount
+)
+
+// onboardStatus is the state of one onboarding step
+type onboardStatus int
+
+const (
+	onboardPending onboardStatus = iota
+	onboardRunning
+	onboardDone
+	onboardFailed
+	onboardSkipped
+)
+
+type onboardStep struct {
+	title  string
+	status onboardStatus
+	detail string
+}
+
+// onboardModel walks a new host through trusting its host key, uploading a key and a
+// first passwordless login. Each step either completes or changes nothing, so a failed
+// step can be retried on its own.
+type onboardModel struct {
+	host           config.SSHHost
+	configFile     string
+	knownHostsPath string
+
+	steps    [onboardStepCount]onboardStep
+	selected int
+
+	scannedKeys []knownhosts.HostKey // Keys waiting for the user to trust them
+	keyUpload   *sshKeyUploadModel   // Open while the key upload step runs
+	uploaded    bool
+
+	styles Styles
+	width  int
+	height int
+}
+
+// onboardScanMsg carries the result of scanning the host key
+type onboardScanMsg struct {
+	keys   []knownhosts.HostKey
+	status knownhosts.Status
+	err    error
+}
+
+// onboardLoginMsg carries the result of the test login
+type onboardLoginMsg struct {
+	err error
+}
+
+type onboardCloseMsg struct{}
+
+// NewOnboard creates the onboarding checklist for a host
+func NewOnboard(host config.SSHHost, configFile string, styles Styles, width, height int) *onboardModel {
+	m := &onboardModel{
+		host:       host,
+		configFile: configFile,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+	m.steps[onboardStepHostKey].title = "Trust the host key"
+	m.steps[onboardStepUploadKey].title = "Upload your public key"
+	m.steps[onboardStepLogin].title = "Log in without a password"
+	m.knownHostsPath, _ = knownhosts.DefaultPath()
+	return m
+}
+
+func (m *onboardModel) Init() tea.Cmd {
+	return m.runStep(onboardStepHostKey)
+}
+
+// runStep starts a step, clearing the outcome of an earlier attempt
+func (m *onboardModel) runStep(step int) tea.Cmd {
+	m.selected = step
+	m.scannedKeys = nil
+	m.steps[step].status = onboardRunning
+	m.steps[step].detail = ""
+
+	switch step {
+	case onboardStepHostKey:
+		if m.host.IsProxied() {
+			m.steps[step].status = onboardSkipped
+			m.steps[step].detail = "reached through a proxy, so ssh-keyscan cannot see it; accept the key on the first interactive connection"
+			return m.advance(step)
+		}
+		address := m.host.Hostname
+		if address == "" {
+			address = m.host.Name
+		}
+		port, name, path := m.host.Port, m.host.KnownHostsName(), m.knownHostsPath
+		return func() tea.Msg {
+			keys, err := knownhosts.Scan(context.Background(), address, port, name)
+			if err != nil {
+				return onboardScanMsg{err: err}
+			}
+			status, err := knownhosts.Check(path, name, keys)
+			return onboardScanMsg{keys: keys, status: status, err: err}
+		}
+
+	case onboardStepUploadKey:
+		m.uploaded = false
+		m.keyUpload = NewSSHKeyUploadForm(m.host.Name, m.styles, m.width, m.height, m.configFile)
+		return m.keyUpload.Init()
+
+	case onboardStepLogin:
+		hostName, configFile := m.host.Name, m.configFile
+		return func() tea.Msg {
+			return onboardLoginMsg{err: connectivity.VerifyLogin(context.Background(), hostName, configFile)}
+		}
+	}
+	return nil
+}
+
+// advance runs the step after step unless it is already done, so retrying one step
+// does not redo the ones that follow it
+func (m *onboardModel) advance(step int) tea.Cmd {
+	next := step + 1
+	if next >= onboardStepCount || m.steps[next].status == onboardDone {
+		return nil
+	}
+	return m.runStep(next)
+}
+
+func (m *onboardModel) Update(msg tea.Msg) (*onboardModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.keyUpload != nil {
+			m.keyUpload.width = msg.Width
+			m.keyUpload.height = msg.Height
+		}
+		return m, nil
+
+	case onboardScanMsg:
+		step := &m.steps[onboardStepHostKey]
+		name := m.host.KnownHostsName()
+		switch {
+		case msg.err != nil:
+			step.status = onboardFailed
+			step.detail = msg.err.Error()
+		case msg.status == knownhosts.Known:
+			step.status = onboardDone
+			step.detail = "already in known_hosts"
+			return m, m.advance(onboardStepHostKey)
+		case msg.status == knownhosts.Changed:
+			step.status = onboardFailed
+			step.detail = fmt.Sprintf("known_hosts has a different key for %s; if the server was reinstalled, run ssh-keygen -R '%s' first", name, name)
+		default:
+			// Wait for the user to compare the fingerprints
+			m.scannedKeys = msg.keys
+		}
+		return m, nil
+
+	case sshKeyUploadSubmitMsg:
+		if m.keyUpload == nil {
+			return m, nil
+		}
+		m.uploaded = msg.err == nil
+		var cmd tea.Cmd
+		m.keyUpload, cmd = m.keyUpload.Update(msg)
+		return m, cmd
+
+	case sshKeyUploadCancelMsg:
+		// The upload form is done, either after uploading or because it was closed
+		if m.keyUpload == nil {
+			return m, nil
+		}
+		step := &m.steps[onboardStepUploadKey]
+		m.keyUpload = nil
+		if !m.uploaded {
+			step.status = onboardFailed
+			step.detail = "no key was uploaded"
+			return m, nil
+		}
+		step.status = onboardDone
+		step.detail = "public key added to authorized_keys"
+		return m, m.advance(onboardStepUploadKey)
+
+	case onboardLoginMsg:
+		step := &m.steps[onboardStepLogin]
+		if msg.err != nil {
+			step.status = onboardFailed
+			step.detail = msg.err.Error()
+			return m, nil
+		}
+		step.status = onboardDone
+		step.detail = "logged in with BatchMode, no password needed"
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.keyUpload != nil {
+			var cmd tea.Cmd
+			m.keyUpload, cmd = m.keyUpload.Update(msg)
+			return m, cmd
+		}
+		if m.scannedKeys != nil {
+			return m.confirmHostKey(msg)
+		}
+
+		switch msg.String() {
+		case "esc", "q", "ctrl+c":
+			return m, func() tea.Msg { return onboardCloseMsg{} }
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < onboardStepCount-1 {
+				m.selected++
+			}
+		case "enter", "r":
+			if m.steps[m.selected].status != onboardRunning {
+				return m, m.runStep(m.selected)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// confirmHostKey adds the scanned keys to known_hosts once the user accepts them
+func (m *onboardModel) confirmHostKey(msg tea.KeyMsg) (*onboardModel, tea.Cmd) {
+	step := &m.steps[onboardStepHostKey]
+	switch msg.String() {
+	case "y", "Y":
+		keys := m.scannedKeys
+		m.scannedKeys = nil
+		if err := knownhosts.Add(m.knownHostsPath, keys); err != nil {
+			step.status = onboardFailed
+			step.detail = fmt.Sprintf("could not write %s: %v", m.knownHostsPath, err)
+			return m, nil
+		}
+		step.status = onboardDone
+		step.detail = fmt.Sprintf("%d key(s) added to %s", len(keys), m.knownHostsPath)
+		return m, m.advance(onboardStepHostKey)
+	case "n", "N", "esc":
+		m.scannedKeys = nil
+		step.status = onboardFailed
+		step.detail = "host key not trusted; known_hosts was not changed"
+	}
+	return m, nil
+}
+
+// nextStepHint says which step to redo, or that onboarding is complete
+func (m *onboardModel) nextStepHint() string {
+	for i, step := range m.steps {
+		switch step.status {
+		case onboardFailed:
+			return fmt.Sprintf("Step %d (%s) failed. Select it and press r to retry; the earlier steps stay done.", i+1, strings.ToLower(step.title))
+		case onboardPending, onboardRunning:
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s is ready.", m.host.Name)
+}
+
+func (m *onboardModel) View() string {
+	if m.keyUpload != nil {
+		return m.keyUpload.View()
+	}
+
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	detailWidth := max(m.width-16, 30)
+
+	body := []string{titleStyle.Render("Onboard " + m.host.Name), ""}
+	for i, step := range m.steps {
+		marker, color := "[ ]", theme.Muted
+		switch step.status {
+		case onboardRunning:
+			marker, color = "[…]", theme.Warning
+		case onboardDone:
+			marker, color = "[✓]", theme.Success
+		case onboardFailed:
+			marker, color = "[✗]", theme.Error
+		case onboardSkipped:
+			marker, color = "[-]", theme.Muted
+		}
+		line := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(marker) + " " + fmt.Sprintf("%d. %s", i+1, step.title)
+		if i == m.selected {
+			line = lipgloss.NewStyle().Bold(true).Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		body = append(body, line)
+		if step.detail != "" {
+			body = append(body, mutedStyle.Width(detailWidth).Render("      "+step.detail))
+		}
+	}
+
+	help := "↑/↓: select • r/Enter: run or retry step • Esc: close"
+	if m.scannedKeys != nil {
+		body = append(body, "", fmt.Sprintf("%s offers these host keys:", m.host.KnownHostsName()))
+		for _, key := range m.scannedKeys {
+			body = append(body, "  "+key.Type+" "+key.Fingerprint)
+		}
+		body = append(body, "", "Compare them with the server's fingerprints before trusting them.")
+		help = "y: trust and add to known_hosts • n: do not trust"
+	} else if hint := m.nextStepHint(); hint != "" {
+		body = append(body, "", lipgloss.NewStyle().Width(detailWidth).Render(hint))
+	}
+	body = append(body, "", mutedStyle.Render(help))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+	)
+}
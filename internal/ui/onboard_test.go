@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/knownhosts"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestOnboardStepsAndRetry(t *testing.T) {
+	m := NewOnboard(config.SSHHost{Name: "web", Hostname: "web.example.com", Port: "22"}, "", NewStyles(120), 120, 40)
+	m.steps[onboardStepHostKey].status = onboardRunning
+
+	// A trusted host key moves straight on to the key upload
+	m, _ = m.Update(onboardScanMsg{status: knownhosts.Known})
+	if m.steps[onboardStepHostKey].status != onboardDone || m.keyUpload == nil {
+		t.Fatalf("Expected the key upload to open after a known host key, got %+v", m.steps)
+	}
+
+	// Closing the upload form without uploading fails only that step
+	m, _ = m.Update(sshKeyUploadCancelMsg{})
+	if m.steps[onboardStepUploadKey].status != onboardFailed || m.keyUpload != nil {
+		t.Fatalf("Expected the upload step to fail, got %+v", m.steps)
+	}
+	if hint := m.nextStepHint(); !strings.Contains(hint, "Step 2") {
+		t.Errorf("Expected the hint to name step 2, got %q", hint)
+	}
+
+	// Retrying the upload step reopens the form and a successful upload runs the login test
+	m.selected = onboardStepUploadKey
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if m.keyUpload == nil {
+		t.Fatal("Expected r to reopen the key upload")
+	}
+	m, _ = m.Update(sshKeyUploadSubmitMsg{})
+	m, cmd := m.Update(sshKeyUploadCancelMsg{})
+	if m.steps[onboardStepUploadKey].status != onboardDone || m.steps[onboardStepLogin].status != onboardRunning || cmd == nil {
+		t.Fatalf("Expected the login test to start after the upload, got %+v", m.steps)
+	}
+
+	m, _ = m.Update(onboardLoginMsg{err: errors.New("Permission denied (publickey).")})
+	if m.steps[onboardStepLogin].status != onboardFailed || !strings.Contains(m.nextStepHint(), "Step 3") {
+		t.Errorf("Expected the login step to fail, got %+v", m.steps)
+	}
+	m, _ = m.Update(onboardLoginMsg{})
+	if m.nextStepHint() != "web is ready." {
+		t.Errorf("Unexpected hint after all steps passed: %q", m.nextStepHint())
+	}
+}
+
+func TestOnboardRejectsChangedHostKey(t *testing.T) {
+	m := NewOnboard(config.SSHHost{Name: "db", Hostname: "db.example.com", Port: "2222"}, "", NewStyles(120), 120, 40)
+
+	m, cmd := m.Update(onboardScanMsg{status: knownhosts.Changed})
+	if cmd != nil || m.keyUpload != nil {
+		t.Fatal("Expected a changed host key to stop onboarding")
+	}
+	if detail := m.steps[onboardStepHostKey].detail; !strings.Contains(detail, "ssh-keygen -R '[db.example.com]:2222'") {
+		t.Errorf("Expected the fix for the changed key, got %q", detail)
+	}
+}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/browser"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Browser hooks, replaced in tests
+var (
+	openInBrowser = browser.Open
+
+	// forwardListening reports whether something already accepts connections on
+	// the local end of a forward, such as a tunnel opened earlier
+	forwardListening = func(address string) bool {
+		conn, err := net.DialTimeout("tcp", address, 300*time.Millisecond)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+)
+
+// tunnelOffer is a URL that a forward from the port-forward history reaches
+type tunnelOffer struct {
+	url      string // As configured
+	localURL string // Through the forward
+	forward  browser.Forward
+}
+
+// openURLModel picks one of the URLs of a host, and asks whether to open the
+// tunnel from the port-forward history before opening one it leads to
+type openURLModel struct {
+	hostName string
+	urls     []string
+	selected int
+	tunnel   *tunnelOffer // Set while asking about the tunnel
+
+	styles Styles
+	width  int
+	height int
+}
+
+// openURLMsg asks to open a URL, through the tunnel of offer when it is set
+type openURLMsg struct {
+	hostName string
+	url      string
+	tunnel   *tunnelOffer
+}
+
+type openURLCancelMsg struct{}
+
+// tunnelReadyMsg reports whether ssh set up the forward of a tunnel offer
+type tunnelReadyMsg struct {
+	offer tunnelOffer
+	err   error
+}
+
+// browserOpenedMsg reports whether the browser was started on a URL
+type browserOpenedMsg struct {
+	url string
+	err error
+}
+
+func NewOpenURL(hostName string, urls []string, styles Styles, width, height int) *openURLModel {
+	return &openURLModel{hostName: hostName, urls: urls, styles: styles, width: width, height: height}
+}
+
+func (m *openURLModel) Update(msg tea.Msg) (*openURLModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		if m.tunnel != nil {
+			offer, hostName := *m.tunnel, m.hostName
+			switch msg.String() {
+			case "y", "Y", "enter":
+				return m, func() tea.Msg { return openURLMsg{hostName: hostName, url: offer.url, tunnel: &offer} }
+			case "n", "N":
+				return m, func() tea.Msg { return openURLMsg{hostName: hostName, url: offer.url} }
+			case "ctrl+c", "esc", "q":
+				return m, func() tea.Msg { return openURLCancelMsg{} }
+			}
+			return m, nil
+		}
+
+		switch key := msg.String(); key {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return openURLCancelMsg{} }
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.urls)-1 {
+				m.selected++
+			}
+		case "enter":
+			return m, m.choose(m.selected)
+		default:
+			if len(key) == 1 && key[0] >= '1' && key[0] <= '9' && int(key[0]-'1') < len(m.urls) {
+				return m, m.choose(int(key[0] - '1'))
+			}
+		}
+	}
+	return m, nil
+}
+
+// choose opens the URL at index i
+func (m *openURLModel) choose(i int) tea.Cmd {
+	hostName, url := m.hostName, m.urls[i]
+	return func() tea.Msg { return openURLMsg{hostName: hostName, url: url} }
+}
+
+func (m *openURLModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.SelectionFg)).
+		Background(lipgloss.Color(theme.SelectionBg)).
+		Bold(true)
+	width := max(m.width-14, 20)
+
+	var lines []string
+	if m.tunnel != nil {
+		f := m.tunnel.forward
+		lines = []string{
+			titleStyle.Render("Open " + ansi.Truncate(m.tunnel.url, width-5, "…")),
+			"",
+			fmt.Sprintf("The last port forward of %s leads there:", m.hostName),
+			fmt.Sprintf("  ssh -L %s %s", f.Arg(), m.hostName),
+			"",
+			mutedStyle.Render("y/Enter: open the tunnel, then " + ansi.Truncate(m.tunnel.localURL, width-30, "…")),
+			mutedStyle.Render("n: open the URL directly • Esc: cancel"),
+		}
+	} else {
+		lines = []string{titleStyle.Render("Open a page of " + m.hostName), ""}
+		for i, url := range m.urls {
+			line := ansi.Truncate(fmt.Sprintf("%d  %s", i+1, url), width, "…")
+			if i == m.selected {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "", mutedStyle.Render("Enter/1-9: open • Esc: cancel"))
+	}
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+		container.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)))
+}
+
+// hostURLs returns the URLs kept for a host, filled in for it
+func (m *Model) hostURLs(hostName string) []string {
+	target := browser.Target{Host: hostName}
+	for _, host := range m.hosts {
+		if host.Name == hostName {
+			target.Hostname, target.User = host.Hostname, host.User
+			break
+		}
+	}
+	var urls []string
+	for _, template := range m.appConfig.URLsFor(hostName) {
+		if strings.TrimSpace(template) != "" {
+			urls = append(urls, browser.Expand(template, target))
+		}
+	}
+	return urls
+}
+
+// tunnelOfferFor returns the offer to reach url through the last local forward of
+// the host, or nil when there is none leading there
+func (m *Model) tunnelOfferFor(hostName, url string) *tunnelOffer {
+	if m.historyManager == nil {
+		return nil
+	}
+	config := m.historyManager.GetPortForwardingConfig(hostName)
+	if config == nil || config.Type != "local" {
+		return nil
+	}
+	forward := browser.Forward{
+		BindAddress: config.BindAddress,
+		LocalPort:   config.LocalPort,
+		RemoteHost:  config.RemoteHost,
+		RemotePort:  config.RemotePort,
+	}
+	hostname := hostName
+	for _, host := range m.hosts {
+		if host.Name == hostName && host.Hostname != "" {
+			hostname = host.Hostname
+			break
+		}
+	}
+	localURL, ok := browser.ThroughForward(url, forward, hostname)
+	if !ok {
+		return nil
+	}
+	return &tunnelOffer{url: url, localURL: localURL, forward: forward}
+}
+
+// openURL opens a URL of a host. When the host's last port forward leads to it,
+// the forward is used if it is already up, and offered otherwise.
+func (m *Model) openURL(hostName, url string) tea.Cmd {
+	offer := m.tunnelOfferFor(hostName, url)
+	if offer == nil {
+		m.closeOpenURL()
+		return openBrowserCmd(url)
+	}
+	if forwardListening(offer.forward.LocalAddress()) {
+		m.closeOpenURL()
+		return openBrowserCmd(offer.localURL)
+	}
+	if m.openURLForm == nil {
+		m.openURLForm = NewOpenURL(hostName, []string{url}, m.styles, m.width, m.height)
+	}
+	m.openURLForm.tunnel = offer
+	m.viewMode = ViewOpenURL
+	m.table.Blur()
+	return nil
+}
+
+// closeOpenURL goes back to the list from the URL picker, if it is open
+func (m *Model) closeOpenURL() {
+	if m.openURLForm != nil {
+		m.openURLForm = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+	}
+}
+
+// openTunnelCmd runs ssh in the background with the forward of offer. It takes the
+// terminal until ssh is logged in, so a password or passphrase can be typed.
+func (m *Model) openTunnelCmd(hostName string, offer tunnelOffer) tea.Cmd {
+	args := []string{"-f", "-N", "-o", "ExitOnForwardFailure=yes", "-L", offer.forward.Arg(), hostName}
+	if m.configFile != "" {
+		args = append([]string{"-F", m.configFile}, args...)
+	}
+	return execProcess(exec.Command("ssh", args...), func(err error) tea.Msg {
+		return tunnelReadyMsg{offer: offer, err: err}
+	})
+}
+
+// openBrowserCmd opens a URL in the default browser
+func openBrowserCmd(url string) tea.Cmd {
+	return func() tea.Msg {
+		return browserOpenedMsg{url: url, err: openInBrowser(url)}
+	}
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// setupOpenURLTest returns a model with URLs for node-0000 and records the URLs
+// the browser is asked to open and the forwards said to be listening
+func setupOpenURLTest(t *testing.T, urls ...string) (Model, *[]string, map[string]bool) {
+	t.Helper()
+	m := createWorkspaceTestModel(t)
+	m.historyManager = newTestHistoryManager(t)
+	m.appConfig.HostURLs = map[string][]string{"node-0000": urls}
+
+	var opened []string
+	listening := map[string]bool{}
+	previousOpen, previousListening := openInBrowser, forwardListening
+	openInBrowser = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	forwardListening = func(address string) bool { return listening[address] }
+	t.Cleanup(func() { openInBrowser, forwardListening = previousOpen, previousListening })
+	return m, &opened, listening
+}
+
+// openSelected presses the open-url key and runs what it leads to until the
+// browser opens or a view waits for input
+func openSelected(t *testing.T, m Model, keys ...tea.KeyMsg) Model {
+	t.Helper()
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	for _, key := range keys {
+		if msg != nil {
+			m, _ = press(t, m, msg)
+		}
+		m, msg = press(t, m, key)
+	}
+	for msg != nil {
+		if _, ok := msg.(browserOpenedMsg); ok {
+			updated, _ := m.Update(msg)
+			return updated.(Model)
+		}
+		m, msg = press(t, m, msg)
+	}
+	return m
+}
+
+func TestOpenURLOfHost(t *testing.T) {
+	m, opened, _ := setupOpenURLTest(t, "https://{hostname}:5001/{user}")
+	m = openSelected(t, m)
+	if len(*opened) != 1 || (*opened)[0] != "https://10.0.0.0:5001/deploy" {
+		t.Fatalf("opened = %v", *opened)
+	}
+	if m.viewMode != ViewList || !strings.Contains(m.errorMessage, "Opened https://10.0.0.0:5001/deploy") {
+		t.Errorf("viewMode = %v, toast = %q", m.viewMode, m.errorMessage)
+	}
+}
+
+func TestOpenURLWithoutURLs(t *testing.T) {
+	m, opened, _ := setupOpenURLTest(t)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = updated.(Model)
+	if len(*opened) != 0 || !strings.Contains(m.errorMessage, "No URLs for node-0000") {
+		t.Errorf("opened = %v, toast = %q", *opened, m.errorMessage)
+	}
+}
+
+func TestOpenURLPicker(t *testing.T) {
+	m, opened, _ := setupOpenURLTest(t, "http://{hostname}/", "http://{hostname}:9090/")
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.viewMode != ViewOpenURL {
+		t.Fatalf("viewMode = %v, want the URL picker", m.viewMode)
+	}
+	if view := m.View(); !strings.Contains(view, "2  http://10.0.0.0:9090/") {
+		t.Errorf("picker does not list the URLs:\n%s", view)
+	}
+
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, msg = press(t, m, msg)
+	if _, ok := msg.(browserOpenedMsg); !ok || m.viewMode != ViewList {
+		t.Fatalf("msg = %T, viewMode = %v after picking", msg, m.viewMode)
+	}
+	if len(*opened) != 1 || (*opened)[0] != "http://10.0.0.0:9090/" {
+		t.Errorf("opened = %v", *opened)
+	}
+}
+
+func TestOpenURLThroughForward(t *testing.T) {
+	m, opened, listening := setupOpenURLTest(t, "http://localhost:3000/d/home")
+	m.historyManager.RecordPortForwarding("node-0000", "local", "9000", "localhost", "3000", "")
+
+	// The tunnel is offered while nothing listens on its local port
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.viewMode != ViewOpenURL || m.openURLForm.tunnel == nil {
+		t.Fatalf("viewMode = %v, want the tunnel offer", m.viewMode)
+	}
+	if view := m.View(); !strings.Contains(view, "ssh -L 9000:localhost:3000 node-0000") {
+		t.Errorf("offer does not show the forward:\n%s", view)
+	}
+
+	// Accepting it runs ssh, which the test does not, and opens the page through it
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	open, ok := msg.(openURLMsg)
+	if !ok || open.tunnel == nil || open.tunnel.forward.Arg() != "9000:localhost:3000" {
+		t.Fatalf("y sent %#v, want the tunnel opened", msg)
+	}
+	updated, cmd := m.Update(open)
+	m = updated.(Model)
+	if cmd == nil || m.viewMode != ViewList {
+		t.Fatalf("viewMode = %v, want ssh started from the list", m.viewMode)
+	}
+	m, msg = press(t, m, tunnelReadyMsg{offer: *open.tunnel})
+	if _, ok := msg.(browserOpenedMsg); !ok || len(*opened) != 1 || (*opened)[0] != "http://localhost:9000/d/home" {
+		t.Fatalf("opened = %v after the tunnel came up", *opened)
+	}
+
+	// Declining opens the URL as configured
+	*opened = nil
+	m = openSelected(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if len(*opened) != 1 || (*opened)[0] != "http://localhost:3000/d/home" {
+		t.Errorf("opened = %v after declining the tunnel", *opened)
+	}
+
+	// A tunnel already up is used without asking
+	*opened = nil
+	listening["localhost:9000"] = true
+	m = openSelected(t, m)
+	if m.viewMode != ViewList || len(*opened) != 1 || (*opened)[0] != "http://localhost:9000/d/home" {
+		t.Errorf("viewMode = %v, opened = %v with the tunnel up", m.viewMode, *opened)
+	}
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/history"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cleanupOrphans removes the history, color labels and pins of the deleted hosts
+// that no config defines any more, and returns a toast summarizing it, or nil.
+// Records of other hosts are left alone: they may come from a config sshc was
+// not started with, or from connecting with "sshc <host>".
+func (m *Model) cleanupOrphans(deleted []string) tea.Cmd {
+	if len(deleted) == 0 || m.dryRun || m.browsing() {
+		return nil
+	}
+	known, err := config.KnownHostNames(m.configFile)
+	if err != nil {
+		return nil
+	}
+	report, err := history.CleanupDeleted(m.historyManager, m.appConfig, deleted, known)
+	if err != nil {
+		debugLogf("Could not clean up orphaned records: %v", err)
+	}
+	if report.Count() == 0 {
+		return nil
+	}
+
+	records := "record"
+	if report.Count() > 1 {
+		records = "records"
+	}
+	m.errorMessage = fmt.Sprintf("Cleaned %d orphaned %s", report.Count(), records)
+	m.showingError = true
+	return func() tea.Msg {
+		time.Sleep(2 * time.Second)
+		return errorMsg("clear")
+	}
+}
@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/connectivity"
+	sshclog "github.com/xvertile/sshc/internal/log"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// osInfoConcurrency is how many OS probes run at once
+const osInfoConcurrency = 8
+
+// osLetters are the badges of well-known OS families; others use their first letter
+var osLetters = map[string]string{
+	"macos":    "M",
+	"freebsd":  "F",
+	"fedora":   "Fe",
+	"openbsd":  "O",
+	"opensuse": "S",
+	"rocky":    "Ro",
+	"rhel":     "R",
+	"centos":   "C",
+	"arch":     "Ar",
+	"alpine":   "A",
+	"ubuntu":   "U",
+	"debian":   "D",
+}
+
+// osInfoMsg carries the result of probing one host
+type osInfoMsg struct {
+	hostName string
+	info     connectivity.OSInfo
+	err      error
+}
+
+// osBadge renders the OS family of a host as a short badge such as "[U]" for
+// Ubuntu, or "" when it is not known
+func osBadge(family string) string {
+	if family == "" {
+		return ""
+	}
+	letter, ok := osLetters[family]
+	if !ok {
+		letter = strings.ToUpper(family[:1])
+	}
+	return "[" + letter + "]"
+}
+
+// isOSBadge reports whether a word of a name cell is an OS badge
+func isOSBadge(word string) bool {
+	return len(word) >= 3 && len(word) <= 4 && word[0] == '[' && word[len(word)-1] == ']'
+}
+
+// osInfoTTL returns how long probed OS info is used
+func (m *Model) osInfoTTL() time.Duration {
+	if m.appConfig == nil || m.appConfig.OSInfoTTL <= 0 {
+		return connectivity.DefaultOSInfoTTL
+	}
+	return time.Duration(m.appConfig.OSInfoTTL) * time.Hour
+}
+
+// osInfo returns the cached OS info of a host, however old
+func (m *Model) osInfo(hostName string) (connectivity.OSInfo, bool) {
+	if m.osCache == nil {
+		return connectivity.OSInfo{}, false
+	}
+	return m.osCache.Get(hostName)
+}
+
+// staleOSInfoHosts returns those of hostNames whose OS info is missing or past its TTL
+func (m *Model) staleOSInfoHosts(hostNames []string) []string {
+	if m.osCache == nil {
+		return nil
+	}
+	now, ttl := clock(), m.osInfoTTL()
+	var stale []string
+	for _, name := range hostNames {
+		if m.osCache.Stale(name, now, ttl) {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+// listedSSHHostNames returns the names of the SSH hosts listed, members of
+// collapsed blocks included
+func (m *Model) listedSSHHostNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(entry HostEntry) {
+		if entry.SSHHost != nil && !seen[entry.Name] {
+			seen[entry.Name] = true
+			names = append(names, entry.Name)
+		}
+	}
+	for _, entry := range m.filteredEntries {
+		if entry.BlockMembers == nil {
+			add(entry)
+			continue
+		}
+		for _, member := range entry.BlockMembers {
+			add(member)
+		}
+	}
+	return names
+}
+
+// startOSInfoCmd probes the OS and uptime of the hosts, a few at a time
+func (m *Model) startOSInfoCmd(hostNames []string) tea.Cmd {
+	if m.browsing() || m.osCache == nil || len(hostNames) == 0 {
+		return nil
+	}
+	m.osInfoPending += len(hostNames)
+	slots := make(chan struct{}, osInfoConcurrency)
+	configFile := m.configFile
+	cmds := make([]tea.Cmd, 0, len(hostNames))
+	for _, hostName := range hostNames {
+		cmds = append(cmds, func() tea.Msg {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			info, err := connectivity.ProbeOSInfo(context.Background(), hostName, configFile)
+			sshclog.Debug("os probe", "host", hostName, "family", info.Family, "err", err)
+			return osInfoMsg{hostName: hostName, info: info, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyOSInfo caches the OS info of a host and saves the cache once the last
+// probe of a batch is back
+func (m *Model) applyOSInfo(msg osInfoMsg) {
+	m.osInfoPending = max(m.osInfoPending-1, 0)
+	if msg.err != nil {
+		m.report(severityWarning, sourcePing, fmt.Sprintf("Could not read the OS of %s: %v", msg.hostName, msg.err))
+	} else {
+		m.osCache.Put(msg.hostName, msg.info)
+		m.updateTableRows()
+	}
+	if m.osInfoPending == 0 {
+		if err := m.osCache.Save(); err != nil {
+			m.report(severityWarning, sourceWrite, fmt.Sprintf("Could not save the OS info cache: %v", err))
+		}
+	}
+}
+
+// formatOSFamily names an OS family for display
+func formatOSFamily(family string) string {
+	switch family {
+	case "macos":
+		return "macOS"
+	case "freebsd":
+		return "FreeBSD"
+	case "openbsd":
+		return "OpenBSD"
+	case "rhel":
+		return "RHEL"
+	case "opensuse":
+		return "openSUSE"
+	}
+	if family == "" {
+		return ""
+	}
+	return strings.ToUpper(family[:1]) + family[1:]
+}
+
+// formatUptime writes an uptime in its two largest units, such as "3d 4h" or "12m"
+func formatUptime(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d/time.Hour) % 24
+	minutes := int(d/time.Minute) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// formatOSInfo describes the OS info of a host for the info view
+func formatOSInfo(info connectivity.OSInfo, now time.Time) string {
+	parts := []string{fmt.Sprintf("%s %s (%s %s)", osBadge(info.Family), formatOSFamily(info.Family), info.System, info.Kernel)}
+	if info.HasUptime {
+		parts = append(parts, "up "+formatUptime(info.Uptime))
+	}
+	parts = append(parts, "checked "+formatTimeSince(info.CheckedAt, now))
+	return strings.Join(parts, " • ")
+}
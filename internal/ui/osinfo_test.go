@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/connectivity"
+)
+
+func TestOSBadge(t *testing.T) {
+	tests := map[string]string{"ubuntu": "[U]", "fedora": "[Fe]", "macos": "[M]", "gentoo": "[G]", "": ""}
+	for family, want := range tests {
+		if got := osBadge(family); got != want {
+			t.Errorf("osBadge(%q) = %q, want %q", family, got, want)
+		}
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	tests := map[time.Duration]string{
+		12 * time.Minute:                              "12m",
+		4*time.Hour + 5*time.Minute:                   "4h 5m",
+		3*24*time.Hour + 4*time.Hour + 10*time.Minute: "3d 4h",
+	}
+	for uptime, want := range tests {
+		if got := formatUptime(uptime); got != want {
+			t.Errorf("formatUptime(%v) = %q, want %q", uptime, got, want)
+		}
+	}
+}
+
+func TestApplyOSInfo(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LOCALAPPDATA", t.TempDir())
+	now := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	setTestClock(t, now)
+
+	m := createWorkspaceTestModel(t)
+	cache, err := connectivity.LoadOSCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.osCache = cache
+
+	hosts := m.listedSSHHostNames()
+	if stale := m.staleOSInfoHosts(hosts); len(stale) != len(hosts) {
+		t.Fatalf("Expected every host to need probing, got %d of %d", len(stale), len(hosts))
+	}
+
+	m.osInfoPending = 2
+	m.applyOSInfo(osInfoMsg{hostName: "node-0000", info: connectivity.OSInfo{Family: "alpine", System: "Linux", CheckedAt: now}})
+	m.applyOSInfo(osInfoMsg{hostName: "node-0001", err: errors.New("connection refused")})
+	if cell := m.table.Rows()[0][0]; !strings.Contains(cell, "[A]") {
+		t.Errorf("name cell = %q, want the Alpine badge", cell)
+	}
+	if stale := m.staleOSInfoHosts([]string{"node-0000", "node-0001"}); len(stale) != 1 || stale[0] != "node-0001" {
+		t.Errorf("staleOSInfoHosts() = %v, want only the host that failed", stale)
+	}
+
+	// The batch is back, so the cache was saved
+	reloaded, _ := connectivity.LoadOSCache()
+	if info, ok := reloaded.Get("node-0000"); !ok || info.Family != "alpine" {
+		t.Errorf("saved OS info = %+v, %v", info, ok)
+	}
+
+	setTestClock(t, now.Add(25*time.Hour))
+	if stale := m.staleOSInfoHosts([]string{"node-0000"}); len(stale) != 1 {
+		t.Error("Expected OS info older than the TTL to be probed again")
+	}
+}
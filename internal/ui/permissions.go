@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// addPermission records a warning about a file ssh refuses for its permissions
+func (d *messageDrawer) addPermission(problem config.PermissionProblem) {
+	d.entries = append(d.entries, drawerEntry{
+		severity:   severityWarning,
+		time:       time.Now(),
+		source:     sourcePermissions,
+		text:       problem.String(),
+		count:      1,
+		permission: &problem,
+	})
+}
+
+// permissionProblems returns the permission problems still in the drawer
+func (d *messageDrawer) permissionProblems() []config.PermissionProblem {
+	var problems []config.PermissionProblem
+	for _, entry := range d.entries {
+		if entry.permission != nil {
+			problems = append(problems, *entry.permission)
+		}
+	}
+	return problems
+}
+
+// reportPermissions lists the files of the config and the keys of hosts that ssh
+// refuses for their permissions in the drawer, and points at them with a toast
+func (m *Model) reportPermissions(hosts []config.SSHHost) {
+	m.drawer.removeSource(sourcePermissions)
+	if m.browsing() || !config.PermissionChecksSupported {
+		return
+	}
+	sshDir, err := config.GetSSHDirectory()
+	if err != nil {
+		return
+	}
+	var files []string
+	if m.configFile != "" {
+		files, _ = config.GetAllConfigFilesFromBase(m.configFile)
+	} else {
+		files, _ = config.GetAllConfigFiles()
+	}
+
+	problems := config.CheckPermissions(sshDir, files, hosts)
+	for _, problem := range problems {
+		m.drawer.addPermission(problem)
+	}
+	if len(problems) > 0 && !m.showingError {
+		kb := m.keyBindings()
+		m.errorMessage = fmt.Sprintf("ssh refuses %d files for their permissions; press %s to see and fix them", len(problems), kb.KeyForAction(config.ActionMessages))
+		m.showingError = true
+	}
+}
+
+// fixPermissions applies the permissions ssh wants to the problems in the drawer,
+// those outside the .ssh directory only when outside is set. When some of those
+// are left, the drawer asks whether to fix them too.
+func (m *Model) fixPermissions(outside bool) tea.Cmd {
+	problems := m.drawer.permissionProblems()
+	if m.dryRun {
+		m.errorMessage = fmt.Sprintf("Dry run: would fix the permissions of %d files", len(problems))
+	} else {
+		left, err := config.FixPermissions(problems, outside)
+		m.drawer.removeSource(sourcePermissions)
+		for _, problem := range left {
+			m.drawer.addPermission(problem)
+		}
+		m.errorMessage = fmt.Sprintf("Fixed the permissions of %d files", len(problems)-len(left))
+		if err != nil {
+			m.errorMessage = fmt.Sprintf("Could not fix permissions: %v", err)
+			m.report(severityError, sourceWrite, m.errorMessage)
+		} else if !outside && len(left) > 0 {
+			m.drawer.confirmOutside = true
+			if len(left) == len(problems) {
+				m.errorMessage = fmt.Sprintf("%d files are outside ~/.ssh; press y to fix them too", len(left))
+			}
+		}
+	}
+	m.showingError = true
+	return func() tea.Msg {
+		time.Sleep(2 * time.Second)
+		return errorMsg("clear")
+	}
+}
@@ -288,7 +288,7 @@ func (m *portForwardModel) View() string {
 
 	// Help
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("↑/↓: navigate • Enter: connect • Esc: cancel"))
+	b.WriteString(helpStyle.Render(hintLine(m.hintState(), nil)))
 
 	content := b.String()
 
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// opensshInstallHint tells how to get the OpenSSH client programs
+const opensshInstallHint = "Install the OpenSSH client, e.g. apt install openssh-client, apk add openssh-client or dnf install openssh-clients"
+
+// installHints tells how to install the programs sshc hands the terminal to
+var installHints = map[string]string{
+	"ssh":         opensshInstallHint,
+	"scp":         opensshInstallHint,
+	"ssh-copy-id": opensshInstallHint,
+	"ssh-add":     opensshInstallHint,
+	"kubectl":     "See https://kubernetes.io/docs/tasks/tools/ to install it",
+}
+
+// missingProgramError is returned instead of running a program that is not installed
+type missingProgramError struct {
+	name string
+}
+
+func (e *missingProgramError) Error() string {
+	message := fmt.Sprintf("%s is not installed or not in PATH", e.name)
+	if hint := installHints[e.name]; hint != "" {
+		message += ". " + hint
+	}
+	return message
+}
+
+// checkProgram returns a missingProgramError when the program c would run cannot be found
+func checkProgram(c *exec.Cmd) error {
+	if _, err := exec.LookPath(c.Path); err != nil {
+		return &missingProgramError{name: filepath.Base(c.Args[0])}
+	}
+	return nil
+}
+
+// startFailed reports whether err means a program did not run at all, rather
+// than that it ran and failed
+func startFailed(err error) bool {
+	var missing *missingProgramError
+	var execErr *exec.Error
+	var pathErr *fs.PathError
+	return errors.As(err, &missing) || errors.As(err, &execErr) || errors.As(err, &pathErr)
+}
+
+// execProcess hands the terminal to c like tea.ExecProcess. When its program is
+// not installed, fn gets a missingProgramError right away instead.
+func execProcess(c *exec.Cmd, fn tea.ExecCallback) tea.Cmd {
+	sshclog.Exec(c.Args)
+	if err := checkProgram(c); err != nil {
+		sshclog.Warn("program not found", "program", c.Args[0])
+		return func() tea.Msg { return fn(err) }
+	}
+	return tea.ExecProcess(c, fn)
+}
+
+// execHinted hands the terminal to c like tea.Exec, checking its program like execProcess
+func execHinted(c *hintedCommand, fn tea.ExecCallback) tea.Cmd {
+	sshclog.Exec(c.Cmd.Args)
+	if err := checkProgram(c.Cmd); err != nil {
+		sshclog.Warn("program not found", "program", c.Cmd.Args[0])
+		return func() tea.Msg { return fn(err) }
+	}
+	return tea.Exec(c, fn)
+}
+
+// execFailedMsg is sent when a program that was to take over the terminal did not start
+type execFailedMsg struct {
+	err error
+}
+
+// showExecFailure returns to the host list with a toast saying why a program did not start
+func (m *Model) showExecFailure(err error) tea.Cmd {
+	m.viewMode = ViewList
+	m.portForwardForm = nil
+	m.transferForm = nil
+	m.table.Focus()
+
+	m.errorMessage = err.Error()
+	m.showingError = true
+	m.report(severityError, sourceExec, m.errorMessage)
+	return func() tea.Msg {
+		time.Sleep(5 * time.Second)
+		return errorMsg("clear")
+	}
+}
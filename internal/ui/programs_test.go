@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// emptyPath leaves nothing for exec to find
+func emptyPath(t *testing.T) {
+	t.Helper()
+	t.Setenv("PATH", t.TempDir())
+}
+
+func TestCheckProgramNamesMissingProgram(t *testing.T) {
+	emptyPath(t)
+	err := checkProgram(exec.Command("ssh-copy-id", "web"))
+	var missing *missingProgramError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a missing program error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "ssh-copy-id is not installed") || !strings.Contains(err.Error(), "openssh-client") {
+		t.Errorf("Expected the program and how to install it, got %q", err)
+	}
+	if !startFailed(err) {
+		t.Error("Expected a missing program to count as not started")
+	}
+}
+
+func TestStartFailed(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if err := exec.Command("sh", "-c", "exit 255").Run(); startFailed(err) {
+		t.Errorf("Expected a program that exited with an error to have started, got %v", err)
+	}
+	if err := exec.Command("/nonexistent/ssh").Run(); !startFailed(err) {
+		t.Errorf("Expected %v to count as not started", err)
+	}
+	if startFailed(nil) {
+		t.Error("Expected no error to count as started")
+	}
+}
+
+func TestConnectWithoutSSHKeepsTUI(t *testing.T) {
+	emptyPath(t)
+	m := createLargeTestModel(3)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Expected a command")
+	}
+	msg := cmd()
+	if _, ok := msg.(sshConnectionResultMsg); !ok {
+		t.Fatalf("Expected the connection to end at once, got %T", msg)
+	}
+
+	updated, cmd = m.Update(msg)
+	m = updated.(Model)
+	if cmd == nil || m.viewMode != ViewList {
+		t.Fatalf("Expected to stay in the host list, got view %v", m.viewMode)
+	}
+	if !m.showingError || !strings.Contains(m.errorMessage, "ssh is not installed") {
+		t.Errorf("Expected a toast about ssh, got %q", m.errorMessage)
+	}
+	if !strings.Contains(m.View(), "ssh is not installed") {
+		t.Error("Expected the toast in the view")
+	}
+}
+
+func TestExecFailedReturnsToList(t *testing.T) {
+	m := createLargeTestModel(3)
+	m.viewMode = ViewPortForward
+	m.portForwardForm = &portForwardModel{}
+
+	updated, _ := m.Update(execFailedMsg{err: &missingProgramError{name: "ssh"}})
+	m = updated.(Model)
+	if m.viewMode != ViewList || m.portForwardForm != nil {
+		t.Errorf("Expected the form to close, got view %v", m.viewMode)
+	}
+	if !strings.Contains(m.errorMessage, "ssh is not installed") {
+		t.Errorf("Expected a toast about ssh, got %q", m.errorMessage)
+	}
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/xvertile/sshc/internal/history"
@@ -102,6 +103,7 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 			return m, func() tea.Msg { return quickTransferCancelMsg{} }
 		}
 		m.localPath = msg.path
+		m.rememberDir(msg.path, false)
 
 		if m.direction == transfer.Download {
 			// For downloads: both paths set (remote first, then local), execute transfer
@@ -118,6 +120,7 @@ func (m *quickTransferModel) Update(msg tea.Msg) (*quickTransferModel, tea.Cmd)
 			return m, func() tea.Msg { return quickTransferCancelMsg{} }
 		}
 		m.remotePath = msg.path
+		m.rememberDir(msg.path, true)
 
 		if m.direction == transfer.Download {
 			// For downloads: remote picked, now ask for local destination
@@ -322,8 +325,8 @@ func (m *quickTransferModel) openLocalPicker() tea.Cmd {
 			title = "Select download destination"
 		}
 
-		startDir, _ := os.Getwd()
-		result, err := transfer.OpenFilePicker(mode, title, startDir)
+		_, lastLocal := m.lastDirs()
+		result, err := transfer.OpenFilePicker(mode, title, localStartDir(lastLocal))
 		if err != nil || result == nil || !result.Selected {
 			return quickLocalPickedMsg{selected: false}
 		}
@@ -347,16 +350,62 @@ func (m *quickTransferModel) openRemotePicker() tea.Cmd {
 		}
 	}
 
+	startPath, _ := m.lastDirs()
+	if startPath == "" {
+		startPath = "~"
+	}
+
 	return func() tea.Msg {
 		return openRemoteBrowserMsg{
 			host:       m.hostName,
-			startPath:  "~",
+			startPath:  startPath,
 			configFile: m.configFile,
 			mode:       mode,
 		}
 	}
 }
 
+// lastDirs returns the remote and local directories last picked for the host
+func (m *quickTransferModel) lastDirs() (remoteDir, localDir string) {
+	if m.historyManager == nil {
+		return "", ""
+	}
+	return m.historyManager.GetLastDirs(m.hostName)
+}
+
+// rememberDir records where a picked path is so the next transfer with the host
+// starts there. The destination is remembered as picked and the source by the
+// directory holding it.
+func (m *quickTransferModel) rememberDir(picked string, remote bool) {
+	if m.historyManager == nil || picked == "" {
+		return
+	}
+	source := (m.direction == transfer.Upload) != remote
+	if remote {
+		if source {
+			picked = path.Dir(picked)
+		}
+		_ = m.historyManager.RecordLastDirs(m.hostName, picked, "")
+		return
+	}
+	if source {
+		picked = filepath.Dir(picked)
+	}
+	_ = m.historyManager.RecordLastDirs(m.hostName, "", picked)
+}
+
+// localStartDir returns dir when it is still a directory, or else the working
+// directory
+func localStartDir(dir string) string {
+	if dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
 func (m *quickTransferModel) executeTransfer() tea.Cmd {
 	localPath := m.localPath
 	recursive := false
@@ -395,19 +444,24 @@ func (m *quickTransferModel) executeTransfer() tea.Cmd {
 	// Return a command that waits for the transfer to complete
 	return func() tea.Msg {
 		result := <-m.runningTransfer.Done()
-		if !result.Success {
-			return quickTransferDoneMsg{success: false, err: result.Error}
-		}
 
-		// Record in history
+		// Record in history, failures included
 		if m.historyManager != nil {
-			direction := "upload"
-			if m.direction == transfer.Download {
-				direction = "download"
+			entry := transferHistoryEntry(req)
+			entry.LocalPath = m.localPath
+			entry.Outcome = history.TransferSucceeded
+			if !result.Success {
+				entry.Outcome = history.TransferFailed
+				if result.Error != nil {
+					entry.Error = result.Error.Error()
+				}
 			}
-			_ = m.historyManager.RecordTransfer(m.hostName, direction, m.localPath, m.remotePath)
+			_ = m.historyManager.RecordTransferEntry(m.hostName, entry)
 		}
 
+		if !result.Success {
+			return quickTransferDoneMsg{success: false, err: result.Error}
+		}
 		return quickTransferDoneMsg{success: true}
 	}
 }
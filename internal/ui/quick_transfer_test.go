@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/transfer"
+)
+
+func TestQuickTransferRemembersDirectories(t *testing.T) {
+	hm := newTestHistoryManager(t)
+	local := t.TempDir()
+
+	upload := &quickTransferModel{hostName: "web", direction: transfer.Upload, historyManager: hm}
+	upload.Update(quickLocalPickedMsg{path: filepath.Join(local, "site.tar.gz"), selected: true})
+	upload.Update(quickRemotePickedMsg{path: "/var/www/releases", selected: true})
+	if remote, got := hm.GetLastDirs("web"); remote != "/var/www/releases" || got != local {
+		t.Errorf("Expected the upload's destination and the directory of its source, got %q, %q", remote, got)
+	}
+
+	download := &quickTransferModel{hostName: "web", direction: transfer.Download, downloadType: UploadFile, historyManager: hm}
+	download.Update(quickRemotePickedMsg{path: "/var/log/nginx/error.log", selected: true})
+	if remote, _ := download.lastDirs(); remote != "/var/log/nginx" {
+		t.Errorf("Expected the directory of the downloaded file, got %q", remote)
+	}
+	if cmd := download.openRemotePicker(); cmd().(openRemoteBrowserMsg).startPath != "/var/log/nginx" {
+		t.Error("Expected the remote browser to start in the remembered directory")
+	}
+	if cmd := (&quickTransferModel{hostName: "db", historyManager: hm}).openRemotePicker(); cmd().(openRemoteBrowserMsg).startPath != "~" {
+		t.Error("Expected a host without a remembered directory to start at home")
+	}
+}
+
+func TestLocalStartDirFallsBack(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		dir:                           dir,
+		filepath.Join(dir, "removed"): cwd,
+		file:                          cwd,
+		"":                            cwd,
+	}
+	for saved, want := range tests {
+		if got := localStartDir(saved); got != want {
+			t.Errorf("localStartDir(%q) = %q, want %q", saved, got, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// configCheckInterval is how often the config files are checked for changes on disk
+const configCheckInterval = 30 * time.Second
+
+// configSnapshot maps each SSH config file to its modification time
+type configSnapshot map[string]time.Time
+
+// configCheckTickMsg asks for the config files to be checked again
+type configCheckTickMsg struct{}
+
+// configCheckMsg carries the modification times found by a check
+type configCheckMsg struct {
+	snapshot configSnapshot
+}
+
+// takeConfigSnapshot records the modification time of configFile and every file it
+// includes, or of the default SSH config when configFile is empty. Files that
+// cannot be read are left out, so deleting one counts as a change.
+func takeConfigSnapshot(configFile string) configSnapshot {
+	files, err := config.GetAllConfigFilesFromBase(configFile)
+	if err != nil {
+		return nil
+	}
+	snapshot := make(configSnapshot, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			snapshot[file] = info.ModTime()
+		}
+	}
+	return snapshot
+}
+
+// noteOwnWrite retakes the snapshot after sshc wrote the config itself, so its own
+// change is not reported as one made on disk
+func (m *Model) noteOwnWrite() {
+	if m.configSnapshot != nil {
+		m.configSnapshot = takeConfigSnapshot(m.configFile)
+	}
+}
+
+// differs reports whether a file was added, removed or modified between s and other
+func (s configSnapshot) differs(other configSnapshot) bool {
+	if len(s) != len(other) {
+		return true
+	}
+	for file, modTime := range s {
+		if otherTime, ok := other[file]; !ok || !otherTime.Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfigCmd takes a snapshot of the config files off the UI loop
+func checkConfigCmd(configFile string) tea.Cmd {
+	return func() tea.Msg {
+		return configCheckMsg{snapshot: takeConfigSnapshot(configFile)}
+	}
+}
+
+// configCheckTick schedules the next periodic check of the config files
+func configCheckTick() tea.Cmd {
+	return tea.Tick(configCheckInterval, func(time.Time) tea.Msg { return configCheckTickMsg{} })
+}
+
+// hostChanges counts the hosts a reload added, removed and changed
+type hostChanges struct {
+	added   []string
+	removed []string
+	changed []string
+}
+
+// String summarizes the changes for the reload toast
+func (c hostChanges) String() string {
+	if len(c.added)+len(c.removed)+len(c.changed) == 0 {
+		return "no changes"
+	}
+	var parts []string
+	if len(c.added) > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", len(c.added)))
+	}
+	if len(c.removed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", len(c.removed)))
+	}
+	if len(c.changed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d changed", len(c.changed)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffHosts compares the hosts before and after a reload by name. A host counts as
+// changed when any of its settings differ; moving within its file does not count.
+func diffHosts(before, after []config.SSHHost) hostChanges {
+	old := make(map[string]config.SSHHost, len(before))
+	for _, host := range before {
+		old[host.Name] = host
+	}
+
+	var changes hostChanges
+	seen := make(map[string]bool, len(after))
+	for _, host := range after {
+		seen[host.Name] = true
+		previous, ok := old[host.Name]
+		switch {
+		case !ok:
+			changes.added = append(changes.added, host.Name)
+		case !sameHostSettings(previous, host):
+			changes.changed = append(changes.changed, host.Name)
+		}
+	}
+	for _, host := range before {
+		if !seen[host.Name] {
+			changes.removed = append(changes.removed, host.Name)
+		}
+	}
+	return changes
+}
+
+// sameHostSettings compares two definitions of a host, ignoring where they are
+func sameHostSettings(a, b config.SSHHost) bool {
+	a.Line, b.Line = 0, 0
+	a.Order, b.Order = 0, 0
+	a.DirectiveLines, b.DirectiveLines = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// reloadConfig parses the config files again and rebuilds the list, keeping the
+// search, the sort order and the selected host if it still exists
+func (m *Model) reloadConfig() (hostChanges, error) {
+	var hosts []config.SSHHost
+	var err error
+	if m.configFile != "" {
+		hosts, err = config.ParseSSHConfigFile(m.configFile)
+	} else {
+		hosts, err = config.ParseSSHConfig()
+	}
+	if err != nil {
+		return hostChanges{}, err
+	}
+	if config.K8sConfigExists() {
+		if k8sHosts, err := config.ParseK8sConfig(); err == nil {
+			m.k8sHosts = k8sHosts
+			m.filteredK8sHosts = k8sHosts
+		}
+	}
+
+	selected := ""
+	if entry := m.selectedEntry(); entry != nil {
+		selected = entry.Name
+	}
+
+	changes := diffHosts(m.hosts, hosts)
+	m.hosts = m.sortHosts(hosts)
+	m.filteredHosts = m.hosts
+	m.rowCache = make(map[string]*rowCacheEntry)
+	m.rebuildEntries()
+	m.applySearchFilter()
+
+	for i, entry := range m.displayEntries() {
+		if entry.Name == selected {
+			m.materializeRows(i)
+			break
+		}
+	}
+
+	m.reportLint(m.hosts)
+	m.expireMaintenance()
+	m.configSnapshot = takeConfigSnapshot(m.configFile)
+	m.configStale = false
+	return changes, nil
+}
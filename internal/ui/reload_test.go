@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+func TestDiffHosts(t *testing.T) {
+	before := []config.SSHHost{
+		{Name: "web", Hostname: "10.0.0.1", Line: 1},
+		{Name: "db", Hostname: "10.0.0.2", Tags: []string{"prod"}, Line: 4},
+		{Name: "old", Hostname: "10.0.0.3", Line: 7},
+	}
+	after := []config.SSHHost{
+		{Name: "new", Hostname: "10.0.0.9", Line: 1},
+		{Name: "web", Hostname: "10.0.0.1", Line: 4, DirectiveLines: map[string]int{"hostname": 5}},
+		{Name: "db", Hostname: "10.0.0.2", Tags: []string{"prod", "eu"}, Line: 7},
+	}
+
+	changes := diffHosts(before, after)
+	if !slices.Equal(changes.added, []string{"new"}) {
+		t.Errorf("added = %v, want new", changes.added)
+	}
+	if !slices.Equal(changes.removed, []string{"old"}) {
+		t.Errorf("removed = %v, want old", changes.removed)
+	}
+	if !slices.Equal(changes.changed, []string{"db"}) {
+		t.Errorf("changed = %v, want only db; moving web within the file is no change", changes.changed)
+	}
+	if got := changes.String(); got != "1 added, 1 removed, 1 changed" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := diffHosts(before, before).String(); got != "no changes" {
+		t.Errorf("String() without changes = %q", got)
+	}
+}
+
+func TestConfigSnapshotDiffers(t *testing.T) {
+	now := time.Now()
+	snapshot := configSnapshot{"/a": now, "/b": now}
+
+	if snapshot.differs(configSnapshot{"/a": now, "/b": now}) {
+		t.Error("Expected identical snapshots not to differ")
+	}
+	if !snapshot.differs(configSnapshot{"/a": now, "/b": now.Add(time.Second)}) {
+		t.Error("Expected a modified file to be noticed")
+	}
+	if !snapshot.differs(configSnapshot{"/a": now}) {
+		t.Error("Expected a removed file to be noticed")
+	}
+	if !snapshot.differs(configSnapshot{"/a": now, "/c": now}) {
+		t.Error("Expected a replaced file to be noticed")
+	}
+}
+
+func TestReloadConfigKeepsSelection(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	configFile := filepath.Join(tempDir, "config")
+	write := func(content string) {
+		if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Host alpha\n    HostName 10.0.0.1\n\nHost bravo\n    HostName 10.0.0.2\n\nHost charlie\n    HostName 10.0.0.3\n")
+
+	hosts, err := config.ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := createLargeTestModel(0)
+	m.configFile = configFile
+	m.hosts, m.filteredHosts = hosts, hosts
+	m.rebuildEntries()
+	m.updateTableRows()
+	m.materializeRows(2)
+	m.configSnapshot = takeConfigSnapshot(configFile)
+
+	// Another editor adds a host above the selected one, drops one and changes another
+	write("Host aardvark\n    HostName 10.0.0.9\n\nHost alpha\n    HostName 10.0.0.1\n\nHost charlie\n    HostName 10.0.0.30\n")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(configFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := m.Update(configCheckMsg{snapshot: takeConfigSnapshot(configFile)})
+	m = updated.(Model)
+	if !m.configStale {
+		t.Fatal("Expected the change on disk to be noticed")
+	}
+
+	changes, err := m.reloadConfig()
+	if err != nil {
+		t.Fatalf("reloadConfig() error = %v", err)
+	}
+	if got := changes.String(); got != "1 added, 1 removed, 1 changed" {
+		t.Errorf("changes = %q", got)
+	}
+	if m.configStale {
+		t.Error("Expected the reload to clear the stale indicator")
+	}
+	if entry := m.selectedEntry(); entry == nil || entry.Name != "charlie" || entry.Hostname != "10.0.0.30" {
+		t.Errorf("Selected %+v, want the reloaded charlie", entry)
+	}
+}
+
+func TestOwnWriteIsNotReportedAsChangeOnDisk(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	configFile := filepath.Join(tempDir, "config")
+	if err := os.WriteFile(configFile, []byte("Host alpha\n    HostName 10.0.0.1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := createLargeTestModel(0)
+	m.configFile = configFile
+	m.configSnapshot = takeConfigSnapshot(configFile)
+
+	// The add form appends a host, then reports success
+	if err := config.AddSSHHostToFile(config.SSHHost{Name: "bravo", Hostname: "10.0.0.2"}, configFile); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(configFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := m.Update(addFormSubmitMsg{hostname: "bravo"})
+	m = updated.(Model)
+
+	updated, _ = m.Update(configCheckMsg{snapshot: takeConfigSnapshot(configFile)})
+	m = updated.(Model)
+	if m.configStale {
+		t.Error("Expected sshc's own write not to mark the config as changed on disk")
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/transfer"
+
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -42,12 +45,30 @@ type remoteBrowserModel struct {
 	searchMode  bool
 	searchQuery string
 	searchFiles []transfer.RemoteFile // Search results
+	searchDir   string                // Directory the results are relative to
 	hasLocate   bool                  // Whether locate is available on remote
 	showHidden  bool                  // Whether to show dotfiles
+	sortMode    remoteSortMode        // Order of the files, cycled with o
+	sortReverse bool                  // Order reversed with O
 
 	// Debounce state
 	pendingSearch   string // Query waiting to be searched
 	searchTriggered bool   // Whether a search has been triggered for current query
+
+	// focusName is the file to put the cursor on once its directory has loaded
+	focusName string
+
+	// fallbackDir is loaded instead when the start directory cannot be listed,
+	// such as a remembered directory that was removed since
+	fallbackDir string
+	notice      string
+
+	// appConfig keeps the paths pinned for the host; pinning is off without it
+	appConfig    *config.AppConfig
+	managingPins bool // The pin list has the keys
+	pinCursor    int
+	editingPin   bool
+	pinInput     textinput.Model
 }
 
 // remoteBrowserResultMsg is sent when browsing is complete
@@ -68,6 +89,7 @@ type remoteBrowserLoadedMsg struct {
 type remoteBrowserSearchMsg struct {
 	files []transfer.RemoteFile
 	query string
+	dir   string // Directory searched, with ~ expanded
 	err   error
 }
 
@@ -82,16 +104,22 @@ func NewRemoteBrowser(host, startPath, configFile string, mode BrowserMode, styl
 		startPath = "~"
 	}
 
+	fallbackDir := ""
+	if startPath != "~" {
+		fallbackDir = "~"
+	}
+
 	return &remoteBrowserModel{
-		host:       host,
-		configFile: configFile,
-		currentDir: startPath,
-		mode:       mode,
-		styles:     styles,
-		width:      width,
-		height:     height,
-		loading:    true,
-		cursor:     0,
+		host:        host,
+		configFile:  configFile,
+		currentDir:  startPath,
+		mode:        mode,
+		styles:      styles,
+		width:       width,
+		height:      height,
+		loading:     true,
+		cursor:      0,
+		fallbackDir: fallbackDir,
 	}
 }
 
@@ -134,12 +162,19 @@ func (m *remoteBrowserModel) runSearch() tea.Cmd {
 			return remoteBrowserSearchMsg{err: fmt.Errorf("no session"), query: query}
 		}
 
-		files, err := m.session.QuickSearch(query, m.currentDir, 30)
+		dir := m.currentDir
+		if strings.HasPrefix(dir, "~") {
+			if home, err := m.session.GetHomeDirectory(); err == nil {
+				dir = strings.Replace(dir, "~", home, 1)
+			}
+		}
+
+		files, err := m.session.QuickSearch(query, dir, transfer.SearchLimit)
 		if err != nil {
 			return remoteBrowserSearchMsg{err: err, query: query}
 		}
 
-		return remoteBrowserSearchMsg{files: files, query: query}
+		return remoteBrowserSearchMsg{files: files, query: query, dir: dir}
 	}
 }
 
@@ -149,20 +184,45 @@ func (m *remoteBrowserModel) scheduleSearch(query string) tea.Cmd {
 	})
 }
 
-// filterFiles updates visibleFiles based on showHidden setting
+// filterFiles updates visibleFiles based on showHidden setting, in the
+// current sort order
 func (m *remoteBrowserModel) filterFiles() {
-	if m.showHidden {
-		m.visibleFiles = m.files
-		return
-	}
-
 	m.visibleFiles = nil
 	for _, f := range m.files {
 		// Always show ".." for navigation
-		if f.Name == ".." || !strings.HasPrefix(f.Name, ".") {
+		if m.showHidden || f.Name == ".." || !strings.HasPrefix(f.Name, ".") {
 			m.visibleFiles = append(m.visibleFiles, f)
 		}
 	}
+	sortRemoteFiles(m.visibleFiles, m.sortMode, m.sortReverse)
+}
+
+// focusFile moves the cursor to the file a search result jumped to
+func (m *remoteBrowserModel) focusFile() {
+	if m.focusName == "" {
+		return
+	}
+	name := m.focusName
+	m.focusName = ""
+	if strings.HasPrefix(name, ".") && !m.showHidden {
+		m.showHidden = true
+		m.filterFiles()
+	}
+	for i, f := range m.visibleFiles {
+		if f.Name == name {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// relativePath returns a search result path relative to the directory being searched
+func (m *remoteBrowserModel) relativePath(p string) string {
+	dir := strings.TrimSuffix(m.searchDir, "/") + "/"
+	if rel := strings.TrimPrefix(p, dir); rel != p && rel != "" {
+		return rel
+	}
+	return p
 }
 
 // filterSearchResults filters existing search results by current query (for backspace)
@@ -228,7 +288,15 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 	switch msg := msg.(type) {
 	case remoteBrowserLoadedMsg:
 		m.loading = false
+		fallbackDir := m.fallbackDir
+		m.fallbackDir = ""
 		if msg.err != nil {
+			// Only a listing error falls back, not a failed connection
+			if fallbackDir != "" && m.session != nil {
+				m.notice = fmt.Sprintf("Could not open %s, showing %s instead", m.currentDir, fallbackDir)
+				m.loading = true
+				return m, m.loadDirectory(fallbackDir)
+			}
 			m.err = msg.err.Error()
 			return m, nil
 		}
@@ -240,6 +308,7 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 		m.searchQuery = ""
 		m.searchFiles = nil
 		m.filterFiles()
+		m.focusFile()
 		return m, nil
 
 	case remoteBrowserSearchMsg:
@@ -254,6 +323,7 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 			return m, nil
 		}
 		m.searchFiles = msg.files
+		m.searchDir = msg.dir
 		m.sortSearchResults()
 		m.cursor = 0
 		m.err = ""
@@ -325,14 +395,16 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 						m.searchTriggered = false
 						m.loading = true
 						return m, m.loadDirectory(file.Path)
-					} else if m.mode == BrowseFiles {
-						// Select file
-						if m.session != nil {
-							m.session.Close()
-						}
-						return m, func() tea.Msg {
-							return remoteBrowserResultMsg{path: file.Path, selected: true}
-						}
+					} else {
+						// Jump to the directory containing the file
+						m.searchMode = false
+						m.searchQuery = ""
+						m.searchFiles = nil
+						m.pendingSearch = ""
+						m.searchTriggered = false
+						m.loading = true
+						m.focusName = filepath.Base(file.Path)
+						return m, m.loadDirectory(filepath.Dir(file.Path))
 					}
 				}
 				return m, nil
@@ -384,6 +456,13 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 		}
 
 		// Normal mode
+		if m.managingPins {
+			return m.updatePinManager(msg)
+		}
+		m.notice = ""
+		if cmd, ok := m.handlePinKey(msg.String()); ok {
+			return m, cmd
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			// Cancel
@@ -430,6 +509,11 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 			}
 			return m, nil
 
+		case "o", "O":
+			// Cycle the sort order, or reverse it
+			m.cycleSort(msg.String())
+			return m, nil
+
 		case "r", "R":
 			// Retry connection / reload current directory
 			m.err = ""
@@ -531,6 +615,26 @@ func (m *remoteBrowserModel) Update(msg tea.Msg) (*remoteBrowserModel, tea.Cmd)
 }
 
 func (m *remoteBrowserModel) View() string {
+	theme := GetCurrentTheme()
+	container := m.renderPanel(theme.Primary, 0)
+
+	// Logo outside the container
+	logo := m.styles.Header.Render(asciiTitle)
+
+	// Stack logo and container
+	fullContent := lipgloss.JoinVertical(lipgloss.Center, logo, "", container)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		fullContent,
+	)
+}
+
+// renderPanel renders the bordered browser panel. A width of 0 sizes it to its content.
+func (m *remoteBrowserModel) renderPanel(borderColor string, width int) string {
 	var b strings.Builder
 	theme := GetCurrentTheme()
 
@@ -558,6 +662,8 @@ func (m *remoteBrowserModel) View() string {
 	// Error message
 	if m.err != "" {
 		b.WriteString(m.styles.Error.Render("Error: "+m.err) + "\n\n")
+	} else if m.notice != "" {
+		b.WriteString(m.styles.HelpText.Render(m.notice) + "\n\n")
 	}
 
 	// Loading indicator or file list
@@ -579,8 +685,12 @@ func (m *remoteBrowserModel) View() string {
 			displayFiles = nil
 		}
 
-		if displayFiles != nil {
-			visibleHeight := m.height - 16 // Account for logo + container padding
+		if !m.searchMode && len(m.pins()) > 0 {
+			b.WriteString(m.renderPins() + "\n")
+		}
+
+		if displayFiles != nil && !m.managingPins {
+			visibleHeight := m.height - 16 - len(m.pins()) // Account for logo + container padding
 			if visibleHeight < 5 {
 				visibleHeight = 5
 			}
@@ -594,13 +704,20 @@ func (m *remoteBrowserModel) View() string {
 				end = len(displayFiles)
 			}
 
+			// Narrow panels, such as those of the dual browser, only fit the names
+			showDetails := width == 0 || width >= remoteDetailsMinWidth
+			now := time.Now()
 			for i := start; i < end; i++ {
 				file := displayFiles[i]
 				if m.searchMode {
 					b.WriteString(m.renderSearchResultLine(file, i == m.cursor) + "\n")
-				} else {
-					b.WriteString(m.renderFileLine(file, i == m.cursor) + "\n")
+					continue
+				}
+				details := ""
+				if showDetails {
+					details = fileDetails(file, now)
 				}
+				b.WriteString(m.renderFileLine(file, i == m.cursor, details) + "\n")
 			}
 
 			if len(displayFiles) > visibleHeight {
@@ -613,43 +730,43 @@ func (m *remoteBrowserModel) View() string {
 
 	// Hidden files indicator and help
 	if !m.searchMode {
+		hidden := "off"
 		if m.showHidden {
-			b.WriteString("  [hidden: on]\n")
-		} else {
-			b.WriteString("  [hidden: off]\n")
+			hidden = "on"
 		}
+		b.WriteString(fmt.Sprintf("  [hidden: %s] [sort: %s]\n", hidden, m.sortLabel()))
 	}
 
-	if m.searchMode {
-		b.WriteString(" ↑/↓: navigate | Enter: select | Esc: back\n")
+	pinHelp := ""
+	if m.appConfig != nil {
+		pinHelp = "P: pin | "
+		if len(m.pins()) > 0 {
+			pinHelp = "P: pin | p: pins | "
+		}
+	}
+	if m.managingPins && m.editingPin {
+		b.WriteString(" Enter: save | Esc: cancel\n")
+	} else if m.managingPins {
+		b.WriteString(" ↑/↓: navigate | Enter: open | e: edit | d: unpin | Esc: back\n")
+	} else if m.searchMode {
+		b.WriteString(" ↑/↓: navigate | Enter: go to | Esc: back\n")
 	} else if m.mode == BrowseDirectories {
-		b.WriteString(" ↑/↓: navigate | Enter: open | s: select | r: retry | Esc: cancel\n")
+		b.WriteString(" ↑/↓: navigate | Enter: open | s: select | o/O: sort | .: hidden | " + pinHelp + "r: retry | Esc: cancel\n")
 	} else {
-		b.WriteString(" ↑/↓: navigate | Enter: select | /: search | r: retry | Esc: cancel\n")
+		b.WriteString(" ↑/↓: navigate | Enter: select | /: search | o/O: sort | .: hidden | " + pinHelp + "r: retry | Esc: cancel\n")
 	}
 
 	content := b.String()
 
-	// Container with primary color border
-	container := lipgloss.NewStyle().
+	// Container with a colored border
+	style := lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(theme.Primary)).
-		Padding(1, 2).
-		Render(content)
-
-	// Logo outside the container
-	logo := m.styles.Header.Render(asciiTitle)
-
-	// Stack logo and container
-	fullContent := lipgloss.JoinVertical(lipgloss.Center, logo, "", container)
-
-	return lipgloss.Place(
-		m.width,
-		m.height,
-		lipgloss.Center,
-		lipgloss.Center,
-		fullContent,
-	)
+		BorderForeground(lipgloss.Color(borderColor)).
+		Padding(1, 2)
+	if width > 0 {
+		style = style.Width(width)
+	}
+	return style.Render(content)
 }
 
 // ANSI escape codes
@@ -673,7 +790,9 @@ func hexToDec(hex string) int {
 	return result
 }
 
-func (m *remoteBrowserModel) renderFileLine(file transfer.RemoteFile, selected bool) string {
+// renderFileLine renders a file of the listing, followed by its details columns
+// when there are any
+func (m *remoteBrowserModel) renderFileLine(file transfer.RemoteFile, selected bool, details string) string {
 	theme := GetCurrentTheme()
 	var icon, name string
 
@@ -693,6 +812,9 @@ func (m *remoteBrowserModel) renderFileLine(file transfer.RemoteFile, selected b
 	if len(displayName) > 42 {
 		displayName = displayName[:39] + "..."
 	}
+	if details != "" {
+		displayName += strings.Repeat(" ", max(0, 42-lipgloss.Width(displayName))) + "  " + details
+	}
 
 	if selected {
 		// Use theme selection colors
@@ -715,12 +837,12 @@ func (m *remoteBrowserModel) renderSearchResultLine(file transfer.RemoteFile, se
 		icon = " "
 	}
 
-	path := file.Path
-	if len(path) > 50 {
-		path = "..." + path[len(path)-47:]
+	name := m.relativePath(file.Path)
+	if len(name) > 50 {
+		name = "..." + name[len(name)-47:]
 	}
 
-	displayName := icon + " " + path
+	displayName := icon + " " + name
 
 	if selected {
 		return fmt.Sprintf("\x1b[38;2;%s\x1b[48;2;%s  %s%s",
@@ -793,6 +915,9 @@ func (m standaloneRemoteBrowser) View() string {
 func RunRemoteBrowser(host, startPath, configFile string, mode BrowserMode) (string, bool, error) {
 	styles := NewStyles(80)
 	browser := NewRemoteBrowser(host, startPath, configFile, mode, styles, 80, 24)
+	if appConfig, err := config.LoadAppConfig(); err == nil {
+		browser.appConfig = appConfig
+	}
 	m := standaloneRemoteBrowser{browser}
 
 	p := tea.NewProgram(m,
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/transfer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRemoteBrowserSearchResultJumpsToDirectory(t *testing.T) {
+	m := NewRemoteBrowser("web", "~", "", BrowseFiles, NewStyles(120), 120, 40)
+	m.searchMode = true
+	m.searchQuery = "app"
+	m, _ = m.Update(remoteBrowserSearchMsg{
+		query: "app",
+		dir:   "/home/deploy",
+		files: []transfer.RemoteFile{{Name: ".app.env", Path: "/home/deploy/srv/.app.env"}},
+	})
+	if got := m.relativePath(m.searchFiles[0].Path); got != "srv/.app.env" {
+		t.Errorf("Expected the result relative to the searched directory, got %q", got)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searchMode || cmd == nil {
+		t.Fatal("Expected enter on a file to leave search and load its directory")
+	}
+
+	m, _ = m.Update(remoteBrowserLoadedMsg{dir: "/home/deploy/srv", files: []transfer.RemoteFile{
+		{Name: "..", IsDir: true},
+		{Name: ".app.env", Path: "/home/deploy/srv/.app.env"},
+		{Name: "main.go", Path: "/home/deploy/srv/main.go"},
+	}})
+	if !m.showHidden {
+		t.Error("Expected hidden files to be shown when jumping to a dotfile")
+	}
+	if got := m.visibleFiles[m.cursor].Name; got != ".app.env" {
+		t.Errorf("Expected the cursor on the matched file, got %q", got)
+	}
+}
+
+func TestRemoteBrowserFallsBackWhenStartDirIsGone(t *testing.T) {
+	m := NewRemoteBrowser("web", "/var/www/releases", "", BrowseDirectories, NewStyles(120), 120, 40)
+	m.session = &transfer.SFTPSession{}
+
+	m, cmd := m.Update(remoteBrowserLoadedMsg{err: errors.New("No such file or directory")})
+	if cmd == nil || !m.loading || m.err != "" {
+		t.Fatal("Expected a missing start directory to load home instead of failing")
+	}
+	m, _ = m.Update(remoteBrowserLoadedMsg{dir: "/home/deploy", files: []transfer.RemoteFile{{Name: "..", IsDir: true}}})
+	if m.currentDir != "/home/deploy" {
+		t.Errorf("Expected home to be shown, got %q", m.currentDir)
+	}
+	if !strings.Contains(m.renderPanel("#ffffff", 0), "Could not open /var/www/releases") {
+		t.Error("Expected a notice about the missing directory")
+	}
+
+	// Later listing errors are shown as they are
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'~'}})
+	if m.notice != "" {
+		t.Error("Expected the notice to go away on the next key")
+	}
+	m, cmd = m.Update(remoteBrowserLoadedMsg{err: errors.New("Permission denied")})
+	if cmd != nil || m.err != "Permission denied" {
+		t.Errorf("Expected the error to be shown, got %q", m.err)
+	}
+}
+
+func TestRemoteBrowserConnectionErrorDoesNotFallBack(t *testing.T) {
+	m := NewRemoteBrowser("web", "/var/www/releases", "", BrowseDirectories, NewStyles(120), 120, 40)
+	m, cmd := m.Update(remoteBrowserLoadedMsg{err: errors.New("connection refused")})
+	if cmd != nil || m.err != "connection refused" {
+		t.Errorf("Expected a failed connection to be reported, got %q", m.err)
+	}
+}
+
+func TestRemoteBrowserPinnedPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	setTestClock(t, time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC))
+	appConfig := config.GetDefaultAppConfig()
+	m := NewRemoteBrowser("web", "~", "", BrowseDirectories, NewStyles(120), 120, 40)
+	m.appConfig = &appConfig
+	key := func(k string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)} }
+	loaded := func(dir string) remoteBrowserLoadedMsg {
+		return remoteBrowserLoadedMsg{dir: dir, files: []transfer.RemoteFile{{Name: "..", IsDir: true}}}
+	}
+
+	// Pin two directories with P
+	for _, dir := range []string{"/var/www/releases/current", "/etc/nginx/sites-enabled"} {
+		m, _ = m.Update(loaded(dir))
+		m, _ = m.Update(key("P"))
+	}
+	saved, err := config.LoadAppConfig()
+	if err != nil || len(saved.PinnedPathsFor("web")) != 2 {
+		t.Fatalf("saved pins = %+v, %v", saved.PinnedPathsFor("web"), err)
+	}
+	panel := m.renderPanel("#ffffff", 0)
+	for _, want := range []string{"1 /var/www/releases/current", "2 /etc/nginx/sites-enabled", "p: pins"} {
+		if !strings.Contains(panel, want) {
+			t.Errorf("panel lacks %q:\n%s", want, panel)
+		}
+	}
+
+	// A number key jumps to its pin
+	m, cmd := m.Update(key("1"))
+	if cmd == nil || !m.loading {
+		t.Fatal("1 did not load the first pin")
+	}
+	m, _ = m.Update(loaded("/var/www/releases/current"))
+	if m.currentDir != "/var/www/releases/current" {
+		t.Errorf("currentDir = %q", m.currentDir)
+	}
+
+	// The pin list edits and removes pins
+	m, _ = m.Update(key("p"))
+	if !m.managingPins {
+		t.Fatal("p did not open the pin list")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(key("e"))
+	m.pinInput.SetValue("/etc/nginx/conf.d")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(key("d"))
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.managingPins {
+		t.Error("Esc did not close the pin list")
+	}
+	saved, _ = config.LoadAppConfig()
+	if pins := saved.PinnedPathsFor("web"); len(pins) != 1 || pins[0].Path != "/etc/nginx/conf.d" {
+		t.Errorf("saved pins = %+v, want the edited pin alone", pins)
+	}
+}
+
+func TestRemoteBrowserSortsByNameSizeAndTime(t *testing.T) {
+	m := NewRemoteBrowser("web", "~", "", BrowseFiles, NewStyles(120), 120, 40)
+	now := time.Now()
+	m, _ = m.Update(remoteBrowserLoadedMsg{dir: "/var/log", files: []transfer.RemoteFile{
+		{Name: "..", IsDir: true},
+		{Name: "syslog", Size: 2048, ModTime: now.Add(-2 * time.Hour)},
+		{Name: "nginx", IsDir: true, Size: 4096, ModTime: now.Add(-72 * time.Hour)},
+		{Name: "app.log", Size: 3 * 1024 * 1024, ModTime: now.Add(-30 * time.Minute)},
+		{Name: ".hidden.log", Size: 10, ModTime: now},
+		{Name: "auth.log", Size: 512, ModTime: now.Add(-5 * time.Hour)},
+	}})
+	key := func(k string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)} }
+	names := func() string {
+		var names []string
+		for _, f := range m.visibleFiles {
+			names = append(names, f.Name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	steps := []struct {
+		key  string
+		want string
+	}{
+		{"", ".. nginx app.log auth.log syslog"},
+		{"o", ".. nginx app.log syslog auth.log"},             // Largest first
+		{"o", ".. nginx app.log syslog auth.log"},             // Newest first
+		{"O", ".. nginx auth.log syslog app.log"},             // Oldest first
+		{".", ".. nginx auth.log syslog app.log .hidden.log"}, // Dotfiles join the order
+		{"o", ".. nginx .hidden.log app.log auth.log syslog"}, // Back to names
+	}
+	for i, step := range steps {
+		if step.key != "" {
+			m, _ = m.Update(key(step.key))
+		}
+		if got := names(); got != step.want {
+			t.Errorf("step %d (%q): order = %q, want %q", i, step.key, got, step.want)
+		}
+	}
+
+	// The cursor stays on its file when the order changes
+	m.cursor = 3 // app.log
+	m, _ = m.Update(key("O"))
+	if got := m.visibleFiles[m.cursor].Name; got != "app.log" {
+		t.Errorf("cursor on %q after reversing, want app.log", got)
+	}
+
+	panel := m.renderPanel("#ffffff", 0)
+	for _, want := range []string{"3.0M  30 minutes ago", "512B  5 hours ago", "3 days ago", "[sort: name, reversed]"} {
+		if !strings.Contains(panel, want) {
+			t.Errorf("panel lacks %q:\n%s", want, panel)
+		}
+	}
+	if narrow := m.renderPanel("#ffffff", 50); strings.Contains(narrow, "minutes ago") {
+		t.Errorf("narrow panel shows details:\n%s", narrow)
+	}
+}
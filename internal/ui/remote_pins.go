@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pinKeyIndex returns the pin a number key jumps to: 1-9 for the first nine, 0
+// for the tenth
+func pinKeyIndex(key string) (int, bool) {
+	if len(key) != 1 || key[0] < '0' || key[0] > '9' {
+		return 0, false
+	}
+	if key == "0" {
+		return 9, true
+	}
+	return int(key[0] - '1'), true
+}
+
+// pinKey returns the number key of the pin at index i
+func pinKey(i int) string {
+	if i == 9 {
+		return "0"
+	}
+	return fmt.Sprint(i + 1)
+}
+
+// pins returns the paths pinned for the host being browsed
+func (m *remoteBrowserModel) pins() []config.PinnedPath {
+	return m.appConfig.PinnedPathsFor(m.host)
+}
+
+// savePins writes the pins, noting a failure in the browser
+func (m *remoteBrowserModel) savePins() {
+	if err := config.SaveAppConfig(m.appConfig); err != nil {
+		m.notice = fmt.Sprintf("Could not save pins: %v", err)
+	}
+}
+
+// handlePinKey pins the current directory with P, opens the pin list with p and
+// jumps to a pin with its number key. It reports whether key was one of them.
+func (m *remoteBrowserModel) handlePinKey(key string) (tea.Cmd, bool) {
+	if m.appConfig == nil {
+		return nil, false
+	}
+	switch key {
+	case "P":
+		dir := m.currentDir
+		if evicted := m.appConfig.PinPath(m.host, dir, clock()); evicted != "" {
+			m.notice = fmt.Sprintf("Pinned %s, unpinned %s (used longest ago)", dir, evicted)
+		} else {
+			m.notice = "Pinned " + dir
+		}
+		m.savePins()
+		return nil, true
+	case "p":
+		if len(m.pins()) == 0 {
+			m.notice = "No pinned paths yet, P pins the current directory"
+			return nil, true
+		}
+		m.managingPins = true
+		m.pinCursor = 0
+		return nil, true
+	}
+	if i, ok := pinKeyIndex(key); ok && i < len(m.pins()) {
+		return m.jumpToPin(i), true
+	}
+	return nil, false
+}
+
+// jumpToPin loads the directory of the pin at index i and marks it used
+func (m *remoteBrowserModel) jumpToPin(i int) tea.Cmd {
+	pin := m.pins()[i].Path
+	m.appConfig.TouchPinnedPath(m.host, pin, clock())
+	m.savePins()
+	m.managingPins = false
+	m.loading = true
+	return m.loadDirectory(pin)
+}
+
+// updatePinManager handles the keys of the pin list, where pins are opened,
+// edited and removed
+func (m *remoteBrowserModel) updatePinManager(msg tea.KeyMsg) (*remoteBrowserModel, tea.Cmd) {
+	pins := m.pins()
+	if m.editingPin {
+		switch msg.String() {
+		case "enter":
+			m.editingPin = false
+			newPath := strings.TrimSpace(m.pinInput.Value())
+			if newPath != pins[m.pinCursor].Path && !m.appConfig.ReplacePinnedPath(m.host, pins[m.pinCursor].Path, newPath) {
+				m.notice = fmt.Sprintf("%s is empty or pinned already", newPath)
+				return m, nil
+			}
+			m.savePins()
+		case "esc":
+			m.editingPin = false
+		default:
+			var cmd tea.Cmd
+			m.pinInput, cmd = m.pinInput.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	m.notice = ""
+	switch key := msg.String(); key {
+	case "esc", "q", "p":
+		m.managingPins = false
+	case "up", "k":
+		if m.pinCursor > 0 {
+			m.pinCursor--
+		}
+	case "down", "j":
+		if m.pinCursor < len(pins)-1 {
+			m.pinCursor++
+		}
+	case "enter":
+		return m, m.jumpToPin(m.pinCursor)
+	case "e":
+		m.editingPin = true
+		m.pinInput = textinput.New()
+		m.pinInput.SetValue(pins[m.pinCursor].Path)
+		m.pinInput.CursorEnd()
+		return m, m.pinInput.Focus()
+	case "d", "x", "delete":
+		m.appConfig.UnpinPath(m.host, pins[m.pinCursor].Path)
+		m.savePins()
+		if remaining := len(m.pins()); remaining == 0 {
+			m.managingPins = false
+		} else if m.pinCursor >= remaining {
+			m.pinCursor = remaining - 1
+		}
+	default:
+		if i, ok := pinKeyIndex(key); ok && i < len(pins) {
+			return m, m.jumpToPin(i)
+		}
+	}
+	return m, nil
+}
+
+// renderPins renders the pinned paths with their number keys, the selected one
+// highlighted while the pin list is open
+func (m *remoteBrowserModel) renderPins() string {
+	theme := GetCurrentTheme()
+	var b strings.Builder
+	for i, pin := range m.pins() {
+		line := fmt.Sprintf("%s %s", pinKey(i), pin.Path)
+		switch {
+		case m.managingPins && i == m.pinCursor && m.editingPin:
+			line = fmt.Sprintf("  %s %s", pinKey(i), m.pinInput.View())
+		case m.managingPins && i == m.pinCursor:
+			line = fmt.Sprintf("\x1b[38;2;%s\x1b[48;2;%s  %s%s",
+				hexToRGB(theme.SelectionFg), hexToRGB(theme.SelectionBg), line, ansiReset)
+		default:
+			line = fmt.Sprintf("\x1b[38;2;%s  %s%s", hexToRGB(theme.Muted), line, ansiReset)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/transfer"
+)
+
+// remoteSortMode is the order of the files in the remote browser
+type remoteSortMode int
+
+const (
+	remoteSortName remoteSortMode = iota
+	remoteSortSize
+	remoteSortModTime
+)
+
+func (s remoteSortMode) String() string {
+	switch s {
+	case remoteSortSize:
+		return "size"
+	case remoteSortModTime:
+		return "modified"
+	default:
+		return "name"
+	}
+}
+
+// remoteDetailsMinWidth is the narrowest panel that shows the size and
+// modified columns next to the names
+const remoteDetailsMinWidth = 80
+
+// sortRemoteFiles sorts files in place: ".." first, then directories, then
+// files, each by name (A-Z), size (largest first) or modification time (newest
+// first). reverse flips the order within directories and files.
+func sortRemoteFiles(files []transfer.RemoteFile, mode remoteSortMode, reverse bool) {
+	sort.SliceStable(files, func(i, j int) bool {
+		fi, fj := files[i], files[j]
+		if fi.Name == ".." || fj.Name == ".." {
+			return fi.Name == ".." && fj.Name != ".."
+		}
+		if fi.IsDir != fj.IsDir {
+			return fi.IsDir
+		}
+
+		nameI, nameJ := strings.ToLower(fi.Name), strings.ToLower(fj.Name)
+		var less, greater bool
+		switch {
+		case mode == remoteSortSize && fi.Size != fj.Size:
+			less, greater = fi.Size > fj.Size, fi.Size < fj.Size
+		case mode == remoteSortModTime && !fi.ModTime.Equal(fj.ModTime):
+			less, greater = fi.ModTime.After(fj.ModTime), fi.ModTime.Before(fj.ModTime)
+		default:
+			less, greater = nameI < nameJ, nameI > nameJ
+		}
+		if reverse {
+			return greater
+		}
+		return less
+	})
+}
+
+// cycleSort switches to the next sort mode with o, or reverses the order with O,
+// keeping the cursor on the same file
+func (m *remoteBrowserModel) cycleSort(key string) {
+	if key == "O" {
+		m.sortReverse = !m.sortReverse
+	} else {
+		m.sortMode = (m.sortMode + 1) % 3
+		m.sortReverse = false
+	}
+
+	var current string
+	if m.cursor < len(m.visibleFiles) {
+		current = m.visibleFiles[m.cursor].Name
+	}
+	m.filterFiles()
+	for i, f := range m.visibleFiles {
+		if f.Name == current {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// sortLabel describes the current order for the status line, such as "size, reversed"
+func (m *remoteBrowserModel) sortLabel() string {
+	if m.sortReverse {
+		return m.sortMode.String() + ", reversed"
+	}
+	return m.sortMode.String()
+}
+
+// fileDetails renders the size and modification time columns of a file. Sizes
+// are left out for directories, and both for ".." and times ls did not give.
+func fileDetails(file transfer.RemoteFile, now time.Time) string {
+	if file.Name == ".." {
+		return ""
+	}
+	size := ""
+	if !file.IsDir {
+		size = formatSize(file.Size)
+	}
+	modified := ""
+	if !file.ModTime.IsZero() {
+		modified = formatTimeSince(file.ModTime, now)
+	}
+	return padLeft(size, 7) + "  " + modified
+}
+
+func padLeft(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return strings.Repeat(" ", width-len(s)) + s
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// hiddenField is a host setting the list has no column for, searched with a
+// field-scoped term or a deep search
+type hiddenField struct {
+	key   string // Filter term key and the label shown after a host that matched on it
+	value func(host *config.SSHHost) []string
+}
+
+// hiddenFields are searched in order, so the label names the first that matched
+var hiddenFields = []hiddenField{
+	{"proxy", func(host *config.SSHHost) []string { return []string{host.ProxyJump, host.ProxyCommand} }},
+	{"identity", func(host *config.SSHHost) []string { return []string{host.Identity} }},
+	{"command", func(host *config.SSHHost) []string { return []string{host.RemoteCommand} }},
+	{"opt", func(host *config.SSHHost) []string { return strings.Split(host.Options, "\n") }},
+}
+
+// matchHiddenField returns the key of the first hidden field of host that contains
+// word, or "" if none does. A key other than "" only looks in that field.
+func matchHiddenField(host *config.SSHHost, word, key string) string {
+	if host == nil {
+		return ""
+	}
+	for _, field := range hiddenFields {
+		if key != "" && field.key != key {
+			continue
+		}
+		for _, value := range field.value(host) {
+			if value != "" && strings.Contains(strings.ToLower(value), word) {
+				return field.key
+			}
+		}
+	}
+	return ""
+}
+
+// searchMatchLabel returns the hidden field a host matched the query on, for a
+// host that would not have matched on what the list shows, or ""
+func searchMatchLabel(entry HostEntry, query string, deep bool) string {
+	for _, word := range searchWords(strings.ToLower(query)) {
+		if key, value, found := strings.Cut(word, ":"); found && value != "" && isHiddenFieldKey(key) {
+			if key == "opt" {
+				key = ""
+			}
+			if label := matchHiddenField(entry.SSHHost, value, key); label != "" {
+				return label
+			}
+			continue
+		}
+		if _, isTerm := matchFilterTerm(entry, word); !deep || isTerm || entryMatchesVisible(entry, word) {
+			continue
+		}
+		if label := matchHiddenField(entry.SSHHost, word, ""); label != "" {
+			return label
+		}
+	}
+	return ""
+}
+
+// searchWords splits a search query into words. Double quotes keep a phrase
+// together, as in tag:"web server", and are dropped from the word.
+func searchWords(query string) []string {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// isHiddenFieldKey reports whether key scopes a filter term to hidden fields
+func isHiddenFieldKey(key string) bool {
+	for _, field := range hiddenFields {
+		if field.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleDeepSearch makes free-text search words also match the hidden fields
+func (m *Model) toggleDeepSearch() {
+	m.deepSearch = !m.deepSearch
+	m.applySearchFilter()
+}
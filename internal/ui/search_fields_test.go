@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// createHiddenFieldsTestModel creates a model whose hosts differ only in the
+// settings the list has no column for
+func createHiddenFieldsTestModel() Model {
+	hosts := []config.SSHHost{
+		{Name: "app", Hostname: "app.example.com", ProxyJump: "bastion-1"},
+		{Name: "legacy", Hostname: "legacy.example.com", Identity: "~/.ssh/id_rsa"},
+		{Name: "logs", Hostname: "logs.example.com", RemoteCommand: "tail -f /var/log/syslog"},
+		{Name: "tunnel", Hostname: "tunnel.example.com", Options: "ForwardAgent yes\nServerAliveInterval 30"},
+		{Name: "bastion-1", Hostname: "bastion-1.example.com"},
+	}
+	return createTestModel(withHosts(hosts), withSize(120, 24))
+}
+
+func filteredNames(m Model) []string {
+	var names []string
+	for _, entry := range m.filteredEntries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func TestMatchFilterTermHiddenFields(t *testing.T) {
+	host := config.SSHHost{
+		Name:          "app",
+		ProxyCommand:  "ssh -W %h:%p jump.internal",
+		Identity:      "~/.ssh/id_ed25519",
+		RemoteCommand: "tmux attach",
+		Options:       "ForwardAgent yes",
+	}
+	entry := HostEntry{Name: host.Name, SSHHost: &host}
+
+	tests := []struct {
+		word    string
+		matched bool
+	}{
+		{"proxy:jump.internal", true},
+		{"proxy:id_ed25519", false},
+		{"identity:id_ed25519", true},
+		{"identity:id_rsa", false},
+		{"command:tmux", true},
+		{"opt:forwardagent", true},
+		{"opt:jump.internal", true}, // opt: looks in every hidden field
+		{"opt:missing", false},
+	}
+	for _, tt := range tests {
+		matched, ok := matchFilterTerm(entry, tt.word)
+		if !ok || matched != tt.matched {
+			t.Errorf("matchFilterTerm(%q) = %v, %v; want %v, true", tt.word, matched, ok, tt.matched)
+		}
+	}
+
+	// Kubernetes hosts have none of these fields
+	if matched, ok := matchFilterTerm(HostEntry{Name: "pod", IsK8s: true}, "opt:yes"); !ok || matched {
+		t.Errorf("matchFilterTerm(opt:yes) on a k8s host = %v, %v; want false, true", matched, ok)
+	}
+}
+
+func TestFieldScopedSearchTerms(t *testing.T) {
+	m := createHiddenFieldsTestModel()
+
+	for query, want := range map[string]string{
+		"opt:bastion-1":   "app",
+		"identity:id_rsa": "legacy",
+		"command:syslog":  "logs",
+		"opt:serveralive": "tunnel",
+	} {
+		m.searchInput.SetValue(query)
+		m.applySearchFilter()
+		if names := filteredNames(m); len(names) != 1 || names[0] != want {
+			t.Errorf("search %q = %v, want only %s", query, names, want)
+		}
+	}
+}
+
+func TestDeepSearchToggle(t *testing.T) {
+	m := createHiddenFieldsTestModel()
+	m.searchInput.SetValue("bastion-1")
+	m.applySearchFilter()
+	if names := filteredNames(m); len(names) != 1 || names[0] != "bastion-1" {
+		t.Fatalf("search without deep search = %v, want only bastion-1", names)
+	}
+
+	// Terminals send ctrl+/ as ctrl+_
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlUnderscore})
+	m = model.(Model)
+	if !m.deepSearch {
+		t.Fatal("ctrl+_ did not turn deep search on")
+	}
+	if names := filteredNames(m); len(names) != 2 {
+		t.Errorf("deep search = %v, want app and bastion-1", names)
+	}
+	if view := m.renderStatusBar(); !strings.Contains(view, "[deep search]") {
+		t.Errorf("status bar does not show deep search is on:\n%s", view)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlUnderscore})
+	m = model.(Model)
+	if m.deepSearch || len(m.filteredEntries) != 1 {
+		t.Errorf("second ctrl+_ left deep search %v with %v", m.deepSearch, filteredNames(m))
+	}
+}
+
+func TestSearchMatchLabel(t *testing.T) {
+	m := createHiddenFieldsTestModel()
+	entry := func(name string) HostEntry {
+		for _, e := range m.allEntries {
+			if e.Name == name {
+				return e
+			}
+		}
+		t.Fatalf("no host %s", name)
+		return HostEntry{}
+	}
+
+	tests := []struct {
+		host  string
+		query string
+		deep  bool
+		want  string
+	}{
+		{"app", "opt:bastion-1", false, "proxy"},
+		{"legacy", "identity:id_rsa", false, "identity"},
+		{"tunnel", "opt:forwardagent", false, "opt"},
+		{"app", "bastion-1", true, "proxy"},
+		{"app", "bastion-1", false, ""},      // Not searched without deep search
+		{"bastion-1", "bastion-1", true, ""}, // Matched on its name
+		{"logs", "logs syslog", true, "command"},
+		{"app", "", true, ""},
+	}
+	for _, tt := range tests {
+		if got := searchMatchLabel(entry(tt.host), tt.query, tt.deep); got != tt.want {
+			t.Errorf("searchMatchLabel(%s, %q, deep=%v) = %q, want %q", tt.host, tt.query, tt.deep, got, tt.want)
+		}
+	}
+}
+
+func TestSearchMatchLabelInRow(t *testing.T) {
+	m := createHiddenFieldsTestModel()
+	m.searchInput.SetValue("opt:bastion-1")
+	m.applySearchFilter()
+
+	rows := m.table.Rows()
+	if len(rows) != 1 {
+		t.Fatalf("rows = %v, want one", rows)
+	}
+	if !strings.Contains(rows[0][0], "app ~proxy") {
+		t.Errorf("name cell = %q, want the proxy match shown", rows[0][0])
+	}
+	if entry := m.selectedEntry(); entry == nil || entry.Name != "app" {
+		t.Errorf("selectedEntry() = %+v, want app", entry)
+	}
+}
@@ -9,8 +9,35 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// createTestModel creates a model with test data for testing
-func createTestModel() Model {
+// testModelOption changes the model createTestModel builds
+type testModelOption func(*Model)
+
+// withHosts lists hosts instead of the five default servers
+func withHosts(hosts []config.SSHHost) testModelOption {
+	return func(m *Model) {
+		m.hosts = hosts
+		m.filteredHosts = hosts
+	}
+}
+
+// withSize sets the terminal size, with the table taking half its height
+func withSize(width, height int) testModelOption {
+	return func(m *Model) {
+		m.width, m.height = width, height
+		m.styles = NewStyles(width)
+		m.table.SetHeight(height / 2)
+	}
+}
+
+// withCollapsedBlocks lists each multi-host block as a single row
+func withCollapsedBlocks() testModelOption {
+	return func(m *Model) {
+		m.collapseBlocks = true
+	}
+}
+
+// createTestModel creates a model with test data for testing, changed by opts
+func createTestModel(opts ...testModelOption) Model {
 	hosts := []config.SSHHost{
 		{Name: "server1", Hostname: "server1.example.com", User: "user1"},
 		{Name: "server2", Hostname: "server2.example.com", User: "user2"},
@@ -30,14 +57,29 @@ func createTestModel() Model {
 		height:        24,
 		styles:        NewStyles(80),
 	}
+	for _, opt := range opts {
+		opt(&m)
+	}
 
 	// Initialize table with test data
+	m.rebuildEntries()
 	m.updateTableColumns()
 	m.updateTableRows()
 
 	return m
 }
 
+// press sends msg to the model and returns the message its command produces, if any
+func press(t *testing.T, m Model, msg tea.Msg) (Model, tea.Msg) {
+	t.Helper()
+	updated, cmd := m.Update(msg)
+	m = updated.(Model)
+	if cmd == nil {
+		return m, nil
+	}
+	return m, cmd()
+}
+
 func TestSearchModeToggle(t *testing.T) {
 	m := createTestModel()
 
@@ -303,3 +345,122 @@ func TestSearchByUser(t *testing.T) {
 		t.Errorf("Expected 'server1' to match user search, got '%s'", m.filteredHosts[0].Name)
 	}
 }
+
+func TestSearchActionRebinding(t *testing.T) {
+	m := createTestModel()
+	appConfig := config.GetDefaultAppConfig()
+	appConfig.KeyBindings.Actions[config.ActionSearch] = "?"
+	m.appConfig = &appConfig
+
+	// The default "/" key is no longer bound to search
+	keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")}
+	newModel, _ := m.Update(keyMsg)
+	m = newModel.(Model)
+	if m.searchMode {
+		t.Error("'/' should not enter search mode once search is rebound")
+	}
+
+	keyMsg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}
+	newModel, _ = m.Update(keyMsg)
+	m = newModel.(Model)
+	if !m.searchMode {
+		t.Error("Rebound search key should enter search mode")
+	}
+}
+
+func TestQuickTagFilter(t *testing.T) {
+	hosts := []config.SSHHost{
+		{Name: "api", Hostname: "api.example.com", Tags: []string{"prod", "web"}},
+		{Name: "api-staging", Hostname: "api.staging.example.com", Tags: []string{"staging", "web"}},
+		{Name: "db", Hostname: "db.example.com", Tags: []string{"prod"}},
+		{Name: "production-notes", Hostname: "notes.example.com"},
+	}
+	m := Model{
+		hosts:         hosts,
+		filteredHosts: hosts,
+		searchInput:   textinput.New(),
+		table:         table.New(table.WithHeight(10)),
+		ready:         true,
+		width:         120,
+		height:        40,
+		styles:        NewStyles(120),
+		rowCache:      make(map[string]*rowCacheEntry),
+	}
+	m.rebuildEntries()
+	m.updateTableColumns()
+	m.updateTableRows()
+
+	// "#" on the first host opens the picker with its tags
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("#")})
+	m = newModel.(Model)
+	if m.viewMode != ViewTagPicker || m.tagPicker == nil {
+		t.Fatalf("Expected tag picker to open, got view mode %v", m.viewMode)
+	}
+
+	// "1" picks "prod"
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("Expected picking a tag to return a command")
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+
+	if m.viewMode != ViewList || m.searchInput.Value() != "tag:prod" || !m.tagFilterActive() {
+		t.Fatalf("Expected tag:prod filter in list view, got %q (view %v)", m.searchInput.Value(), m.viewMode)
+	}
+	var names []string
+	for _, entry := range m.displayEntries() {
+		names = append(names, entry.Name)
+	}
+	if len(names) != 2 || names[0] != "api" || names[1] != "db" {
+		t.Errorf("Expected only hosts tagged prod, got %v", names)
+	}
+
+	// Esc clears the tag filter instead of quitting
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if cmd != nil {
+		t.Error("Expected Esc to clear the tag filter without quitting")
+	}
+	if m.tagFilterActive() || m.searchInput.Value() != "" || len(m.displayEntries()) != len(hosts) {
+		t.Errorf("Expected the tag filter to be cleared, got %q with %d entries", m.searchInput.Value(), len(m.displayEntries()))
+	}
+}
+
+func TestToggleTagFilterTwiceClears(t *testing.T) {
+	m := createTestModel()
+	m.hosts[0].Tags = []string{"prod"}
+
+	m.toggleTagFilter("prod")
+	if !m.tagFilterActive() {
+		t.Fatal("Expected tag filter to be active")
+	}
+	m.toggleTagFilter("prod")
+	if m.tagFilterActive() || m.searchInput.Value() != "" {
+		t.Errorf("Expected selecting the active tag again to clear it, got %q", m.searchInput.Value())
+	}
+}
+
+func TestTagFilterWithSpaces(t *testing.T) {
+	m := createTestModel()
+	m.hosts[0].Tags = []string{"Web Server"}
+	m.hosts[1].Tags = []string{"web"}
+	m.hosts[2].Tags = []string{"server"}
+	m.filteredHosts = m.hosts
+	m.rebuildEntries()
+
+	m.toggleTagFilter("Web Server")
+	if got := m.searchInput.Value(); got != `tag:"web server"` {
+		t.Errorf("Expected the tag to be quoted in the query, got %q", got)
+	}
+	if names := filteredNames(m); len(names) != 1 || names[0] != "server1" {
+		t.Errorf("Expected only the host tagged %q, got %v", "Web Server", names)
+	}
+
+	m.searchInput.SetValue(`"web server" user1`)
+	m.applySearchFilter()
+	if names := filteredNames(m); len(names) != 1 || names[0] != "server1" {
+		t.Errorf("Expected a quoted phrase to match as one word, got %v", names)
+	}
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/sessions"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionIndicator starts the badge of a host with sessions open to it
+const sessionIndicator = "⇄"
+
+// sessionScanTimeout bounds listing the processes of the machine
+const sessionScanTimeout = 5 * time.Second
+
+// sessionScanMsg carries the sessions open to each host
+type sessionScanMsg struct {
+	counts map[string]sessions.Count
+	err    error
+}
+
+// startSessionScanCmd counts the ssh sessions open to each host, from the ssh
+// processes running on this machine and the ControlMaster sockets present
+func (m Model) startSessionScanCmd() tea.Cmd {
+	if m.browsing() || len(m.hosts) == 0 {
+		return nil
+	}
+	hosts := append([]config.SSHHost(nil), m.hosts...)
+	configFile := m.configFile
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), sessionScanTimeout)
+		defer cancel()
+
+		configPath := configFile
+		if configPath == "" {
+			configPath, _ = config.GetDefaultSSHConfigPath()
+		}
+		names := make([]string, len(hosts))
+		for i, host := range hosts {
+			names[i] = host.Name
+		}
+		// A Host * block usually sets ControlPath, so it is resolved like ssh does
+		settings, err := config.ResolveSettings(names, []string{"ControlPath", "User", "Port"}, configPath)
+		if err != nil {
+			sshclog.Debug("session scan", "err", err)
+		}
+
+		targets := make([]sessions.Host, len(hosts))
+		for i, host := range hosts {
+			values := settings[host.Name]
+			if host.User == "" {
+				host.User = values["user"]
+			}
+			if host.Port == "" {
+				host.Port = values["port"]
+			}
+			targets[i] = sessions.Host{SSHHost: host, ControlPath: values["controlpath"]}
+		}
+
+		start := time.Now()
+		counts, err := sessions.Scan(ctx, targets)
+		sshclog.Debug("session scan", "hosts", len(hosts), "active", len(counts), "duration", time.Since(start), "err", err)
+		return sessionScanMsg{counts: counts, err: err}
+	}
+}
+
+// sessionBadge renders the sessions open to a host: the number of ssh processes,
+// followed by "m" when a ControlMaster socket is present
+func sessionBadge(count sessions.Count) string {
+	if !count.Active() {
+		return ""
+	}
+	badge := sessionIndicator
+	if count.Processes > 0 {
+		badge += strconv.Itoa(count.Processes)
+	}
+	if count.Socket {
+		badge += "m"
+	}
+	return badge
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/sessions"
+)
+
+func TestSessionBadge(t *testing.T) {
+	tests := []struct {
+		count sessions.Count
+		want  string
+	}{
+		{sessions.Count{}, ""},
+		{sessions.Count{Processes: 2}, "⇄2"},
+		{sessions.Count{Socket: true}, "⇄m"},
+		{sessions.Count{Processes: 1, Socket: true}, "⇄1m"},
+	}
+	for _, tt := range tests {
+		if got := sessionBadge(tt.count); got != tt.want {
+			t.Errorf("sessionBadge(%+v) = %q, want %q", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestSessionScanShowsBadge(t *testing.T) {
+	m := createLargeTestModel(4)
+	updated, _ := m.Update(sessionScanMsg{counts: map[string]sessions.Count{"node-0001": {Processes: 3}}})
+	m = updated.(Model)
+
+	rows := m.table.Rows()
+	if strings.Contains(rows[0][0], sessionIndicator) {
+		t.Errorf("name cell = %q, want no badge without sessions", rows[0][0])
+	}
+	if !strings.Contains(rows[1][0], "node-0001 ⇄3") {
+		t.Errorf("name cell = %q, want the session count", rows[1][0])
+	}
+}
@@ -0,0 +1,511 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// snippetPickerMode is what the snippet picker is showing
+type snippetPickerMode int
+
+const (
+	snippetModeList snippetPickerMode = iota
+	snippetModeForm
+	snippetModeConfirmDelete
+)
+
+// Fields of the snippet form, in tab order
+const (
+	snippetFieldName = iota
+	snippetFieldCommand
+	snippetFieldTTY
+	snippetFieldPause
+	snippetFieldCount
+)
+
+type snippetPickerModel struct {
+	hostName      string
+	snippets      []config.Snippet
+	matches       []int // Indexes into snippets, best match first
+	selectedIndex int
+	search        textinput.Model
+	mode          snippetPickerMode
+	err           string
+
+	// Snippet form
+	editIndex    int // Index of the snippet being edited, -1 for a new one
+	nameInput    textinput.Model
+	commandInput textinput.Model
+	tty          bool
+	pause        bool
+	focus        int
+
+	styles Styles
+	width  int
+	height int
+}
+
+// Messages for communication with parent model
+type snippetRunMsg struct {
+	hostName string
+	snippet  config.Snippet
+}
+
+type snippetPickerCancelMsg struct{}
+
+// snippetResultMsg is sent when a snippet finished running
+type snippetResultMsg struct {
+	hostName string
+	snippet  config.Snippet
+	err      error
+}
+
+// NewSnippetPicker creates a picker for running a snippet of the library on a host
+func NewSnippetPicker(hostName string, styles Styles, width, height int) (*snippetPickerModel, error) {
+	snippets, err := config.LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+
+	search := textinput.New()
+	search.Placeholder = "Search snippets..."
+	search.Prompt = "/ "
+	search.Focus()
+
+	nameInput := textinput.New()
+	nameInput.Placeholder = "check disk"
+	nameInput.CharLimit = 64
+
+	commandInput := textinput.New()
+	commandInput.Placeholder = "df -h /"
+	commandInput.CharLimit = 1024
+
+	m := &snippetPickerModel{
+		hostName:     hostName,
+		snippets:     snippets,
+		search:       search,
+		nameInput:    nameInput,
+		commandInput: commandInput,
+		styles:       styles,
+		width:        width,
+		height:       height,
+	}
+	m.filter()
+	return m, nil
+}
+
+func (m *snippetPickerModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *snippetPickerModel) Update(msg tea.Msg) (*snippetPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case snippetModeForm:
+			return m.updateForm(msg)
+		case snippetModeConfirmDelete:
+			return m.updateConfirmDelete(msg)
+		default:
+			return m.updateList(msg)
+		}
+	}
+
+	return m, nil
+}
+
+// updateList handles keys while browsing and searching snippets
+func (m *snippetPickerModel) updateList(msg tea.KeyMsg) (*snippetPickerModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		return m, func() tea.Msg { return snippetPickerCancelMsg{} }
+
+	case "enter":
+		if snippet, ok := m.selected(); ok {
+			hostName := m.hostName
+			return m, func() tea.Msg { return snippetRunMsg{hostName: hostName, snippet: snippet} }
+		}
+		return m, nil
+
+	case "up", "ctrl+p", "ctrl+k":
+		if m.selectedIndex > 0 {
+			m.selectedIndex--
+		}
+		return m, nil
+
+	case "down", "ctrl+n", "ctrl+j":
+		if m.selectedIndex < len(m.matches)-1 {
+			m.selectedIndex++
+		}
+		return m, nil
+
+	case "ctrl+a":
+		m.openForm(-1)
+		return m, textinput.Blink
+
+	case "ctrl+e":
+		if len(m.matches) > 0 {
+			m.openForm(m.matches[m.selectedIndex])
+			return m, textinput.Blink
+		}
+		return m, nil
+
+	case "ctrl+d":
+		if len(m.matches) > 0 {
+			m.mode = snippetModeConfirmDelete
+			m.err = ""
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	previous := m.search.Value()
+	m.search, cmd = m.search.Update(msg)
+	if m.search.Value() != previous {
+		m.filter()
+	}
+	return m, cmd
+}
+
+// updateConfirmDelete asks before deleting the selected snippet
+func (m *snippetPickerModel) updateConfirmDelete(msg tea.KeyMsg) (*snippetPickerModel, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		index := m.matches[m.selectedIndex]
+		updated := append(append([]config.Snippet{}, m.snippets[:index]...), m.snippets[index+1:]...)
+		if err := config.SaveSnippets(updated); err != nil {
+			m.err = err.Error()
+		} else {
+			m.snippets = updated
+			m.filter()
+		}
+		m.mode = snippetModeList
+	default:
+		m.mode = snippetModeList
+	}
+	return m, nil
+}
+
+// openForm starts editing the snippet at index, or a new one when index is -1
+func (m *snippetPickerModel) openForm(index int) {
+	m.mode = snippetModeForm
+	m.editIndex = index
+	m.err = ""
+	m.focus = snippetFieldName
+
+	snippet := config.Snippet{}
+	if index >= 0 {
+		snippet = m.snippets[index]
+	}
+	m.nameInput.SetValue(snippet.Name)
+	m.commandInput.SetValue(snippet.Command)
+	m.tty = snippet.TTY
+	m.pause = snippet.Pause
+	m.focusField()
+}
+
+// focusField moves the cursor to the focused text input
+func (m *snippetPickerModel) focusField() {
+	m.nameInput.Blur()
+	m.commandInput.Blur()
+	switch m.focus {
+	case snippetFieldName:
+		m.nameInput.Focus()
+	case snippetFieldCommand:
+		m.commandInput.Focus()
+	}
+}
+
+// updateForm handles keys in the add/edit snippet form
+func (m *snippetPickerModel) updateForm(msg tea.KeyMsg) (*snippetPickerModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.mode = snippetModeList
+		m.err = ""
+		return m, nil
+
+	case "tab", "down":
+		m.focus = (m.focus + 1) % snippetFieldCount
+		m.focusField()
+		return m, nil
+
+	case "shift+tab", "up":
+		m.focus = (m.focus + snippetFieldCount - 1) % snippetFieldCount
+		m.focusField()
+		return m, nil
+
+	case " ":
+		switch m.focus {
+		case snippetFieldTTY:
+			m.tty = !m.tty
+			return m, nil
+		case snippetFieldPause:
+			m.pause = !m.pause
+			return m, nil
+		}
+
+	case "enter":
+		m.saveForm()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	switch m.focus {
+	case snippetFieldName:
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	case snippetFieldCommand:
+		m.commandInput, cmd = m.commandInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// saveForm writes the snippet from the form to the library
+func (m *snippetPickerModel) saveForm() {
+	snippet := config.Snippet{
+		Name:    strings.TrimSpace(m.nameInput.Value()),
+		Command: strings.TrimSpace(m.commandInput.Value()),
+		TTY:     m.tty,
+		Pause:   m.pause,
+	}
+
+	updated := append([]config.Snippet{}, m.snippets...)
+	if m.editIndex >= 0 {
+		updated[m.editIndex] = snippet
+	} else {
+		updated = append(updated, snippet)
+	}
+	if err := config.SaveSnippets(updated); err != nil {
+		m.err = err.Error()
+		return
+	}
+
+	m.snippets = updated
+	m.mode = snippetModeList
+	m.err = ""
+	m.filter()
+	for i, index := range m.matches {
+		if m.snippets[index].Name == snippet.Name {
+			m.selectedIndex = i
+		}
+	}
+}
+
+// selected returns the highlighted snippet
+func (m *snippetPickerModel) selected() (config.Snippet, bool) {
+	if m.selectedIndex < 0 || m.selectedIndex >= len(m.matches) {
+		return config.Snippet{}, false
+	}
+	return m.snippets[m.matches[m.selectedIndex]], true
+}
+
+// filter ranks the snippets against the search. Matches in the name count double
+// so a name hit beats the same letters scattered through a long command.
+func (m *snippetPickerModel) filter() {
+	query := strings.TrimSpace(m.search.Value())
+	scores := make(map[int]int)
+	m.matches = m.matches[:0]
+	for i, snippet := range m.snippets {
+		if query == "" {
+			m.matches = append(m.matches, i)
+			continue
+		}
+		nameScore, nameOK := fuzzyScore(query, snippet.Name)
+		commandScore, commandOK := fuzzyScore(query, snippet.Command)
+		if !nameOK && !commandOK {
+			continue
+		}
+		if nameOK {
+			nameScore *= 2
+		}
+		scores[i] = max(nameScore, commandScore)
+		m.matches = append(m.matches, i)
+	}
+	sort.SliceStable(m.matches, func(a, b int) bool {
+		return scores[m.matches[a]] > scores[m.matches[b]]
+	})
+	m.selectedIndex = min(m.selectedIndex, len(m.matches)-1)
+	if m.selectedIndex < 0 {
+		m.selectedIndex = 0
+	}
+}
+
+// fuzzyScore matches the letters of pattern in order anywhere in text, ignoring case.
+// Letters that follow each other or start a word score higher.
+func fuzzyScore(pattern, text string) (int, bool) {
+	patternRunes := []rune(strings.ToLower(pattern))
+	textRunes := []rune(strings.ToLower(text))
+
+	score, p := 0, 0
+	previousMatch := -2
+	for i, r := range textRunes {
+		if p == len(patternRunes) {
+			break
+		}
+		if r != patternRunes[p] {
+			continue
+		}
+		score++
+		if i == previousMatch+1 {
+			score += 4
+		}
+		if i == 0 || !unicode.IsLetter(textRunes[i-1]) && !unicode.IsDigit(textRunes[i-1]) {
+			score += 3
+		}
+		previousMatch = i
+		p++
+	}
+	if p < len(patternRunes) {
+		return 0, false
+	}
+	return score, true
+}
+
+func (m *snippetPickerModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Error))
+
+	var body []string
+	var help string
+	switch m.mode {
+	case snippetModeForm:
+		title := "New snippet"
+		if m.editIndex >= 0 {
+			title = "Edit snippet"
+		}
+		body = append(body, titleStyle.Render(title), "")
+		body = append(body, m.formFields()...)
+		help = "Tab: next field • Space: toggle • Enter: save • Esc: back"
+
+	default:
+		body = append(body, titleStyle.Render("Run a snippet on "+m.hostName), "", m.search.View(), "")
+		body = append(body, m.listItems()...)
+		help = "Enter: run • ctrl+a: new • ctrl+e: edit • ctrl+d: delete • Esc: cancel"
+		if m.mode == snippetModeConfirmDelete {
+			snippet, _ := m.selected()
+			help = fmt.Sprintf("Delete snippet %q? (y/N)", snippet.Name)
+		}
+	}
+
+	if m.err != "" {
+		body = append(body, "", errorStyle.Render(m.err))
+	}
+	body = append(body, "", helpStyle.Render(help))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+	)
+}
+
+// listItems renders the matching snippets around the selection
+func (m *snippetPickerModel) listItems() []string {
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	if len(m.snippets) == 0 {
+		return []string{mutedStyle.Render("No snippets yet, press ctrl+a to add one")}
+	}
+	if len(m.matches) == 0 {
+		return []string{mutedStyle.Render("No matching snippets")}
+	}
+
+	// Leave room for the border, title, search and help lines
+	visible := max(m.height-14, 3)
+	start := 0
+	if m.selectedIndex >= visible {
+		start = m.selectedIndex - visible + 1
+	}
+	end := min(start+visible, len(m.matches))
+	commandWidth := max(m.width/2, 20)
+
+	var items []string
+	for i := start; i < end; i++ {
+		snippet := m.snippets[m.matches[i]]
+		var flags []string
+		if snippet.TTY {
+			flags = append(flags, "tty")
+		}
+		if snippet.Pause {
+			flags = append(flags, "pause")
+		}
+		label := snippet.Name
+		if len(flags) > 0 {
+			label += " [" + strings.Join(flags, ", ") + "]"
+		}
+		command := ansi.Truncate(snippet.Command, commandWidth, "…")
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground)).Padding(0, 2)
+		if i == m.selectedIndex {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 2)
+		}
+		items = append(items, style.Render(label), mutedStyle.Render("    "+command))
+	}
+	return items
+}
+
+// formFields renders the inputs of the snippet form
+func (m *snippetPickerModel) formFields() []string {
+	checkbox := func(label string, checked bool, field int) string {
+		box := "[ ]"
+		if checked {
+			box = "[x]"
+		}
+		style := m.styles.Label
+		if m.focus == field {
+			style = m.styles.FocusedLabel
+		}
+		return style.Render(box + " " + label)
+	}
+	label := func(text string, field int) string {
+		if m.focus == field {
+			return m.styles.FocusedLabel.Render(text)
+		}
+		return m.styles.Label.Render(text)
+	}
+
+	return []string{
+		label("Name", snippetFieldName),
+		m.nameInput.View(),
+		"",
+		label("Command", snippetFieldCommand),
+		m.commandInput.View(),
+		"",
+		checkbox("Needs a terminal (ssh -t)", m.tty, snippetFieldTTY),
+		checkbox("Pause before returning to sshc", m.pause, snippetFieldPause),
+	}
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("tng", "tail nginx logs"); !ok {
+		t.Error("Expected tng to match tail nginx logs")
+	}
+	if _, ok := fuzzyScore("ngt", "tail nginx logs"); ok {
+		t.Error("Expected letters out of order not to match")
+	}
+	if _, ok := fuzzyScore("DISK", "check disk"); !ok {
+		t.Error("Expected matching to ignore case")
+	}
+
+	consecutive, _ := fuzzyScore("disk", "check disk")
+	scattered, _ := fuzzyScore("disk", "docker inspect sk")
+	if consecutive <= scattered {
+		t.Errorf("Expected a consecutive match to score higher, got %d and %d", consecutive, scattered)
+	}
+}
+
+func TestSnippetPickerSearchAndRun(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+
+	if err := config.SaveSnippets([]config.Snippet{
+		{Name: "check disk", Command: "df -h /"},
+		{Name: "tail nginx logs", Command: "tail -f /var/log/nginx/access.log", TTY: true},
+	}); err != nil {
+		t.Fatalf("SaveSnippets() error = %v", err)
+	}
+
+	m, err := NewSnippetPicker("web", NewStyles(120), 120, 40)
+	if err != nil {
+		t.Fatalf("NewSnippetPicker() error = %v", err)
+	}
+	if len(m.matches) != 2 {
+		t.Fatalf("Expected both snippets listed, got %v", m.matches)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("nginx")})
+	if len(m.matches) != 1 {
+		t.Fatalf("Expected the search to leave one snippet, got %v", m.matches)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected enter to run the snippet")
+	}
+	run, ok := cmd().(snippetRunMsg)
+	if !ok || run.hostName != "web" || run.snippet.Name != "tail nginx logs" || !run.snippet.TTY {
+		t.Errorf("Unexpected run message %+v", run)
+	}
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/xvertile/sshc/internal/config"
 )
@@ -74,7 +75,10 @@ func sortHostsByName(hosts []config.SSHHost) []config.SSHHost {
 
 // filterHosts filters hosts according to the search query (name or tags)
 func (m Model) filterHosts(query string) []config.SSHHost {
-	subqueries := strings.Split(query, " ")
+	subqueries := searchWords(query)
+	if len(subqueries) == 0 {
+		subqueries = []string{""}
+	}
 	subqueriesLength := len(subqueries)
 	subfilteredHosts := make([][]config.SSHHost, subqueriesLength)
 	for i, subquery := range subqueries {
@@ -107,14 +111,13 @@ func (m Model) filterEntries(query string) []HostEntry {
 		return m.allEntries
 	}
 
-	query = strings.ToLower(query)
-	words := strings.Fields(query)
+	words := searchWords(strings.ToLower(query))
 
 	var filtered []HostEntry
 	for _, entry := range m.allEntries {
 		matchesAll := true
 		for _, word := range words {
-			if !entryMatchesWord(entry, word) {
+			if !entryMatchesWord(entry, word, m.deepSearch) {
 				matchesAll = false
 				break
 			}
@@ -127,8 +130,21 @@ func (m Model) filterEntries(query string) []HostEntry {
 	return filtered
 }
 
-// entryMatchesWord checks if a HostEntry matches a single search word
-func entryMatchesWord(entry HostEntry, word string) bool {
+// entryMatchesWord checks if a HostEntry matches a single search word. A deep
+// search also looks in the settings the list has no column for.
+func entryMatchesWord(entry HostEntry, word string, deep bool) bool {
+	// Check filter terms like "expired:true" or "tag:prod"
+	if matched, ok := matchFilterTerm(entry, word); ok {
+		return matched
+	}
+	if entryMatchesVisible(entry, word) {
+		return true
+	}
+	return deep && matchHiddenField(entry.SSHHost, word, "") != ""
+}
+
+// entryMatchesVisible checks if a word is in one of the fields shown in the list
+func entryMatchesVisible(entry HostEntry, word string) bool {
 	// Check name
 	if strings.Contains(strings.ToLower(entry.Name), word) {
 		return true
@@ -137,6 +153,10 @@ func entryMatchesWord(entry HostEntry, word string) bool {
 	if strings.Contains(strings.ToLower(entry.Hostname), word) {
 		return true
 	}
+	// Check description
+	if entry.SSHHost != nil && strings.Contains(strings.ToLower(entry.SSHHost.Description), word) {
+		return true
+	}
 	// Check user (from underlying SSH host if available)
 	if entry.SSHHost != nil && strings.Contains(strings.ToLower(entry.SSHHost.User), word) {
 		return true
@@ -150,6 +170,44 @@ func entryMatchesWord(entry HostEntry, word string) bool {
 	return false
 }
 
+// matchFilterTerm evaluates a field-scoped term like "tag:prod" or "expired:true" against an entry.
+// ok is false when the word is not a recognized filter term.
+func matchFilterTerm(entry HostEntry, word string) (matched, ok bool) {
+	key, value, found := strings.Cut(word, ":")
+	if !found || value == "" {
+		return false, false
+	}
+
+	switch key {
+	case "tag":
+		for _, tag := range entry.Tags {
+			if strings.EqualFold(tag, value) {
+				return true, true
+			}
+		}
+		return false, true
+	case "name":
+		return strings.Contains(strings.ToLower(entry.Name), value), true
+	case "host":
+		return strings.Contains(strings.ToLower(entry.Hostname), value), true
+	case "user":
+		return entry.SSHHost != nil && strings.Contains(strings.ToLower(entry.SSHHost.User), value), true
+	case "opt":
+		// Searches every hidden field, not only the extra options
+		return matchHiddenField(entry.SSHHost, value, "") != "", true
+	case "identity", "proxy", "command":
+		return matchHiddenField(entry.SSHHost, value, key) != "", true
+	case "expired":
+		if value != "true" && value != "false" {
+			return false, false
+		}
+		expired := entry.SSHHost != nil && entry.SSHHost.IsExpired(time.Now())
+		return expired == (value == "true"), true
+	}
+
+	return false, false
+}
+
 // filterHostsByWord filters hosts according to a single word
 func (m Model) filterHostsByWord(word string) []config.SSHHost {
 	var filtered []config.SSHHost
@@ -160,30 +218,9 @@ func (m Model) filterHostsByWord(word string) []config.SSHHost {
 		word = strings.ToLower(word)
 
 		for _, host := range m.hosts {
-			// Check the hostname
-			if strings.Contains(strings.ToLower(host.Name), word) {
-				filtered = append(filtered, host)
-				continue
-			}
-
-			// Check the hostname
-			if strings.Contains(strings.ToLower(host.Hostname), word) {
+			entry := HostEntry{Name: host.Name, SSHHost: &host, Tags: host.Tags, Hostname: host.Hostname}
+			if entryMatchesWord(entry, word, m.deepSearch) {
 				filtered = append(filtered, host)
-				continue
-			}
-
-			// Check the user
-			if strings.Contains(strings.ToLower(host.User), word) {
-				filtered = append(filtered, host)
-				continue
-			}
-
-			// Check the tags
-			for _, tag := range host.Tags {
-				if strings.Contains(strings.ToLower(tag), word) {
-					filtered = append(filtered, host)
-					break
-				}
 			}
 		}
 	}
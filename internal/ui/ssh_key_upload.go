@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/shellquote"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -320,45 +321,57 @@ func (m *sshKeyUploadModel) updateSSHConfig() error {
 	return nil
 }
 
-// uploadKey uploads a key file using ssh-copy-id (interactive)
-func (m *sshKeyUploadModel) uploadKey(keyPath string) tea.Cmd {
+// copyIDCommand builds the ssh-copy-id command installing the key at keyPath on hostName
+func copyIDCommand(configFile, keyPath, hostName string) *exec.Cmd {
 	var args []string
 
 	// Add config file if specified
-	if m.configFile != "" {
-		args = append(args, "-F", m.configFile)
+	if configFile != "" {
+		args = append(args, "-F", configFile)
 	}
 
-	args = append(args, "-i", keyPath, m.hostName)
+	args = append(args, "-i", keyPath, hostName)
+
+	return exec.Command("ssh-copy-id", args...)
+}
 
-	cmd := exec.Command("ssh-copy-id", args...)
-	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+// uploadKey uploads a key file using ssh-copy-id (interactive)
+func (m *sshKeyUploadModel) uploadKey(keyPath string) tea.Cmd {
+	cmd := copyIDCommand(m.configFile, keyPath, m.hostName)
+	return execProcess(cmd, func(err error) tea.Msg {
 		return sshKeyUploadSubmitMsg{err: err, keyPath: keyPath}
 	})
 }
 
-// uploadPastedKey uploads a pasted key using SSH directly (since ssh-copy-id -i requires a private key)
-func (m *sshKeyUploadModel) uploadPastedKey(key string) tea.Cmd {
-	// Build SSH command to append key to authorized_keys
-	// This is the manual equivalent of what ssh-copy-id does
+// authorizeKeyCommand builds the remote command that creates ~/.ssh, appends key
+// to authorized_keys and sets their permissions. The key is quoted for the remote
+// shell, as its comment can hold anything.
+func authorizeKeyCommand(key string) string {
+	return "mkdir -p ~/.ssh && chmod 700 ~/.ssh && printf '%s\\n' " + shellquote.Quote(key) +
+		" >> ~/.ssh/authorized_keys && chmod 600 ~/.ssh/authorized_keys"
+}
+
+// authorizeKeySSHCommand builds the ssh command appending key to the authorized_keys
+// of hostName, the manual equivalent of what ssh-copy-id does
+func authorizeKeySSHCommand(configFile, hostName, key string) *exec.Cmd {
 	var sshArgs []string
 
 	// Add config file if specified
-	if m.configFile != "" {
-		sshArgs = append(sshArgs, "-F", m.configFile)
+	if configFile != "" {
+		sshArgs = append(sshArgs, "-F", configFile)
 	}
 
-	sshArgs = append(sshArgs, m.hostName)
+	sshArgs = append(sshArgs, hostName)
 
-	// Command to run on remote: create .ssh dir, append key, set permissions
-	remoteCmd := fmt.Sprintf(
-		`mkdir -p ~/.ssh && chmod 700 ~/.ssh && echo %q >> ~/.ssh/authorized_keys && chmod 600 ~/.ssh/authorized_keys`,
-		key,
-	)
-	sshArgs = append(sshArgs, remoteCmd)
+	sshArgs = append(sshArgs, authorizeKeyCommand(key))
 
-	cmd := exec.Command("ssh", sshArgs...)
-	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+	return exec.Command("ssh", sshArgs...)
+}
+
+// uploadPastedKey uploads a pasted key using SSH directly (since ssh-copy-id -i requires a private key)
+func (m *sshKeyUploadModel) uploadPastedKey(key string) tea.Cmd {
+	cmd := authorizeKeySSHCommand(m.configFile, m.hostName, key)
+	return execProcess(cmd, func(err error) tea.Msg {
 		// For pasted keys, we don't offer config update since there's no local key file
 		return sshKeyUploadSubmitMsg{err: err, keyPath: ""}
 	})
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthorizeKeyCommandAppendsKeyLiterally(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	home := t.TempDir()
+	// The comment of a pasted key is free text
+	key := `ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHb0 it's me $(touch pwned) "laptop" \n`
+
+	for range 2 {
+		cmd := exec.Command("sh", "-c", authorizeKeyCommand(key))
+		cmd.Dir = home
+		cmd.Env = append(os.Environ(), "HOME="+home)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("command failed: %v\n%s", err, out)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".ssh", "authorized_keys"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := key + "\n" + key + "\n"; string(data) != want {
+		t.Errorf("authorized_keys = %q, want %q", data, want)
+	}
+	if _, err := os.Stat(filepath.Join(home, "pwned")); err == nil {
+		t.Error("The key comment ran a command")
+	}
+	if info, err := os.Stat(filepath.Join(home, ".ssh")); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0700 {
+		t.Errorf("~/.ssh mode = %v, want 0700", info.Mode().Perm())
+	}
+}
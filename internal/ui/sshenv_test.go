@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/sshver"
+)
+
+func TestConnectCommandPassesHostEnv(t *testing.T) {
+	m := createLargeTestModel(0)
+	m.configFile = filepath.Join(t.TempDir(), "config")
+	m.hosts = []config.SSHHost{{Name: "router", Hostname: "10.0.0.254"}}
+	m.appConfig = &config.AppConfig{HostEnv: map[string]map[string]string{"router": {"TERM": "vt100", "LANG": "C"}}}
+	m.sshVersion, _ = sshver.Parse("OpenSSH_9.6p1")
+	m.sshVersionKnown = true
+
+	cmd := m.connectCommand("router", m.sshDestination("router"))
+	if want := []string{"ssh", "-F", m.configFile, "-o", "SetEnv=LANG=C", "router"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+	if !slices.Contains(cmd.Env, "TERM=vt100") || slices.Contains(cmd.Env, "LANG=C") {
+		t.Errorf("env does not set TERM=vt100 alone: %q", cmd.Env)
+	}
+
+	// Hosts without overrides inherit the environment as it is
+	if cmd := m.connectCommand("web", []string{"web"}); cmd.Env != nil || len(cmd.Args) != 4 {
+		t.Errorf("args = %q, env = %q, want them untouched", cmd.Args, cmd.Env)
+	}
+}
+
+func TestConnectCommandKeepsConfiguredSetEnv(t *testing.T) {
+	m := createLargeTestModel(0)
+	m.configFile = filepath.Join(t.TempDir(), "config")
+	fixture := "Host router\n    HostName 10.0.0.254\n    SetEnv EDITOR=vi LANG=en_US.UTF-8\n"
+	if err := os.WriteFile(m.configFile, []byte(fixture), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m.appConfig = &config.AppConfig{HostEnv: map[string]map[string]string{"router": {"LANG": "C"}}}
+	m.sshVersion, _ = sshver.Parse("OpenSSH_9.6p1")
+	m.sshVersionKnown = true
+
+	// The SetEnv on the command line replaces the configured one, so it carries its variables
+	cmd := m.connectCommand("router", []string{"router"})
+	if want := []string{"ssh", "-F", m.configFile, "-o", "SetEnv=EDITOR=vi LANG=C", "router"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+}
@@ -10,11 +10,13 @@ type Theme struct {
 	Accent      string
 	Error       string
 	Success     string
+	Warning     string
 	Background  string
 	Foreground  string
 	SelectionBg string
 	SelectionFg string
 	Muted       string
+	Light       bool // Made for light terminal backgrounds
 }
 
 // Available themes
@@ -26,6 +28,7 @@ var Themes = []Theme{
 		Accent:      "#06B6D4", // Cyan
 		Error:       "#EF4444", // Red
 		Success:     "#22C55E", // Green
+		Warning:     "#F59E0B", // Amber
 		Background:  "#0F172A", // Dark Slate
 		Foreground:  "#F1F5F9", // Slate White
 		SelectionBg: "#1E293B", // Lighter Slate
@@ -39,6 +42,7 @@ var Themes = []Theme{
 		Accent:      "#FF79C6", // Pink
 		Error:       "#FF5555", // Red
 		Success:     "#50FA7B", // Green
+		Warning:     "#FFB86C", // Orange
 		Background:  "#282A36",
 		Foreground:  "#F8F8F2",
 		SelectionBg: "#44475A",
@@ -52,6 +56,7 @@ var Themes = []Theme{
 		Accent:      "#81A1C1", // Blue
 		Error:       "#BF616A", // Red
 		Success:     "#A3BE8C", // Green
+		Warning:     "#EBCB8B", // Yellow
 		Background:  "#2E3440", // Dark Grey
 		Foreground:  "#D8DEE9", // White-ish
 		SelectionBg: "#3B4252", // Lighter Grey
@@ -65,6 +70,7 @@ var Themes = []Theme{
 		Accent:      "#66D9EF", // Light Blue
 		Error:       "#F92672",
 		Success:     "#A6E22E", // Green
+		Warning:     "#E6DB74", // Yellow
 		Background:  "#272822",
 		Foreground:  "#F8F8F2",
 		SelectionBg: "#3E3D32",
@@ -78,6 +84,7 @@ var Themes = []Theme{
 		Accent:      "#2AA198", // Cyan
 		Error:       "#DC322F", // Red
 		Success:     "#859900", // Green
+		Warning:     "#B58900", // Yellow
 		Background:  "#002B36",
 		Foreground:  "#839496",
 		SelectionBg: "#073642",
@@ -91,6 +98,7 @@ var Themes = []Theme{
 		Accent:      "#FABD2F", // Yellow
 		Error:       "#FB4934", // Red
 		Success:     "#B8BB26", // Green
+		Warning:     "#FABD2F", // Yellow
 		Background:  "#282828",
 		Foreground:  "#EBDBB2",
 		SelectionBg: "#3C3836",
@@ -104,6 +112,7 @@ var Themes = []Theme{
 		Accent:      "#BB9AF7", // Purple
 		Error:       "#F7768E",
 		Success:     "#9ECE6A",
+		Warning:     "#E0AF68", // Yellow
 		Background:  "#1A1B26",
 		Foreground:  "#C0CAF5",
 		SelectionBg: "#292E42",
@@ -117,6 +126,7 @@ var Themes = []Theme{
 		Accent:      "#F5C2E7", // Pink
 		Error:       "#F38BA8", // Red
 		Success:     "#A6E3A1", // Green
+		Warning:     "#F9E2AF", // Yellow
 		Background:  "#1E1E2E", // Base
 		Foreground:  "#CDD6F4", // Text
 		SelectionBg: "#313244", // Surface
@@ -130,6 +140,7 @@ var Themes = []Theme{
 		Accent:      "#C678DD", // Purple
 		Error:       "#E06C75", // Red
 		Success:     "#98C379", // Green
+		Warning:     "#E5C07B", // Yellow
 		Background:  "#282C34",
 		Foreground:  "#ABB2BF",
 		SelectionBg: "#3E4451",
@@ -143,6 +154,7 @@ var Themes = []Theme{
 		Accent:      "#F9F871", // Neon Yellow
 		Error:       "#FF2A6D", // Red
 		Success:     "#00FF9C", // Green
+		Warning:     "#FCEE0A", // Yellow
 		Background:  "#050505", // Almost Black
 		Foreground:  "#F0F0F0",
 		SelectionBg: "#212121",
@@ -156,12 +168,79 @@ var Themes = []Theme{
 		Accent:      "#0D9488", // Teal 600
 		Error:       "#FDA4AF", // Rose
 		Success:     "#6EE7B7", // Emerald
+		Warning:     "#FCD34D", // Amber
 		Background:  "#132F35", // Deep Teal Dark
 		Foreground:  "#F0FDFA", // Azure White
 		SelectionBg: "#115E59", // Deep Teal
 		SelectionFg: "#CCFBF1", // Light Teal
 		Muted:       "#6B9CA6",
 	},
+	{
+		Name:        "Default Light",
+		Primary:     "#1D4ED8", // Blue 700
+		Secondary:   "#475569", // Slate 600
+		Accent:      "#0E7490", // Cyan 700
+		Error:       "#B91C1C", // Red 700
+		Success:     "#15803D", // Green 700
+		Warning:     "#B45309", // Amber 700
+		Background:  "#F8FAFC", // Slate 50
+		Foreground:  "#0F172A", // Slate 900
+		SelectionBg: "#DBEAFE", // Blue 100
+		SelectionFg: "#1E3A8A", // Blue 900
+		Muted:       "#475569",
+		Light:       true,
+	},
+	{
+		Name:        "Solarized Light",
+		Primary:     "#268BD2", // Blue
+		Secondary:   "#93A1A1",
+		Accent:      "#2AA198", // Cyan
+		Error:       "#DC322F", // Red
+		Success:     "#859900", // Green
+		Warning:     "#B58900", // Yellow
+		Background:  "#FDF6E3",
+		Foreground:  "#657B83",
+		SelectionBg: "#EEE8D5",
+		SelectionFg: "#073642",
+		Muted:       "#586E75",
+		Light:       true,
+	},
+	{
+		Name:        "Catppuccin Latte",
+		Primary:     "#8839EF", // Mauve
+		Secondary:   "#7C7F93", // Overlay
+		Accent:      "#EA76CB", // Pink
+		Error:       "#D20F39", // Red
+		Success:     "#40A02B", // Green
+		Warning:     "#DF8E1D", // Yellow
+		Background:  "#EFF1F5", // Base
+		Foreground:  "#4C4F69", // Text
+		SelectionBg: "#CCD0DA", // Surface
+		SelectionFg: "#8839EF",
+		Muted:       "#6C6F85",
+		Light:       true,
+	},
+}
+
+// themeFamilies pairs the dark themes that have a light variant with it
+var themeFamilies = map[string]string{
+	"Default":          "Default Light",
+	"Solarized Dark":   "Solarized Light",
+	"Catppuccin Mocha": "Catppuccin Latte",
+}
+
+// themeVariant returns the light or dark theme of the family name belongs to,
+// or name itself when it has no variant of that kind
+func themeVariant(name string, light bool) string {
+	for dark, lightName := range themeFamilies {
+		if name == dark || name == lightName {
+			if light {
+				return lightName
+			}
+			return dark
+		}
+	}
+	return name
 }
 
 // Current theme colors (set by SetTheme)
@@ -203,6 +282,27 @@ func GetCurrentTheme() Theme {
 	return Themes[CurrentThemeIndex]
 }
 
+// ColorLabels are the color slots a host can be labeled with. Hosts store the slot
+// rather than a color, so labels follow theme changes.
+var ColorLabels = []string{"accent-1", "accent-2", "accent-3", "accent-4", "accent-5"}
+
+// LabelColor returns the theme color of a label slot, or "" for unknown slots
+func (t Theme) LabelColor(slot string) string {
+	switch slot {
+	case "accent-1":
+		return t.Primary
+	case "accent-2":
+		return t.Accent
+	case "accent-3":
+		return t.Success
+	case "accent-4":
+		return t.Warning
+	case "accent-5":
+		return t.Error
+	}
+	return ""
+}
+
 // Styles struct centralizes all lipgloss styles
 type Styles struct {
 	// Layout
@@ -1,20 +1,38 @@
 package ui
 
 import (
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/hooks"
+	"github.com/xvertile/sshc/internal/metrics"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // calculateDynamicColumnWidths calculates optimal column widths based on terminal width
 // and content length, ensuring all content fits when possible
 func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int, int, int) {
+	showTags := m.columnVisible(config.ColumnTags)
+	showLastLogin := m.columnVisible(config.ColumnLastLogin)
+
 	if m.width <= 0 {
 		// Fallback to static widths if terminal width is not available
-		return calculateNameColumnWidth(hosts), 25, calculateTagsColumnWidth(hosts), calculateLastLoginColumnWidth(hosts, m.historyManager)
+		tagsWidth, lastLoginWidth := 0, 0
+		if showTags {
+			tagsWidth = calculateTagsColumnWidth(hosts)
+		}
+		if showLastLogin {
+			lastLoginWidth = calculateLastLoginColumnWidth(hosts, m.historyManager)
+		}
+		return calculateNameColumnWidth(hosts), 25, tagsWidth, lastLoginWidth
 	}
 
 	// Calculate content lengths
@@ -22,10 +40,17 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 	maxHostnameLength := 8   // Minimum for "Hostname" header
 	maxTagsLength := 8       // Minimum for "Tags" header
 	maxLastLoginLength := 12 // Minimum for "Last Login" header
+	if m.absoluteTimes {
+		maxLastLoginLength = len(absoluteTimeLayout)
+	}
 
 	for _, host := range hosts {
 		// Name column includes status indicator (2 chars) + space (1 char) + name
 		nameLength := 3 + len(host.Name)
+		if m.collapseBlocks && len(host.BlockNames) > 1 {
+			// Members are indented, and the block's row is named after two of them
+			nameLength = max(nameLength+len(blockMemberIndent), 3+ansi.StringWidth(blockLabel(host.BlockNames)))
+		}
 		if nameLength > maxNameLength {
 			maxNameLength = nameLength
 		}
@@ -34,35 +59,28 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 			maxHostnameLength = len(host.Hostname)
 		}
 
-		// Calculate tags string length
-		var tagsStr string
-		if len(host.Tags) > 0 {
-			var formattedTags []string
-			for _, tag := range host.Tags {
-				formattedTags = append(formattedTags, "#"+tag)
-			}
-			tagsStr = strings.Join(formattedTags, " ")
-		}
-		if len(tagsStr) > maxTagsLength {
-			maxTagsLength = len(tagsStr)
+		cells := m.cachedCells(host.Name, host.Tags)
+		if len(cells.tagsStr) > maxTagsLength {
+			maxTagsLength = len(cells.tagsStr)
 		}
 
 		// Calculate last login length
-		if m.historyManager != nil {
-			if lastConnect, exists := m.historyManager.GetLastConnectionTime(host.Name); exists {
-				timeStr := formatTimeAgo(lastConnect)
-				if len(timeStr) > maxLastLoginLength {
-					maxLastLoginLength = len(timeStr)
-				}
-			}
+		if timeStr := m.lastLoginCell(cells); len(timeStr) > maxLastLoginLength {
+			maxLastLoginLength = len(timeStr)
 		}
 	}
 
-	// Add padding to each column
+	// Add padding to each column; hidden columns take no width at all
 	maxNameLength += 2
 	maxHostnameLength += 2
 	maxTagsLength += 2
 	maxLastLoginLength += 2
+	if !showTags {
+		maxTagsLength = 0
+	}
+	if !showLastLogin {
+		maxLastLoginLength = 0
+	}
 
 	// Calculate available width (minus borders and separators)
 	// Table has borders (2 chars) + column separators (3 chars between 4 columns)
@@ -82,7 +100,17 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 	minNameWidth := 15 // Enough for status + short name
 	minHostnameWidth := 15
 	minLastLoginWidth := 12
+	if m.absoluteTimes {
+		// Absolute timestamps have a fixed length and must never be truncated
+		minLastLoginWidth = len(absoluteTimeLayout) + 2
+	}
 	minTagsWidth := 10
+	if !showTags {
+		minTagsWidth = 0
+	}
+	if !showLastLogin {
+		minLastLoginWidth = 0
+	}
 
 	remainingWidth := availableWidth
 
@@ -110,6 +138,11 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 			hostnameExtra := (hostnameWant * remainingWidth) / totalWant
 			lastLoginExtra := (lastLoginWant * remainingWidth) / totalWant
 			tagsExtra := remainingWidth - nameExtra - hostnameExtra - lastLoginExtra
+			if !showTags {
+				// The rounding remainder goes to the hostname instead
+				hostnameExtra += tagsExtra
+				tagsExtra = 0
+			}
 
 			nameWidth += nameExtra
 			hostnameWidth += hostnameExtra
@@ -121,86 +154,326 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 	return nameWidth, hostnameWidth, tagsWidth, lastLoginWidth
 }
 
-// updateTableRows updates the table with filtered hosts (SSH and K8s)
-func (m *Model) updateTableRows() {
-	var rows []table.Row
+// expiredIndicator replaces the ping status of hosts past their expiry date
+const expiredIndicator = "✝"
 
-	// Use unified entries if available, otherwise fall back to SSH hosts
-	if len(m.filteredEntries) > 0 {
-		for _, entry := range m.filteredEntries {
-			// Get status indicator
-			var statusIndicator string
-			if entry.IsK8s {
-				statusIndicator = "k" // Kubernetes indicator
-			} else {
-				statusIndicator = m.getPingStatusIndicator(entry.Name)
-			}
+// readOnlyIndicator follows the names of hosts from files marked read-only
+const readOnlyIndicator = "🔒"
 
-			// Format tags for display
-			var tagsStr string
-			if len(entry.Tags) > 0 {
-				var formattedTags []string
-				for _, tag := range entry.Tags {
-					formattedTags = append(formattedTags, "#"+tag)
-				}
-				tagsStr = strings.Join(formattedTags, " ")
-			}
+// mountedIndicator follows the names of hosts mounted with sshfs
+const mountedIndicator = "📂"
 
-			// Format last login information
-			var lastLoginStr string
-			if m.historyManager != nil {
-				if lastConnect, exists := m.historyManager.GetLastConnectionTime(entry.Name); exists {
-					lastLoginStr = formatTimeAgo(lastConnect)
-				}
-			}
+// expiryWarningWindow is how far ahead hosts are highlighted before they expire
+const expiryWarningWindow = 14 * 24 * time.Hour
+
+// Bounds of the Description column, which is only shown when the other columns leave room for it
+const (
+	descriptionColumnMinWidth = 20
+	descriptionColumnMaxWidth = 50
+)
+
+// minRowWindow is the smallest number of rows materialized into the table at once
+const minRowWindow = 60
+
+// rowCacheEntry holds the formatted cell values of a host row
+type rowCacheEntry struct {
+	tags       []string
+	tagsStr    string // Tags, followed by the host's annotation
+	annotation hooks.Annotation
+	lastLogin  time.Time
+	hasLogin   bool
+
+	// Last Login cell as last formatted, and until when it reads the same
+	loginStr      string
+	loginAbsolute bool
+	loginStaleAt  time.Time
+}
+
+// cachedCells returns the formatted cell values for a host, computing them on first use.
+// The entry is recomputed when the host's tags change or after invalidateRow.
+func (m *Model) cachedCells(name string, tags []string) *rowCacheEntry {
+	if m.rowCache == nil {
+		m.rowCache = make(map[string]*rowCacheEntry)
+	}
+	if cached, ok := m.rowCache[name]; ok && slices.Equal(cached.tags, tags) {
+		return cached
+	}
 
-			rows = append(rows, table.Row{
-				statusIndicator + " " + entry.Name,
-				entry.Hostname,
-				tagsStr,
-				lastLoginStr,
-			})
+	cached := &rowCacheEntry{tags: tags}
+	if len(tags) > 0 {
+		formattedTags := make([]string, len(tags))
+		for i, tag := range tags {
+			formattedTags[i] = "#" + tag
 		}
+		cached.tagsStr = strings.Join(formattedTags, " ")
+	}
+	if annotation, ok := m.annotations[name]; ok {
+		cached.annotation = annotation
+		cached.tagsStr = joinAnnotation(cached.tagsStr, annotation.Text)
+	}
+	if m.historyManager != nil {
+		cached.lastLogin, cached.hasLogin = m.historyManager.GetLastConnectionTime(name)
+	}
+
+	m.rowCache[name] = cached
+	return cached
+}
+
+// lastLoginCell returns the Last Login cell of a host. The text is kept until the
+// time-ago wording would change or the time format is switched.
+func (m *Model) lastLoginCell(cells *rowCacheEntry) string {
+	if !cells.hasLogin {
+		return ""
+	}
+	now := time.Now()
+	if cells.loginStr != "" && cells.loginAbsolute == m.absoluteTimes && (m.absoluteTimes || now.Before(cells.loginStaleAt)) {
+		return cells.loginStr
+	}
+	cells.loginAbsolute = m.absoluteTimes
+	if m.absoluteTimes {
+		cells.loginStr = formatAbsoluteTime(cells.lastLogin, time.Local)
 	} else {
-		// Fallback to SSH hosts only
-		hostsToShow := m.filteredHosts
-		if hostsToShow == nil {
-			hostsToShow = m.hosts
+		cells.loginStr = formatTimeSince(cells.lastLogin, now)
+		cells.loginStaleAt = timeSinceChangesAt(cells.lastLogin, now)
+	}
+	return cells.loginStr
+}
+
+// invalidateRow drops the cached cell values of a host
+func (m *Model) invalidateRow(name string) {
+	delete(m.rowCache, name)
+}
+
+// displayEntries returns the entries currently listed in the table
+func (m *Model) displayEntries() []HostEntry {
+	// Use unified entries if available, otherwise fall back to SSH hosts
+	if len(m.filteredEntries) > 0 {
+		if m.collapseBlocks {
+			return m.blockEntries(m.filteredEntries)
 		}
+		return m.filteredEntries
+	}
 
-		for _, host := range hostsToShow {
-			statusIndicator := m.getPingStatusIndicator(host.Name)
+	hostsToShow := m.filteredHosts
+	if hostsToShow == nil {
+		hostsToShow = m.hosts
+	}
 
-			var tagsStr string
-			if len(host.Tags) > 0 {
-				var formattedTags []string
-				for _, tag := range host.Tags {
-					formattedTags = append(formattedTags, "#"+tag)
-				}
-				tagsStr = strings.Join(formattedTags, " ")
-			}
+	entries := make([]HostEntry, len(hostsToShow))
+	for i := range hostsToShow {
+		host := &hostsToShow[i]
+		entries[i] = HostEntry{
+			Name:     host.Name,
+			SSHHost:  host,
+			Tags:     host.Tags,
+			Hostname: host.Hostname,
+		}
+	}
+	if m.collapseBlocks {
+		return m.blockEntries(entries)
+	}
+	return entries
+}
 
-			var lastLoginStr string
-			if m.historyManager != nil {
-				if lastConnect, exists := m.historyManager.GetLastConnectionTime(host.Name); exists {
-					lastLoginStr = formatTimeAgo(lastConnect)
-				}
-			}
+// buildRow formats a single table row
+func (m *Model) buildRow(entry HostEntry) table.Row {
+	// Get status indicator
+	var statusIndicator string
+	if entry.IsK8s {
+		statusIndicator = "k" // Kubernetes indicator
+	} else {
+		statusIndicator = m.getPingStatusIndicator(entry.Name)
+	}
+
+	cells := m.cachedCells(entry.Name, entry.Tags)
+
+	lastLoginStr := m.lastLoginCell(cells)
+
+	var description string
+	if entry.SSHHost != nil {
+		description = entry.SSHHost.Description
+	}
+
+	row := table.Row{
+		statusIndicator + " " + entry.Name,
+		entry.Hostname,
+		m.tagsCell(cells),
+		lastLoginStr,
+		description,
+	}
 
-			rows = append(rows, table.Row{
-				statusIndicator + " " + host.Name,
-				host.Hostname,
-				tagsStr,
-				lastLoginStr,
-			})
+	if entry.SSHHost == nil {
+		return row
+	}
+	name := entry.Name
+	if entry.SSHHost.ReadOnly {
+		name += " " + readOnlyIndicator
+		row[0] = statusIndicator + " " + name
+	}
+	if m.mounter != nil {
+		if _, mounted := m.mounter.Mounted(entry.Name); mounted {
+			name += " " + mountedIndicator
+			row[0] = statusIndicator + " " + name
 		}
 	}
+	if m.marked[entry.Name] {
+		name += " " + markedIndicator
+		row[0] = statusIndicator + " " + name
+	}
+	if badge := sessionBadge(m.openSessions[entry.Name]); badge != "" {
+		name += " " + badge
+		row[0] = statusIndicator + " " + name
+	}
+	if info, ok := m.osInfo(entry.Name); ok {
+		name += " " + osBadge(info.Family)
+		row[0] = statusIndicator + " " + name
+	}
+	_, maintained := m.inMaintenance(entry.Name)
+	if maintained {
+		name += " " + maintenanceIndicator
+		row[0] = statusIndicator + " " + name
+	}
+	if label := searchMatchLabel(entry, m.searchInput.Value(), m.deepSearch); label != "" {
+		name += " ~" + label
+		row[0] = statusIndicator + " " + name
+	}
 
-	m.table.SetRows(rows)
+	// Dim expired hosts and highlight the ones about to expire
+	theme := GetCurrentTheme()
+	now := time.Now()
+	switch {
+	case entry.SSHHost.IsExpired(now):
+		row[0] = expiredIndicator + " " + name
+		dimmed := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Faint(true)
+		for i := range row {
+			row[i] = m.styleCell(row[i], i, dimmed)
+		}
+	case maintained:
+		dimmed := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted)).Faint(true)
+		for i := range row {
+			row[i] = m.styleCell(row[i], i, dimmed)
+		}
+	case entry.SSHHost.ExpiresWithin(expiryWarningWindow, now):
+		warning := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Warning))
+		row[0] = m.styleCell(row[0], 0, warning)
+	default:
+		if color := theme.LabelColor(m.hostColor(entry.Name)); color != "" {
+			label := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+			row[0] = m.styleCell(row[0], 0, label)
+		}
+	}
+
+	return row
+}
 
-	// Update table height and columns based on current terminal size
+// hostColor returns the color label slot assigned to a host, or ""
+func (m *Model) hostColor(name string) string {
+	if m.appConfig == nil {
+		return ""
+	}
+	return m.appConfig.HostColors[name]
+}
+
+// styleCell applies a style to a cell value. The table truncates cells by rune
+// width and counts escape sequences as visible, so the value is left unstyled
+// when the column is too narrow to hold them.
+func (m *Model) styleCell(value string, column int, style lipgloss.Style) string {
+	styled := style.Render(value)
+	columns := m.table.Columns()
+	if column < len(columns) && len(styled) > columns[column].Width {
+		return value
+	}
+	return styled
+}
+
+// rowWindowSize returns how many rows are materialized around the cursor
+func (m *Model) rowWindowSize() int {
+	return max(minRowWindow, 3*m.table.Height())
+}
+
+// selectedIndex returns the cursor position within the full list of displayed entries
+func (m *Model) selectedIndex() int {
+	return m.rowOffset + m.table.Cursor()
+}
+
+// materializeRows builds the rows within the window surrounding the given cursor
+// position. Only this slice of the list is handed to the table.
+func (m *Model) materializeRows(cursor int) {
+	entries := m.displayEntries()
+	cursor = min(cursor, len(entries)-1)
+	cursor = max(cursor, 0)
+
+	window := m.rowWindowSize()
+	offset := 0
+	if len(entries) > window {
+		offset = min(max(cursor-window/2, 0), len(entries)-window)
+	}
+	end := min(offset+window, len(entries))
+
+	// Rows are built into whichever buffer the table is not holding, so they
+	// can be compared with the rows it shows
+	m.rowBufferIndex ^= 1
+	rows := m.rowBuffers[m.rowBufferIndex][:0]
+	for _, entry := range entries[offset:end] {
+		rows = append(rows, m.buildEntryRow(entry))
+	}
+	m.rowBuffers[m.rowBufferIndex] = rows
+
+	// The table renders its viewport on every setter, so unchanged rows are not
+	// handed over again and the cursor is only set when SetRows left it elsewhere
+	m.rowOffset = offset
+	if slices.EqualFunc(rows, m.table.Rows(), slices.Equal) {
+		m.rowBufferIndex ^= 1
+	} else {
+		m.table.SetRows(rows)
+	}
+	if local := min(max(cursor-offset, 0), len(rows)-1); local != m.table.Cursor() {
+		m.table.SetCursor(local)
+	}
+	if m.onRowsRebuilt != nil {
+		m.onRowsRebuilt()
+	}
+}
+
+// syncRowWindow shifts the materialized rows once the cursor nears the edge of the window
+func (m *Model) syncRowWindow(msg tea.KeyMsg) {
+	total := len(m.displayEntries())
+	rows := len(m.table.Rows())
+	if total <= rows {
+		return
+	}
+
+	cursor := m.selectedIndex()
+	switch {
+	case key.Matches(msg, m.table.KeyMap.GotoTop):
+		m.materializeRows(0)
+		return
+	case key.Matches(msg, m.table.KeyMap.GotoBottom):
+		m.materializeRows(total - 1)
+		return
+	}
+
+	margin := m.table.Height()
+	local := m.table.Cursor()
+	nearTop := local < margin && m.rowOffset > 0
+	nearBottom := local >= rows-margin && m.rowOffset+rows < total
+	if nearTop || nearBottom {
+		m.materializeRows(cursor)
+	}
+}
+
+// updateTableRows updates the table with filtered hosts (SSH and K8s)
+func (m *Model) updateTableRows() {
+	if metrics.Enabled() {
+		defer metrics.TableRebuild.Since(time.Now())
+	}
+
+	// Update table height and columns based on current terminal size first,
+	// since both the row window and cell styling depend on them
 	m.updateTableHeight()
 	m.updateTableColumns()
+
+	m.materializeRows(m.selectedIndex())
 }
 
 // updateTableHeight dynamically adjusts table height based on terminal size
@@ -217,8 +490,9 @@ func (m *Model) updateTableHeight() {
 	// - Help text: 1 line
 	// - App margins/spacing: 3 lines
 	// - Safety margin: 3 lines (to ensure UI elements are always visible)
+	// - Message drawer: while it is open
 	// Total reserved: 14 lines minimum to preserve essential UI elements
-	reservedHeight := 14
+	reservedHeight := 14 + m.drawerHeight()
 	availableHeight := m.height - reservedHeight
 
 	// Use total entry count (not filtered) to maintain consistent table size
@@ -252,8 +526,11 @@ func (m *Model) updateTableHeight() {
 	// This compensates for table rendering quirks in bubble tea
 	tableHeight += 1
 
-	// Update table height
-	m.table.SetHeight(tableHeight)
+	// Update table height, unless it is unchanged since setting it re-renders the table
+	if tableHeight != m.tableHeight {
+		m.table.SetHeight(tableHeight)
+		m.tableHeight = tableHeight
+	}
 }
 
 // updateTableColumns dynamically adjusts table column widths based on terminal size
@@ -290,20 +567,104 @@ func (m *Model) updateTableColumns() {
 		{Title: "Tags", Width: tagsWidth},
 		{Title: lastLoginTitle, Width: lastLoginWidth},
 	}
+	// Hidden with a zero width unless the terminal is wide enough
+	columns = append(columns, table.Column{Title: "Description", Width: m.descriptionColumnWidth(hostsToShow, columnsWidth(columns))})
+
+	if !slices.Equal(columns, m.table.Columns()) {
+		m.table.SetColumns(columns)
+	}
+}
+
+// descriptionColumnWidth returns the width of the Description column given the
+// width taken by the other columns and their padding, or 0 when it is hidden,
+// does not fit or no host has a description
+func (m *Model) descriptionColumnWidth(hosts []config.SSHHost, usedWidth int) int {
+	if !m.columnVisible(config.ColumnDescription) {
+		return 0
+	}
+	longest := 0
+	for _, host := range hosts {
+		longest = max(longest, len(host.Description))
+	}
+	if longest == 0 {
+		return 0
+	}
+
+	// The table's borders and the column's own padding
+	available := m.width - 2 - columnPadding - usedWidth
+	if available < descriptionColumnMinWidth {
+		return 0
+	}
+	return min(available, longest+2, descriptionColumnMaxWidth)
+}
+
+// compactLayout reports whether the terminal is too narrow for the table, in which
+// case hosts are listed one per line
+func (m *Model) compactLayout() bool {
+	threshold := m.appConfig.CompactListThreshold()
+	return m.width > 0 && m.width < threshold
+}
+
+// renderCompactList renders the materialized rows as "status name — hostname" lines
+// without borders or a header. The table keeps the rows and the cursor, so every
+// key works the same and switching layouts keeps the selection.
+func (m *Model) renderCompactList() string {
+	rows := m.table.Rows()
+	if len(rows) == 0 {
+		return "No hosts"
+	}
 
-	m.table.SetColumns(columns)
+	// Page through the rows so the window only moves when the cursor leaves it
+	height := max(m.table.Height()-1, 1)
+	cursor := m.table.Cursor()
+	top := (cursor / height) * height
+	width := max(m.width-2, 10)
+
+	theme := GetCurrentTheme()
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.SelectionFg)).
+		Background(lipgloss.Color(theme.SelectionBg)).
+		Bold(true)
+
+	var lines []string
+	for i := top; i < min(top+height, len(rows)); i++ {
+		line := rows[i][0]
+		if hostname := rows[i][1]; hostname != "" {
+			line += " — " + hostname
+		}
+		if i == cursor {
+			line = ansi.Truncate(ansi.Strip(line), width, "…")
+			line = selectedStyle.Render(line + strings.Repeat(" ", width-ansi.StringWidth(line)))
+		} else {
+			line = ansi.Truncate(line, width, "…")
+		}
+		lines = append(lines, line)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
 // getTableWidth returns the current total width of the table
 func (m *Model) getTableWidth() int {
-	columns := m.table.Columns()
-	totalWidth := 0
+	if m.compactLayout() {
+		return max(m.width-2, 10)
+	}
+	// Add the border on both sides
+	return columnsWidth(m.table.Columns()) + 2
+}
+
+// columnPadding is the space the table's cell style adds around every column
+const columnPadding = 2
+
+// columnsWidth returns the width columns take in the table, padding included.
+// Columns hidden with a zero width are not drawn and take none.
+func columnsWidth(columns []table.Column) int {
+	width := 0
 	for _, col := range columns {
-		totalWidth += col.Width
+		if col.Width > 0 {
+			width += col.Width + columnPadding
+		}
 	}
-	// Add border and separator widths (2 for borders + 3 for column separators)
-	totalWidth += 5
-	return totalWidth
+	return width
 }
 
 // max returns the maximum of two integers
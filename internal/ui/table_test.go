@@ -0,0 +1,447 @@
+package ui
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/xvertile/sshc/internal/config"
+	"github.com/xvertile/sshc/internal/connectivity"
+	"github.com/xvertile/sshc/internal/hooks"
+)
+
+// createLargeTestModel creates a model with the given number of SSH hosts
+func createLargeTestModel(count int) Model {
+	hosts := make([]config.SSHHost, count)
+	for i := range hosts {
+		hosts[i] = config.SSHHost{
+			Name:     fmt.Sprintf("node-%04d", i),
+			Hostname: fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+			User:     "deploy",
+			Tags:     []string{"cloud", fmt.Sprintf("zone-%d", i%8)},
+		}
+	}
+	return createTestModel(withHosts(hosts), withSize(120, 40))
+}
+
+// typeFilterKeystroke types a rune into the search input and applies the debounced filter
+func typeFilterKeystroke(m Model, r rune) Model {
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	m = newModel.(Model)
+	newModel, _ = m.Update(listSearchDebounceMsg{seq: m.searchSeq})
+	return newModel.(Model)
+}
+
+func TestRowsAreVirtualized(t *testing.T) {
+	m := createLargeTestModel(5000)
+
+	if rows := len(m.table.Rows()); rows >= len(m.allEntries) {
+		t.Errorf("Expected only a window of rows to be materialized, got %d of %d", rows, len(m.allEntries))
+	}
+
+	// Jumping to the bottom should move the window to the end of the list
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	m = newModel.(Model)
+	if entry := m.selectedEntry(); entry == nil || entry.Name != "node-4999" {
+		t.Errorf("Expected last host to be selected after End, got %+v", entry)
+	}
+}
+
+// TestFilterKeystrokeOnLargeList checks a keystroke on a large list rebuilds only a
+// window of rows, once, and allocates in proportion to the hosts. Counting the work
+// keeps the budget from depending on the machine; BenchmarkFilterKeystroke times it.
+func TestFilterKeystrokeOnLargeList(t *testing.T) {
+	const hosts = 5000
+	m := createLargeTestModel(hosts)
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = newModel.(Model)
+	rebuilds := 0
+	m.onRowsRebuilt = func() { rebuilds++ }
+	m = typeFilterKeystroke(m, '7')
+
+	if len(m.filteredEntries) == 0 || len(m.filteredEntries) == len(m.allEntries) {
+		t.Errorf("Expected the keystroke to filter the list, got %d entries", len(m.filteredEntries))
+	}
+	if rebuilds != 1 {
+		t.Errorf("Keystroke materialized the rows %d times, want once", rebuilds)
+	}
+	if rows := len(m.table.Rows()); rows > m.rowWindowSize() {
+		t.Errorf("Keystroke built %d rows, want at most the window of %d", rows, m.rowWindowSize())
+	}
+
+	// About one allocation per host; rendering every row again would take several
+	allocs := testing.AllocsPerRun(5, func() {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m = typeFilterKeystroke(newModel.(Model), '7')
+	})
+	if allocs > 3*hosts {
+		t.Errorf("Keystroke made %.0f allocations for %d hosts, want at most %d", allocs, hosts, 3*hosts)
+	}
+}
+
+func BenchmarkFilterKeystroke(b *testing.B) {
+	m := createLargeTestModel(5000)
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = newModel.(Model)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m = typeFilterKeystroke(m, '7')
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m = newModel.(Model)
+	}
+}
+
+func TestPingStormRefreshesInBatches(t *testing.T) {
+	m := createLargeTestModel(1000)
+	m.table.Focus()
+	for i := 0; i < 40; i++ {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = newModel.(Model)
+	}
+	selected := m.selectedIndex()
+
+	rebuilds := 0
+	m.onRowsRebuilt = func() { rebuilds++ }
+
+	// Results arrive much faster than the refresh interval; one tick fires per 100 of them
+	ticks := 0
+	for i := 0; i < 1000; i++ {
+		result := &connectivity.HostPingResult{HostName: fmt.Sprintf("node-%04d", i), Status: connectivity.StatusOnline}
+		newModel, cmd := m.Update(pingResultMsg(result))
+		m = newModel.(Model)
+		if cmd != nil {
+			ticks++
+		}
+		if i%100 == 99 {
+			newModel, _ = m.Update(pingRefreshMsg{})
+			m = newModel.(Model)
+		}
+	}
+
+	if ticks != 10 || rebuilds != 10 {
+		t.Errorf("Expected 10 refresh ticks and 10 row rebuilds for 1000 results, got %d and %d", ticks, rebuilds)
+	}
+	if got := m.selectedIndex(); got != selected {
+		t.Errorf("Expected the cursor to stay on row %d, got %d", selected, got)
+	}
+}
+
+func TestPingGroupSendsAResultPerHost(t *testing.T) {
+	// A port nothing listens on fails fast
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	hosts := []config.SSHHost{
+		{Name: "web", Hostname: "127.0.0.1", Port: port},
+		{Name: "web-alias", Hostname: "127.0.0.1", Port: port},
+	}
+	batch, ok := pingGroupCmd(connectivity.NewPingManager(time.Second), hosts)().(tea.BatchMsg)
+	if !ok || len(batch) != len(hosts) {
+		t.Fatalf("Expected a batch of %d results, got %#v", len(hosts), batch)
+	}
+	for i, cmd := range batch {
+		result, ok := cmd().(pingResultMsg)
+		if !ok || result.HostName != hosts[i].Name || result.Status != connectivity.StatusOffline {
+			t.Errorf("Result %d = %+v, want %s offline", i, result, hosts[i].Name)
+		}
+	}
+}
+
+func TestPingAllWithTagFilterLooksUpTheTag(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	content := "# Tags: prod\nHost web web-alias\n    HostName web.example.com\n\n# Tags: dev\nHost dev\n    HostName dev.example.com\n\n# Tags: Prod\nHost db\n    HostName db.example.com\n"
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := config.ParseSSHConfigFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := createTestModel()
+	m.hosts, m.filteredHosts, m.configFile = hosts, hosts, configFile
+
+	if got := hostNames(m.pingedHosts()); len(got) != 4 {
+		t.Errorf("Expected every host pinged without a tag filter, got %v", got)
+	}
+
+	// Each name of a block keeps its own status
+	m.toggleTagFilter("prod")
+	if got, want := hostNames(m.pingedHosts()), []string{"web", "web-alias", "db"}; !slices.Equal(got, want) {
+		t.Errorf("pingedHosts() = %v, want %v", got, want)
+	}
+}
+
+func TestDescriptionColumnOnWideTerminals(t *testing.T) {
+	m := createLargeTestModel(3)
+	m.hosts[1].Description = "primary LB, eu-west"
+	m.filteredHosts = m.hosts
+	m.rebuildEntries()
+
+	descriptionWidth := func(m Model) int {
+		columns := m.table.Columns()
+		return columns[len(columns)-1].Width
+	}
+
+	m.width = 160
+	m.updateTableRows()
+	if descriptionWidth(m) == 0 {
+		t.Fatal("Expected the Description column on a wide terminal")
+	}
+	if got := m.table.Rows()[1][4]; got != "primary LB, eu-west" {
+		t.Errorf("Expected description cell, got %q", got)
+	}
+	if m.getTableWidth() > m.width {
+		t.Errorf("Table width %d exceeds terminal width %d", m.getTableWidth(), m.width)
+	}
+
+	renderedWidth := func(m Model) int {
+		return lipgloss.Width(m.styles.TableFocused.Render(m.table.View()))
+	}
+	if got := renderedWidth(m); m.getTableWidth() != got {
+		t.Errorf("getTableWidth() = %d, rendered table is %d wide", m.getTableWidth(), got)
+	}
+
+	m.width = 70
+	m.updateTableRows()
+	if descriptionWidth(m) != 0 {
+		t.Errorf("Expected the Description column to be hidden on a narrow terminal, got width %d", descriptionWidth(m))
+	}
+	// The hidden column takes no padding
+	if got := renderedWidth(m); m.getTableWidth() != got {
+		t.Errorf("getTableWidth() = %d with the Description column hidden, rendered table is %d wide", m.getTableWidth(), got)
+	}
+}
+
+func TestAbsoluteLastLoginIsNotTruncated(t *testing.T) {
+	m := createLargeTestModel(3)
+	lastLogin := time.Date(2024, 5, 2, 14, 31, 0, 0, time.Local)
+	for _, host := range m.hosts {
+		m.rowCache[host.Name] = &rowCacheEntry{tags: host.Tags, lastLogin: lastLogin, hasLogin: true}
+	}
+
+	m.width = 80
+	m.absoluteTimes = true
+	m.updateTableRows()
+
+	want := "2024-05-02 14:31"
+	columns := m.table.Columns()
+	if columns[3].Width < len(want) {
+		t.Errorf("Expected the Last Login column to fit %q, got width %d", want, columns[3].Width)
+	}
+	if got := m.table.Rows()[0][3]; got != want {
+		t.Errorf("Expected absolute last login %q, got %q", want, got)
+	}
+
+	// Toggling back restores relative times
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	m = newModel.(Model)
+	if m.absoluteTimes {
+		t.Fatal("Expected z to switch back to relative times")
+	}
+	if got := m.table.Rows()[0][3]; got != formatTimeAgo(lastLogin) {
+		t.Errorf("Expected relative last login, got %q", got)
+	}
+}
+
+func TestColorLabelFollowsTheme(t *testing.T) {
+	defer SetTheme(CurrentThemeIndex)
+
+	m := createLargeTestModel(3)
+	m.appConfig = &config.AppConfig{HostColors: map[string]string{"node-0001": "accent-2"}}
+	m.updateTableRows()
+
+	if got := m.hostColor("node-0001"); got != "accent-2" {
+		t.Fatalf("Expected the stored slot, got %q", got)
+	}
+	if got := m.hostColor("node-0002"); got != "" {
+		t.Errorf("Expected no label for an unlabeled host, got %q", got)
+	}
+
+	// The slot resolves to each theme's own accent color
+	seen := make(map[string]bool)
+	for i, theme := range Themes {
+		SetTheme(i)
+		color := GetCurrentTheme().LabelColor("accent-2")
+		if color != theme.Accent {
+			t.Errorf("Theme %s: expected accent-2 to be %s, got %s", theme.Name, theme.Accent, color)
+		}
+		seen[color] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Expected the label color to change with the theme")
+	}
+
+	// The label must not hide the host name
+	for _, row := range m.table.Rows() {
+		if !strings.Contains(ansi.Strip(row[0]), "node-") {
+			t.Errorf("Unexpected name cell %q", row[0])
+		}
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares a rendered view with testdata/name, ignoring trailing spaces
+func assertGolden(t *testing.T, name, view string) {
+	t.Helper()
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	got := strings.Join(lines, "\n")
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("View does not match %s:\n%s", path, got)
+	}
+}
+
+func TestCompactLayoutSwitchesWithWidth(t *testing.T) {
+	m := createLargeTestModel(6)
+	m.hosts[2].Name = "a-host-with-a-name-long-enough-to-be-truncated"
+	m.rebuildEntries()
+	m.updateTableRows()
+	m.table.Focus()
+
+	resize := func(width int) {
+		t.Helper()
+		newModel, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 24})
+		m = newModel.(Model)
+	}
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = newModel.(Model)
+
+	resize(120)
+	if m.compactLayout() {
+		t.Fatal("Expected the table at 120 columns")
+	}
+	assertGolden(t, "list_120.golden", m.View())
+
+	resize(50)
+	if !m.compactLayout() {
+		t.Fatal("Expected the compact list at 50 columns")
+	}
+	assertGolden(t, "list_50.golden", m.View())
+
+	// Keys still move the cursor, and widening the terminal keeps it
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = newModel.(Model)
+	resize(120)
+	if m.compactLayout() || m.selectedIndex() != 3 {
+		t.Errorf("Expected the table back with the fourth host selected, got index %d", m.selectedIndex())
+	}
+
+	// The threshold is configurable, and -1 keeps the table
+	m.appConfig = &config.AppConfig{CompactListWidth: -1}
+	resize(50)
+	if m.compactLayout() {
+		t.Error("Expected compact_list_width -1 to keep the table")
+	}
+	m.appConfig = &config.AppConfig{CompactListWidth: 130}
+	resize(120)
+	if !m.compactLayout() {
+		t.Error("Expected compact_list_width 130 to use the compact list at 120 columns")
+	}
+}
+
+func TestSpaceMarksHosts(t *testing.T) {
+	m := createLargeTestModel(10)
+	m.table.SetCursor(2)
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = newModel.(Model)
+	if got := m.markedHostNames(); len(got) != 1 || got[0] != "node-0002" {
+		t.Fatalf("marked = %v, want [node-0002]", got)
+	}
+	row := m.table.SelectedRow()
+	if !strings.Contains(row[0], markedIndicator) {
+		t.Errorf("marked row = %q, want the %s indicator", row[0], markedIndicator)
+	}
+	if entry := m.selectedEntry(); entry == nil || entry.Name != "node-0002" {
+		t.Errorf("selectedEntry() = %+v, want node-0002", entry)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = newModel.(Model)
+	if got := m.markedHostNames(); len(got) != 0 {
+		t.Errorf("marked = %v after a second space, want none", got)
+	}
+}
+
+func TestAnnotationsAppendToTags(t *testing.T) {
+	m := createLargeTestModel(3)
+
+	m.applyAnnotations(annotationsMsg{annotations: map[string]hooks.Annotation{
+		"node-0001": {Text: "team-a", Color: "accent-2"},
+	}})
+	m.table.SetCursor(1)
+	if row := m.table.SelectedRow(); !strings.Contains(ansi.Strip(row[2]), "zone-1"+annotationSeparator+"team-a") {
+		t.Errorf("tags cell = %q, want the annotation after the tags", row[2])
+	}
+	m.table.SetCursor(0)
+	if row := m.table.SelectedRow(); strings.Contains(row[2], annotationSeparator) {
+		t.Errorf("tags cell of a host without annotation = %q", row[2])
+	}
+
+	// A failed command drops every annotation and warns once
+	if cmd := m.applyAnnotations(annotationsMsg{err: errors.New("annotation command timed out after 10s")}); cmd == nil {
+		t.Error("Expected the warning to clear itself")
+	}
+	if !m.showingError || !strings.Contains(m.errorMessage, "timed out") {
+		t.Errorf("error = %q, want a warning", m.errorMessage)
+	}
+	m.table.SetCursor(1)
+	if row := m.table.SelectedRow(); strings.Contains(row[2], "team-a") {
+		t.Errorf("tags cell = %q after a failure, want no annotation", row[2])
+	}
+}
+
+func TestUpdateTableRowsAllocations(t *testing.T) {
+	m := createLargeTestModel(2000)
+
+	// Rebuilding rendered every row's cells from scratch, some 7000 allocations
+	allocs := testing.AllocsPerRun(10, m.updateTableRows)
+	if allocs > 1400 {
+		t.Errorf("updateTableRows() made %.0f allocations for 2000 hosts, want at most 1400", allocs)
+	}
+}
+
+func BenchmarkUpdateTableRows(b *testing.B) {
+	m := createLargeTestModel(2000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.updateTableRows()
+	}
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type tagPickerModel struct {
+	hostName      string
+	tags          []string
+	activeTag     string
+	selectedIndex int
+	styles        Styles
+	width         int
+	height        int
+}
+
+// Messages for communication with parent model
+type tagPickerSubmitMsg struct {
+	tag string
+}
+
+type tagPickerCancelMsg struct{}
+
+// NewTagPicker creates a picker for the tags of the selected host
+func NewTagPicker(hostName string, tags []string, activeTag string, styles Styles, width, height int) *tagPickerModel {
+	selectedIndex := 0
+	for i, tag := range tags {
+		if tag == activeTag {
+			selectedIndex = i
+			break
+		}
+	}
+
+	return &tagPickerModel{
+		hostName:      hostName,
+		tags:          tags,
+		activeTag:     activeTag,
+		selectedIndex: selectedIndex,
+		styles:        styles,
+		width:         width,
+		height:        height,
+	}
+}
+
+func (m *tagPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *tagPickerModel) Update(msg tea.Msg) (*tagPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch key := msg.String(); key {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return tagPickerCancelMsg{} }
+
+		case "enter":
+			return m, m.submit(m.selectedIndex)
+
+		case "up", "k":
+			m.selectedIndex--
+			if m.selectedIndex < 0 {
+				m.selectedIndex = len(m.tags) - 1
+			}
+			return m, nil
+
+		case "down", "j":
+			m.selectedIndex++
+			if m.selectedIndex >= len(m.tags) {
+				m.selectedIndex = 0
+			}
+			return m, nil
+
+		default:
+			// Digits pick a tag directly
+			if index, ok := tagDigitIndex(key); ok && index < len(m.tags) {
+				return m, m.submit(index)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// submit returns a command selecting the tag at index
+func (m *tagPickerModel) submit(index int) tea.Cmd {
+	if index < 0 || index >= len(m.tags) {
+		return nil
+	}
+	tag := m.tags[index]
+	return func() tea.Msg { return tagPickerSubmitMsg{tag: tag} }
+}
+
+// tagDigitIndex maps the keys "1" to "9" to a zero-based tag index
+func tagDigitIndex(key string) (int, bool) {
+	if len(key) != 1 || key[0] < '1' || key[0] > '9' {
+		return 0, false
+	}
+	return int(key[0] - '1'), true
+}
+
+func (m *tagPickerModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	var items []string
+	for i, tag := range m.tags {
+		number := "   "
+		if i < 9 {
+			number = fmt.Sprintf("%d. ", i+1)
+		}
+		label := number + tag
+		if tag == m.activeTag {
+			label += " (active)"
+		}
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground)).Padding(0, 2)
+		if i == m.selectedIndex {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 2)
+		}
+		items = append(items, style.Render(label))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Filter by tag of "+m.hostName),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		helpStyle.Render("1-9/Enter: filter • picking the active tag clears it • Esc: cancel"),
+	)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content),
+	)
+}
@@ -26,15 +26,10 @@ type themePickerSubmitMsg struct {
 type themePickerCancelMsg struct{}
 
 func NewThemePicker(styles Styles, width, height int, appConfig *config.AppConfig) *themePickerModel {
-	// Find current theme index
+	// Start on the theme in use, which is the saved one or its variant
 	selectedIndex := 0
 	if appConfig != nil {
-		for i, theme := range Themes {
-			if theme.Name == appConfig.Theme {
-				selectedIndex = i
-				break
-			}
-		}
+		selectedIndex = CurrentThemeIndex
 	}
 
 	return &themePickerModel{
@@ -62,7 +57,7 @@ func (m *themePickerModel) Update(msg tea.Msg) (*themePickerModel, tea.Cmd) {
 		case "ctrl+c", "esc", "q":
 			// Cancel and revert to original theme
 			if m.appConfig != nil {
-				SetThemeByName(m.appConfig.Theme)
+				applyTheme(m.appConfig.Theme)
 			}
 			return m, func() tea.Msg { return themePickerCancelMsg{} }
 
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"os"
+
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/termbg"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// themeBackground is the background the theme families are matched to, decided
+// at startup from theme_variant or the terminal. Unknown keeps themes as named.
+var themeBackground termbg.Background
+
+// applyTheme applies a theme by name, switched to its light or dark variant to
+// suit the background
+func applyTheme(name string) {
+	if themeBackground != termbg.Unknown {
+		name = themeVariant(name, themeBackground == termbg.Light)
+	}
+	SetThemeByName(name)
+}
+
+// hasThemeVariants reports whether a theme belongs to a family with a light and
+// a dark variant
+func hasThemeVariants(name string) bool {
+	return themeVariant(name, true) != themeVariant(name, false)
+}
+
+// resolveThemeBackground decides the background from the theme_variant setting,
+// asking the terminal only for auto and a theme that has variants to choose from
+func resolveThemeBackground(variant, themeName string) termbg.Background {
+	switch variant {
+	case "dark":
+		return termbg.Dark
+	case "light":
+		return termbg.Light
+	}
+	if !hasThemeVariants(themeName) || !term.IsTerminal(os.Stdout.Fd()) {
+		return termbg.Unknown
+	}
+	bg, err := termbg.Detect(termbg.DefaultTimeout)
+	sshclog.Debug("terminal background", "background", bg, "err", err)
+	return bg
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/xvertile/sshc/internal/termbg"
+)
+
+func TestApplyThemeVariant(t *testing.T) {
+	defer func(index int, bg termbg.Background) {
+		SetTheme(index)
+		themeBackground = bg
+	}(CurrentThemeIndex, themeBackground)
+
+	tests := []struct {
+		name string
+		bg   termbg.Background
+		want string
+	}{
+		{"Default", termbg.Light, "Default Light"},
+		{"Default Light", termbg.Dark, "Default"},
+		{"Solarized Dark", termbg.Light, "Solarized Light"},
+		{"Catppuccin Latte", termbg.Light, "Catppuccin Latte"},
+		{"Catppuccin Latte", termbg.Dark, "Catppuccin Mocha"},
+		{"Dracula", termbg.Light, "Dracula"}, // No light variant
+		{"Default Light", termbg.Unknown, "Default Light"},
+	}
+	for _, tt := range tests {
+		themeBackground = tt.bg
+		applyTheme(tt.name)
+		if got := GetCurrentTheme().Name; got != tt.want {
+			t.Errorf("applyTheme(%q) on a %v background = %q, want %q", tt.name, tt.bg, got, tt.want)
+		}
+	}
+}
+
+func TestResolveThemeBackgroundFromSetting(t *testing.T) {
+	if bg := resolveThemeBackground("light", "Dracula"); bg != termbg.Light {
+		t.Errorf("theme_variant light = %v", bg)
+	}
+	if bg := resolveThemeBackground("dark", "Default"); bg != termbg.Dark {
+		t.Errorf("theme_variant dark = %v", bg)
+	}
+	// A theme without variants does not need the terminal asked
+	if bg := resolveThemeBackground("", "Dracula"); bg != termbg.Unknown {
+		t.Errorf("auto for Dracula = %v, want unknown", bg)
+	}
+}
+
+func TestLightThemesHaveDarkCounterparts(t *testing.T) {
+	for _, theme := range Themes {
+		if theme.Light && !hasThemeVariants(theme.Name) {
+			t.Errorf("light theme %s is not in a family", theme.Name)
+		}
+	}
+	for dark, light := range themeFamilies {
+		for _, name := range []string{dark, light} {
+			found := false
+			for _, theme := range Themes {
+				found = found || theme.Name == name
+			}
+			if !found {
+				t.Errorf("theme family names missing theme %s", name)
+			}
+		}
+	}
+}
@@ -712,16 +712,9 @@ func (m standaloneTransferForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Record in history
 			if m.transferFormModel.historyManager != nil {
-				direction := "upload"
-				if msg.request.Direction == transfer.Download {
-					direction = "download"
-				}
-				_ = m.transferFormModel.historyManager.RecordTransfer(
-					m.transferFormModel.hostName,
-					direction,
-					msg.request.LocalPath,
-					msg.request.RemotePath,
-				)
+				entry := transferHistoryEntry(msg.request)
+				entry.Outcome = history.TransferSucceeded
+				_ = m.transferFormModel.historyManager.RecordTransferEntry(m.transferFormModel.hostName, entry)
 			}
 
 			fmt.Println("Transfer complete!")
@@ -2,12 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/connectivity"
 	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/metrics"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -17,6 +19,12 @@ import (
 
 // NewModel creates a new TUI model with the given SSH hosts
 func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
+	return newModel(hosts, configFile, currentVersion, "")
+}
+
+// newModel creates the model, browsing the hosts shared at browseSource when it
+// is set, in which case nothing about them is stored locally
+func newModel(hosts []config.SSHHost, configFile, currentVersion, browseSource string) Model {
 	// Load application configuration
 	appConfig, err := config.LoadAppConfig()
 	if err != nil {
@@ -26,9 +34,23 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		appConfig = &defaultConfig
 	}
 
-	// Apply saved theme
+	// Apply saved theme, in the variant that suits the terminal's background
+	themeBackground = resolveThemeBackground(appConfig.ThemeVariant, appConfig.Theme)
 	if appConfig.Theme != "" {
-		SetThemeByName(appConfig.Theme)
+		applyTheme(appConfig.Theme)
+	}
+
+	// Drop color labels of hosts renamed or removed outside sshc. Only the default
+	// config lists every host, so a config given with -F leaves them alone.
+	if configFile == "" && browseSource == "" && len(appConfig.HostColors) > 0 {
+		names := make([]string, len(hosts))
+		for i, host := range hosts {
+			names[i] = host.Name
+		}
+		if pruned := appConfig.PruneHostColors(names); len(pruned) > 0 {
+			debugLogf("Removed color labels of missing hosts: %s", strings.Join(pruned, ", "))
+			config.SaveAppConfig(appConfig)
+		}
 	}
 
 	// Initialize the history manager
@@ -38,10 +60,16 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		fmt.Printf("Warning: Could not initialize history manager: %v\n", err)
 		historyManager = nil
 	}
+	if browseSource != "" {
+		historyManager = nil
+	}
+	if historyManager != nil && appConfig != nil {
+		historyManager.SetJournal(appConfig.HistoryJournal)
+	}
 
 	// Load k8s hosts if config exists (feature is off by default)
 	var k8sHosts []config.K8sHost
-	if config.K8sConfigExists() {
+	if browseSource == "" && config.K8sConfigExists() {
 		k8sHosts, err = config.ParseK8sConfig()
 		if err != nil {
 			// Log the error but continue without k8s hosts
@@ -55,6 +83,9 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 
 	// Initialize ping manager with 5 second timeout
 	pingManager := connectivity.NewPingManager(5 * time.Second)
+	if appConfig != nil {
+		pingManager.SetMaxConcurrent(appConfig.PingConcurrency)
+	}
 
 	// Determine sort mode from config
 	sortMode := SortByName
@@ -69,7 +100,10 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		historyManager: historyManager,
 		pingManager:    pingManager,
 		sortMode:       sortMode,
+		absoluteTimes:  appConfig != nil && appConfig.TimeFormat == "absolute",
+		collapseBlocks: appConfig != nil && appConfig.CollapseBlocks,
 		configFile:     configFile,
+		dryRun:         config.IsDryRun(),
 		currentVersion: currentVersion,
 		appConfig:      appConfig,
 		styles:         styles,
@@ -77,6 +111,30 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		height:         24,
 		ready:          false,
 		viewMode:       ViewList,
+		rowCache:       make(map[string]*rowCacheEntry),
+		browseSource:   browseSource,
+	}
+	if appConfig != nil {
+		m.hiddenColumns = hideableColumns(appConfig.HiddenColumns)
+	}
+	if browseSource == "" {
+		m.configSnapshot = takeConfigSnapshot(configFile)
+	}
+	if metrics.Enabled() {
+		m.debugOverlay = newDebugOverlay(styles)
+	}
+	m.expireMaintenance()
+	if browseSource == "" {
+		osCache, err := connectivity.LoadOSCache()
+		if err != nil {
+			debugLogf("Could not locate the OS info cache: %v", err)
+		}
+		m.osCache = osCache
+	}
+	if historyManager != nil && historyManager.RecoveredFrom() != "" {
+		m.errorMessage = fmt.Sprintf("Connection history was corrupt and has been reset; the old file is at %s", abbreviateHome(historyManager.RecoveredFrom()))
+		m.showingError = true
+		m.report(severityWarning, sourceHistory, m.errorMessage)
 	}
 
 	// Sort hosts according to the default sort mode
@@ -99,6 +157,7 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		// {Title: "Port", Width: 6},                   // Commented to save space
 		{Title: "Tags", Width: tagsWidth},
 		{Title: "Last Login", Width: lastLoginWidth},
+		{Title: "Description", Width: 0}, // Sized once the terminal width is known
 	}
 
 	// Build unified entries for SSH and K8s hosts
@@ -132,51 +191,11 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 	m.allEntries = allEntries
 	m.filteredEntries = allEntries
 
-	// Convert entries to table rows
-	var rows []table.Row
-	for _, entry := range allEntries {
-		// Get status indicator (only for SSH hosts)
-		var statusIndicator string
-		if entry.IsK8s {
-			statusIndicator = "k" // Kubernetes indicator
-		} else {
-			statusIndicator = m.getPingStatusIndicator(entry.Name)
-		}
-
-		// Format tags for display
-		var tagsStr string
-		if len(entry.Tags) > 0 {
-			// Add the # prefix to each tag and join them with spaces
-			var formattedTags []string
-			for _, tag := range entry.Tags {
-				formattedTags = append(formattedTags, "#"+tag)
-			}
-			tagsStr = strings.Join(formattedTags, " ")
-		}
-
-		// Format last login information
-		var lastLoginStr string
-		if historyManager != nil {
-			if lastConnect, exists := historyManager.GetLastConnectionTime(entry.Name); exists {
-				lastLoginStr = formatTimeAgo(lastConnect)
-			}
-		}
-
-		rows = append(rows, table.Row{
-			statusIndicator + " " + entry.Name,
-			entry.Hostname,
-			// host.User,        // Commented to save space
-			// host.Port,        // Commented to save space
-			tagsStr,
-			lastLoginStr,
-		})
-	}
-
 	// Create the table with initial height (will be updated on first WindowSizeMsg)
 	t := table.New(
 		table.WithColumns(columns),
-		table.WithRows(rows),
 		table.WithFocused(true),
+		table.WithKeyMap(listTableKeyMap()),
 		table.WithHeight(10), // Initial height, will be recalculated dynamically
 	)
 
@@ -197,6 +216,9 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 	m.filteredHosts = sortedHosts
 	m.filteredK8sHosts = k8sHosts
 
+	// Materialize the rows around the initial cursor
+	m.materializeRows(0)
+
 	// Start in search mode if configured
 	if appConfig != nil && appConfig.StartInSearchMode {
 		m.searchMode = true
@@ -207,6 +229,13 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 	// Initialize table styles based on initial focus state
 	m.updateTableStyles()
 
+	// Problems lint finds in the config wait in the message drawer
+	for _, warning := range appConfig.KeyBindingWarnings {
+		m.drawer.add(severityWarning, sourceKeys, "Key bindings: "+warning)
+	}
+	m.reportLint(sortedHosts)
+	m.reportPermissions(sortedHosts)
+
 	// The table height will be properly set on the first WindowSizeMsg
 	// when m.ready becomes true and actual terminal dimensions are known
 
@@ -215,10 +244,25 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 
 // RunInteractiveMode starts the interactive TUI interface
 func RunInteractiveMode(hosts []config.SSHHost, configFile, currentVersion string) error {
+	// Send debug logging to a file so it does not draw over the TUI
+	if os.Getenv("SSHC_DEBUG") != "" {
+		if logPath, err := config.StateFilePath("debug.log"); err == nil {
+			if f, err := tea.LogToFile(logPath, "sshc"); err == nil {
+				defer f.Close()
+			}
+		}
+	}
+
 	m := NewModel(hosts, configFile, currentVersion)
 
 	// Start the application in alt screen mode for clean output
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithReportFocus())
+
+	// Audit log failures must not print over the TUI, show them as a transient error instead
+	config.SetAuditErrorHandler(func(err error) {
+		go p.Send(auditErrorMsg{err: err})
+	})
+
 	_, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("error running TUI: %w", err)
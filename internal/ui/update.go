@@ -2,12 +2,18 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/connectivity"
+	"github.com/xvertile/sshc/internal/keys"
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/metrics"
+	"github.com/xvertile/sshc/internal/sshver"
 	"github.com/xvertile/sshc/internal/transfer"
 	"github.com/xvertile/sshc/internal/version"
 
@@ -23,49 +29,159 @@ type (
 	errorMsg        string
 )
 
+// sshVersionMsg carries the result of detecting the local ssh client
+type sshVersionMsg struct {
+	version sshver.Version
+	err     error
+}
+
+// detectSSHVersionCmd runs ssh -V in the background
+func detectSSHVersionCmd() tea.Cmd {
+	return func() tea.Msg {
+		v, err := sshver.Detect()
+		return sshVersionMsg{version: v, err: err}
+	}
+}
+
+// bannerMsg carries the pre-auth banner of a host, fetched for the info view
+type bannerMsg struct {
+	hostName string
+	banner   string
+	err      error
+}
+
+// fetchBannerCmd reads a host's pre-auth banner in the background
+func fetchBannerCmd(host config.SSHHost) tea.Cmd {
+	return func() tea.Msg {
+		banner, err := connectivity.FetchBanner(context.Background(), host)
+		return bannerMsg{hostName: host.Name, banner: banner, err: err}
+	}
+}
+
+// auditErrorMsg reports that a change was saved but could not be written to the audit log
+type auditErrorMsg struct {
+	err error
+}
+
+// searchDebounceDelay is how long typing must pause before large lists are filtered
+const searchDebounceDelay = 80 * time.Millisecond
+
+// searchDebounceThreshold is the number of entries above which filtering is debounced
+const searchDebounceThreshold = 500
+
+// listSearchDebounceMsg triggers a pending host list filter once typing pauses
+type listSearchDebounceMsg struct {
+	seq int
+}
+
+// pingRefreshInterval is the shortest time between two table refreshes for ping results
+const pingRefreshInterval = 100 * time.Millisecond
+
+// pingRefreshMsg shows the ping results received since the last refresh
+type pingRefreshMsg struct{}
+
+// agentKeyAddedMsg is sent when ssh-add started from the info view exits
+type agentKeyAddedMsg struct {
+	err error
+}
+
 // sshConnectionResultMsg is sent when an SSH/kubectl connection completes
 type sshConnectionResultMsg struct {
-	err error
+	err          error
+	clientFailed bool // ssh could not connect or lost the connection, see hintedCommand.clientFailed
 }
 
-// startPingAllCmd creates a command to ping all hosts concurrently
-func (m Model) startPingAllCmd() tea.Cmd {
+// startPingAllCmd creates a command to ping hosts concurrently, probing hosts
+// that resolve to the same hostname and port only once
+func (m Model) startPingAllCmd(hosts []config.SSHHost) tea.Cmd {
 	if m.pingManager == nil {
 		return nil
 	}
 
-	return tea.Batch(
-		// Create individual ping commands for each host
-		func() tea.Cmd {
-			var cmds []tea.Cmd
-			for _, host := range m.hosts {
-				cmds = append(cmds, pingSingleHostCmd(m.pingManager, host))
+	var cmds []tea.Cmd
+	groups := connectivity.GroupByTarget(hosts)
+	for _, group := range groups {
+		cmds = append(cmds, pingGroupCmd(m.pingManager, group))
+	}
+	sshclog.Info("ping batch", "hosts", len(hosts), "targets", len(groups))
+	return tea.Batch(cmds...)
+}
+
+// pingedHosts returns the hosts ping-all pings: all of them, or while the quick
+// tag filter is on, those with the tag as the config lists them. A browsed
+// list is not in the local config and is filtered as it is.
+func (m Model) pingedHosts() []config.SSHHost {
+	if !m.tagFilterActive() {
+		return m.hosts
+	}
+	if m.browsing() {
+		return config.FilterHostsByTags(m.hosts, []string{m.tagFilter}, false)
+	}
+
+	var hosts []config.SSHHost
+	var err error
+	if m.configFile != "" {
+		hosts, err = config.GetHostsByTagsFromFile([]string{m.tagFilter}, false, m.configFile)
+	} else {
+		hosts, err = config.GetHostsByTag(m.tagFilter)
+	}
+	if err != nil {
+		sshclog.Warn("tag lookup for ping failed", "tag", m.tagFilter, "err", err)
+		return config.FilterHostsByTags(m.hosts, []string{m.tagFilter}, false)
+	}
+	return withBlockAliases(hosts)
+}
+
+// withBlockAliases adds a copy of each host for the other names of its block,
+// which the list shows with a status of their own
+func withBlockAliases(hosts []config.SSHHost) []config.SSHHost {
+	var all []config.SSHHost
+	for _, host := range hosts {
+		all = append(all, host)
+		for _, name := range host.BlockNames {
+			if name != host.Name {
+				alias := host
+				alias.Name = name
+				all = append(all, alias)
 			}
-			return tea.Batch(cmds...)
-		}(),
-	)
+		}
+	}
+	return all
 }
 
-// listenForPingResultsCmd is no longer needed since we use individual ping commands
+// hostNames returns the names of hosts
+func hostNames(hosts []config.SSHHost) []string {
+	names := make([]string, len(hosts))
+	for i, host := range hosts {
+		names[i] = host.Name
+	}
+	return names
+}
 
-// pingSingleHostCmd creates a command to ping a single host
-func pingSingleHostCmd(pingManager *connectivity.PingManager, host config.SSHHost) tea.Cmd {
+// pingGroupCmd creates a command to ping hosts sharing a ping target, which
+// sends a result for each of them
+func pingGroupCmd(pingManager *connectivity.PingManager, hosts []config.SSHHost) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		result := pingManager.PingHost(ctx, host)
-		return pingResultMsg(result)
+		// The manager applies its own per-probe timeout, so time spent queued doesn't count
+		results := pingManager.PingGroup(context.Background(), hosts)
+		first := results[0]
+		sshclog.Debug("ping", "host", first.HostName, "hosts", len(hosts), "status", first.Status, "duration", first.Duration, "err", first.Error)
+
+		cmds := make(tea.BatchMsg, len(results))
+		for i, result := range results {
+			cmds[i] = func() tea.Msg { return pingResultMsg(result) }
+		}
+		return cmds
 	}
 }
 
 // checkVersionCmd creates a command to check for version updates
-func checkVersionCmd(currentVersion string) tea.Cmd {
+func checkVersionCmd(currentVersion string, opts version.HTTPOptions) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), opts.RequestTimeout())
 		defer cancel()
 
-		updateInfo, err := version.CheckForUpdates(ctx, currentVersion)
+		updateInfo, err := version.CheckForUpdates(ctx, currentVersion, opts)
 		if err != nil {
 			return versionErrorMsg(err)
 		}
@@ -78,18 +194,45 @@ func (m Model) Init() tea.Cmd {
 	var cmds []tea.Cmd
 
 	// Basic initialization commands
-	cmds = append(cmds, textinput.Blink)
+	cmds = append(cmds, textinput.Blink, detectSSHVersionCmd(), configCheckTick(), windowSizeFallback())
+	if cmd := m.startSessionScanCmd(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	if cmd := m.loadAnnotations(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	// Clear a warning shown at startup, such as a reset history
+	if m.showingError {
+		cmds = append(cmds, func() tea.Msg {
+			time.Sleep(5 * time.Second)
+			return errorMsg("clear")
+		})
+	}
 
 	// Check for version updates if we have a current version
 	if m.currentVersion != "" {
-		cmds = append(cmds, checkVersionCmd(m.currentVersion))
+		cmds = append(cmds, checkVersionCmd(m.currentVersion, version.HTTPOptionsFromAppConfig(m.appConfig)))
 	}
 
 	return tea.Batch(cmds...)
 }
 
-// Update handles model updates
+// Update handles model updates. In a dry run, changes that were kept from being
+// written while handling msg are shown in place of the usual result.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer sshclog.Recover()
+	if metrics.Enabled() {
+		metrics.MessageLoop.Tick(time.Now())
+	}
+	model, cmd := m.update(msg)
+	if updated, ok := model.(Model); ok && updated.dryRun {
+		return updated.showDryRunChanges(), cmd
+	}
+	return model, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	// Handle different message types
@@ -100,10 +243,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		m.styles = NewStyles(m.width)
 		m.ready = true
+		if m.debugOverlay != nil {
+			m.debugOverlay.styles = m.styles
+		}
 
 		// Update table height and columns based on new window size
 		m.updateTableHeight()
 		m.updateTableColumns()
+		if m.drawer.open {
+			m.drawer.refresh(m.drawerWidth())
+		}
 
 		// Update sub-forms if they exist
 		if m.addForm != nil {
@@ -151,19 +300,134 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.fileSelectorForm.height = m.height
 			m.fileSelectorForm.styles = m.styles
 		}
+		if m.colorPicker != nil {
+			m.colorPicker.width = m.width
+			m.colorPicker.height = m.height
+			m.colorPicker.styles = m.styles
+		}
+		if m.dashboard != nil {
+			m.dashboard.width = m.width
+			m.dashboard.height = m.height
+			m.dashboard.styles = m.styles
+		}
+		if m.snippetPicker != nil {
+			m.snippetPicker.width = m.width
+			m.snippetPicker.height = m.height
+			m.snippetPicker.styles = m.styles
+		}
+		if m.dryRunView != nil {
+			m.dryRunView.width = m.width
+			m.dryRunView.height = m.height
+			m.dryRunView.styles = m.styles
+		}
+		if m.mountForm != nil {
+			m.mountForm.width = m.width
+			m.mountForm.height = m.height
+			m.mountForm.styles = m.styles
+		}
+		if m.historyView != nil {
+			m.historyView.width = m.width
+			m.historyView.height = m.height
+			m.historyView.styles = m.styles
+		}
+		if m.waitView != nil {
+			m.waitView.width = m.width
+			m.waitView.height = m.height
+			m.waitView.styles = m.styles
+		}
+		if m.verifyView != nil {
+			m.verifyView.width = m.width
+			m.verifyView.height = m.height
+			m.verifyView.styles = m.styles
+		}
+		if m.mergeView != nil {
+			m.mergeView.width = m.width
+			m.mergeView.height = m.height
+			m.mergeView.styles = m.styles
+		}
+		if m.openURLForm != nil {
+			m.openURLForm.width = m.width
+			m.openURLForm.height = m.height
+			m.openURLForm.styles = m.styles
+		}
+		if m.onboard != nil {
+			m.onboard.width = m.width
+			m.onboard.height = m.height
+			m.onboard.styles = m.styles
+			if m.onboard.keyUpload != nil {
+				m.onboard.keyUpload.width = m.width
+				m.onboard.keyUpload.height = m.height
+				m.onboard.keyUpload.styles = m.styles
+			}
+		}
 		if m.sshKeyUploadForm != nil {
 			m.sshKeyUploadForm.width = m.width
 			m.sshKeyUploadForm.height = m.height
 			m.sshKeyUploadForm.styles = m.styles
 		}
+		if m.dualBrowser != nil {
+			m.dualBrowser.styles = m.styles
+			m.dualBrowser, _ = m.dualBrowser.Update(msg)
+		}
+		return m, nil
+
+	case windowSizeFallbackMsg:
+		// Without a WindowSizeMsg the list never leaves "Loading...", so size it
+		// ourselves; a resize arriving later is handled as usual
+		if m.ready {
+			return m, nil
+		}
+		return m.update(fallbackWindowSize())
+
+	case listSearchDebounceMsg:
+		// Ignore ticks superseded by a later keystroke
+		if msg.seq == m.searchSeq {
+			m.applySearchFilter()
+		}
+		return m, nil
+
+	case tea.FocusMsg:
+		// Coming back to the terminal is a good moment to notice edits made elsewhere
+		return m, checkConfigCmd(m.configFile)
+
+	case configCheckTickMsg:
+		return m, tea.Batch(checkConfigCmd(m.configFile), configCheckTick())
+
+	case configCheckMsg:
+		if msg.snapshot != nil && m.configSnapshot != nil {
+			m.configStale = m.configSnapshot.differs(msg.snapshot)
+		}
 		return m, nil
 
 	case pingResultMsg:
-		// Handle ping result - update table display
-		if msg != nil {
-			// Update the table to reflect the new ping status
-			m.updateTableRows()
+		if msg != nil && msg.Error != nil {
+			m.report(severityWarning, sourcePing, fmt.Sprintf("%s: %v", msg.HostName, msg.Error))
+		}
+		// Pinging every host at once sends hundreds of results; refresh once per tick
+		if msg == nil || m.pingRefreshPending {
+			return m, nil
+		}
+		m.pingRefreshPending = true
+		return m, tea.Tick(pingRefreshInterval, func(time.Time) tea.Msg {
+			return pingRefreshMsg{}
+		})
+
+	case pingRefreshMsg:
+		// The status indicator has a fixed width, so the columns stay as they are
+		m.pingRefreshPending = false
+		m.materializeRows(m.selectedIndex())
+		return m, nil
+
+	case osInfoMsg:
+		m.applyOSInfo(msg)
+		return m, nil
+
+	case sessionScanMsg:
+		if msg.err != nil {
+			m.report(severityWarning, sourcePing, fmt.Sprintf("Could not list ssh processes: %v", msg.err))
 		}
+		m.openSessions = msg.counts
+		m.updateTableRows()
 		return m, nil
 
 	case versionCheckMsg:
@@ -173,10 +437,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case nameSuggestionMsg:
+		if m.addForm != nil {
+			m.addForm.applyNameSuggestion(msg)
+		}
+		return m, nil
+
+	case routeSuggestionMsg:
+		if m.addForm != nil {
+			m.addForm.applyRouteSuggestion(msg)
+		}
+		return m, nil
+
+	case bannerMsg:
+		if m.banners == nil {
+			m.banners = make(map[string]bannerMsg)
+		}
+		m.banners[msg.hostName] = msg
+		if m.infoForm != nil && m.infoForm.hostName == msg.hostName {
+			m.infoForm.setBanner(msg.banner, msg.err)
+		}
+		return m, nil
+
+	case sshVersionMsg:
+		m.sshVersion = msg.version
+		m.sshVersionErr = msg.err
+		m.sshVersionKnown = true
+		return m, nil
+
+	case auditErrorMsg:
+		m.errorMessage = fmt.Sprintf("Change saved, but the audit log could not be written: %v", msg.err)
+		m.showingError = true
+		m.report(severityError, sourceWrite, m.errorMessage)
+		return m, func() tea.Msg {
+			time.Sleep(3 * time.Second)
+			return errorMsg("clear")
+		}
+
 	case versionErrorMsg:
 		// Handle version check error (silently - not critical)
 		// We don't want to show error messages for version checks
-		// as it might disrupt the user experience
+		// as it might disrupt the user experience, but keep them in the debug log
+		if errors.Is(msg, version.ErrProxyAuth) {
+			debugLogf("version check: proxy rejected credentials, check http_proxy or HTTPS_PROXY: %v", msg)
+		} else {
+			debugLogf("version check failed: %v", msg)
+		}
 		return m, nil
 
 	case errorMsg:
@@ -189,8 +495,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case sshConnectionResultMsg:
 		// Handle SSH/kubectl connection result
+		if startFailed(msg.err) {
+			// Nothing to retry until the program is installed
+			return m, m.showExecFailure(msg.err)
+		}
 		if msg.err != nil {
 			// Connection failed - show error view for retry
+			if msg.clientFailed && !m.connectionIsK8s && m.connectionHost != "" && m.historyManager != nil {
+				m.historyManager.RecordConnectFailure(m.connectionHost)
+			}
 			m.connectionError = msg.err.Error()
 			m.viewMode = ViewConnectionError
 			return m, nil
@@ -198,11 +511,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Connection succeeded (user exited normally) - quit
 		return m, tea.Quit
 
+	case execFailedMsg:
+		return m, m.showExecFailure(msg.err)
+
 	case addFormSubmitMsg:
 		if msg.err != nil {
-			// Show error in form
+			// Show error in form, opening it again if it was closed while saving
+			if m.addForm == nil && msg.form != nil {
+				m.addForm = msg.form
+				m.viewMode = ViewAdd
+			}
 			if m.addForm != nil {
-				m.addForm.err = msg.err.Error()
+				m.addForm.err = saveErrorText(msg.err)
 			}
 			return m, nil
 		} else {
@@ -220,6 +540,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 			m.hosts = m.sortHosts(hosts)
+			m.noteOwnWrite()
 
 			// Reapply search filter if there is one active
 			if m.searchInput.Value() != "" {
@@ -232,6 +553,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.rebuildEntries()
 			m.updateTableRows()
 			m.viewMode = ViewList
+			if m.addForm != nil {
+				m.addForm.cancelRouteSearch()
+			}
 			m.addForm = nil
 			m.table.Focus()
 			return m, nil
@@ -246,12 +570,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case editFormSubmitMsg:
 		if msg.err != nil {
-			// Show error in form
+			// Show error in form, opening it again if it was closed while writing
+			if m.editForm == nil && msg.form != nil {
+				m.editForm = msg.form
+				m.viewMode = ViewEdit
+			}
 			if m.editForm != nil {
-				m.editForm.err = msg.err.Error()
+				m.editForm.err = saveErrorText(msg.err)
+				m.editForm.preview = nil
 			}
 			return m, nil
 		} else {
+			// Keep the color label, maintenance, usage limit, URLs and pins of a renamed host,
+			// and store the environment overrides of the form
+			if m.editForm != nil {
+				renamedColor := m.appConfig.RenameHostColor(m.editForm.originalName, msg.hostname)
+				renamedMaintenance := m.appConfig.RenameMaintenance(m.editForm.originalName, msg.hostname)
+				renamedURLs := m.appConfig.RenameHostURLs(m.editForm.originalName, msg.hostname)
+				renamedPins := m.appConfig.RenamePinnedPaths(m.editForm.originalName, msg.hostname)
+				savedEnv := m.editForm.saveHostEnv(m.appConfig)
+				if m.appConfig.RenameUsageLimit(m.editForm.originalName, msg.hostname) || renamedMaintenance || renamedColor || renamedURLs || renamedPins || savedEnv {
+					config.SaveAppConfig(m.appConfig)
+				}
+			}
+
 			// Success: refresh hosts and return to list view
 			var hosts []config.SSHHost
 			var err error
@@ -266,6 +608,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 			m.hosts = m.sortHosts(hosts)
+			m.noteOwnWrite()
 
 			// Reapply search filter if there is one active
 			if m.searchInput.Value() != "" {
@@ -283,6 +626,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+	case editFormPreviewMsg:
+		// The change is shown for confirmation by the form that computed it
+		if m.editForm != nil {
+			updatedModel, cmd := m.editForm.Update(msg)
+			m.editForm = updatedModel.(*editFormModel)
+			return m, cmd
+		}
+		return m, nil
+
 	case editFormCancelMsg:
 		// Cancel: return to list view
 		m.viewMode = ViewList
@@ -292,13 +644,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case moveFormSubmitMsg:
 		if msg.err != nil {
-			// En cas d'erreur, on pourrait afficher une notification ou retourner à la liste
-			// Pour l'instant, on retourne simplement à la liste
+			// Nothing was moved, go back to the list and say why
 			m.viewMode = ViewList
 			m.moveForm = nil
 			m.table.Focus()
-			return m, nil
+			m.errorMessage = fmt.Sprintf("Move failed: %v", msg.err)
+			m.showingError = true
+			m.report(severityError, sourceWrite, m.errorMessage)
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second)
+				return errorMsg("clear")
+			}
 		} else {
+			m.marked = nil
 			// Success: refresh hosts and return to list view
 			var hosts []config.SSHHost
 			var err error
@@ -313,6 +671,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 			m.hosts = m.sortHosts(hosts)
+			m.noteOwnWrite()
 
 			// Reapply search filter if there is one active
 			if m.searchInput.Value() != "" {
@@ -354,166 +713,681 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			// File selected: proceed to add form with selected file
 			m.addForm = NewAddForm("", m.styles, m.width, m.height, msg.selectedFile)
+			completeHostNames(&m.addForm.inputs[addProxyJumpInput], m.hosts, "")
 			m.viewMode = ViewAdd
 			m.fileSelectorForm = nil
 			return m, textinput.Blink
 		}
 
-	case infoFormEditMsg:
-		// Switch from info to edit mode
-		editForm, err := NewEditForm(msg.hostName, m.styles, m.width, m.height, m.configFile)
-		if err != nil {
-			// Handle error - could show in UI, for now just go back to list
-			m.viewMode = ViewList
-			m.infoForm = nil
-			m.table.Focus()
-			return m, nil
-		}
-		m.editForm = editForm
+	case infoFormTagMsg:
+		// Tag chip selected in the info view: filter the list by it
+		m.viewMode = ViewList
 		m.infoForm = nil
-		m.viewMode = ViewEdit
-		return m, textinput.Blink
-
-	case portForwardSubmitMsg:
-		if msg.err != nil {
-			// Show error in form
-			if m.portForwardForm != nil {
-				m.portForwardForm.err = msg.err.Error()
-			}
-			return m, nil
-		} else {
-			// Success: execute SSH command with port forwarding
-			if len(msg.sshArgs) > 0 {
-				sshCmd := exec.Command("ssh", msg.sshArgs...)
-
-				// Record the connection in history
-				if m.historyManager != nil && m.portForwardForm != nil {
-					err := m.historyManager.RecordConnection(m.portForwardForm.hostName)
-					if err != nil {
-						fmt.Printf("Warning: Could not record connection history: %v\n", err)
-					}
-				}
-
-				return m, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
-					return tea.Quit()
-				})
-			}
+		m.table.Focus()
+		m.toggleTagFilter(msg.tag)
+		return m, nil
 
-			// If no SSH args, just return to list view
-			m.viewMode = ViewList
-			m.portForwardForm = nil
-			m.table.Focus()
-			return m, nil
-		}
+	case tagPickerSubmitMsg:
+		m.viewMode = ViewList
+		m.tagPicker = nil
+		m.table.Focus()
+		m.toggleTagFilter(msg.tag)
+		return m, nil
 
-	case portForwardCancelMsg:
-		// Cancel: return to list view
+	case tagPickerCancelMsg:
 		m.viewMode = ViewList
-		m.portForwardForm = nil
+		m.tagPicker = nil
 		m.table.Focus()
 		return m, nil
 
-	case transferSubmitMsg:
-		if msg.err != nil {
-			// Show error in form
-			if m.transferForm != nil {
-				m.transferForm.err = msg.err.Error()
-			}
-			return m, nil
-		} else {
-			// Success: execute transfer command
-			if msg.request != nil {
-				// Record the transfer in history
-				if m.historyManager != nil {
-					direction := "upload"
-					if msg.request.Direction == transfer.Download {
-						direction = "download"
-					}
-					_ = m.historyManager.RecordTransfer(
-						msg.request.Host,
-						direction,
-						msg.request.LocalPath,
-						msg.request.RemotePath,
-					)
-				}
+	case infoFormColorMsg:
+		m.colorPicker = NewColorPicker(msg.hostName, m.appConfig.HostColors[msg.hostName], m.styles, m.width, m.height)
+		m.infoForm = nil
+		m.viewMode = ViewColorPicker
+		return m, nil
 
-				// Build and execute scp command
-				scpCmd := msg.request.BuildSCPCommand()
-				return m, tea.ExecProcess(scpCmd, func(err error) tea.Msg {
-					return tea.Quit()
-				})
-			}
+	case infoFormAddKeyMsg:
+		// ssh-add prompts for the passphrase on the terminal
+		return m, execProcess(keys.AddCommand(msg.path), func(err error) tea.Msg {
+			return agentKeyAddedMsg{err: err}
+		})
 
-			// If no request, just return to list view
-			m.viewMode = ViewList
-			m.transferForm = nil
-			m.table.Focus()
-			return m, nil
+	case agentKeyAddedMsg:
+		if m.infoForm != nil {
+			m.infoForm.checkIdentity()
+		}
+		if msg.err != nil {
+			m.errorMessage = fmt.Sprintf("ssh-add failed: %v", msg.err)
+			m.showingError = true
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second)
+				return errorMsg("clear")
+			}
 		}
+		return m, nil
 
-	case transferCancelMsg:
-		// Cancel: return to list view
+	case colorPickerSubmitMsg:
 		m.viewMode = ViewList
-		m.transferForm = nil
+		m.colorPicker = nil
 		m.table.Focus()
+		m.appConfig.SetHostColor(msg.hostName, msg.slot)
+		m.updateTableRows()
+		if err := config.SaveAppConfig(m.appConfig); err != nil {
+			m.errorMessage = fmt.Sprintf("Could not save color label: %v", err)
+			m.showingError = true
+			m.report(severityError, sourceWrite, m.errorMessage)
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second)
+				return errorMsg("clear")
+			}
+		}
 		return m, nil
 
-	case quickTransferCancelMsg:
-		// Quick transfer cancelled or done: return to list view
+	case colorPickerCancelMsg:
 		m.viewMode = ViewList
-		m.quickTransferForm = nil
+		m.colorPicker = nil
 		m.table.Focus()
 		return m, nil
 
-	case quickLocalPickedMsg, quickRemotePickedMsg, quickTransferDoneMsg:
-		// Route quick transfer async messages to the form
-		if m.viewMode == ViewQuickTransfer && m.quickTransferForm != nil {
-			var newForm *quickTransferModel
-			newForm, cmd = m.quickTransferForm.Update(msg)
-			m.quickTransferForm = newForm
+	case dashboardResultMsg:
+		if m.dashboard != nil {
+			var cmd tea.Cmd
+			m.dashboard, cmd = m.dashboard.Update(msg)
 			return m, cmd
 		}
 		return m, nil
 
-	case openRemoteBrowserMsg:
-		// Open the remote browser as a sub-view (not a nested program)
-		m.remoteBrowserForm = NewRemoteBrowser(msg.host, msg.startPath, msg.configFile, msg.mode, m.styles, m.width, m.height)
-		m.viewMode = ViewRemoteBrowser
-		return m, m.remoteBrowserForm.Init()
-
-	case remoteBrowserResultMsg:
-		// Remote browser completed - route result back to quick transfer
-		m.remoteBrowserForm = nil
-		m.viewMode = ViewQuickTransfer
-		if m.quickTransferForm != nil {
-			// Convert to quickRemotePickedMsg
-			pickedMsg := quickRemotePickedMsg{path: msg.path, selected: msg.selected}
-			var newForm *quickTransferModel
-			newForm, cmd = m.quickTransferForm.Update(pickedMsg)
-			m.quickTransferForm = newForm
-			return m, cmd
-		}
+	case dashboardCloseMsg:
+		m.viewMode = ViewList
+		m.dashboard = nil
+		m.table.Focus()
 		return m, nil
 
-	case remoteBrowserLoadedMsg, remoteBrowserSearchMsg, searchDebounceMsg:
-		// Route remote browser async messages to the form
-		if m.viewMode == ViewRemoteBrowser && m.remoteBrowserForm != nil {
-			var newForm *remoteBrowserModel
-			newForm, cmd = m.remoteBrowserForm.Update(msg)
-			m.remoteBrowserForm = newForm
+	case onboardScanMsg, onboardLoginMsg:
+		if m.onboard != nil {
+			var cmd tea.Cmd
+			m.onboard, cmd = m.onboard.Update(msg)
 			return m, cmd
 		}
 		return m, nil
 
-	case helpCloseMsg:
-		// Close help: return to list view
+	case onboardCloseMsg:
 		m.viewMode = ViewList
-		m.helpForm = nil
+		m.onboard = nil
 		m.table.Focus()
 		return m, nil
 
-	case k8sAddFormSubmitMsg:
-		if msg.err != nil {
+	case dryRunCloseMsg:
+		m.viewMode = m.dryRunReturn
+		m.dryRunView = nil
+		if m.viewMode == ViewList {
+			m.table.Focus()
+		}
+		return m, nil
+
+	case snippetRunMsg:
+		m.viewMode = ViewList
+		m.snippetPicker = nil
+		m.table.Focus()
+		if m.historyManager != nil {
+			if err := m.historyManager.RecordSnippet(msg.hostName, msg.snippet.Name); err != nil {
+				// Log the error but don't prevent running the snippet
+				fmt.Printf("Warning: Could not record snippet history: %v\n", err)
+			}
+		}
+		return m, m.execSnippet(msg.hostName, msg.snippet)
+
+	case snippetPickerCancelMsg:
+		m.viewMode = ViewList
+		m.snippetPicker = nil
+		m.table.Focus()
+		return m, nil
+
+	case waitProgressMsg:
+		if m.waitView != nil && m.waitView.hostName == msg.hostName {
+			var cmd tea.Cmd
+			m.waitView, cmd = m.waitView.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case waitTickMsg:
+		// Keep the elapsed time moving while the wait view is open
+		if m.waitView != nil && m.waitView.hostName == msg.hostName {
+			return m, waitTick(msg.hostName)
+		}
+		return m, nil
+
+	case waitCancelMsg:
+		m.viewMode = ViewList
+		m.waitView = nil
+		m.table.Focus()
+		return m, nil
+
+	case annotationsMsg:
+		return m, m.applyAnnotations(msg)
+
+	case verifyResultMsg:
+		if m.verifyView != nil {
+			var cmd tea.Cmd
+			m.verifyView, cmd = m.verifyView.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case jumpPromptSubmitMsg:
+		m.viewMode = ViewList
+		m.jumpPrompt = nil
+		m.table.Focus()
+
+		m.connectionHost = msg.hostName
+		m.connectionIsK8s = false
+		m.connectionJump = msg.jump
+		m.connectionError = ""
+		if m.historyManager != nil {
+			m.invalidateRow(msg.hostName)
+			if err := m.historyManager.RecordConnection(msg.hostName); err != nil {
+				fmt.Printf("Warning: Could not record connection history: %v\n", err)
+			}
+			// Offered as the default the next time
+			if err := m.historyManager.RecordJumpOverride(msg.hostName, msg.jump); err != nil {
+				fmt.Printf("Warning: Could not record connection history: %v\n", err)
+			}
+		}
+		return m, m.execSSHVia(msg.hostName, msg.jump)
+
+	case jumpPromptCancelMsg:
+		m.viewMode = ViewList
+		m.jumpPrompt = nil
+		m.table.Focus()
+		return m, nil
+
+	case workspaceSaveMsg:
+		m.viewMode = ViewList
+		m.workspacePrompt = nil
+		m.table.Focus()
+		if err := m.saveWorkspace(msg.name); err != nil {
+			return m, m.showWorkspaceError(err)
+		}
+		return m, nil
+
+	case workspacePromptCancelMsg:
+		m.viewMode = ViewList
+		m.workspacePrompt = nil
+		m.table.Focus()
+		return m, nil
+
+	case workspaceLoadMsg:
+		m.viewMode = ViewList
+		m.workspacePicker = nil
+		m.table.Focus()
+		if err := m.loadWorkspace(msg.name); err != nil {
+			return m, m.showWorkspaceError(err)
+		}
+		return m, nil
+
+	case workspaceDeleteMsg:
+		// The picker stays open on the remaining workspaces
+		err := m.deleteWorkspace(msg.name)
+		if m.workspacePicker != nil {
+			m.workspacePicker.active = m.activeWorkspace
+			m.workspacePicker.setNames(workspaceNames(m.appConfig))
+		}
+		if err != nil {
+			m.viewMode = ViewList
+			m.workspacePicker = nil
+			m.table.Focus()
+			return m, m.showWorkspaceError(err)
+		}
+		return m, nil
+
+	case workspacePickerCloseMsg:
+		m.viewMode = ViewList
+		m.workspacePicker = nil
+		m.table.Focus()
+		return m, nil
+
+	case columnsChangedMsg:
+		// The picker stays open so more columns can be toggled
+		m.setHiddenColumns(msg.hidden)
+		m.saveHiddenColumns()
+		return m, nil
+
+	case columnPickerCloseMsg:
+		m.viewMode = ViewList
+		m.columnPicker = nil
+		m.table.Focus()
+		return m, nil
+
+	case infoFormMaintenanceMsg:
+		m.infoForm = nil
+		if cmd := m.openMaintenancePrompt(msg.hostName); cmd != nil {
+			return m, cmd
+		}
+		m.viewMode = ViewList
+		m.table.Focus()
+		return m, nil
+
+	case maintenanceToggleMsg:
+		m.viewMode = ViewList
+		m.maintenancePrompt = nil
+		m.table.Focus()
+		return m, m.toggleMaintenance(msg)
+
+	case maintenancePromptCancelMsg:
+		m.viewMode = ViewList
+		m.maintenancePrompt = nil
+		m.table.Focus()
+		return m, nil
+
+	case maintenanceConfirmMsg:
+		// Handle the key that connects again, now that the name was typed
+		m.viewMode = ViewList
+		m.maintenanceConfirm = nil
+		m.table.Focus()
+		m.maintenanceConfirmed = msg.hostName
+		return m.handleListViewKeys(msg.key)
+
+	case maintenanceConfirmCancelMsg:
+		m.viewMode = ViewList
+		m.maintenanceConfirm = nil
+		m.table.Focus()
+		return m, nil
+
+	case connectRateConfirmMsg:
+		// Handle the key that connects again, now that it was confirmed
+		m.viewMode = ViewList
+		m.connectRateConfirm = nil
+		m.table.Focus()
+		m.connectRateConfirmed = msg.hostName
+		// The rate is only checked once maintenance was confirmed, so keep that
+		if _, ok := m.inMaintenance(msg.hostName); ok {
+			m.maintenanceConfirmed = msg.hostName
+		}
+		return m.handleListViewKeys(msg.key)
+
+	case connectRateCancelMsg:
+		m.viewMode = ViewList
+		m.connectRateConfirm = nil
+		m.table.Focus()
+		return m, nil
+
+	case mergeViewDoneMsg:
+		if msg.err != nil {
+			// Keep the view open so the choices are not lost
+			if m.mergeView != nil {
+				m.mergeView, _ = m.mergeView.Update(msg)
+			}
+			m.report(severityError, sourceWrite, fmt.Sprintf("Could not merge %s: %v", strings.Join(msg.names, ", "), msg.err))
+			return m, nil
+		}
+		m.viewMode = ViewList
+		m.mergeView = nil
+		m.table.Focus()
+		if _, err := m.reloadConfig(); err != nil {
+			m.errorMessage = fmt.Sprintf("Reload failed: %v", err)
+			m.report(severityError, sourceConfig, m.errorMessage)
+		} else {
+			m.errorMessage = fmt.Sprintf("Merged %s into %s", strings.Join(msg.names, ", "), abbreviateHome(msg.target))
+		}
+		m.showingError = true
+		return m, func() tea.Msg {
+			time.Sleep(2 * time.Second)
+			return errorMsg("clear")
+		}
+
+	case mergeViewCancelMsg:
+		m.viewMode = ViewList
+		m.mergeView = nil
+		m.table.Focus()
+		return m, nil
+
+	case openURLMsg:
+		if msg.tunnel == nil {
+			if m.openURLForm != nil && m.openURLForm.tunnel != nil {
+				// Declined the tunnel
+				m.closeOpenURL()
+				return m, openBrowserCmd(msg.url)
+			}
+			return m, m.openURL(msg.hostName, msg.url)
+		}
+		m.closeOpenURL()
+		return m, m.openTunnelCmd(msg.hostName, *msg.tunnel)
+
+	case openURLCancelMsg:
+		m.closeOpenURL()
+		return m, nil
+
+	case tunnelReadyMsg:
+		if msg.err != nil {
+			m.errorMessage = fmt.Sprintf("Could not open the tunnel %s: %v", msg.offer.forward.Arg(), msg.err)
+			m.report(severityError, sourceExec, m.errorMessage)
+			m.showingError = true
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second)
+				return errorMsg("clear")
+			}
+		}
+		return m, openBrowserCmd(msg.offer.localURL)
+
+	case browserOpenedMsg:
+		if msg.err != nil {
+			m.errorMessage = fmt.Sprintf("Could not open %s: %v", msg.url, msg.err)
+			m.report(severityError, sourceExec, m.errorMessage)
+		} else {
+			m.errorMessage = "Opened " + msg.url
+		}
+		m.showingError = true
+		return m, func() tea.Msg {
+			time.Sleep(2 * time.Second)
+			return errorMsg("clear")
+		}
+
+	case verifyCloseMsg:
+		m.viewMode = ViewList
+		m.verifyView = nil
+		m.table.Focus()
+		if msg.adopted {
+			if _, err := m.reloadConfig(); err != nil {
+				m.errorMessage = fmt.Sprintf("Reload failed: %v", err)
+				m.showingError = true
+				m.report(severityError, sourceConfig, m.errorMessage)
+				return m, func() tea.Msg {
+					time.Sleep(3 * time.Second)
+					return errorMsg("clear")
+				}
+			}
+		}
+		return m, nil
+
+	case waitDoneMsg:
+		if m.waitView == nil || m.waitView.hostName != msg.hostName {
+			return m, nil
+		}
+		connect := m.waitView.connectWhenReady
+		m.waitView.cancel()
+		m.waitView = nil
+		m.viewMode = ViewList
+		m.table.Focus()
+
+		if msg.err == nil && connect {
+			m.connectionHost = msg.hostName
+			m.connectionIsK8s = false
+			m.connectionJump = ""
+			m.connectionError = ""
+			if m.historyManager != nil {
+				m.invalidateRow(msg.hostName)
+				if err := m.historyManager.RecordConnection(msg.hostName); err != nil {
+					fmt.Printf("Warning: Could not record connection history: %v\n", err)
+				}
+			}
+			return m, m.execSSH(msg.hostName)
+		}
+		if msg.err == nil {
+			m.errorMessage = fmt.Sprintf("%s is reachable", msg.hostName)
+		} else {
+			m.errorMessage = fmt.Sprintf("Gave up waiting for %s: %v", msg.hostName, msg.err)
+			m.report(severityWarning, sourcePing, m.errorMessage)
+		}
+		m.showingError = true
+		return m, func() tea.Msg {
+			time.Sleep(5 * time.Second)
+			return errorMsg("clear")
+		}
+
+	case historyViewCloseMsg:
+		m.viewMode = ViewList
+		m.historyView = nil
+		m.table.Focus()
+		return m, nil
+
+	case transferRerunMsg:
+		// Run a transfer from the history again, then come back to the history
+		entry := transferHistoryEntry(msg.request)
+		if m.historyManager != nil {
+			_ = m.historyManager.RecordTransferEntry(msg.request.Host, entry)
+		}
+		historyManager := m.historyManager
+		return m, execProcess(msg.request.BuildSCPCommand(), func(err error) tea.Msg {
+			if historyManager != nil {
+				_ = historyManager.RecordTransferOutcome(msg.request.Host, entry.Timestamp, err)
+			}
+			return transferRerunDoneMsg{hostName: msg.request.Host, err: err}
+		})
+
+	case transferRerunDoneMsg:
+		if m.historyView != nil {
+			var cmd tea.Cmd
+			m.historyView, cmd = m.historyView.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case mountFormCancelMsg:
+		m.viewMode = ViewList
+		m.mountForm = nil
+		m.table.Focus()
+		return m, nil
+
+	case mountFormSubmitMsg:
+		m.viewMode = ViewList
+		m.mountForm = nil
+		m.table.Focus()
+		m.errorMessage = fmt.Sprintf("Mounting %s at %s...", msg.hostName, msg.mountPoint)
+		m.showingError = true
+		return m, mountCmd(m.mounter, msg.hostName, msg.remotePath, msg.mountPoint, m.configFile)
+
+	case mountResultMsg:
+		switch {
+		case msg.err != nil && msg.unmount:
+			m.errorMessage = fmt.Sprintf("Could not unmount %s: %v", msg.hostName, msg.err)
+			m.report(severityError, sourceMount, m.errorMessage)
+		case msg.err != nil:
+			m.errorMessage = fmt.Sprintf("Could not mount %s: %v", msg.hostName, msg.err)
+			m.report(severityError, sourceMount, m.errorMessage)
+		case msg.unmount:
+			m.errorMessage = fmt.Sprintf("Unmounted %s from %s", msg.hostName, msg.mountPoint)
+		default:
+			m.errorMessage = fmt.Sprintf("Mounted %s at %s", msg.hostName, msg.mountPoint)
+		}
+		m.showingError = true
+		m.materializeRows(m.selectedIndex())
+		return m, func() tea.Msg {
+			time.Sleep(4 * time.Second)
+			return errorMsg("clear")
+		}
+
+	case snippetResultMsg:
+		// Unlike a connection, a snippet returns to the host list when it is done
+		if msg.err != nil {
+			m.errorMessage = fmt.Sprintf("Snippet %q failed on %s: %v", msg.snippet.Name, msg.hostName, msg.err)
+			m.showingError = true
+			m.report(severityError, sourceSnippet, m.errorMessage)
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second)
+				return errorMsg("clear")
+			}
+		}
+		return m, nil
+
+	case infoFormEditMsg:
+		// Switch from info to edit mode
+		editForm, err := NewEditForm(msg.hostName, m.styles, m.width, m.height, m.configFile)
+		if err != nil {
+			// Handle error - could show in UI, for now just go back to list
+			m.viewMode = ViewList
+			m.infoForm = nil
+			m.table.Focus()
+			return m, nil
+		}
+		editForm.interactiveCommands = m.appConfig.InteractiveCommandList()
+		editForm.inputs[12].SetValue(config.FormatHostEnv(m.appConfig.HostEnvFor(editForm.originalName)))
+		completeHostNames(&editForm.inputs[4], m.hosts, editForm.originalName)
+		m.editForm = editForm
+		m.infoForm = nil
+		m.viewMode = ViewEdit
+		return m, textinput.Blink
+
+	case portForwardSubmitMsg:
+		if msg.err != nil {
+			// Show error in form
+			if m.portForwardForm != nil {
+				m.portForwardForm.err = msg.err.Error()
+			}
+			return m, nil
+		} else {
+			// Success: execute SSH command with port forwarding
+			if len(msg.sshArgs) > 0 {
+				sshCmd := exec.Command("ssh", msg.sshArgs...)
+
+				// Record the connection in history
+				if m.historyManager != nil && m.portForwardForm != nil {
+					m.invalidateRow(m.portForwardForm.hostName)
+					err := m.historyManager.RecordConnection(m.portForwardForm.hostName)
+					if err != nil {
+						fmt.Printf("Warning: Could not record connection history: %v\n", err)
+					}
+				}
+
+				return m, execProcess(sshCmd, func(err error) tea.Msg {
+					if startFailed(err) {
+						return execFailedMsg{err: err}
+					}
+					return tea.Quit()
+				})
+			}
+
+			// If no SSH args, just return to list view
+			m.viewMode = ViewList
+			m.portForwardForm = nil
+			m.table.Focus()
+			return m, nil
+		}
+
+	case portForwardCancelMsg:
+		// Cancel: return to list view
+		m.viewMode = ViewList
+		m.portForwardForm = nil
+		m.table.Focus()
+		return m, nil
+
+	case transferSubmitMsg:
+		if msg.err != nil {
+			// Show error in form
+			if m.transferForm != nil {
+				m.transferForm.err = msg.err.Error()
+			}
+			return m, nil
+		} else {
+			// Success: execute transfer command
+			if msg.request != nil {
+				// Record the transfer in history, and how it ended once scp exits
+				entry := transferHistoryEntry(msg.request)
+				if m.historyManager != nil {
+					_ = m.historyManager.RecordTransferEntry(msg.request.Host, entry)
+				}
+
+				// Build and execute scp command
+				scpCmd := msg.request.BuildSCPCommand()
+				historyManager := m.historyManager
+				return m, execProcess(scpCmd, func(err error) tea.Msg {
+					if historyManager != nil {
+						_ = historyManager.RecordTransferOutcome(msg.request.Host, entry.Timestamp, err)
+					}
+					if startFailed(err) {
+						return execFailedMsg{err: err}
+					}
+					return tea.Quit()
+				})
+			}
+
+			// If no request, just return to list view
+			m.viewMode = ViewList
+			m.transferForm = nil
+			m.table.Focus()
+			return m, nil
+		}
+
+	case transferCancelMsg:
+		// Cancel: return to list view
+		m.viewMode = ViewList
+		m.transferForm = nil
+		m.table.Focus()
+		return m, nil
+
+	case quickTransferCancelMsg:
+		// Quick transfer cancelled or done: return to list view
+		m.viewMode = ViewList
+		m.quickTransferForm = nil
+		m.table.Focus()
+		return m, nil
+
+	case quickLocalPickedMsg, quickRemotePickedMsg, quickTransferDoneMsg:
+		// Route quick transfer async messages to the form
+		if m.viewMode == ViewQuickTransfer && m.quickTransferForm != nil {
+			var newForm *quickTransferModel
+			newForm, cmd = m.quickTransferForm.Update(msg)
+			m.quickTransferForm = newForm
+			return m, cmd
+		}
+		return m, nil
+
+	case openRemoteBrowserMsg:
+		// Open the remote browser as a sub-view (not a nested program)
+		m.remoteBrowserForm = NewRemoteBrowser(msg.host, msg.startPath, msg.configFile, msg.mode, m.styles, m.width, m.height)
+		m.remoteBrowserForm.appConfig = m.appConfig
+		m.viewMode = ViewRemoteBrowser
+		return m, m.remoteBrowserForm.Init()
+
+	case remoteBrowserResultMsg:
+		// Remote browser completed - route result back to quick transfer
+		m.remoteBrowserForm = nil
+		m.viewMode = ViewQuickTransfer
+		if m.quickTransferForm != nil {
+			// Convert to quickRemotePickedMsg
+			pickedMsg := quickRemotePickedMsg{path: msg.path, selected: msg.selected}
+			var newForm *quickTransferModel
+			newForm, cmd = m.quickTransferForm.Update(pickedMsg)
+			m.quickTransferForm = newForm
+			return m, cmd
+		}
+		return m, nil
+
+	case dualPaneMsg, dualCopyDoneMsg:
+		// Route dual browser async messages to the browser
+		if m.viewMode == ViewDualBrowser && m.dualBrowser != nil {
+			var newBrowser *dualBrowserModel
+			newBrowser, cmd = m.dualBrowser.Update(msg)
+			m.dualBrowser = newBrowser
+			return m, cmd
+		}
+		return m, nil
+
+	case dualBrowserCloseMsg:
+		m.viewMode = ViewList
+		m.dualBrowser = nil
+		m.table.Focus()
+		return m, nil
+
+	case remoteBrowserLoadedMsg, remoteBrowserSearchMsg, searchDebounceMsg:
+		// Route remote browser async messages to the form
+		if m.viewMode == ViewRemoteBrowser && m.remoteBrowserForm != nil {
+			var newForm *remoteBrowserModel
+			newForm, cmd = m.remoteBrowserForm.Update(msg)
+			m.remoteBrowserForm = newForm
+			return m, cmd
+		}
+		return m, nil
+
+	case helpCloseMsg:
+		// Close help: return to list view
+		m.viewMode = ViewList
+		m.helpForm = nil
+		m.table.Focus()
+		return m, nil
+
+	case k8sAddFormSubmitMsg:
+		if msg.err != nil {
 			// Show error in form
 			if m.k8sAddForm != nil {
 				m.k8sAddForm.err = msg.err.Error()
@@ -578,7 +1452,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.appConfig.Theme = msg.themeName
 			_ = config.SaveAppConfig(m.appConfig)
 		}
-		SetThemeByName(msg.themeName)
+		applyTheme(msg.themeName)
 		m.styles = NewStyles(m.width)
 		m.updateTableStyles()
 		m.viewMode = ViewList
@@ -589,7 +1463,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case themePickerCancelMsg:
 		// Cancel: restore original theme and return to list view
 		if m.appConfig != nil {
-			SetThemeByName(m.appConfig.Theme)
+			applyTheme(m.appConfig.Theme)
 		}
 		m.styles = NewStyles(m.width)
 		m.updateTableStyles()
@@ -600,6 +1474,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case sshKeyUploadSubmitMsg:
 		// Handle SSH key upload result
+		if msg.err == nil {
+			m.noteOwnWrite()
+		}
+		if m.onboard != nil {
+			var cmd tea.Cmd
+			m.onboard, cmd = m.onboard.Update(msg)
+			return m, cmd
+		}
 		if m.sshKeyUploadForm != nil {
 			var newForm *sshKeyUploadModel
 			newForm, cmd = m.sshKeyUploadForm.Update(msg)
@@ -609,6 +1491,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case sshKeyUploadCancelMsg:
+		// The key upload step of onboarding returns to the checklist
+		if m.onboard != nil {
+			var cmd tea.Cmd
+			m.onboard, cmd = m.onboard.Update(msg)
+			return m, cmd
+		}
 		// Cancel: return to list view
 		m.viewMode = ViewList
 		m.sshKeyUploadForm = nil
@@ -616,6 +1504,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		// The debug overlay is drawn over every view
+		if msg.String() == "f12" {
+			m.toggleDebugOverlay()
+			return m, nil
+		}
+
 		// Handle view-specific key presses
 		switch m.viewMode {
 		case ViewAdd:
@@ -711,6 +1605,146 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.styles = NewStyles(m.width)
 				return m, cmd
 			}
+		case ViewTagPicker:
+			if m.tagPicker != nil {
+				var newPicker *tagPickerModel
+				newPicker, cmd = m.tagPicker.Update(msg)
+				m.tagPicker = newPicker
+				return m, cmd
+			}
+		case ViewColorPicker:
+			if m.colorPicker != nil {
+				var newPicker *colorPickerModel
+				newPicker, cmd = m.colorPicker.Update(msg)
+				m.colorPicker = newPicker
+				return m, cmd
+			}
+		case ViewDashboard:
+			if m.dashboard != nil {
+				var newDashboard *dashboardModel
+				newDashboard, cmd = m.dashboard.Update(msg)
+				m.dashboard = newDashboard
+				return m, cmd
+			}
+		case ViewOnboard:
+			if m.onboard != nil {
+				var newOnboard *onboardModel
+				newOnboard, cmd = m.onboard.Update(msg)
+				m.onboard = newOnboard
+				return m, cmd
+			}
+		case ViewSnippetPicker:
+			if m.snippetPicker != nil {
+				var newPicker *snippetPickerModel
+				newPicker, cmd = m.snippetPicker.Update(msg)
+				m.snippetPicker = newPicker
+				return m, cmd
+			}
+		case ViewDryRun:
+			if m.dryRunView != nil {
+				var newView *dryRunModel
+				newView, cmd = m.dryRunView.Update(msg)
+				m.dryRunView = newView
+				return m, cmd
+			}
+		case ViewWait:
+			if m.waitView != nil {
+				var newView *waitViewModel
+				newView, cmd = m.waitView.Update(msg)
+				m.waitView = newView
+				return m, cmd
+			}
+		case ViewVerify:
+			if m.verifyView != nil {
+				var newView *verifyViewModel
+				newView, cmd = m.verifyView.Update(msg)
+				m.verifyView = newView
+				return m, cmd
+			}
+		case ViewJumpPrompt:
+			if m.jumpPrompt != nil {
+				var newPrompt *jumpPromptModel
+				newPrompt, cmd = m.jumpPrompt.Update(msg)
+				m.jumpPrompt = newPrompt
+				return m, cmd
+			}
+		case ViewWorkspacePrompt:
+			if m.workspacePrompt != nil {
+				var newPrompt *workspacePromptModel
+				newPrompt, cmd = m.workspacePrompt.Update(msg)
+				m.workspacePrompt = newPrompt
+				return m, cmd
+			}
+		case ViewWorkspacePicker:
+			if m.workspacePicker != nil {
+				var newPicker *workspacePickerModel
+				newPicker, cmd = m.workspacePicker.Update(msg)
+				m.workspacePicker = newPicker
+				return m, cmd
+			}
+		case ViewColumnPicker:
+			if m.columnPicker != nil {
+				var newPicker *columnPickerModel
+				newPicker, cmd = m.columnPicker.Update(msg)
+				m.columnPicker = newPicker
+				return m, cmd
+			}
+		case ViewMaintenancePrompt:
+			if m.maintenancePrompt != nil {
+				var newPrompt *maintenancePromptModel
+				newPrompt, cmd = m.maintenancePrompt.Update(msg)
+				m.maintenancePrompt = newPrompt
+				return m, cmd
+			}
+		case ViewMaintenanceConfirm:
+			if m.maintenanceConfirm != nil {
+				var newConfirm *maintenanceConfirmModel
+				newConfirm, cmd = m.maintenanceConfirm.Update(msg)
+				m.maintenanceConfirm = newConfirm
+				return m, cmd
+			}
+		case ViewConnectRateConfirm:
+			if m.connectRateConfirm != nil {
+				var newConfirm *connectRateConfirmModel
+				newConfirm, cmd = m.connectRateConfirm.Update(msg)
+				m.connectRateConfirm = newConfirm
+				return m, cmd
+			}
+		case ViewMergeBlocks:
+			if m.mergeView != nil {
+				var newView *mergeViewModel
+				newView, cmd = m.mergeView.Update(msg)
+				m.mergeView = newView
+				return m, cmd
+			}
+		case ViewOpenURL:
+			if m.openURLForm != nil {
+				var newForm *openURLModel
+				newForm, cmd = m.openURLForm.Update(msg)
+				m.openURLForm = newForm
+				return m, cmd
+			}
+		case ViewHistory:
+			if m.historyView != nil {
+				var newView *historyViewModel
+				newView, cmd = m.historyView.Update(msg)
+				m.historyView = newView
+				return m, cmd
+			}
+		case ViewMount:
+			if m.mountForm != nil {
+				var newForm *mountFormModel
+				newForm, cmd = m.mountForm.Update(msg)
+				m.mountForm = newForm
+				return m, cmd
+			}
+		case ViewDualBrowser:
+			if m.dualBrowser != nil {
+				var newBrowser *dualBrowserModel
+				newBrowser, cmd = m.dualBrowser.Update(msg)
+				m.dualBrowser = newBrowser
+				return m, cmd
+			}
 		case ViewConnectionError:
 			// Handle connection error view keys
 			return m.handleConnectionErrorKeys(msg)
@@ -734,12 +1768,33 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	key := msg.String()
 
+	if m.drawer.open {
+		return m.handleDrawerKeys(msg)
+	}
+
+	// Movement comes first, so no action fires while a prefix like the g of gg waits
+	if !m.searchMode && !m.deleteMode {
+		switch route, _ := m.routeListKey(key); route {
+		case routePrefix:
+			return m.handlePrefixKey(msg)
+		case routeMovement:
+			return m.handleMovementKey(msg)
+		}
+	}
+
+	if m.collapseBlocks && !m.searchMode && !m.deleteMode {
+		if model, cmd, handled := m.handleBlockKey(msg); handled {
+			return model, cmd
+		}
+	}
+
 	switch key {
 	case "esc", "ctrl+c":
 		if m.deleteMode {
 			// Exit delete mode
 			m.deleteMode = false
 			m.deleteHost = ""
+			m.deleteExpired = nil
 			m.table.Focus()
 			return m, nil
 		}
@@ -751,26 +1806,18 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.table.Focus()
 			return m, nil
 		}
+		if key == "esc" && m.tagFilterActive() {
+			// Clear the quick tag filter before quitting
+			m.toggleTagFilter(m.tagFilter)
+			return m, nil
+		}
 		// Use configurable key bindings for quit
-		if m.appConfig != nil && m.appConfig.KeyBindings.ShouldQuitOnKey(key) {
+		if kb := m.keyBindings(); kb.ShouldQuitOnKey(key) {
 			return m, tea.Quit
 		}
-	case "q":
+	case "ctrl+f":
 		if !m.searchMode && !m.deleteMode {
-			// Use configurable key bindings for quit
-			if m.appConfig != nil && m.appConfig.KeyBindings.ShouldQuitOnKey(key) {
-				return m, tea.Quit
-			}
-		}
-	case "/", "ctrl+f":
-		if !m.searchMode && !m.deleteMode {
-			// Enter search mode
-			m.searchMode = true
-			m.updateTableStyles()
-			m.table.Blur()
-			m.searchInput.Focus()
-			// Don't trigger filtering when entering search mode - wait for user input
-			return m, textinput.Blink
+			return m.enterSearchMode()
 		}
 	case "tab":
 		if !m.deleteMode {
@@ -800,6 +1847,8 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.searchInput.Blur()
 			m.table.Focus()
 			return m, nil
+		} else if m.deleteMode && len(m.deleteExpired) > 0 {
+			return m.deleteExpiredHosts()
 		} else if m.deleteMode {
 			// Confirm deletion - handle both SSH and K8s hosts
 			var err error
@@ -830,6 +1879,7 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						hosts, _ = config.ParseSSHConfig()
 					}
 					m.hosts = m.sortHosts(hosts)
+					m.noteOwnWrite()
 					if m.searchInput.Value() != "" {
 						m.filteredHosts = m.filterHosts(m.searchInput.Value())
 					} else {
@@ -849,25 +1899,31 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Rebuild unified entries and update table
 			m.rebuildEntries()
 			m.updateTableRows()
+			deleted := []string{m.deleteHost}
 			m.deleteMode = false
 			m.deleteHost = ""
 			m.deleteHostIsK8s = false
 			m.table.Focus()
-			return m, nil
+			return m, m.cleanupOrphans(deleted)
 		} else {
 			// Connect to the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0])
-				isK8s := isK8sHostFromTableRow(selected[0])
+			entry := m.selectedEntry()
+			if entry != nil {
+				hostName := entry.Name
+				isK8s := entry.IsK8s
+				if m.blockedByMaintenance(hostName, msg) || m.blockedByConnectRate(hostName, msg) {
+					return m, textinput.Blink
+				}
 
 				// Store connection info for retry
 				m.connectionHost = hostName
 				m.connectionIsK8s = isK8s
+				m.connectionJump = ""
 				m.connectionError = ""
 
 				// Record the connection in history
 				if m.historyManager != nil {
+					m.invalidateRow(hostName)
 					err := m.historyManager.RecordConnection(hostName)
 					if err != nil {
 						fmt.Printf("Warning: Could not record connection history: %v\n", err)
@@ -881,31 +1937,75 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						fmt.Printf("Error: Could not find k8s host: %v\n", err)
 						return m, nil
 					}
+					if k8sHost.Pod == "" {
+						m.errorMessage = fmt.Sprintf("No pod set for '%s', edit the host to choose one", hostName)
+						m.showingError = true
+						return m, func() tea.Msg {
+							time.Sleep(3 * time.Second)
+							return errorMsg("clear")
+						}
+					}
 					kubectlCmd := k8sHost.BuildKubectlCommand()
-					return m, tea.ExecProcess(kubectlCmd, func(err error) tea.Msg {
+					return m, execProcess(kubectlCmd, func(err error) tea.Msg {
 						return sshConnectionResultMsg{err: err}
 					})
 				} else {
-					// Build the SSH command with the appropriate config file
-					var sshCmd *exec.Cmd
-					if m.configFile != "" {
-						sshCmd = exec.Command("ssh", "-F", m.configFile, hostName)
-					} else {
-						sshCmd = exec.Command("ssh", hostName)
-					}
-					return m, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
-						return sshConnectionResultMsg{err: err}
-					})
+					return m, m.execSSH(hostName)
 				}
 			}
 		}
-	case "e":
-		if !m.searchMode && !m.deleteMode {
+	case "ctrl+_", "ctrl+/":
+		// Terminals send ctrl+/ as ctrl+_
+		if !m.deleteMode {
+			m.toggleDeepSearch()
+			return m, nil
+		}
+	case "ctrl+s":
+		// Toggle "start in search mode" setting while searching; in the table the
+		// key saves a workspace unless rebound
+		if !m.searchMode {
+			break
+		}
+		if m.appConfig != nil {
+			m.appConfig.StartInSearchMode = !m.appConfig.StartInSearchMode
+			config.SaveAppConfig(m.appConfig)
+			// Also toggle current search mode to match
+			if m.appConfig.StartInSearchMode {
+				m.searchMode = true
+				m.searchInput.Focus()
+				m.table.Blur()
+			} else {
+				m.searchMode = false
+				m.searchInput.Blur()
+				m.table.Focus()
+			}
+			m.updateTableStyles()
+		}
+		return m, nil
+	}
+
+	// Dispatch remaining keys through the configurable action bindings
+	if !m.searchMode && !m.deleteMode {
+		kb := m.keyBindings()
+		_, action := m.routeListKey(key)
+		// Bindings name the space bar "space"
+		if key == " " {
+			key = "space"
+		}
+		if key != "esc" && kb.ShouldQuitOnKey(key) {
+			return m, tea.Quit
+		}
+		if cmd, blocked := m.blockedInBrowseMode(action); blocked {
+			return m, cmd
+		}
+
+		switch action {
+		case config.ActionEdit:
 			// Edit the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0])
-				isK8s := isK8sHostFromTableRow(selected[0])
+			entry := m.selectedEntry()
+			if entry != nil {
+				hostName := entry.Name
+				isK8s := entry.IsK8s
 
 				if isK8s {
 					// Edit k8s host
@@ -921,19 +2021,34 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					if err != nil {
 						return m, nil
 					}
+					editForm.interactiveCommands = m.appConfig.InteractiveCommandList()
+					editForm.inputs[12].SetValue(config.FormatHostEnv(m.appConfig.HostEnvFor(editForm.originalName)))
+					completeHostNames(&editForm.inputs[4], m.hosts, editForm.originalName)
 					m.editForm = editForm
 					m.viewMode = ViewEdit
 				}
 				return m, textinput.Blink
 			}
-		}
-	case "m":
-		if !m.searchMode && !m.deleteMode {
-			// Move the selected host to another config file
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
+		case config.ActionMove:
+			// Move the marked hosts, or else the selected one, to another config file
+			if marked := m.markedHostNames(); len(marked) > 0 {
+				moveForm, err := NewMoveFormForHosts(marked, m.styles, m.width, m.height, m.configFile)
+				if err != nil {
+					m.errorMessage = err.Error()
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(3 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				m.moveForm = moveForm
+				m.viewMode = ViewMove
+				return m, textinput.Blink
+			}
+			entry := m.selectedEntry()
+			if entry != nil {
 				// Check if it's a k8s host
-				if isK8sHostFromTableRow(selected[0]) {
+				if entry.IsK8s {
 					m.errorMessage = "Move is not supported for Kubernetes hosts"
 					m.showingError = true
 					return m, func() tea.Msg {
@@ -941,7 +2056,7 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						return errorMsg("clear")
 					}
 				}
-				hostName := extractHostNameFromTableRow(selected[0])
+				hostName := entry.Name
 				moveForm, err := NewMoveForm(hostName, m.styles, m.width, m.height, m.configFile)
 				if err != nil {
 					// Show error message to user
@@ -956,15 +2071,15 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.viewMode = ViewMove
 				return m, textinput.Blink
 			}
-		}
-	case "i":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionMark:
+			return m, m.toggleMark()
+		case config.ActionInfo:
 			// Show info for the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
+			entry := m.selectedEntry()
+			if entry != nil {
 				// Check if it's a k8s host - show basic info in error message for now
-				if isK8sHostFromTableRow(selected[0]) {
-					hostName := extractHostNameFromTableRow(selected[0])
+				if entry.IsK8s {
+					hostName := entry.Name
 					k8sHost, err := config.GetK8sHost(hostName)
 					if err != nil {
 						return m, nil
@@ -982,19 +2097,30 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						return errorMsg("clear")
 					}
 				}
-				hostName := extractHostNameFromTableRow(selected[0])
+				hostName := entry.Name
 				infoForm, err := NewInfoForm(hostName, m.styles, m.width, m.height, m.configFile)
 				if err != nil {
 					// Handle error - could show in UI
 					return m, nil
 				}
+				if m.historyManager != nil {
+					infoForm.lastLogin, infoForm.hasLogin = m.historyManager.GetLastConnectionTime(hostName)
+					infoForm.acceptedKey = m.historyManager.GetAcceptedKey(hostName)
+				}
+				infoForm.maintenance, infoForm.inMaintenance = m.inMaintenance(hostName)
+				infoForm.usageLimit = m.appConfig.UsageLimitFor(hostName)
+				infoForm.envPlan = m.hostEnvPlan(hostName)
+				infoForm.osInfo, infoForm.hasOSInfo = m.osInfo(hostName)
 				m.infoForm = infoForm
 				m.viewMode = ViewInfo
-				return m, nil
+				// The banner is fetched once per session
+				if cached, ok := m.banners[hostName]; ok {
+					infoForm.setBanner(cached.banner, cached.err)
+					return m, nil
+				}
+				return m, fetchBannerCmd(*infoForm.host)
 			}
-		}
-	case "a":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionAdd:
 			// Check if there are multiple config files starting from the current base config
 			var configFiles []string
 			var err error
@@ -1006,8 +2132,20 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				// Use the default config file as base
 				configFiles, err = config.GetAllConfigFiles()
 			}
+			configFiles = config.WritableConfigFiles(configFiles)
+
+			// Include directives may point at files that don't exist yet
+			hasIncludes := false
+			if err == nil {
+				base := m.configFile
+				if base == "" {
+					base, _ = config.GetDefaultSSHConfigPath()
+				}
+				patterns, _ := config.GetIncludePatterns(base)
+				hasIncludes = len(patterns) > 0
+			}
 
-			if err != nil || len(configFiles) <= 1 {
+			if err != nil || (len(configFiles) <= 1 && !hasIncludes) {
 				// Only one config file (or error), go directly to add form
 				var configFile string
 				if len(configFiles) == 1 {
@@ -1016,13 +2154,15 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					configFile = m.configFile
 				}
 				m.addForm = NewAddForm("", m.styles, m.width, m.height, configFile)
+				completeHostNames(&m.addForm.inputs[addProxyJumpInput], m.hosts, "")
 				m.viewMode = ViewAdd
 			} else {
-				// Multiple config files, show file selector
-				fileSelectorForm, err := NewFileSelectorFromBase("Select config file to add host to:", m.styles, m.width, m.height, m.configFile)
+				// Multiple config files or Include targets, show file selector
+				fileSelectorForm, err := NewIncludeTargetSelector("Select config file to add host to:", m.styles, m.width, m.height, m.configFile)
 				if err != nil {
 					// Fallback to default behavior if file selector fails
 					m.addForm = NewAddForm("", m.styles, m.width, m.height, m.configFile)
+					completeHostNames(&m.addForm.inputs[addProxyJumpInput], m.hosts, "")
 					m.viewMode = ViewAdd
 				} else {
 					m.fileSelectorForm = fileSelectorForm
@@ -1030,40 +2170,98 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, textinput.Blink
-		}
-	case "d":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionDelete:
 			// Delete the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0])
-				isK8s := isK8sHostFromTableRow(selected[0])
+			entry := m.selectedEntry()
+			if entry != nil {
+				hostName := entry.Name
+				isK8s := entry.IsK8s
 				m.deleteMode = true
 				m.deleteHost = hostName
 				m.deleteHostIsK8s = isK8s
 				m.table.Blur()
 				return m, nil
 			}
-		}
-	case "K":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionDeleteExpired:
+			// Ask for confirmation before deleting every expired host
+			now := time.Now()
+			var expired []string
+			for _, host := range m.hosts {
+				if host.IsExpired(now) {
+					expired = append(expired, host.Name)
+				}
+			}
+			if len(expired) == 0 {
+				m.errorMessage = "No expired hosts"
+				m.showingError = true
+				return m, func() tea.Msg {
+					time.Sleep(2 * time.Second)
+					return errorMsg("clear")
+				}
+			}
+			m.deleteMode = true
+			m.deleteExpired = expired
+			m.table.Blur()
+			return m, nil
+		case config.ActionK8sAdd:
 			// Add new k8s host
 			m.k8sAddForm = NewK8sAddForm(m.styles, m.width, m.height)
 			m.viewMode = ViewK8sAdd
 			return m, textinput.Blink
-		}
-	case "p":
-		if !m.searchMode && !m.deleteMode {
-			// Ping all hosts
-			return m, m.startPingAllCmd()
-		}
-	case "f":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionPing:
+			// Ping all hosts, or those of the tag filtered by, and count their sessions
+			pinged := m.pingedHosts()
+			cmds := []tea.Cmd{m.startPingAllCmd(pinged), m.startSessionScanCmd()}
+			if m.appConfig != nil && m.appConfig.OSInfoOnPing {
+				cmds = append(cmds, m.startOSInfoCmd(m.staleOSInfoHosts(hostNames(pinged))))
+			}
+			return m, tea.Batch(cmds...)
+		case config.ActionOSInfo:
+			// Probe the OS and uptime of the listed hosts whose info is missing or old
+			hostNames := m.listedSSHHostNames()
+			stale := m.staleOSInfoHosts(hostNames)
+			if len(stale) == 0 {
+				m.errorMessage = fmt.Sprintf("OS info of the %d listed hosts is less than %s old", len(hostNames), formatUptime(m.osInfoTTL()))
+			} else {
+				m.errorMessage = fmt.Sprintf("Reading the OS of %d hosts...", len(stale))
+			}
+			m.showingError = true
+			return m, tea.Batch(m.startOSInfoCmd(stale), func() tea.Msg {
+				time.Sleep(2 * time.Second)
+				return errorMsg("clear")
+			})
+		case config.ActionOpenURL:
+			// Open a web page of the selected host, picking one when it has several
+			entry := m.selectedEntry()
+			if entry != nil {
+				var urls []string
+				hostName := entry.Name
+				if entry.IsK8s {
+					m.errorMessage = "Opening web pages is not supported for Kubernetes hosts"
+				} else if urls = m.hostURLs(hostName); len(urls) == 0 {
+					m.errorMessage = fmt.Sprintf("No URLs for %s; add them under host_urls in config.json", hostName)
+				}
+				if len(urls) == 0 {
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				if len(urls) == 1 {
+					return m, m.openURL(hostName, urls[0])
+				}
+				m.openURLForm = NewOpenURL(hostName, urls, m.styles, m.width, m.height)
+				m.viewMode = ViewOpenURL
+				m.table.Blur()
+				return m, nil
+			}
+		case config.ActionForward:
 			// Port forwarding for the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
+			entry := m.selectedEntry()
+			if entry != nil {
 				// Check if it's a k8s host
-				if isK8sHostFromTableRow(selected[0]) {
+				if entry.IsK8s {
 					m.errorMessage = "Port forwarding is not supported for Kubernetes hosts"
 					m.showingError = true
 					return m, func() tea.Msg {
@@ -1071,19 +2269,17 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						return errorMsg("clear")
 					}
 				}
-				hostName := extractHostNameFromTableRow(selected[0])
+				hostName := entry.Name
 				m.portForwardForm = NewPortForwardForm(hostName, m.styles, m.width, m.height, m.configFile, m.historyManager)
 				m.viewMode = ViewPortForward
 				return m, textinput.Blink
 			}
-		}
-	case "t":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionTransfer:
 			// Quick file transfer for the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
+			entry := m.selectedEntry()
+			if entry != nil {
 				// Check if it's a k8s host
-				if isK8sHostFromTableRow(selected[0]) {
+				if entry.IsK8s {
 					m.errorMessage = "File transfer is not supported for Kubernetes hosts"
 					m.showingError = true
 					return m, func() tea.Msg {
@@ -1091,51 +2287,332 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						return errorMsg("clear")
 					}
 				}
-				hostName := extractHostNameFromTableRow(selected[0])
+				hostName := entry.Name
 				m.quickTransferForm = NewQuickTransfer(hostName, m.styles, m.width, m.height, m.configFile)
 				m.viewMode = ViewQuickTransfer
 				return m, nil
 			}
-		}
-	case "h":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionDualBrowser:
+			// Browse the selected host next to a second one to copy files between them
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "File transfer is not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				var candidates []string
+				for _, host := range m.hosts {
+					candidates = append(candidates, host.Name)
+				}
+				hostName := entry.Name
+				m.dualBrowser = NewDualBrowser(hostName, candidates, m.configFile, m.styles, m.width, m.height)
+				m.viewMode = ViewDualBrowser
+				return m, nil
+			}
+		case config.ActionDashboard:
+			// Probe load, disk and memory of the hosts matching the current filter
+			var hostNames []string
+			for _, host := range m.filteredHosts {
+				hostNames = append(hostNames, host.Name)
+			}
+			m.dashboard = NewDashboard(hostNames, config.BuildJumpGraph(m.hosts), m.appConfig.HealthProbeCommand, m.configFile, m.styles, m.width, m.height)
+			m.viewMode = ViewDashboard
+			return m, m.dashboard.Init()
+		case config.ActionVerboseSSH:
+			// Connect with ssh -v to find out which key the server accepts
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "Verbose connect is not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				hostName := entry.Name
+				if m.blockedByMaintenance(hostName, msg) || m.blockedByConnectRate(hostName, msg) {
+					return m, textinput.Blink
+				}
+				m.connectionHost = hostName
+				m.connectionIsK8s = false
+				m.connectionJump = ""
+				m.connectionError = ""
+				if m.historyManager != nil {
+					m.invalidateRow(hostName)
+					if err := m.historyManager.RecordConnection(hostName); err != nil {
+						fmt.Printf("Warning: Could not record connection history: %v\n", err)
+					}
+				}
+				return m, m.execVerboseSSH(hostName)
+			}
+		case config.ActionOnboard:
+			// Trust the host key, upload a key and test the login of the selected host
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "Onboarding is not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				hostName := entry.Name
+				for _, host := range m.hosts {
+					if host.Name == hostName {
+						m.onboard = NewOnboard(host, m.configFile, m.styles, m.width, m.height)
+						m.viewMode = ViewOnboard
+						return m, m.onboard.Init()
+					}
+				}
+			}
+		case config.ActionSnippets:
+			// Pick a command from the snippet library to run on the selected host
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "Snippets are not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				hostName := entry.Name
+				picker, err := NewSnippetPicker(hostName, m.styles, m.width, m.height)
+				if err != nil {
+					m.errorMessage = fmt.Sprintf("Could not load snippets: %v", err)
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(3 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				m.snippetPicker = picker
+				m.viewMode = ViewSnippetPicker
+				return m, m.snippetPicker.Init()
+			}
+		case config.ActionMount:
+			// Mount the selected host with sshfs, or unmount it when it is mounted
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "Mounting is not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				if m.mounter == nil {
+					m.mounter = transfer.NewMounter()
+				}
+				hostName := entry.Name
+				if mount, ok := m.mounter.Mounted(hostName); ok {
+					m.errorMessage = fmt.Sprintf("Unmounting %s from %s...", hostName, mount.MountPoint)
+					m.showingError = true
+					return m, unmountCmd(m.mounter, hostName)
+				}
+				if err := m.mounter.Check(); err != nil {
+					m.errorMessage = err.Error()
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(4 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				m.mountForm = NewMountForm(hostName, m.styles, m.width, m.height)
+				m.viewMode = ViewMount
+				return m, textinput.Blink
+			}
+		case config.ActionWait:
+			// Probe the selected host until it answers, then connect to it
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "Waiting is not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				hostName := entry.Name
+				if m.blockedByMaintenance(hostName, msg) || m.blockedByConnectRate(hostName, msg) {
+					return m, textinput.Blink
+				}
+				for _, host := range m.hosts {
+					if host.Name != hostName {
+						continue
+					}
+					var timeout time.Duration
+					if m.appConfig != nil {
+						timeout = time.Duration(m.appConfig.WaitTimeout) * time.Second
+					}
+					var cmd tea.Cmd
+					probe := connectivity.HostProbe(host, m.configFile)
+					m.waitView, cmd = NewWaitView(hostName, probe, timeout, m.styles, m.width, m.height)
+					m.viewMode = ViewWait
+					return m, cmd
+				}
+			}
+		case config.ActionVerify:
+			// Compare the selected host with what ssh -G resolves for it
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "Verify is not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				var cmd tea.Cmd
+				m.verifyView, cmd = NewVerifyView(entry.Name, m.configFile, m.styles, m.width, m.height)
+				m.viewMode = ViewVerify
+				return m, cmd
+			}
+		case config.ActionJumpConnect:
+			// Connect through another jump host than the configured one
+			entry := m.selectedEntry()
+			if entry != nil {
+				if entry.IsK8s {
+					m.errorMessage = "Jump hosts are not supported for Kubernetes hosts"
+					m.showingError = true
+					return m, func() tea.Msg {
+						time.Sleep(2 * time.Second)
+						return errorMsg("clear")
+					}
+				}
+				hostName := entry.Name
+				if m.blockedByMaintenance(hostName, msg) || m.blockedByConnectRate(hostName, msg) {
+					return m, textinput.Blink
+				}
+				for _, host := range m.hosts {
+					if host.Name != hostName {
+						continue
+					}
+					last := ""
+					if m.historyManager != nil {
+						last = m.historyManager.GetJumpOverride(hostName)
+					}
+					m.jumpPrompt = NewJumpPrompt(host, m.hosts, last, m.styles, m.width, m.height)
+					m.viewMode = ViewJumpPrompt
+					return m, textinput.Blink
+				}
+			}
+		case config.ActionMessages:
+			// Open the warnings and errors of the session below the list
+			m.toggleDrawer()
+			return m, nil
+
+		case config.ActionSaveWorkspace:
+			// Save the search, sort mode, grouping and columns under a name
+			if m.appConfig == nil {
+				return m, nil
+			}
+			m.workspacePrompt = NewWorkspacePrompt(workspaceNames(m.appConfig), m.activeWorkspace, m.styles, m.width, m.height)
+			m.viewMode = ViewWorkspacePrompt
+			return m, textinput.Blink
+
+		case config.ActionWorkspaces:
+			// Switch to a saved workspace, or delete one
+			names := workspaceNames(m.appConfig)
+			if len(names) == 0 {
+				m.errorMessage = fmt.Sprintf("No workspaces yet, save one with %s", kb.KeyForAction(config.ActionSaveWorkspace))
+				m.showingError = true
+				return m, func() tea.Msg {
+					time.Sleep(2 * time.Second)
+					return errorMsg("clear")
+				}
+			}
+			m.workspacePicker = NewWorkspacePicker(names, m.activeWorkspace, m.styles, m.width, m.height)
+			m.viewMode = ViewWorkspacePicker
+			return m, nil
+
+		case config.ActionColumns:
+			// Show or hide the Tags, Last Login and Description columns
+			m.columnPicker = NewColumnPicker(m.hiddenColumns, m.styles, m.width, m.height)
+			m.viewMode = ViewColumnPicker
+			return m, nil
+
+		case config.ActionReload:
+			// Read the config files again, e.g. after editing them in another window
+			changes, err := m.reloadConfig()
+			if err != nil {
+				m.errorMessage = fmt.Sprintf("Reload failed: %v", err)
+				m.report(severityError, sourceConfig, m.errorMessage)
+			} else {
+				m.errorMessage = "Reloaded: " + changes.String()
+			}
+			m.showingError = true
+			return m, tea.Batch(m.loadAnnotations(), func() tea.Msg {
+				time.Sleep(3 * time.Second)
+				return errorMsg("clear")
+			})
+		case config.ActionHistory:
+			// Show recent transfers, to run one again or prune it
+			if m.historyManager == nil {
+				m.errorMessage = "History is not available"
+				m.showingError = true
+				return m, func() tea.Msg {
+					time.Sleep(2 * time.Second)
+					return errorMsg("clear")
+				}
+			}
+			m.historyView = NewHistoryView(m.historyManager, m.configFile, m.styles, m.width, m.height)
+			m.viewMode = ViewHistory
+			return m, nil
+		case config.ActionHelp:
 			// Show help
-			m.helpForm = NewHelpForm(m.styles, m.width, m.height)
+			m.helpForm = NewHelpForm(m.styles, m.width, m.height, m.keyBindings())
+			m.helpForm.sshVersion = m.sshVersionText()
 			m.viewMode = ViewHelp
 			return m, nil
-		}
-	case "ctrl+s":
-		// Toggle "start in search mode" setting (works in any mode)
-		if m.appConfig != nil {
-			m.appConfig.StartInSearchMode = !m.appConfig.StartInSearchMode
-			config.SaveAppConfig(m.appConfig)
-			// Also toggle current search mode to match
-			if m.appConfig.StartInSearchMode {
-				m.searchMode = true
-				m.searchInput.Focus()
-				m.table.Blur()
-			} else {
-				m.searchMode = false
-				m.searchInput.Blur()
-				m.table.Focus()
+		case config.ActionTagFilter:
+			// Pick one of the selected host's tags to filter by
+			index := m.selectedIndex()
+			entries := m.displayEntries()
+			if index < 0 || index >= len(entries) {
+				return m, nil
 			}
-			m.updateTableStyles()
-		}
-		return m, nil
-	case "c":
-		if !m.searchMode && !m.deleteMode {
+			entry := entries[index]
+			if len(entry.Tags) == 0 {
+				if m.tagFilterActive() {
+					m.toggleTagFilter(m.tagFilter)
+					return m, nil
+				}
+				m.errorMessage = fmt.Sprintf("'%s' has no tags", entry.Name)
+				m.showingError = true
+				return m, func() tea.Msg {
+					time.Sleep(2 * time.Second)
+					return errorMsg("clear")
+				}
+			}
+			activeTag := ""
+			if m.tagFilterActive() {
+				activeTag = m.tagFilter
+			}
+			m.tagPicker = NewTagPicker(entry.Name, entry.Tags, activeTag, m.styles, m.width, m.height)
+			m.viewMode = ViewTagPicker
+			return m, nil
+		case config.ActionTheme:
 			// Open theme picker (c for colors)
 			m.themePicker = NewThemePicker(m.styles, m.width, m.height, m.appConfig)
 			m.viewMode = ViewTheme
 			return m, nil
-		}
-	case "k":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionKeyUpload:
 			// Upload SSH key to the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
+			entry := m.selectedEntry()
+			if entry != nil {
 				// Check if it's a k8s host
-				if isK8sHostFromTableRow(selected[0]) {
+				if entry.IsK8s {
 					m.errorMessage = "SSH key upload is not supported for Kubernetes hosts"
 					m.showingError = true
 					return m, func() tea.Msg {
@@ -1143,14 +2620,12 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						return errorMsg("clear")
 					}
 				}
-				hostName := extractHostNameFromTableRow(selected[0])
+				hostName := entry.Name
 				m.sshKeyUploadForm = NewSSHKeyUploadForm(hostName, m.styles, m.width, m.height, m.configFile)
 				m.viewMode = ViewSSHKeyUpload
 				return m, textinput.Blink
 			}
-		}
-	case "s":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionSortCycle:
 			// Cycle through sort modes (only 2 modes now)
 			m.sortMode = (m.sortMode + 1) % 2
 			m.saveSortMode()
@@ -1162,9 +2637,7 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.updateTableRows()
 			return m, nil
-		}
-	case "r":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionSortRecent:
 			// Switch to sort by recent (last used)
 			m.sortMode = SortByLastUsed
 			m.saveSortMode()
@@ -1176,9 +2649,17 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.updateTableRows()
 			return m, nil
-		}
-	case "n":
-		if !m.searchMode && !m.deleteMode {
+		case config.ActionCollapse:
+			// Show multi-host blocks as one row, or every host again
+			m.toggleCollapseBlocks()
+			return m, nil
+		case config.ActionTimeFormat:
+			// Toggle Last Login between relative and absolute local times
+			m.absoluteTimes = !m.absoluteTimes
+			m.saveTimeFormat()
+			m.updateTableRows()
+			return m, nil
+		case config.ActionSortName:
 			// Switch to sort by name
 			m.sortMode = SortByName
 			m.saveSortMode()
@@ -1190,6 +2671,8 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.updateTableRows()
 			return m, nil
+		case config.ActionSearch:
+			return m.enterSearchMode()
 		}
 	}
 
@@ -1199,25 +2682,129 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.searchInput, cmd = m.searchInput.Update(msg)
 		// Update filtered entries only if the search value has changed
 		if m.searchInput.Value() != oldValue {
-			currentCursor := m.table.Cursor()
-			if m.searchInput.Value() != "" {
-				m.filteredEntries = m.filterEntries(m.searchInput.Value())
+			if len(m.allEntries) <= searchDebounceThreshold && len(m.hosts) <= searchDebounceThreshold {
+				m.applySearchFilter()
 			} else {
-				m.filteredEntries = m.allEntries
-			}
-			m.updateTableRows()
-			// If the current cursor position is beyond the filtered results, reset to 0
-			if currentCursor >= len(m.filteredEntries) && len(m.filteredEntries) > 0 {
-				m.table.SetCursor(0)
+				// Large lists are filtered once typing pauses
+				m.searchSeq++
+				seq := m.searchSeq
+				cmd = tea.Batch(cmd, tea.Tick(searchDebounceDelay, func(time.Time) tea.Msg {
+					return listSearchDebounceMsg{seq: seq}
+				}))
 			}
 		}
 	} else {
 		m.table, cmd = m.table.Update(msg)
+		m.syncRowWindow(msg)
 	}
 
 	return m, cmd
 }
 
+// deleteExpiredHosts deletes the hosts queued by the delete-expired action
+func (m Model) deleteExpiredHosts() (tea.Model, tea.Cmd) {
+	var deleted, failed []string
+	for _, name := range m.deleteExpired {
+		var err error
+		if m.configFile != "" {
+			err = config.DeleteSSHHostFromFile(name, m.configFile)
+		} else {
+			err = config.DeleteSSHHost(name)
+		}
+		if err != nil {
+			failed = append(failed, name)
+		} else {
+			deleted = append(deleted, name)
+		}
+		m.invalidateRow(name)
+	}
+
+	m.deleteMode = false
+	m.deleteExpired = nil
+	m.table.Focus()
+
+	// Refresh SSH hosts
+	var hosts []config.SSHHost
+	if m.configFile != "" {
+		hosts, _ = config.ParseSSHConfigFile(m.configFile)
+	} else {
+		hosts, _ = config.ParseSSHConfig()
+	}
+	m.hosts = m.sortHosts(hosts)
+	m.noteOwnWrite()
+	if m.searchInput.Value() != "" {
+		m.filteredHosts = m.filterHosts(m.searchInput.Value())
+	} else {
+		m.filteredHosts = m.hosts
+	}
+	m.rebuildEntries()
+	m.updateTableRows()
+
+	if len(failed) > 0 {
+		m.errorMessage = fmt.Sprintf("Could not delete: %s", strings.Join(failed, ", "))
+		m.showingError = true
+		return m, func() tea.Msg {
+			time.Sleep(3 * time.Second)
+			return errorMsg("clear")
+		}
+	}
+	return m, m.cleanupOrphans(deleted)
+}
+
+// applySearchFilter filters the host list with the current search input
+func (m *Model) applySearchFilter() {
+	currentCursor := m.selectedIndex()
+	if m.searchInput.Value() != "" {
+		m.filteredHosts = m.filterHosts(m.searchInput.Value())
+		m.filteredEntries = m.filterEntries(m.searchInput.Value())
+	} else {
+		m.filteredHosts = m.hosts
+		m.filteredEntries = m.allEntries
+	}
+	// If the current cursor position is beyond the filtered results, reset to 0
+	if currentCursor >= len(m.displayEntries()) {
+		m.rowOffset = 0
+		m.table.SetCursor(0)
+	}
+	m.updateTableRows()
+}
+
+// tagFilterActive reports whether the search query is still the quick tag filter
+func (m Model) tagFilterActive() bool {
+	return m.tagFilter != "" && m.searchInput.Value() == tagFilterQuery(m.tagFilter)
+}
+
+// toggleTagFilter filters the list by tag, or clears the filter if that tag is already active
+func (m *Model) toggleTagFilter(tag string) {
+	if m.tagFilterActive() && strings.EqualFold(m.tagFilter, tag) {
+		m.tagFilter = ""
+		m.searchInput.SetValue("")
+	} else {
+		m.tagFilter = tag
+		m.searchInput.SetValue(tagFilterQuery(tag))
+	}
+	m.applySearchFilter()
+}
+
+// tagFilterQuery returns the field-scoped search query for a tag, quoted when
+// it has spaces
+func tagFilterQuery(tag string) string {
+	tag = strings.ToLower(tag)
+	if strings.ContainsAny(tag, " \t") {
+		tag = `"` + tag + `"`
+	}
+	return "tag:" + tag
+}
+
+// enterSearchMode focuses the search input without filtering until the user types
+func (m Model) enterSearchMode() (tea.Model, tea.Cmd) {
+	m.searchMode = true
+	m.updateTableStyles()
+	m.table.Blur()
+	m.searchInput.Focus()
+	return m, textinput.Blink
+}
+
 // handleConnectionErrorKeys handles key presses in the connection error view
 func (m Model) handleConnectionErrorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
@@ -1234,21 +2821,16 @@ func (m Model) handleConnectionErrorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.connectionError = err.Error()
 				return m, nil
 			}
+			if k8sHost.Pod == "" {
+				m.connectionError = fmt.Sprintf("no pod set for '%s', edit the host to choose one", m.connectionHost)
+				return m, nil
+			}
 			kubectlCmd := k8sHost.BuildKubectlCommand()
-			return m, tea.ExecProcess(kubectlCmd, func(err error) tea.Msg {
+			return m, execProcess(kubectlCmd, func(err error) tea.Msg {
 				return sshConnectionResultMsg{err: err}
 			})
 		} else {
-			// Build the SSH command with the appropriate config file
-			var sshCmd *exec.Cmd
-			if m.configFile != "" {
-				sshCmd = exec.Command("ssh", "-F", m.configFile, m.connectionHost)
-			} else {
-				sshCmd = exec.Command("ssh", m.connectionHost)
-			}
-			return m, tea.ExecProcess(sshCmd, func(err error) tea.Msg {
-				return sshConnectionResultMsg{err: err}
-			})
+			return m, m.execSSHVia(m.connectionHost, m.connectionJump)
 		}
 
 	case "esc", "q", "ctrl+c":
@@ -1256,6 +2838,7 @@ func (m Model) handleConnectionErrorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.viewMode = ViewList
 		m.connectionHost = ""
 		m.connectionIsK8s = false
+		m.connectionJump = ""
 		m.connectionError = ""
 		m.table.Focus()
 		return m, nil
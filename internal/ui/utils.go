@@ -1,12 +1,30 @@
 package ui
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/xvertile/sshc/internal/config"
 	"github.com/xvertile/sshc/internal/connectivity"
+	"github.com/xvertile/sshc/internal/history"
+	"github.com/xvertile/sshc/internal/hooks"
+	"github.com/xvertile/sshc/internal/keys"
+	sshclog "github.com/xvertile/sshc/internal/log"
+	"github.com/xvertile/sshc/internal/sshauth"
+	"github.com/xvertile/sshc/internal/sshenv"
+	"github.com/xvertile/sshc/internal/sshver"
+	"github.com/xvertile/sshc/internal/termtitle"
+	"github.com/xvertile/sshc/internal/validation"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // saveSortMode persists the current sort mode to config
@@ -24,9 +42,85 @@ func (m *Model) saveSortMode() {
 	config.SaveAppConfig(m.appConfig)
 }
 
+// saveTimeFormat saves the Last Login time format to the application configuration
+func (m *Model) saveTimeFormat() {
+	if m.appConfig == nil {
+		return
+	}
+
+	m.appConfig.TimeFormat = "relative"
+	if m.absoluteTimes {
+		m.appConfig.TimeFormat = "absolute"
+	}
+	config.SaveAppConfig(m.appConfig)
+}
+
+// keyBindings returns the configured key bindings, or the defaults if no config is loaded
+func (m Model) keyBindings() config.KeyBindings {
+	if m.appConfig == nil {
+		return config.GetDefaultKeyBindings()
+	}
+	return m.appConfig.KeyBindings
+}
+
+// sshVersionText describes the detected ssh client for display
+func (m Model) sshVersionText() string {
+	switch {
+	case !m.sshVersionKnown:
+		return "detecting…"
+	case errors.Is(m.sshVersionErr, sshver.ErrNotInstalled):
+		return "not installed"
+	case m.sshVersionErr != nil:
+		return "unknown (" + m.sshVersionErr.Error() + ")"
+	}
+	return m.sshVersion.String()
+}
+
+// debugLogf writes to the log file at debug level, and to the standard logger when
+// SSHC_DEBUG is set
+func debugLogf(format string, args ...any) {
+	if sshclog.Enabled(slog.LevelDebug) {
+		sshclog.Debug(fmt.Sprintf(format, args...))
+	}
+	if os.Getenv("SSHC_DEBUG") == "" {
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// absoluteTimeLayout is the format of absolute timestamps in the host list
+const absoluteTimeLayout = "2006-01-02 15:04"
+
+// formatAbsoluteTime formats a time in the given location, e.g. "2024-05-02 14:31"
+func formatAbsoluteTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(absoluteTimeLayout)
+}
+
+// formatLoginDetails formats a timestamp relative to now, in local time with its
+// zone and in UTC, e.g. "3 days ago · 2024-05-02 14:31 CEST · 2024-05-02 12:31 UTC"
+func formatLoginDetails(t, now time.Time, loc *time.Location) string {
+	local := t.In(loc)
+	return fmt.Sprintf("%s · %s %s · %s UTC",
+		formatTimeSince(t, now),
+		local.Format(absoluteTimeLayout), local.Format("MST"),
+		formatAbsoluteTime(t, time.UTC))
+}
+
+// formatLastLogin formats a Last Login cell in the list's current time format
+func (m *Model) formatLastLogin(t time.Time) string {
+	if m.absoluteTimes {
+		return formatAbsoluteTime(t, time.Local)
+	}
+	return formatTimeAgo(t)
+}
+
 // formatTimeAgo formats a time into a readable "X time ago" string
 func formatTimeAgo(t time.Time) string {
-	now := time.Now()
+	return formatTimeSince(t, time.Now())
+}
+
+// formatTimeSince formats the time elapsed between t and now as "X time ago"
+func formatTimeSince(t, now time.Time) string {
 	duration := now.Sub(t)
 
 	switch {
@@ -75,6 +169,36 @@ func formatTimeAgo(t time.Time) string {
 	}
 }
 
+// timeSinceChangesAt returns when formatTimeSince(t, now) next reads differently
+func timeSinceChangesAt(t, now time.Time) time.Time {
+	steps := []struct{ limit, unit time.Duration }{
+		{time.Minute, time.Second},
+		{time.Hour, time.Minute},
+		{24 * time.Hour, time.Hour},
+		{7 * 24 * time.Hour, 24 * time.Hour},
+		{30 * 24 * time.Hour, 7 * 24 * time.Hour},
+		{365 * 24 * time.Hour, 30 * 24 * time.Hour},
+	}
+	duration := now.Sub(t)
+	for _, step := range steps {
+		if duration < step.limit {
+			next := (duration/step.unit + 1) * step.unit
+			return t.Add(min(next, step.limit))
+		}
+	}
+	unit := 365 * 24 * time.Hour
+	return t.Add((duration/unit + 1) * unit)
+}
+
+// saveErrorText describes a failed save in a form, pointing out when trying
+// again may work since the form keeps what was typed
+func saveErrorText(err error) string {
+	if config.IsTransientWriteError(err) {
+		return err.Error() + " - your input is kept, ctrl+s tries again"
+	}
+	return err.Error()
+}
+
 // formatConfigFile formats a config file path for display
 func formatConfigFile(filePath string) string {
 	if filePath == "" {
@@ -88,6 +212,16 @@ func formatConfigFile(filePath string) string {
 	return filePath
 }
 
+// formatConfigFileTarget formats a config file like formatConfigFile, followed by
+// the file changes are written to when it is a symlink
+func formatConfigFileTarget(filePath string) string {
+	target, ok := config.SymlinkTarget(filePath)
+	if !ok {
+		return formatConfigFile(filePath)
+	}
+	return fmt.Sprintf("%s → %s (symlink)", formatConfigFile(filePath), abbreviateHome(target))
+}
+
 // getPingStatusIndicator returns a status indicator based on ping status
 func (m *Model) getPingStatusIndicator(hostName string) string {
 	if m.pingManager == nil {
@@ -107,26 +241,6 @@ func (m *Model) getPingStatusIndicator(hostName string) string {
 	}
 }
 
-// extractHostNameFromTableRow extracts the host name from the first column,
-// removing the status indicator
-func extractHostNameFromTableRow(firstColumn string) string {
-	// The first column format is: "● hostname" or "○ hostname" or "k hostname" etc.
-	// We need to remove the indicator and space to get just the hostname
-	parts := strings.Fields(firstColumn)
-	if len(parts) >= 2 {
-		// Return everything after the first part (the indicator)
-		return strings.Join(parts[1:], " ")
-	}
-	// Fallback: if there's no space, return the whole string
-	return firstColumn
-}
-
-// isK8sHostFromTableRow checks if the selected row is a k8s host based on the icon
-func isK8sHostFromTableRow(firstColumn string) bool {
-	// K8s hosts have the "k" prefix
-	return strings.HasPrefix(firstColumn, "k ")
-}
-
 // getHostEntryByName finds a host entry by name from the filtered entries
 func (m *Model) getHostEntryByName(name string) *HostEntry {
 	for i := range m.filteredEntries {
@@ -168,3 +282,265 @@ func (m *Model) rebuildEntries() {
 	m.allEntries = allEntries
 	m.filteredEntries = allEntries
 }
+
+// hintedCommand is an exec command that prints a one-line hint to the terminal before it runs.
+// Connections also run the configured connect hooks around it and set the window title.
+type hintedCommand struct {
+	*exec.Cmd
+	hint string
+
+	connectHooks hooks.Hooks
+	hookTarget   hooks.Target
+
+	title string // Terminal title while the command runs, "" to leave it alone
+
+	pause bool // Wait for Enter after the command so its output can be read
+
+	authLog     string       // ssh -E log to read the accepted key from, "" when not verbose
+	acceptedKey *sshauth.Key // Key the server accepted, read from authLog
+
+	stderrTail tailBuffer // The end of what ssh printed, to tell its own failures apart
+}
+
+// tailBufferSize is how much of a command's error output tailBuffer keeps
+const tailBufferSize = 4096
+
+// tailBuffer keeps the last tailBufferSize bytes written to it
+type tailBuffer struct {
+	data []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if over := len(b.data) - tailBufferSize; over > 0 {
+		b.data = b.data[over:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.data)
+}
+
+// clientFailed reports whether the command exited with ssh's error status and ssh
+// printed that it could not connect or lost the connection
+func (c *hintedCommand) clientFailed(err error) bool {
+	return hooks.ExitCode(err) == hooks.SSHErrorExitCode && connectivity.ClientFailed(c.stderrTail.String())
+}
+
+func (c *hintedCommand) SetStdin(r io.Reader) {
+	if c.Stdin == nil {
+		c.Stdin = r
+	}
+}
+
+func (c *hintedCommand) SetStdout(w io.Writer) {
+	if c.Stdout == nil {
+		c.Stdout = w
+	}
+}
+
+func (c *hintedCommand) SetStderr(w io.Writer) {
+	if c.Stderr == nil {
+		c.Stderr = w
+	}
+}
+
+func (c *hintedCommand) Run() error {
+	if c.hint != "" && c.Stderr != nil {
+		fmt.Fprintln(c.Stderr, c.hint)
+	}
+	if c.Stderr != nil {
+		c.Stderr = io.MultiWriter(c.Stderr, &c.stderrTail)
+		// A ControlPersist master keeping the output open must not hold up the return
+		c.WaitDelay = time.Second
+	}
+	if err := c.connectHooks.RunPre(c.hookTarget, c.Stdout); err != nil {
+		return err
+	}
+
+	// The title is restored on the writer it was set on, while it is still the terminal's
+	if c.title != "" {
+		termtitle.Set(c.Stdout, c.title)
+	}
+	err := c.Cmd.Run()
+	if c.title != "" {
+		termtitle.Restore(c.Stdout)
+	}
+	if postErr := c.connectHooks.RunPost(c.hookTarget, hooks.ExitCode(err), c.Stdout); postErr != nil && c.Stderr != nil {
+		fmt.Fprintf(c.Stderr, "sshc: %v\n", postErr)
+	}
+	if c.authLog != "" && c.Stderr != nil {
+		c.reportAuth()
+	}
+	if c.pause && c.Stdin != nil && c.Stdout != nil {
+		if err != nil {
+			fmt.Fprintf(c.Stdout, "\nsshc: command failed: %v\n", err)
+		}
+		fmt.Fprint(c.Stdout, "\nPress Enter to return to sshc...")
+		bufio.NewReader(c.Stdin).ReadString('\n')
+	}
+	return err
+}
+
+// reportAuth prints what ssh would have printed without -E, and which key the server
+// accepted
+func (c *hintedCommand) reportAuth() {
+	result, err := sshauth.ParseFile(c.authLog)
+	if err != nil {
+		fmt.Fprintf(c.Stderr, "sshc: could not read the ssh log: %v\n", err)
+		return
+	}
+	for _, message := range result.Messages {
+		fmt.Fprintln(c.Stderr, message)
+	}
+	switch {
+	case result.Accepted != nil:
+		c.acceptedKey = result.Accepted
+		fmt.Fprintf(c.Stderr, "sshc: the server accepted %s\n", result.Accepted)
+	case result.Method != "":
+		fmt.Fprintf(c.Stderr, "sshc: authenticated with %s, no key was accepted\n", result.Method)
+	}
+}
+
+// execSSH connects to an SSH host, warning first when its configuration will likely
+// give a session that exits immediately
+func (m *Model) execSSH(hostName string) tea.Cmd {
+	return m.execSSHVia(hostName, "")
+}
+
+// execSSHVia connects like execSSH, through jump instead of the host's own
+// ProxyJump or ProxyCommand when jump is not ""
+func (m *Model) execSSHVia(hostName, jump string) tea.Cmd {
+	cmd := m.connectCommand(hostName, jumpArgs(jump, m.sshDestination(hostName)))
+	cmd.hint = m.connectHint(hostName)
+	if jump != "" {
+		cmd.hint = strings.TrimPrefix(cmd.hint+"\nsshc: connecting to "+hostName+" through "+jump, "\n")
+	}
+
+	return execHinted(cmd, func(err error) tea.Msg {
+		return sshConnectionResultMsg{err: err, clientFailed: cmd.clientFailed(err)}
+	})
+}
+
+// execVerboseSSH connects like execSSH with ssh -v logging to a temporary file, so the
+// session is not cluttered, and records the key the server accepted in the history
+func (m *Model) execVerboseSSH(hostName string) tea.Cmd {
+	logFile, err := os.CreateTemp("", "sshc-ssh-*.log")
+	if err != nil {
+		return m.execSSH(hostName)
+	}
+	logFile.Close()
+
+	cmd := m.connectCommand(hostName, append([]string{"-v", "-E", logFile.Name()}, m.sshDestination(hostName)...))
+	cmd.hint = m.connectHint(hostName)
+	cmd.authLog = logFile.Name()
+	historyManager := m.historyManager
+
+	return execHinted(cmd, func(err error) tea.Msg {
+		os.Remove(cmd.authLog)
+		if key := cmd.acceptedKey; key != nil && historyManager != nil {
+			historyManager.RecordAcceptedKey(hostName, history.AcceptedKey{
+				Path:        key.Path,
+				Type:        key.Type,
+				Fingerprint: key.Fingerprint,
+			})
+		}
+		return sshConnectionResultMsg{err: err, clientFailed: cmd.clientFailed(err)}
+	})
+}
+
+// execSnippet runs a snippet on an SSH host and returns to the TUI when it finishes
+func (m *Model) execSnippet(hostName string, snippet config.Snippet) tea.Cmd {
+	var args []string
+	if snippet.TTY {
+		args = append(args, "-t")
+	}
+	for _, host := range m.hosts {
+		if host.Name == hostName && host.RemoteCommand != "" {
+			// ssh refuses a command on the command line when the host has a RemoteCommand
+			args = append(args, "-o", "RemoteCommand=none")
+			break
+		}
+	}
+	args = append(args, hostName, snippet.Command)
+
+	cmd := m.connectCommand(hostName, args)
+	cmd.hint = fmt.Sprintf("sshc: running %q on %s", snippet.Name, hostName)
+	cmd.pause = snippet.Pause
+
+	return execHinted(cmd, func(err error) tea.Msg {
+		return snippetResultMsg{hostName: hostName, snippet: snippet, err: err}
+	})
+}
+
+// jumpArgs prepends -J jump to the ssh arguments. ssh takes the first value it
+// obtains for an option and reads the command line before any config, so the
+// jump replaces a configured ProxyJump, and ProxyCommand as ssh uses only one.
+func jumpArgs(jump string, args []string) []string {
+	if jump == "" {
+		return args
+	}
+	return append([]string{"-J", jump}, args...)
+}
+
+// connectCommand builds the ssh command for a host with the connect hooks and window
+// title set up. args are passed to ssh after the config file option and the
+// options of the host's environment overrides. A browsed host comes from a peer,
+// so it gets neither the hooks nor the SetEnv of the local config.
+func (m *Model) connectCommand(hostName string, args []string) *hintedCommand {
+	envPlan := m.hostEnvPlan(hostName)
+	setEnvPlan := envPlan
+	if !m.browsing() {
+		setEnvPlan = sshenv.MergeConfigSetEnv(envPlan, hostName, m.configFile)
+	}
+	args = append(sshenv.Args(setEnvPlan), args...)
+	if m.configFile != "" {
+		args = append([]string{"-F", m.configFile}, args...)
+	}
+
+	cmd := &hintedCommand{
+		Cmd:        exec.Command("ssh", args...),
+		hookTarget: hooks.Target{Host: hostName},
+	}
+	if !m.browsing() {
+		cmd.connectHooks = hooks.FromAppConfig(m.appConfig)
+	}
+	cmd.Env = sshenv.Environ(envPlan, os.Environ())
+	for _, host := range m.hosts {
+		if host.Name == hostName {
+			cmd.hookTarget.Hostname = host.Hostname
+			cmd.hookTarget.User = host.User
+			break
+		}
+	}
+	if template := termtitle.Template(m.appConfig); template != "" {
+		cmd.title = termtitle.Format(template, hostName, cmd.hookTarget.Hostname, cmd.hookTarget.User)
+	}
+	return cmd
+}
+
+// hostEnvPlan decides how the environment overrides of a host are passed to ssh,
+// with SetEnv only once the client is known to support it
+func (m *Model) hostEnvPlan(hostName string) []sshenv.Override {
+	return sshenv.Plan(m.appConfig.HostEnvFor(hostName), m.sshVersion, m.sshVersionKnown && m.sshVersionErr == nil)
+}
+
+// connectHint returns warnings about the host's configuration to show before connecting, or ""
+func (m *Model) connectHint(hostName string) string {
+	for _, host := range m.hosts {
+		if host.Name != hostName {
+			continue
+		}
+		var hints []string
+		interactive := m.appConfig.InteractiveCommandList()
+		if validation.RemoteCommandNeedsTTY(host.RemoteCommand, host.RequestTTY, interactive) {
+			hints = append(hints, fmt.Sprintf("sshc: RemoteCommand %q needs a terminal but RequestTTY is not set; the session may exit at once (set RequestTTY yes)", host.RemoteCommand))
+		}
+		if keys.NeedsPassphrase(host.Identity) {
+			hints = append(hints, fmt.Sprintf("sshc: %s is passphrase-protected and not in the agent; to skip this prompt next time run: %s", host.Identity, keys.AddCommandLine(host.Identity)))
+		}
+		return strings.Join(hints, "\n")
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+	_ "time/tzdata" // Fixed zones must not depend on the system zoneinfo
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q) error = %v", name, err)
+	}
+	return loc
+}
+
+func TestFormatAbsoluteTimeAcrossDST(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name string
+		t    time.Time
+		loc  *time.Location
+		want string
+	}{
+		{"before spring forward", time.Date(2024, 3, 31, 0, 30, 0, 0, time.UTC), berlin, "2024-03-31 01:30"},
+		{"after spring forward", time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC), berlin, "2024-03-31 03:30"},
+		{"before fall back", time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), newYork, "2024-11-03 01:30"},
+		{"after fall back", time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC), newYork, "2024-11-03 01:30"},
+		{"utc", time.Date(2024, 5, 2, 14, 31, 0, 0, berlin), time.UTC, "2024-05-02 12:31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAbsoluteTime(tt.t, tt.loc); got != tt.want {
+				t.Errorf("formatAbsoluteTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLoginDetailsAcrossDST(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name string
+		t    time.Time
+		now  time.Time
+		loc  *time.Location
+		want string
+	}{
+		{
+			// The repeated 01:30 is told apart by its zone, the elapsed time ignores the wall clock
+			name: "repeated hour at fall back",
+			t:    time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+			now:  time.Date(2024, 11, 3, 7, 30, 0, 0, time.UTC),
+			loc:  newYork,
+			want: "2 hours ago · 2024-11-03 01:30 EDT · 2024-11-03 05:30 UTC",
+		},
+		{
+			name: "after fall back",
+			t:    time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC),
+			now:  time.Date(2024, 11, 3, 7, 30, 0, 0, time.UTC),
+			loc:  newYork,
+			want: "1 hour ago · 2024-11-03 01:30 EST · 2024-11-03 06:30 UTC",
+		},
+		{
+			// Noon to noon across spring forward is only 23 hours
+			name: "short day at spring forward",
+			t:    time.Date(2024, 3, 30, 12, 0, 0, 0, berlin),
+			now:  time.Date(2024, 3, 31, 12, 0, 0, 0, berlin),
+			loc:  berlin,
+			want: "23 hours ago · 2024-03-30 12:00 CET · 2024-03-30 11:00 UTC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatLoginDetails(tt.t, tt.now, tt.loc); got != tt.want {
+				t.Errorf("formatLoginDetails() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectionDoesNotDependOnRowText(t *testing.T) {
+	m := createLargeTestModel(3)
+	// Names that look like the decorations rows get, on hosts that have them too
+	m.hosts[1].Name = "k ~proxy"
+	m.hosts[2].Name = "[U]"
+	m.filteredHosts = m.hosts
+	m.marked = map[string]bool{"k ~proxy": true, "[U]": true}
+	m.rebuildEntries()
+	m.updateTableRows()
+
+	for i, want := range []string{"node-0000", "k ~proxy", "[U]"} {
+		m.table.SetCursor(i)
+		entry := m.selectedEntry()
+		if entry == nil || entry.Name != want || entry.IsK8s {
+			t.Errorf("row %d: selectedEntry() = %+v, want SSH host %q", i, entry, want)
+		}
+	}
+}
+
+func TestHintedCommandTellsSSHFailuresApart(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	tests := []struct {
+		name   string
+		script string
+		want   bool
+	}{
+		{"could not connect", `echo "ssh: connect to host web port 22: Connection timed out" >&2; exit 255`, true},
+		{"remote command exited with 255", `echo "deploy failed" >&2; exit 255`, false},
+		{"ssh error with another status", `echo "Connection refused" >&2; exit 1`, false},
+	}
+	for _, tt := range tests {
+		cmd := &hintedCommand{Cmd: exec.Command("sh", "-c", tt.script)}
+		cmd.SetStderr(io.Discard)
+		err := cmd.Run()
+		if got := cmd.clientFailed(err); got != tt.want {
+			t.Errorf("%s: clientFailed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTimeSinceChangesAt(t *testing.T) {
+	base := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	elapsed := []time.Duration{
+		30 * time.Second,
+		59*time.Minute + 30*time.Second,
+		5*time.Hour + 20*time.Minute,
+		6*24*time.Hour + time.Hour,
+		29 * 24 * time.Hour,
+		200 * 24 * time.Hour,
+		3 * 365 * 24 * time.Hour,
+	}
+
+	for _, d := range elapsed {
+		now := base.Add(d)
+		changesAt := timeSinceChangesAt(base, now)
+		if !changesAt.After(now) {
+			t.Fatalf("timeSinceChangesAt() after %v = %v, want a later time than %v", d, changesAt, now)
+		}
+		text := formatTimeSince(base, now)
+		if got := formatTimeSince(base, changesAt.Add(-time.Millisecond)); got != text {
+			t.Errorf("after %v: text just before the change = %q, want %q", d, got, text)
+		}
+		if got := formatTimeSince(base, changesAt); got == text {
+			t.Errorf("after %v: text at the change is still %q", d, got)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Verification hooks, replaced in tests
+var (
+	resolveWithSSH = config.ResolveWithSSH
+	adoptDrift     = config.AdoptDrift
+)
+
+// verifyViewModel compares what ssh -G resolves for a host with what sshc shows,
+// and writes the value ssh uses into the host's block on request
+type verifyViewModel struct {
+	hostName      string
+	configFile    string
+	drifts        []config.Drift
+	selectedIndex int
+	checking      bool
+	confirmAdopt  bool
+	adopted       bool // A value was written, so the host list needs reloading
+	status        string
+	err           string
+
+	styles Styles
+	width  int
+	height int
+}
+
+// verifyResultMsg carries the outcome of comparing a host with ssh -G
+type verifyResultMsg struct {
+	hostName string
+	drifts   []config.Drift
+	err      error
+}
+
+// verifyCloseMsg closes the verify view, reporting whether the config changed
+type verifyCloseMsg struct {
+	adopted bool
+}
+
+// NewVerifyView opens the verify view of a host and starts comparing it with ssh -G
+func NewVerifyView(hostName, configFile string, styles Styles, width, height int) (*verifyViewModel, tea.Cmd) {
+	m := &verifyViewModel{
+		hostName:   hostName,
+		configFile: configFile,
+		checking:   true,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+	return m, m.check()
+}
+
+// check resolves the host the way sshc does and the way ssh does, and compares them
+func (m *verifyViewModel) check() tea.Cmd {
+	hostName, configFile := m.hostName, m.configFile
+	return func() tea.Msg {
+		configPath := configFile
+		if configPath == "" {
+			var err error
+			if configPath, err = config.GetDefaultSSHConfigPath(); err != nil {
+				return verifyResultMsg{hostName: hostName, err: err}
+			}
+		}
+		effective, err := config.ResolveEffectiveHost(hostName, configPath)
+		if err != nil {
+			return verifyResultMsg{hostName: hostName, err: err}
+		}
+		resolved, err := resolveWithSSH(context.Background(), hostName, configFile)
+		if err != nil {
+			return verifyResultMsg{hostName: hostName, err: err}
+		}
+
+		// ssh only reads the system-wide config when no -F is given
+		var system *config.EffectiveHost
+		if configFile == "" {
+			system, _ = config.ResolveEffectiveHost(hostName, config.SystemSSHConfigPath)
+		}
+		return verifyResultMsg{hostName: hostName, drifts: config.DiffResolved(effective, resolved, system)}
+	}
+}
+
+func (m *verifyViewModel) Update(msg tea.Msg) (*verifyViewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case verifyResultMsg:
+		if msg.hostName != m.hostName {
+			return m, nil
+		}
+		m.checking = false
+		if msg.err != nil {
+			m.drifts, m.err = nil, msg.err.Error()
+			return m, nil
+		}
+		m.drifts = msg.drifts
+		if m.selectedIndex >= len(m.drifts) {
+			m.selectedIndex = max(len(m.drifts)-1, 0)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.confirmAdopt {
+			return m.updateConfirmAdopt(msg)
+		}
+		return m.updateList(msg)
+	}
+	return m, nil
+}
+
+// updateList handles keys while browsing the differences
+func (m *verifyViewModel) updateList(msg tea.KeyMsg) (*verifyViewModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc", "q":
+		adopted := m.adopted
+		return m, func() tea.Msg { return verifyCloseMsg{adopted: adopted} }
+
+	case "up", "k":
+		if m.selectedIndex > 0 {
+			m.selectedIndex--
+		}
+
+	case "down", "j":
+		if m.selectedIndex < len(m.drifts)-1 {
+			m.selectedIndex++
+		}
+
+	case "r":
+		if !m.checking {
+			m.checking = true
+			m.status, m.err = "", ""
+			return m, m.check()
+		}
+
+	case "enter", "a":
+		if m.checking || len(m.drifts) == 0 {
+			return m, nil
+		}
+		if drift := m.drifts[m.selectedIndex]; !drift.Adoptable() {
+			m.status, m.err = "", fmt.Sprintf("%s is collected from every matching block; edit the host to change it", drift.Keyword)
+			return m, nil
+		}
+		m.confirmAdopt = true
+		m.status, m.err = "", ""
+	}
+	return m, nil
+}
+
+// updateConfirmAdopt asks before writing the selected value into the host's block
+func (m *verifyViewModel) updateConfirmAdopt(msg tea.KeyMsg) (*verifyViewModel, tea.Cmd) {
+	m.confirmAdopt = false
+	switch msg.String() {
+	case "y", "Y":
+		drift := m.drifts[m.selectedIndex]
+		if err := adoptDrift(m.hostName, drift, m.configFile); err != nil {
+			m.err = fmt.Sprintf("Could not adopt %s: %v", drift.Keyword, err)
+			return m, nil
+		}
+		m.adopted = true
+		m.status = fmt.Sprintf("Wrote %s %s into %s", drift.Keyword, drift.SSHG, m.hostName)
+		m.checking = true
+		return m, m.check()
+	}
+	return m, nil
+}
+
+func (m *verifyViewModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error))
+	successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Success))
+
+	body := []string{titleStyle.Render("Verify " + m.hostName + " against ssh -G"), ""}
+	body = append(body, m.driftLines()...)
+
+	switch {
+	case m.err != "":
+		body = append(body, "", errorStyle.Render(m.err))
+	case m.status != "":
+		body = append(body, "", successStyle.Render(m.status))
+	}
+
+	help := "Enter: adopt the ssh value • r: check again • Esc: close"
+	if m.confirmAdopt {
+		drift := m.drifts[m.selectedIndex]
+		help = fmt.Sprintf("Write %s %s into the block of %s? (y/N)", drift.Keyword, drift.SSHG, m.hostName)
+	}
+	body = append(body, "", helpStyle.Render(ansi.Truncate(help, max(m.width-10, 20), "…")))
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		container.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+	)
+}
+
+// driftLines renders the differences as a table of option, sshc value, ssh -G
+// value and probable source, with the source of the selected one on its own line
+func (m *verifyViewModel) driftLines() []string {
+	theme := GetCurrentTheme()
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true).Padding(0, 1)
+
+	switch {
+	case m.checking:
+		return []string{mutedStyle.Render("Running ssh -G " + m.hostName + "...")}
+	case m.err != "":
+		return nil
+	case len(m.drifts) == 0:
+		return []string{mutedStyle.Render("ssh resolves the host just as sshc shows it")}
+	}
+
+	valueWidth := max((m.width-60)/2, 12)
+	keywordWidth := len("Option")
+	for _, drift := range m.drifts {
+		keywordWidth = max(keywordWidth, len(drift.Keyword))
+	}
+	cell := func(value string) string {
+		if value == "" {
+			value = "(not set)"
+		}
+		value = ansi.Truncate(value, valueWidth, "…")
+		return value + strings.Repeat(" ", max(valueWidth-ansi.StringWidth(value), 0))
+	}
+
+	lines := []string{headerStyle.Render(fmt.Sprintf("%-*s  %s  %s", keywordWidth, "Option", cell("sshc"), cell("ssh -G")))}
+
+	// Leave room for the border, title, header, source, status and help lines
+	visible := max(m.height-16, 3)
+	start := 0
+	if m.selectedIndex >= visible {
+		start = m.selectedIndex - visible + 1
+	}
+	end := min(start+visible, len(m.drifts))
+	for i := start; i < end; i++ {
+		drift := m.drifts[i]
+		line := fmt.Sprintf("%-*s  %s  %s", keywordWidth, drift.Keyword, cell(drift.SSHC), cell(drift.SSHG))
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground)).Padding(0, 1)
+		if i == m.selectedIndex {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 1)
+		}
+		lines = append(lines, style.Render(line))
+		if i == m.selectedIndex {
+			lines = append(lines, mutedStyle.Render("    from "+ansi.Truncate(abbreviateHome(drift.Source), max(m.width-20, 20), "…")))
+		}
+	}
+	return lines
+}
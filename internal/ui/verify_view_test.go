@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestVerifyViewShowsAndAdoptsDrift(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configFile, []byte("Host web\n    HostName web.example.com\n    User deploy\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	originalResolve, originalAdopt := resolveWithSSH, adoptDrift
+	defer func() { resolveWithSSH, adoptDrift = originalResolve, originalAdopt }()
+	sshUser := "admin"
+	resolveWithSSH = func(ctx context.Context, hostName, configFile string) (config.ResolvedConfig, error) {
+		return config.ResolvedConfig{"hostname": {"web.example.com"}, "user": {sshUser}}, nil
+	}
+	var adopted []config.Drift
+	adoptDrift = func(hostName string, drift config.Drift, configFile string) error {
+		adopted = append(adopted, drift)
+		sshUser = "deploy"
+		return nil
+	}
+
+	m, cmd := NewVerifyView("web", configFile, NewStyles(120), 120, 40)
+	m, _ = m.Update(cmd())
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "User") || !strings.Contains(view, "deploy") || !strings.Contains(view, "admin") {
+		t.Fatalf("Expected the User drift, got:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.confirmAdopt {
+		t.Fatal("Expected a confirmation before adopting")
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if len(adopted) != 1 || adopted[0].Keyword != "User" || adopted[0].SSHG != "admin" {
+		t.Fatalf("adopted = %+v", adopted)
+	}
+
+	// The check runs again, and closing reports the change so the list reloads
+	m, _ = m.Update(cmd())
+	if len(m.drifts) != 0 {
+		t.Errorf("drifts after adopting = %+v", m.drifts)
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(verifyCloseMsg); !ok || !msg.adopted {
+		t.Errorf("Expected a close reporting the change, got %#v", msg)
+	}
+}
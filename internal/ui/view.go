@@ -4,11 +4,23 @@ import (
 	"fmt"
 	"strings"
 
+	sshclog "github.com/xvertile/sshc/internal/log"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
-// View renders the complete user interface
+// View renders the complete user interface, with the debug overlay over it when
+// it is open
 func (m Model) View() string {
+	defer sshclog.Recover()
+	view := m.view()
+	if m.debugOverlay != nil && m.ready {
+		return placeTopRight(view, m.debugOverlay.View(len(m.hosts)), m.width)
+	}
+	return view
+}
+
+func (m Model) view() string {
 	if !m.ready {
 		return "Loading..."
 	}
@@ -67,6 +79,86 @@ func (m Model) View() string {
 		if m.themePicker != nil {
 			return m.themePicker.View()
 		}
+	case ViewTagPicker:
+		if m.tagPicker != nil {
+			return m.tagPicker.View()
+		}
+	case ViewDualBrowser:
+		if m.dualBrowser != nil {
+			return m.dualBrowser.View()
+		}
+	case ViewColorPicker:
+		if m.colorPicker != nil {
+			return m.colorPicker.View()
+		}
+	case ViewDashboard:
+		if m.dashboard != nil {
+			return m.dashboard.View()
+		}
+	case ViewOnboard:
+		if m.onboard != nil {
+			return m.onboard.View()
+		}
+	case ViewSnippetPicker:
+		if m.snippetPicker != nil {
+			return m.snippetPicker.View()
+		}
+	case ViewDryRun:
+		if m.dryRunView != nil {
+			return m.dryRunView.View()
+		}
+	case ViewWait:
+		if m.waitView != nil {
+			return m.waitView.View()
+		}
+	case ViewVerify:
+		if m.verifyView != nil {
+			return m.verifyView.View()
+		}
+	case ViewJumpPrompt:
+		if m.jumpPrompt != nil {
+			return m.jumpPrompt.View()
+		}
+	case ViewWorkspacePrompt:
+		if m.workspacePrompt != nil {
+			return m.workspacePrompt.View()
+		}
+	case ViewWorkspacePicker:
+		if m.workspacePicker != nil {
+			return m.workspacePicker.View()
+		}
+	case ViewColumnPicker:
+		if m.columnPicker != nil {
+			return m.columnPicker.View()
+		}
+	case ViewMaintenancePrompt:
+		if m.maintenancePrompt != nil {
+			return m.maintenancePrompt.View()
+		}
+	case ViewMaintenanceConfirm:
+		if m.maintenanceConfirm != nil {
+			return m.maintenanceConfirm.View()
+		}
+	case ViewConnectRateConfirm:
+		if m.connectRateConfirm != nil {
+			return m.connectRateConfirm.View()
+		}
+	case ViewMergeBlocks:
+		if m.mergeView != nil {
+			return m.mergeView.View()
+		}
+	case ViewOpenURL:
+		if m.openURLForm != nil {
+			return m.openURLForm.View()
+		}
+	case ViewHistory:
+		if m.historyView != nil {
+			return m.historyView.View()
+		}
+	case ViewMount:
+		if m.mountForm != nil {
+			return m.mountForm.View()
+		}
 	case ViewConnectionError:
 		return m.renderConnectionErrorView()
 	case ViewSSHKeyUpload:
@@ -80,121 +172,12 @@ func (m Model) View() string {
 	return m.renderListView()
 }
 
-// renderListView renders the main list interface
-func (m Model) renderListView() string {
-	// Build the interface components
-	components := []string{}
-
-	// Add the ASCII title
-	components = append(components, m.styles.Header.Render(asciiTitle))
-
-	// Add update notification if available (between title and search)
-	if m.updateInfo != nil && m.updateInfo.Available {
-		updateText := fmt.Sprintf("Update available: %s -> %s",
-			m.updateInfo.CurrentVer,
-			m.updateInfo.LatestVer)
-
-		updateStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("10")). // Green color
-			Bold(true).
-			Align(lipgloss.Center) // Center the notification
-
-		components = append(components, updateStyle.Render(updateText))
+// summarizeHostNames lists up to limit host names and counts the rest
+func summarizeHostNames(names []string, limit int) string {
+	if len(names) <= limit {
+		return strings.Join(names, ", ")
 	}
-
-	// Add error message if there's one to show
-	if m.showingError && m.errorMessage != "" {
-		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")). // Red color
-			Background(lipgloss.Color("1")). // Dark red background
-			Bold(true).
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("9")).
-			Align(lipgloss.Center)
-
-		components = append(components, errorStyle.Render("[!] "+m.errorMessage))
-	}
-
-	// Add the search bar with the appropriate style based on focus
-	searchPrompt := "Search (/ to focus): "
-	if m.searchMode {
-		components = append(components, m.styles.SearchFocused.Render(searchPrompt+m.searchInput.View()))
-	} else {
-		components = append(components, m.styles.SearchUnfocused.Render(searchPrompt+m.searchInput.View()))
-	}
-
-	// Add the table with the appropriate style based on focus
-	if m.searchMode {
-		// The table is not focused, use the unfocused style
-		components = append(components, m.styles.TableUnfocused.Render(m.table.View()))
-	} else {
-		// The table is focused, use the focused style with the primary color
-		components = append(components, m.styles.TableFocused.Render(m.table.View()))
-	}
-
-	// Add the help text - constrained to table width
-	theme := GetCurrentTheme()
-	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
-
-	// Calculate table width from current columns
-	tableWidth := m.getTableWidth()
-
-	var helpParts []string
-	if !m.searchMode {
-		helpParts = append(helpParts, mutedStyle.Render("↑/↓: navigate • Enter: connect • a: add • c: themes • ctrl+s: search focus "))
-		if m.appConfig != nil && m.appConfig.StartInSearchMode {
-			onStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
-			helpParts = append(helpParts, onStyle.Render("[on]"))
-		} else {
-			helpParts = append(helpParts, mutedStyle.Render("[off]"))
-		}
-		helpParts = append(helpParts, mutedStyle.Render(" • h: help • q: quit"))
-	} else {
-		helpParts = append(helpParts, mutedStyle.Render("Type to filter • Enter: validate • Tab: switch • ctrl+s: search focus "))
-		if m.appConfig != nil && m.appConfig.StartInSearchMode {
-			onStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
-			helpParts = append(helpParts, onStyle.Render("[on]"))
-		} else {
-			helpParts = append(helpParts, mutedStyle.Render("[off]"))
-		}
-		helpParts = append(helpParts, mutedStyle.Render(" • Esc: exit"))
-	}
-
-	// Constrain help text to table width using lipgloss
-	helpStyle := lipgloss.NewStyle().Width(tableWidth).Align(lipgloss.Center)
-	components = append(components, helpStyle.Render(strings.Join(helpParts, "")))
-
-	// Join all components vertically with center alignment
-	mainView := lipgloss.Place(
-		m.width,
-		m.height,
-		lipgloss.Center,
-		lipgloss.Center,
-		lipgloss.JoinVertical(
-			lipgloss.Center,
-			components...,
-		),
-	)
-
-	// If in delete mode, overlay the confirmation dialog
-	if m.deleteMode {
-		// Combine the main view with the confirmation dialog overlay
-		confirmation := m.renderDeleteConfirmation()
-
-		// Center the confirmation dialog on the screen
-		centeredConfirmation := lipgloss.Place(
-			m.width,
-			m.height,
-			lipgloss.Center,
-			lipgloss.Center,
-			confirmation,
-		)
-
-		return centeredConfirmation
-	}
-
-	return mainView
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
 }
 
 // renderDeleteConfirmation renders a clean delete confirmation dialog
@@ -207,8 +190,13 @@ func (m Model) renderDeleteConfirmation() string {
 		title = "DELETE SSH HOST"
 	}
 	question := fmt.Sprintf("Are you sure you want to delete host '%s'?", m.deleteHost)
+	if len(m.deleteExpired) > 0 {
+		title = "DELETE EXPIRED HOSTS"
+		question = fmt.Sprintf("Are you sure you want to delete %d expired host(s)?\n%s",
+			len(m.deleteExpired), summarizeHostNames(m.deleteExpired, 5))
+	}
 	action := "This action cannot be undone."
-	help := "Enter: confirm • Esc: cancel"
+	help := m.contextHint()
 
 	// Individual styles (do not affect width via internal centering)
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196"))
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xvertile/sshc/internal/connectivity"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// waitViewModel shows the progress of waiting for a host to become reachable
+type waitViewModel struct {
+	hostName         string
+	started          time.Time
+	progress         connectivity.WaitProgress
+	connectWhenReady bool
+
+	cancel  context.CancelFunc
+	updates <-chan tea.Msg
+
+	styles Styles
+	width  int
+	height int
+}
+
+// waitProgressMsg reports a failed attempt to reach the host
+type waitProgressMsg struct {
+	hostName string
+	progress connectivity.WaitProgress
+}
+
+// waitDoneMsg is sent when the host became reachable or the wait ended
+type waitDoneMsg struct {
+	hostName string
+	err      error
+}
+
+// waitTickMsg refreshes the elapsed time while waiting
+type waitTickMsg struct {
+	hostName string
+}
+
+type waitCancelMsg struct{}
+
+// waitBackoff is the delay between the attempts of a wait view, shortened by tests
+var waitBackoff = connectivity.DefaultBackoff
+
+// NewWaitView starts probing a host in the background until probe succeeds or
+// timeout passes. The probing stops when the view's cancel is called.
+func NewWaitView(hostName string, probe func(context.Context) error, timeout time.Duration, styles Styles, width, height int) (*waitViewModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan tea.Msg)
+
+	go func() {
+		defer close(updates)
+		err := connectivity.WaitUntil(ctx, probe, timeout, waitBackoff, func(progress connectivity.WaitProgress) {
+			select {
+			case updates <- waitProgressMsg{hostName: hostName, progress: progress}:
+			case <-ctx.Done():
+			}
+		})
+		select {
+		case updates <- waitDoneMsg{hostName: hostName, err: err}:
+		case <-ctx.Done():
+		}
+	}()
+
+	m := &waitViewModel{
+		hostName:         hostName,
+		started:          time.Now(),
+		connectWhenReady: true,
+		cancel:           cancel,
+		updates:          updates,
+		styles:           styles,
+		width:            width,
+		height:           height,
+	}
+	return m, tea.Batch(m.listen(), waitTick(hostName))
+}
+
+// listen returns the next update of the wait, or nothing once it is over
+func (m *waitViewModel) listen() tea.Cmd {
+	updates := m.updates
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// waitTick schedules the next refresh of the elapsed time
+func waitTick(hostName string) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return waitTickMsg{hostName: hostName} })
+}
+
+func (m *waitViewModel) Update(msg tea.Msg) (*waitViewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case waitProgressMsg:
+		m.progress = msg.progress
+		return m, m.listen()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c", "q":
+			m.cancel()
+			return m, func() tea.Msg { return waitCancelMsg{} }
+		case "n":
+			m.connectWhenReady = !m.connectWhenReady
+		}
+	}
+	return m, nil
+}
+
+func (m *waitViewModel) View() string {
+	theme := GetCurrentTheme()
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary)).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+
+	elapsed := time.Since(m.started).Truncate(time.Second)
+	status := fmt.Sprintf("%s elapsed", elapsed)
+	if m.progress.Attempt > 0 {
+		status = fmt.Sprintf("Attempt %d • %s elapsed • next try in %s", m.progress.Attempt+1, elapsed, m.progress.Next.Round(time.Second))
+	}
+
+	body := []string{titleStyle.Render("Waiting for " + m.hostName), "", status}
+	if m.progress.Err != nil {
+		body = append(body, mutedStyle.Render("Last attempt: "+m.progress.Err.Error()))
+	}
+
+	whenReady := "connect"
+	if !m.connectWhenReady {
+		whenReady = "notify only"
+	}
+	body = append(body, "", "When reachable: "+whenReady, "", mutedStyle.Render("n: connect/notify • Esc: stop waiting"))
+
+	container := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		container.Render(lipgloss.JoinVertical(lipgloss.Left, body...)),
+	)
+}
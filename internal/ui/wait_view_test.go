@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xvertile/sshc/internal/connectivity"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// nextWaitMsg reads the next update of a wait view, failing after a second
+func nextWaitMsg(t *testing.T, m *waitViewModel) tea.Msg {
+	t.Helper()
+	result := make(chan tea.Msg, 1)
+	go func() { result <- m.listen()() }()
+	select {
+	case msg := <-result:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("No update from the wait view")
+		return nil
+	}
+}
+
+func TestWaitViewReportsProgressAndReady(t *testing.T) {
+	original := waitBackoff
+	waitBackoff = connectivity.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	defer func() { waitBackoff = original }()
+
+	attempts := 0
+	probe := func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	m, _ := NewWaitView("web", probe, time.Minute, NewStyles(120), 120, 40)
+	defer m.cancel()
+
+	progress, ok := nextWaitMsg(t, m).(waitProgressMsg)
+	if !ok || progress.progress.Attempt != 1 {
+		t.Fatalf("Expected the first failed attempt, got %#v", progress)
+	}
+	m.Update(progress)
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Attempt 2") || !strings.Contains(view, "connection refused") {
+		t.Errorf("Expected the attempt count and last error, got:\n%s", view)
+	}
+
+	if done, ok := nextWaitMsg(t, m).(waitDoneMsg); !ok || done.err != nil || done.hostName != "web" {
+		t.Errorf("Expected web to be reachable, got %#v", done)
+	}
+}
+
+func TestWaitViewCancelStopsProbing(t *testing.T) {
+	probeDone := make(chan struct{})
+	probe := func(ctx context.Context) error {
+		<-ctx.Done()
+		close(probeDone)
+		return ctx.Err()
+	}
+	m, _ := NewWaitView("web", probe, time.Hour, NewStyles(120), 120, 40)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := cmd().(waitCancelMsg); !ok {
+		t.Fatal("Expected Esc to cancel the wait")
+	}
+	select {
+	case <-probeDone:
+	case <-time.After(time.Second):
+		t.Fatal("The probe kept running after Esc")
+	}
+	// The background goroutine closes the updates channel once it is done
+	if msg := nextWaitMsg(t, m); msg != nil {
+		t.Errorf("Expected no update after cancel, got %#v", msg)
+	}
+}
+
+func TestWaitDoneNotifiesWhenNotConnecting(t *testing.T) {
+	m := createLargeTestModel(3)
+	probe := func(ctx context.Context) error { return connectivity.ErrWaitTimeout }
+	m.waitView, _ = NewWaitView("web", probe, time.Hour, m.styles, m.width, m.height)
+	m.waitView.cancel()
+	m.viewMode = ViewWait
+
+	updated, _ := m.Update(waitDoneMsg{hostName: "web", err: connectivity.ErrWaitTimeout})
+	m = updated.(Model)
+	if m.waitView != nil || m.viewMode != ViewList {
+		t.Error("Expected the wait view to close")
+	}
+	if !strings.Contains(m.errorMessage, "Gave up waiting for web") {
+		t.Errorf("errorMessage = %q", m.errorMessage)
+	}
+}
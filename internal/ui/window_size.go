@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+)
+
+// windowSizeTimeout is how long to wait for the first WindowSizeMsg. Some IDE
+// terminals and multiplexer setups never send one.
+const windowSizeTimeout = 200 * time.Millisecond
+
+// Size assumed when the terminal cannot be asked for its own
+const (
+	fallbackWidth  = 80
+	fallbackHeight = 24
+)
+
+// windowSizeFallbackMsg is sent when no WindowSizeMsg arrived in time
+type windowSizeFallbackMsg struct{}
+
+// terminalSize returns the size of the terminal on stdout. A variable so tests
+// can simulate terminals that do not report one.
+var terminalSize = func() (int, int, error) {
+	return term.GetSize(os.Stdout.Fd())
+}
+
+// windowSizeFallback schedules the check for a missing WindowSizeMsg
+func windowSizeFallback() tea.Cmd {
+	return tea.Tick(windowSizeTimeout, func(time.Time) tea.Msg { return windowSizeFallbackMsg{} })
+}
+
+// fallbackWindowSize asks the terminal for its size, or assumes 80x24
+func fallbackWindowSize() tea.WindowSizeMsg {
+	width, height, err := terminalSize()
+	if err != nil || width <= 0 || height <= 0 {
+		return tea.WindowSizeMsg{Width: fallbackWidth, Height: fallbackHeight}
+	}
+	return tea.WindowSizeMsg{Width: width, Height: height}
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRendersWithoutWindowSizeMsg(t *testing.T) {
+	defer func(original func() (int, int, error)) { terminalSize = original }(terminalSize)
+	terminalSize = func() (int, int, error) { return 0, 0, errors.New("not a terminal") }
+
+	m := createLargeTestModel(5)
+	m.ready = false
+	m.width, m.height = 0, 0
+	if view := m.View(); view != "Loading..." {
+		t.Fatalf("Expected the loading screen before any size is known, got:\n%s", view)
+	}
+
+	updated, _ := m.Update(windowSizeFallbackMsg{})
+	m = updated.(Model)
+	if !m.ready || m.width != fallbackWidth || m.height != fallbackHeight {
+		t.Fatalf("Expected the fallback to assume %dx%d, got ready=%v %dx%d", fallbackWidth, fallbackHeight, m.ready, m.width, m.height)
+	}
+	for _, column := range m.table.Columns()[:2] {
+		if column.Width <= 0 {
+			t.Errorf("Column %q has width %d", column.Title, column.Width)
+		}
+	}
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "node-0000") || !strings.Contains(view, "10.0.0.0") {
+		t.Errorf("Expected the hosts to render, got:\n%s", view)
+	}
+
+	// A resize arriving later still applies, and a late fallback tick changes nothing
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 140, Height: 50})
+	m = updated.(Model)
+	updated, _ = m.Update(windowSizeFallbackMsg{})
+	m = updated.(Model)
+	if m.width != 140 || m.height != 50 {
+		t.Errorf("Expected the real size to win, got %dx%d", m.width, m.height)
+	}
+}
+
+func TestFallbackWindowSizeAsksTerminal(t *testing.T) {
+	defer func(original func() (int, int, error)) { terminalSize = original }(terminalSize)
+	terminalSize = func() (int, int, error) { return 132, 43, nil }
+
+	if got := fallbackWindowSize(); got.Width != 132 || got.Height != 43 {
+		t.Errorf("fallbackWindowSize() = %+v, want the terminal's 132x43", got)
+	}
+}
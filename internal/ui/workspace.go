@@ -0,0 +1,454 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// workspacePromptModel asks for the name to save the current view of the list under
+type workspacePromptModel struct {
+	input            textinput.Model
+	existing         []string // Names of the saved workspaces
+	confirmOverwrite bool
+	err              string
+	styles           Styles
+	width            int
+	height           int
+}
+
+// workspacePickerModel lists the saved workspaces to switch to or delete one
+type workspacePickerModel struct {
+	names         []string
+	active        string
+	selectedIndex int
+	confirmDelete bool
+	styles        Styles
+	width         int
+	height        int
+}
+
+// Messages for communication with parent model
+type workspaceSaveMsg struct {
+	name string
+}
+
+type workspacePromptCancelMsg struct{}
+
+type workspaceLoadMsg struct {
+	name string
+}
+
+type workspaceDeleteMsg struct {
+	name string
+}
+
+type workspacePickerCloseMsg struct{}
+
+// workspaceNames returns the names of the saved workspaces in the order they were saved
+func workspaceNames(appConfig *config.AppConfig) []string {
+	if appConfig == nil {
+		return nil
+	}
+	names := make([]string, len(appConfig.Workspaces))
+	for i, workspace := range appConfig.Workspaces {
+		names[i] = workspace.Name
+	}
+	return names
+}
+
+// NewWorkspacePrompt creates the prompt for the name of a new workspace, filled in
+// with the active one so it can be updated
+func NewWorkspacePrompt(existing []string, active string, styles Styles, width, height int) *workspacePromptModel {
+	input := textinput.New()
+	input.Placeholder = "oncall"
+	input.CharLimit = 50
+	input.Width = 30
+	input.SetValue(active)
+	input.CursorEnd()
+	input.Focus()
+
+	return &workspacePromptModel{
+		input:    input,
+		existing: existing,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+}
+
+func (m *workspacePromptModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *workspacePromptModel) Update(msg tea.Msg) (*workspacePromptModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		name := strings.TrimSpace(m.input.Value())
+		if m.confirmOverwrite {
+			m.confirmOverwrite = false
+			if msg.String() == "y" || msg.String() == "Y" {
+				return m, func() tea.Msg { return workspaceSaveMsg{name: name} }
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, func() tea.Msg { return workspacePromptCancelMsg{} }
+
+		case "enter":
+			if name == "" {
+				m.err = "Enter a name for the workspace"
+				return m, nil
+			}
+			m.err = ""
+			for _, existing := range m.existing {
+				if strings.EqualFold(existing, name) {
+					m.confirmOverwrite = true
+					return m, nil
+				}
+			}
+			return m, func() tea.Msg { return workspaceSaveMsg{name: name} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *workspacePromptModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	lines := []string{
+		titleStyle.Render("Save workspace"),
+		"",
+		mutedStyle.Render("Keeps the search, sort mode, block grouping and columns"),
+		"",
+		"Name: " + m.input.View(),
+	}
+	if m.err != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Error)).Render(m.err))
+	}
+
+	help := "Enter: save • Esc: cancel"
+	if m.confirmOverwrite {
+		help = fmt.Sprintf("Overwrite workspace %q? (y/N)", strings.TrimSpace(m.input.Value()))
+	}
+	lines = append(lines, "", mutedStyle.Render(help))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+}
+
+// NewWorkspacePicker creates the picker of the saved workspaces, with the active one selected
+func NewWorkspacePicker(names []string, active string, styles Styles, width, height int) *workspacePickerModel {
+	m := &workspacePickerModel{
+		active: active,
+		styles: styles,
+		width:  width,
+		height: height,
+	}
+	m.setNames(names)
+	for i, name := range names {
+		if name == active {
+			m.selectedIndex = i
+			break
+		}
+	}
+	return m
+}
+
+// setNames replaces the listed workspaces, keeping the selection in range
+func (m *workspacePickerModel) setNames(names []string) {
+	m.names = names
+	if m.selectedIndex >= len(names) {
+		m.selectedIndex = max(len(names)-1, 0)
+	}
+}
+
+func (m *workspacePickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *workspacePickerModel) Update(msg tea.Msg) (*workspacePickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.confirmDelete {
+			m.confirmDelete = false
+			if msg.String() == "y" || msg.String() == "Y" {
+				name := m.names[m.selectedIndex]
+				return m, func() tea.Msg { return workspaceDeleteMsg{name: name} }
+			}
+			return m, nil
+		}
+
+		switch key := msg.String(); key {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return workspacePickerCloseMsg{} }
+
+		case "enter":
+			return m, m.submit(m.selectedIndex)
+
+		case "up", "k":
+			m.selectedIndex--
+			if m.selectedIndex < 0 {
+				m.selectedIndex = max(len(m.names)-1, 0)
+			}
+
+		case "down", "j":
+			m.selectedIndex++
+			if m.selectedIndex >= len(m.names) {
+				m.selectedIndex = 0
+			}
+
+		case "d", "delete":
+			if len(m.names) > 0 {
+				m.confirmDelete = true
+			}
+
+		default:
+			// Digits pick a workspace directly
+			if index, ok := tagDigitIndex(key); ok {
+				return m, m.submit(index)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// submit returns a command switching to the workspace at index
+func (m *workspacePickerModel) submit(index int) tea.Cmd {
+	if index < 0 || index >= len(m.names) {
+		return nil
+	}
+	name := m.names[index]
+	return func() tea.Msg { return workspaceLoadMsg{name: name} }
+}
+
+func (m *workspacePickerModel) View() string {
+	theme := GetCurrentTheme()
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Primary)).
+		Bold(true)
+
+	containerStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Primary)).
+		Padding(1, 3)
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Muted))
+
+	var items []string
+	for i, name := range m.names {
+		number := "   "
+		if i < 9 {
+			number = fmt.Sprintf("%d. ", i+1)
+		}
+		label := number + name
+		if name == m.active {
+			label += " (active)"
+		}
+
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Foreground)).Padding(0, 2)
+		if i == m.selectedIndex {
+			style = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(theme.SelectionFg)).
+				Background(lipgloss.Color(theme.SelectionBg)).
+				Bold(true).
+				Padding(0, 2)
+		}
+		items = append(items, style.Render(label))
+	}
+	if len(items) == 0 {
+		items = append(items, helpStyle.Render("No workspaces left"))
+	}
+
+	help := "1-9/Enter: switch • d: delete • Esc: close"
+	if m.confirmDelete {
+		help = fmt.Sprintf("Delete workspace %q? (y/N)", m.names[m.selectedIndex])
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Workspaces"),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, items...),
+		"",
+		helpStyle.Render(help),
+	)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		containerStyle.Render(content),
+	)
+}
+
+// currentWorkspace returns the current view of the list as a workspace named name
+func (m *Model) currentWorkspace(name string) config.Workspace {
+	workspace := config.Workspace{
+		Name:           name,
+		Query:          m.searchInput.Value(),
+		SortMode:       "name",
+		CollapseBlocks: m.collapseBlocks,
+		Columns:        m.visibleColumns(),
+	}
+	if m.sortMode == SortByLastUsed {
+		workspace.SortMode = "recent"
+	}
+	return workspace
+}
+
+// saveWorkspace stores the current view of the list under name and makes it the active workspace
+func (m *Model) saveWorkspace(name string) error {
+	m.appConfig.SetWorkspace(m.currentWorkspace(name))
+	if err := config.SaveAppConfig(m.appConfig); err != nil {
+		return err
+	}
+	m.activeWorkspace = m.appConfig.FindWorkspace(name).Name
+	return nil
+}
+
+// loadWorkspace shows the list the way the workspace named name was saved. The sort
+// mode, grouping and columns are kept as preferences, as when changed by key.
+func (m *Model) loadWorkspace(name string) error {
+	workspace := m.appConfig.FindWorkspace(name)
+	if workspace == nil {
+		return fmt.Errorf("workspace %q not found", name)
+	}
+
+	*workspace = normalizeWorkspace(*workspace)
+	m.sortMode = SortByName
+	if workspace.SortMode == "recent" {
+		m.sortMode = SortByLastUsed
+	}
+	m.collapseBlocks = workspace.CollapseBlocks
+	m.hiddenColumns = nil
+	for _, column := range optionalColumns {
+		if !slices.Contains(workspace.Columns, column) {
+			m.hiddenColumns = append(m.hiddenColumns, column)
+		}
+	}
+	m.appConfig.SortMode = workspace.SortMode
+	m.appConfig.CollapseBlocks = workspace.CollapseBlocks
+	m.appConfig.HiddenColumns = slices.Clone(m.hiddenColumns)
+	m.activeWorkspace = workspace.Name
+
+	m.tagFilter = ""
+	m.searchInput.SetValue(workspace.Query)
+	if workspace.Query != "" {
+		m.filteredHosts = m.filterHosts(workspace.Query)
+		m.filteredEntries = m.sortEntries(m.filterEntries(workspace.Query))
+	} else {
+		m.filteredHosts = m.hosts
+		m.filteredEntries = m.sortEntries(m.allEntries)
+	}
+	m.rowOffset = 0
+	m.table.SetCursor(0)
+	m.updateTableHeight()
+	m.updateTableColumns()
+	m.updateTableRows()
+
+	return config.SaveAppConfig(m.appConfig)
+}
+
+// deleteWorkspace removes the workspace named name, which stops being the active one
+func (m *Model) deleteWorkspace(name string) error {
+	if !m.appConfig.DeleteWorkspace(name) {
+		return fmt.Errorf("workspace %q not found", name)
+	}
+	if strings.EqualFold(m.activeWorkspace, name) {
+		m.activeWorkspace = ""
+	}
+	return config.SaveAppConfig(m.appConfig)
+}
+
+// workspaceLabel returns the active workspace name for the status bar, marked with
+// a * when the list no longer looks the way it was saved
+func (m *Model) workspaceLabel() string {
+	if m.activeWorkspace == "" || m.appConfig == nil {
+		return ""
+	}
+	saved := m.appConfig.FindWorkspace(m.activeWorkspace)
+	if saved == nil {
+		return ""
+	}
+	if !sameWorkspace(m.currentWorkspace(saved.Name), normalizeWorkspace(*saved)) {
+		return saved.Name + "*"
+	}
+	return saved.Name
+}
+
+// normalizeWorkspace fills in the defaults of a workspace edited by hand
+func normalizeWorkspace(workspace config.Workspace) config.Workspace {
+	if workspace.SortMode != "recent" {
+		workspace.SortMode = "name"
+	}
+	if len(workspace.Columns) == 0 {
+		workspace.Columns = config.ListColumns
+	}
+	// Name and hostname are always shown, in the order of the list
+	columns := []string{config.ColumnName, config.ColumnHostname}
+	workspace.Columns = append(columns, hideableColumns(workspace.Columns)...)
+	return workspace
+}
+
+// sameWorkspace reports whether two workspaces show the list the same way
+func sameWorkspace(a, b config.Workspace) bool {
+	return a.Name == b.Name &&
+		a.Query == b.Query &&
+		a.SortMode == b.SortMode &&
+		a.CollapseBlocks == b.CollapseBlocks &&
+		slices.Equal(a.Columns, b.Columns)
+}
+
+// showWorkspaceError shows why a workspace could not be saved, switched to or deleted
+func (m *Model) showWorkspaceError(err error) tea.Cmd {
+	m.errorMessage = err.Error()
+	m.showingError = true
+	m.report(severityError, sourceWrite, m.errorMessage)
+	return func() tea.Msg {
+		time.Sleep(3 * time.Second)
+		return errorMsg("clear")
+	}
+}
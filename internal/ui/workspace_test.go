@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xvertile/sshc/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createWorkspaceTestModel returns a test model with an app config saved to a temporary directory
+func createWorkspaceTestModel(t *testing.T) Model {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := createLargeTestModel(16)
+	appConfig := config.GetDefaultAppConfig()
+	m.appConfig = &appConfig
+	m.table.Focus()
+	return m
+}
+
+func typeText(t *testing.T, m Model, text string) Model {
+	t.Helper()
+	// The cursor blink command would wait for its tick
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	return updated.(Model)
+}
+
+// saveTestWorkspace saves the current view of the list under name, confirming an overwrite
+func saveTestWorkspace(t *testing.T, m Model, name string) Model {
+	t.Helper()
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.viewMode != ViewWorkspacePrompt {
+		t.Fatalf("Expected the workspace prompt, got view %v", m.viewMode)
+	}
+	m.workspacePrompt.input.SetValue("")
+	m = typeText(t, m, name)
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if msg == nil {
+		m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	}
+	if _, ok := msg.(workspaceSaveMsg); !ok {
+		t.Fatalf("Expected the workspace to be saved, got %T", msg)
+	}
+	m, _ = press(t, m, msg)
+	return m
+}
+
+func filterTestModel(m Model, query string) Model {
+	m.searchInput.SetValue(query)
+	m.applySearchFilter()
+	return m
+}
+
+func TestSaveWorkspace(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m = filterTestModel(m, "tag:zone-1")
+	m.sortMode = SortByLastUsed
+	m.setHiddenColumns([]string{config.ColumnLastLogin})
+
+	m = saveTestWorkspace(t, m, "oncall")
+
+	if m.viewMode != ViewList || m.activeWorkspace != "oncall" {
+		t.Fatalf("Expected to be back in the list with oncall active, got view %v and %q", m.viewMode, m.activeWorkspace)
+	}
+	want := config.Workspace{
+		Name:     "oncall",
+		Query:    "tag:zone-1",
+		SortMode: "recent",
+		Columns:  []string{config.ColumnName, config.ColumnHostname, config.ColumnTags, config.ColumnDescription},
+	}
+	if len(m.appConfig.Workspaces) != 1 || !sameWorkspace(m.appConfig.Workspaces[0], want) {
+		t.Errorf("Expected %+v, got %+v", want, m.appConfig.Workspaces)
+	}
+	loaded, err := config.LoadAppConfig()
+	if err != nil || loaded.FindWorkspace("oncall") == nil {
+		t.Errorf("Expected the workspace in the saved app config, got %v", err)
+	}
+	if !strings.Contains(m.renderStatusBar(), "[workspace: oncall]") {
+		t.Errorf("Expected the workspace in the status bar, got %q", m.renderStatusBar())
+	}
+}
+
+func TestSwitchWorkspace(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m = filterTestModel(m, "tag:zone-1")
+	m.sortMode = SortByLastUsed
+	m.collapseBlocks = true
+	m.setHiddenColumns([]string{config.ColumnTags})
+	m = saveTestWorkspace(t, m, "oncall")
+
+	m = filterTestModel(m, "")
+	m.sortMode = SortByName
+	m.collapseBlocks = false
+	m.setHiddenColumns(nil)
+	m = saveTestWorkspace(t, m, "all")
+	if got := len(m.displayEntries()); got != 16 {
+		t.Fatalf("Expected every host, got %d", got)
+	}
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.viewMode != ViewWorkspacePicker {
+		t.Fatalf("Expected the workspace picker, got view %v", m.viewMode)
+	}
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	if load, ok := msg.(workspaceLoadMsg); !ok || load.name != "oncall" {
+		t.Fatalf("Expected oncall to be picked, got %#v", msg)
+	}
+	m, _ = press(t, m, msg)
+
+	if m.viewMode != ViewList || m.activeWorkspace != "oncall" {
+		t.Fatalf("Expected oncall to be active in the list, got view %v and %q", m.viewMode, m.activeWorkspace)
+	}
+	if m.searchInput.Value() != "tag:zone-1" || m.sortMode != SortByLastUsed || !m.collapseBlocks {
+		t.Errorf("Expected the saved query, sort mode and grouping, got %q, %v, %v", m.searchInput.Value(), m.sortMode, m.collapseBlocks)
+	}
+	if got := len(m.displayEntries()); got != 2 {
+		t.Errorf("Expected the 2 hosts of zone-1, got %d", got)
+	}
+	if m.columnVisible(config.ColumnTags) || m.table.Columns()[2].Width != 0 {
+		t.Errorf("Expected the Tags column to be hidden, got columns %+v", m.table.Columns())
+	}
+	if m.appConfig.SortMode != "recent" || !slices.Equal(m.appConfig.HiddenColumns, []string{config.ColumnTags}) {
+		t.Errorf("Expected the sort mode and columns to be kept as preferences, got %q and %v", m.appConfig.SortMode, m.appConfig.HiddenColumns)
+	}
+
+	// Changing the view marks the workspace as modified
+	m.setHiddenColumns(nil)
+	if label := m.workspaceLabel(); label != "oncall*" {
+		t.Errorf("Expected oncall* after showing the Tags column, got %q", label)
+	}
+	m.setHiddenColumns([]string{config.ColumnTags})
+	m = filterTestModel(m, "tag:zone-2")
+	if label := m.workspaceLabel(); label != "oncall*" {
+		t.Errorf("Expected oncall*, got %q", label)
+	}
+}
+
+func TestWorkspaceWithoutColumnsShowsThemAll(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m.setHiddenColumns([]string{config.ColumnTags, config.ColumnDescription})
+	m.appConfig.SetWorkspace(config.Workspace{Name: "edited", SortMode: "name"})
+
+	if err := m.loadWorkspace("edited"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.hiddenColumns) != 0 {
+		t.Errorf("Expected every column for a workspace saved without columns, hidden %v", m.hiddenColumns)
+	}
+	if label := m.workspaceLabel(); label != "edited" {
+		t.Errorf("Expected the workspace to match the list, got %q", label)
+	}
+}
+
+func TestColumnPickerTogglesColumns(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	if m.viewMode != ViewColumnPicker {
+		t.Fatalf("Expected the column picker, got view %v", m.viewMode)
+	}
+
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	m, _ = press(t, m, msg)
+	if m.columnVisible(config.ColumnLastLogin) || m.table.Columns()[3].Width != 0 {
+		t.Errorf("Expected Last Login to be hidden, got columns %+v", m.table.Columns())
+	}
+	if !slices.Equal(m.appConfig.HiddenColumns, []string{config.ColumnLastLogin}) {
+		t.Errorf("Expected the hidden column to be saved, got %v", m.appConfig.HiddenColumns)
+	}
+	if !strings.Contains(m.View(), "[ ] Last Login") {
+		t.Error("Expected Last Login to be unchecked in the picker")
+	}
+
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" ")})
+	m, _ = press(t, m, msg)
+	if !m.columnVisible(config.ColumnLastLogin) || m.table.Columns()[3].Width == 0 {
+		t.Errorf("Expected Last Login to be shown again, got columns %+v", m.table.Columns())
+	}
+
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = press(t, m, msg)
+	if m.viewMode != ViewList {
+		t.Errorf("Expected to be back in the list, got view %v", m.viewMode)
+	}
+}
+
+func TestOverwriteWorkspaceNeedsConfirmation(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m = filterTestModel(m, "tag:zone-1")
+	m = saveTestWorkspace(t, m, "oncall")
+	m = filterTestModel(m, "tag:zone-2")
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if got := m.workspacePrompt.input.Value(); got != "oncall" {
+		t.Errorf("Expected the prompt filled in with the active workspace, got %q", got)
+	}
+	m.workspacePrompt.input.SetValue("OnCall")
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if msg != nil || !m.workspacePrompt.confirmOverwrite {
+		t.Fatalf("Expected a confirmation before overwriting, got %#v", msg)
+	}
+	if !strings.Contains(m.View(), `Overwrite workspace "OnCall"?`) {
+		t.Error("Expected the confirmation in the prompt")
+	}
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if msg != nil || m.appConfig.Workspaces[0].Query != "tag:zone-1" {
+		t.Fatalf("Expected no overwrite, got %#v", msg)
+	}
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m, _ = press(t, m, msg)
+	if len(m.appConfig.Workspaces) != 1 || m.appConfig.Workspaces[0].Query != "tag:zone-2" {
+		t.Errorf("Expected the workspace to be overwritten, got %+v", m.appConfig.Workspaces)
+	}
+}
+
+func TestDeleteWorkspaceNeedsConfirmation(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	m = saveTestWorkspace(t, m, "all")
+	m = filterTestModel(m, "tag:zone-1")
+	m = saveTestWorkspace(t, m, "oncall")
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.workspacePicker.names[m.workspacePicker.selectedIndex] != "oncall" {
+		t.Fatal("Expected the active workspace to be selected")
+	}
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m, msg := press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if msg != nil || len(m.appConfig.Workspaces) != 2 {
+		t.Fatalf("Expected nothing deleted, got %#v", msg)
+	}
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m, msg = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if del, ok := msg.(workspaceDeleteMsg); !ok || del.name != "oncall" {
+		t.Fatalf("Expected oncall to be deleted, got %#v", msg)
+	}
+	m, _ = press(t, m, msg)
+
+	if m.viewMode != ViewWorkspacePicker || len(m.workspacePicker.names) != 1 {
+		t.Fatalf("Expected the picker to stay open on the remaining workspace, got view %v", m.viewMode)
+	}
+	if m.activeWorkspace != "" || m.appConfig.FindWorkspace("oncall") != nil {
+		t.Errorf("Expected oncall to be gone and no longer active, got %q", m.activeWorkspace)
+	}
+}
+
+func TestCtrlSInSearchTogglesSearchFocus(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	updated, _ := m.enterSearchMode()
+	m = updated.(Model)
+
+	m, _ = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.viewMode != ViewList || !m.appConfig.StartInSearchMode {
+		t.Errorf("Expected ctrl+s to toggle the search focus setting while searching, got view %v", m.viewMode)
+	}
+}
+
+func TestWorkspacesWithoutAny(t *testing.T) {
+	m := createWorkspaceTestModel(t)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = updated.(Model)
+	if m.viewMode != ViewList || !strings.Contains(m.errorMessage, "No workspaces yet") {
+		t.Errorf("Expected a hint instead of an empty picker, got view %v and %q", m.viewMode, m.errorMessage)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ValidateHostname checks if a hostname is valid
@@ -49,6 +50,24 @@ func ValidateHostName(name string) bool {
 	return !strings.ContainsAny(name, " \t\n\r#")
 }
 
+// hostPatternChars are the characters ssh reads as wildcards or negation in a Host
+// line, plus brackets, which sshc code paths and known_hosts ([host]:port) treat specially
+const hostPatternChars = "*?![]"
+
+// IsHostPattern reports whether a host name contains characters ssh treats as a pattern
+func IsHostPattern(name string) bool {
+	return strings.ContainsAny(name, hostPatternChars)
+}
+
+// ValidateNewHostName rejects names for new hosts that ssh would read as a pattern.
+// Hosts that already carry such names are left alone so they can still be edited.
+func ValidateNewHostName(name string) error {
+	if IsHostPattern(name) {
+		return fmt.Errorf("invalid host name %q: *, ?, !, [ and ] are pattern characters in ssh config", name)
+	}
+	return nil
+}
+
 // ValidateIdentityFile checks if an identity file path is valid
 func ValidateIdentityFile(path string) bool {
 	if path == "" {
@@ -66,6 +85,123 @@ func ValidateIdentityFile(path string) bool {
 	return err == nil
 }
 
+// ValidateExpiryDate checks if an expiry date uses the YYYY-MM-DD format
+func ValidateExpiryDate(date string) bool {
+	if date == "" {
+		return true // Optional field
+	}
+	if !regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`).MatchString(date) {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+// commandWrappers run the command that follows them, so the command after them is the one that matters
+var commandWrappers = map[string]bool{
+	"sudo": true, "exec": true, "env": true, "nice": true, "nohup": true, "command": true, "time": true,
+}
+
+// wrapperValueFlags are wrapper options whose value is the next word, e.g. "sudo -u root"
+var wrapperValueFlags = map[string]bool{
+	"-u": true, "-g": true,
+}
+
+// loginShellFlags make sudo start an interactive shell when no command follows
+var loginShellFlags = map[string]bool{
+	"-i": true, "-s": true, "--login": true, "--shell": true,
+}
+
+// InteractiveRemoteCommand returns the first command in a RemoteCommand that appears in
+// interactive, such as "htop" in "sudo -u root htop", or "" when none does
+func InteractiveRemoteCommand(remoteCommand string, interactive []string) string {
+	expectCommand := true
+	skipValue := false
+	loginShell := false
+	for _, word := range strings.Fields(remoteCommand) {
+		if skipValue {
+			skipValue = false
+			continue
+		}
+
+		// Shell separators start a new command
+		separated := strings.HasSuffix(word, ";")
+		word = strings.TrimSuffix(word, ";")
+		switch word {
+		case "&&", "||", "|", "":
+			expectCommand = true
+			continue
+		}
+
+		if expectCommand {
+			switch {
+			case wrapperValueFlags[word]:
+				skipValue = true
+			case loginShellFlags[word]:
+				loginShell = true
+			case strings.HasPrefix(word, "-"), strings.Contains(word, "="), strings.Trim(word, "0123456789") == "":
+				// Wrapper options, environment assignments and option values like "nice -n 10"
+			default:
+				name := filepath.Base(word)
+				if !commandWrappers[name] {
+					for _, candidate := range interactive {
+						if strings.EqualFold(name, candidate) {
+							return name
+						}
+					}
+					expectCommand = false
+				}
+			}
+		}
+
+		if separated {
+			expectCommand = true
+		}
+	}
+
+	// "sudo -i" on its own opens a login shell
+	if loginShell && expectCommand {
+		return "sudo"
+	}
+	return ""
+}
+
+// RemoteCommandNeedsTTY reports whether a RemoteCommand looks interactive while RequestTTY
+// is unset or "auto", in which case ssh runs it without a terminal and it usually exits at once
+func RemoteCommandNeedsTTY(remoteCommand, requestTTY string, interactive []string) bool {
+	switch strings.ToLower(strings.TrimSpace(requestTTY)) {
+	case "", "auto":
+		return InteractiveRemoteCommand(remoteCommand, interactive) != ""
+	}
+	return false
+}
+
+// optionKeywordRegex matches an ssh_config keyword
+var optionKeywordRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// ValidateSSHOptions checks that every line of options, in config format, is a
+// keyword followed by its value. Host and Match lines are rejected since they
+// would start a new block.
+func ValidateSSHOptions(options string) error {
+	for i, line := range strings.Split(options, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, value, _ := strings.Cut(strings.Replace(line, "=", " ", 1), " ")
+		if !optionKeywordRegex.MatchString(keyword) {
+			return fmt.Errorf("SSH options line %d: %q does not start with a keyword", i+1, line)
+		}
+		if strings.EqualFold(keyword, "Host") || strings.EqualFold(keyword, "Match") {
+			return fmt.Errorf("SSH options line %d: %s would start a new block", i+1, keyword)
+		}
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("SSH options line %d: %s has no value", i+1, keyword)
+		}
+	}
+	return nil
+}
+
 // ValidateHost validates all host fields
 func ValidateHost(name, hostname, port, identity string) error {
 	if strings.TrimSpace(name) == "" {
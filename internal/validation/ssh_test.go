@@ -86,6 +86,79 @@ func TestValidatePort(t *testing.T) {
 	}
 }
 
+func TestValidateExpiryDate(t *testing.T) {
+	tests := []struct {
+		name string
+		date string
+		want bool
+	}{
+		{"empty date", "", true}, // Expiry is optional
+		{"valid date", "2024-12-31", true},
+		{"leap day", "2024-02-29", true},
+		{"invalid leap day", "2023-02-29", false},
+		{"invalid month", "2024-13-01", false},
+		{"day first", "31-12-2024", false},
+		{"slashes", "2024/12/31", false},
+		{"missing zero padding", "2024-1-5", false},
+		{"with time", "2024-12-31T00:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateExpiryDate(tt.date); got != tt.want {
+				t.Errorf("ValidateExpiryDate(%q) = %v, want %v", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteCommandNeedsTTY(t *testing.T) {
+	interactive := []string{"bash", "sh", "htop", "tmux", "su"}
+
+	tests := []struct {
+		name          string
+		remoteCommand string
+		requestTTY    string
+		want          bool
+	}{
+		{"no remote command", "", "", false},
+		{"shell without tty", "bash", "", true},
+		{"absolute path", "/usr/bin/htop", "", true},
+		{"auto does not allocate for commands", "htop", "auto", true},
+		{"tty requested", "htop", "yes", false},
+		{"tty forced", "htop", "force", false},
+		{"tty explicitly refused", "bash", "no", false},
+		{"case insensitive request", "bash", "Yes", false},
+		{"non-interactive command", "tail -f /var/log/syslog", "", false},
+		{"interactive word as argument", "grep bash /etc/shells", "", false},
+		{"after cd", "cd /srv/app && bash -l", "", true},
+		{"after semicolon", "cd /srv/app; tmux attach", "", true},
+		{"sudo with user", "sudo -u deploy htop", "", true},
+		{"env assignment", "TERM=xterm-256color htop", "", true},
+		{"nice with value", "nice -n 10 htop", "", true},
+		{"sudo login shell", "sudo -i", "", true},
+		{"sudo -s with command", "sudo -s cat /etc/hosts", "", false},
+		{"su", "sudo su - deploy", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemoteCommandNeedsTTY(tt.remoteCommand, tt.requestTTY, interactive); got != tt.want {
+				t.Errorf("RemoteCommandNeedsTTY(%q, %q) = %v, want %v", tt.remoteCommand, tt.requestTTY, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInteractiveRemoteCommandCustomList(t *testing.T) {
+	if got := InteractiveRemoteCommand("k9s --readonly", []string{"bash"}); got != "" {
+		t.Errorf("Expected k9s not to match the default list, got %q", got)
+	}
+	if got := InteractiveRemoteCommand("k9s --readonly", []string{"bash", "K9s"}); got != "k9s" {
+		t.Errorf("Expected k9s to match an extended list, got %q", got)
+	}
+}
+
 func TestValidateHostName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -112,6 +185,31 @@ func TestValidateHostName(t *testing.T) {
 	}
 }
 
+func TestValidateNewHostName(t *testing.T) {
+	for _, name := range []string{"web*", "web?", "!web", "web[1]", "web]"} {
+		if err := ValidateNewHostName(name); err == nil {
+			t.Errorf("ValidateNewHostName(%q) = nil, want an error", name)
+		}
+	}
+	for _, name := range []string{"web1", "web-1.prod", "web_1"} {
+		if err := ValidateNewHostName(name); err != nil {
+			t.Errorf("ValidateNewHostName(%q) = %v, want nil", name, err)
+		}
+	}
+}
+
+func TestValidateSSHOptions(t *testing.T) {
+	valid := "Compression yes\nProxyCommand ssh -W \"%h:%p\" 'bastion host'\nServerAliveInterval=60\n\n# comment"
+	if err := ValidateSSHOptions(valid); err != nil {
+		t.Errorf("ValidateSSHOptions(%q) = %v, want nil", valid, err)
+	}
+	for _, options := range []string{"-o Compression=yes", "Compression", "Compression yes\nHost other", "\"Quoted\" value"} {
+		if err := ValidateSSHOptions(options); err == nil {
+			t.Errorf("ValidateSSHOptions(%q) = nil, want an error", options)
+		}
+	}
+}
+
 func TestValidateIdentityFile(t *testing.T) {
 	// Create a temporary file for testing
 	tmpDir := t.TempDir()
@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SSHURI is the destination named by an ssh:// URI. User and Port are empty when
+// the URI leaves them out.
+type SSHURI struct {
+	User     string
+	Hostname string
+	Port     string
+}
+
+// uriSchemeRegex matches the scheme of a URI, as in "ssh://"
+var uriSchemeRegex = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*)://`)
+
+// IsURI reports whether s starts with a URI scheme such as ssh://
+func IsURI(s string) bool {
+	return uriSchemeRegex.MatchString(strings.TrimSpace(s))
+}
+
+// ParseSSHURI parses ssh://[user@]host[:port][/path] into its user, hostname and
+// port. The user may be percent-encoded and IPv6 hosts are written in brackets.
+// Connection parameters after ";" in the user (such as a fingerprint) and any
+// path, as in GitLab clone URLs, are ignored. Other schemes are rejected.
+func ParseSSHURI(raw string) (SSHURI, error) {
+	raw = strings.TrimSpace(raw)
+	match := uriSchemeRegex.FindStringSubmatch(raw)
+	if match == nil {
+		return SSHURI{}, fmt.Errorf("%q is not an ssh:// URI", raw)
+	}
+	if scheme := strings.ToLower(match[1]); scheme != "ssh" {
+		return SSHURI{}, fmt.Errorf("unsupported URI scheme %q: only ssh:// URIs are supported", scheme)
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return SSHURI{}, fmt.Errorf("invalid ssh:// URI: %w", err)
+	}
+
+	var uri SSHURI
+	if parsed.User != nil {
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			return SSHURI{}, fmt.Errorf("passwords in ssh:// URIs are not supported")
+		}
+		uri.User, _, _ = strings.Cut(parsed.User.Username(), ";")
+	}
+
+	uri.Hostname = parsed.Hostname()
+	if uri.Hostname == "" {
+		return SSHURI{}, fmt.Errorf("ssh:// URI has no host")
+	}
+	if !ValidateHostname(uri.Hostname) && !ValidateIP(uri.Hostname) {
+		return SSHURI{}, fmt.Errorf("invalid host %q in ssh:// URI", uri.Hostname)
+	}
+
+	uri.Port = parsed.Port()
+	if uri.Port == "" && strings.HasSuffix(parsed.Host, ":") {
+		return SSHURI{}, fmt.Errorf("ssh:// URI has an empty port")
+	}
+	if !ValidatePort(uri.Port) {
+		return SSHURI{}, fmt.Errorf("invalid port %q in ssh:// URI", uri.Port)
+	}
+	return uri, nil
+}
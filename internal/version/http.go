@@ -0,0 +1,90 @@
+package version
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/config"
+)
+
+// DefaultHTTPTimeout is used when no timeout is configured
+const DefaultHTTPTimeout = 10 * time.Second
+
+// ErrProxyAuth is returned when the configured proxy rejects our credentials
+var ErrProxyAuth = errors.New("proxy authentication failed")
+
+// HTTPOptions controls how outbound HTTP requests are made
+type HTTPOptions struct {
+	// Proxy overrides HTTPS_PROXY/HTTP_PROXY/NO_PROXY when set.
+	// Supports http://, https://, socks5:// and socks5h:// URLs with optional user:password.
+	Proxy string
+
+	// Timeout for the whole request, DefaultHTTPTimeout when zero
+	Timeout time.Duration
+
+	// rootCAs replaces the system roots, only used by tests
+	rootCAs *x509.CertPool
+}
+
+// HTTPOptionsFromAppConfig returns the proxy and timeout set in the app config
+func HTTPOptionsFromAppConfig(c *config.AppConfig) HTTPOptions {
+	if c == nil {
+		return HTTPOptions{}
+	}
+	return HTTPOptions{
+		Proxy:   c.HTTPProxy,
+		Timeout: c.HTTPTimeoutDuration(),
+	}
+}
+
+// RequestTimeout returns the time a request may take: Timeout, or
+// DefaultHTTPTimeout when it is not set
+func (o HTTPOptions) RequestTimeout() time.Duration {
+	if o.Timeout <= 0 {
+		return DefaultHTTPTimeout
+	}
+	return o.Timeout
+}
+
+// NewHTTPClient creates an HTTP client that honors the proxy environment and the given options
+func NewHTTPClient(opts HTTPOptions) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid http_proxy %q", opts.Proxy)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q in http_proxy", proxyURL.Scheme)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	if opts.rootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: opts.rootCAs}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.RequestTimeout(),
+	}, nil
+}
+
+// wrapProxyError marks proxy authentication failures so callers can tell them apart
+func wrapProxyError(err error) error {
+	// net/http reports a rejected CONNECT with the proxy's status text
+	if err != nil && strings.Contains(err.Error(), http.StatusText(http.StatusProxyAuthRequired)) {
+		return fmt.Errorf("%w: %v", ErrProxyAuth, err)
+	}
+	return err
+}
@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 )
 
+// latestReleaseURL is the GitHub API endpoint for the latest sshc release
+var latestReleaseURL = "https://api.github.com/repos/xvertile/sshc/releases/latest"
+
 // GitHubRelease represents a GitHub release response
 type GitHubRelease struct {
 	TagName    string `json:"tag_name"`
@@ -83,7 +85,7 @@ func compareVersions(v1, v2 string) int {
 }
 
 // CheckForUpdates checks GitHub for the latest release of sshc
-func CheckForUpdates(ctx context.Context, currentVersion string) (*UpdateInfo, error) {
+func CheckForUpdates(ctx context.Context, currentVersion string, opts HTTPOptions) (*UpdateInfo, error) {
 	// Skip version check if current version is "dev"
 	if currentVersion == "dev" {
 		return &UpdateInfo{
@@ -92,14 +94,14 @@ func CheckForUpdates(ctx context.Context, currentVersion string) (*UpdateInfo, e
 		}, nil
 	}
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	// Create HTTP client honoring proxy settings and timeout
+	client, err := NewHTTPClient(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, "GET",
-		"https://api.github.com/repos/xvertile/sshc/releases/latest", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", latestReleaseURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -110,10 +112,13 @@ func CheckForUpdates(ctx context.Context, currentVersion string) (*UpdateInfo, e
 	// Make the request
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+		return nil, fmt.Errorf("failed to fetch latest release: %w", wrapProxyError(err))
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", ErrProxyAuth)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
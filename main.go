@@ -1,7 +1,11 @@
 package main
 
-import "github.com/xvertile/sshc/cmd"
+import (
+	"os"
+
+	"github.com/xvertile/sshc/cmd"
+)
 
 func main() {
-	cmd.Execute()
+	os.Exit(cmd.Execute())
 }
@@ -0,0 +1,17 @@
+// Package sshconfig reads and edits OpenSSH client config files the way sshc does.
+//
+// It parses Host blocks, follows Include directives, and adds, updates and deletes
+// hosts while keeping the rest of the file intact. This is the parser and writer
+// sshc itself uses. Add, Update and Delete only write the file; an Editor with
+// Backup and Write set keeps copies and a record of changes, the way sshc keeps
+// its backups and audit log.
+//
+// # Compatibility
+//
+// The exported identifiers of this package follow semantic versioning with the
+// sshc module: within a major version, functions keep their signatures and
+// behavior, and the Host and Option structs only gain fields. Code that builds
+// these structs with field names keeps compiling across minor releases.
+// Anything not exported here, including the packages under internal/, may
+// change at any time.
+package sshconfig
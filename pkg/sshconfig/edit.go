@@ -0,0 +1,349 @@
+package sshconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xvertile/sshc/internal/configtext"
+)
+
+// Editor adds, updates and deletes hosts. Its zero value rewrites config files
+// and does nothing else; Backup and Write let a caller keep a copy of each file
+// before it changes and a record of every change, as sshc does with its backups
+// and audit log.
+type Editor struct {
+	// NativeTags writes tags as Tag directives, which OpenSSH 9.4 and later
+	// understand, instead of a "# Tags:" comment. Tags ssh would read as more
+	// than one word keep the comment.
+	NativeTags bool
+
+	// Backup, when set, is called with the path of an existing file before it is
+	// changed. An error stops the change.
+	Backup func(path string) error
+
+	// Write, when set, writes after to path in place of the editor. host names the
+	// host changed and before is the content the change was made to.
+	Write func(path, host string, before []byte, after string) error
+}
+
+// writeMutex serializes the changes made by editors in this process
+var writeMutex sync.Mutex
+
+// Add appends host as a new block at the end of the config file at path. The file
+// is created if it does not exist. It fails if path already declares the name.
+func (e Editor) Add(path string, host Host) error {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	if _, err := os.Stat(path); err == nil {
+		if err := e.backup(path); err != nil {
+			return err
+		}
+	}
+
+	exists, err := HasHost(path, host.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("host '%s' already exists", host.Name)
+	}
+
+	// A missing file starts out empty
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return e.write(path, host.Name, content, e.AppendHost(string(content), host))
+}
+
+// Update replaces the block of the host called name in the config file at path
+// with host, which may carry a new name. A host that shares its Host line with
+// others is moved into a block of its own, leaving the other names untouched.
+func (e Editor) Update(path, name string, host Host) error {
+	return e.rewrite(path, name, func(content string) (string, error) {
+		return e.ReplaceHost(content, name, host)
+	})
+}
+
+// UpdateBlock replaces the block declaring any of names in the config file at
+// path with a single block declaring newNames, all with the settings of host
+func (e Editor) UpdateBlock(path string, names, newNames []string, host Host) error {
+	return e.rewrite(path, strings.Join(names, " "), func(content string) (string, error) {
+		return e.ReplaceBlock(content, names, newNames, host)
+	})
+}
+
+// Delete removes the host called name from the config file at path, along with
+// its metadata comments. Other names on the same Host line are kept.
+func (e Editor) Delete(path, name string) error {
+	return e.rewrite(path, name, func(content string) (string, error) {
+		return e.RemoveHost(content, name)
+	})
+}
+
+// rewrite backs up the file at path and replaces its content with what change
+// makes of it. host names the hosts changed.
+func (e Editor) rewrite(path, host string, change func(content string) (string, error)) error {
+	writeMutex.Lock()
+	defer writeMutex.Unlock()
+
+	if err := e.backup(path); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	after, err := change(string(content))
+	if err != nil {
+		return err
+	}
+	return e.write(path, host, content, after)
+}
+
+// backup calls Backup when it is set
+func (e Editor) backup(path string) error {
+	if e.Backup == nil {
+		return nil
+	}
+	if err := e.Backup(path); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	return nil
+}
+
+// write checks the file is not marked read-only and writes after with Write, or
+// to the file itself. A symlinked file is written through to its target.
+func (e Editor) write(path, host string, before []byte, after string) error {
+	if configtext.HasReadOnlyMarker(string(before)) {
+		return fmt.Errorf("%w: %s", ErrReadOnlyFile, path)
+	}
+	if e.Write != nil {
+		return e.Write(path, host, before, after)
+	}
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	return os.WriteFile(path, []byte(after), 0600)
+}
+
+// FormatBlock returns the lines of a Host block declaring names with the settings
+// of host, preceded by its metadata comments. The comments are always written in
+// the same order so rewrites do not reorder them.
+func (e Editor) FormatBlock(names []string, host Host) []string {
+	var lines []string
+
+	if description := configtext.SingleLine(host.Description); description != "" {
+		lines = append(lines, configtext.DescriptionPrefix+" "+description)
+	}
+	nativeTags := e.nativeTags(host.Tags)
+	if len(host.Tags) > 0 && !nativeTags {
+		lines = append(lines, configtext.TagsPrefix+" "+strings.Join(host.Tags, ", "))
+	}
+	if host.Expires != "" {
+		lines = append(lines, configtext.ExpiresPrefix+" "+host.Expires)
+	}
+
+	lines = append(lines, configtext.HostLine(names))
+	lines = append(lines, "    HostName "+host.Hostname)
+	if host.User != "" {
+		lines = append(lines, "    User "+host.User)
+	}
+	if host.Port != "" && host.Port != "22" {
+		lines = append(lines, "    Port "+host.Port)
+	}
+	if host.IdentityFile != "" {
+		lines = append(lines, "    IdentityFile "+configtext.QuoteValue(host.IdentityFile))
+	}
+	if host.ProxyJump != "" {
+		lines = append(lines, "    ProxyJump "+host.ProxyJump)
+	}
+	if host.ProxyCommand != "" {
+		// Never quoted: ssh passes the whole rest of the line to the shell
+		lines = append(lines, "    ProxyCommand "+host.ProxyCommand)
+	}
+	if host.RemoteCommand != "" {
+		lines = append(lines, "    RemoteCommand "+host.RemoteCommand)
+	}
+	if host.RequestTTY != "" {
+		lines = append(lines, "    RequestTTY "+host.RequestTTY)
+	}
+	if nativeTags {
+		for _, tag := range host.Tags {
+			lines = append(lines, "    Tag "+tag)
+		}
+	}
+
+	for _, option := range host.Options {
+		if option := strings.TrimSpace(option.Name + " " + option.Value); option != "" {
+			lines = append(lines, "    "+option)
+		}
+	}
+
+	return lines
+}
+
+// nativeTags reports whether tags are written as Tag directives
+func (e Editor) nativeTags(tags []string) bool {
+	if !e.NativeTags || len(tags) == 0 {
+		return false
+	}
+	for _, tag := range tags {
+		if !configtext.IsNativeTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// AppendHost returns config content with a block for host added at the end,
+// using the line endings of content
+func (e Editor) AppendHost(content string, host Host) string {
+	newline := configtext.DetectFormat(content).Newline()
+	return content + newline + strings.Join(e.FormatBlock([]string{host.Name}, host), newline) + newline
+}
+
+// ReplaceHost returns config content with the block of the host called name
+// replaced by host. A host sharing its block with others is split out into a
+// block of its own.
+func (e Editor) ReplaceHost(content, name string, host Host) (string, error) {
+	lines, format := configtext.Split(content)
+	var newLines []string
+	hostFound := false
+
+	for i := 0; i < len(lines); {
+		start, hostLine, names, ok := blockAt(lines, i, name)
+		if !ok {
+			newLines = append(newLines, lines[i])
+			i++
+			continue
+		}
+		hostFound = true
+		end := configtext.BlockEnd(lines, hostLine)
+
+		if remaining := slices.DeleteFunc(slices.Clone(names), func(n string) bool { return n == name }); len(names) > 1 {
+			// Keep the block for the other names and add the host as a separate entry
+			newLines = append(newLines, lines[start:hostLine]...)
+			if len(remaining) > 0 {
+				newLines = append(newLines, configtext.HostLine(remaining))
+				newLines = append(newLines, lines[hostLine+1:end]...)
+			}
+			newLines = append(newLines, "")
+			newLines = append(newLines, e.FormatBlock([]string{host.Name}, host)...)
+			newLines = append(newLines, "")
+			i = end
+			continue
+		}
+
+		// Replace the whole block and the empty lines after it
+		i = skipEmpty(lines, end)
+		if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
+			newLines = append(newLines, "")
+		}
+		newLines = append(newLines, e.FormatBlock([]string{host.Name}, host)...)
+		newLines = append(newLines, "")
+	}
+
+	if !hostFound {
+		return "", fmt.Errorf("host '%s' not found", name)
+	}
+	return format.Join(newLines), nil
+}
+
+// ReplaceBlock returns config content with the block declaring any of names
+// replaced by a single block declaring newNames with the settings of host
+func (e Editor) ReplaceBlock(content string, names, newNames []string, host Host) (string, error) {
+	lines, format := configtext.Split(content)
+	var newLines []string
+	blockFound := false
+
+	for i := 0; i < len(lines); {
+		var hostLine int
+		ok := false
+		for _, name := range names {
+			if _, hostLine, _, ok = blockAt(lines, i, name); ok {
+				break
+			}
+		}
+		if !ok {
+			newLines = append(newLines, lines[i])
+			i++
+			continue
+		}
+		blockFound = true
+
+		// Skip the old block entirely, along with the empty lines after it
+		i = skipEmpty(lines, configtext.BlockEnd(lines, hostLine))
+		if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
+			newLines = append(newLines, "")
+		}
+		newLines = append(newLines, e.FormatBlock(newNames, host)...)
+		newLines = append(newLines, "")
+	}
+
+	if !blockFound {
+		return "", fmt.Errorf("multi-host block not found")
+	}
+	return format.Join(newLines), nil
+}
+
+// RemoveHost returns config content without the host called name. A host sharing
+// its Host line with others is only taken off that line; the block stays for the rest.
+func (e Editor) RemoveHost(content, name string) (string, error) {
+	lines, format := configtext.Split(content)
+	var newLines []string
+	hostFound := false
+
+	for i := 0; i < len(lines); {
+		start, hostLine, names, ok := blockAt(lines, i, name)
+		if !ok {
+			newLines = append(newLines, lines[i])
+			i++
+			continue
+		}
+		hostFound = true
+		end := configtext.BlockEnd(lines, hostLine)
+
+		if remaining := slices.DeleteFunc(slices.Clone(names), func(n string) bool { return n == name }); len(remaining) > 0 {
+			// Keep the metadata comments and the block for the other names
+			newLines = append(newLines, lines[start:hostLine]...)
+			newLines = append(newLines, configtext.HostLine(remaining))
+			newLines = append(newLines, lines[hostLine+1:end]...)
+		}
+		i = skipEmpty(lines, end)
+	}
+
+	if !hostFound {
+		return "", fmt.Errorf("host '%s' not found", name)
+	}
+	return format.Join(newLines), nil
+}
+
+// blockAt reports whether a block declaring name starts at line i, either with
+// its metadata comments or with the Host line itself. It returns the index of
+// the Host line and the names it declares.
+func blockAt(lines []string, i int, name string) (start, hostLine int, names []string, ok bool) {
+	hostLine = configtext.MetadataEnd(lines, i)
+	if hostLine == len(lines) {
+		return 0, 0, nil, false
+	}
+	names, ok = configtext.HostNames(strings.TrimSpace(lines[hostLine]))
+	if !ok || !slices.Contains(names, name) {
+		return 0, 0, nil, false
+	}
+	return i, hostLine, names, true
+}
+
+// skipEmpty returns the index of the first non-empty line from i on
+func skipEmpty(lines []string, i int) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return i
+}
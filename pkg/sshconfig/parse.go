@@ -0,0 +1,383 @@
+package sshconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xvertile/sshc/internal/configtext"
+	"github.com/xvertile/sshc/internal/metrics"
+)
+
+// ParseOptions sets what ParseFileWithOptions does with the parts of a config it
+// cannot read. The zero value parses like ParseFile.
+type ParseOptions struct {
+	// Strict fails the parse with every problem found, instead of skipping them
+	Strict bool
+	// CollectWarnings returns the problems skipped by a lenient parse
+	CollectWarnings bool
+}
+
+// ParseProblem is a line or include the parser skipped
+type ParseProblem struct {
+	File    string
+	Line    int // 0 when the problem is with the whole file
+	Message string
+}
+
+// String formats the problem as file:line: message
+func (p ParseProblem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.File, p.Message)
+}
+
+// ParseErrors is the error of a strict parse, with a problem per line
+type ParseErrors []ParseProblem
+
+func (e ParseErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, problem := range e {
+		lines[i] = problem.String()
+	}
+	if len(lines) == 1 {
+		return lines[0]
+	}
+	return fmt.Sprintf("%d problems in SSH config:\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+// ParseFile returns the hosts defined in the config file at path and in the files
+// it includes. Wildcard patterns such as "Host *" are not returned. A missing file
+// has no hosts, and lines or includes that cannot be read are skipped.
+func ParseFile(path string) ([]Host, error) {
+	hosts, err := parseFile(path, make(map[string]bool), 0, nil)
+	numberHostBlocks(hosts)
+	return hosts, err
+}
+
+// ParseFileWithOptions parses a config like ParseFile and also returns the lines
+// and includes it skipped when opts.CollectWarnings is set. With opts.Strict,
+// finding any of them fails the parse with a ParseErrors and no hosts.
+func ParseFileWithOptions(path string, opts ParseOptions) ([]Host, []ParseProblem, error) {
+	if !opts.Strict && !opts.CollectWarnings {
+		hosts, err := ParseFile(path)
+		return hosts, nil, err
+	}
+
+	problems := []ParseProblem{}
+	hosts, err := parseFile(path, make(map[string]bool), 0, &problems)
+	numberHostBlocks(hosts)
+	if err == nil && opts.Strict && len(problems) > 0 {
+		err = ParseErrors(problems)
+	}
+	if err != nil && opts.Strict {
+		hosts = nil
+	}
+	if !opts.CollectWarnings {
+		problems = nil
+	}
+	return hosts, problems, err
+}
+
+// addParseProblem records a problem when problems are collected
+func addParseProblem(problems *[]ParseProblem, file string, line int, format string, args ...any) {
+	if problems != nil {
+		*problems = append(*problems, ParseProblem{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// numberHostBlocks sets the Order of hosts, which are in the order ssh reads their
+// blocks. The aliases of a block share its number.
+func numberHostBlocks(hosts []Host) {
+	order := -1
+	for i := range hosts {
+		if i == 0 || hosts[i].SourceFile != hosts[i-1].SourceFile || hosts[i].Line != hosts[i-1].Line {
+			order++
+		}
+		hosts[i].Order = order
+	}
+}
+
+// parseFile parses a config file with include support. processedFiles holds the
+// files already read, which are skipped, and depth counts the Includes that led to
+// the file. The lines and includes skipped are added to problems unless it is nil.
+func parseFile(configPath string, processedFiles map[string]bool, depth int, problems *[]ParseProblem) ([]Host, error) {
+	// Resolve absolute path to prevent infinite recursion
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", configPath, err)
+	}
+
+	// Check for circular includes
+	if processedFiles[absPath] {
+		return []Host{}, nil // Skip already processed files silently
+	}
+	processedFiles[absPath] = true
+	defer func(start time.Time) { metrics.ConfigParse.Record(absPath, depth, time.Since(start)) }(time.Now())
+
+	file, err := os.Open(configPath)
+	if os.IsNotExist(err) {
+		return []Host{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var hosts []Host
+	var currentHost *Host
+	var aliasNames []string  // The other names of the current block
+	var includedHosts []Host // Included inside the current block, which ssh reads first
+	var pendingTags []string
+	var pendingExpires string
+	var pendingDescription string
+	readOnly := false
+	inHeader := true  // Still in the comments at the top of the file
+	inBlocks := false // Past the first Host or Match line
+	lineNumber := 0
+	scanner := bufio.NewScanner(file)
+
+	// endBlock adds the current host, its aliases and what it included to hosts
+	endBlock := func() {
+		hosts = append(appendParsedHost(hosts, currentHost, aliasNames), includedHosts...)
+		currentHost, aliasNames, includedHosts = nil, nil, nil
+		pendingTags = nil
+		pendingExpires = ""
+		pendingDescription = ""
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		line := configtext.TrimLine(scanner.Text())
+
+		// Ignore empty lines
+		if line == "" {
+			continue
+		}
+
+		if inHeader {
+			if configtext.IsReadOnlyMarker(line) {
+				readOnly = true
+				continue
+			}
+			inHeader = strings.HasPrefix(line, "#")
+		}
+
+		// Check for description comment
+		if strings.HasPrefix(line, configtext.DescriptionPrefix) {
+			pendingDescription = strings.TrimSpace(strings.TrimPrefix(line, configtext.DescriptionPrefix))
+			continue
+		}
+
+		// Check for expiry comment
+		if strings.HasPrefix(line, configtext.ExpiresPrefix) {
+			pendingExpires = strings.TrimSpace(strings.TrimPrefix(line, configtext.ExpiresPrefix))
+			continue
+		}
+
+		// Check for tags comment
+		if strings.HasPrefix(line, configtext.TagsPrefix) {
+			tagsStr := strings.TrimPrefix(line, configtext.TagsPrefix)
+			tagsStr = strings.TrimSpace(tagsStr)
+			if tagsStr != "" {
+				// Split tags by comma and trim whitespace
+				for _, tag := range strings.Split(tagsStr, ",") {
+					pendingTags = configtext.MergeTags(pendingTags, strings.TrimSpace(tag))
+				}
+			}
+			continue
+		}
+
+		// Ignore other comments
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Split line into a keyword and its words, "Keyword=value" included
+		keyword, rawValue := configtext.SplitDirective(line)
+		words := strings.Fields(rawValue)
+		if len(words) == 0 {
+			addParseProblem(problems, absPath, lineNumber, "cannot split %q into a keyword and a value", line)
+			continue
+		}
+		if strings.Count(line, `"`)%2 != 0 {
+			addParseProblem(problems, absPath, lineNumber, "unterminated quote in %q", line)
+		}
+
+		key := strings.ToLower(keyword)
+		value := strings.Join(words, " ")
+
+		switch {
+		case key == "host" || key == "match":
+			inBlocks = true
+		case !inBlocks && key != "include":
+			addParseProblem(problems, absPath, lineNumber, "%s before the first Host is not read", keyword)
+		}
+
+		// A tags comment followed by a directive rather than a Host line was moved into
+		// the block, often by a formatter, and belongs to the host being parsed
+		if key != "host" && key != "match" && pendingTags != nil {
+			if currentHost != nil {
+				currentHost.Tags = configtext.MergeTags(currentHost.Tags, pendingTags...)
+			}
+			pendingTags = nil
+		}
+
+		if currentHost != nil && key != "host" && key != "match" && key != "include" {
+			if _, seen := currentHost.DirectiveLines[key]; !seen {
+				currentHost.DirectiveLines[key] = lineNumber
+			}
+		}
+
+		switch key {
+		case "include":
+			// Handle Include directive
+			includeHosts, err := parseInclude(value, configPath, processedFiles, depth+1, problems)
+			if err != nil {
+				// Don't fail the entire parse if include fails, just skip it
+				addParseProblem(problems, absPath, lineNumber, "Include %s: %v", value, err)
+				continue
+			}
+			if currentHost != nil {
+				includedHosts = append(includedHosts, includeHosts...)
+			} else {
+				hosts = append(hosts, includeHosts...)
+			}
+		case "host":
+			// New host, save previous one if it exists
+			description, tags, expires := pendingDescription, pendingTags, pendingExpires
+			endBlock()
+
+			// Skip wildcard (*, ?) and negated (!) names as they are patterns, not actual hosts
+			var validHostNames []string
+			for _, hostName := range configtext.SplitHostNames(value) {
+				if !configtext.IsPattern(hostName) {
+					validHostNames = append(validHostNames, hostName)
+				}
+			}
+			if len(validHostNames) == 0 {
+				continue
+			}
+
+			// The first name is parsed, and copied for the others once the block ends
+			currentHost = &Host{
+				Name:        validHostNames[0],
+				Port:        "22", // Default port
+				Description: description,
+				Tags:        tags,
+				Expires:     expires,
+				SourceFile:  absPath,
+				ReadOnly:    readOnly,
+
+				Line:           lineNumber,
+				DirectiveLines: make(map[string]int),
+			}
+			aliasNames = validHostNames[1:]
+		case "hostname":
+			if currentHost != nil {
+				currentHost.Hostname = value
+			}
+		case "user":
+			if currentHost != nil {
+				currentHost.User = value
+			}
+		case "port":
+			if currentHost != nil {
+				currentHost.Port = value
+			}
+		case "identityfile":
+			if currentHost != nil {
+				currentHost.IdentityFile = value
+			}
+		case "proxyjump":
+			if currentHost != nil {
+				currentHost.ProxyJump = value
+			}
+		case "proxycommand":
+			if currentHost != nil {
+				currentHost.ProxyCommand = value
+			}
+		case "remotecommand":
+			if currentHost != nil {
+				currentHost.RemoteCommand = value
+			}
+		case "requesttty":
+			if currentHost != nil {
+				currentHost.RequestTTY = value
+			}
+		case "tag":
+			// Native tags (OpenSSH 9.4) join the ones from a "# Tags:" comment
+			if currentHost != nil {
+				currentHost.Tags = configtext.MergeTags(currentHost.Tags, words...)
+			}
+		case "match":
+			// A Match block ends the host; its settings belong to whatever it matches
+			endBlock()
+		default:
+			if currentHost != nil {
+				currentHost.Options = append(currentHost.Options, Option{Name: keyword, Value: value})
+			}
+		}
+	}
+
+	// Add the last host if it exists
+	endBlock()
+
+	return hosts, scanner.Err()
+}
+
+// appendParsedHost adds a finished host block to hosts, with a copy for each of its aliases
+func appendParsedHost(hosts []Host, host *Host, aliasNames []string) []Host {
+	if host == nil {
+		return hosts
+	}
+	if len(aliasNames) > 0 {
+		host.BlockNames = append([]string{host.Name}, aliasNames...)
+	}
+	hosts = append(hosts, *host)
+
+	for _, aliasName := range aliasNames {
+		aliasHost := *host
+		aliasHost.Name = aliasName
+		hosts = append(hosts, aliasHost)
+	}
+	return hosts
+}
+
+// parseInclude parses the files an Include directive names, in the order ssh reads them
+func parseInclude(pattern string, baseConfigPath string, processedFiles map[string]bool, depth int, problems *[]ParseProblem) ([]Host, error) {
+	pattern, err := ResolveIncludePath(pattern, baseConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := configtext.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob pattern %s: %w", pattern, err)
+	}
+
+	var allHosts []Host
+	for _, match := range matches {
+		// Skip directories, sshc's backups and files that are not ssh configs
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			continue
+		}
+		if configtext.NotConfig(match) {
+			continue
+		}
+
+		hosts, err := parseFile(match, processedFiles, depth, problems)
+		if err != nil {
+			// Skip files that can't be parsed rather than failing completely
+			addParseProblem(problems, match, 0, "cannot read included file: %v", err)
+			continue
+		}
+		allHosts = append(allHosts, hosts...)
+	}
+
+	return allHosts, nil
+}
@@ -0,0 +1,162 @@
+package sshconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/xvertile/sshc/internal/configtext"
+)
+
+// Host is one host of an ssh config file. A Host line declaring several names
+// yields one Host per name, each with the settings of the block.
+type Host struct {
+	Name          string
+	Hostname      string
+	User          string
+	Port          string // "22" unless the block sets another port
+	IdentityFile  string
+	ProxyJump     string
+	ProxyCommand  string
+	RemoteCommand string
+	RequestTTY    string // yes, no, force or auto
+
+	// Options holds every other directive of the block, in file order
+	Options []Option
+
+	// Metadata sshc keeps in comments directly above the Host line
+	Description string
+	Tags        []string
+	Expires     string // YYYY-MM-DD
+
+	// SourceFile is the file the host was read from. It is ignored when writing.
+	SourceFile string
+	// ReadOnly is set when SourceFile starts with a "# sshm: readonly" comment
+	ReadOnly bool
+	// Order numbers the Host blocks in the order ssh reads them, included files
+	// counted where their Include is. Of two hosts with the same name, ssh uses
+	// the one with the lower Order. It is ignored when writing.
+	Order int
+
+	// BlockNames lists every name of the Host line the host was declared on,
+	// when there is more than one. It is ignored when writing.
+	BlockNames []string
+	// Line is the line of SourceFile the Host line is on, and DirectiveLines the
+	// line of each directive of the block by lowercase keyword. A directive given
+	// twice keeps its first line, the one ssh uses. Both are ignored when writing.
+	Line           int
+	DirectiveLines map[string]int
+}
+
+// Option is a directive of a Host block that has no field of its own in Host
+type Option struct {
+	Name  string
+	Value string
+}
+
+// ErrReadOnlyFile is returned when a write targets a file marked read-only
+var ErrReadOnlyFile = errors.New("config file is marked read-only")
+
+// DefaultPath returns the path of the user's ssh config, ~/.ssh/config
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh", "config"), nil
+}
+
+// Lookup returns the host called name from the config file at path or its includes
+func Lookup(path, name string) (Host, error) {
+	hosts, err := ParseFile(path)
+	if err != nil {
+		return Host{}, err
+	}
+	for _, host := range hosts {
+		if host.Name == name {
+			return host, nil
+		}
+	}
+	return Host{}, fmt.Errorf("host '%s' not found", name)
+}
+
+// HasHost reports whether a Host line of the config file at path declares name.
+// Included files are not searched, and a missing file declares nothing.
+func HasHost(path, name string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if names, ok := configtext.HostNames(configtext.TrimLine(scanner.Text())); ok && slices.Contains(names, name) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// Add appends host as a new block at the end of the config file at path. The file
+// is created if it does not exist. It fails if path already declares the name.
+// Nothing but the file is written; see Editor for backups and a record of changes.
+func Add(path string, host Host) error {
+	return Editor{}.Add(path, host)
+}
+
+// Update replaces the block of the host called name in the config file at path
+// with host, which may carry a new name. A host that shares its Host line with
+// others is moved into a block of its own, leaving the other names untouched.
+// Only path itself is searched; pass a host's SourceFile to edit an included file.
+func Update(path, name string, host Host) error {
+	return Editor{}.Update(path, name, host)
+}
+
+// Delete removes the host called name from the config file at path, along with
+// its metadata comments. Other names on the same Host line are kept.
+func Delete(path, name string) error {
+	return Editor{}.Delete(path, name)
+}
+
+// ResolveIncludePath returns the absolute form of an Include argument found in
+// the config file at configPath, the way ssh resolves it. The result may be a
+// glob pattern.
+func ResolveIncludePath(pattern, configPath string) (string, error) {
+	if strings.HasPrefix(pattern, "~") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		pattern = filepath.Join(homeDir, pattern[1:])
+	}
+
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(configPath), pattern)
+	}
+	return pattern, nil
+}
+
+// IncludedFiles returns the config file at path and every file it includes,
+// directly or through other includes, sorted by path
+func IncludedFiles(path string) ([]string, error) {
+	processed := make(map[string]bool)
+	if _, err := parseFile(path, processed, 0, nil); err != nil && len(processed) == 0 {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(processed))
+	for file := range processed {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
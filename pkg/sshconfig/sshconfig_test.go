@@ -0,0 +1,283 @@
+package sshconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupTest points the config directories at a temp directory, where nothing
+// should be written but the configs the test makes
+func setupTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv("APPDATA", tempDir)
+	t.Setenv("XDG_STATE_HOME", tempDir)
+	t.Setenv("LOCALAPPDATA", tempDir)
+	return tempDir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseFileFollowsIncludes(t *testing.T) {
+	tempDir := setupTest(t)
+	mainConfig := filepath.Join(tempDir, "ssh", "config")
+	writeFile(t, mainConfig, `Include conf.d/*
+
+# Description: web tier
+# Tags: prod, web
+Host web1 web2
+    HostName web.example.com
+    User deploy
+    ServerAliveInterval 30
+
+Host *
+    ForwardAgent no
+`)
+	writeFile(t, filepath.Join(tempDir, "ssh", "conf.d", "db"), "Host db\n    HostName db.example.com\n    Port 2222\n")
+
+	hosts, err := ParseFile(mainConfig)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	byName := make(map[string]Host)
+	for _, host := range hosts {
+		byName[host.Name] = host
+	}
+	if len(byName) != 3 {
+		t.Fatalf("Expected web1, web2 and db, got %+v", hosts)
+	}
+
+	web2 := byName["web2"]
+	if web2.Hostname != "web.example.com" || web2.User != "deploy" || web2.Description != "web tier" {
+		t.Errorf("Unexpected web2: %+v", web2)
+	}
+	if !reflect.DeepEqual(web2.Tags, []string{"prod", "web"}) {
+		t.Errorf("Expected tags [prod web], got %v", web2.Tags)
+	}
+	if !reflect.DeepEqual(web2.Options, []Option{{Name: "ServerAliveInterval", Value: "30"}}) {
+		t.Errorf("Expected ServerAliveInterval in Options, got %+v", web2.Options)
+	}
+
+	db := byName["db"]
+	if db.Port != "2222" || !strings.HasSuffix(db.SourceFile, filepath.Join("conf.d", "db")) {
+		t.Errorf("Unexpected db: %+v", db)
+	}
+	// The included db is read where its Include is, before the web block
+	if db.Order != 0 || web2.Order != 1 || byName["web1"].Order != 1 {
+		t.Errorf("Orders db=%d web1=%d web2=%d, want 0, 1, 1", db.Order, byName["web1"].Order, web2.Order)
+	}
+
+	files, err := IncludedFiles(mainConfig)
+	if err != nil {
+		t.Fatalf("IncludedFiles() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected the main config and conf.d/db, got %v", files)
+	}
+}
+
+func TestAddUpdateDeleteRoundTrip(t *testing.T) {
+	tempDir := setupTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, "# Managed by hand\nHost keep\n    HostName keep.example.com\n")
+
+	added := Host{
+		Name:         "app",
+		Hostname:     "app.example.com",
+		User:         "deploy",
+		Port:         "2200",
+		IdentityFile: "~/.ssh/app_ed25519",
+		Options:      []Option{{Name: "Compression", Value: "yes"}},
+		Tags:         []string{"staging"},
+	}
+	if err := Add(configFile, added); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := Add(configFile, added); err == nil {
+		t.Error("Expected adding a duplicate host to fail")
+	}
+
+	got, err := Lookup(configFile, "app")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	got.SourceFile, got.Order, got.Line, got.DirectiveLines = "", 0, 0, nil
+	if !reflect.DeepEqual(got, added) {
+		t.Errorf("Lookup() = %+v, want %+v", got, added)
+	}
+
+	renamed := added
+	renamed.Name = "app-staging"
+	renamed.User = "admin"
+	if err := Update(configFile, "app", renamed); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := Lookup(configFile, "app"); err == nil {
+		t.Error("Expected the old name to be gone after renaming")
+	}
+	if got, err := Lookup(configFile, "app-staging"); err != nil || got.User != "admin" {
+		t.Errorf("Lookup(app-staging) = %+v, %v", got, err)
+	}
+
+	if err := Delete(configFile, "app-staging"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := Delete(configFile, "app-staging"); err == nil {
+		t.Error("Expected deleting a missing host to fail")
+	}
+
+	content, _ := os.ReadFile(configFile)
+	if !strings.HasPrefix(string(content), "# Managed by hand\nHost keep\n    HostName keep.example.com\n") {
+		t.Errorf("Expected the untouched block to survive, got:\n%s", content)
+	}
+	if strings.Contains(string(content), "staging") {
+		t.Errorf("Expected the deleted host and its tags to be gone, got:\n%s", content)
+	}
+
+	// Without an Editor asking for them there are no backups or audit entries
+	entries, _ := os.ReadDir(tempDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the config file in %s, got %v", tempDir, entries)
+	}
+}
+
+func TestEditorHooks(t *testing.T) {
+	tempDir := setupTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, "Host web\n    HostName web.example.com\n")
+
+	var backedUp []string
+	type write struct{ path, host, before, after string }
+	var writes []write
+	editor := Editor{
+		NativeTags: true,
+		Backup: func(path string) error {
+			backedUp = append(backedUp, path)
+			return nil
+		},
+		Write: func(path, host string, before []byte, after string) error {
+			writes = append(writes, write{path, host, string(before), after})
+			return os.WriteFile(path, []byte(after), 0600)
+		},
+	}
+
+	if err := editor.Update(configFile, "web", Host{Name: "web", Hostname: "10.0.0.5", Tags: []string{"prod", "two words"}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := editor.Add(configFile, Host{Name: "db", Hostname: "db.example.com", Tags: []string{"prod"}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(backedUp, []string{configFile, configFile}) {
+		t.Errorf("Backup called with %v", backedUp)
+	}
+	if len(writes) != 2 || writes[0].host != "web" || writes[0].before != "Host web\n    HostName web.example.com\n" || writes[1].host != "db" {
+		t.Fatalf("Write called with %+v", writes)
+	}
+	// A tag with a space keeps the comment form; the others become Tag directives
+	content, _ := os.ReadFile(configFile)
+	if !strings.Contains(string(content), "# Tags: prod, two words\nHost web\n") || !strings.Contains(string(content), "Host db\n    HostName db.example.com\n    Tag prod\n") {
+		t.Errorf("Unexpected config:\n%s", content)
+	}
+
+	// A failing backup stops the change
+	editor.Backup = func(string) error { return os.ErrPermission }
+	if err := editor.Delete(configFile, "db"); err == nil || len(writes) != 2 {
+		t.Errorf("Delete() = %v after %d writes, want a backup error and no write", err, len(writes))
+	}
+}
+
+func TestEditorRefusesReadOnlyFile(t *testing.T) {
+	tempDir := setupTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, "# sshc: readonly\nHost web\n    HostName web.example.com\n")
+
+	hosts, err := ParseFile(configFile)
+	if err != nil || len(hosts) != 1 || !hosts[0].ReadOnly {
+		t.Fatalf("ParseFile() = %+v, %v, want a read-only host", hosts, err)
+	}
+	if err := Delete(configFile, "web"); !errors.Is(err, ErrReadOnlyFile) {
+		t.Errorf("Delete() error = %v, want ErrReadOnlyFile", err)
+	}
+}
+
+func TestUpdateSplitsSharedHostLine(t *testing.T) {
+	tempDir := setupTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, "Host a b c\n    HostName shared.example.com\n")
+
+	if err := Update(configFile, "b", Host{Name: "b", Hostname: "b.example.com"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	hosts, err := ParseFile(configFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	want := map[string]string{"a": "shared.example.com", "b": "b.example.com", "c": "shared.example.com"}
+	if len(hosts) != len(want) {
+		t.Fatalf("Expected %d hosts, got %+v", len(want), hosts)
+	}
+	for _, host := range hosts {
+		if host.Hostname != want[host.Name] {
+			t.Errorf("Expected %s to have HostName %s, got %s", host.Name, want[host.Name], host.Hostname)
+		}
+	}
+}
+
+func TestResolveIncludePath(t *testing.T) {
+	got, err := ResolveIncludePath("conf.d/*", "/etc/ssh/ssh_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != filepath.Join("/etc/ssh", "conf.d", "*") {
+		t.Errorf("ResolveIncludePath() = %s", got)
+	}
+}
+
+func TestParseFileWithOptions(t *testing.T) {
+	tempDir := setupTest(t)
+	configFile := filepath.Join(tempDir, "config")
+	writeFile(t, configFile, "User root\nHost web\n    HostName web.example.com\n    ForwardAgent\n")
+
+	// The default stays lenient and reports nothing
+	hosts, warnings, err := ParseFileWithOptions(configFile, ParseOptions{})
+	if err != nil || len(hosts) != 1 || warnings != nil {
+		t.Fatalf("ParseFileWithOptions() = %+v, %v, %v", hosts, warnings, err)
+	}
+
+	hosts, warnings, err = ParseFileWithOptions(configFile, ParseOptions{CollectWarnings: true})
+	want := []ParseProblem{
+		{File: configFile, Line: 1, Message: "User before the first Host is not read"},
+		{File: configFile, Line: 4, Message: `cannot split "ForwardAgent" into a keyword and a value`},
+	}
+	if err != nil || len(hosts) != 1 || hosts[0].Hostname != "web.example.com" {
+		t.Fatalf("lenient parse = %+v, %v", hosts, err)
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %v, want %v", warnings, want)
+	}
+
+	hosts, _, err = ParseFileWithOptions(configFile, ParseOptions{Strict: true})
+	var parseErrors ParseErrors
+	if !errors.As(err, &parseErrors) || hosts != nil || !reflect.DeepEqual([]ParseProblem(parseErrors), want) {
+		t.Fatalf("strict parse = %+v, %v, want ParseErrors", hosts, err)
+	}
+	if !strings.Contains(err.Error(), configFile+":4: cannot split") {
+		t.Errorf("error does not give file:line:\n%v", err)
+	}
+}